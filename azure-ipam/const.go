@@ -5,8 +5,10 @@ import (
 )
 
 const (
-	pluginName    = "azure-ipam"
-	cnsBaseURL    = "" // fallback to default http://localhost:10090
+	pluginName = "azure-ipam"
+	// cnsBaseURL falls back to the default http://localhost:10090, since the pinned CNS client has no unix socket
+	// support, so CNS refuses an exclusive unix socket listener on nodes with azure-ipam.
+	cnsBaseURL    = ""
 	cnsReqTimeout = 15 * time.Second
 )
 
@@ -74,6 +74,8 @@ type NetworkConfig struct {
 	DisableIPTableLock            bool            `json:"disableIPTableLock,omitempty"`
 	CNSUrl                        string          `json:"cnsurl,omitempty"`
 	ExecutionMode                 string          `json:"executionMode,omitempty"`
+	OTLPEndpoint                  string          `json:"otlpEndpoint,omitempty"`
+	OTLPFlushTimeoutMs            int             `json:"otlpFlushTimeoutMs,omitempty"`
 	IPAM                          IPAM            `json:"ipam,omitempty"`
 	DNS                           cniTypes.DNS    `json:"dns,omitempty"`
 	RuntimeConfig                 RuntimeConfig   `json:"runtimeConfig,omitempty"`
@@ -26,6 +26,8 @@ var (
 )
 
 type CNSIPAMInvoker struct {
+	// ctx is the context of the CNI command the invoker is created for, which carries its trace to CNS
+	ctx           context.Context //nolint:containedctx // an invoker is only used for a single CNI command
 	podName       string
 	podNamespace  string
 	cnsClient     cnsclient
@@ -43,8 +45,9 @@ type IPv4ResultInfo struct {
 	hostGateway        string
 }
 
-func NewCNSInvoker(podName, namespace string, cnsClient cnsclient, executionMode util.ExecutionMode, ipamMode util.IpamMode) *CNSIPAMInvoker {
+func NewCNSInvoker(ctx context.Context, podName, namespace string, cnsClient cnsclient, executionMode util.ExecutionMode, ipamMode util.IpamMode) *CNSIPAMInvoker {
 	return &CNSIPAMInvoker{
+		ctx:           ctx,
 		podName:       podName,
 		podNamespace:  namespace,
 		cnsClient:     cnsClient,
@@ -53,6 +56,13 @@ func NewCNSInvoker(podName, namespace string, cnsClient cnsclient, executionMode
 	}
 }
 
+func (invoker *CNSIPAMInvoker) context() context.Context {
+	if invoker.ctx == nil {
+		return context.TODO()
+	}
+	return invoker.ctx
+}
+
 // Add uses the requestipconfig API in cns, and returns ipv4 and a nil ipv6 as CNS doesn't support IPv6 yet
 func (invoker *CNSIPAMInvoker) Add(addConfig IPAMAddConfig) (IPAMAddResult, error) {
 	// Parse Pod arguments.
@@ -78,7 +88,7 @@ func (invoker *CNSIPAMInvoker) Add(addConfig IPAMAddConfig) (IPAMAddResult, erro
 	}
 
 	log.Printf("Requesting IP for pod %+v using ipconfig %+v", podInfo, ipconfig)
-	response, err := invoker.cnsClient.RequestIPAddress(context.TODO(), ipconfig)
+	response, err := invoker.cnsClient.RequestIPAddress(invoker.context(), ipconfig)
 	if err != nil {
 		log.Printf("Failed to get IP address from CNS with error %v, response: %v", err, response)
 		return IPAMAddResult{}, errors.Wrap(err, "Failed to get IP address from CNS with error: %w")
@@ -225,7 +235,7 @@ func (invoker *CNSIPAMInvoker) Delete(address *net.IPNet, nwCfg *cni.NetworkConf
 		log.Printf("CNS invoker called with empty IP address")
 	}
 
-	if err := invoker.cnsClient.ReleaseIPAddress(context.TODO(), req); err != nil {
+	if err := invoker.cnsClient.ReleaseIPAddress(invoker.context(), req); err != nil {
 		return errors.Wrap(err, fmt.Sprintf("failed to release IP %v with err ", address)+"%w")
 	}
 
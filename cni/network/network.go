@@ -323,6 +323,11 @@ func addNatIPV6SubnetInfo(nwCfg *cni.NetworkConfig,
 
 // Add handles CNI add commands.
 func (plugin *NetPlugin) Add(args *cniSkel.CmdArgs) error {
+	ctx, endTrace := plugin.startTrace(args, "CNI ADD")
+	return endTrace(plugin.add(ctx, args))
+}
+
+func (plugin *NetPlugin) add(ctx context.Context, args *cniSkel.CmdArgs) error {
 	var (
 		ipamAddResult    IPAMAddResult
 		azIpamResult     *cniTypesCurr.Result
@@ -447,7 +452,7 @@ func (plugin *NetPlugin) Add(args *cniSkel.CmdArgs) error {
 		}
 
 		ipamAddResult.ncResponse, ipamAddResult.hostSubnetPrefix, er = plugin.multitenancyClient.GetContainerNetworkConfiguration(
-			context.TODO(), nwCfg, k8sPodName, k8sNamespace)
+			ctx, nwCfg, k8sPodName, k8sNamespace)
 		if er != nil {
 			er = errors.Wrapf(er, "GetContainerNetworkConfiguration failed for podname %v namespace %v", k8sPodName, k8sNamespace)
 			log.Printf("%+v", er)
@@ -499,7 +504,7 @@ func (plugin *NetPlugin) Add(args *cniSkel.CmdArgs) error {
 	if plugin.ipamInvoker == nil {
 		switch nwCfg.IPAM.Type {
 		case network.AzureCNS:
-			plugin.ipamInvoker = NewCNSInvoker(k8sPodName, k8sNamespace, cnsClient, util.ExecutionMode(nwCfg.ExecutionMode), util.IpamMode(nwCfg.IPAM.Mode))
+			plugin.ipamInvoker = NewCNSInvoker(ctx, k8sPodName, k8sNamespace, cnsClient, util.ExecutionMode(nwCfg.ExecutionMode), util.IpamMode(nwCfg.IPAM.Mode))
 
 		default:
 			plugin.ipamInvoker = NewAzureIpamInvoker(plugin, &nwInfo)
@@ -863,6 +868,11 @@ func (plugin *NetPlugin) Get(args *cniSkel.CmdArgs) error {
 
 // Delete handles CNI delete commands.
 func (plugin *NetPlugin) Delete(args *cniSkel.CmdArgs) error {
+	ctx, endTrace := plugin.startTrace(args, "CNI DEL")
+	return endTrace(plugin.del(ctx, args))
+}
+
+func (plugin *NetPlugin) del(ctx context.Context, args *cniSkel.CmdArgs) error {
 	var (
 		err          error
 		nwCfg        *cni.NetworkConfig
@@ -929,12 +939,12 @@ func (plugin *NetPlugin) Delete(args *cniSkel.CmdArgs) error {
 	if plugin.ipamInvoker == nil {
 		switch nwCfg.IPAM.Type {
 		case network.AzureCNS:
-			cnsClient, cnsErr := cnscli.New("", defaultRequestTimeout)
+			cnsClient, cnsErr := cnscli.New(nwCfg.CNSUrl, defaultRequestTimeout)
 			if cnsErr != nil {
 				log.Printf("[cni-net] failed to create cns client:%v", cnsErr)
 				return errors.Wrap(cnsErr, "failed to create cns client")
 			}
-			plugin.ipamInvoker = NewCNSInvoker(k8sPodName, k8sNamespace, cnsClient, util.ExecutionMode(nwCfg.ExecutionMode), util.IpamMode(nwCfg.IPAM.Mode))
+			plugin.ipamInvoker = NewCNSInvoker(ctx, k8sPodName, k8sNamespace, cnsClient, util.ExecutionMode(nwCfg.ExecutionMode), util.IpamMode(nwCfg.IPAM.Mode))
 
 		default:
 			plugin.ipamInvoker = NewAzureIpamInvoker(plugin, &nwInfo)
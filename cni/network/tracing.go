@@ -0,0 +1,93 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Azure/azure-container-networking/cni"
+	"github.com/Azure/azure-container-networking/log"
+	"github.com/Azure/azure-container-networking/otlp"
+	cniSkel "github.com/containernetworking/cni/pkg/skel"
+	cniTypes "github.com/containernetworking/cni/pkg/types"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startTrace starts the span of a CNI command, and returns a context carrying it and a function which ends it
+// with the error of the command. The span is the parent of the requests to CNS, which continues the trace.
+// The span is only exported to the OTLP endpoint of the network config when it also has an otlpFlushTimeoutMs,
+// since the plugin exits after the command and must wait, up to that timeout, for the export; every pod ADD and
+// DEL is delayed by it when the collector is slow. Otherwise the trace is still propagated to CNS, which exports
+// its spans of the command.
+// The function returns the error with the trace ID, to find the requests of a failed command in CNS and NMAgent.
+func (plugin *NetPlugin) startTrace(args *cniSkel.CmdArgs, name string) (context.Context, func(error) error) {
+	// the provider generates the ids of the trace even when it exports nothing
+	tp := sdktrace.NewTracerProvider()
+	var flushTimeout time.Duration
+	if nwCfg, err := cni.ParseNetworkConfig(args.StdinData); err == nil && nwCfg.OTLPEndpoint != "" && nwCfg.OTLPFlushTimeoutMs > 0 {
+		exported, err := otlp.NewTracerProvider(otlp.Config{
+			Endpoint:       nwCfg.OTLPEndpoint,
+			ServiceName:    plugin.Name,
+			ServiceVersion: plugin.Version,
+		})
+		if err != nil {
+			log.Printf("[cni-net] Failed to create the OTLP exporter: %v", err)
+		} else {
+			tp, flushTimeout = exported, time.Duration(nwCfg.OTLPFlushTimeoutMs)*time.Millisecond
+		}
+	}
+	otel.SetTracerProvider(tp)
+
+	attrs := []attribute.KeyValue{
+		attribute.String("container_id", args.ContainerID),
+		attribute.String("ifname", args.IfName),
+		attribute.String("netns", args.Netns),
+	}
+	if podCfg, err := cni.ParseCniArgs(args.Args); err == nil {
+		attrs = append(attrs,
+			attribute.String("pod", string(podCfg.K8S_POD_NAME)),
+			attribute.String("namespace", string(podCfg.K8S_POD_NAMESPACE)))
+	}
+	ctx, span := otlp.Tracer().Start(context.Background(), name,
+		trace.WithSpanKind(trace.SpanKindInternal), trace.WithAttributes(attrs...))
+	traceID := span.SpanContext().TraceID().String()
+	log.Printf("[cni-net] %s trace ID %s", name, traceID)
+
+	return ctx, func(err error) error {
+		if err != nil {
+			otlp.SetError(span, err)
+		} else {
+			span.SetStatus(codes.Ok, "")
+		}
+		span.End()
+		if flushTimeout > 0 {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+			defer cancel()
+			if shutdownErr := tp.Shutdown(shutdownCtx); shutdownErr != nil {
+				log.Printf("[cni-net] Failed to export the trace of %s: %v", name, shutdownErr)
+			}
+		}
+		return withTraceID(err, traceID)
+	}
+}
+
+// withTraceID adds the trace ID to the error returned to the runtime. The details of CNI errors get the ID, so that
+// the code and message of the error stay as they are.
+func withTraceID(err error, traceID string) error {
+	if err == nil {
+		return nil
+	}
+	if cniErr, ok := err.(*cniTypes.Error); ok { //nolint:errorlint // the CNI error is returned as the error itself
+		traced := *cniErr
+		if traced.Details != "" {
+			traced.Details += ", "
+		}
+		traced.Details += "trace ID " + traceID
+		return &traced
+	}
+	return fmt.Errorf("%w (trace ID %s)", err, traceID)
+}
@@ -0,0 +1,76 @@
+package network
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/cni"
+	"github.com/Azure/azure-container-networking/common"
+	cniSkel "github.com/containernetworking/cni/pkg/skel"
+	cniTypes "github.com/containernetworking/cni/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestStartTraceExportIsOptIn(t *testing.T) {
+	var exports atomic.Int32
+	collector := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/traces" {
+			exports.Add(1)
+		}
+	}))
+	defer collector.Close()
+	plugin := &NetPlugin{Plugin: &cni.Plugin{Plugin: &common.Plugin{Name: "azure-vnet", Version: "v1.0.0"}}}
+
+	tests := []struct {
+		name    string
+		config  string
+		exports int32
+	}{
+		{
+			name:    "endpoint without flush timeout",
+			config:  `{"otlpEndpoint":"` + collector.URL + `"}`,
+			exports: 0,
+		},
+		{
+			name:    "endpoint with flush timeout",
+			config:  `{"otlpEndpoint":"` + collector.URL + `","otlpFlushTimeoutMs":5000}`,
+			exports: 1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			exports.Store(0)
+			ctx, end := plugin.startTrace(&cniSkel.CmdArgs{ContainerID: "c1", StdinData: []byte(tt.config)}, "CNI ADD")
+			// the trace is propagated to CNS whether or not the plugin exports its span
+			require.True(t, trace.SpanContextFromContext(ctx).IsValid())
+			require.NoError(t, end(nil))
+			assert.Equal(t, tt.exports, exports.Load())
+		})
+	}
+}
+
+func TestWithTraceID(t *testing.T) {
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	assert.NoError(t, withTraceID(nil, traceID))
+
+	errNoIPs := errors.New("no IPs available")
+	err := withTraceID(errNoIPs, traceID)
+	require.ErrorIs(t, err, errNoIPs)
+	assert.Equal(t, "no IPs available (trace ID "+traceID+")", err.Error())
+
+	cniErr := cniTypes.NewError(cniTypes.ErrTryAgainLater, "CNS is unavailable", "dial timeout")
+	err = withTraceID(cniErr, traceID)
+	var traced *cniTypes.Error
+	require.ErrorAs(t, err, &traced)
+	assert.Equal(t, uint(cniTypes.ErrTryAgainLater), traced.Code)
+	assert.Equal(t, "CNS is unavailable", traced.Msg)
+	assert.Equal(t, "dial timeout, trace ID "+traceID, traced.Details)
+	assert.Equal(t, "dial timeout", cniErr.Details, "the error of the command must not be modified")
+}
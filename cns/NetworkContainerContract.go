@@ -6,6 +6,7 @@ import (
 	"net"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Azure/azure-container-networking/cns/types"
 	"github.com/pkg/errors"
@@ -30,6 +31,10 @@ const (
 	PathDebugIPAddresses                     = "/debug/ipaddresses"
 	PathDebugPodContext                      = "/debug/podcontext"
 	PathDebugRestData                        = "/debug/restdata"
+	PathDebugLogLevels                       = "/debug/loglevel"
+	PathDebugPprof                           = "/debug/pprof"
+	PathReadyz                               = "/readyz"
+	WatchIPAddresses                         = "/network/ipaddresses/watch"
 	NumberOfCPUCores                         = NumberOfCPUCoresPath
 	NMAgentSupportedAPIs                     = NmAgentSupportedApisPath
 )
@@ -434,11 +439,30 @@ type GetIPAddressStateResponse struct {
 }
 
 // GetIPAddressStatusResponse is used in CNS IPAM mode as a response to get IP address, state and Pod info
+// The ResourceVersion is of the last IPStateEvent, so a watch from it receives every change after the response.
+// Resource versions are only valid in the CNS process which returned them, and a watch from one of a previous process fails.
 type GetIPAddressStatusResponse struct {
 	IPConfigurationStatus []IPConfigurationStatus
+	ResourceVersion       uint64 `json:",omitempty"`
 	Response              Response
 }
 
+// IPStateEvent is a transition of the state of an IP, which is sent by the IP address watch in ResourceVersion order.
+// An IP which is added has an empty PreviousState, and an IP which is removed is Deleted.
+// The Pod is the one the IP is assigned to, or was assigned to before the transition.
+type IPStateEvent struct {
+	ResourceVersion uint64
+	Time            time.Time
+	ID              string
+	IPAddress       string
+	NCID            string
+	PreviousState   types.IPState
+	State           types.IPState
+	Deleted         bool `json:",omitempty"`
+	PodName         string
+	PodNamespace    string
+}
+
 // GetPodContextResponse is used in CNS Client debug mode to get mapping of Orchestrator Context to Pod IP UUID
 type GetPodContextResponse struct {
 	PodContext map[string]string
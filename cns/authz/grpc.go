@@ -0,0 +1,81 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/Azure/azure-container-networking/cns"
+	"github.com/Azure/azure-container-networking/cns/logger"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCIdentities returns the identities of the client of the gRPC call, like Identities for HTTP requests.
+// The UID of the client is only known if the server wraps its transport credentials to add it to the
+// cns.PeerAuthInfo of the connection.
+func GRPCIdentities(ctx context.Context) []string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	var (
+		info   = p.AuthInfo
+		uid    uint32
+		hasUID bool
+	)
+	if peerInfo, ok := info.(cns.PeerAuthInfo); ok {
+		info, uid, hasUID = peerInfo.AuthInfo, peerInfo.UID, peerInfo.HasUID
+	}
+	if tlsInfo, ok := info.(credentials.TLSInfo); ok {
+		return identities(&tlsInfo.State, uid, hasUID)
+	}
+	return identities(nil, uid, hasUID)
+}
+
+// AuthorizeGRPC returns ErrUnauthorized unless a rule allows one of the identities of the client to call the route
+// group of the gRPC method, which is looked up in groups by its full name. Methods which are not in a route group
+// are not authorized.
+func (p *Policy) AuthorizeGRPC(ctx context.Context, groups map[string]cns.RouteGroup, method string) error {
+	group, ok := groups[method]
+	if !ok {
+		return errors.Wrapf(ErrUnauthorized, "%s is not in a route group", method)
+	}
+	return p.authorize(group, GRPCIdentities(ctx))
+}
+
+// UnaryServerInterceptor serves only the unary calls which the policy authorizes, and fails the others with
+// PermissionDenied.
+func (p *Policy) UnaryServerInterceptor(groups map[string]cns.RouteGroup) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := p.authorizeCall(ctx, groups, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor serves only the streaming calls which the policy authorizes, and fails the others with
+// PermissionDenied.
+func (p *Policy) StreamServerInterceptor(groups map[string]cns.RouteGroup) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := p.authorizeCall(ss.Context(), groups, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func (p *Policy) authorizeCall(ctx context.Context, groups map[string]cns.RouteGroup, method string) error {
+	if err := p.AuthorizeGRPC(ctx, groups, method); err != nil {
+		addr := "unknown"
+		if pr, ok := peer.FromContext(ctx); ok {
+			addr = pr.Addr.String()
+		}
+		logger.Errorf("[Azure CNS] Denied gRPC %s from %s: %v", method, addr, err)
+		return status.Error(codes.PermissionDenied, err.Error()) //nolint:wrapcheck // grpc status errors must not be wrapped
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package authz
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/cns"
+	grpcv1 "github.com/Azure/azure-container-networking/cns/grpc/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	requestIPConfigMethod  = "/cns.v1.CNS/RequestIPConfig"
+	watchIPAddressesMethod = "/cns.v1.CNS/WatchIPAddresses"
+)
+
+// peerContext returns the context of a gRPC call from a client with the AuthInfo.
+func peerContext(info credentials.AuthInfo) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{Addr: &net.UnixAddr{Name: "cns.sock", Net: "unix"}, AuthInfo: info})
+}
+
+func TestGRPCIdentities(t *testing.T) {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "dnc"}}
+	tlsInfo := credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}}
+
+	assert.Equal(t, []string{"CN=dnc"}, GRPCIdentities(peerContext(tlsInfo)))
+	assert.Equal(t, []string{"CN=dnc", "UID=0"}, GRPCIdentities(peerContext(cns.PeerAuthInfo{AuthInfo: tlsInfo, HasUID: true})))
+	assert.Equal(t, []string{"UID=1000"}, GRPCIdentities(peerContext(cns.PeerAuthInfo{UID: 1000, HasUID: true})))
+	assert.Empty(t, GRPCIdentities(peerContext(cns.PeerAuthInfo{})))
+	assert.Empty(t, GRPCIdentities(context.Background()))
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := newTestPolicy(t).UnaryServerInterceptor(grpcv1.RouteGroups)
+	handler := func(context.Context, interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	root := cns.PeerAuthInfo{HasUID: true}
+	user := cns.PeerAuthInfo{UID: 1000, HasUID: true}
+
+	resp, err := interceptor(peerContext(root), nil, &grpc.UnaryServerInfo{FullMethod: requestIPConfigMethod}, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+
+	_, err = interceptor(peerContext(user), nil, &grpc.UnaryServerInfo{FullMethod: requestIPConfigMethod}, handler)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = interceptor(peerContext(root), nil, &grpc.UnaryServerInfo{FullMethod: "/cns.v1.CNS/Unknown"}, handler)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	interceptor := newTestPolicy(t).StreamServerInterceptor(grpcv1.RouteGroups)
+	handler := func(interface{}, grpc.ServerStream) error {
+		return nil
+	}
+
+	// no rule allows the Debug route group
+	err := interceptor(nil, testServerStream{ctx: peerContext(cns.PeerAuthInfo{HasUID: true})}, &grpc.StreamServerInfo{FullMethod: watchIPAddressesMethod}, handler)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
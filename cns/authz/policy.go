@@ -0,0 +1,164 @@
+// Package authz authorizes the clients of the CNS API, mapping the identities of the clients to the route groups
+// they may call.
+package authz
+
+import (
+	"crypto/tls"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-container-networking/cns"
+	"github.com/Azure/azure-container-networking/cns/configuration"
+	"github.com/Azure/azure-container-networking/cns/logger"
+	acn "github.com/Azure/azure-container-networking/common"
+	"github.com/pkg/errors"
+)
+
+// Prefixes of the identities of clients.
+const (
+	CommonNamePrefix     = "CN="
+	SubjectAltNamePrefix = "SAN="
+	UIDPrefix            = "UID="
+	// AnyIdentity matches every client, including those without an identity.
+	AnyIdentity = "*"
+)
+
+// ErrUnauthorized is returned for clients which are not allowed to call a route.
+var ErrUnauthorized = errors.New("client is not authorized")
+
+type rule struct {
+	identities map[string]struct{}
+	groups     map[cns.RouteGroup]struct{}
+}
+
+// Policy authorizes requests by the route group of their path and the identities of their client.
+type Policy struct {
+	rules []rule
+}
+
+// NewPolicy returns the Policy of the authorization settings.
+func NewPolicy(settings configuration.AuthorizationSettings) (*Policy, error) {
+	known := map[cns.RouteGroup]struct{}{}
+	for _, group := range cns.RouteGroups {
+		known[group] = struct{}{}
+	}
+
+	p := &Policy{}
+	for i, r := range settings.Rules {
+		rule := rule{
+			identities: map[string]struct{}{},
+			groups:     map[cns.RouteGroup]struct{}{},
+		}
+		for _, identity := range r.Identities {
+			if err := validateIdentity(identity); err != nil {
+				return nil, errors.Wrapf(err, "invalid identity in rule %d", i)
+			}
+			rule.identities[identity] = struct{}{}
+		}
+		for _, group := range r.RouteGroups {
+			if _, ok := known[cns.RouteGroup(group)]; !ok {
+				return nil, errors.Errorf("unknown route group %q in rule %d", group, i)
+			}
+			rule.groups[cns.RouteGroup(group)] = struct{}{}
+		}
+		p.rules = append(p.rules, rule)
+	}
+	return p, nil
+}
+
+func validateIdentity(identity string) error {
+	switch {
+	case identity == AnyIdentity:
+		return nil
+	case strings.HasPrefix(identity, UIDPrefix):
+		if _, err := strconv.ParseUint(strings.TrimPrefix(identity, UIDPrefix), 10, 32); err != nil {
+			return errors.Wrapf(err, "invalid uid in %q", identity)
+		}
+		return nil
+	case strings.HasPrefix(identity, CommonNamePrefix) && len(identity) > len(CommonNamePrefix),
+		strings.HasPrefix(identity, SubjectAltNamePrefix) && len(identity) > len(SubjectAltNamePrefix):
+		return nil
+	}
+	return errors.Errorf("identity %q is not %s, %s, %s or %s", identity, CommonNamePrefix, SubjectAltNamePrefix, UIDPrefix, AnyIdentity)
+}
+
+// Identities returns the identities of the client of the request: the common name and subject alternative names of
+// its verified certificate, and the UID of its process when it is connected over a unix socket.
+func Identities(r *http.Request) []string {
+	uid, hasUID := acn.PeerUIDFromContext(r.Context())
+	return identities(r.TLS, uid, hasUID)
+}
+
+func identities(state *tls.ConnectionState, uid uint32, hasUID bool) []string {
+	var identities []string
+	if state != nil && len(state.VerifiedChains) > 0 {
+		cert := state.VerifiedChains[0][0]
+		if cert.Subject.CommonName != "" {
+			identities = append(identities, CommonNamePrefix+cert.Subject.CommonName)
+		}
+		for _, name := range cert.DNSNames {
+			identities = append(identities, SubjectAltNamePrefix+name)
+		}
+		for _, ip := range cert.IPAddresses {
+			identities = append(identities, SubjectAltNamePrefix+ip.String())
+		}
+		for _, uri := range cert.URIs {
+			identities = append(identities, SubjectAltNamePrefix+uri.String())
+		}
+		for _, email := range cert.EmailAddresses {
+			identities = append(identities, SubjectAltNamePrefix+email)
+		}
+	}
+	if hasUID {
+		identities = append(identities, UIDPrefix+strconv.FormatUint(uint64(uid), 10))
+	}
+	return identities
+}
+
+// publicPaths are served to every client, since the kubelet probes them without credentials.
+var publicPaths = map[string]struct{}{
+	cns.PathReadyz: {},
+}
+
+// Authorize returns ErrUnauthorized unless a rule allows one of the identities of the client to call the route group
+// of the path of the request. Paths which are not in a route group are not authorized, except the public paths.
+func (p *Policy) Authorize(r *http.Request) error {
+	if _, ok := publicPaths[r.URL.Path]; ok {
+		return nil
+	}
+	group, ok := cns.RouteGroupOf(r.URL.Path)
+	if !ok {
+		return errors.Wrapf(ErrUnauthorized, "%s is not in a route group", r.URL.Path)
+	}
+	return p.authorize(group, Identities(r))
+}
+
+func (p *Policy) authorize(group cns.RouteGroup, identities []string) error {
+	for _, rule := range p.rules {
+		if _, ok := rule.groups[group]; !ok {
+			continue
+		}
+		if _, ok := rule.identities[AnyIdentity]; ok {
+			return nil
+		}
+		for _, identity := range identities {
+			if _, ok := rule.identities[identity]; ok {
+				return nil
+			}
+		}
+	}
+	return errors.Wrapf(ErrUnauthorized, "identities %v may not call %s routes", identities, group)
+}
+
+// Handler serves only the requests which the policy authorizes, and responds to the others with 403 Forbidden.
+func (p *Policy) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := p.Authorize(r); err != nil {
+			logger.Errorf("[Azure CNS] Denied %s %s from %s: %v", r.Method, r.URL.Path, r.RemoteAddr, err)
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,56 @@
+package authz
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/cns"
+	"github.com/Azure/azure-container-networking/cns/configuration"
+	acn "github.com/Azure/azure-container-networking/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorizeUnixSocketPeer(t *testing.T) {
+	p, err := NewPolicy(configuration.AuthorizationSettings{
+		Enabled: true,
+		Rules: []configuration.AuthorizationRule{
+			{Identities: []string{"UID=" + strconv.Itoa(os.Getuid())}, RouteGroups: []string{"IPAM"}},
+		},
+	})
+	require.NoError(t, err)
+
+	socket := filepath.Join(t.TempDir(), "azure-cns", "cns.sock")
+	listener, err := acn.NewListener(&url.URL{Scheme: "tcp", Host: "localhost:10090"})
+	require.NoError(t, err)
+	listener.AddHandler(cns.RequestIPConfig, func(http.ResponseWriter, *http.Request) {})
+	listener.AddHandler(cns.PublishNetworkContainer, func(http.ResponseWriter, *http.Request) {})
+	listener.Use(p.Handler)
+	require.NoError(t, listener.StartUnix(make(chan error, 1), socket, 0o600))
+	defer listener.Stop()
+
+	info, err := os.Stat(socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	post := func(path string) int {
+		resp, err := client.Post("http://cns"+path, "application/json", http.NoBody)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusOK, post(cns.RequestIPConfig))
+	assert.Equal(t, http.StatusForbidden, post(cns.PublishNetworkContainer))
+}
@@ -0,0 +1,125 @@
+package authz
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/cns"
+	"github.com/Azure/azure-container-networking/cns/configuration"
+	"github.com/Azure/azure-container-networking/cns/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLogger("", 0, 0, "")
+	os.Exit(m.Run())
+}
+
+func newTestPolicy(t *testing.T) *Policy {
+	t.Helper()
+	p, err := NewPolicy(configuration.AuthorizationSettings{
+		Enabled: true,
+		Rules: []configuration.AuthorizationRule{
+			{Identities: []string{"CN=dnc"}, RouteGroups: []string{"NetworkContainers", "Read"}},
+			{Identities: []string{"SAN=cni.example.com", "UID=0"}, RouteGroups: []string{"IPAM"}},
+			{Identities: []string{"*"}, RouteGroups: []string{"Read"}},
+			{Identities: []string{"CN=admin"}, RouteGroups: []string{"Debug"}},
+		},
+	})
+	require.NoError(t, err)
+	return p
+}
+
+// requestFrom returns a request to the path from a client with a verified certificate of the common name and DNS name.
+func requestFrom(path, commonName, dnsName string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, path, http.NoBody)
+	if commonName != "" || dnsName != "" {
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}, IPAddresses: []net.IP{net.IPv4(10, 0, 0, 4)}}
+		if dnsName != "" {
+			cert.DNSNames = []string{dnsName}
+		}
+		r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	}
+	return r
+}
+
+func TestNewPolicy(t *testing.T) {
+	tests := []struct {
+		name string
+		rule configuration.AuthorizationRule
+	}{
+		{"unknown route group", configuration.AuthorizationRule{Identities: []string{"CN=dnc"}, RouteGroups: []string{"Admin"}}},
+		{"unknown identity", configuration.AuthorizationRule{Identities: []string{"dnc"}, RouteGroups: []string{"Read"}}},
+		{"empty common name", configuration.AuthorizationRule{Identities: []string{"CN="}, RouteGroups: []string{"Read"}}},
+		{"invalid uid", configuration.AuthorizationRule{Identities: []string{"UID=root"}, RouteGroups: []string{"Read"}}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPolicy(configuration.AuthorizationSettings{Rules: []configuration.AuthorizationRule{tt.rule}})
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestIdentities(t *testing.T) {
+	assert.Equal(t, []string{"CN=dnc", "SAN=dnc.example.com", "SAN=10.0.0.4"},
+		Identities(requestFrom(cns.CreateOrUpdateNetworkContainer, "dnc", "dnc.example.com")))
+	assert.Empty(t, Identities(requestFrom(cns.CreateOrUpdateNetworkContainer, "", "")))
+}
+
+func TestAuthorize(t *testing.T) {
+	p := newTestPolicy(t)
+	tests := []struct {
+		name       string
+		req        *http.Request
+		authorized bool
+	}{
+		{"dnc creates nc", requestFrom(cns.CreateOrUpdateNetworkContainer, "dnc", ""), true},
+		{"dnc creates nc with version prefix", requestFrom(cns.V2Prefix+cns.CreateOrUpdateNetworkContainer, "dnc", ""), true},
+		{"dnc requests ip", requestFrom(cns.RequestIPConfig, "dnc", ""), false},
+		{"cni requests ip by san", requestFrom(cns.RequestIPConfig, "cni", "cni.example.com"), true},
+		{"cni publishes nc", requestFrom(cns.PublishNetworkContainer, "cni", "cni.example.com"), false},
+		{"anonymous reads", requestFrom(cns.GetHomeAz, "", ""), true},
+		{"anonymous deletes nc", requestFrom(cns.DeleteNetworkContainer, "", ""), false},
+		{"admin sets log level", requestFrom(cns.PathDebugLogLevels+"/ipampool", "admin", ""), true},
+		{"anonymous sets log level", requestFrom(cns.PathDebugLogLevels+"/ipampool", "", ""), false},
+		{"admin profiles", requestFrom(cns.PathDebugPprof+"/heap", "admin", ""), true},
+		{"anonymous profiles", requestFrom(cns.PathDebugPprof+"/", "", ""), false},
+		{"anonymous probes readiness", requestFrom(cns.PathReadyz, "", ""), true},
+		{"path not in a route group", requestFrom("/unknown", "dnc", ""), false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.Authorize(tt.req)
+			if tt.authorized {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrUnauthorized)
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	p := newTestPolicy(t)
+	handler := p.Handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, requestFrom(cns.PublishNetworkContainer, "", ""))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, requestFrom(cns.PublishNetworkContainer, "dnc", ""))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -1,29 +1,42 @@
 package client
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Azure/azure-container-networking/cns"
 	"github.com/Azure/azure-container-networking/cns/restserver"
 	"github.com/Azure/azure-container-networking/cns/types"
+	"github.com/Azure/azure-container-networking/otlp"
 	"github.com/pkg/errors"
 )
 
 const (
 	contentTypeJSON = "application/json"
 	defaultBaseURL  = "http://localhost:10090"
+	unixScheme      = "unix://"
+	unixBaseURL     = "http://localhost"
 	// DefaultTimeout default timeout duration for CNS Client.
 	DefaultTimeout    = 5 * time.Second
 	headerContentType = "Content-Type"
+	// watchRetryDelay is how long a watch waits before it reconnects after a disconnect.
+	watchRetryDelay = time.Second
 )
 
+// ErrResourceVersionTooOld is returned by WatchIPAddresses when CNS no longer has the events after the resource version.
+// The IPs should be listed again, and watched from the listed ResourceVersion.
+var ErrResourceVersionTooOld = errors.New("resource version too old")
+
 var clientPaths = []string{
 	cns.GetNetworkContainerByOrchestratorContext,
 	cns.CreateHostNCApipaEndpointPath,
@@ -42,6 +55,7 @@ var clientPaths = []string{
 	cns.DeleteNetworkContainer,
 	cns.NetworkContainersURLPath,
 	cns.GetHomeAz,
+	cns.WatchIPAddresses,
 }
 
 type do interface {
@@ -55,11 +69,24 @@ type Client struct {
 }
 
 // New returns a new CNS client configured with the passed URL and timeout.
+// The URL of the unix socket listener of CNS is unix:///path/to/socket.
 func New(baseURL string, requestTimeout time.Duration) (*Client, error) {
 	if baseURL == "" {
 		baseURL = defaultBaseURL
 	}
 
+	var transport http.RoundTripper
+	if socket := strings.TrimPrefix(baseURL, unixScheme); socket != baseURL {
+		transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socket)
+			},
+		}
+		// the requests are sent over the socket, whatever their host
+		baseURL = unixBaseURL
+	}
+
 	routes, err := buildRoutes(baseURL, clientPaths)
 	if err != nil {
 		return nil, err
@@ -68,6 +95,8 @@ func New(baseURL string, requestTimeout time.Duration) (*Client, error) {
 	return &Client{
 		client: &http.Client{
 			Timeout: requestTimeout,
+			// propagate the trace of the caller, such as a CNI ADD, to CNS
+			Transport: &otlp.Transport{Base: transport},
 		},
 		routes: routes,
 	}, nil
@@ -320,6 +349,15 @@ func (c *Client) GetIPAddressesMatchingStates(ctx context.Context, stateFilter .
 		return nil, nil
 	}
 
+	resp, err := c.GetIPAddressStatus(ctx, stateFilter...)
+	if err != nil {
+		return nil, err
+	}
+	return resp.IPConfigurationStatus, nil
+}
+
+// GetIPAddressStatus gets all IP Addresses matching any of the states, with the ResourceVersion to watch them from.
+func (c *Client) GetIPAddressStatus(ctx context.Context, stateFilter ...types.IPState) (*cns.GetIPAddressStatusResponse, error) {
 	payload := cns.GetIPAddressesRequest{
 		IPConfigStateFilter: stateFilter,
 	}
@@ -355,7 +393,7 @@ func (c *Client) GetIPAddressesMatchingStates(ctx context.Context, stateFilter .
 		return nil, errors.New(resp.Response.Message)
 	}
 
-	return resp.IPConfigurationStatus, nil
+	return &resp, nil
 }
 
 // GetPodOrchestratorContext calls GetPodIpOrchestratorContext API on CNS
@@ -847,3 +885,110 @@ func (c *Client) GetHomeAz(ctx context.Context) (*cns.GetHomeAzResponse, error)
 
 	return &getHomeAzResponse, nil
 }
+
+// WatchIPAddresses calls fn with every IP state event after the resource version, in order, until the context
+// is cancelled or fn returns an error. A resource version of 0 watches from the current one.
+// The watch reconnects after disconnects, resuming from the last event it received.
+func (c *Client) WatchIPAddresses(ctx context.Context, resourceVersion uint64, fn func(cns.IPStateEvent) error) error {
+	// the request timeout of the client doesn't apply to watches, which don't end
+	watchClient := c.client
+	if httpClient, ok := c.client.(*http.Client); ok && httpClient.Timeout > 0 {
+		noTimeout := *httpClient
+		noTimeout.Timeout = 0
+		watchClient = &noTimeout
+	}
+
+	var handled bool
+	for {
+		err := c.watchIPAddresses(ctx, watchClient, &resourceVersion, func(event cns.IPStateEvent) error {
+			handled = true
+			return fn(event)
+		})
+		if ctx.Err() != nil {
+			return ctx.Err() //nolint:wrapcheck // the caller's context error
+		}
+		var fnErr *watchFuncError
+		if errors.As(err, &fnErr) {
+			return fnErr.err
+		}
+		if errors.Is(err, ErrResourceVersionTooOld) {
+			return err
+		}
+		if !handled && err != nil {
+			// don't retry a watch which never received anything, e.g. since CNS doesn't support it
+			var failed *FailedHTTPRequest
+			if errors.As(err, &failed) {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err() //nolint:wrapcheck // the caller's context error
+		case <-time.After(watchRetryDelay):
+		}
+	}
+}
+
+// watchFuncError wraps the error of the func of a watch, so that it is returned instead of retrying the watch.
+type watchFuncError struct {
+	err error
+}
+
+func (e *watchFuncError) Error() string {
+	return e.err.Error()
+}
+
+// watchIPAddresses reads the server-sent events of a single watch request, and updates the resource version
+// with the id of each event.
+func (c *Client) watchIPAddresses(ctx context.Context, watchClient do, resourceVersion *uint64, fn func(cns.IPStateEvent) error) error {
+	u := c.routes[cns.WatchIPAddresses]
+	if *resourceVersion > 0 {
+		u.RawQuery = url.Values{"resourceVersion": []string{strconv.FormatUint(*resourceVersion, 10)}}.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), http.NoBody)
+	if err != nil {
+		return errors.Wrap(err, "failed to build request")
+	}
+	res, err := watchClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "http request failed")
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusGone {
+		return ErrResourceVersionTooOld
+	}
+	if res.StatusCode != http.StatusOK {
+		return &FailedHTTPRequest{Code: res.StatusCode}
+	}
+
+	var id, data string
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			// an event ends with a blank line, and may have only an id to set the resource version
+			if id != "" {
+				if *resourceVersion, err = strconv.ParseUint(id, 10, 64); err != nil {
+					return errors.Wrapf(err, "invalid event id %q", id)
+				}
+			}
+			if data != "" {
+				var event cns.IPStateEvent
+				if err := json.Unmarshal([]byte(data), &event); err != nil {
+					return errors.Wrap(err, "failed to decode IPStateEvent")
+				}
+				if err := fn(event); err != nil {
+					return &watchFuncError{err: err}
+				}
+			}
+			id, data = "", ""
+		case strings.HasPrefix(line, "id:"):
+			id = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+		case strings.HasPrefix(line, "data:"):
+			data += strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		}
+	}
+	return errors.Wrap(scanner.Err(), "failed to read events")
+}
@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"testing"
@@ -19,11 +20,13 @@ import (
 	"github.com/Azure/azure-container-networking/cns"
 	"github.com/Azure/azure-container-networking/cns/common"
 	"github.com/Azure/azure-container-networking/cns/fakes"
+	"github.com/Azure/azure-container-networking/cns/hostmetadata"
 	"github.com/Azure/azure-container-networking/cns/logger"
 	"github.com/Azure/azure-container-networking/cns/restserver"
 	"github.com/Azure/azure-container-networking/cns/types"
 	"github.com/Azure/azure-container-networking/crd/nodenetworkconfig/api/v1alpha"
 	"github.com/Azure/azure-container-networking/log"
+	"github.com/Azure/azure-container-networking/otlp"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -44,6 +47,7 @@ const (
 
 var (
 	svc           *restserver.HTTPRestService
+	grpcURL       string
 	dnsServers    = []string{"8.8.8.8", "8.8.4.4"}
 	errBadRequest = errors.New("bad request")
 )
@@ -167,9 +171,11 @@ func TestMain(m *testing.M) {
 	}
 
 	logger.InitLogger(logName, 0, 0, tmpLogDir+"/")
-	config := common.ServiceConfig{}
+	grpcURL = "unix://" + tmpLogDir + "/cns-grpc.sock"
+	config := common.ServiceConfig{GRPCURL: grpcURL, UnixSocketMode: 0o600}
 
-	httpRestService, err := restserver.NewHTTPRestService(&config, &fakes.WireserverClientFake{}, &fakes.NMAgentClientFake{}, nil, nil, nil)
+	nma := &fakes.NMAgentClientFake{}
+	httpRestService, err := restserver.NewHTTPRestService(&config, hostmetadata.NewWireserver(&fakes.WireserverClientFake{}, nma), nma, nil, nil, nil)
 	svc = httpRestService.(*restserver.HTTPRestService)
 	svc.Name = "cns-test-server"
 	fakeNNC := v1alpha.NodeNetworkConfig{
@@ -402,7 +408,8 @@ func TestNew(t *testing.T) {
 			want: &Client{
 				routes: emptyRoutes,
 				client: &http.Client{
-					Timeout: 0,
+					Timeout:   0,
+					Transport: &otlp.Transport{},
 				},
 			},
 			wantErr: false,
@@ -413,7 +420,8 @@ func TestNew(t *testing.T) {
 			want: &Client{
 				routes: fqdnRoutes,
 				client: &http.Client{
-					Timeout: 0,
+					Timeout:   0,
+					Transport: &otlp.Transport{},
 				},
 			},
 			wantErr: false,
@@ -424,7 +432,8 @@ func TestNew(t *testing.T) {
 			want: &Client{
 				routes: fqdnWithPortRoutes,
 				client: &http.Client{
-					Timeout: 0,
+					Timeout:   0,
+					Transport: &otlp.Transport{},
 				},
 			},
 			wantErr: false,
@@ -2323,3 +2332,82 @@ func TestGetHomeAz(t *testing.T) {
 		})
 	}
 }
+
+func TestWatchIPAddresses(t *testing.T) {
+	watchIPAddress := "10.0.0.7"
+	returnCode := svc.CreateOrUpdateNetworkContainerInternal(&cns.CreateNetworkContainerRequest{
+		NetworkContainerType: dockerContainerType,
+		NetworkContainerid:   "watchTestNcId",
+		IPConfiguration: cns.IPConfiguration{
+			IPSubnet:         cns.IPSubnet{IPAddress: primaryIp, PrefixLength: subnetPrfixLength},
+			DNSServers:       dnsServers,
+			GatewayIPAddress: gatewayIp,
+		},
+		SecondaryIPConfigs: map[string]cns.SecondaryIPConfig{
+			"watch-test-ip": {IPAddress: watchIPAddress, NCVersion: -1},
+		},
+		Version: "-1",
+	})
+	require.Equal(t, types.Success, returnCode)
+
+	cnsClient, err := New("", DefaultTimeout)
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	list, err := cnsClient.GetIPAddressStatus(ctx, types.Available)
+	require.NoError(t, err)
+
+	orchestratorContext, err := json.Marshal(cns.KubernetesPodInfo{PodName: "watchpod", PodNamespace: "watchpodnamespace"})
+	require.NoError(t, err)
+	req := cns.IPConfigRequest{DesiredIPAddress: watchIPAddress, OrchestratorContext: orchestratorContext}
+	_, err = cnsClient.RequestIPAddress(ctx, req)
+	require.NoError(t, err)
+	require.NoError(t, cnsClient.ReleaseIPAddress(ctx, req))
+
+	// the events after the list are received in order
+	var events []cns.IPStateEvent
+	errDone := errors.New("done")
+	err = cnsClient.WatchIPAddresses(ctx, list.ResourceVersion, func(event cns.IPStateEvent) error {
+		events = append(events, event)
+		if len(events) == 2 {
+			return errDone
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, errDone)
+	assert.Equal(t, list.ResourceVersion+1, events[0].ResourceVersion)
+	assert.Equal(t, watchIPAddress, events[0].IPAddress)
+	assert.Equal(t, types.Available, events[0].PreviousState)
+	assert.Equal(t, types.Assigned, events[0].State)
+	assert.Equal(t, "watchpod", events[0].PodName)
+	assert.Equal(t, list.ResourceVersion+2, events[1].ResourceVersion)
+	assert.Equal(t, types.Available, events[1].State)
+
+	// a resource version which CNS doesn't have
+	err = cnsClient.WatchIPAddresses(ctx, events[1].ResourceVersion+100, func(cns.IPStateEvent) error { return nil })
+	require.ErrorIs(t, err, ErrResourceVersionTooOld)
+}
+
+func TestNewUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "cns.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	exp := &cns.GetHomeAzResponse{HomeAzResponse: cns.HomeAzResponse{IsSupported: true, HomeAz: 1}}
+	srv := &http.Server{ //nolint:gosec // test server
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, cns.GetHomeAz, r.URL.Path)
+			assert.NoError(t, json.NewEncoder(w).Encode(exp))
+		}),
+	}
+	go func() {
+		_ = srv.Serve(listener)
+	}()
+	defer srv.Close()
+
+	client, err := New("unix://"+socket, DefaultTimeout)
+	require.NoError(t, err)
+	got, err := client.GetHomeAz(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, exp, got)
+}
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/url"
+
+	"github.com/Azure/azure-container-networking/cns"
+	v1 "github.com/Azure/azure-container-networking/cns/grpc/v1"
+	"github.com/Azure/azure-container-networking/cns/types"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// GRPCClient is a client of the CNS gRPC API, with the same semantics as the HTTP Client.
+type GRPCClient struct {
+	conn *grpc.ClientConn
+	cli  v1.CNSClient
+}
+
+// NewGRPC returns a CNS gRPC client connected to the unix socket ("unix:///path") or tcp ("tcp://host:port") URL.
+// The connection uses TLS if tlsConfig is not nil.
+func NewGRPC(rawURL string, tlsConfig *tls.Config) (*GRPCClient, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse grpc url %s", rawURL)
+	}
+	address := u.Host + u.Path
+	creds := insecure.NewCredentials()
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	conn, err := grpc.Dial(address,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, u.Scheme, addr)
+		}),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to dial %s", rawURL)
+	}
+	return &GRPCClient{
+		conn: conn,
+		cli:  v1.NewCNSClient(conn),
+	}, nil
+}
+
+// Close closes the connection to CNS.
+func (c *GRPCClient) Close() error {
+	return errors.Wrap(c.conn.Close(), "failed to close grpc connection")
+}
+
+// GetNetworkConfiguration Request to get network config.
+func (c *GRPCClient) GetNetworkConfiguration(ctx context.Context, orchestratorContext []byte) (*cns.GetNetworkContainerResponse, error) {
+	out, err := c.cli.GetNetworkContainer(ctx, v1.GetNetworkContainerRequestFromCNS(&cns.GetNetworkContainerRequest{OrchestratorContext: orchestratorContext}))
+	if err != nil {
+		return nil, errors.Wrap(err, "grpc request failed")
+	}
+	resp := out.ToCNS()
+	if resp.Response.ReturnCode != 0 {
+		return nil, &CNSClientError{
+			Code: resp.Response.ReturnCode,
+			Err:  errors.New(resp.Response.Message),
+		}
+	}
+	return &resp, nil
+}
+
+// RequestIPAddress calls the requestIPAddress in CNS
+func (c *GRPCClient) RequestIPAddress(ctx context.Context, ipconfig cns.IPConfigRequest) (*cns.IPConfigResponse, error) {
+	out, err := c.cli.RequestIPConfig(ctx, v1.IPConfigRequestFromCNS(&ipconfig))
+	if err != nil {
+		return nil, errors.Wrap(err, "grpc request failed")
+	}
+	resp := out.ToCNS()
+	if resp.Response.ReturnCode != 0 {
+		return nil, errors.New(resp.Response.Message)
+	}
+	return &resp, nil
+}
+
+// ReleaseIPAddress calls releaseIPAddress on CNS, ipaddress ex: (10.0.0.1)
+func (c *GRPCClient) ReleaseIPAddress(ctx context.Context, ipconfig cns.IPConfigRequest) error {
+	resp, err := c.cli.ReleaseIPConfig(ctx, v1.IPConfigRequestFromCNS(&ipconfig))
+	if err != nil {
+		return errors.Wrap(err, "grpc request failed")
+	}
+	if resp.GetReturnCode() != 0 {
+		return errors.New(resp.GetMessage())
+	}
+	return nil
+}
+
+// GetIPAddressesMatchingStates gets all IP Addresses matching any of the states.
+func (c *GRPCClient) GetIPAddressesMatchingStates(ctx context.Context, stateFilter ...types.IPState) ([]cns.IPConfigurationStatus, error) {
+	if len(stateFilter) == 0 {
+		return nil, nil
+	}
+	out, err := c.cli.GetIPAddresses(ctx, v1.GetIPAddressesRequestFromCNS(&cns.GetIPAddressesRequest{IPConfigStateFilter: stateFilter}))
+	if err != nil {
+		return nil, errors.Wrap(err, "grpc request failed")
+	}
+	resp := out.ToCNS()
+	if resp.Response.ReturnCode != 0 {
+		return nil, errors.New(resp.Response.Message)
+	}
+	return resp.IPConfigurationStatus, nil
+}
+
+// WatchIPAddressesMatchingStates calls fn with all IP Addresses matching any of the states when the watch starts,
+// and again after IPs change state, until the context is cancelled or fn returns an error.
+func (c *GRPCClient) WatchIPAddressesMatchingStates(ctx context.Context, fn func([]cns.IPConfigurationStatus) error, stateFilter ...types.IPState) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.cli.WatchIPAddresses(ctx, v1.GetIPAddressesRequestFromCNS(&cns.GetIPAddressesRequest{IPConfigStateFilter: stateFilter}))
+	if err != nil {
+		return errors.Wrap(err, "failed to start watch")
+	}
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err() //nolint:wrapcheck // the caller's context error
+			}
+			return errors.Wrap(err, "failed to receive ip addresses")
+		}
+		if err := fn(resp.ToCNS().IPConfigurationStatus); err != nil {
+			return err
+		}
+	}
+}
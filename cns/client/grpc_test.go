@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-container-networking/cns"
+	"github.com/Azure/azure-container-networking/cns/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGRPCClientRequestAndRelease(t *testing.T) {
+	desiredIPAddress := "10.0.0.6"
+	// use another NC, since IPs of testNcId1 may be left assigned by other tests
+	returnCode := svc.CreateOrUpdateNetworkContainerInternal(&cns.CreateNetworkContainerRequest{
+		NetworkContainerType: dockerContainerType,
+		NetworkContainerid:   "grpcTestNcId",
+		IPConfiguration: cns.IPConfiguration{
+			IPSubnet:         cns.IPSubnet{IPAddress: primaryIp, PrefixLength: subnetPrfixLength},
+			DNSServers:       dnsServers,
+			GatewayIPAddress: gatewayIp,
+		},
+		SecondaryIPConfigs: map[string]cns.SecondaryIPConfig{
+			"grpc-test-ip": {IPAddress: desiredIPAddress, NCVersion: -1},
+		},
+		Version: "-1",
+	})
+	require.Equal(t, types.Success, returnCode)
+
+	cnsClient, err := NewGRPC(grpcURL, nil)
+	require.NoError(t, err)
+	defer cnsClient.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	orchestratorContext, err := json.Marshal(cns.KubernetesPodInfo{PodName: "grpcpod", PodNamespace: "grpcpodnamespace"})
+	require.NoError(t, err)
+	req := cns.IPConfigRequest{DesiredIPAddress: desiredIPAddress, OrchestratorContext: orchestratorContext}
+
+	// the watch sends the current IPs, and again after the IP is assigned
+	errDone := errors.New("done")
+	watched := make(chan []cns.IPConfigurationStatus, 1)
+	watchErr := make(chan error, 1)
+	go func() {
+		watchErr <- cnsClient.WatchIPAddressesMatchingStates(ctx, func(ips []cns.IPConfigurationStatus) error {
+			select {
+			case watched <- ips:
+			default:
+			}
+			for _, ip := range ipAddresses(ips) {
+				if ip == desiredIPAddress {
+					return errDone
+				}
+			}
+			return nil
+		}, types.Assigned)
+	}()
+	select {
+	case <-watched:
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the initial watch event")
+	}
+
+	resp, err := cnsClient.RequestIPAddress(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, desiredIPAddress, resp.PodIpInfo.PodIPConfig.IPAddress)
+	assert.Equal(t, primaryIp, resp.PodIpInfo.NetworkContainerPrimaryIPConfig.IPSubnet.IPAddress)
+
+	ips, err := cnsClient.GetIPAddressesMatchingStates(ctx, types.Assigned)
+	require.NoError(t, err)
+	assert.Contains(t, ipAddresses(ips), desiredIPAddress)
+
+	require.ErrorIs(t, <-watchErr, errDone)
+
+	require.NoError(t, cnsClient.ReleaseIPAddress(ctx, req))
+	ips, err = cnsClient.GetIPAddressesMatchingStates(ctx, types.Assigned)
+	require.NoError(t, err)
+	assert.NotContains(t, ipAddresses(ips), desiredIPAddress)
+}
+
+func ipAddresses(ips []cns.IPConfigurationStatus) []string {
+	addresses := make([]string, len(ips))
+	for i := range ips {
+		addresses[i] = ips[i].IPAddress
+	}
+	return addresses
+}
@@ -24,6 +24,8 @@ type cniConflist struct { //nolint:unused,deadcode // used in linux
 // V4OverlayGenerator generates the Azure CNI conflist for the ipv4 Overlay scenario
 type V4OverlayGenerator struct {
 	Writer io.WriteCloser
+	// CNSURL is the URL of CNS for the CNI plugin, such as its unix socket, which uses the default URL if empty
+	CNSURL string
 }
 
 func (v *V4OverlayGenerator) Close() error {
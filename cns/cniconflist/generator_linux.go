@@ -34,6 +34,7 @@ func (v *V4OverlayGenerator) Generate() error {
 				Mode:              cninet.OpModeTransparent,
 				ExecutionMode:     string(util.V4Swift),
 				IPsToRouteViaHost: []string{nodeLocalDNSIP},
+				CNSUrl:            v.CNSURL,
 				IPAM: cni.IPAM{
 					Type: network.AzureCNS,
 					Mode: string(util.V4Overlay),
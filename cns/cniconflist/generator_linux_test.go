@@ -32,6 +32,20 @@ func TestGenerateV4OverlayConflist(t *testing.T) {
 	assert.Equal(t, removeNewLines(fixtureBytes), removeNewLines(buffer.Bytes()))
 }
 
+func TestGenerateV4OverlayConflistWithCNSURL(t *testing.T) {
+	fixture := "testdata/fixtures/azure-linux-swift-overlay-unix-socket.conflist"
+
+	buffer := new(bytes.Buffer)
+	g := cniconflist.V4OverlayGenerator{Writer: &bufferWriteCloser{buffer}, CNSURL: "unix:///var/run/azure-cns/cns.sock"}
+	err := g.Generate()
+	assert.NoError(t, err)
+
+	fixtureBytes, err := os.ReadFile(fixture)
+	assert.NoError(t, err)
+
+	assert.Equal(t, removeNewLines(fixtureBytes), removeNewLines(buffer.Bytes()))
+}
+
 // removeNewLines will remove the newlines and carriage returns from the byte slice
 func removeNewLines(b []byte) []byte {
 	var bb []byte //nolint:prealloc // can't prealloc since we don't know how many bytes will get removed
@@ -5,11 +5,14 @@ package common
 
 import (
 	"errors"
+	"net/http"
+	"os"
 
 	"github.com/Azure/azure-container-networking/cns/logger"
 	acn "github.com/Azure/azure-container-networking/common"
 	"github.com/Azure/azure-container-networking/server/tls"
 	"github.com/Azure/azure-container-networking/store"
+	"google.golang.org/grpc"
 )
 
 // Service implements behavior common to all services.
@@ -40,6 +43,20 @@ type ServiceConfig struct {
 	Store       store.KeyValueStore
 	ChannelMode string
 	TlsSettings tls.TlsSettings
+	// GRPCURL is the unix socket or tcp URL of the gRPC listener, which is disabled if it is empty
+	GRPCURL string
+	// UnixSocketPath is the path of the unix socket listener of the API, which is disabled if it is empty
+	UnixSocketPath string
+	// UnixSocketMode is the file mode of the unix sockets of the API and of the gRPC server
+	UnixSocketMode os.FileMode
+	// UnixSocketOnly disables the TCP listener of the API, so that it is only served on the unix socket
+	UnixSocketOnly bool
+	// Authorize wraps the handlers of the listener to authorize their clients, which are all allowed if it is nil
+	Authorize func(http.Handler) http.Handler
+	// AuthorizeUnary and AuthorizeStream authorize the clients of the gRPC listener, which refuses to start
+	// without them if Authorize is set
+	AuthorizeUnary  grpc.UnaryServerInterceptor
+	AuthorizeStream grpc.StreamServerInterceptor
 }
 
 // NewService creates a new Service object.
@@ -20,21 +20,36 @@ const (
 
 type CNSConfig struct {
 	ChannelMode                          string
+	EnableIPAMStatePublishing            bool
+	EnableIPLeakGC                       bool
 	EnablePprof                          bool
 	EnableSubnetScarcity                 bool
+	GRPCURL                              string
+	HostMetadataSettings                 HostMetadataSettings
+	IPLeakGCGracePeriodSecs              int
+	IPLeakGCIntervalSecs                 int
 	InitializeFromCNI                    bool
+	LogLevels                            map[string]string
 	ManagedSettings                      ManagedSettings
 	MetricsBindAddress                   string
+	NMAgentCircuitBreakerOpenSecs        int
+	NMAgentCircuitBreakerThreshold       int
+	NMAgentRateBurst                     int
+	NMAgentRateLimit                     float64
 	SyncHostNCTimeoutMs                  int
 	SyncHostNCVersionIntervalMs          int
 	TLSCertificatePath                   string
+	TLSClientCAPath                      string
 	TLSEndpoint                          string
+	TLSKeyPath                           string
 	TLSPort                              string
 	TLSSubjectName                       string
 	TelemetrySettings                    TelemetrySettings
+	UnixSocketSettings                   UnixSocketSettings
 	UseHTTPS                             bool
 	WireserverIP                         string
 	KeyVaultSettings                     KeyVaultSettings
+	AuthorizationSettings                AuthorizationSettings
 	MSISettings                          MSISettings
 	ProgramSNATIPTables                  bool
 	ManageEndpointState                  bool
@@ -69,8 +84,47 @@ type TelemetrySettings struct {
 	SnapshotIntervalInMins int
 	// AppInsightsInstrumentationKey allows the user to override the default appinsights ikey
 	AppInsightsInstrumentationKey string
+	// Exporter selects where the telemetry is sent: AppInsights (the default) or OTLP.
+	Exporter TelemetryExporter
+	// OTLPEndpoint is the base URL of the OTLP/HTTP receiver of the collector, such as http://otel-collector:4318
+	OTLPEndpoint string
+	// OTLPHeaders are added to the OTLP export requests, in the key1=value1,key2=value2 format
+	OTLPHeaders string
 }
 
+// TelemetryExporter is the destination of the CNS telemetry.
+type TelemetryExporter string
+
+const (
+	// TelemetryExporterAppInsights sends the logs, events and metrics of CNS to Application Insights.
+	TelemetryExporterAppInsights TelemetryExporter = "AppInsights"
+	// TelemetryExporterOTLP sends the logs, events and metrics of CNS, the traces of its IP requests, and its
+	// Prometheus metrics to an OpenTelemetry collector.
+	TelemetryExporterOTLP TelemetryExporter = "OTLP"
+)
+
+// HostMetadataSettings selects where CNS gets the metadata of its host: its primary interface and gateway, its home
+// AZ, and the NMAgent APIs supported on it.
+type HostMetadataSettings struct {
+	// Provider is Wireserver (the default), IMDS or Static.
+	Provider HostMetadataProvider
+	// StaticFilePath is the JSON file of the metadata of the Static provider.
+	StaticFilePath string
+}
+
+// HostMetadataProvider is the source of the metadata of the host.
+type HostMetadataProvider string
+
+const (
+	// HostMetadataProviderWireserver gets the metadata from wireserver and NMAgent on Azure VMs.
+	HostMetadataProviderWireserver HostMetadataProvider = "Wireserver"
+	// HostMetadataProviderIMDS gets the metadata from the Azure Instance Metadata Service, on Azure VMs which can't
+	// reach wireserver.
+	HostMetadataProviderIMDS HostMetadataProvider = "IMDS"
+	// HostMetadataProviderStatic reads the metadata from a file, for hosts outside of Azure.
+	HostMetadataProviderStatic HostMetadataProvider = "Static"
+)
+
 type ManagedSettings struct {
 	PrivateEndpoint           string
 	InfrastructureNetworkID   string
@@ -88,6 +142,32 @@ type KeyVaultSettings struct {
 	RefreshIntervalInHrs int
 }
 
+// UnixSocketSettings configures the unix socket listener of the CNS API, for the clients on the node.
+type UnixSocketSettings struct {
+	// Path of the socket, which is not created if it is empty.
+	Path string
+	// Mode is the octal file mode of the socket, restricting the local users which may connect to it.
+	Mode string
+	// Exclusive disables the TCP listener of the CNS API, so that the clients on the node must use the socket.
+	// It can't be set on nodes with azure-ipam, which only reaches CNS over TCP at localhost:10090.
+	Exclusive bool
+}
+
+// AuthorizationSettings restricts the clients of the CNS API to the route groups allowed by its rules.
+// Requests are not authorized when it is disabled.
+type AuthorizationSettings struct {
+	Enabled bool
+	Rules   []AuthorizationRule
+}
+
+// AuthorizationRule allows clients with any of the identities to call the routes of the route groups.
+// Identities are "CN=<common name>" or "SAN=<DNS name, IP, URI or email>" of a verified client certificate,
+// "UID=<uid>" of a process connected over a unix socket, or "*" for any client.
+type AuthorizationRule struct {
+	Identities  []string
+	RouteGroups []string
+}
+
 func getConfigFilePath(cmdLineConfigPath string) (string, error) {
 	// If config path is set from cmd line, return that
 	if cmdLineConfigPath != "" {
@@ -156,6 +236,10 @@ func setTelemetrySettingDefaults(telemetrySettings *TelemetrySettings) {
 	if telemetrySettings.SnapshotIntervalInMins == 0 {
 		telemetrySettings.SnapshotIntervalInMins = 60
 	}
+
+	if telemetrySettings.Exporter == "" {
+		telemetrySettings.Exporter = TelemetryExporterAppInsights
+	}
 }
 
 // set managed setting defaults
@@ -171,11 +255,25 @@ func setKeyVaultSettingsDefaults(kvs *KeyVaultSettings) {
 	}
 }
 
+func setUnixSocketSettingsDefaults(uss *UnixSocketSettings) {
+	if uss.Mode == "" {
+		uss.Mode = "0600"
+	}
+}
+
+func setHostMetadataSettingsDefaults(hms *HostMetadataSettings) {
+	if hms.Provider == "" {
+		hms.Provider = HostMetadataProviderWireserver
+	}
+}
+
 // SetCNSConfigDefaults set default values of CNS config if not specified
 func SetCNSConfigDefaults(config *CNSConfig) {
 	setTelemetrySettingDefaults(&config.TelemetrySettings)
 	setManagedSettingDefaults(&config.ManagedSettings)
 	setKeyVaultSettingsDefaults(&config.KeyVaultSettings)
+	setUnixSocketSettingsDefaults(&config.UnixSocketSettings)
+	setHostMetadataSettingsDefaults(&config.HostMetadataSettings)
 
 	if config.ChannelMode == "" {
 		config.ChannelMode = cns.Direct
@@ -189,6 +287,25 @@ func SetCNSConfigDefaults(config *CNSConfig) {
 	if config.SyncHostNCTimeoutMs == 0 {
 		config.SyncHostNCTimeoutMs = 500 //nolint:gomnd // default times
 	}
+	if config.IPLeakGCIntervalSecs == 0 {
+		config.IPLeakGCIntervalSecs = 60 //nolint:gomnd // default times
+	}
+	if config.IPLeakGCGracePeriodSecs == 0 {
+		config.IPLeakGCGracePeriodSecs = 300 //nolint:gomnd // default times
+	}
+	// the NMAgent rate limiter and circuit breaker are disabled with negative values
+	if config.NMAgentRateLimit == 0 {
+		config.NMAgentRateLimit = 10 //nolint:gomnd // default requests per second
+	}
+	if config.NMAgentRateBurst == 0 {
+		config.NMAgentRateBurst = 20 //nolint:gomnd // default burst
+	}
+	if config.NMAgentCircuitBreakerThreshold == 0 {
+		config.NMAgentCircuitBreakerThreshold = 5 //nolint:gomnd // default consecutive failures
+	}
+	if config.NMAgentCircuitBreakerOpenSecs == 0 {
+		config.NMAgentCircuitBreakerOpenSecs = 30 //nolint:gomnd // default times
+	}
 	if config.PopulateHomeAzCacheRetryIntervalSecs == 0 {
 		// set the default PopulateHomeAzCache retry interval to 15 seconds
 		config.PopulateHomeAzCacheRetryIntervalSecs = 15
@@ -118,6 +118,7 @@ func TestSetTelemetrySettingDefaults(t *testing.T) {
 				TelemetryBatchSizeBytes:      32768,
 				HeartBeatIntervalInMins:      30,
 				SnapshotIntervalInMins:       60,
+				Exporter:                     TelemetryExporterAppInsights,
 			},
 		},
 		{
@@ -128,6 +129,7 @@ func TestSetTelemetrySettingDefaults(t *testing.T) {
 				TelemetryBatchSizeBytes:      5,
 				HeartBeatIntervalInMins:      6,
 				SnapshotIntervalInMins:       7,
+				Exporter:                     TelemetryExporterOTLP,
 			},
 			want: TelemetrySettings{
 				RefreshIntervalInSecs:        3,
@@ -135,6 +137,7 @@ func TestSetTelemetrySettingDefaults(t *testing.T) {
 				TelemetryBatchSizeBytes:      5,
 				HeartBeatIntervalInMins:      6,
 				SnapshotIntervalInMins:       7,
+				Exporter:                     TelemetryExporterOTLP,
 			},
 		},
 	}
@@ -202,11 +205,24 @@ func TestSetCNSConfigDefaults(t *testing.T) {
 					HeartBeatIntervalInMins:      30,
 					RefreshIntervalInSecs:        15,
 					SnapshotIntervalInMins:       60,
+					Exporter:                     TelemetryExporterAppInsights,
 				},
 				KeyVaultSettings: KeyVaultSettings{
 					RefreshIntervalInHrs: 12,
 				},
+				UnixSocketSettings: UnixSocketSettings{
+					Mode: "0600",
+				},
+				HostMetadataSettings: HostMetadataSettings{
+					Provider: HostMetadataProviderWireserver,
+				},
 				PopulateHomeAzCacheRetryIntervalSecs: 15,
+				IPLeakGCIntervalSecs:                 60,
+				IPLeakGCGracePeriodSecs:              300,
+				NMAgentRateLimit:                     10,
+				NMAgentRateBurst:                     20,
+				NMAgentCircuitBreakerThreshold:       5,
+				NMAgentCircuitBreakerOpenSecs:        30,
 			},
 		},
 		{
@@ -225,11 +241,24 @@ func TestSetCNSConfigDefaults(t *testing.T) {
 					HeartBeatIntervalInMins:      3,
 					RefreshIntervalInSecs:        1,
 					SnapshotIntervalInMins:       6,
+					Exporter:                     TelemetryExporterOTLP,
 				},
 				KeyVaultSettings: KeyVaultSettings{
 					RefreshIntervalInHrs: 3,
 				},
+				UnixSocketSettings: UnixSocketSettings{
+					Mode: "0660",
+				},
+				HostMetadataSettings: HostMetadataSettings{
+					Provider: HostMetadataProviderStatic,
+				},
 				PopulateHomeAzCacheRetryIntervalSecs: 10,
+				IPLeakGCIntervalSecs:                 10,
+				IPLeakGCGracePeriodSecs:              10,
+				NMAgentRateLimit:                     -1,
+				NMAgentRateBurst:                     1,
+				NMAgentCircuitBreakerThreshold:       -1,
+				NMAgentCircuitBreakerOpenSecs:        10,
 			},
 			want: CNSConfig{
 				ChannelMode: "Other",
@@ -245,11 +274,24 @@ func TestSetCNSConfigDefaults(t *testing.T) {
 					HeartBeatIntervalInMins:      3,
 					RefreshIntervalInSecs:        1,
 					SnapshotIntervalInMins:       6,
+					Exporter:                     TelemetryExporterOTLP,
 				},
 				KeyVaultSettings: KeyVaultSettings{
 					RefreshIntervalInHrs: 3,
 				},
+				UnixSocketSettings: UnixSocketSettings{
+					Mode: "0660",
+				},
+				HostMetadataSettings: HostMetadataSettings{
+					Provider: HostMetadataProviderStatic,
+				},
 				PopulateHomeAzCacheRetryIntervalSecs: 10,
+				IPLeakGCIntervalSecs:                 10,
+				IPLeakGCGracePeriodSecs:              10,
+				NMAgentRateLimit:                     -1,
+				NMAgentRateBurst:                     1,
+				NMAgentCircuitBreakerThreshold:       -1,
+				NMAgentCircuitBreakerOpenSecs:        10,
 			},
 		},
 	}
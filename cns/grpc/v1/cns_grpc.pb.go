@@ -0,0 +1,281 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: cns.proto
+
+package v1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// CNSClient is the client API for CNS service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CNSClient interface {
+	RequestIPConfig(ctx context.Context, in *IPConfigRequest, opts ...grpc.CallOption) (*IPConfigResponse, error)
+	ReleaseIPConfig(ctx context.Context, in *IPConfigRequest, opts ...grpc.CallOption) (*Response, error)
+	GetNetworkContainer(ctx context.Context, in *GetNetworkContainerRequest, opts ...grpc.CallOption) (*GetNetworkContainerResponse, error)
+	GetIPAddresses(ctx context.Context, in *GetIPAddressesRequest, opts ...grpc.CallOption) (*GetIPAddressStatusResponse, error)
+	// WatchIPAddresses streams the IPConfigs matching the request when the
+	// watch starts, and again whenever IPs change state.
+	WatchIPAddresses(ctx context.Context, in *GetIPAddressesRequest, opts ...grpc.CallOption) (CNS_WatchIPAddressesClient, error)
+}
+
+type cNSClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCNSClient(cc grpc.ClientConnInterface) CNSClient {
+	return &cNSClient{cc}
+}
+
+func (c *cNSClient) RequestIPConfig(ctx context.Context, in *IPConfigRequest, opts ...grpc.CallOption) (*IPConfigResponse, error) {
+	out := new(IPConfigResponse)
+	err := c.cc.Invoke(ctx, "/cns.v1.CNS/RequestIPConfig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cNSClient) ReleaseIPConfig(ctx context.Context, in *IPConfigRequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/cns.v1.CNS/ReleaseIPConfig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cNSClient) GetNetworkContainer(ctx context.Context, in *GetNetworkContainerRequest, opts ...grpc.CallOption) (*GetNetworkContainerResponse, error) {
+	out := new(GetNetworkContainerResponse)
+	err := c.cc.Invoke(ctx, "/cns.v1.CNS/GetNetworkContainer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cNSClient) GetIPAddresses(ctx context.Context, in *GetIPAddressesRequest, opts ...grpc.CallOption) (*GetIPAddressStatusResponse, error) {
+	out := new(GetIPAddressStatusResponse)
+	err := c.cc.Invoke(ctx, "/cns.v1.CNS/GetIPAddresses", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cNSClient) WatchIPAddresses(ctx context.Context, in *GetIPAddressesRequest, opts ...grpc.CallOption) (CNS_WatchIPAddressesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &CNS_ServiceDesc.Streams[0], "/cns.v1.CNS/WatchIPAddresses", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &cNSWatchIPAddressesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type CNS_WatchIPAddressesClient interface {
+	Recv() (*GetIPAddressStatusResponse, error)
+	grpc.ClientStream
+}
+
+type cNSWatchIPAddressesClient struct {
+	grpc.ClientStream
+}
+
+func (x *cNSWatchIPAddressesClient) Recv() (*GetIPAddressStatusResponse, error) {
+	m := new(GetIPAddressStatusResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// CNSServer is the server API for CNS service.
+// All implementations must embed UnimplementedCNSServer
+// for forward compatibility
+type CNSServer interface {
+	RequestIPConfig(context.Context, *IPConfigRequest) (*IPConfigResponse, error)
+	ReleaseIPConfig(context.Context, *IPConfigRequest) (*Response, error)
+	GetNetworkContainer(context.Context, *GetNetworkContainerRequest) (*GetNetworkContainerResponse, error)
+	GetIPAddresses(context.Context, *GetIPAddressesRequest) (*GetIPAddressStatusResponse, error)
+	// WatchIPAddresses streams the IPConfigs matching the request when the
+	// watch starts, and again whenever IPs change state.
+	WatchIPAddresses(*GetIPAddressesRequest, CNS_WatchIPAddressesServer) error
+	mustEmbedUnimplementedCNSServer()
+}
+
+// UnimplementedCNSServer must be embedded to have forward compatible implementations.
+type UnimplementedCNSServer struct {
+}
+
+func (UnimplementedCNSServer) RequestIPConfig(context.Context, *IPConfigRequest) (*IPConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestIPConfig not implemented")
+}
+func (UnimplementedCNSServer) ReleaseIPConfig(context.Context, *IPConfigRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReleaseIPConfig not implemented")
+}
+func (UnimplementedCNSServer) GetNetworkContainer(context.Context, *GetNetworkContainerRequest) (*GetNetworkContainerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNetworkContainer not implemented")
+}
+func (UnimplementedCNSServer) GetIPAddresses(context.Context, *GetIPAddressesRequest) (*GetIPAddressStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetIPAddresses not implemented")
+}
+func (UnimplementedCNSServer) WatchIPAddresses(*GetIPAddressesRequest, CNS_WatchIPAddressesServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchIPAddresses not implemented")
+}
+func (UnimplementedCNSServer) mustEmbedUnimplementedCNSServer() {}
+
+// UnsafeCNSServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CNSServer will
+// result in compilation errors.
+type UnsafeCNSServer interface {
+	mustEmbedUnimplementedCNSServer()
+}
+
+func RegisterCNSServer(s grpc.ServiceRegistrar, srv CNSServer) {
+	s.RegisterService(&CNS_ServiceDesc, srv)
+}
+
+func _CNS_RequestIPConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IPConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CNSServer).RequestIPConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cns.v1.CNS/RequestIPConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CNSServer).RequestIPConfig(ctx, req.(*IPConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CNS_ReleaseIPConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IPConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CNSServer).ReleaseIPConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cns.v1.CNS/ReleaseIPConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CNSServer).ReleaseIPConfig(ctx, req.(*IPConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CNS_GetNetworkContainer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNetworkContainerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CNSServer).GetNetworkContainer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cns.v1.CNS/GetNetworkContainer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CNSServer).GetNetworkContainer(ctx, req.(*GetNetworkContainerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CNS_GetIPAddresses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetIPAddressesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CNSServer).GetIPAddresses(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cns.v1.CNS/GetIPAddresses",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CNSServer).GetIPAddresses(ctx, req.(*GetIPAddressesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CNS_WatchIPAddresses_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetIPAddressesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CNSServer).WatchIPAddresses(m, &cNSWatchIPAddressesServer{stream})
+}
+
+type CNS_WatchIPAddressesServer interface {
+	Send(*GetIPAddressStatusResponse) error
+	grpc.ServerStream
+}
+
+type cNSWatchIPAddressesServer struct {
+	grpc.ServerStream
+}
+
+func (x *cNSWatchIPAddressesServer) Send(m *GetIPAddressStatusResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// CNS_ServiceDesc is the grpc.ServiceDesc for CNS service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CNS_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "cns.v1.CNS",
+	HandlerType: (*CNSServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RequestIPConfig",
+			Handler:    _CNS_RequestIPConfig_Handler,
+		},
+		{
+			MethodName: "ReleaseIPConfig",
+			Handler:    _CNS_ReleaseIPConfig_Handler,
+		},
+		{
+			MethodName: "GetNetworkContainer",
+			Handler:    _CNS_GetNetworkContainer_Handler,
+		},
+		{
+			MethodName: "GetIPAddresses",
+			Handler:    _CNS_GetIPAddresses_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchIPAddresses",
+			Handler:       _CNS_WatchIPAddresses_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "cns.proto",
+}
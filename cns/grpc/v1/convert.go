@@ -0,0 +1,268 @@
+package v1
+
+import (
+	"time"
+
+	"github.com/Azure/azure-container-networking/cns"
+	"github.com/Azure/azure-container-networking/cns/types"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// The messages of the gRPC API mirror the types of the HTTP API, and are converted to and from them at the edges,
+// so that both APIs are served by the same logic.
+
+// ResponseFromCNS converts a cns.Response to a Response.
+func ResponseFromCNS(r *cns.Response) *Response {
+	return &Response{
+		ReturnCode: int32(r.ReturnCode),
+		Message:    r.Message,
+	}
+}
+
+// ToCNS converts the Response to a cns.Response.
+func (x *Response) ToCNS() cns.Response {
+	return cns.Response{
+		ReturnCode: types.ResponseCode(x.GetReturnCode()),
+		Message:    x.GetMessage(),
+	}
+}
+
+func ipSubnetFromCNS(s *cns.IPSubnet) *IPSubnet {
+	return &IPSubnet{
+		IpAddress:    s.IPAddress,
+		PrefixLength: uint32(s.PrefixLength),
+	}
+}
+
+func (x *IPSubnet) toCNS() cns.IPSubnet {
+	return cns.IPSubnet{
+		IPAddress:    x.GetIpAddress(),
+		PrefixLength: uint8(x.GetPrefixLength()),
+	}
+}
+
+func ipConfigurationFromCNS(c *cns.IPConfiguration) *IPConfiguration {
+	return &IPConfiguration{
+		IpSubnet:         ipSubnetFromCNS(&c.IPSubnet),
+		DnsServers:       c.DNSServers,
+		GatewayIpAddress: c.GatewayIPAddress,
+	}
+}
+
+func (x *IPConfiguration) toCNS() cns.IPConfiguration {
+	return cns.IPConfiguration{
+		IPSubnet:         x.GetIpSubnet().toCNS(),
+		DNSServers:       x.GetDnsServers(),
+		GatewayIPAddress: x.GetGatewayIpAddress(),
+	}
+}
+
+// IPConfigRequestFromCNS converts a cns.IPConfigRequest to an IPConfigRequest.
+func IPConfigRequestFromCNS(r *cns.IPConfigRequest) *IPConfigRequest {
+	return &IPConfigRequest{
+		DesiredIpAddress:    r.DesiredIPAddress,
+		PodInterfaceId:      r.PodInterfaceID,
+		InfraContainerId:    r.InfraContainerID,
+		OrchestratorContext: r.OrchestratorContext,
+		Ifname:              r.Ifname,
+	}
+}
+
+// ToCNS converts the IPConfigRequest to a cns.IPConfigRequest.
+func (x *IPConfigRequest) ToCNS() cns.IPConfigRequest {
+	return cns.IPConfigRequest{
+		DesiredIPAddress:    x.GetDesiredIpAddress(),
+		PodInterfaceID:      x.GetPodInterfaceId(),
+		InfraContainerID:    x.GetInfraContainerId(),
+		OrchestratorContext: x.GetOrchestratorContext(),
+		Ifname:              x.GetIfname(),
+	}
+}
+
+// IPConfigResponseFromCNS converts a cns.IPConfigResponse to an IPConfigResponse.
+func IPConfigResponseFromCNS(r *cns.IPConfigResponse) *IPConfigResponse {
+	host := r.PodIpInfo.HostPrimaryIPInfo
+	return &IPConfigResponse{
+		PodIpInfo: &PodIPInfo{
+			PodIpConfig:                     ipSubnetFromCNS(&r.PodIpInfo.PodIPConfig),
+			NetworkContainerPrimaryIpConfig: ipConfigurationFromCNS(&r.PodIpInfo.NetworkContainerPrimaryIPConfig),
+			HostPrimaryIpInfo: &HostIPInfo{
+				Gateway:   host.Gateway,
+				PrimaryIp: host.PrimaryIP,
+				Subnet:    host.Subnet,
+			},
+		},
+		Response: ResponseFromCNS(&r.Response),
+	}
+}
+
+// ToCNS converts the IPConfigResponse to a cns.IPConfigResponse.
+func (x *IPConfigResponse) ToCNS() cns.IPConfigResponse {
+	info := x.GetPodIpInfo()
+	host := info.GetHostPrimaryIpInfo()
+	return cns.IPConfigResponse{
+		PodIpInfo: cns.PodIpInfo{
+			PodIPConfig:                     info.GetPodIpConfig().toCNS(),
+			NetworkContainerPrimaryIPConfig: info.GetNetworkContainerPrimaryIpConfig().toCNS(),
+			HostPrimaryIPInfo: cns.HostIPInfo{
+				Gateway:   host.GetGateway(),
+				PrimaryIP: host.GetPrimaryIp(),
+				Subnet:    host.GetSubnet(),
+			},
+		},
+		Response: x.GetResponse().ToCNS(),
+	}
+}
+
+// GetNetworkContainerRequestFromCNS converts a cns.GetNetworkContainerRequest to a GetNetworkContainerRequest.
+func GetNetworkContainerRequestFromCNS(r *cns.GetNetworkContainerRequest) *GetNetworkContainerRequest {
+	return &GetNetworkContainerRequest{
+		NetworkContainerId:  r.NetworkContainerid,
+		OrchestratorContext: r.OrchestratorContext,
+	}
+}
+
+// ToCNS converts the GetNetworkContainerRequest to a cns.GetNetworkContainerRequest.
+func (x *GetNetworkContainerRequest) ToCNS() cns.GetNetworkContainerRequest {
+	return cns.GetNetworkContainerRequest{
+		NetworkContainerid:  x.GetNetworkContainerId(),
+		OrchestratorContext: x.GetOrchestratorContext(),
+	}
+}
+
+// GetNetworkContainerResponseFromCNS converts a cns.GetNetworkContainerResponse to a GetNetworkContainerResponse.
+func GetNetworkContainerResponseFromCNS(r *cns.GetNetworkContainerResponse) *GetNetworkContainerResponse {
+	resp := &GetNetworkContainerResponse{
+		NetworkContainerId: r.NetworkContainerID,
+		IpConfiguration:    ipConfigurationFromCNS(&r.IPConfiguration),
+		MultiTenancyInfo: &MultiTenancyInfo{
+			EncapType: r.MultiTenancyInfo.EncapType,
+			Id:        int64(r.MultiTenancyInfo.ID),
+		},
+		PrimaryInterfaceIdentifier: r.PrimaryInterfaceIdentifier,
+		LocalIpConfiguration:       ipConfigurationFromCNS(&r.LocalIPConfiguration),
+		Response:                   ResponseFromCNS(&r.Response),
+		AllowHostToNcCommunication: r.AllowHostToNCCommunication,
+		AllowNcToHostCommunication: r.AllowNCToHostCommunication,
+	}
+	for i := range r.Routes {
+		resp.Routes = append(resp.Routes, &Route{
+			IpAddress:        r.Routes[i].IPAddress,
+			GatewayIpAddress: r.Routes[i].GatewayIPAddress,
+			InterfaceToUse:   r.Routes[i].InterfaceToUse,
+		})
+	}
+	for i := range r.CnetAddressSpace {
+		resp.CnetAddressSpace = append(resp.CnetAddressSpace, ipSubnetFromCNS(&r.CnetAddressSpace[i]))
+	}
+	return resp
+}
+
+// ToCNS converts the GetNetworkContainerResponse to a cns.GetNetworkContainerResponse.
+func (x *GetNetworkContainerResponse) ToCNS() cns.GetNetworkContainerResponse {
+	resp := cns.GetNetworkContainerResponse{
+		NetworkContainerID: x.GetNetworkContainerId(),
+		IPConfiguration:    x.GetIpConfiguration().toCNS(),
+		MultiTenancyInfo: cns.MultiTenancyInfo{
+			EncapType: x.GetMultiTenancyInfo().GetEncapType(),
+			ID:        int(x.GetMultiTenancyInfo().GetId()),
+		},
+		PrimaryInterfaceIdentifier: x.GetPrimaryInterfaceIdentifier(),
+		LocalIPConfiguration:       x.GetLocalIpConfiguration().toCNS(),
+		Response:                   x.GetResponse().ToCNS(),
+		AllowHostToNCCommunication: x.GetAllowHostToNcCommunication(),
+		AllowNCToHostCommunication: x.GetAllowNcToHostCommunication(),
+	}
+	for _, route := range x.GetRoutes() {
+		resp.Routes = append(resp.Routes, cns.Route{
+			IPAddress:        route.GetIpAddress(),
+			GatewayIPAddress: route.GetGatewayIpAddress(),
+			InterfaceToUse:   route.GetInterfaceToUse(),
+		})
+	}
+	for _, subnet := range x.GetCnetAddressSpace() {
+		resp.CnetAddressSpace = append(resp.CnetAddressSpace, subnet.toCNS())
+	}
+	return resp
+}
+
+// GetIPAddressesRequestFromCNS converts a cns.GetIPAddressesRequest to a GetIPAddressesRequest.
+func GetIPAddressesRequestFromCNS(r *cns.GetIPAddressesRequest) *GetIPAddressesRequest {
+	req := &GetIPAddressesRequest{}
+	for _, state := range r.IPConfigStateFilter {
+		req.IpConfigStateFilter = append(req.IpConfigStateFilter, string(state))
+	}
+	return req
+}
+
+// ToCNS converts the GetIPAddressesRequest to a cns.GetIPAddressesRequest.
+func (x *GetIPAddressesRequest) ToCNS() cns.GetIPAddressesRequest {
+	req := cns.GetIPAddressesRequest{}
+	for _, state := range x.GetIpConfigStateFilter() {
+		req.IPConfigStateFilter = append(req.IPConfigStateFilter, types.IPState(state))
+	}
+	return req
+}
+
+func ipConfigurationStatusFromCNS(s *cns.IPConfigurationStatus) *IPConfigurationStatus {
+	status := &IPConfigurationStatus{
+		Id:        s.ID,
+		IpAddress: s.IPAddress,
+		Ncid:      s.NCID,
+		State:     string(s.GetState()),
+	}
+	if !s.LastStateTransition.IsZero() {
+		status.LastStateTransition = timestamppb.New(s.LastStateTransition)
+	}
+	if s.PodInfo != nil {
+		status.PodInfo = &PodInfo{
+			InfraContainerId: s.PodInfo.InfraContainerID(),
+			InterfaceId:      s.PodInfo.InterfaceID(),
+			Name:             s.PodInfo.Name(),
+			Namespace:        s.PodInfo.Namespace(),
+		}
+	}
+	return status
+}
+
+func (x *IPConfigurationStatus) toCNS() cns.IPConfigurationStatus {
+	status := cns.IPConfigurationStatus{
+		ID:        x.GetId(),
+		IPAddress: x.GetIpAddress(),
+		NCID:      x.GetNcid(),
+	}
+	// SetState also sets the transition time, which is overwritten by the one of the message
+	status.SetState(types.IPState(x.GetState()))
+	status.LastStateTransition = time.Time{}
+	if x.GetLastStateTransition() != nil {
+		status.LastStateTransition = x.GetLastStateTransition().AsTime().In(time.Local)
+	}
+	if p := x.GetPodInfo(); p != nil {
+		status.PodInfo = cns.NewPodInfo(p.GetInfraContainerId(), p.GetInterfaceId(), p.GetName(), p.GetNamespace())
+	}
+	return status
+}
+
+// GetIPAddressStatusResponseFromCNS converts a cns.GetIPAddressStatusResponse to a GetIPAddressStatusResponse.
+func GetIPAddressStatusResponseFromCNS(r *cns.GetIPAddressStatusResponse) *GetIPAddressStatusResponse {
+	resp := &GetIPAddressStatusResponse{
+		ResourceVersion: r.ResourceVersion,
+		Response:        ResponseFromCNS(&r.Response),
+	}
+	for i := range r.IPConfigurationStatus {
+		resp.IpConfigurationStatus = append(resp.IpConfigurationStatus, ipConfigurationStatusFromCNS(&r.IPConfigurationStatus[i]))
+	}
+	return resp
+}
+
+// ToCNS converts the GetIPAddressStatusResponse to a cns.GetIPAddressStatusResponse.
+func (x *GetIPAddressStatusResponse) ToCNS() cns.GetIPAddressStatusResponse {
+	resp := cns.GetIPAddressStatusResponse{
+		ResourceVersion: x.GetResourceVersion(),
+		Response:        x.GetResponse().ToCNS(),
+	}
+	for _, status := range x.GetIpConfigurationStatus() {
+		resp.IPConfigurationStatus = append(resp.IPConfigurationStatus, status.toCNS())
+	}
+	return resp
+}
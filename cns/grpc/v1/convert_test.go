@@ -0,0 +1,91 @@
+package v1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-container-networking/cns"
+	"github.com/Azure/azure-container-networking/cns/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// roundTrip marshals and unmarshals the message, as it is sent over the wire.
+func roundTrip[M proto.Message](t *testing.T, in, out M) M {
+	t.Helper()
+	b, err := proto.Marshal(in)
+	require.NoError(t, err)
+	require.NoError(t, proto.Unmarshal(b, out))
+	return out
+}
+
+func TestIPConfigConversion(t *testing.T) {
+	req := cns.IPConfigRequest{
+		DesiredIPAddress:    "10.0.0.4",
+		PodInterfaceID:      "eth0",
+		InfraContainerID:    "container",
+		OrchestratorContext: []byte(`{"PodName":"pod","PodNamespace":"ns"}`),
+	}
+	assert.Equal(t, req, roundTrip(t, IPConfigRequestFromCNS(&req), &IPConfigRequest{}).ToCNS())
+
+	resp := cns.IPConfigResponse{
+		PodIpInfo: cns.PodIpInfo{
+			PodIPConfig: cns.IPSubnet{IPAddress: "10.0.0.4", PrefixLength: 24},
+			NetworkContainerPrimaryIPConfig: cns.IPConfiguration{
+				IPSubnet:         cns.IPSubnet{IPAddress: "10.0.0.0", PrefixLength: 24},
+				DNSServers:       []string{"168.63.129.16"},
+				GatewayIPAddress: "10.0.0.1",
+			},
+			HostPrimaryIPInfo: cns.HostIPInfo{Gateway: "10.1.0.1", PrimaryIP: "10.1.0.4", Subnet: "10.1.0.0/24"},
+		},
+		Response: cns.Response{ReturnCode: types.FailedToAllocateIPConfig, Message: "failed"},
+	}
+	assert.Equal(t, resp, roundTrip(t, IPConfigResponseFromCNS(&resp), &IPConfigResponse{}).ToCNS())
+}
+
+func TestGetNetworkContainerConversion(t *testing.T) {
+	resp := cns.GetNetworkContainerResponse{
+		NetworkContainerID: "nc",
+		IPConfiguration:    cns.IPConfiguration{IPSubnet: cns.IPSubnet{IPAddress: "10.0.0.4", PrefixLength: 24}},
+		Routes:             []cns.Route{{IPAddress: "0.0.0.0/0", GatewayIPAddress: "10.0.0.1", InterfaceToUse: "eth0"}},
+		CnetAddressSpace:   []cns.IPSubnet{{IPAddress: "10.2.0.0", PrefixLength: 16}},
+		MultiTenancyInfo:   cns.MultiTenancyInfo{EncapType: "Vlan", ID: 2},
+		Response:           cns.Response{ReturnCode: types.Success},
+
+		AllowNCToHostCommunication: true,
+	}
+	assert.Equal(t, resp, roundTrip(t, GetNetworkContainerResponseFromCNS(&resp), &GetNetworkContainerResponse{}).ToCNS())
+}
+
+func TestGetIPAddressesConversion(t *testing.T) {
+	req := cns.GetIPAddressesRequest{IPConfigStateFilter: []types.IPState{types.Assigned, types.Available}}
+	assert.Equal(t, req, roundTrip(t, GetIPAddressesRequestFromCNS(&req), &GetIPAddressesRequest{}).ToCNS())
+
+	assigned := cns.IPConfigurationStatus{
+		ID:                  "id",
+		IPAddress:           "10.0.0.4",
+		LastStateTransition: time.Now(),
+		NCID:                "nc",
+		PodInfo:             cns.NewPodInfo("container", "eth0", "pod", "ns"),
+	}
+	assigned.SetState(types.Assigned)
+	available := cns.IPConfigurationStatus{ID: "id2", IPAddress: "10.0.0.5", NCID: "nc"}
+	available.SetState(types.Available)
+	// an IP which never transitioned has no transition time
+	available.LastStateTransition = time.Time{}
+	resp := cns.GetIPAddressStatusResponse{
+		IPConfigurationStatus: []cns.IPConfigurationStatus{assigned, available},
+		ResourceVersion:       42,
+	}
+
+	got := roundTrip(t, GetIPAddressStatusResponseFromCNS(&resp), &GetIPAddressStatusResponse{}).ToCNS()
+	require.Len(t, got.IPConfigurationStatus, 2)
+	assert.True(t, got.IPConfigurationStatus[0].Equals(assigned))
+	assert.True(t, got.IPConfigurationStatus[0].LastStateTransition.Equal(assigned.LastStateTransition))
+	assert.Equal(t, "eth0", got.IPConfigurationStatus[0].PodInfo.InterfaceID())
+	assert.True(t, got.IPConfigurationStatus[1].Equals(available))
+	assert.True(t, got.IPConfigurationStatus[1].LastStateTransition.IsZero())
+	assert.Nil(t, got.IPConfigurationStatus[1].PodInfo)
+	assert.Equal(t, uint64(42), got.ResourceVersion)
+}
@@ -0,0 +1,13 @@
+package v1
+
+import "github.com/Azure/azure-container-networking/cns"
+
+// RouteGroups maps the full method names of the CNS gRPC service to the route groups of the HTTP routes they serve,
+// so that both APIs are authorized by the same policy.
+var RouteGroups = map[string]cns.RouteGroup{
+	"/cns.v1.CNS/RequestIPConfig":     cns.RouteGroupIPAM,
+	"/cns.v1.CNS/ReleaseIPConfig":     cns.RouteGroupIPAM,
+	"/cns.v1.CNS/GetNetworkContainer": cns.RouteGroupRead,
+	"/cns.v1.CNS/GetIPAddresses":      cns.RouteGroupDebug,
+	"/cns.v1.CNS/WatchIPAddresses":    cns.RouteGroupDebug,
+}
@@ -0,0 +1,36 @@
+package cns
+
+import (
+	"net"
+
+	acn "github.com/Azure/azure-container-networking/common"
+	"google.golang.org/grpc/credentials"
+)
+
+// PeerAuthInfo is the AuthInfo of the connections of the gRPC server, which adds the UID of the peer of unix socket
+// connections to the AuthInfo of their transport credentials, as the HTTP listener adds it to the request context.
+type PeerAuthInfo struct {
+	credentials.AuthInfo
+	// UID is the UID of the peer process, which is only set if HasUID is true.
+	UID    uint32
+	HasUID bool
+}
+
+// peerCredentials wraps the transport credentials of the gRPC server to add the UID of the peer to the AuthInfo.
+type peerCredentials struct {
+	credentials.TransportCredentials
+}
+
+func (c peerCredentials) ServerHandshake(rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	// the peer credentials are read from the raw connection, before TLS wraps it
+	uid, hasUID := acn.PeerUID(rawConn)
+	conn, info, err := c.TransportCredentials.ServerHandshake(rawConn)
+	if err != nil {
+		return nil, nil, err //nolint:wrapcheck // handshake errors are returned as is to grpc
+	}
+	return conn, PeerAuthInfo{AuthInfo: info, UID: uid, HasUID: hasUID}, nil
+}
+
+func (c peerCredentials) Clone() credentials.TransportCredentials {
+	return peerCredentials{c.TransportCredentials.Clone()}
+}
@@ -0,0 +1,65 @@
+package healthserver
+
+import (
+	"net/http"
+	"sync"
+
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// Checks are the named readiness and liveness checks of CNS, which subsystems register as they are started.
+// They are served as /readyz and /livez, with the output of every check in the ?verbose response, and each
+// check on its own at /readyz/<name> and /livez/<name>.
+type Checks struct {
+	sync.RWMutex
+	readyz map[string]healthz.Checker
+	livez  map[string]healthz.Checker
+}
+
+// NewChecks creates Checks with the ping check, which only reports that the healthserver is serving,
+// registered for both readiness and liveness.
+func NewChecks() *Checks {
+	return &Checks{
+		readyz: map[string]healthz.Checker{"ping": healthz.Ping},
+		livez:  map[string]healthz.Checker{"ping": healthz.Ping},
+	}
+}
+
+// AddReadyzCheck registers the readiness check, replacing any check with the same name.
+func (c *Checks) AddReadyzCheck(name string, check healthz.Checker) {
+	c.Lock()
+	defer c.Unlock()
+	c.readyz[name] = check
+}
+
+// AddLivezCheck registers the liveness check, replacing any check with the same name.
+func (c *Checks) AddLivezCheck(name string, check healthz.Checker) {
+	c.Lock()
+	defer c.Unlock()
+	c.livez[name] = check
+}
+
+// ReadyzHandler serves the readiness checks. The path must be stripped of its prefix.
+func (c *Checks) ReadyzHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.handler(c.readyz).ServeHTTP(w, r)
+	})
+}
+
+// LivezHandler serves the liveness checks. The path must be stripped of its prefix.
+func (c *Checks) LivezHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.handler(c.livez).ServeHTTP(w, r)
+	})
+}
+
+// handler returns a healthz.Handler of the checks registered so far.
+func (c *Checks) handler(checks map[string]healthz.Checker) *healthz.Handler {
+	c.RLock()
+	defer c.RUnlock()
+	h := &healthz.Handler{Checks: make(map[string]healthz.Checker, len(checks))}
+	for name, check := range checks {
+		h.Checks[name] = check
+	}
+	return h
+}
@@ -0,0 +1,43 @@
+package healthserver
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecks(t *testing.T) {
+	checks := NewChecks()
+	handler := http.StripPrefix("/readyz", checks.ReadyzHandler())
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, http.NoBody))
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, serve("/readyz").Code)
+
+	// checks registered after the handler was created are served
+	ready := false
+	checks.AddReadyzCheck("subsystem", func(*http.Request) error {
+		if !ready {
+			return errors.New("not ready")
+		}
+		return nil
+	})
+	checks.AddLivezCheck("other", func(*http.Request) error { return nil })
+	w := serve("/readyz?verbose")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "[-]subsystem failed")
+	assert.Contains(t, w.Body.String(), "[+]ping ok")
+	assert.NotContains(t, w.Body.String(), "other")
+	assert.Equal(t, http.StatusOK, serve("/readyz/ping").Code)
+	assert.Equal(t, http.StatusOK, serve("/readyz?exclude=subsystem").Code)
+
+	ready = true
+	assert.Equal(t, http.StatusOK, serve("/readyz").Code)
+	assert.Equal(t, http.StatusOK, serve("/readyz/subsystem").Code)
+}
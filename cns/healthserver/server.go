@@ -6,14 +6,23 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
-	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
-func Start(log *zap.Logger, addr string) {
+// Start serves the metrics and the health checks on the addr.
+// /healthz is kept as an alias of /livez for existing probes.
+func Start(log *zap.Logger, addr string, checks *Checks) {
 	e := echo.New()
 	e.HideBanner = true
-	e.GET("/healthz", echo.WrapHandler(http.StripPrefix("/healthz", &healthz.Handler{})))
+	for prefix, handler := range map[string]http.Handler{
+		"/healthz": checks.LivezHandler(),
+		"/livez":   checks.LivezHandler(),
+		"/readyz":  checks.ReadyzHandler(),
+	} {
+		h := echo.WrapHandler(http.StripPrefix(prefix, handler))
+		e.GET(prefix, h)
+		e.GET(prefix+"/*", h)
+	}
 	e.GET("/metrics", echo.WrapHandler(promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{
 		ErrorHandling: promhttp.HTTPErrorOnError,
 	})))
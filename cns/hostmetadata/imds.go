@@ -0,0 +1,134 @@
+package hostmetadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Azure/azure-container-networking/cns/wireserver"
+	"github.com/Azure/azure-container-networking/nmagent"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultIMDSHost = "169.254.169.254"
+	imdsInstanceURL = "http://%s/metadata/instance?api-version=2021-02-01&format=json"
+)
+
+type do interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// IMDS is the Provider of Azure VMs which can't reach wireserver, which gets the metadata of the host from the Azure
+// Instance Metadata Service. IMDS doesn't know the APIs of NMAgent, so only GetHomeAzAPI is supported, on VMs in an
+// availability zone.
+type IMDS struct {
+	HTTPClient do
+	// Host is the host, and optional port, of IMDS. It defaults to the well-known IMDS IP.
+	Host string
+}
+
+type imdsInstance struct {
+	Compute struct {
+		Zone string `json:"zone"`
+	} `json:"compute"`
+	Network struct {
+		Interface []imdsInterface `json:"interface"`
+	} `json:"network"`
+}
+
+type imdsInterface struct {
+	MacAddress string `json:"macAddress"`
+	IPv4       struct {
+		IPAddress []struct {
+			PrivateIPAddress string `json:"privateIpAddress"`
+		} `json:"ipAddress"`
+		Subnet []struct {
+			Address string `json:"address"`
+			Prefix  string `json:"prefix"`
+		} `json:"subnet"`
+	} `json:"ipv4"`
+}
+
+func (i *IMDS) instance(ctx context.Context) (*imdsInstance, error) {
+	host := i.Host
+	if host == "" {
+		host = defaultIMDSHost
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(imdsInstanceURL, host), http.NoBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to construct request")
+	}
+	req.Header.Set("Metadata", "true")
+	resp, err := i.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to execute request")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("imds responded with status %s", resp.Status)
+	}
+	var instance imdsInstance
+	if err := json.NewDecoder(resp.Body).Decode(&instance); err != nil {
+		return nil, errors.Wrap(err, "failed to decode response body")
+	}
+	return &instance, nil
+}
+
+// GetInterfaces returns the IPv4 interfaces of the VM. IMDS lists the primary interface, and the primary IP of each
+// interface, first.
+func (i *IMDS) GetInterfaces(ctx context.Context) (*wireserver.GetInterfacesResult, error) {
+	instance, err := i.instance(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := &wireserver.GetInterfacesResult{}
+	for j, imdsIface := range instance.Network.Interface {
+		iface := wireserver.Interface{
+			MacAddress: imdsIface.MacAddress,
+			IsPrimary:  j == 0,
+		}
+		for _, s := range imdsIface.IPv4.Subnet {
+			subnet := wireserver.Subnet{Prefix: s.Address + "/" + s.Prefix}
+			for k, ip := range imdsIface.IPv4.IPAddress {
+				subnet.IPAddress = append(subnet.IPAddress, wireserver.Address{
+					Address:   ip.PrivateIPAddress,
+					IsPrimary: k == 0,
+				})
+			}
+			iface.IPSubnet = append(iface.IPSubnet, subnet)
+		}
+		res.Interface = append(res.Interface, iface)
+	}
+	return res, nil
+}
+
+// GetHomeAz returns the availability zone of the VM, or ErrHomeAzUnknown if it is not in one.
+func (i *IMDS) GetHomeAz(ctx context.Context) (nmagent.AzResponse, error) {
+	instance, err := i.instance(ctx)
+	if err != nil {
+		return nmagent.AzResponse{}, err
+	}
+	if instance.Compute.Zone == "" {
+		return nmagent.AzResponse{}, ErrHomeAzUnknown
+	}
+	zone, err := strconv.ParseUint(instance.Compute.Zone, 10, 32)
+	if err != nil {
+		return nmagent.AzResponse{}, errors.Wrapf(err, "failed to parse zone %q", instance.Compute.Zone)
+	}
+	return nmagent.AzResponse{HomeAz: uint(zone)}, nil
+}
+
+// SupportedAPIs returns GetHomeAzAPI if the VM is in an availability zone.
+func (i *IMDS) SupportedAPIs(ctx context.Context) ([]string, error) {
+	instance, err := i.instance(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if instance.Compute.Zone == "" {
+		return []string{}, nil
+	}
+	return []string{GetHomeAzAPI}, nil
+}
@@ -0,0 +1,95 @@
+package hostmetadata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/cns/wireserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const imdsInstanceResponse = `{
+	"compute": {"zone": "%s"},
+	"network": {
+		"interface": [
+			{
+				"macAddress": "000D3A6E1825",
+				"ipv4": {
+					"ipAddress": [{"privateIpAddress": "10.0.0.4"}, {"privateIpAddress": "10.0.0.5"}],
+					"subnet": [{"address": "10.0.0.0", "prefix": "24"}]
+				}
+			},
+			{
+				"macAddress": "000D3A6E1826",
+				"ipv4": {
+					"ipAddress": [{"privateIpAddress": "10.1.0.4"}],
+					"subnet": [{"address": "10.1.0.0", "prefix": "24"}]
+				}
+			}
+		]
+	}
+}`
+
+func newIMDS(t *testing.T, zone string) *IMDS {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata") != "true" || r.URL.Path != "/metadata/instance" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(strings.Replace(imdsInstanceResponse, "%s", zone, 1)))
+	}))
+	t.Cleanup(srv.Close)
+	return &IMDS{HTTPClient: srv.Client(), Host: strings.TrimPrefix(srv.URL, "http://")}
+}
+
+func TestIMDSInZone(t *testing.T) {
+	i := newIMDS(t, "3")
+
+	res, err := i.GetInterfaces(context.Background())
+	require.NoError(t, err)
+	require.Len(t, res.Interface, 2)
+	assert.False(t, res.Interface[1].IsPrimary)
+	primary, err := wireserver.GetPrimaryInterfaceFromResult(res)
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.0/24", primary.Subnet)
+	assert.Equal(t, "10.0.0.1", primary.Gateway)
+	assert.Equal(t, "10.0.0.4", primary.PrimaryIP)
+
+	az, err := i.GetHomeAz(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint(3), az.HomeAz)
+
+	apis, err := i.SupportedAPIs(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{GetHomeAzAPI}, apis)
+}
+
+func TestIMDSWithoutZone(t *testing.T) {
+	i := newIMDS(t, "")
+
+	_, err := i.GetHomeAz(context.Background())
+	require.ErrorIs(t, err, ErrHomeAzUnknown)
+
+	apis, err := i.SupportedAPIs(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, apis)
+}
+
+func TestIMDSError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(srv.Close)
+	i := &IMDS{HTTPClient: srv.Client(), Host: strings.TrimPrefix(srv.URL, "http://")}
+
+	_, err := i.GetInterfaces(context.Background())
+	require.Error(t, err)
+	_, err = i.GetHomeAz(context.Background())
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrHomeAzUnknown)
+}
@@ -0,0 +1,58 @@
+// Package hostmetadata provides the metadata of the host which CNS runs on: its primary interface and gateway, its
+// home AZ, and the NMAgent APIs which are supported on it. On Azure VMs, the metadata is read from wireserver and
+// NMAgent or from IMDS. Elsewhere, such as on-prem or at the edge, it is read from a static file.
+package hostmetadata
+
+import (
+	"context"
+
+	"github.com/Azure/azure-container-networking/cns/wireserver"
+	"github.com/Azure/azure-container-networking/nmagent"
+	"github.com/pkg/errors"
+)
+
+// GetHomeAzAPI is the supported API of the hosts whose home AZ is known.
+const GetHomeAzAPI = "GetHomeAz"
+
+// ErrHomeAzUnknown is returned for the home AZ of hosts which are not in an availability zone.
+var ErrHomeAzUnknown = errors.New("home az of the host is unknown")
+
+// Provider provides the metadata of the host.
+type Provider interface {
+	// GetInterfaces returns the interfaces of the host, of which CNS uses the primary one.
+	GetInterfaces(context.Context) (*wireserver.GetInterfacesResult, error)
+	// GetHomeAz returns the home AZ of the host, if GetHomeAzAPI is supported.
+	GetHomeAz(context.Context) (nmagent.AzResponse, error)
+	// SupportedAPIs returns the NMAgent APIs which are supported on the host.
+	SupportedAPIs(context.Context) ([]string, error)
+}
+
+var (
+	_ Provider = (*Wireserver)(nil)
+	_ Provider = (*IMDS)(nil)
+	_ Provider = (*Static)(nil)
+)
+
+type interfaceGetter interface {
+	GetInterfaces(context.Context) (*wireserver.GetInterfacesResult, error)
+}
+
+type nmagentClient interface {
+	GetHomeAz(context.Context) (nmagent.AzResponse, error)
+	SupportedAPIs(context.Context) ([]string, error)
+}
+
+// Wireserver is the Provider of Azure VMs, which gets the interfaces of the host from wireserver and the rest of its
+// metadata from NMAgent.
+type Wireserver struct {
+	interfaceGetter
+	nmagentClient
+}
+
+// NewWireserver returns the Provider of the wireserver and NMAgent clients.
+func NewWireserver(wscli interfaceGetter, nma nmagentClient) *Wireserver {
+	return &Wireserver{
+		interfaceGetter: wscli,
+		nmagentClient:   nma,
+	}
+}
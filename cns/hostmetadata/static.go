@@ -0,0 +1,115 @@
+package hostmetadata
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+
+	"github.com/Azure/azure-container-networking/cns/wireserver"
+	"github.com/Azure/azure-container-networking/nmagent"
+	"github.com/pkg/errors"
+)
+
+// StaticMetadata is the metadata of a host outside of Azure, which the Static provider reads from a JSON file.
+type StaticMetadata struct {
+	PrimaryInterface StaticInterface
+	// HomeAz is the home AZ of the host, which is unknown if it is 0.
+	HomeAz uint
+	// SupportedAPIs are the NMAgent APIs which are supported on the host. GetHomeAzAPI is added if HomeAz is set.
+	SupportedAPIs []string
+}
+
+// StaticInterface is the primary interface of a host outside of Azure.
+type StaticInterface struct {
+	MacAddress string
+	// Subnet is the prefix of the subnet of the interface, such as 10.0.0.0/24.
+	Subnet string
+	// Gateway is the gateway of the subnet, which defaults to the first IP of the subnet.
+	Gateway      string
+	PrimaryIP    string
+	SecondaryIPs []string
+}
+
+// Static is the Provider of hosts outside of Azure, which serves metadata from the configuration of CNS.
+type Static struct {
+	metadata StaticMetadata
+}
+
+// NewStatic returns the Provider of the metadata, which must have the subnet and primary IP of the primary interface.
+func NewStatic(metadata StaticMetadata) (*Static, error) {
+	iface := metadata.PrimaryInterface
+	_, subnet, err := net.ParseCIDR(iface.Subnet)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid subnet of the primary interface")
+	}
+	for _, ip := range append([]string{iface.PrimaryIP}, iface.SecondaryIPs...) {
+		if parsed := net.ParseIP(ip); parsed == nil || !subnet.Contains(parsed) {
+			return nil, errors.Errorf("IP %q of the primary interface is not in its subnet %s", ip, iface.Subnet)
+		}
+	}
+	if iface.Gateway != "" && net.ParseIP(iface.Gateway) == nil {
+		return nil, errors.Errorf("invalid gateway %q of the primary interface", iface.Gateway)
+	}
+	return &Static{metadata: metadata}, nil
+}
+
+// NewStaticFromFile returns the Provider of the StaticMetadata in the JSON file.
+func NewStaticFromFile(path string) (*Static, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read host metadata file %s", path)
+	}
+	var metadata StaticMetadata
+	if err := json.Unmarshal(b, &metadata); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal host metadata file %s", path)
+	}
+	return NewStatic(metadata)
+}
+
+// GetInterfaces returns the primary interface of the host.
+func (s *Static) GetInterfaces(context.Context) (*wireserver.GetInterfacesResult, error) {
+	iface := s.metadata.PrimaryInterface
+	subnet := wireserver.Subnet{
+		Prefix:    iface.Subnet,
+		Gateway:   iface.Gateway,
+		IPAddress: []wireserver.Address{{Address: iface.PrimaryIP, IsPrimary: true}},
+	}
+	for _, ip := range iface.SecondaryIPs {
+		subnet.IPAddress = append(subnet.IPAddress, wireserver.Address{Address: ip})
+	}
+	return &wireserver.GetInterfacesResult{
+		Interface: []wireserver.Interface{{
+			MacAddress: iface.MacAddress,
+			IsPrimary:  true,
+			IPSubnet:   []wireserver.Subnet{subnet},
+		}},
+	}, nil
+}
+
+// GetHomeAz returns the configured home AZ, or ErrHomeAzUnknown if it is not set.
+func (s *Static) GetHomeAz(context.Context) (nmagent.AzResponse, error) {
+	if s.metadata.HomeAz == 0 {
+		return nmagent.AzResponse{}, ErrHomeAzUnknown
+	}
+	return nmagent.AzResponse{HomeAz: s.metadata.HomeAz}, nil
+}
+
+// SupportedAPIs returns the configured APIs, with GetHomeAzAPI if the home AZ is set.
+func (s *Static) SupportedAPIs(context.Context) ([]string, error) {
+	apis := make([]string, 0, len(s.metadata.SupportedAPIs)+1)
+	apis = append(apis, s.metadata.SupportedAPIs...)
+	if s.metadata.HomeAz != 0 && !contains(apis, GetHomeAzAPI) {
+		apis = append(apis, GetHomeAzAPI)
+	}
+	return apis, nil
+}
+
+func contains(s []string, v string) bool {
+	for i := range s {
+		if s[i] == v {
+			return true
+		}
+	}
+	return false
+}
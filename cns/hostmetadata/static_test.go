@@ -0,0 +1,117 @@
+package hostmetadata
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/cns/wireserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStatic(t *testing.T) {
+	tests := []struct {
+		name    string
+		iface   StaticInterface
+		wantErr bool
+	}{
+		{
+			name:  "valid",
+			iface: StaticInterface{Subnet: "10.0.0.0/24", Gateway: "10.0.0.254", PrimaryIP: "10.0.0.4", SecondaryIPs: []string{"10.0.0.5"}},
+		},
+		{
+			name:    "invalid subnet",
+			iface:   StaticInterface{Subnet: "10.0.0.0", PrimaryIP: "10.0.0.4"},
+			wantErr: true,
+		},
+		{
+			name:    "primary IP outside subnet",
+			iface:   StaticInterface{Subnet: "10.0.0.0/24", PrimaryIP: "10.1.0.4"},
+			wantErr: true,
+		},
+		{
+			name:    "missing primary IP",
+			iface:   StaticInterface{Subnet: "10.0.0.0/24"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid secondary IP",
+			iface:   StaticInterface{Subnet: "10.0.0.0/24", PrimaryIP: "10.0.0.4", SecondaryIPs: []string{"test"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid gateway",
+			iface:   StaticInterface{Subnet: "10.0.0.0/24", Gateway: "test", PrimaryIP: "10.0.0.4"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewStatic(StaticMetadata{PrimaryInterface: tt.iface})
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestStaticFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hostmetadata.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"PrimaryInterface": {
+			"MacAddress": "000D3A6E1825",
+			"Subnet": "10.0.0.0/24",
+			"Gateway": "10.0.0.254",
+			"PrimaryIP": "10.0.0.4",
+			"SecondaryIPs": ["10.0.0.5"]
+		},
+		"HomeAz": 2,
+		"SupportedAPIs": ["NetworkManagement"]
+	}`), 0o600))
+
+	s, err := NewStaticFromFile(path)
+	require.NoError(t, err)
+
+	res, err := s.GetInterfaces(context.Background())
+	require.NoError(t, err)
+	primary, err := wireserver.GetPrimaryInterfaceFromResult(res)
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.254", primary.Gateway)
+	assert.Equal(t, "10.0.0.4", primary.PrimaryIP)
+	require.Len(t, res.Interface[0].IPSubnet[0].IPAddress, 2)
+	assert.Equal(t, "10.0.0.5", res.Interface[0].IPSubnet[0].IPAddress[1].Address)
+
+	az, err := s.GetHomeAz(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), az.HomeAz)
+
+	apis, err := s.SupportedAPIs(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"NetworkManagement", GetHomeAzAPI}, apis)
+
+	_, err = NewStaticFromFile(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+}
+
+func TestStaticWithoutHomeAz(t *testing.T) {
+	s, err := NewStatic(StaticMetadata{PrimaryInterface: StaticInterface{Subnet: "10.0.0.0/24", PrimaryIP: "10.0.0.4"}})
+	require.NoError(t, err)
+
+	res, err := s.GetInterfaces(context.Background())
+	require.NoError(t, err)
+	primary, err := wireserver.GetPrimaryInterfaceFromResult(res)
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", primary.Gateway)
+
+	_, err = s.GetHomeAz(context.Background())
+	require.ErrorIs(t, err, ErrHomeAzUnknown)
+
+	apis, err := s.SupportedAPIs(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, apis)
+}
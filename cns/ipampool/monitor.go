@@ -3,6 +3,7 @@ package ipampool
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strconv"
 	"sync"
 	"time"
@@ -13,19 +14,31 @@ import (
 	"github.com/Azure/azure-container-networking/cns/types"
 	"github.com/Azure/azure-container-networking/crd/clustersubnetstate/api/v1alpha1"
 	"github.com/Azure/azure-container-networking/crd/nodenetworkconfig/api/v1alpha"
+	"github.com/Azure/azure-container-networking/otlp"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 const (
 	// DefaultRefreshDelay pool monitor poll delay default in seconds.
+	// When the Monitor is notified of IP state changes, polling is only a safety net and can be much slower.
 	DefaultRefreshDelay = 1 * time.Second
 	// DefaultMaxIPs default maximum allocatable IPs
 	DefaultMaxIPs = 250
+	// minRetryDelay is the delay before the first retry of a failed reconcile, doubled on each consecutive failure
+	// up to the RefreshDelay.
+	minRetryDelay = 250 * time.Millisecond
 	// Subnet ARM ID /subscriptions/$(SUB)/resourceGroups/$(GROUP)/providers/Microsoft.Network/virtualNetworks/$(VNET)/subnets/$(SUBNET)
 	subnetARMIDTemplate = "/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Network/virtualNetworks/%s/subnets/%s"
 )
 
+var errNotInitialized = errors.New("no NodeNetworkConfig has been received yet")
+
 type nodeNetworkConfigSpecUpdater interface {
 	UpdateSpec(context.Context, *v1alpha.NodeNetworkConfigSpec) (*v1alpha.NodeNetworkConfig, error)
 }
@@ -44,52 +57,109 @@ type metaState struct {
 	subnetCIDR         string
 }
 
+func (m metaState) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddInt64("batch", m.batch)
+	enc.AddBool("exhausted", m.exhausted)
+	enc.AddInt64("max", m.max)
+	enc.AddInt64("maxFreeCount", m.maxFreeCount)
+	enc.AddInt64("minFreeCount", m.minFreeCount)
+	enc.AddInt64("notInUseCount", m.notInUseCount)
+	enc.AddInt("primaryIPAddresses", len(m.primaryIPAddresses))
+	enc.AddString("subnet", m.subnet)
+	enc.AddString("subnetARMID", m.subnetARMID)
+	enc.AddString("subnetCIDR", m.subnetCIDR)
+	return nil
+}
+
 type Options struct {
 	RefreshDelay time.Duration
 	MaxIPs       int64
+	// PublishIPAMState publishes a summary of the IP pool to the NodeNetworkConfig status, if the NNC client supports it.
+	PublishIPAMState bool
 }
 
 type Monitor struct {
+	log         *zap.Logger
 	opts        *Options
 	spec        v1alpha.NodeNetworkConfigSpec
 	metastate   metaState
 	nnccli      nodeNetworkConfigSpecUpdater
 	httpService cns.HTTPService
 	cssSource   <-chan v1alpha1.ClusterSubnetState
-	nncSource   chan v1alpha.NodeNetworkConfig
-	started     chan interface{}
-	once        sync.Once
+	// ipStateSource notifies of IP state changes in CNS
+	ipStateSource <-chan struct{}
+	nncSource     chan v1alpha.NodeNetworkConfig
+	started       chan interface{}
+	once          sync.Once
+	// statePublisher is nil unless the IPAM state is published
+	statePublisher *ipamStatePublisher
 }
 
-func NewMonitor(httpService cns.HTTPService, nnccli nodeNetworkConfigSpecUpdater, cssSource <-chan v1alpha1.ClusterSubnetState, opts *Options) *Monitor {
+// NewMonitor creates a Monitor of the IP pool of the httpService.
+// The Monitor reconciles the pool when notified through the ipStateSource, if set, and polls once per RefreshDelay.
+func NewMonitor(
+	httpService cns.HTTPService,
+	nnccli nodeNetworkConfigSpecUpdater,
+	cssSource <-chan v1alpha1.ClusterSubnetState,
+	ipStateSource <-chan struct{},
+	opts *Options,
+) *Monitor {
 	if opts.RefreshDelay < 1 {
 		opts.RefreshDelay = DefaultRefreshDelay
 	}
 	if opts.MaxIPs < 1 {
 		opts.MaxIPs = DefaultMaxIPs
 	}
-	return &Monitor{
-		opts:        opts,
-		httpService: httpService,
-		nnccli:      nnccli,
-		cssSource:   cssSource,
-		nncSource:   make(chan v1alpha.NodeNetworkConfig),
-		started:     make(chan interface{}),
+	pm := &Monitor{
+		log:           logger.Named(logger.IPAMPool),
+		opts:          opts,
+		httpService:   httpService,
+		nnccli:        nnccli,
+		cssSource:     cssSource,
+		ipStateSource: ipStateSource,
+		nncSource:     make(chan v1alpha.NodeNetworkConfig),
+		started:       make(chan interface{}),
+	}
+	if opts.PublishIPAMState {
+		if patcher, ok := nnccli.(nodeNetworkConfigIPAMStatePatcher); ok {
+			pm.statePublisher = &ipamStatePublisher{log: pm.log, patcher: patcher}
+		} else {
+			pm.log.Warn("NNC client can't publish the IPAM state, not publishing it")
+		}
+	}
+	return pm
+}
+
+// InitializedCheck is a healthz.Checker which fails until the Monitor has received its first NodeNetworkConfig.
+func (pm *Monitor) InitializedCheck(*http.Request) error {
+	select {
+	case <-pm.started:
+		return nil
+	default:
+		return errNotInitialized
 	}
 }
 
 // Start begins the Monitor's pool reconcile loop.
 // On first run, it will block until a NodeNetworkConfig is received (through a call to Update()).
-// Subsequently, it will run run once per RefreshDelay and attempt to re-reconcile the pool.
+// Subsequently, it will attempt to re-reconcile the pool whenever the IP state changes, and once per RefreshDelay.
+// A failed reconcile is retried with exponential backoff, without waiting for the next RefreshDelay.
 func (pm *Monitor) Start(ctx context.Context) error {
-	logger.Printf("[ipam-pool-monitor] Starting CNS IPAM Pool Monitor")
+	pm.log.Info("starting CNS IPAM pool monitor")
 	ticker := time.NewTicker(pm.opts.RefreshDelay)
 	defer ticker.Stop()
+	retry := time.NewTimer(0)
+	if !retry.Stop() {
+		<-retry.C
+	}
+	defer retry.Stop()
+	var retryDelay time.Duration
 	for {
 		// proceed when things happen:
 		select {
 		case <-ctx.Done(): // calling context has closed, we'll exit.
 			return errors.Wrap(ctx.Err(), "pool monitor context closed")
+		case <-retry.C: // the last reconcile failed, retry it.
 		case <-ticker.C: // attempt to reconcile every tick.
 			select {
 			default:
@@ -98,9 +168,17 @@ func (pm *Monitor) Start(ctx context.Context) error {
 			case <-pm.started: // this blocks until we have initialized
 				// if we have initialized and enter this case, we proceed out of the select and continue to reconcile.
 			}
+		case <-pm.ipStateSource: // IPs were assigned, released, added or removed, reconcile immediately.
+			select {
+			default:
+				// if we have NOT initialized and enter this case, we continue out of this iteration and let the for loop begin again.
+				continue
+			case <-pm.started: // this blocks until we have initialized
+				// if we have initialized and enter this case, we proceed out of the select and continue to reconcile.
+			}
 		case css := <-pm.cssSource: // received an updated ClusterSubnetState
 			pm.metastate.exhausted = css.Status.Exhausted
-			logger.Printf("subnet exhausted status = %t", pm.metastate.exhausted)
+			pm.log.Info("received subnet exhaustion", zap.Bool("exhausted", pm.metastate.exhausted))
 			ipamSubnetExhaustionCount.With(prometheus.Labels{
 				subnetLabel: pm.metastate.subnet, subnetCIDRLabel: pm.metastate.subnetCIDR,
 				podnetARMIDLabel: pm.metastate.subnetARMID, subnetExhaustionStateLabel: strconv.FormatBool(pm.metastate.exhausted),
@@ -135,16 +213,46 @@ func (pm *Monitor) Start(ctx context.Context) error {
 			pm.metastate.minFreeCount, pm.metastate.maxFreeCount = CalculateMinFreeIPs(scaler), CalculateMaxFreeIPs(scaler)
 			pm.once.Do(func() {
 				pm.spec = nnc.Spec // set the spec from the NNC initially (afterwards we write the Spec so we know target state).
-				logger.Printf("[ipam-pool-monitor] set initial pool spec %+v", pm.spec)
+				pm.log.Info("set initial pool spec",
+					zap.Int64("requestedIPCount", pm.spec.RequestedIPCount), zap.Int("ipsNotInUse", len(pm.spec.IPsNotInUse)))
 				close(pm.started) // close the init channel the first time we fully receive a NodeNetworkConfig.
 			})
 		}
 		// if control has flowed through the select(s) to this point, we can now reconcile.
 		err := pm.reconcile(ctx)
+		if !retry.Stop() {
+			// drain the timer if it fired while another case was selected.
+			select {
+			case <-retry.C:
+			default:
+			}
+		}
 		if err != nil {
-			logger.Printf("[ipam-pool-monitor] Reconcile failed with err %v", err)
+			retryDelay = nextRetryDelay(retryDelay, pm.opts.RefreshDelay)
+			pm.log.Error("reconcile failed", zap.Error(err), zap.Duration("retryIn", retryDelay))
+			retry.Reset(retryDelay)
+		} else {
+			retryDelay = 0
 		}
+		if pm.statePublisher != nil {
+			pm.statePublisher.recordReconcile(err)
+			if err := pm.statePublisher.publish(ctx, pm.httpService.GetPodIPConfigState()); err != nil {
+				pm.log.Error("failed to publish ipam state", zap.Error(err))
+			}
+		}
+	}
+}
+
+// nextRetryDelay doubles the previous retry delay, starting from minRetryDelay and capped at max.
+func nextRetryDelay(prev, max time.Duration) time.Duration {
+	next := 2 * prev
+	if next < minRetryDelay {
+		next = minRetryDelay
+	}
+	if next > max {
+		next = max
 	}
+	return next
 }
 
 // ipPoolState is the current actual state of the CNS IP pool.
@@ -167,6 +275,18 @@ type ipPoolState struct {
 	totalIPs int64
 }
 
+func (s ipPoolState) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddInt64("allocatedToPods", s.allocatedToPods)
+	enc.AddInt64("available", s.available)
+	enc.AddInt64("currentAvailableIPs", s.currentAvailableIPs)
+	enc.AddInt64("expectedAvailableIPs", s.expectedAvailableIPs)
+	enc.AddInt64("pendingProgramming", s.pendingProgramming)
+	enc.AddInt64("pendingRelease", s.pendingRelease)
+	enc.AddInt64("requestedIPs", s.requestedIPs)
+	enc.AddInt64("totalIPs", s.totalIPs)
+	return nil
+}
+
 func buildIPPoolState(ips map[string]cns.IPConfigurationStatus, spec v1alpha.NodeNetworkConfigSpec) ipPoolState {
 	state := ipPoolState{
 		totalIPs:     int64(len(ips)),
@@ -201,7 +321,7 @@ func (pm *Monitor) reconcile(ctx context.Context) error {
 	// log every 30th reconcile to reduce the AI load. we will always log when the monitor
 	// changes the pool, below.
 	if statelogDownsample = (statelogDownsample + 1) % 30; statelogDownsample == 0 { //nolint:gomnd //downsample by 30
-		logger.Printf("ipam-pool-monitor state: %+v, meta: %+v", state, meta)
+		pm.log.Info("pool state", zap.Object("state", state), zap.Object("meta", meta))
 	}
 
 	// if the subnet is exhausted, overwrite the batch/minfree/maxfree in the meta copy for this iteration
@@ -218,27 +338,23 @@ func (pm *Monitor) reconcile(ctx context.Context) error {
 			// If we're already at the maxIPCount, don't try to increase
 			return nil
 		}
-		logger.Printf("ipam-pool-monitor state %+v", state)
-		logger.Printf("[ipam-pool-monitor] Increasing pool size...")
+		pm.log.Info("increasing pool size", zap.Object("state", state))
 		return pm.increasePoolSize(ctx, meta, state)
 
 	// pod count is decreasing
 	case state.currentAvailableIPs >= meta.maxFreeCount:
-		logger.Printf("ipam-pool-monitor state %+v", state)
-		logger.Printf("[ipam-pool-monitor] Decreasing pool size...")
+		pm.log.Info("decreasing pool size", zap.Object("state", state))
 		return pm.decreasePoolSize(ctx, meta, state)
 
 	// CRD has reconciled CNS state, and target spec is now the same size as the state
 	// free to remove the IPs from the CRD
 	case int64(len(pm.spec.IPsNotInUse)) != state.pendingRelease:
-		logger.Printf("ipam-pool-monitor state %+v", state)
-		logger.Printf("[ipam-pool-monitor] Removing Pending Release IPs from CRD...")
+		pm.log.Info("removing pending release IPs from NNC", zap.Object("state", state))
 		return pm.cleanPendingRelease(ctx)
 
 	// no pods scheduled
 	case state.allocatedToPods == 0:
-		logger.Printf("ipam-pool-monitor state %+v", state)
-		logger.Printf("[ipam-pool-monitor] No pods scheduled")
+		pm.log.Info("no pods scheduled", zap.Object("state", state))
 		return nil
 	}
 
@@ -252,31 +368,30 @@ func (pm *Monitor) increasePoolSize(ctx context.Context, meta metaState, state i
 	previouslyRequestedIPCount := tempNNCSpec.RequestedIPCount
 	batchSize := meta.batch
 	modResult := previouslyRequestedIPCount % batchSize
-	logger.Printf("[ipam-pool-monitor] Previously RequestedIP Count %d", previouslyRequestedIPCount)
-	logger.Printf("[ipam-pool-monitor] Batch size : %d", batchSize)
-	logger.Printf("[ipam-pool-monitor] modResult of (previously requested IP count mod batch size) = %d", modResult)
+	log := pm.log.With(zap.Int64("previouslyRequestedIPCount", previouslyRequestedIPCount), zap.Int64("batchSize", batchSize))
+	log.Debug("computing increased requested IP count", zap.Int64("modResult", modResult))
 
 	tempNNCSpec.RequestedIPCount += batchSize - modResult
 	if tempNNCSpec.RequestedIPCount > meta.max {
 		// We don't want to ask for more ips than the max
-		logger.Printf("[ipam-pool-monitor] Requested IP count (%d) is over max limit (%d), requesting max limit instead.", tempNNCSpec.RequestedIPCount, meta.max)
+		log.Info("requested IP count is over max limit, requesting max limit instead",
+			zap.Int64("requestedIPCount", tempNNCSpec.RequestedIPCount), zap.Int64("max", meta.max))
 		tempNNCSpec.RequestedIPCount = meta.max
 	}
 
 	// If the requested IP count is same as before, then don't do anything
 	if tempNNCSpec.RequestedIPCount == previouslyRequestedIPCount {
-		logger.Printf("[ipam-pool-monitor] Previously requested IP count %d is same as updated IP count %d, doing nothing", previouslyRequestedIPCount, tempNNCSpec.RequestedIPCount)
+		log.Info("requested IP count is unchanged, doing nothing")
 		return nil
 	}
 
-	logger.Printf("[ipam-pool-monitor] Increasing pool size, pool %+v, spec %+v", state, tempNNCSpec)
-
-	if _, err := pm.nnccli.UpdateSpec(ctx, &tempNNCSpec); err != nil {
+	if err := pm.updateSpec(ctx, "increase", &tempNNCSpec); err != nil {
 		// caller will retry to update the CRD again
-		return errors.Wrap(err, "executing UpdateSpec with NNC CLI")
+		return err
 	}
 
-	logger.Printf("[ipam-pool-monitor] Increasing pool size: UpdateCRDSpec succeeded for spec %+v", tempNNCSpec)
+	log.Info("increased pool size", zap.Int64("requestedIPCount", tempNNCSpec.RequestedIPCount))
+	pm.recordScaleEvent(previouslyRequestedIPCount, tempNNCSpec.RequestedIPCount)
 	// start an alloc timer
 	metric.StartPoolIncreaseTimer(batchSize)
 	// save the updated state to cachedSpec
@@ -294,9 +409,8 @@ func (pm *Monitor) decreasePoolSize(ctx context.Context, meta metaState, state i
 	previouslyRequestedIPCount := pm.spec.RequestedIPCount
 	batchSize := meta.batch
 	modResult := previouslyRequestedIPCount % batchSize
-	logger.Printf("[ipam-pool-monitor] Previously RequestedIP Count %d", previouslyRequestedIPCount)
-	logger.Printf("[ipam-pool-monitor] Batch size : %d", batchSize)
-	logger.Printf("[ipam-pool-monitor] modResult of (previously requested IP count mod batch size) = %d", modResult)
+	log := pm.log.With(zap.Int64("previouslyRequestedIPCount", previouslyRequestedIPCount), zap.Int64("batchSize", batchSize))
+	log.Debug("computing decreased requested IP count", zap.Int64("modResult", modResult))
 
 	if modResult != 0 {
 		// Example: previouscount = 25, batchsize = 10, 25 - 10 = 15, NOT a multiple of batchsize (10)
@@ -309,10 +423,10 @@ func (pm *Monitor) decreasePoolSize(ctx context.Context, meta metaState, state i
 
 	decreaseIPCountBy := previouslyRequestedIPCount - updatedRequestedIPCount
 
-	logger.Printf("[ipam-pool-monitor] updatedRequestedIPCount %d", updatedRequestedIPCount)
+	log.Debug("computed decreased requested IP count", zap.Int64("updatedRequestedIPCount", updatedRequestedIPCount))
 
 	if meta.notInUseCount == 0 || meta.notInUseCount < state.pendingRelease {
-		logger.Printf("[ipam-pool-monitor] Marking IPs as PendingRelease, ipsToBeReleasedCount %d", decreaseIPCountBy)
+		log.Info("marking IPs as pending release", zap.Int64("count", decreaseIPCountBy))
 		var err error
 		if pendingIPAddresses, err = pm.httpService.MarkIPAsPendingRelease(int(decreaseIPCountBy)); err != nil {
 			return errors.Wrap(err, "marking IPs that are pending release")
@@ -328,19 +442,17 @@ func (pm *Monitor) decreasePoolSize(ctx context.Context, meta metaState, state i
 		pm.metastate.notInUseCount = int64(len(tempNNCSpec.IPsNotInUse))
 	}
 
-	logger.Printf("[ipam-pool-monitor] Releasing IPCount in this batch %d, updatingPendingIpsNotInUse count %d",
-		len(pendingIPAddresses), pm.metastate.notInUseCount)
-
 	tempNNCSpec.RequestedIPCount -= int64(len(pendingIPAddresses))
-	logger.Printf("[ipam-pool-monitor] Decreasing pool size, pool %+v, spec %+v", state, tempNNCSpec)
+	log.Info("releasing IPs", zap.Int("count", len(pendingIPAddresses)), zap.Int64("notInUseCount", pm.metastate.notInUseCount),
+		zap.Int64("requestedIPCount", tempNNCSpec.RequestedIPCount))
 
-	_, err := pm.nnccli.UpdateSpec(ctx, &tempNNCSpec)
-	if err != nil {
+	if err := pm.updateSpec(ctx, "decrease", &tempNNCSpec); err != nil {
 		// caller will retry to update the CRD again
-		return errors.Wrap(err, "executing UpdateSpec with NNC CLI")
+		return err
 	}
 
-	logger.Printf("[ipam-pool-monitor] Decreasing pool size: UpdateCRDSpec succeeded for spec %+v", tempNNCSpec)
+	log.Info("decreased pool size", zap.Int64("requestedIPCount", tempNNCSpec.RequestedIPCount))
+	pm.recordScaleEvent(previouslyRequestedIPCount, tempNNCSpec.RequestedIPCount)
 	// start a dealloc timer
 	metric.StartPoolDecreaseTimer(batchSize)
 
@@ -348,7 +460,6 @@ func (pm *Monitor) decreasePoolSize(ctx context.Context, meta metaState, state i
 	pm.spec = tempNNCSpec
 
 	// clear the updatingPendingIpsNotInUse, as we have Updated the CRD
-	logger.Printf("[ipam-pool-monitor] cleaning the updatingPendingIpsNotInUse, existing length %d", pm.metastate.notInUseCount)
 	pm.metastate.notInUseCount = 0
 
 	return nil
@@ -359,19 +470,44 @@ func (pm *Monitor) decreasePoolSize(ctx context.Context, meta metaState, state i
 func (pm *Monitor) cleanPendingRelease(ctx context.Context) error {
 	tempNNCSpec := pm.createNNCSpecForCRD()
 
-	_, err := pm.nnccli.UpdateSpec(ctx, &tempNNCSpec)
-	if err != nil {
+	if err := pm.updateSpec(ctx, "cleanPendingRelease", &tempNNCSpec); err != nil {
 		// caller will retry to update the CRD again
-		return errors.Wrap(err, "executing UpdateSpec with NNC CLI")
+		return err
 	}
 
-	logger.Printf("[ipam-pool-monitor] cleanPendingRelease: UpdateCRDSpec succeeded for spec %+v", tempNNCSpec)
+	pm.log.Info("removed pending release IPs from NNC", zap.Int("ipsNotInUse", len(tempNNCSpec.IPsNotInUse)))
 
 	// save the updated state to cachedSpec
 	pm.spec = tempNNCSpec
 	return nil
 }
 
+// updateSpec writes the spec to the NNC in a span which records the operation scaling the pool.
+func (pm *Monitor) updateSpec(ctx context.Context, operation string, spec *v1alpha.NodeNetworkConfigSpec) error {
+	_, span := otlp.Tracer().Start(ctx, "ipampool UpdateNNC",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("operation", operation),
+			attribute.Int64("requested_ip_count", spec.RequestedIPCount),
+			attribute.Int("ips_not_in_use", len(spec.IPsNotInUse))))
+	defer span.End()
+
+	if _, err := pm.nnccli.UpdateSpec(ctx, spec); err != nil {
+		err = errors.Wrap(err, "executing UpdateSpec with NNC CLI")
+		otlp.SetError(span, err)
+		return err
+	}
+	span.SetStatus(codes.Ok, "")
+	return nil
+}
+
+// recordScaleEvent keeps the change of the requested IP count for the published IPAM state.
+func (pm *Monitor) recordScaleEvent(previousRequestedIPCount, requestedIPCount int64) {
+	if pm.statePublisher != nil {
+		pm.statePublisher.recordScaleEvent(previousRequestedIPCount, requestedIPCount)
+	}
+}
+
 // createNNCSpecForCRD translates CNS's map of IPs to be released and requested IP count into an NNC Spec.
 func (pm *Monitor) createNNCSpecForCRD() v1alpha.NodeNetworkConfigSpec {
 	var spec v1alpha.NodeNetworkConfigSpec
@@ -431,7 +567,7 @@ func (pm *Monitor) Update(nnc *v1alpha.NodeNetworkConfig) error {
 		// observe elapsed duration for IP pool scaling
 		metric.ObserverPoolScaleLatency()
 	}
-	logger.Printf("[ipam-pool-monitor] pushing NodeNetworkConfig update, allocatedIPs = %d", allocatedIPs)
+	pm.log.Debug("pushing NodeNetworkConfig update", zap.Int("allocatedIPs", allocatedIPs))
 	pm.nncSource <- *nnc
 	return nil
 }
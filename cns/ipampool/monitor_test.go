@@ -9,7 +9,9 @@ import (
 	"github.com/Azure/azure-container-networking/cns/fakes"
 	"github.com/Azure/azure-container-networking/cns/logger"
 	"github.com/Azure/azure-container-networking/crd/nodenetworkconfig/api/v1alpha"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type fakeNodeNetworkConfigUpdater struct {
@@ -62,7 +64,7 @@ func initFakes(state testState) (*fakes.HTTPServiceFake, *fakes.RequestControlle
 	fakecns := fakes.NewHTTPServiceFake()
 	fakerc := fakes.NewRequestControllerFake(fakecns, scalarUnits, subnetaddresspace, state.totalIPs)
 
-	poolmonitor := NewMonitor(fakecns, &fakeNodeNetworkConfigUpdater{fakerc.NNC}, nil, &Options{RefreshDelay: 100 * time.Second})
+	poolmonitor := NewMonitor(fakecns, &fakeNodeNetworkConfigUpdater{fakerc.NNC}, nil, nil, &Options{RefreshDelay: 100 * time.Second})
 	poolmonitor.metastate = metaState{
 		batch:     state.batch,
 		max:       state.max,
@@ -633,3 +635,57 @@ func TestCalculateIPs(t *testing.T) {
 		})
 	}
 }
+
+type failingNodeNetworkConfigUpdater struct {
+	fakeNodeNetworkConfigUpdater
+	failures int
+	calls    chan struct{}
+}
+
+func (f *failingNodeNetworkConfigUpdater) UpdateSpec(ctx context.Context, spec *v1alpha.NodeNetworkConfigSpec) (*v1alpha.NodeNetworkConfig, error) {
+	f.calls <- struct{}{}
+	if f.failures > 0 {
+		f.failures--
+		return nil, errors.New("update failed")
+	}
+	return f.fakeNodeNetworkConfigUpdater.UpdateSpec(ctx, spec)
+}
+
+func TestStartRetriesFailedReconcile(t *testing.T) {
+	_, fakerc, poolmonitor := initFakes(testState{
+		allocated:               10,
+		assigned:                8,
+		batch:                   10,
+		max:                     30,
+		releaseThresholdPercent: 150,
+		requestThresholdPercent: 50,
+	})
+	require.NoError(t, fakerc.Reconcile(true))
+	updater := &failingNodeNetworkConfigUpdater{
+		fakeNodeNetworkConfigUpdater: fakeNodeNetworkConfigUpdater{fakerc.NNC},
+		failures:                     2,
+		calls:                        make(chan struct{}, 10),
+	}
+	poolmonitor.nnccli = updater
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = poolmonitor.Start(ctx) }()
+	require.NoError(t, poolmonitor.Update(fakerc.NNC))
+
+	// the first update and both retries happen well before the 100s RefreshDelay.
+	for i := 0; i < 3; i++ {
+		select {
+		case <-updater.calls:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("reconcile was not retried, got %d updates", i)
+		}
+	}
+}
+
+func TestNextRetryDelay(t *testing.T) {
+	assert.Equal(t, minRetryDelay, nextRetryDelay(0, time.Minute))
+	assert.Equal(t, 2*minRetryDelay, nextRetryDelay(minRetryDelay, time.Minute))
+	assert.Equal(t, time.Minute, nextRetryDelay(time.Minute, time.Minute))
+	assert.Equal(t, 100*time.Millisecond, nextRetryDelay(0, 100*time.Millisecond))
+}
@@ -0,0 +1,101 @@
+package ipampool
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/Azure/azure-container-networking/cns"
+	"github.com/Azure/azure-container-networking/cns/types"
+	"github.com/Azure/azure-container-networking/crd/nodenetworkconfig/api/v1alpha"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// minIPAMStatePublishInterval limits how often a changing IPAM state is published, to bound the load on the apiserver.
+	minIPAMStatePublishInterval = 15 * time.Second
+	// maxIPAMStatePublishInterval is how often an unchanged IPAM state is published again,
+	// in case it was dropped by a writer of the NNC status which doesn't know about it.
+	maxIPAMStatePublishInterval = 5 * time.Minute
+)
+
+// nodeNetworkConfigIPAMStatePatcher is implemented by NNC clients which can publish the IPAM state.
+type nodeNetworkConfigIPAMStatePatcher interface {
+	PatchIPAMState(context.Context, *v1alpha.IPAMState) error
+}
+
+// ipamStatePublisher tracks the scale events and errors of the Monitor, and publishes them with
+// a summary of the IP pool to the NodeNetworkConfig status so that it can be inspected with kubectl.
+type ipamStatePublisher struct {
+	log            *zap.Logger
+	patcher        nodeNetworkConfigIPAMStatePatcher
+	lastScaleEvent *v1alpha.ScaleEvent
+	lastError      string
+	published      *v1alpha.IPAMState
+	publishedAt    time.Time
+}
+
+func (p *ipamStatePublisher) recordScaleEvent(previousRequestedIPCount, requestedIPCount int64) {
+	p.lastScaleEvent = &v1alpha.ScaleEvent{
+		Time:                     metav1.Now(),
+		PreviousRequestedIPCount: previousRequestedIPCount,
+		RequestedIPCount:         requestedIPCount,
+	}
+}
+
+// recordReconcile keeps the error of the last reconcile, which is cleared once a reconcile succeeds.
+func (p *ipamStatePublisher) recordReconcile(err error) {
+	if err != nil {
+		p.lastError = err.Error()
+		return
+	}
+	p.lastError = ""
+}
+
+// publish patches the NNC status with the IPAM state if it changed since it was last published,
+// or if it hasn't been published for a while. Publishing is rate limited, so some changes are only published later.
+func (p *ipamStatePublisher) publish(ctx context.Context, ips map[string]cns.IPConfigurationStatus) error {
+	state := buildIPAMState(ips)
+	state.LastScaleEvent = p.lastScaleEvent
+	state.LastError = p.lastError
+
+	sincePublished := time.Since(p.publishedAt)
+	if sincePublished < minIPAMStatePublishInterval {
+		return nil
+	}
+	if sincePublished < maxIPAMStatePublishInterval && p.published != nil && reflect.DeepEqual(state, p.published) {
+		return nil
+	}
+
+	published := state.DeepCopy()
+	state.LastUpdateTime = metav1.Now()
+	if err := p.patcher.PatchIPAMState(ctx, state); err != nil {
+		return errors.Wrap(err, "failed to publish ipam state")
+	}
+	p.log.Info("published ipam state", zap.Any("ipCounts", state.IPCounts))
+	p.published, p.publishedAt = published, time.Now()
+	return nil
+}
+
+// buildIPAMState summarizes the IPs in CNS by state and the Pods they are assigned to.
+func buildIPAMState(ips map[string]cns.IPConfigurationStatus) *v1alpha.IPAMState {
+	state := &v1alpha.IPAMState{
+		IPCounts: map[string]int64{
+			string(types.Available):          0,
+			string(types.Assigned):           0,
+			string(types.PendingRelease):     0,
+			string(types.PendingProgramming): 0,
+		},
+		AssignedIPs: make(map[string]string),
+	}
+	for i := range ips {
+		ip := ips[i]
+		state.IPCounts[string(ip.GetState())]++
+		if ip.GetState() == types.Assigned && ip.PodInfo != nil {
+			state.AssignedIPs[ip.IPAddress] = ip.PodInfo.Namespace() + "/" + ip.PodInfo.Name()
+		}
+	}
+	return state
+}
@@ -0,0 +1,88 @@
+package ipampool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-container-networking/cns"
+	"github.com/Azure/azure-container-networking/cns/types"
+	"github.com/Azure/azure-container-networking/crd/nodenetworkconfig/api/v1alpha"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fakeIPAMStatePatcher struct {
+	states []*v1alpha.IPAMState
+}
+
+func (f *fakeIPAMStatePatcher) PatchIPAMState(_ context.Context, state *v1alpha.IPAMState) error {
+	f.states = append(f.states, state)
+	return nil
+}
+
+func newIPConfig(ip string, state types.IPState, podInfo cns.PodInfo) cns.IPConfigurationStatus {
+	ipConfig := cns.IPConfigurationStatus{ID: ip, IPAddress: ip, PodInfo: podInfo}
+	ipConfig.SetState(state)
+	return ipConfig
+}
+
+func TestBuildIPAMState(t *testing.T) {
+	ips := map[string]cns.IPConfigurationStatus{
+		"10.0.0.1": newIPConfig("10.0.0.1", types.Assigned, cns.NewPodInfo("", "", "a", "x")),
+		"10.0.0.2": newIPConfig("10.0.0.2", types.Assigned, cns.NewPodInfo("", "", "b", "y")),
+		"10.0.0.3": newIPConfig("10.0.0.3", types.Available, nil),
+		"10.0.0.4": newIPConfig("10.0.0.4", types.PendingRelease, nil),
+	}
+	assert.Equal(t, &v1alpha.IPAMState{
+		IPCounts: map[string]int64{
+			"Available":          1,
+			"Assigned":           2,
+			"PendingRelease":     1,
+			"PendingProgramming": 0,
+		},
+		AssignedIPs: map[string]string{
+			"10.0.0.1": "x/a",
+			"10.0.0.2": "y/b",
+		},
+	}, buildIPAMState(ips))
+}
+
+func TestPublishIPAMState(t *testing.T) {
+	patcher := &fakeIPAMStatePatcher{}
+	p := &ipamStatePublisher{log: zap.NewNop(), patcher: patcher}
+	ips := map[string]cns.IPConfigurationStatus{
+		"10.0.0.1": newIPConfig("10.0.0.1", types.Available, nil),
+	}
+	ctx := context.Background()
+
+	p.recordScaleEvent(10, 20)
+	p.recordReconcile(errors.New("failed to update nnc"))
+	require.NoError(t, p.publish(ctx, ips))
+	require.Len(t, patcher.states, 1)
+	assert.Equal(t, int64(20), patcher.states[0].LastScaleEvent.RequestedIPCount)
+	assert.Equal(t, "failed to update nnc", patcher.states[0].LastError)
+	assert.False(t, patcher.states[0].LastUpdateTime.IsZero())
+
+	// changes are rate limited
+	p.recordReconcile(nil)
+	require.NoError(t, p.publish(ctx, ips))
+	require.Len(t, patcher.states, 1)
+
+	// a change is published once the rate limit passes
+	p.publishedAt = time.Now().Add(-minIPAMStatePublishInterval)
+	require.NoError(t, p.publish(ctx, ips))
+	require.Len(t, patcher.states, 2)
+	assert.Empty(t, patcher.states[1].LastError)
+
+	// an unchanged state isn't published again until the max interval passes
+	p.publishedAt = time.Now().Add(-minIPAMStatePublishInterval)
+	require.NoError(t, p.publish(ctx, ips))
+	require.Len(t, patcher.states, 2)
+
+	p.publishedAt = time.Now().Add(-maxIPAMStatePublishInterval)
+	require.NoError(t, p.publish(ctx, ips))
+	require.Len(t, patcher.states, 3)
+}
@@ -2,6 +2,7 @@ package nodenetworkconfig
 
 import (
 	"context"
+	"net/http"
 	"sync"
 
 	"github.com/Azure/azure-container-networking/cns"
@@ -11,7 +12,9 @@ import (
 	"github.com/Azure/azure-container-networking/crd/nodenetworkconfig"
 	"github.com/Azure/azure-container-networking/crd/nodenetworkconfig/api/v1alpha"
 	"github.com/pkg/errors"
+	"go.uber.org/zap"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -22,6 +25,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+var errNotStarted = errors.New("no NodeNetworkConfig has been reconciled yet")
+
 type cnsClient interface {
 	CreateOrUpdateNetworkContainerInternal(*cns.CreateNetworkContainerRequest) cnstypes.ResponseCode
 }
@@ -36,6 +41,7 @@ type nncGetter interface {
 
 // Reconciler watches for CRD status changes
 type Reconciler struct {
+	log                *zap.Logger
 	cnscli             cnsClient
 	ipampoolmonitorcli nodeNetworkConfigListener
 	nnccli             nncGetter
@@ -50,6 +56,7 @@ type Reconciler struct {
 // passed Listeners are notified in the order provided.
 func NewReconciler(cnscli cnsClient, ipampoolmonitorcli nodeNetworkConfigListener, nodeIP string) *Reconciler {
 	return &Reconciler{
+		log:                logger.Named(logger.KubeController).With(zap.String("controller", "nodenetworkconfig")),
 		cnscli:             cnscli,
 		ipampoolmonitorcli: ipampoolmonitorcli,
 		started:            make(chan interface{}),
@@ -63,14 +70,15 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 	nnc, err := r.nnccli.Get(ctx, req.NamespacedName)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
-			logger.Printf("[cns-rc] CRD not found, ignoring %v", err)
+			r.log.Info("NodeNetworkConfig not found, ignoring", zap.Stringer("nnc", req.NamespacedName))
 			return reconcile.Result{}, errors.Wrapf(client.IgnoreNotFound(err), "NodeNetworkConfig %v not found", req.NamespacedName)
 		}
-		logger.Errorf("[cns-rc] Error retrieving CRD from cache : %v", err)
+		r.log.Error("failed to get NodeNetworkConfig from cache", zap.Stringer("nnc", req.NamespacedName), zap.Error(err))
 		return reconcile.Result{}, errors.Wrapf(err, "failed to get NodeNetworkConfig %v", req.NamespacedName)
 	}
 
-	logger.Printf("[cns-rc] CRD Spec: %+v", nnc.Spec)
+	r.log.Info("reconciling NodeNetworkConfig", zap.Stringer("nnc", req.NamespacedName),
+		zap.Int64("requestedIPCount", nnc.Spec.RequestedIPCount), zap.Strings("ipsNotInUse", nnc.Spec.IPsNotInUse))
 
 	ipAssignments := 0
 
@@ -80,8 +88,8 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		if r.nodeIP != "" {
 			if r.nodeIP != nnc.Status.NetworkContainers[i].NodeIP {
 				// skip this NC since it was created for a different node
-				logger.Printf("[cns-rc] skipping network container %s found in NNC because node IP doesn't match, got %s, expected %s",
-					nnc.Status.NetworkContainers[i].ID, nnc.Status.NetworkContainers[i].NodeIP, r.nodeIP)
+				r.log.Info("skipping network container for a different node", zap.String("nc", nnc.Status.NetworkContainers[i].ID),
+					zap.String("ncNodeIP", nnc.Status.NetworkContainers[i].NodeIP), zap.String("nodeIP", r.nodeIP))
 				continue
 			}
 		}
@@ -98,15 +106,15 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		}
 
 		if err != nil {
-			logger.Errorf("[cns-rc] failed to generate CreateNCRequest from NC: %v, assignmentMode %s", err,
-				nnc.Status.NetworkContainers[i].AssignmentMode)
+			r.log.Error("failed to generate CreateNCRequest from NC", zap.String("nc", nnc.Status.NetworkContainers[i].ID),
+				zap.String("assignmentMode", string(nnc.Status.NetworkContainers[i].AssignmentMode)), zap.Error(err))
 			return reconcile.Result{}, errors.Wrapf(err, "failed to generate CreateNCRequest from NC "+
 				"assignmentMode %s", nnc.Status.NetworkContainers[i].AssignmentMode)
 		}
 
 		responseCode := r.cnscli.CreateOrUpdateNetworkContainerInternal(req)
 		if err := restserver.ResponseCodeToError(responseCode); err != nil {
-			logger.Errorf("[cns-rc] Error creating or updating NC in reconcile: %v", err)
+			r.log.Error("failed to create or update NC", zap.String("nc", req.NetworkContainerid), zap.Error(err))
 			return reconcile.Result{}, errors.Wrap(err, "failed to create or update network container")
 		}
 		ipAssignments += len(req.SecondaryIPConfigs)
@@ -125,7 +133,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 	// we have received and pushed an NNC update, we are "Started"
 	r.once.Do(func() {
 		close(r.started)
-		logger.Printf("[cns-rc] CNS NNC Reconciler Started")
+		r.log.Info("started")
 	})
 	return reconcile.Result{}, nil
 }
@@ -144,6 +152,34 @@ func (r *Reconciler) Started(ctx context.Context) bool {
 	}
 }
 
+// StartedCheck is a healthz.Checker which fails until the Reconciler has Started.
+func (r *Reconciler) StartedCheck(*http.Request) error {
+	select {
+	case <-r.started:
+		return nil
+	default:
+		return errNotStarted
+	}
+}
+
+// onlyIPAMStateChanged returns true if the spec and status of the NNCs only differ in the IPAM state of the status.
+func onlyIPAMStateChanged(oldObj, newObj client.Object) bool {
+	oldNNC, ok := oldObj.(*v1alpha.NodeNetworkConfig)
+	if !ok {
+		return false
+	}
+	newNNC, ok := newObj.(*v1alpha.NodeNetworkConfig)
+	if !ok {
+		return false
+	}
+	if equality.Semantic.DeepEqual(oldNNC.Status.IPAM, newNNC.Status.IPAM) {
+		return false
+	}
+	oldStatus, newStatus := oldNNC.Status.DeepCopy(), newNNC.Status.DeepCopy()
+	oldStatus.IPAM, newStatus.IPAM = nil, nil
+	return equality.Semantic.DeepEqual(oldNNC.Spec, newNNC.Spec) && equality.Semantic.DeepEqual(oldStatus, newStatus)
+}
+
 // SetupWithManager Sets up the reconciler with a new manager, filtering using NodeNetworkConfigFilter on nodeName.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager, node *v1.Node) error {
 	r.nnccli = nodenetworkconfig.NewClient(mgr.GetClient())
@@ -165,6 +201,12 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager, node *v1.Node) error {
 				return ue.ObjectOld.GetGeneration() == ue.ObjectNew.GetGeneration()
 			},
 		}).
+		WithEventFilter(predicate.Funcs{
+			// ignore the IPAM state which CNS publishes to the status itself.
+			UpdateFunc: func(ue event.UpdateEvent) bool {
+				return !onlyIPAMStateChanged(ue.ObjectOld, ue.ObjectNew)
+			},
+		}).
 		Complete(r)
 	if err != nil {
 		return errors.Wrap(err, "failed to set up reconciler with manager")
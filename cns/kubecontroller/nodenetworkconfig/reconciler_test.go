@@ -179,6 +179,7 @@ func TestReconcile(t *testing.T) {
 			got, err := r.Reconcile(context.Background(), tt.in)
 			if tt.wantErr {
 				require.Error(t, err)
+				assert.Error(t, r.StartedCheck(nil))
 				return
 			}
 			require.NoError(t, err)
@@ -187,3 +188,25 @@ func TestReconcile(t *testing.T) {
 		})
 	}
 }
+
+func TestOnlyIPAMStateChanged(t *testing.T) {
+	oldNNC := &v1alpha.NodeNetworkConfig{
+		Spec:   v1alpha.NodeNetworkConfigSpec{RequestedIPCount: 16},
+		Status: v1alpha.NodeNetworkConfigStatus{AssignedIPCount: 16},
+	}
+
+	ipamChanged := oldNNC.DeepCopy()
+	ipamChanged.Status.IPAM = &v1alpha.IPAMState{IPCounts: map[string]int64{"Available": 16}}
+	assert.True(t, onlyIPAMStateChanged(oldNNC, ipamChanged))
+
+	statusChanged := ipamChanged.DeepCopy()
+	statusChanged.Status.AssignedIPCount = 32
+	assert.False(t, onlyIPAMStateChanged(ipamChanged, statusChanged))
+
+	specChanged := ipamChanged.DeepCopy()
+	specChanged.Spec.RequestedIPCount = 32
+	specChanged.Status.IPAM.IPCounts["Available"] = 8
+	assert.False(t, onlyIPAMStateChanged(ipamChanged, specChanged))
+
+	assert.False(t, onlyIPAMStateChanged(oldNNC, oldNNC.DeepCopy()))
+}
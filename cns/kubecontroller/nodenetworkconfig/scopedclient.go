@@ -34,3 +34,9 @@ func (sc *ScopedClient) UpdateSpec(ctx context.Context, spec *v1alpha.NodeNetwor
 	nnc, err := sc.Client.UpdateSpec(ctx, sc.NamespacedName, spec)
 	return nnc, errors.Wrapf(err, "failed to update nnc %v", sc.NamespacedName)
 }
+
+// PatchIPAMState sets the IPAM state in the status of the associated NodeNetworkConfig.
+func (sc *ScopedClient) PatchIPAMState(ctx context.Context, state *v1alpha.IPAMState) error {
+	err := sc.Client.PatchIPAMState(ctx, sc.NamespacedName, state)
+	return errors.Wrapf(err, "failed to patch ipam state of nnc %v", sc.NamespacedName)
+}
@@ -1,22 +1,35 @@
 package logger
 
 import (
-	"fmt"
+	"os"
 	"sync"
 
 	"github.com/Azure/azure-container-networking/aitelemetry"
 	"github.com/Azure/azure-container-networking/cns/types"
 	"github.com/Azure/azure-container-networking/log"
+	"github.com/Azure/azure-container-networking/otlp"
 	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
+// CNSLogger writes the logs of CNS as JSON entries to the log target of the legacy logger, through the named zap
+// logger of each subsystem, and sends them to the telemetry handle once it is initialized.
+// The printf methods log to the CNS subsystem.
 type CNSLogger struct {
 	logger               *log.Logger
+	zap                  *zap.Logger
+	sugar                *zap.SugaredLogger
 	th                   aitelemetry.TelemetryHandle
 	DisableTraceLogging  bool
 	DisableMetricLogging bool
 	DisableEventLogging  bool
 
+	levelsMu     sync.RWMutex
+	levels       map[string]zap.AtomicLevel
+	sugars       map[string]*zap.SugaredLogger
+	defaultLevel zapcore.Level
+
 	m            sync.RWMutex
 	Orchestrator string
 	NodeID       string
@@ -28,7 +41,22 @@ func NewCNSLogger(fileName string, logLevel, logTarget int, logDir string) (*CNS
 		return nil, errors.Wrap(err, "could not get new logger")
 	}
 
-	return &CNSLogger{logger: l}, nil
+	c := &CNSLogger{
+		logger:       l,
+		levels:       map[string]zap.AtomicLevel{},
+		sugars:       map[string]*zap.SugaredLogger{},
+		defaultLevel: zapLevel(logLevel),
+	}
+	core := zapcore.NewTee(
+		zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig()), zapcore.AddSync(l), zapcore.DebugLevel),
+		&telemetryCore{c: c},
+	)
+	c.zap = zap.New(core, zap.Fields(zap.Int("pid", os.Getpid())))
+	for _, subsystem := range Subsystems {
+		c.level(subsystem)
+	}
+	c.sugar = c.sugared(CNS)
+	return c, nil
 }
 
 func (c *CNSLogger) InitAI(aiConfig aitelemetry.AIConfig, disableTraceLogging, disableMetricLogging, disableEventLogging bool) {
@@ -38,12 +66,21 @@ func (c *CNSLogger) InitAI(aiConfig aitelemetry.AIConfig, disableTraceLogging, d
 func (c *CNSLogger) InitAIWithIKey(aiConfig aitelemetry.AIConfig, instrumentationKey string, disableTraceLogging, disableMetricLogging, disableEventLogging bool) {
 	th, err := aitelemetry.NewAITelemetry("", instrumentationKey, aiConfig)
 	if err != nil {
-		c.logger.Errorf("Error initializing AI Telemetry:%v", err)
+		c.sugar.Errorf("Error initializing AI Telemetry:%v", err)
 		return
 	}
 
 	c.th = th
-	c.logger.Printf("AI Telemetry Handle created")
+	c.sugar.Infof("AI Telemetry Handle created")
+	c.DisableMetricLogging = disableMetricLogging
+	c.DisableTraceLogging = disableTraceLogging
+	c.DisableEventLogging = disableEventLogging
+}
+
+// InitOTLP sends the telemetry to an OpenTelemetry collector through the exporter, instead of AI.
+func (c *CNSLogger) InitOTLP(exporter *otlp.Exporter, disableTraceLogging, disableMetricLogging, disableEventLogging bool) {
+	c.th = otlp.NewTelemetryHandle(exporter)
+	c.sugar.Infof("OTLP Telemetry Handle created")
 	c.DisableMetricLogging = disableMetricLogging
 	c.DisableTraceLogging = disableTraceLogging
 	c.DisableEventLogging = disableEventLogging
@@ -53,6 +90,7 @@ func (c *CNSLogger) InitAIWithIKey(aiConfig aitelemetry.AIConfig, instrumentatio
 const waitTimeInSecs = 10
 
 func (c *CNSLogger) Close() {
+	_ = c.zap.Sync()
 	c.logger.Close()
 	if c.th != nil {
 		c.th.Close(waitTimeInSecs)
@@ -60,7 +98,7 @@ func (c *CNSLogger) Close() {
 }
 
 func (c *CNSLogger) SetContextDetails(orchestrator, nodeID string) {
-	c.logger.Logf("SetContext details called with: %v orchestrator nodeID %v", orchestrator, nodeID)
+	c.sugar.Infof("SetContext details called with: %v orchestrator nodeID %v", orchestrator, nodeID)
 	c.m.Lock()
 	c.Orchestrator = orchestrator
 	c.NodeID = nodeID
@@ -68,104 +106,78 @@ func (c *CNSLogger) SetContextDetails(orchestrator, nodeID string) {
 }
 
 func (c *CNSLogger) Printf(format string, args ...any) {
-	c.logger.Logf(format, args...)
-
-	if c.th == nil || c.DisableTraceLogging {
-		return
-	}
-
-	msg := fmt.Sprintf(format, args...)
-	c.sendTraceInternal(msg)
+	c.sugar.Infof(format, args...)
 }
 
 func (c *CNSLogger) Debugf(format string, args ...any) {
-	c.logger.Debugf(format, args...)
-
-	if c.th == nil || c.DisableTraceLogging {
-		return
-	}
-
-	msg := fmt.Sprintf(format, args...)
-	c.sendTraceInternal(msg)
+	c.sugar.Debugf(format, args...)
 }
 
 func (c *CNSLogger) Warnf(format string, args ...any) {
-	c.logger.Warnf(format, args...)
-
-	if c.th == nil || c.DisableTraceLogging {
-		return
-	}
-
-	msg := fmt.Sprintf(format, args...)
-	c.sendTraceInternal(msg)
+	c.sugar.Warnf(format, args...)
 }
 
 func (c *CNSLogger) Errorf(format string, args ...any) {
-	c.logger.Errorf(format, args...)
+	c.sugar.Errorf(format, args...)
+}
 
-	if c.th == nil || c.DisableTraceLogging {
-		return
-	}
+func (c *CNSLogger) Request(tag string, request any, err error) {
+	logRequest(c.sugar, tag, request, err)
+}
 
-	msg := fmt.Sprintf(format, args...)
-	c.sendTraceInternal(msg)
+func (c *CNSLogger) Response(tag string, response any, returnCode types.ResponseCode, err error) {
+	logResponse(c.sugar, tag, response, returnCode, err)
 }
 
-func (c *CNSLogger) Request(tag string, request any, err error) {
-	c.logger.Request(tag, request, err)
+func (c *CNSLogger) ResponseEx(tag string, request, response any, returnCode types.ResponseCode, err error) {
+	logResponseEx(c.sugar, tag, request, response, returnCode, err)
+}
 
-	if c.th == nil || c.DisableTraceLogging {
-		return
-	}
+// expectedCodes are the return codes which report the state of CNS to the caller rather than a failure, such as an
+// unknown network container, so their responses are logged at info.
+var expectedCodes = map[types.ResponseCode]struct{}{
+	types.Success:                                {},
+	types.NotFound:                               {},
+	types.UnknownContainerID:                     {},
+	types.NetworkContainerVfpProgramPending:      {},
+	types.NetworkContainerVfpProgramComplete:     {},
+	types.NetworkContainerVfpProgramCheckSkipped: {},
+}
 
-	var msg string
+func logRequest(sugar *zap.SugaredLogger, tag string, request any, err error) {
 	if err == nil {
-		msg = fmt.Sprintf("[%s] Received %T %+v.", tag, request, request)
+		sugar.Infof("[%s] Received %T %+v.", tag, request, request)
 	} else {
-		msg = fmt.Sprintf("[%s] Failed to decode %T %+v %s.", tag, request, request, err.Error())
+		sugar.Errorf("[%s] Failed to decode %T %+v %s.", tag, request, request, err.Error())
 	}
-
-	c.sendTraceInternal(msg)
 }
 
-func (c *CNSLogger) Response(tag string, response any, returnCode types.ResponseCode, err error) {
-	c.logger.Response(tag, response, int(returnCode), returnCode.String(), err)
-
-	if c.th == nil || c.DisableTraceLogging {
-		return
-	}
-
-	var msg string
+func logResponse(sugar *zap.SugaredLogger, tag string, response any, returnCode types.ResponseCode, err error) {
+	_, expected := expectedCodes[returnCode]
 	switch {
 	case err == nil && returnCode == 0:
-		msg = fmt.Sprintf("[%s] Sent %T %+v.", tag, response, response)
+		sugar.Infof("[%s] Sent %T %+v.", tag, response, response)
 	case err != nil:
-		msg = fmt.Sprintf("[%s] Code:%s, %+v %s.", tag, returnCode.String(), response, err.Error())
+		sugar.Errorf("[%s] Code:%s, %+v %s.", tag, returnCode.String(), response, err.Error())
+	case expected:
+		sugar.Infof("[%s] Code:%s, %+v.", tag, returnCode.String(), response)
 	default:
-		msg = fmt.Sprintf("[%s] Code:%s, %+v.", tag, returnCode.String(), response)
+		sugar.Errorf("[%s] Code:%s, %+v.", tag, returnCode.String(), response)
 	}
-
-	c.sendTraceInternal(msg)
 }
 
-func (c *CNSLogger) ResponseEx(tag string, request, response any, returnCode types.ResponseCode, err error) {
-	c.logger.ResponseEx(tag, request, response, int(returnCode), returnCode.String(), err)
-
-	if c.th == nil || c.DisableTraceLogging {
-		return
-	}
-
-	var msg string
+func logResponseEx(sugar *zap.SugaredLogger, tag string, request, response any, returnCode types.ResponseCode, err error) {
+	_, expected := expectedCodes[returnCode]
 	switch {
 	case err == nil && returnCode == 0:
-		msg = fmt.Sprintf("[%s] Sent %T %+v %T %+v.", tag, request, request, response, response)
+		sugar.Infof("[%s] Sent %T %+v %T %+v.", tag, request, request, response, response)
 	case err != nil:
-		msg = fmt.Sprintf("[%s] Code:%s, %+v, %+v, %s.", tag, returnCode.String(), request, response, err.Error())
+		sugar.Errorf("[%s] Code:%s, %+v, %+v, %s.", tag, returnCode.String(), request, response, err.Error())
+	case expected:
+		sugar.Infof("[%s] Code:%s, %+v, %+v.", tag, returnCode.String(), request, response)
 	default:
-		msg = fmt.Sprintf("[%s] Code:%s, %+v, %+v.", tag, returnCode.String(), request, response)
+		sugar.Errorf("[%s] Code:%s, %+v, %+v.", tag, returnCode.String(), request, response)
 	}
-
-	c.sendTraceInternal(msg)
 }
 
 func (c *CNSLogger) getOrchestratorAndNodeID() (orch, nodeID string) {
@@ -175,16 +187,15 @@ func (c *CNSLogger) getOrchestratorAndNodeID() (orch, nodeID string) {
 	return
 }
 
-func (c *CNSLogger) sendTraceInternal(msg string) {
+func (c *CNSLogger) sendTraceInternal(msg string, dimensions map[string]string) {
 	orch, nodeID := c.getOrchestratorAndNodeID()
+	dimensions[OrchestratorTypeStr] = orch
+	dimensions[NodeIDStr] = nodeID
 
 	report := aitelemetry.Report{
-		Message: msg,
-		Context: nodeID,
-		CustomDimensions: map[string]string{
-			OrchestratorTypeStr: orch,
-			NodeIDStr:           nodeID,
-		},
+		Message:          msg,
+		Context:          nodeID,
+		CustomDimensions: dimensions,
 	}
 
 	c.th.TrackLog(report)
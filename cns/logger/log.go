@@ -2,8 +2,12 @@
 package logger
 
 import (
+	"net/http"
+
 	"github.com/Azure/azure-container-networking/aitelemetry"
 	"github.com/Azure/azure-container-networking/cns/types"
+	"github.com/Azure/azure-container-networking/otlp"
+	"go.uber.org/zap"
 )
 
 var (
@@ -29,6 +33,10 @@ func InitAIWithIKey(aiConfig aitelemetry.AIConfig, instrumentationKey string, di
 	Log.InitAIWithIKey(aiConfig, instrumentationKey, disableTraceLogging, disableMetricLogging, disableEventLogging)
 }
 
+func InitOTLP(exporter *otlp.Exporter, disableTraceLogging, disableMetricLogging, disableEventLogging bool) {
+	Log.InitOTLP(exporter, disableTraceLogging, disableMetricLogging, disableEventLogging)
+}
+
 func SetContextDetails(orchestrator, nodeID string) {
 	Log.SetContextDetails(orchestrator, nodeID)
 }
@@ -68,3 +76,19 @@ func ResponseEx(tag string, request, response any, returnCode types.ResponseCode
 func SendMetric(metric aitelemetry.Metric) {
 	Log.SendMetric(metric)
 }
+
+// Named returns the structured logger of the subsystem, or a no-op logger if the logger is not initialized.
+func Named(subsystem string) *zap.Logger {
+	if Log == nil {
+		return zap.NewNop()
+	}
+	return Log.Named(subsystem)
+}
+
+func SetLevel(subsystem, level string) error {
+	return Log.SetLevel(subsystem, level)
+}
+
+func LevelsHandler() http.Handler {
+	return Log.LevelsHandler()
+}
@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"github.com/Azure/azure-container-networking/cns/types"
+	"go.uber.org/zap"
+)
+
+// Subsystem logs the printf-style messages of the package functions through the named logger of a subsystem, so that
+// the level of the subsystem applies to them. Packages declare their Subsystem before the logger is initialized, so
+// the logger is looked up on each call, and nothing is logged until it is initialized.
+type Subsystem string
+
+var nopSugar = zap.NewNop().Sugar()
+
+func (s Subsystem) sugar() *zap.SugaredLogger {
+	if Log == nil {
+		return nopSugar
+	}
+	return Log.sugared(string(s))
+}
+
+func (s Subsystem) Printf(format string, args ...any) {
+	s.sugar().Infof(format, args...)
+}
+
+func (s Subsystem) Debugf(format string, args ...any) {
+	s.sugar().Debugf(format, args...)
+}
+
+func (s Subsystem) Warnf(format string, args ...any) {
+	s.sugar().Warnf(format, args...)
+}
+
+func (s Subsystem) Errorf(format string, args ...any) {
+	s.sugar().Errorf(format, args...)
+}
+
+func (s Subsystem) Request(tag string, request any, err error) {
+	logRequest(s.sugar(), tag, request, err)
+}
+
+func (s Subsystem) Response(tag string, response any, returnCode types.ResponseCode, err error) {
+	logResponse(s.sugar(), tag, response, returnCode, err)
+}
+
+func (s Subsystem) ResponseEx(tag string, request, response any, returnCode types.ResponseCode, err error) {
+	logResponseEx(s.sugar(), tag, request, response, returnCode, err)
+}
@@ -0,0 +1,220 @@
+// Copyright Microsoft. All rights reserved.
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-container-networking/log"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Subsystems of CNS with their own named loggers, whose levels can be changed independently.
+const (
+	// CNS is the logger of the printf functions of this package.
+	CNS            = "cns"
+	RestServer     = "restserver"
+	IPAMPool       = "ipampool"
+	NMAgent        = "nmagent"
+	KubeController = "kubecontroller"
+	Telemetry      = "telemetry"
+)
+
+// Subsystems lists the named loggers of CNS.
+var Subsystems = []string{CNS, RestServer, IPAMPool, NMAgent, KubeController, Telemetry}
+
+// zapLevel maps the levels of the legacy logger to zap levels.
+func zapLevel(level int) zapcore.Level {
+	switch {
+	case level >= log.LevelDebug:
+		return zapcore.DebugLevel
+	case level == log.LevelInfo:
+		return zapcore.InfoLevel
+	case level == log.LevelWarning:
+		return zapcore.WarnLevel
+	default:
+		return zapcore.ErrorLevel
+	}
+}
+
+func encoderConfig() zapcore.EncoderConfig {
+	cfg := zap.NewProductionEncoderConfig()
+	cfg.EncodeTime = zapcore.ISO8601TimeEncoder
+	return cfg
+}
+
+// levelCore filters the entries of a named logger by its level, so that the level of each subsystem can be
+// changed at runtime. zapcore.NewIncreaseLevelCore can only raise the level of the core it wraps.
+type levelCore struct {
+	zapcore.Core
+	level zap.AtomicLevel
+}
+
+func (c *levelCore) Enabled(l zapcore.Level) bool {
+	return c.level.Enabled(l)
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), level: c.level}
+}
+
+//nolint:gocritic // ignore hugeparam in interface impl
+func (c *levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.level.Enabled(entry.Level) {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
+
+// telemetryCore sends the entries of the loggers as traces to the telemetry handle of the CNSLogger, once it is
+// initialized, with the name of the logger and the fields of the entry as custom dimensions.
+type telemetryCore struct {
+	c      *CNSLogger
+	fields []zapcore.Field
+}
+
+func (*telemetryCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+func (t *telemetryCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *t
+	clone.fields = make([]zapcore.Field, 0, len(t.fields)+len(fields))
+	clone.fields = append(clone.fields, t.fields...)
+	clone.fields = append(clone.fields, fields...)
+	return &clone
+}
+
+//nolint:gocritic // ignore hugeparam in interface impl
+func (t *telemetryCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if t.c.th == nil || t.c.DisableTraceLogging {
+		return checked
+	}
+	return checked.AddCore(entry, t)
+}
+
+//nolint:gocritic // ignore hugeparam in interface impl
+func (t *telemetryCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for i := range t.fields {
+		t.fields[i].AddTo(enc)
+	}
+	for i := range fields {
+		fields[i].AddTo(enc)
+	}
+	dimensions := make(map[string]string, len(enc.Fields)+1)
+	for k, v := range enc.Fields {
+		dimensions[k] = fmt.Sprint(v)
+	}
+	if entry.LoggerName != "" {
+		dimensions["logger"] = entry.LoggerName
+	}
+	t.c.sendTraceInternal(entry.Message, dimensions)
+	return nil
+}
+
+func (*telemetryCore) Sync() error {
+	return nil
+}
+
+// Named returns the structured logger of the subsystem, which writes JSON entries to the log target and sends
+// them to the telemetry handle. Its level is the level of the subsystem, which starts as the level of the
+// CNSLogger and can be changed with SetLevel or the LevelsHandler.
+func (c *CNSLogger) Named(subsystem string) *zap.Logger {
+	level := c.level(subsystem)
+	return c.zap.Named(subsystem).WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &levelCore{Core: core, level: level}
+	}))
+}
+
+// sugared returns the sugared logger of the subsystem, which is built once since the printf functions use it on
+// every call.
+func (c *CNSLogger) sugared(subsystem string) *zap.SugaredLogger {
+	c.levelsMu.RLock()
+	sugar, ok := c.sugars[subsystem]
+	c.levelsMu.RUnlock()
+	if ok {
+		return sugar
+	}
+	sugar = c.Named(subsystem).Sugar()
+	c.levelsMu.Lock()
+	c.sugars[subsystem] = sugar
+	c.levelsMu.Unlock()
+	return sugar
+}
+
+// level returns the level of the subsystem, adding it at the default level if it has none yet.
+func (c *CNSLogger) level(subsystem string) zap.AtomicLevel {
+	c.levelsMu.Lock()
+	defer c.levelsMu.Unlock()
+	level, ok := c.levels[subsystem]
+	if !ok {
+		level = zap.NewAtomicLevelAt(c.defaultLevel)
+		c.levels[subsystem] = level
+	}
+	return level
+}
+
+// SetLevel sets the level of the subsystem to a zap level name, such as "debug" or "warn".
+func (c *CNSLogger) SetLevel(subsystem, level string) error {
+	var l zapcore.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return errors.Wrapf(err, "invalid level for %s", subsystem)
+	}
+	c.level(subsystem).SetLevel(l)
+	return nil
+}
+
+// Levels returns the level of each subsystem.
+func (c *CNSLogger) Levels() map[string]string {
+	c.levelsMu.RLock()
+	defer c.levelsMu.RUnlock()
+	levels := make(map[string]string, len(c.levels))
+	for subsystem, level := range c.levels {
+		levels[subsystem] = level.String()
+	}
+	return levels
+}
+
+// LevelsHandler serves the levels of the subsystems as a JSON object at its root, and the level of each subsystem
+// at /<subsystem>, which is changed by a PUT of {"level":"<level>"}. The path must be stripped of its prefix.
+func (c *CNSLogger) LevelsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		subsystem := strings.Trim(r.URL.Path, "/")
+		if subsystem == "" {
+			if r.Method != http.MethodGet {
+				http.Error(w, "only GET is allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(c.Levels())
+			return
+		}
+
+		c.levelsMu.RLock()
+		level, ok := c.levels[subsystem]
+		c.levelsMu.RUnlock()
+		if !ok {
+			levels := c.Levels()
+			subsystems := make([]string, 0, len(levels))
+			for s := range levels {
+				subsystems = append(subsystems, s)
+			}
+			sort.Strings(subsystems)
+			http.Error(w, fmt.Sprintf("unknown subsystem %q, expected one of %v", subsystem, subsystems), http.StatusNotFound)
+			return
+		}
+
+		before := level.Level()
+		level.ServeHTTP(w, r)
+		if after := level.Level(); after != before {
+			c.zap.Named(CNS).Info("changed log level",
+				zap.String("subsystem", subsystem), zap.Stringer("from", before), zap.Stringer("to", after))
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/cns/types"
+	"github.com/Azure/azure-container-networking/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestLogger(t *testing.T) (c *CNSLogger, entries func() []map[string]any) {
+	t.Helper()
+	dir := t.TempDir()
+	c, err := NewCNSLogger("test", log.LevelInfo, log.TargetLogfile, dir)
+	require.NoError(t, err)
+	t.Cleanup(c.Close)
+
+	return c, func() []map[string]any {
+		b, err := os.ReadFile(filepath.Join(dir, "test.log"))
+		require.NoError(t, err)
+		var entries []map[string]any
+		for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+			if line == "" {
+				continue
+			}
+			entry := map[string]any{}
+			require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+			entries = append(entries, entry)
+		}
+		return entries
+	}
+}
+
+func TestNamedLevels(t *testing.T) {
+	c, entries := newTestLogger(t)
+
+	c.Printf("legacy %s", "message")
+	c.Debugf("hidden")
+	c.Named(IPAMPool).Debug("hidden")
+	require.NoError(t, c.SetLevel(IPAMPool, "debug"))
+	c.Named(IPAMPool).Debug("scaling", zap.Int64("requestedIPCount", 16))
+	c.Named(RestServer).Debug("hidden")
+
+	got := entries()
+	require.Len(t, got, 2)
+	assert.Equal(t, "legacy message", got[0]["msg"])
+	assert.Equal(t, CNS, got[0]["logger"])
+	assert.Equal(t, "info", got[0]["level"])
+	assert.Equal(t, "scaling", got[1]["msg"])
+	assert.Equal(t, IPAMPool, got[1]["logger"])
+	assert.Equal(t, "debug", got[1]["level"])
+	assert.InDelta(t, 16, got[1]["requestedIPCount"], 0)
+
+	require.Error(t, c.SetLevel(IPAMPool, "loud"))
+}
+
+func TestLevelsHandler(t *testing.T) {
+	c, entries := newTestLogger(t)
+	handler := c.LevelsHandler()
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	w := serve(http.MethodGet, "/", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	levels := map[string]string{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &levels))
+	assert.Len(t, levels, len(Subsystems))
+	assert.Equal(t, "info", levels[NMAgent])
+
+	w = serve(http.MethodPut, "/"+NMAgent, `{"level":"debug"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "debug", c.Levels()[NMAgent])
+	assert.Equal(t, "info", c.Levels()[RestServer])
+
+	c.Named(NMAgent).Debug("visible")
+	got := entries()
+	require.Len(t, got, 2)
+	assert.Equal(t, "changed log level", got[0]["msg"])
+	assert.Equal(t, NMAgent, got[0]["subsystem"])
+	assert.Equal(t, "visible", got[1]["msg"])
+
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPut, "/"+NMAgent, `{"level":"loud"}`).Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/unknown", "").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodPut, "/", "").Code)
+}
+
+func TestSubsystem(t *testing.T) {
+	c, entries := newTestLogger(t)
+	prev := Log
+	Log = c
+	t.Cleanup(func() { Log = prev })
+	rest := Subsystem(RestServer)
+
+	rest.Debugf("hidden")
+	require.NoError(t, c.SetLevel(RestServer, "debug"))
+	rest.Debugf("visible %d", 1)
+	rest.Response("test", "resp", types.NotFound, nil)
+	rest.Response("test", "resp", types.UnexpectedError, nil)
+
+	got := entries()
+	require.Len(t, got, 3)
+	assert.Equal(t, "visible 1", got[0]["msg"])
+	assert.Equal(t, RestServer, got[0]["logger"])
+	assert.Equal(t, "debug", got[0]["level"])
+	assert.Equal(t, "info", got[1]["level"])
+	assert.Equal(t, "error", got[2]["level"])
+}
@@ -43,11 +43,11 @@ const (
 
 // Handles requests to set the environment type.
 func (service *HTTPRestService) setEnvironment(w http.ResponseWriter, r *http.Request) {
-	logger.Printf("[Azure CNS] setEnvironment")
+	restLog.Printf("[Azure CNS] setEnvironment")
 
 	var req cns.SetEnvironmentRequest
 	err := service.Listener.Decode(w, r, &req)
-	logger.Request(service.Name, &req, err)
+	restLog.Request(service.Name, &req, err)
 
 	if err != nil {
 		return
@@ -55,7 +55,7 @@ func (service *HTTPRestService) setEnvironment(w http.ResponseWriter, r *http.Re
 
 	switch r.Method {
 	case http.MethodPost:
-		logger.Printf("[Azure CNS]  POST received for SetEnvironment.")
+		restLog.Printf("[Azure CNS]  POST received for SetEnvironment.")
 		service.state.Location = req.Location
 		service.state.NetworkType = req.NetworkType
 		service.state.Initialized = true
@@ -66,12 +66,12 @@ func (service *HTTPRestService) setEnvironment(w http.ResponseWriter, r *http.Re
 	resp := &cns.Response{ReturnCode: 0}
 	err = service.Listener.Encode(w, &resp)
 
-	logger.Response(service.Name, resp, resp.ReturnCode, err)
+	restLog.Response(service.Name, resp, resp.ReturnCode, err)
 }
 
 // Handles CreateNetwork requests.
 func (service *HTTPRestService) createNetwork(w http.ResponseWriter, r *http.Request) {
-	logger.Printf("[Azure CNS] createNetwork")
+	restLog.Printf("[Azure CNS] createNetwork")
 
 	var err error
 	var returnCode types.ResponseCode
@@ -80,7 +80,7 @@ func (service *HTTPRestService) createNetwork(w http.ResponseWriter, r *http.Req
 	if service.state.Initialized {
 		var req cns.CreateNetworkRequest
 		err = service.Listener.Decode(w, r, &req)
-		logger.Request(service.Name, &req, err)
+		restLog.Request(service.Name, &req, err)
 
 		if err != nil {
 			//nolint:goconst // ignore const string
@@ -99,7 +99,7 @@ func (service *HTTPRestService) createNetwork(w http.ResponseWriter, r *http.Req
 					case "Underlay":
 						switch service.state.Location {
 						case "Azure":
-							logger.Printf("[Azure CNS] Creating network with name %v.", req.NetworkName)
+							restLog.Printf("[Azure CNS] Creating network with name %v.", req.NetworkName)
 
 							err = rt.GetRoutingTable()
 							if err != nil {
@@ -107,7 +107,7 @@ func (service *HTTPRestService) createNetwork(w http.ResponseWriter, r *http.Req
 								// This is because restoring routes is a fallback mechanism in case
 								// network driver is not behaving as expected.
 								// The responsibility to restore routes is with network driver.
-								logger.Printf("[Azure CNS] Unable to get routing table from node, %+v.", err.Error())
+								restLog.Printf("[Azure CNS] Unable to get routing table from node, %+v.", err.Error())
 							}
 
 							var nicInfo *wireserver.InterfaceInfo
@@ -126,7 +126,7 @@ func (service *HTTPRestService) createNetwork(w http.ResponseWriter, r *http.Req
 
 							err = rt.RestoreRoutingTable()
 							if err != nil {
-								logger.Printf("[Azure CNS] Unable to restore routing table on node, %+v.", err.Error())
+								restLog.Printf("[Azure CNS] Unable to restore routing table on node, %+v.", err.Error())
 							}
 
 							networkInfo := &networkInfo{
@@ -147,7 +147,7 @@ func (service *HTTPRestService) createNetwork(w http.ResponseWriter, r *http.Req
 					}
 				} else {
 					returnMessage = fmt.Sprintf("[Azure CNS] Received a request to create an already existing network %v", req.NetworkName)
-					logger.Printf(returnMessage)
+					restLog.Printf(returnMessage)
 				}
 
 			default:
@@ -172,18 +172,18 @@ func (service *HTTPRestService) createNetwork(w http.ResponseWriter, r *http.Req
 		service.saveState()
 	}
 
-	logger.Response(service.Name, resp, resp.ReturnCode, err)
+	restLog.Response(service.Name, resp, resp.ReturnCode, err)
 }
 
 // Handles DeleteNetwork requests.
 func (service *HTTPRestService) deleteNetwork(w http.ResponseWriter, r *http.Request) {
-	logger.Printf("[Azure CNS] deleteNetwork")
+	restLog.Printf("[Azure CNS] deleteNetwork")
 
 	var req cns.DeleteNetworkRequest
 	var returnCode types.ResponseCode
 	returnMessage := ""
 	err := service.Listener.Decode(w, r, &req)
-	logger.Request(service.Name, &req, err)
+	restLog.Request(service.Name, &req, err)
 
 	if err != nil {
 		return
@@ -196,7 +196,7 @@ func (service *HTTPRestService) deleteNetwork(w http.ResponseWriter, r *http.Req
 
 		// Network does exist
 		if err == nil {
-			logger.Printf("[Azure CNS] Deleting network with name %v.", req.NetworkName)
+			restLog.Printf("[Azure CNS] Deleting network with name %v.", req.NetworkName)
 			err := dc.DeleteNetwork(req.NetworkName)
 			if err != nil {
 				returnMessage = fmt.Sprintf("[Azure CNS] Error. DeleteNetwork failed %v.", err.Error())
@@ -204,7 +204,7 @@ func (service *HTTPRestService) deleteNetwork(w http.ResponseWriter, r *http.Req
 			}
 		} else {
 			if err == fmt.Errorf("Network not found") {
-				logger.Printf("[Azure CNS] Received a request to delete network that does not exist: %v.", req.NetworkName)
+				restLog.Printf("[Azure CNS] Received a request to delete network that does not exist: %v.", req.NetworkName)
 			} else {
 				returnCode = types.UnexpectedError
 				returnMessage = err.Error()
@@ -228,12 +228,12 @@ func (service *HTTPRestService) deleteNetwork(w http.ResponseWriter, r *http.Req
 		service.saveState()
 	}
 
-	logger.Response(service.Name, resp, resp.ReturnCode, err)
+	restLog.Response(service.Name, resp, resp.ReturnCode, err)
 }
 
 // Handles CreateHnsNetwork requests.
 func (service *HTTPRestService) createHnsNetwork(w http.ResponseWriter, r *http.Request) {
-	logger.Printf("[Azure CNS] createHnsNetwork")
+	restLog.Printf("[Azure CNS] createHnsNetwork")
 
 	var err error
 	var returnCode types.ResponseCode
@@ -241,7 +241,7 @@ func (service *HTTPRestService) createHnsNetwork(w http.ResponseWriter, r *http.
 
 	var req cns.CreateHnsNetworkRequest
 	err = service.Listener.Decode(w, r, &req)
-	logger.Request(service.Name, &req, err)
+	restLog.Request(service.Name, &req, err)
 
 	if err != nil {
 		//nolint:goconst
@@ -279,12 +279,12 @@ func (service *HTTPRestService) createHnsNetwork(w http.ResponseWriter, r *http.
 		service.saveState()
 	}
 
-	logger.Response(service.Name, resp, resp.ReturnCode, err)
+	restLog.Response(service.Name, resp, resp.ReturnCode, err)
 }
 
 // Handles deleteHnsNetwork requests.
 func (service *HTTPRestService) deleteHnsNetwork(w http.ResponseWriter, r *http.Request) {
-	logger.Printf("[Azure CNS] deleteHnsNetwork")
+	restLog.Printf("[Azure CNS] deleteHnsNetwork")
 
 	var err error
 	var req cns.DeleteHnsNetworkRequest
@@ -292,7 +292,7 @@ func (service *HTTPRestService) deleteHnsNetwork(w http.ResponseWriter, r *http.
 	returnMessage := ""
 
 	err = service.Listener.Decode(w, r, &req)
-	logger.Request(service.Name, &req, err)
+	restLog.Request(service.Name, &req, err)
 
 	if err != nil {
 		//nolint:goconst
@@ -331,12 +331,12 @@ func (service *HTTPRestService) deleteHnsNetwork(w http.ResponseWriter, r *http.
 		service.saveState()
 	}
 
-	logger.Response(service.Name, resp, resp.ReturnCode, err)
+	restLog.Response(service.Name, resp, resp.ReturnCode, err)
 }
 
 // Handles ip reservation requests.
 func (service *HTTPRestService) reserveIPAddress(w http.ResponseWriter, r *http.Request) {
-	logger.Printf("[Azure CNS] reserveIPAddress")
+	restLog.Printf("[Azure CNS] reserveIPAddress")
 
 	var req cns.ReserveIPAddressRequest
 	var returnCode types.ResponseCode
@@ -345,7 +345,7 @@ func (service *HTTPRestService) reserveIPAddress(w http.ResponseWriter, r *http.
 	address := ""
 	err := service.Listener.Decode(w, r, &req)
 
-	logger.Request(service.Name, &req, err)
+	restLog.Request(service.Name, &req, err)
 
 	if err != nil {
 		return
@@ -410,19 +410,19 @@ func (service *HTTPRestService) reserveIPAddress(w http.ResponseWriter, r *http.
 
 	reserveResp := &cns.ReserveIPAddressResponse{Response: resp, IPAddress: address}
 	err = service.Listener.Encode(w, &reserveResp)
-	logger.Response(service.Name, reserveResp, resp.ReturnCode, err)
+	restLog.Response(service.Name, reserveResp, resp.ReturnCode, err)
 }
 
 // Handles release ip reservation requests.
 func (service *HTTPRestService) releaseIPAddress(w http.ResponseWriter, r *http.Request) {
-	logger.Printf("[Azure CNS] releaseIPAddress")
+	restLog.Printf("[Azure CNS] releaseIPAddress")
 
 	var req cns.ReleaseIPAddressRequest
 	var returnCode types.ResponseCode
 	returnMessage := ""
 
 	err := service.Listener.Decode(w, r, &req)
-	logger.Request(service.Name, &req, err)
+	restLog.Request(service.Name, &req, err)
 
 	if err != nil {
 		return
@@ -476,13 +476,13 @@ func (service *HTTPRestService) releaseIPAddress(w http.ResponseWriter, r *http.
 	}
 
 	err = service.Listener.Encode(w, &resp)
-	logger.Response(service.Name, resp, resp.ReturnCode, err)
+	restLog.Response(service.Name, resp, resp.ReturnCode, err)
 }
 
 // Retrieves the host local ip address. Containers can talk to host using this IP address.
 func (service *HTTPRestService) getHostLocalIP(w http.ResponseWriter, r *http.Request) {
-	logger.Printf("[Azure CNS] getHostLocalIP")
-	logger.Request(service.Name, "getHostLocalIP", nil)
+	restLog.Printf("[Azure CNS] getHostLocalIP")
+	restLog.Request(service.Name, "getHostLocalIP", nil)
 
 	var found bool
 	var errmsg string
@@ -493,13 +493,13 @@ func (service *HTTPRestService) getHostLocalIP(w http.ResponseWriter, r *http.Re
 		case http.MethodGet:
 			switch service.state.NetworkType {
 			case "Underlay":
-				if service.wscli != nil {
+				if service.hostMetadata != nil {
 					piface, err := service.getPrimaryHostInterface(context.TODO())
 					if err == nil {
 						hostLocalIP = piface.PrimaryIP
 						found = true
 					} else {
-						logger.Printf("[Azure-CNS] Received error from GetPrimaryInterfaceInfoFromMemory. err: %v", err.Error())
+						restLog.Printf("[Azure-CNS] Received error from GetPrimaryInterfaceInfoFromMemory. err: %v", err.Error())
 					}
 				}
 
@@ -528,13 +528,13 @@ func (service *HTTPRestService) getHostLocalIP(w http.ResponseWriter, r *http.Re
 
 	err := service.Listener.Encode(w, &hostLocalIPResponse)
 
-	logger.Response(service.Name, hostLocalIPResponse, resp.ReturnCode, err)
+	restLog.Response(service.Name, hostLocalIPResponse, resp.ReturnCode, err)
 }
 
 // Handles ip address utilization requests.
 func (service *HTTPRestService) getIPAddressUtilization(w http.ResponseWriter, r *http.Request) {
-	logger.Printf("[Azure CNS] getIPAddressUtilization")
-	logger.Request(service.Name, "getIPAddressUtilization", nil)
+	restLog.Printf("[Azure CNS] getIPAddressUtilization")
+	restLog.Request(service.Name, "getIPAddressUtilization", nil)
 
 	var returnCode types.ResponseCode
 	returnMessage := ""
@@ -573,7 +573,7 @@ func (service *HTTPRestService) getIPAddressUtilization(w http.ResponseWriter, r
 			returnCode = types.UnexpectedError
 			break
 		}
-		logger.Printf("[Azure CNS] Capacity %v Available %v UnhealthyAddrs %v", capacity, available, unhealthyAddrs)
+		restLog.Printf("[Azure CNS] Capacity %v Available %v UnhealthyAddrs %v", capacity, available, unhealthyAddrs)
 
 	default:
 		returnMessage = "[Azure CNS] Error. GetIPUtilization did not receive a GET."
@@ -593,37 +593,37 @@ func (service *HTTPRestService) getIPAddressUtilization(w http.ResponseWriter, r
 	}
 
 	err := service.Listener.Encode(w, &utilResponse)
-	logger.Response(service.Name, utilResponse, resp.ReturnCode, err)
+	restLog.Response(service.Name, utilResponse, resp.ReturnCode, err)
 }
 
 // Handles retrieval of ip addresses that are available to be reserved from ipam driver.
 func (service *HTTPRestService) getAvailableIPAddresses(w http.ResponseWriter, r *http.Request) {
-	logger.Printf("[Azure CNS] getAvailableIPAddresses")
-	logger.Request(service.Name, "getAvailableIPAddresses", nil)
+	restLog.Printf("[Azure CNS] getAvailableIPAddresses")
+	restLog.Request(service.Name, "getAvailableIPAddresses", nil)
 
 	resp := cns.Response{ReturnCode: 0}
 	ipResp := &cns.GetIPAddressesResponse{Response: resp}
 	err := service.Listener.Encode(w, &ipResp)
 
-	logger.Response(service.Name, ipResp, resp.ReturnCode, err)
+	restLog.Response(service.Name, ipResp, resp.ReturnCode, err)
 }
 
 // Handles retrieval of reserved ip addresses from ipam driver.
 func (service *HTTPRestService) getReservedIPAddresses(w http.ResponseWriter, r *http.Request) {
-	logger.Printf("[Azure CNS] getReservedIPAddresses")
-	logger.Request(service.Name, "getReservedIPAddresses", nil)
+	restLog.Printf("[Azure CNS] getReservedIPAddresses")
+	restLog.Request(service.Name, "getReservedIPAddresses", nil)
 
 	resp := cns.Response{ReturnCode: 0}
 	ipResp := &cns.GetIPAddressesResponse{Response: resp}
 	err := service.Listener.Encode(w, &ipResp)
 
-	logger.Response(service.Name, ipResp, resp.ReturnCode, err)
+	restLog.Response(service.Name, ipResp, resp.ReturnCode, err)
 }
 
 // Handles retrieval of ghost ip addresses from ipam driver.
 func (service *HTTPRestService) getUnhealthyIPAddresses(w http.ResponseWriter, r *http.Request) {
-	logger.Printf("[Azure CNS] getUnhealthyIPAddresses")
-	logger.Request(service.Name, "getUnhealthyIPAddresses", nil)
+	restLog.Printf("[Azure CNS] getUnhealthyIPAddresses")
+	restLog.Request(service.Name, "getUnhealthyIPAddresses", nil)
 
 	var returnCode types.ResponseCode
 	returnMessage := ""
@@ -662,7 +662,7 @@ func (service *HTTPRestService) getUnhealthyIPAddresses(w http.ResponseWriter, r
 			returnCode = types.UnexpectedError
 			break
 		}
-		logger.Printf("[Azure CNS] Capacity %v Available %v UnhealthyAddrs %v", capacity, available, unhealthyAddrs)
+		restLog.Printf("[Azure CNS] Capacity %v Available %v UnhealthyAddrs %v", capacity, available, unhealthyAddrs)
 
 	default:
 		returnMessage = "[Azure CNS] Error. GetUnhealthyIP did not receive a POST."
@@ -680,34 +680,34 @@ func (service *HTTPRestService) getUnhealthyIPAddresses(w http.ResponseWriter, r
 	}
 
 	err := service.Listener.Encode(w, &ipResp)
-	logger.Response(service.Name, ipResp, resp.ReturnCode, err)
+	restLog.Response(service.Name, ipResp, resp.ReturnCode, err)
 }
 
 // getAllIPAddresses retrieves all ip addresses from ipam driver.
 func (service *HTTPRestService) getAllIPAddresses(w http.ResponseWriter, r *http.Request) {
-	logger.Printf("[Azure CNS] getAllIPAddresses")
-	logger.Request(service.Name, "getAllIPAddresses", nil)
+	restLog.Printf("[Azure CNS] getAllIPAddresses")
+	restLog.Request(service.Name, "getAllIPAddresses", nil)
 
 	resp := cns.Response{ReturnCode: 0}
 	ipResp := &cns.GetIPAddressesResponse{Response: resp}
 	err := service.Listener.Encode(w, &ipResp)
 
-	logger.Response(service.Name, ipResp, resp.ReturnCode, err)
+	restLog.Response(service.Name, ipResp, resp.ReturnCode, err)
 }
 
 // Handles health report requests.
 func (service *HTTPRestService) getHealthReport(w http.ResponseWriter, r *http.Request) {
-	logger.Printf("[Azure CNS] getHealthReport")
-	logger.Request(service.Name, "getHealthReport", nil)
+	restLog.Printf("[Azure CNS] getHealthReport")
+	restLog.Request(service.Name, "getHealthReport", nil)
 
 	resp := &cns.Response{ReturnCode: 0}
 	err := service.Listener.Encode(w, &resp)
 
-	logger.Response(service.Name, resp, resp.ReturnCode, err)
+	restLog.Response(service.Name, resp, resp.ReturnCode, err)
 }
 
 func (service *HTTPRestService) setOrchestratorType(w http.ResponseWriter, r *http.Request) {
-	logger.Printf("[Azure CNS] setOrchestratorType")
+	restLog.Printf("[Azure CNS] setOrchestratorType")
 
 	var (
 		req           cns.SetOrchestratorTypeRequest
@@ -762,13 +762,13 @@ func (service *HTTPRestService) setOrchestratorType(w http.ResponseWriter, r *ht
 	}
 
 	err = service.Listener.Encode(w, &resp)
-	logger.Response(service.Name, resp, resp.ReturnCode, err)
+	restLog.Response(service.Name, resp, resp.ReturnCode, err)
 }
 
 // getHomeAz retrieves home AZ of host
 func (service *HTTPRestService) getHomeAz(w http.ResponseWriter, r *http.Request) {
-	logger.Printf("[Azure CNS] getHomeAz")
-	logger.Request(service.Name, "getHomeAz", nil)
+	nmaLog.Printf("[Azure CNS] getHomeAz")
+	nmaLog.Request(service.Name, "getHomeAz", nil)
 	ctx := r.Context()
 
 	switch r.Method {
@@ -785,11 +785,11 @@ func (service *HTTPRestService) getHomeAz(w http.ResponseWriter, r *http.Request
 }
 
 func (service *HTTPRestService) createOrUpdateNetworkContainer(w http.ResponseWriter, r *http.Request) {
-	logger.Printf("[Azure CNS] createOrUpdateNetworkContainer")
+	restLog.Printf("[Azure CNS] createOrUpdateNetworkContainer")
 
 	var req cns.CreateNetworkContainerRequest
 	err := service.Listener.Decode(w, r, &req)
-	logger.Request(service.Name, req.String(), err)
+	restLog.Request(service.Name, req.String(), err)
 	if err != nil {
 		return
 	}
@@ -847,18 +847,18 @@ func (service *HTTPRestService) createOrUpdateNetworkContainer(w http.ResponseWr
 		logNCSnapshot(req)
 	}
 
-	logger.Response(service.Name, reserveResp, resp.ReturnCode, err)
+	restLog.Response(service.Name, reserveResp, resp.ReturnCode, err)
 }
 
 func (service *HTTPRestService) getNetworkContainerByID(w http.ResponseWriter, r *http.Request) {
-	logger.Printf("[Azure CNS] getNetworkContainerByID")
+	restLog.Printf("[Azure CNS] getNetworkContainerByID")
 
 	var req cns.GetNetworkContainerRequest
 	var returnCode types.ResponseCode
 	returnMessage := ""
 
 	err := service.Listener.Decode(w, r, &req)
-	logger.Request(service.Name, &req, err)
+	restLog.Request(service.Name, &req, err)
 	if err != nil {
 		return
 	}
@@ -870,16 +870,16 @@ func (service *HTTPRestService) getNetworkContainerByID(w http.ResponseWriter, r
 
 	reserveResp := &cns.GetNetworkContainerResponse{Response: resp}
 	err = service.Listener.Encode(w, &reserveResp)
-	logger.Response(service.Name, reserveResp, resp.ReturnCode, err)
+	restLog.Response(service.Name, reserveResp, resp.ReturnCode, err)
 }
 
 func (service *HTTPRestService) getNetworkContainerByOrchestratorContext(w http.ResponseWriter, r *http.Request) {
-	logger.Printf("[Azure CNS] getNetworkContainerByOrchestratorContext")
+	restLog.Printf("[Azure CNS] getNetworkContainerByOrchestratorContext")
 
 	var req cns.GetNetworkContainerRequest
 
 	err := service.Listener.Decode(w, r, &req)
-	logger.Request(service.Name, &req, err)
+	restLog.Request(service.Name, &req, err)
 	if err != nil {
 		return
 	}
@@ -887,7 +887,7 @@ func (service *HTTPRestService) getNetworkContainerByOrchestratorContext(w http.
 	getNetworkContainerResponse := service.getNetworkContainerResponse(req)
 	returnCode := getNetworkContainerResponse.Response.ReturnCode
 	err = service.Listener.Encode(w, &getNetworkContainerResponse)
-	logger.Response(service.Name, getNetworkContainerResponse, returnCode, err)
+	restLog.Response(service.Name, getNetworkContainerResponse, returnCode, err)
 }
 
 // getOrRefreshNetworkContainers is to check whether refresh association is needed.
@@ -904,20 +904,20 @@ func (service *HTTPRestService) getOrRefreshNetworkContainers(w http.ResponseWri
 	default:
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		err := errors.New("[Azure CNS] getOrRefreshNetworkContainers did not receive a GET or POST")
-		logger.Response(service.Name, nil, types.InvalidParameter, err)
+		restLog.Response(service.Name, nil, types.InvalidParameter, err)
 		return
 	}
 }
 
 func (service *HTTPRestService) deleteNetworkContainer(w http.ResponseWriter, r *http.Request) {
-	logger.Printf("[Azure CNS] deleteNetworkContainer")
+	restLog.Printf("[Azure CNS] deleteNetworkContainer")
 
 	var req cns.DeleteNetworkContainerRequest
 	var returnCode types.ResponseCode
 	returnMessage := ""
 
 	err := service.Listener.Decode(w, r, &req)
-	logger.Request(service.Name, &req, err)
+	restLog.Request(service.Name, &req, err)
 	if err != nil {
 		return
 	}
@@ -935,7 +935,7 @@ func (service *HTTPRestService) deleteNetworkContainer(w http.ResponseWriter, r
 		containerStatus, ok = service.getNetworkContainerDetails(req.NetworkContainerid)
 
 		if !ok {
-			logger.Printf("Not able to retrieve network container details for this container id %v", req.NetworkContainerid)
+			restLog.Printf("Not able to retrieve network container details for this container id %v", req.NetworkContainerid)
 			break
 		}
 
@@ -977,18 +977,18 @@ func (service *HTTPRestService) deleteNetworkContainer(w http.ResponseWriter, r
 
 	reserveResp := &cns.DeleteNetworkContainerResponse{Response: resp}
 	err = service.Listener.Encode(w, &reserveResp)
-	logger.Response(service.Name, reserveResp, resp.ReturnCode, err)
+	restLog.Response(service.Name, reserveResp, resp.ReturnCode, err)
 }
 
 func (service *HTTPRestService) getInterfaceForContainer(w http.ResponseWriter, r *http.Request) {
-	logger.Printf("[Azure CNS] getInterfaceForContainer")
+	restLog.Printf("[Azure CNS] getInterfaceForContainer")
 
 	var req cns.GetInterfaceForContainerRequest
 	var returnCode types.ResponseCode
 	returnMessage := ""
 
 	err := service.Listener.Decode(w, r, &req)
-	logger.Request(service.Name, &req, err)
+	restLog.Request(service.Name, &req, err)
 	if err != nil {
 		return
 	}
@@ -1031,15 +1031,15 @@ func (service *HTTPRestService) getInterfaceForContainer(w http.ResponseWriter,
 
 	err = service.Listener.Encode(w, &getInterfaceForContainerResponse)
 
-	logger.Response(service.Name, getInterfaceForContainerResponse, resp.ReturnCode, err)
+	restLog.Response(service.Name, getInterfaceForContainerResponse, resp.ReturnCode, err)
 }
 
 func (service *HTTPRestService) attachNetworkContainerToNetwork(w http.ResponseWriter, r *http.Request) {
-	logger.Printf("[Azure CNS] attachNetworkContainerToNetwork")
+	restLog.Printf("[Azure CNS] attachNetworkContainerToNetwork")
 
 	var req cns.ConfigureContainerNetworkingRequest
 	err := service.Listener.Decode(w, r, &req)
-	logger.Request(service.Name, &req, err)
+	restLog.Request(service.Name, &req, err)
 	if err != nil {
 		return
 	}
@@ -1047,15 +1047,15 @@ func (service *HTTPRestService) attachNetworkContainerToNetwork(w http.ResponseW
 	resp := service.attachOrDetachHelper(req, attach, r.Method)
 	attachResp := &cns.AttachContainerToNetworkResponse{Response: resp}
 	err = service.Listener.Encode(w, &attachResp)
-	logger.Response(service.Name, attachResp, resp.ReturnCode, err)
+	restLog.Response(service.Name, attachResp, resp.ReturnCode, err)
 }
 
 func (service *HTTPRestService) detachNetworkContainerFromNetwork(w http.ResponseWriter, r *http.Request) {
-	logger.Printf("[Azure CNS] detachNetworkContainerFromNetwork")
+	restLog.Printf("[Azure CNS] detachNetworkContainerFromNetwork")
 
 	var req cns.ConfigureContainerNetworkingRequest
 	err := service.Listener.Decode(w, r, &req)
-	logger.Request(service.Name, &req, err)
+	restLog.Request(service.Name, &req, err)
 	if err != nil {
 		return
 	}
@@ -1063,14 +1063,14 @@ func (service *HTTPRestService) detachNetworkContainerFromNetwork(w http.Respons
 	resp := service.attachOrDetachHelper(req, detach, r.Method)
 	detachResp := &cns.DetachContainerFromNetworkResponse{Response: resp}
 	err = service.Listener.Encode(w, &detachResp)
-	logger.Response(service.Name, detachResp, resp.ReturnCode, err)
+	restLog.Response(service.Name, detachResp, resp.ReturnCode, err)
 }
 
 // Retrieves the number of logic processors on a node. It will be primarily
 // used to enforce per VM delegated NIC limit by DNC.
 func (service *HTTPRestService) getNumberOfCPUCores(w http.ResponseWriter, r *http.Request) {
-	logger.Printf("[Azure-CNS] getNumberOfCPUCores")
-	logger.Request(service.Name, "getNumberOfCPUCores", nil)
+	restLog.Printf("[Azure-CNS] getNumberOfCPUCores")
+	restLog.Request(service.Name, "getNumberOfCPUCores", nil)
 
 	var (
 		num        int
@@ -1094,7 +1094,7 @@ func (service *HTTPRestService) getNumberOfCPUCores(w http.ResponseWriter, r *ht
 
 	err := service.Listener.Encode(w, &numOfCPUCoresResp)
 
-	logger.Response(service.Name, numOfCPUCoresResp, resp.ReturnCode, err)
+	restLog.Response(service.Name, numOfCPUCoresResp, resp.ReturnCode, err)
 }
 
 func getAuthTokenAndInterfaceIDFromNcURL(networkContainerURL string) (*cns.NetworkContainerParameters, error) {
@@ -1132,7 +1132,7 @@ func (h *HTTPRestService) doPublish(ctx context.Context, req cns.PublishNetworkC
 	if err != nil {
 		returnMessage := fmt.Sprintf("Failed to unmarshal embedded NC publish request for NC %s, with err: %v", req.NetworkContainerID, err)
 		returnCode := types.NetworkContainerPublishFailed
-		logger.Errorf("[Azure-CNS] %s", returnMessage)
+		nmaLog.Errorf("[Azure-CNS] %s", returnMessage)
 		return returnMessage, returnCode
 	}
 
@@ -1145,7 +1145,7 @@ func (h *HTTPRestService) doPublish(ctx context.Context, req cns.PublishNetworkC
 	if err != nil {
 		returnMessage := fmt.Sprintf("Failed to publish Network Container %s in put Network Container call, with err: %v", req.NetworkContainerID, err)
 		returnCode := types.NetworkContainerPublishFailed
-		logger.Errorf("[Azure-CNS] %s", returnMessage)
+		nmaLog.Errorf("[Azure-CNS] %s", returnMessage)
 		return returnMessage, returnCode
 	}
 
@@ -1154,7 +1154,7 @@ func (h *HTTPRestService) doPublish(ctx context.Context, req cns.PublishNetworkC
 
 // Publish Network Container by calling nmagent
 func (service *HTTPRestService) publishNetworkContainer(w http.ResponseWriter, r *http.Request) {
-	logger.Printf("[Azure-CNS] PublishNetworkContainer")
+	nmaLog.Printf("[Azure-CNS] PublishNetworkContainer")
 
 	ctx := r.Context()
 
@@ -1183,7 +1183,7 @@ func (service *HTTPRestService) publishNetworkContainer(w http.ResponseWriter, r
 		CreateNetworkContainerURL: strings.Split(req.CreateNetworkContainerURL, "authenticationToken")[0],
 	}
 
-	logger.Request(service.Name, &reqCopy, err)
+	nmaLog.Request(service.Name, &reqCopy, err)
 
 	// TODO - refactor this method for better error handling
 	if err != nil {
@@ -1193,7 +1193,7 @@ func (service *HTTPRestService) publishNetworkContainer(w http.ResponseWriter, r
 	var ncParameters *cns.NetworkContainerParameters
 	ncParameters, err = getAuthTokenAndInterfaceIDFromNcURL(creteNcURLCopy)
 	if err != nil {
-		logger.Errorf("[Azure-CNS] nc parameters validation failed with %+v", err)
+		nmaLog.Errorf("[Azure-CNS] nc parameters validation failed with %+v", err)
 		w.WriteHeader(http.StatusBadRequest)
 
 		badRequestResponse := &cns.PublishNetworkContainerResponse{
@@ -1205,7 +1205,7 @@ func (service *HTTPRestService) publishNetworkContainer(w http.ResponseWriter, r
 			PublishStatusCode: http.StatusBadRequest,
 		}
 		err = service.Listener.Encode(w, &badRequestResponse)
-		logger.Response(service.Name, badRequestResponse, badRequestResponse.Response.ReturnCode, err)
+		nmaLog.Response(service.Name, badRequestResponse, badRequestResponse.Response.ReturnCode, err)
 		return
 	}
 
@@ -1254,12 +1254,12 @@ func (service *HTTPRestService) publishNetworkContainer(w http.ResponseWriter, r
 	}
 
 	err = service.Listener.Encode(w, &response)
-	logger.Response(service.Name, response, response.Response.ReturnCode, err)
+	nmaLog.Response(service.Name, response, response.Response.ReturnCode, err)
 }
 
 // Unpublish Network Container by calling nmagent
 func (service *HTTPRestService) unpublishNetworkContainer(w http.ResponseWriter, r *http.Request) {
-	logger.Printf("[Azure-CNS] UnpublishNetworkContainer")
+	nmaLog.Printf("[Azure-CNS] UnpublishNetworkContainer")
 	ctx := r.Context()
 
 	var (
@@ -1285,7 +1285,7 @@ func (service *HTTPRestService) unpublishNetworkContainer(w http.ResponseWriter,
 		DeleteNetworkContainerURL: strings.Split(req.DeleteNetworkContainerURL, "authenticationToken")[0],
 	}
 
-	logger.Request(service.Name, &reqCopy, err)
+	nmaLog.Request(service.Name, &reqCopy, err)
 	if err != nil {
 		return
 	}
@@ -1293,7 +1293,7 @@ func (service *HTTPRestService) unpublishNetworkContainer(w http.ResponseWriter,
 	var ncParameters *cns.NetworkContainerParameters
 	ncParameters, err = getAuthTokenAndInterfaceIDFromNcURL(deleteNcURLCopy)
 	if err != nil {
-		logger.Errorf("[Azure-CNS] nc parameters validation failed with %+v", err)
+		nmaLog.Errorf("[Azure-CNS] nc parameters validation failed with %+v", err)
 		w.WriteHeader(http.StatusBadRequest)
 
 		badRequestResponse := &cns.UnpublishNetworkContainerResponse{
@@ -1305,7 +1305,7 @@ func (service *HTTPRestService) unpublishNetworkContainer(w http.ResponseWriter,
 			UnpublishStatusCode: http.StatusBadRequest,
 		}
 		err = service.Listener.Encode(w, &badRequestResponse)
-		logger.Response(service.Name, badRequestResponse, badRequestResponse.Response.ReturnCode, err)
+		nmaLog.Response(service.Name, badRequestResponse, badRequestResponse.Response.ReturnCode, err)
 		return
 	}
 
@@ -1342,7 +1342,7 @@ func (service *HTTPRestService) unpublishNetworkContainer(w http.ResponseWriter,
 			if err != nil {
 				returnMessage = fmt.Sprintf("Failed to unpublish Network Container: %s", req.NetworkContainerID)
 				returnCode = types.NetworkContainerUnpublishFailed
-				logger.Errorf("[Azure-CNS] %s", returnMessage)
+				nmaLog.Errorf("[Azure-CNS] %s", returnMessage)
 			}
 		}
 	default:
@@ -1365,11 +1365,11 @@ func (service *HTTPRestService) unpublishNetworkContainer(w http.ResponseWriter,
 	}
 
 	err = service.Listener.Encode(w, &response)
-	logger.Response(service.Name, response, response.Response.ReturnCode, err)
+	nmaLog.Response(service.Name, response, response.Response.ReturnCode, err)
 }
 
 func (service *HTTPRestService) createHostNCApipaEndpoint(w http.ResponseWriter, r *http.Request) {
-	logger.Printf("[Azure-CNS] createHostNCApipaEndpoint")
+	restLog.Printf("[Azure-CNS] createHostNCApipaEndpoint")
 
 	var (
 		err           error
@@ -1380,7 +1380,7 @@ func (service *HTTPRestService) createHostNCApipaEndpoint(w http.ResponseWriter,
 	)
 
 	err = service.Listener.Decode(w, r, &req)
-	logger.Request(service.Name, &req, err)
+	restLog.Request(service.Name, &req, err)
 	if err != nil {
 		return
 	}
@@ -1424,11 +1424,11 @@ func (service *HTTPRestService) createHostNCApipaEndpoint(w http.ResponseWriter,
 	}
 
 	err = service.Listener.Encode(w, &response)
-	logger.Response(service.Name, response, response.Response.ReturnCode, err)
+	restLog.Response(service.Name, response, response.Response.ReturnCode, err)
 }
 
 func (service *HTTPRestService) deleteHostNCApipaEndpoint(w http.ResponseWriter, r *http.Request) {
-	logger.Printf("[Azure-CNS] deleteHostNCApipaEndpoint")
+	restLog.Printf("[Azure-CNS] deleteHostNCApipaEndpoint")
 
 	var (
 		err           error
@@ -1438,7 +1438,7 @@ func (service *HTTPRestService) deleteHostNCApipaEndpoint(w http.ResponseWriter,
 	)
 
 	err = service.Listener.Decode(w, r, &req)
-	logger.Request(service.Name, &req, err)
+	restLog.Request(service.Name, &req, err)
 	if err != nil {
 		return
 	}
@@ -1463,14 +1463,14 @@ func (service *HTTPRestService) deleteHostNCApipaEndpoint(w http.ResponseWriter,
 	}
 
 	err = service.Listener.Encode(w, &response)
-	logger.Response(service.Name, response, response.Response.ReturnCode, err)
+	restLog.Response(service.Name, response, response.Response.ReturnCode, err)
 }
 
 // This function is used to query NMagents's supported APIs list
 func (service *HTTPRestService) nmAgentSupportedApisHandler(w http.ResponseWriter, r *http.Request) {
-	logger.Request(service.Name, "nmAgentSupportedApisHandler", nil)
+	nmaLog.Request(service.Name, "nmAgentSupportedApisHandler", nil)
 	var (
-		err, retErr   error
+		err           error
 		req           cns.NmAgentSupportedApisRequest
 		returnCode    types.ResponseCode
 		returnMessage string
@@ -1480,17 +1480,17 @@ func (service *HTTPRestService) nmAgentSupportedApisHandler(w http.ResponseWrite
 	ctx := r.Context()
 
 	err = service.Listener.Decode(w, r, &req)
-	logger.Request(service.Name, &req, err)
+	nmaLog.Request(service.Name, &req, err)
 	if err != nil {
 		return
 	}
 
 	switch r.Method {
 	case http.MethodPost:
-		apis, err := service.nma.SupportedAPIs(ctx)
+		apis, err := service.hostMetadata.SupportedAPIs(ctx)
 		if err != nil {
 			returnCode = types.NmAgentSupportedApisError
-			returnMessage = fmt.Sprintf("[Azure-CNS] %s", retErr.Error())
+			returnMessage = fmt.Sprintf("[Azure-CNS] %s", err.Error())
 		}
 		supportedApis = apis
 
@@ -1506,5 +1506,5 @@ func (service *HTTPRestService) nmAgentSupportedApisHandler(w http.ResponseWrite
 
 	serviceErr := service.Listener.Encode(w, &nmAgentSupportedApisResponse)
 
-	logger.Response(service.Name, nmAgentSupportedApisResponse, resp.ReturnCode, serviceErr)
+	nmaLog.Response(service.Name, nmAgentSupportedApisResponse, resp.ReturnCode, serviceErr)
 }
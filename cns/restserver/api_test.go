@@ -21,6 +21,7 @@ import (
 	"github.com/Azure/azure-container-networking/cns"
 	"github.com/Azure/azure-container-networking/cns/common"
 	"github.com/Azure/azure-container-networking/cns/fakes"
+	"github.com/Azure/azure-container-networking/cns/hostmetadata"
 	"github.com/Azure/azure-container-networking/cns/logger"
 	"github.com/Azure/azure-container-networking/cns/types"
 	acncommon "github.com/Azure/azure-container-networking/common"
@@ -28,6 +29,7 @@ import (
 	"github.com/Azure/azure-container-networking/processlock"
 	"github.com/Azure/azure-container-networking/store"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -1477,7 +1479,7 @@ func startService() error {
 	config.Store = fileStore
 
 	nmagentClient := &fakes.NMAgentClientFake{}
-	service, err = NewHTTPRestService(&config, &fakes.WireserverClientFake{}, nmagentClient, nil, nil, nil)
+	service, err = NewHTTPRestService(&config, hostmetadata.NewWireserver(&fakes.WireserverClientFake{}, nmagentClient), nmagentClient, nil, nil, nil)
 	if err != nil {
 		return err
 	}
@@ -1755,3 +1757,29 @@ func TestGetHostLocalIP(t *testing.T) {
 	}
 }
 */
+
+func TestNmAgentSupportedApisFromHostMetadata(t *testing.T) {
+	provider, err := hostmetadata.NewStatic(hostmetadata.StaticMetadata{
+		PrimaryInterface: hostmetadata.StaticInterface{Subnet: "10.0.0.0/24", PrimaryIP: "10.0.0.4"},
+		SupportedAPIs:    []string{"NetworkManagement"},
+	})
+	require.NoError(t, err)
+	nma := &fakes.NMAgentClientFake{
+		SupportedAPIsF: func(context.Context) ([]string, error) {
+			return nil, errors.New("nmagent is not on this host")
+		},
+	}
+	httpsvc, err := NewHTTPRestService(&common.ServiceConfig{}, provider, nma, nil, nil, nil)
+	require.NoError(t, err)
+	service := httpsvc.(*HTTPRestService)
+
+	var body bytes.Buffer
+	require.NoError(t, json.NewEncoder(&body).Encode(cns.NmAgentSupportedApisRequest{}))
+	w := httptest.NewRecorder()
+	service.nmAgentSupportedApisHandler(w, httptest.NewRequest(http.MethodPost, cns.NmAgentSupportedApisPath, &body))
+
+	var resp cns.NmAgentSupportedApisResponse
+	require.NoError(t, decodeResponse(w, &resp))
+	assert.Equal(t, types.Success, resp.Response.ReturnCode)
+	assert.Equal(t, []string{"NetworkManagement"}, resp.SupportedApis)
+}
@@ -0,0 +1,67 @@
+package restserver
+
+import (
+	"context"
+
+	v1 "github.com/Azure/azure-container-networking/cns/grpc/v1"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+)
+
+// grpcServer serves the CNS gRPC API from the same state and with the same logic as the HTTP API.
+type grpcServer struct {
+	v1.UnimplementedCNSServer
+	service *HTTPRestService
+}
+
+func (service *HTTPRestService) registerGRPCServer(s grpc.ServiceRegistrar) {
+	v1.RegisterCNSServer(s, &grpcServer{service: service})
+}
+
+func (g *grpcServer) RequestIPConfig(_ context.Context, in *v1.IPConfigRequest) (*v1.IPConfigResponse, error) {
+	req := in.ToCNS()
+	restLog.Request(g.service.Name+"grpcRequestIPConfig", req, nil)
+	resp := g.service.requestIPConfig(req)
+	restLog.ResponseEx(g.service.Name+"grpcRequestIPConfig", req, resp, resp.Response.ReturnCode, nil)
+	return v1.IPConfigResponseFromCNS(resp), nil
+}
+
+func (g *grpcServer) ReleaseIPConfig(_ context.Context, in *v1.IPConfigRequest) (*v1.Response, error) {
+	req := in.ToCNS()
+	restLog.Request(g.service.Name+"grpcReleaseIPConfig", req, nil)
+	resp := g.service.releaseIPConfigRequest(req)
+	restLog.ResponseEx(g.service.Name+"grpcReleaseIPConfig", req, resp, resp.ReturnCode, nil)
+	return v1.ResponseFromCNS(&resp), nil
+}
+
+func (g *grpcServer) GetNetworkContainer(_ context.Context, in *v1.GetNetworkContainerRequest) (*v1.GetNetworkContainerResponse, error) {
+	req := in.ToCNS()
+	restLog.Request(g.service.Name+"grpcGetNetworkContainer", req, nil)
+	resp := g.service.getNetworkContainerResponse(req)
+	restLog.Response(g.service.Name+"grpcGetNetworkContainer", resp, resp.Response.ReturnCode, nil)
+	return v1.GetNetworkContainerResponseFromCNS(&resp), nil
+}
+
+func (g *grpcServer) GetIPAddresses(_ context.Context, in *v1.GetIPAddressesRequest) (*v1.GetIPAddressStatusResponse, error) {
+	resp := g.service.getIPAddresses(in.ToCNS())
+	return v1.GetIPAddressStatusResponseFromCNS(&resp), nil
+}
+
+// WatchIPAddresses sends the IPs matching the request, and sends them again after IPs change state
+// until the client cancels the watch. Changes made while a send is in progress are coalesced into the next send.
+func (g *grpcServer) WatchIPAddresses(in *v1.GetIPAddressesRequest, stream v1.CNS_WatchIPAddressesServer) error {
+	req := in.ToCNS()
+	changes, unsubscribe := g.service.SubscribeIPStateChanges()
+	defer unsubscribe()
+	for {
+		resp := g.service.getIPAddresses(req)
+		if err := stream.Send(v1.GetIPAddressStatusResponseFromCNS(&resp)); err != nil {
+			return errors.Wrap(err, "failed to send ip addresses")
+		}
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-changes:
+		}
+	}
+}
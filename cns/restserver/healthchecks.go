@@ -0,0 +1,40 @@
+package restserver
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// nmaHealthCheckTimeout bounds the request to NMAgent of the NMAgentCheck.
+const nmaHealthCheckTimeout = 2 * time.Second
+
+var errCNIConflistNotGenerated = errors.New("the CNI conflist has not been generated yet")
+
+// StateStoreCheck is a healthz.Checker which fails if the last write of the CNS state to the store failed.
+// It reports the error of the last write rather than writing the store on each probe.
+func (service *HTTPRestService) StateStoreCheck(*http.Request) error {
+	if err := service.saveStateErr.Load(); err != nil {
+		return errors.Wrap(*err, "failed to write the state store")
+	}
+	return nil
+}
+
+// NMAgentCheck is a healthz.Checker which fails if NMAgent can't be reached.
+func (service *HTTPRestService) NMAgentCheck(req *http.Request) error {
+	ctx, cancel := context.WithTimeout(req.Context(), nmaHealthCheckTimeout)
+	defer cancel()
+	_, err := service.nma.SupportedAPIs(ctx)
+	return errors.Wrap(err, "failed to reach nmagent")
+}
+
+// CNIConflistCheck is a healthz.Checker which fails until the CNI conflist has been generated.
+// It should only be registered when CNS generates the conflist.
+func (service *HTTPRestService) CNIConflistCheck(*http.Request) error {
+	if !service.cniConflistGenerated.Load() {
+		return errCNIConflistNotGenerated
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package restserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/cns/common"
+	"github.com/Azure/azure-container-networking/cns/fakes"
+	"github.com/Azure/azure-container-networking/cns/hostmetadata"
+	"github.com/Azure/azure-container-networking/store"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingWriteStore struct {
+	store.KeyValueStore
+	err error
+}
+
+func (s *failingWriteStore) Write(key string, value interface{}) error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.KeyValueStore.Write(key, value) //nolint:wrapcheck // test store
+}
+
+func TestHealthChecks(t *testing.T) {
+	nmaErr := errors.New("unreachable")
+	nma := &fakes.NMAgentClientFake{
+		SupportedAPIsF: func(context.Context) ([]string, error) {
+			return nil, nmaErr
+		},
+	}
+	kvs := &failingWriteStore{KeyValueStore: store.NewMockStore("")}
+	config := common.ServiceConfig{Store: kvs}
+	httpsvc, err := NewHTTPRestService(&config, hostmetadata.NewWireserver(&fakes.WireserverClientFake{}, nma), nma, store.NewMockStore(""), &NoOpConflistGenerator{}, nil)
+	require.NoError(t, err)
+	service := httpsvc.(*HTTPRestService)
+	req := httptest.NewRequest(http.MethodGet, "/readyz", http.NoBody)
+
+	assert.NoError(t, service.StateStoreCheck(req))
+	kvs.err = errors.New("read-only file system")
+	require.Error(t, service.saveState())
+	assert.ErrorIs(t, service.StateStoreCheck(req), kvs.err)
+	kvs.err = nil
+	require.NoError(t, service.saveState())
+	assert.NoError(t, service.StateStoreCheck(req))
+
+	assert.ErrorIs(t, service.NMAgentCheck(req), nmaErr)
+	nmaErr = nil
+	assert.NoError(t, service.NMAgentCheck(req))
+
+	assert.ErrorIs(t, service.CNIConflistCheck(req), errCNIConflistNotGenerated)
+	service.MustGenerateCNIConflistOnce()
+	assert.NoError(t, service.CNIConflistCheck(req))
+}
@@ -7,11 +7,12 @@ import (
 	"time"
 
 	"github.com/Azure/azure-container-networking/cns"
+	"github.com/Azure/azure-container-networking/cns/logger"
 	"github.com/Azure/azure-container-networking/cns/types"
-	"github.com/Azure/azure-container-networking/log"
 	"github.com/Azure/azure-container-networking/nmagent"
 	"github.com/patrickmn/go-cache"
 	"github.com/pkg/errors"
+	"go.uber.org/zap"
 )
 
 const (
@@ -20,8 +21,15 @@ const (
 	homeAzCacheKey   = "HomeAz"
 )
 
+// homeAzProvider is the part of a hostmetadata.Provider which the HomeAzMonitor uses.
+type homeAzProvider interface {
+	SupportedAPIs(context.Context) ([]string, error)
+	GetHomeAz(context.Context) (nmagent.AzResponse, error)
+}
+
 type HomeAzMonitor struct {
-	nmagentClient
+	homeAzProvider
+	log    *zap.Logger
 	values *cache.Cache
 	// channel used as signal to end of the goroutine for populating home az cache
 	closing                  chan struct{}
@@ -29,9 +37,10 @@ type HomeAzMonitor struct {
 }
 
 // NewHomeAzMonitor creates a new HomeAzMonitor object
-func NewHomeAzMonitor(client nmagentClient, cacheRefreshIntervalSecs time.Duration) *HomeAzMonitor {
+func NewHomeAzMonitor(provider homeAzProvider, cacheRefreshIntervalSecs time.Duration) *HomeAzMonitor {
 	return &HomeAzMonitor{
-		nmagentClient:            client,
+		homeAzProvider:           provider,
+		log:                      logger.Named(logger.NMAgent).With(zap.String("monitor", "homeaz")),
 		cacheRefreshIntervalSecs: cacheRefreshIntervalSecs,
 		values:                   cache.New(cache.NoExpiration, cache.NoExpiration),
 		closing:                  make(chan struct{}),
@@ -94,6 +103,9 @@ func (h *HomeAzMonitor) refresh() {
 // Populate makes call to nmagent to retrieve home az if getHomeAz api is supported by nmagent
 func (h *HomeAzMonitor) Populate(ctx context.Context) {
 	supportedApis, err := h.SupportedAPIs(ctx)
+	if h.keepCacheValue(err) {
+		return
+	}
 	if err != nil {
 		returnMessage := fmt.Sprintf("[HomeAzMonitor] failed to query nmagent's supported apis, %v", err)
 		returnCode := types.NmAgentSupportedApisError
@@ -109,7 +121,10 @@ func (h *HomeAzMonitor) Populate(ctx context.Context) {
 	}
 
 	// calling NMAgent to get home AZ
-	azResponse, err := h.nmagentClient.GetHomeAz(ctx)
+	azResponse, err := h.homeAzProvider.GetHomeAz(ctx)
+	if h.keepCacheValue(err) {
+		return
+	}
 	if err != nil {
 		apiError := nmagent.Error{}
 		if ok := errors.As(err, &apiError); ok {
@@ -142,9 +157,22 @@ func (h *HomeAzMonitor) Populate(ctx context.Context) {
 	h.update(types.Success, "Get Home Az succeeded", cns.HomeAzResponse{IsSupported: true, HomeAz: azResponse.HomeAz})
 }
 
+// keepCacheValue reports whether the request to nmagent was rejected by the open circuit breaker of the nmagent client.
+// The home az is then left as cached until nmagent recovers, rather than being replaced by an error.
+func (h *HomeAzMonitor) keepCacheValue(err error) bool {
+	if !errors.Is(err, nmagent.ErrCircuitOpen) {
+		return false
+	}
+	if _, found := h.values.Get(homeAzCacheKey); !found {
+		return false
+	}
+	h.log.Debug("nmagent circuit breaker is open, keeping the cached home az")
+	return true
+}
+
 // update constructs a GetHomeAzResponse entity and update its cache
 func (h *HomeAzMonitor) update(code types.ResponseCode, msg string, homeAzResponse cns.HomeAzResponse) {
-	log.Debugf(msg)
+	h.log.Debug("updating home az cache", zap.String("code", code.String()), zap.String("message", msg))
 	resp := cns.GetHomeAzResponse{
 		Response: cns.Response{
 			ReturnCode: code,
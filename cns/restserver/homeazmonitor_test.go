@@ -87,3 +87,39 @@ func TestHomeAzMonitor(t *testing.T) {
 		})
 	}
 }
+
+// TestHomeAzMonitorCircuitOpen makes sure the cached home az is kept while the nmagent circuit breaker is open
+func TestHomeAzMonitorCircuitOpen(t *testing.T) {
+	circuitOpen := false
+	client := &fakes.NMAgentClientFake{
+		SupportedAPIsF: func(ctx context.Context) ([]string, error) {
+			if circuitOpen {
+				return nil, errors.Wrap(nmagent.ErrCircuitOpen, "submitting request")
+			}
+			return []string{GetHomeAzAPIName}, nil
+		},
+		GetHomeAzF: func(ctx context.Context) (nmagent.AzResponse, error) {
+			return nmagent.AzResponse{HomeAz: uint(1)}, nil
+		},
+	}
+	homeAzMonitor := NewHomeAzMonitor(client, time.Second)
+
+	// without a cached home az, the error is cached
+	circuitOpen = true
+	homeAzMonitor.Populate(context.TODO())
+	if got := homeAzMonitor.GetHomeAz(context.TODO()).Response.ReturnCode; got != types.NmAgentSupportedApisError {
+		t.Fatal("unexpected return code: got:", got, "exp:", types.NmAgentSupportedApisError)
+	}
+
+	circuitOpen = false
+	homeAzMonitor.Populate(context.TODO())
+	circuitOpen = true
+	homeAzMonitor.Populate(context.TODO())
+	getHomeAzResponse := homeAzMonitor.GetHomeAz(context.TODO())
+	if getHomeAzResponse.Response.ReturnCode != types.Success {
+		t.Fatal("unexpected error: ", getHomeAzResponse.Response.Message)
+	}
+	if exp := (cns.HomeAzResponse{IsSupported: true, HomeAz: uint(1)}); !cmp.Equal(getHomeAzResponse.HomeAzResponse, exp) {
+		t.Error("homeAz cache differs from expectation: diff:", cmp.Diff(getHomeAzResponse.HomeAzResponse, exp))
+	}
+}
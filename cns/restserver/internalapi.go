@@ -15,10 +15,10 @@ import (
 	"time"
 
 	"github.com/Azure/azure-container-networking/cns"
-	"github.com/Azure/azure-container-networking/cns/logger"
 	"github.com/Azure/azure-container-networking/cns/types"
 	"github.com/Azure/azure-container-networking/common"
 	"github.com/Azure/azure-container-networking/crd/nodenetworkconfig/api/v1alpha"
+	"github.com/Azure/azure-container-networking/nmagent"
 	"github.com/pkg/errors"
 )
 
@@ -43,7 +43,7 @@ func (service *HTTPRestService) SetNodeOrchestrator(r *cns.SetOrchestratorTypeRe
 }
 
 func (service *HTTPRestService) SyncNodeStatus(dncEP, infraVnet, nodeID string, contextFromCNI json.RawMessage) (returnCode types.ResponseCode, errStr string) {
-	logger.Printf("[Azure CNS] SyncNodeStatus")
+	restLog.Printf("[Azure CNS] SyncNodeStatus")
 	var (
 		resp             *http.Response
 		nodeInfoResponse cns.NodeInfoResponse
@@ -68,7 +68,7 @@ func (service *HTTPRestService) SyncNodeStatus(dncEP, infraVnet, nodeID string,
 	if err != nil {
 		returnCode = types.UnexpectedError
 		errStr = fmt.Sprintf("[Azure-CNS] Failed to sync node with error: %+v", err)
-		logger.Errorf(errStr)
+		restLog.Errorf(errStr)
 		return
 	}
 
@@ -98,7 +98,7 @@ func (service *HTTPRestService) SyncNodeStatus(dncEP, infraVnet, nodeID string,
 	ncVersionListResp, err := service.nma.GetNCVersionList(ctx)
 	if err != nil {
 		skipNCVersionCheck = true
-		logger.Errorf("failed to get nc version list from nmagent")
+		nmaLog.Errorf("failed to get nc version list from nmagent")
 	}
 
 	if !skipNCVersionCheck {
@@ -113,11 +113,11 @@ func (service *HTTPRestService) SyncNodeStatus(dncEP, infraVnet, nodeID string,
 
 			body, err = json.Marshal(ncsToBeAdded[ncid])
 			if err != nil {
-				logger.Errorf("[Azure-CNS] Failed to marshal nc with nc id %s and content %v", ncid, ncsToBeAdded[ncid])
+				restLog.Errorf("[Azure-CNS] Failed to marshal nc with nc id %s and content %v", ncid, ncsToBeAdded[ncid])
 			}
 			req, err = http.NewRequestWithContext(ctx, http.MethodPost, "", bytes.NewBuffer(body))
 			if err != nil {
-				logger.Errorf("[Azure CNS] Error received while creating http POST request for nc %v", ncsToBeAdded[ncid])
+				restLog.Errorf("[Azure CNS] Error received while creating http POST request for nc %v", ncsToBeAdded[ncid])
 			}
 			req.Header.Set(common.ContentType, common.JsonContent)
 
@@ -152,7 +152,7 @@ func (service *HTTPRestService) SyncNodeStatus(dncEP, infraVnet, nodeID string,
 			req.Header.Set(common.JsonContent, common.JsonContent)
 			service.deleteNetworkContainer(httptest.NewRecorder(), req)
 		} else {
-			logger.Errorf("[Azure-CNS] Failed to delete NC request to sync state: %s", err.Error())
+			restLog.Errorf("[Azure-CNS] Failed to delete NC request to sync state: %s", err.Error())
 		}
 	}
 	return
@@ -165,8 +165,13 @@ func (service *HTTPRestService) SyncHostNCVersion(ctx context.Context, channelMo
 	defer service.Unlock()
 	start := time.Now()
 	err := service.syncHostNCVersion(ctx, channelMode)
+	if errors.Is(err, nmagent.ErrCircuitOpen) {
+		// nmagent is known to be failing, so the sync is skipped until its circuit breaker lets requests through again
+		nmaLog.Printf("[SyncHostNCVersion] Skipping sync while the nmagent circuit breaker is open")
+		return
+	}
 	if err != nil {
-		logger.Errorf("sync host error %v", err)
+		nmaLog.Errorf("sync host error %v", err)
 	}
 	syncHostNCVersionCount.WithLabelValues(strconv.FormatBool(err == nil)).Inc()
 	syncHostNCVersionLatency.WithLabelValues(strconv.FormatBool(err == nil)).Observe(time.Since(start).Seconds())
@@ -180,19 +185,19 @@ func (service *HTTPRestService) syncHostNCVersion(ctx context.Context, channelMo
 		// Will open a separate PR to convert all the NC version related variable to int. Change from string to int is a pain.
 		localNCVersion, err := strconv.Atoi(service.state.ContainerStatus[idx].HostVersion)
 		if err != nil {
-			logger.Errorf("Received err when change containerstatus.HostVersion %s to int, err msg %v", service.state.ContainerStatus[idx].HostVersion, err)
+			nmaLog.Errorf("Received err when change containerstatus.HostVersion %s to int, err msg %v", service.state.ContainerStatus[idx].HostVersion, err)
 			continue
 		}
 		dncNCVersion, err := strconv.Atoi(service.state.ContainerStatus[idx].CreateNetworkContainerRequest.Version)
 		if err != nil {
-			logger.Errorf("Received err when change nc version %s in containerstatus to int, err msg %v", service.state.ContainerStatus[idx].CreateNetworkContainerRequest.Version, err)
+			nmaLog.Errorf("Received err when change nc version %s in containerstatus to int, err msg %v", service.state.ContainerStatus[idx].CreateNetworkContainerRequest.Version, err)
 			continue
 		}
 		// host NC version is the NC version from NMAgent, if it's smaller than NC version from DNC, then append it to indicate it needs update.
 		if localNCVersion < dncNCVersion {
 			outdatedNCs[service.state.ContainerStatus[idx].ID] = struct{}{}
 		} else if localNCVersion > dncNCVersion {
-			logger.Errorf("NC version from NMAgent is larger than DNC, NC version from NMAgent is %d, NC version from DNC is %d", localNCVersion, dncNCVersion)
+			nmaLog.Errorf("NC version from NMAgent is larger than DNC, NC version from NMAgent is %d, NC version from DNC is %d", localNCVersion, dncNCVersion)
 		}
 	}
 	if len(outdatedNCs) == 0 {
@@ -215,7 +220,7 @@ func (service *HTTPRestService) syncHostNCVersion(ctx context.Context, channelMo
 		}
 		nmaNCVersion, err := strconv.Atoi(nmaNCVersionStr)
 		if err != nil {
-			logger.Errorf("failed to parse container version of %s: %s", ncID, err)
+			nmaLog.Errorf("failed to parse container version of %s: %s", ncID, err)
 			continue
 		}
 		// Check whether it exist in service state and get the related nc info
@@ -227,19 +232,19 @@ func (service *HTTPRestService) syncHostNCVersion(ctx context.Context, channelMo
 		}
 		localNCVersion, err := strconv.Atoi(ncInfo.HostVersion)
 		if err != nil {
-			logger.Errorf("failed to parse host nc version string %s: %s", ncInfo.HostVersion, err)
+			nmaLog.Errorf("failed to parse host nc version string %s: %s", ncInfo.HostVersion, err)
 			continue
 		}
 		if localNCVersion > nmaNCVersion {
-			logger.Errorf("NC version from NMA is decreasing: have %d, got %d", localNCVersion, nmaNCVersion)
+			nmaLog.Errorf("NC version from NMA is decreasing: have %d, got %d", localNCVersion, nmaNCVersion)
 			continue
 		}
 		if channelMode == cns.CRD {
 			service.MarkIpsAsAvailableUntransacted(ncInfo.ID, nmaNCVersion)
 		}
-		logger.Printf("Updating NC %s host version from %s to %s", ncID, ncInfo.HostVersion, nmaNCVersionStr)
+		nmaLog.Printf("Updating NC %s host version from %s to %s", ncID, ncInfo.HostVersion, nmaNCVersionStr)
 		ncInfo.HostVersion = nmaNCVersionStr
-		logger.Printf("Updated NC %s host version to %s", ncID, ncInfo.HostVersion)
+		nmaLog.Printf("Updated NC %s host version to %s", ncID, ncInfo.HostVersion)
 		service.state.ContainerStatus[ncID] = ncInfo
 		// if we successfully updated the NC, pop it from the needs update set.
 		delete(outdatedNCs, ncID)
@@ -259,10 +264,10 @@ func (service *HTTPRestService) syncHostNCVersion(ctx context.Context, channelMo
 
 // This API will be called by CNS RequestController on CRD update.
 func (service *HTTPRestService) ReconcileNCState(ncRequest *cns.CreateNetworkContainerRequest, podInfoByIP map[string]cns.PodInfo, nnc *v1alpha.NodeNetworkConfig) types.ResponseCode {
-	logger.Printf("Reconciling NC state with podInfo %+v", podInfoByIP)
+	restLog.Printf("Reconciling NC state with podInfo %+v", podInfoByIP)
 	// check if ncRequest is null, then return as there is no CRD state yet
 	if ncRequest == nil {
-		logger.Printf("CNS starting with no NC state, podInfoMap count %d", len(podInfoByIP))
+		restLog.Printf("CNS starting with no NC state, podInfoMap count %d", len(podInfoByIP))
 		return types.Success
 	}
 
@@ -275,11 +280,11 @@ func (service *HTTPRestService) ReconcileNCState(ncRequest *cns.CreateNetworkCon
 	// now parse the secondaryIP list, if it exists in PodInfo list, then assign that ip.
 	for _, secIpConfig := range ncRequest.SecondaryIPConfigs {
 		if podInfo, exists := podInfoByIP[secIpConfig.IPAddress]; exists {
-			logger.Printf("SecondaryIP %+v is assigned to Pod. %+v, ncId: %s", secIpConfig, podInfo, ncRequest.NetworkContainerid)
+			restLog.Printf("SecondaryIP %+v is assigned to Pod. %+v, ncId: %s", secIpConfig, podInfo, ncRequest.NetworkContainerid)
 
 			jsonContext, err := podInfo.OrchestratorContext()
 			if err != nil {
-				logger.Errorf("Failed to marshal KubernetesPodInfo, error: %v", err)
+				restLog.Errorf("Failed to marshal KubernetesPodInfo, error: %v", err)
 				return types.UnexpectedError
 			}
 
@@ -291,17 +296,17 @@ func (service *HTTPRestService) ReconcileNCState(ncRequest *cns.CreateNetworkCon
 			}
 
 			if _, err := requestIPConfigHelper(service, ipconfigRequest); err != nil {
-				logger.Errorf("AllocateIPConfig failed for SecondaryIP %+v, podInfo %+v, ncId %s, error: %v", secIpConfig, podInfo, ncRequest.NetworkContainerid, err)
+				restLog.Errorf("AllocateIPConfig failed for SecondaryIP %+v, podInfo %+v, ncId %s, error: %v", secIpConfig, podInfo, ncRequest.NetworkContainerid, err)
 				return types.FailedToAllocateIPConfig
 			}
 		} else {
-			logger.Printf("SecondaryIP %+v is not assigned. ncId: %s", secIpConfig, ncRequest.NetworkContainerid)
+			restLog.Printf("SecondaryIP %+v is not assigned. ncId: %s", secIpConfig, ncRequest.NetworkContainerid)
 		}
 	}
 
 	err := service.MarkExistingIPsAsPendingRelease(nnc.Spec.IPsNotInUse)
 	if err != nil {
-		logger.Errorf("[Azure CNS] Error. Failed to mark IPs as pending %v", nnc.Spec.IPsNotInUse)
+		restLog.Errorf("[Azure CNS] Error. Failed to mark IPs as pending %v", nnc.Spec.IPsNotInUse)
 		return types.UnexpectedError
 	}
 
@@ -323,7 +328,7 @@ func (service *HTTPRestService) DeleteNetworkContainerInternal(
 ) types.ResponseCode {
 	_, exist := service.getNetworkContainerDetails(req.NetworkContainerid)
 	if !exist {
-		logger.Printf("network container for id %v doesn't exist", req.NetworkContainerid)
+		restLog.Printf("network container for id %v doesn't exist", req.NetworkContainerid)
 		return types.Success
 	}
 
@@ -349,21 +354,21 @@ func (service *HTTPRestService) DeleteNetworkContainerInternal(
 // This API will be called by CNS RequestController on CRD update.
 func (service *HTTPRestService) CreateOrUpdateNetworkContainerInternal(req *cns.CreateNetworkContainerRequest) types.ResponseCode {
 	if req.NetworkContainerid == "" {
-		logger.Errorf("[Azure CNS] Error. NetworkContainerid is empty")
+		restLog.Errorf("[Azure CNS] Error. NetworkContainerid is empty")
 		return types.NetworkContainerNotSpecified
 	}
 
 	// For now only RequestController uses this API which will be initialized only for AKS scenario.
 	// Validate ContainerType is set as Docker
 	if service.state.OrchestratorType != cns.KubernetesCRD && service.state.OrchestratorType != cns.Kubernetes {
-		logger.Errorf("[Azure CNS] Error. Unsupported OrchestratorType: %s", service.state.OrchestratorType)
+		restLog.Errorf("[Azure CNS] Error. Unsupported OrchestratorType: %s", service.state.OrchestratorType)
 		return types.UnsupportedOrchestratorType
 	}
 
 	// Validate PrimaryCA must never be empty
 	err := validateIPSubnet(req.IPConfiguration.IPSubnet)
 	if err != nil {
-		logger.Errorf("[Azure CNS] Error. PrimaryCA is invalid, NC Req: %v", req)
+		restLog.Errorf("[Azure CNS] Error. PrimaryCA is invalid, NC Req: %v", req)
 		return types.InvalidPrimaryIPConfig
 	}
 
@@ -371,7 +376,7 @@ func (service *HTTPRestService) CreateOrUpdateNetworkContainerInternal(req *cns.
 	for _, secIpconfig := range req.SecondaryIPConfigs {
 		// Validate Ipconfig
 		if secIpconfig.IPAddress == "" {
-			logger.Errorf("Failed to add IPConfig to state: %+v, empty IPSubnet.IPAddress", secIpconfig)
+			restLog.Errorf("Failed to add IPConfig to state: %+v, empty IPSubnet.IPAddress", secIpconfig)
 			return types.InvalidSecondaryIPConfig
 		}
 	}
@@ -381,7 +386,7 @@ func (service *HTTPRestService) CreateOrUpdateNetworkContainerInternal(req *cns.
 	if ok {
 		existingReq := existingNCInfo.CreateNetworkContainerRequest
 		if !reflect.DeepEqual(existingReq.IPConfiguration, req.IPConfiguration) {
-			logger.Errorf("[Azure CNS] Error. PrimaryCA is not same, NCId %s, old CA %s, new CA %s", req.NetworkContainerid, existingReq.PrimaryInterfaceIdentifier, req.PrimaryInterfaceIdentifier)
+			restLog.Errorf("[Azure CNS] Error. PrimaryCA is not same, NCId %s, old CA %s, new CA %s", req.NetworkContainerid, existingReq.PrimaryInterfaceIdentifier, req.PrimaryInterfaceIdentifier)
 			return types.PrimaryCANotSame
 		}
 	}
@@ -393,13 +398,13 @@ func (service *HTTPRestService) CreateOrUpdateNetworkContainerInternal(req *cns.
 	if returnCode == 0 {
 		logNCSnapshot(*req)
 	} else {
-		logger.Errorf(returnMessage)
+		restLog.Errorf(returnMessage)
 	}
 
 	if service.Options[common.OptProgramSNATIPTables] == true {
 		returnCode, returnMessage = service.programSNATRules(req)
 		if returnCode != 0 {
-			logger.Errorf(returnMessage)
+			restLog.Errorf(returnMessage)
 		}
 	}
 
@@ -11,35 +11,49 @@ import (
 
 	"github.com/Azure/azure-container-networking/cns"
 	"github.com/Azure/azure-container-networking/cns/filter"
-	"github.com/Azure/azure-container-networking/cns/logger"
 	"github.com/Azure/azure-container-networking/cns/types"
 	"github.com/Azure/azure-container-networking/common"
+	"github.com/Azure/azure-container-networking/otlp"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // used to request an IPConfig from the CNS state
 func (service *HTTPRestService) requestIPConfigHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+
 	var ipconfigRequest cns.IPConfigRequest
 	err := service.Listener.Decode(w, r, &ipconfigRequest)
 	operationName := "requestIPConfigHandler"
-	logger.Request(service.Name+operationName, ipconfigRequest, err)
+	restLog.Request(service.Name+operationName, ipconfigRequest, err)
 	if err != nil {
+		otlp.SetError(span, err)
 		return
 	}
 
+	reserveResp := service.requestIPConfig(ipconfigRequest)
+	setIPConfigSpanStatus(span, ipconfigRequest, reserveResp.Response)
+	if reserveResp.Response.ReturnCode == types.Success {
+		span.SetAttributes(attribute.String("ip", reserveResp.PodIpInfo.PodIPConfig.IPAddress))
+	}
+	w.Header().Set(cnsReturnCode, reserveResp.Response.ReturnCode.String())
+	err = service.Listener.Encode(w, &reserveResp)
+	restLog.ResponseEx(service.Name+operationName, ipconfigRequest, reserveResp, reserveResp.Response.ReturnCode, err)
+}
+
+// requestIPConfig assigns an IPConfig to the Pod of the request, and is shared by the HTTP and gRPC APIs.
+func (service *HTTPRestService) requestIPConfig(ipconfigRequest cns.IPConfigRequest) *cns.IPConfigResponse {
 	// retrieve ipconfig from nc
 	podInfo, returnCode, returnMessage := service.validateIPConfigRequest(ipconfigRequest)
 	if returnCode != types.Success {
-		reserveResp := &cns.IPConfigResponse{
+		return &cns.IPConfigResponse{
 			Response: cns.Response{
 				ReturnCode: returnCode,
 				Message:    returnMessage,
 			},
 		}
-		w.Header().Set(cnsReturnCode, reserveResp.Response.ReturnCode.String())
-		err = service.Listener.Encode(w, &reserveResp)
-		logger.ResponseEx(service.Name+operationName, ipconfigRequest, reserveResp, reserveResp.Response.ReturnCode, err)
-		return
 	}
 
 	// record a pod requesting an IP
@@ -47,17 +61,13 @@ func (service *HTTPRestService) requestIPConfigHandler(w http.ResponseWriter, r
 
 	podIPInfo, err := requestIPConfigHelper(service, ipconfigRequest)
 	if err != nil {
-		reserveResp := &cns.IPConfigResponse{
+		return &cns.IPConfigResponse{
 			Response: cns.Response{
 				ReturnCode: types.FailedToAllocateIPConfig,
 				Message:    fmt.Sprintf("AllocateIPConfig failed: %v, IP config request is %s", err, ipconfigRequest),
 			},
 			PodIpInfo: podIPInfo,
 		}
-		w.Header().Set(cnsReturnCode, reserveResp.Response.ReturnCode.String())
-		err = service.Listener.Encode(w, &reserveResp)
-		logger.ResponseEx(service.Name+operationName, ipconfigRequest, reserveResp, reserveResp.Response.ReturnCode, err)
-		return
 	}
 
 	// record a pod assigned an IP
@@ -72,29 +82,22 @@ func (service *HTTPRestService) requestIPConfigHandler(w http.ResponseWriter, r
 	if service.Options[common.OptManageEndpointState] == true {
 		err = service.updateEndpointState(ipconfigRequest, podInfo, podIPInfo)
 		if err != nil {
-			reserveResp := &cns.IPConfigResponse{
+			return &cns.IPConfigResponse{
 				Response: cns.Response{
 					ReturnCode: types.UnexpectedError,
 					Message:    fmt.Sprintf("Update endpoint state failed: %v ", err),
 				},
 				PodIpInfo: podIPInfo,
 			}
-			w.Header().Set(cnsReturnCode, reserveResp.Response.ReturnCode.String())
-			err = service.Listener.Encode(w, &reserveResp)
-			logger.ResponseEx(service.Name+operationName, ipconfigRequest, reserveResp, reserveResp.Response.ReturnCode, err)
-			return
 		}
 	}
 
-	reserveResp := &cns.IPConfigResponse{
+	return &cns.IPConfigResponse{
 		Response: cns.Response{
 			ReturnCode: types.Success,
 		},
 		PodIpInfo: podIPInfo,
 	}
-	w.Header().Set(cnsReturnCode, reserveResp.Response.ReturnCode.String())
-	err = service.Listener.Encode(w, &reserveResp)
-	logger.ResponseEx(service.Name+operationName, ipconfigRequest, reserveResp, reserveResp.Response.ReturnCode, err)
 }
 
 var (
@@ -108,19 +111,19 @@ func (service *HTTPRestService) updateEndpointState(ipconfigRequest cns.IPConfig
 	}
 	service.Lock()
 	defer service.Unlock()
-	logger.Printf("[updateEndpointState] Updating endpoint state for infra container %s", ipconfigRequest.InfraContainerID)
+	restLog.Printf("[updateEndpointState] Updating endpoint state for infra container %s", ipconfigRequest.InfraContainerID)
 	if endpointInfo, ok := service.EndpointState[ipconfigRequest.InfraContainerID]; ok {
-		logger.Warnf("[updateEndpointState] Found existing endpoint state for infra container %s", ipconfigRequest.InfraContainerID)
+		restLog.Warnf("[updateEndpointState] Found existing endpoint state for infra container %s", ipconfigRequest.InfraContainerID)
 		ip := net.ParseIP(podIPInfo.PodIPConfig.IPAddress)
 		if ip == nil {
-			logger.Errorf("failed to parse pod ip address %s", podIPInfo.PodIPConfig.IPAddress)
+			restLog.Errorf("failed to parse pod ip address %s", podIPInfo.PodIPConfig.IPAddress)
 			return errParsePodIPFailed
 		}
 		if ip.To4() == nil { // is an ipv6 address
 			ipconfig := net.IPNet{IP: ip, Mask: net.CIDRMask(int(podIPInfo.PodIPConfig.PrefixLength), 128)} // nolint
 			for _, ipconf := range endpointInfo.IfnameToIPMap[ipconfigRequest.Ifname].IPv6 {
 				if ipconf.IP.Equal(ipconfig.IP) {
-					logger.Printf("[updateEndpointState] Found existing ipv6 ipconfig for infra container %s", ipconfigRequest.InfraContainerID)
+					restLog.Printf("[updateEndpointState] Found existing ipv6 ipconfig for infra container %s", ipconfigRequest.InfraContainerID)
 					return nil
 				}
 			}
@@ -129,7 +132,7 @@ func (service *HTTPRestService) updateEndpointState(ipconfigRequest cns.IPConfig
 			ipconfig := net.IPNet{IP: ip, Mask: net.CIDRMask(int(podIPInfo.PodIPConfig.PrefixLength), 32)} // nolint
 			for _, ipconf := range endpointInfo.IfnameToIPMap[ipconfigRequest.Ifname].IPv4 {
 				if ipconf.IP.Equal(ipconfig.IP) {
-					logger.Printf("[updateEndpointState] Found existing ipv4 ipconfig for infra container %s", ipconfigRequest.InfraContainerID)
+					restLog.Printf("[updateEndpointState] Found existing ipv4 ipconfig for infra container %s", ipconfigRequest.InfraContainerID)
 					return nil
 				}
 			}
@@ -142,7 +145,7 @@ func (service *HTTPRestService) updateEndpointState(ipconfigRequest cns.IPConfig
 		endpointInfo := &EndpointInfo{PodName: podInfo.Name(), PodNamespace: podInfo.Namespace(), IfnameToIPMap: make(map[string]*IPInfo)}
 		ip := net.ParseIP(podIPInfo.PodIPConfig.IPAddress)
 		if ip == nil {
-			logger.Errorf("failed to parse pod ip address %s", podIPInfo.PodIPConfig.IPAddress)
+			restLog.Errorf("failed to parse pod ip address %s", podIPInfo.PodIPConfig.IPAddress)
 			return errParsePodIPFailed
 		}
 		ipInfo := &IPInfo{}
@@ -165,50 +168,68 @@ func (service *HTTPRestService) updateEndpointState(ipconfigRequest cns.IPConfig
 }
 
 func (service *HTTPRestService) releaseIPConfigHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+
 	var req cns.IPConfigRequest
 	err := service.Listener.Decode(w, r, &req)
-	logger.Request(service.Name+"releaseIPConfigHandler", req, err)
+	restLog.Request(service.Name+"releaseIPConfigHandler", req, err)
 	if err != nil {
+		otlp.SetError(span, err)
 		resp := cns.Response{
 			ReturnCode: types.UnexpectedError,
 			Message:    err.Error(),
 		}
-		logger.Errorf("releaseIPConfigHandler decode failed becase %v, release IP config info %s", resp.Message, req)
+		restLog.Errorf("releaseIPConfigHandler decode failed becase %v, release IP config info %s", resp.Message, req)
 		w.Header().Set(cnsReturnCode, resp.ReturnCode.String())
 		err = service.Listener.Encode(w, &resp)
-		logger.ResponseEx(service.Name, req, resp, resp.ReturnCode, err)
+		restLog.ResponseEx(service.Name, req, resp, resp.ReturnCode, err)
 		return
 	}
 
+	resp := service.releaseIPConfigRequest(req)
+	setIPConfigSpanStatus(span, req, resp)
+	w.Header().Set(cnsReturnCode, resp.ReturnCode.String())
+	err = service.Listener.Encode(w, &resp)
+	restLog.ResponseEx(service.Name, req, resp, resp.ReturnCode, err)
+}
+
+// setIPConfigSpanStatus describes the pod of the IPConfig request in the span, and sets its status from the response.
+func setIPConfigSpanStatus(span trace.Span, req cns.IPConfigRequest, resp cns.Response) {
+	span.SetAttributes(
+		attribute.String("infra_container_id", req.InfraContainerID),
+		attribute.String("return_code", resp.ReturnCode.String()))
+	if resp.ReturnCode != types.Success {
+		otlp.SetError(span, errors.Errorf("%s: %s", resp.ReturnCode.String(), resp.Message))
+		return
+	}
+	span.SetStatus(codes.Ok, "")
+}
+
+// releaseIPConfigRequest releases the IPConfig of the Pod of the request, and is shared by the HTTP and gRPC APIs.
+func (service *HTTPRestService) releaseIPConfigRequest(req cns.IPConfigRequest) cns.Response {
 	podInfo, returnCode, message := service.validateIPConfigRequest(req)
 
 	// Check if http rest service managed endpoint state is set
 	if service.Options[common.OptManageEndpointState] == true {
-		if err = service.removeEndpointState(podInfo); err != nil {
+		if err := service.removeEndpointState(podInfo); err != nil {
 			resp := cns.Response{
 				ReturnCode: types.UnexpectedError,
 				Message:    err.Error(),
 			}
-			logger.Errorf("releaseIPConfigHandler remove endpoint state failed because %v, release IP config info %s", resp.Message, req)
-			w.Header().Set(cnsReturnCode, resp.ReturnCode.String())
-			err = service.Listener.Encode(w, &resp)
-			logger.ResponseEx(service.Name, req, resp, resp.ReturnCode, err)
-			return
+			restLog.Errorf("releaseIPConfigHandler remove endpoint state failed because %v, release IP config info %s", resp.Message, req)
+			return resp
 		}
 	}
 
-	if err = service.releaseIPConfig(podInfo); err != nil {
+	if err := service.releaseIPConfig(podInfo); err != nil {
 		returnCode = types.UnexpectedError
 		message = err.Error()
-		logger.Errorf("releaseIPConfigHandler releaseIPConfig failed because %v, release IP config info %s", message, req)
+		restLog.Errorf("releaseIPConfigHandler releaseIPConfig failed because %v, release IP config info %s", message, req)
 	}
-	resp := cns.Response{
+	return cns.Response{
 		ReturnCode: returnCode,
 		Message:    message,
 	}
-	w.Header().Set(cnsReturnCode, resp.ReturnCode.String())
-	err = service.Listener.Encode(w, &resp)
-	logger.ResponseEx(service.Name, req, resp, resp.ReturnCode, err)
 }
 
 func (service *HTTPRestService) removeEndpointState(podInfo cns.PodInfo) error {
@@ -217,7 +238,7 @@ func (service *HTTPRestService) removeEndpointState(podInfo cns.PodInfo) error {
 	}
 	service.Lock()
 	defer service.Unlock()
-	logger.Printf("[removeEndpointState] Removing endpoint state for infra container %s", podInfo.InfraContainerID())
+	restLog.Printf("[removeEndpointState] Removing endpoint state for infra container %s", podInfo.InfraContainerID())
 	if _, ok := service.EndpointState[podInfo.InfraContainerID()]; ok {
 		delete(service.EndpointState, podInfo.InfraContainerID())
 		err := service.EndpointStateStore.Write(EndpointStoreKey, service.EndpointState)
@@ -225,7 +246,7 @@ func (service *HTTPRestService) removeEndpointState(podInfo cns.PodInfo) error {
 			return fmt.Errorf("failed to write endpoint state to store: %w", err)
 		}
 	} else { // will not fail if no endpoint state for infra container id is found
-		logger.Printf("[removeEndpointState] No endpoint state found for infra container %s", podInfo.InfraContainerID())
+		restLog.Printf("[removeEndpointState] No endpoint state found for infra container %s", podInfo.InfraContainerID())
 	}
 	return nil
 }
@@ -267,13 +288,17 @@ func (service *HTTPRestService) MarkIPAsPendingRelease(totalIpsToRelease int) (m
 		}
 	}
 
-	logger.Printf("[MarkIPAsPendingRelease] Set total ips to PendingRelease %d, expected %d", len(pendingReleasedIps), totalIpsToRelease)
+	restLog.Printf("[MarkIPAsPendingRelease] Set total ips to PendingRelease %d, expected %d", len(pendingReleasedIps), totalIpsToRelease)
 	return pendingReleasedIps, nil
 }
 
 func (service *HTTPRestService) updateIPConfigState(ipID string, updatedState types.IPState, podInfo cns.PodInfo) (cns.IPConfigurationStatus, error) {
 	if ipConfig, found := service.PodIPConfigState[ipID]; found {
-		logger.Printf("[updateIPConfigState] Changing IpId [%s] state to [%s], podInfo [%+v]. Current config [%+v]", ipID, updatedState, podInfo, ipConfig)
+		restLog.Printf("[updateIPConfigState] Changing IpId [%s] state to [%s], podInfo [%+v]. Current config [%+v]", ipID, updatedState, podInfo, ipConfig)
+		// state middlewares see the Pod which an IP is being assigned to, or the Pod it is being released from
+		if podInfo != nil {
+			ipConfig.PodInfo = podInfo
+		}
 		ipConfig.SetState(updatedState)
 		ipConfig.PodInfo = podInfo
 		service.PodIPConfigState[ipID] = ipConfig
@@ -289,28 +314,28 @@ func (service *HTTPRestService) updateIPConfigState(ipID string, updatedState ty
 func (service *HTTPRestService) MarkIpsAsAvailableUntransacted(ncID string, newHostNCVersion int) {
 	// Check whether it exist in service state and get the related nc info
 	if ncInfo, exist := service.state.ContainerStatus[ncID]; !exist {
-		logger.Errorf("Can't find NC with ID %s in service state, stop updating its pending programming IP status", ncID)
+		restLog.Errorf("Can't find NC with ID %s in service state, stop updating its pending programming IP status", ncID)
 	} else {
 		previousHostNCVersion, err := strconv.Atoi(ncInfo.HostVersion)
 		if err != nil {
-			logger.Printf("[MarkIpsAsAvailableUntransacted] Get int value from ncInfo.HostVersion %s failed: %v, can't proceed", ncInfo.HostVersion, err)
+			restLog.Printf("[MarkIpsAsAvailableUntransacted] Get int value from ncInfo.HostVersion %s failed: %v, can't proceed", ncInfo.HostVersion, err)
 			return
 		}
 		// We only need to handle the situation when dnc nc version is larger than programmed nc version
 		if previousHostNCVersion < newHostNCVersion {
 			for uuid, secondaryIPConfigs := range ncInfo.CreateNetworkContainerRequest.SecondaryIPConfigs {
 				if ipConfigStatus, exist := service.PodIPConfigState[uuid]; !exist {
-					logger.Errorf("IP %s with uuid as %s exist in service state Secondary IP list but can't find in PodIPConfigState", ipConfigStatus.IPAddress, uuid)
+					restLog.Errorf("IP %s with uuid as %s exist in service state Secondary IP list but can't find in PodIPConfigState", ipConfigStatus.IPAddress, uuid)
 				} else if ipConfigStatus.GetState() == types.PendingProgramming && secondaryIPConfigs.NCVersion <= newHostNCVersion {
 					_, err := service.updateIPConfigState(uuid, types.Available, nil)
 					if err != nil {
-						logger.Errorf("Error updating IPConfig [%+v] state to Available, err: %+v", ipConfigStatus, err)
+						restLog.Errorf("Error updating IPConfig [%+v] state to Available, err: %+v", ipConfigStatus, err)
 					}
 
 					// Following 2 sentence assign new host version to secondary ip config.
 					secondaryIPConfigs.NCVersion = newHostNCVersion
 					ncInfo.CreateNetworkContainerRequest.SecondaryIPConfigs[uuid] = secondaryIPConfigs
-					logger.Printf("Change ip %s with uuid %s from pending programming to %s, current secondary ip configs is %+v", ipConfigStatus.IPAddress, uuid, types.Available,
+					restLog.Printf("Change ip %s with uuid %s from pending programming to %s, current secondary ip configs is %+v", ipConfigStatus.IPAddress, uuid, types.Available,
 						ncInfo.CreateNetworkContainerRequest.SecondaryIPConfigs[uuid])
 				}
 			}
@@ -335,7 +360,7 @@ func (service *HTTPRestService) handleDebugPodContext(w http.ResponseWriter, r *
 		PodContext: service.PodIPIDByPodInterfaceKey,
 	}
 	err := service.Listener.Encode(w, &resp)
-	logger.Response(service.Name, resp, resp.Response.ReturnCode, err)
+	restLog.Response(service.Name, resp, resp.Response.ReturnCode, err)
 }
 
 func (service *HTTPRestService) handleDebugRestData(w http.ResponseWriter, r *http.Request) {
@@ -349,7 +374,7 @@ func (service *HTTPRestService) handleDebugRestData(w http.ResponseWriter, r *ht
 		},
 	}
 	err := service.Listener.Encode(w, &resp)
-	logger.Response(service.Name, resp, resp.Response.ReturnCode, err)
+	restLog.Response(service.Name, resp, resp.Response.ReturnCode, err)
 }
 
 func (service *HTTPRestService) handleDebugIPAddresses(w http.ResponseWriter, r *http.Request) {
@@ -362,15 +387,23 @@ func (service *HTTPRestService) handleDebugIPAddresses(w http.ResponseWriter, r
 			},
 		}
 		err = service.Listener.Encode(w, &resp)
-		logger.ResponseEx(service.Name, req, resp, resp.Response.ReturnCode, err)
+		restLog.ResponseEx(service.Name, req, resp, resp.Response.ReturnCode, err)
 		return
 	}
 	// Get all IPConfigs matching a state and return in the response
-	resp := cns.GetIPAddressStatusResponse{
+	resp := service.getIPAddresses(req)
+	err := service.Listener.Encode(w, &resp)
+	restLog.ResponseEx(service.Name, req, resp, resp.Response.ReturnCode, err)
+}
+
+// getIPAddresses returns the IPConfigs matching any of the states of the request.
+func (service *HTTPRestService) getIPAddresses(req cns.GetIPAddressesRequest) cns.GetIPAddressStatusResponse {
+	service.RLock()
+	defer service.RUnlock()
+	return cns.GetIPAddressStatusResponse{
 		IPConfigurationStatus: filter.MatchAnyIPConfigState(service.PodIPConfigState, filter.PredicatesForStates(req.IPConfigStateFilter...)...),
+		ResourceVersion:       service.ipStateEvents.currentResourceVersion(),
 	}
-	err := service.Listener.Encode(w, &resp)
-	logger.ResponseEx(service.Name, req, resp, resp.Response.ReturnCode, err)
 }
 
 // GetAssignedIPConfigs returns a filtered list of IPs which are in
@@ -424,7 +457,7 @@ func (service *HTTPRestService) unassignIPConfig(ipconfig cns.IPConfigurationSta
 	}
 
 	delete(service.PodIPIDByPodInterfaceKey, podInfo.Key())
-	logger.Printf("[setIPConfigAsAvailable] Deleted outdated pod info %s from PodIPIDByOrchestratorContext since IP %s with ID %s will be released and set as Available",
+	restLog.Printf("[setIPConfigAsAvailable] Deleted outdated pod info %s from PodIPIDByOrchestratorContext since IP %s with ID %s will be released and set as Available",
 		podInfo.Key(), ipconfig.IPAddress, ipconfig.ID)
 	return ipconfig, nil
 }
@@ -438,20 +471,20 @@ func (service *HTTPRestService) releaseIPConfig(podInfo cns.PodInfo) error {
 	ipID := service.PodIPIDByPodInterfaceKey[podInfo.Key()]
 	if ipID != "" {
 		if ipconfig, isExist := service.PodIPConfigState[ipID]; isExist {
-			logger.Printf("[releaseIPConfig] Releasing IP %+v for pod %+v", ipconfig.IPAddress, podInfo)
+			restLog.Printf("[releaseIPConfig] Releasing IP %+v for pod %+v", ipconfig.IPAddress, podInfo)
 			_, err := service.unassignIPConfig(ipconfig, podInfo)
 			if err != nil {
 				return fmt.Errorf("[releaseIPConfig] failed to mark IPConfig [%+v] as Available. err: %v", ipconfig, err)
 			}
-			logger.Printf("[releaseIPConfig] Released IP %+v for pod %+v", ipconfig.IPAddress, podInfo)
+			restLog.Printf("[releaseIPConfig] Released IP %+v for pod %+v", ipconfig.IPAddress, podInfo)
 		} else {
-			logger.Errorf("[releaseIPConfig] Failed to get release ipconfig %+v and pod info is %+v. Pod to IPID exists, but IPID to IPConfig doesn't exist, CNS State potentially corrupt",
+			restLog.Errorf("[releaseIPConfig] Failed to get release ipconfig %+v and pod info is %+v. Pod to IPID exists, but IPID to IPConfig doesn't exist, CNS State potentially corrupt",
 				ipconfig.IPAddress, podInfo)
 			return fmt.Errorf("[releaseIPConfig] releaseIPConfig failed. IPconfig %+v and pod info is %+v. Pod to IPID exists, but IPID to IPConfig doesn't exist, CNS State potentially corrupt",
 				ipconfig.IPAddress, podInfo)
 		}
 	} else {
-		logger.Errorf("[releaseIPConfig] SetIPConfigAsAvailable ignoring request to release, no allocation found for pod [%+v]", podInfo)
+		restLog.Errorf("[releaseIPConfig] SetIPConfigAsAvailable ignoring request to release, no allocation found for pod [%+v]", podInfo)
 		return nil
 	}
 	return nil
@@ -468,11 +501,11 @@ func (service *HTTPRestService) MarkExistingIPsAsPendingRelease(pendingIPIDs []s
 				return errors.Errorf("Failed to mark IP [%v] as pending, currently assigned", id)
 			}
 
-			logger.Printf("[MarkExistingIPsAsPending]: Marking IP [%+v] to PendingRelease", ipconfig)
+			restLog.Printf("[MarkExistingIPsAsPending]: Marking IP [%+v] to PendingRelease", ipconfig)
 			ipconfig.SetState(types.PendingRelease)
 			service.PodIPConfigState[id] = ipconfig
 		} else {
-			logger.Errorf("Inconsistent state, ipconfig with ID [%v] marked as pending release, but does not exist in state", id)
+			restLog.Errorf("Inconsistent state, ipconfig with ID [%v] marked as pending release, but does not exist in state", id)
 		}
 	}
 	return nil
@@ -494,7 +527,7 @@ func (service *HTTPRestService) GetExistingIPConfig(podInfo cns.PodInfo) (cns.Po
 			return podIpInfo, isExist, err
 		}
 
-		logger.Errorf("Failed to get existing ipconfig. Pod to IPID exists, but IPID to IPConfig doesn't exist, CNS State potentially corrupt")
+		restLog.Errorf("Failed to get existing ipconfig. Pod to IPID exists, but IPID to IPConfig doesn't exist, CNS State potentially corrupt")
 		return podIpInfo, isExist, fmt.Errorf("Failed to get existing ipconfig. Pod to IPID exists, but IPID to IPConfig doesn't exist, CNS State potentially corrupt")
 	}
 
@@ -513,7 +546,7 @@ func (service *HTTPRestService) AssignDesiredIPConfig(podInfo cns.PodInfo, desir
 				// This IP has already been assigned, if it is assigned to same pod, then return the same
 				// IPconfiguration
 				if ipConfig.PodInfo.Key() == podInfo.Key() {
-					logger.Printf("[AssignDesiredIPConfig]: IP Config [%+v] is already assigned to this Pod [%+v]", ipConfig, podInfo)
+					restLog.Printf("[AssignDesiredIPConfig]: IP Config [%+v] is already assigned to this Pod [%+v]", ipConfig, podInfo)
 				} else {
 					return podIpInfo, errors.Errorf("[AssignDesiredIPConfig] Desired IP is already assigned %+v, requested for pod %+v", ipConfig, podInfo)
 				}
@@ -576,3 +609,52 @@ func requestIPConfigHelper(service *HTTPRestService, req cns.IPConfigRequest) (c
 	// return any free IPConfig
 	return service.AssignAnyAvailableIPConfig(podInfo)
 }
+
+// NotifyIPStateChanges makes the service notify ch when IPs are added, removed, or change state,
+// so that the IPAM pool monitor can reconcile the pool immediately instead of polling.
+// Notifications don't block, so they are dropped (coalesced) while ch is full.
+// It must be called before the service is started.
+func (service *HTTPRestService) NotifyIPStateChanges(ch chan<- struct{}) {
+	service.ipStateNotifier = ch
+}
+
+// ipStateChangeMiddleware is a state middleware of every IPConfigurationStatus which records and notifies of its state changes.
+func (service *HTTPRestService) ipStateChangeMiddleware(ipconfig *cns.IPConfigurationStatus, state types.IPState) {
+	service.ipStateEvents.record(ipconfig, state, false)
+	service.notifyIPStateChange()
+}
+
+func (service *HTTPRestService) notifyIPStateChange() {
+	if service.ipStateNotifier != nil {
+		select {
+		case service.ipStateNotifier <- struct{}{}:
+		default:
+		}
+	}
+
+	service.ipStateSubscribersMu.Lock()
+	defer service.ipStateSubscribersMu.Unlock()
+	for ch := range service.ipStateSubscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// SubscribeIPStateChanges returns a channel which is notified when IPs are added, removed, or change state,
+// and a func which unsubscribes it. Like NotifyIPStateChanges, notifications are coalesced while the channel is full.
+func (service *HTTPRestService) SubscribeIPStateChanges() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	service.ipStateSubscribersMu.Lock()
+	defer service.ipStateSubscribersMu.Unlock()
+	if service.ipStateSubscribers == nil {
+		service.ipStateSubscribers = make(map[chan struct{}]struct{})
+	}
+	service.ipStateSubscribers[ch] = struct{}{}
+	return ch, func() {
+		service.ipStateSubscribersMu.Lock()
+		defer service.ipStateSubscribersMu.Unlock()
+		delete(service.ipStateSubscribers, ch)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/Azure/azure-container-networking/cns"
 	"github.com/Azure/azure-container-networking/cns/common"
 	"github.com/Azure/azure-container-networking/cns/fakes"
+	"github.com/Azure/azure-container-networking/cns/hostmetadata"
 	"github.com/Azure/azure-container-networking/cns/types"
 	"github.com/Azure/azure-container-networking/crd/nodenetworkconfig/api/v1alpha"
 	"github.com/Azure/azure-container-networking/store"
@@ -40,7 +41,8 @@ var (
 
 func getTestService() *HTTPRestService {
 	var config common.ServiceConfig
-	httpsvc, _ := NewHTTPRestService(&config, &fakes.WireserverClientFake{}, &fakes.NMAgentClientFake{}, store.NewMockStore(""), nil, nil)
+	nma := &fakes.NMAgentClientFake{}
+	httpsvc, _ := NewHTTPRestService(&config, hostmetadata.NewWireserver(&fakes.WireserverClientFake{}, nma), nma, store.NewMockStore(""), nil, nil)
 	svc = httpsvc.(*HTTPRestService)
 	svc.IPAMPoolMonitor = &fakes.MonitorFake{}
 	setOrchestratorTypeInternal(cns.KubernetesCRD)
@@ -483,6 +485,49 @@ func TestIPAMRequestThenReleaseThenRequestAgain(t *testing.T) {
 	assert.Equal(t, desiredState.PodInfo, actualstate.PodInfo)
 }
 
+func TestIPStateChangeNotifications(t *testing.T) {
+	svc := getTestService()
+	ipStateChanges := make(chan struct{}, 1)
+	svc.NotifyIPStateChanges(ipStateChanges)
+
+	assertNotified := func(action string) {
+		select {
+		case <-ipStateChanges:
+		default:
+			t.Fatalf("Expected a notification of the IP state change after %s", action)
+		}
+	}
+
+	// adding IPs notifies once, since notifications are coalesced
+	secondaryIPConfigs := map[string]cns.SecondaryIPConfig{
+		testPod1GUID: newSecondaryIPConfig(testIP1, -1),
+		testPod2GUID: newSecondaryIPConfig(testIP2, -1),
+	}
+	createAndValidateNCRequest(t, secondaryIPConfigs, testNCID, "-1")
+	assertNotified("adding IPs")
+
+	req := cns.IPConfigRequest{
+		PodInterfaceID:   testPod1Info.InterfaceID(),
+		InfraContainerID: testPod1Info.InfraContainerID(),
+	}
+	b, _ := testPod1Info.OrchestratorContext()
+	req.OrchestratorContext = b
+	if _, err := requestIPAddressAndGetState(t, req); err != nil {
+		t.Fatalf("Expected IP retrieval to be nil: %+v", err)
+	}
+	assertNotified("assigning an IP")
+
+	if err := svc.releaseIPConfig(testPod1Info); err != nil {
+		t.Fatalf("Unexpected failure releasing IP: %+v", err)
+	}
+	assertNotified("releasing an IP")
+
+	if _, err := svc.MarkIPAsPendingRelease(1); err != nil {
+		t.Fatalf("Unexpected failure marking IP as pending release: %+v", err)
+	}
+	assertNotified("marking an IP as pending release")
+}
+
 func TestIPAMReleaseIPIdempotency(t *testing.T) {
 	svc := getTestService()
 	// set state as already assigned
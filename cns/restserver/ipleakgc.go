@@ -0,0 +1,148 @@
+package restserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/Azure/azure-container-networking/cns"
+	"github.com/Azure/azure-container-networking/cns/types"
+	"github.com/Azure/azure-container-networking/common"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// IPLeakReclaimedEventReason is the reason of the Event emitted for each leaked IP that is released.
+const IPLeakReclaimedEventReason = "IPLeakReclaimed"
+
+// LivePodsProvider returns the Pods which exist on this Node, keyed by "namespace/name".
+// It is backed by the apiserver, but may be backed by a local source such as the kubelet or CRI.
+type LivePodsProvider interface {
+	LivePods(context.Context) (map[string]struct{}, error)
+}
+
+// LivePodsProviderFunc functional type to implement LivePodsProvider.
+type LivePodsProviderFunc func(context.Context) (map[string]struct{}, error)
+
+func (f LivePodsProviderFunc) LivePods(ctx context.Context) (map[string]struct{}, error) {
+	return f(ctx)
+}
+
+// IPLeakGC releases the Assigned IPs of Pods which no longer exist, such as when the CNI DEL for a Pod
+// never reached CNS. An IP is only released once its Pod has been missing for the grace period, so that
+// Pods which are being created (and aren't listed yet) and short outages of the Pod source are tolerated.
+type IPLeakGC struct {
+	log         *zap.Logger
+	service     *HTTPRestService
+	pods        LivePodsProvider
+	recorder    record.EventRecorder
+	node        *corev1.Node
+	interval    time.Duration
+	gracePeriod time.Duration
+	// missingSince is when the Pod of an Assigned IP was first seen missing, keyed by IP ID.
+	missingSince map[string]time.Time
+}
+
+// NewIPLeakGC creates an IPLeakGC. The recorder and node are optional, and Events are only emitted
+// on the node when both are set.
+func NewIPLeakGC(service *HTTPRestService, pods LivePodsProvider, recorder record.EventRecorder, node *corev1.Node, interval, gracePeriod time.Duration) *IPLeakGC {
+	return &IPLeakGC{
+		log:          service.log.With(zap.String("gc", "ipleak")),
+		service:      service,
+		pods:         pods,
+		recorder:     recorder,
+		node:         node,
+		interval:     interval,
+		gracePeriod:  gracePeriod,
+		missingSince: make(map[string]time.Time),
+	}
+}
+
+// Start runs the IPLeakGC until the context is cancelled.
+func (gc *IPLeakGC) Start(ctx context.Context) {
+	gc.log.Info("starting", zap.Duration("interval", gc.interval), zap.Duration("gracePeriod", gc.gracePeriod))
+	ticker := time.NewTicker(gc.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			gc.log.Info("exiting")
+			return
+		case <-ticker.C:
+			if err := gc.collect(ctx, time.Now()); err != nil {
+				gc.log.Error("failed to collect leaked IPs", zap.Error(err))
+			}
+		}
+	}
+}
+
+// collect releases the Assigned IPs whose Pod has been missing since at least the grace period before now.
+func (gc *IPLeakGC) collect(ctx context.Context, now time.Time) error {
+	livePods, err := gc.pods.LivePods(ctx)
+	if err != nil {
+		ipLeakGCRunCount.WithLabelValues("false").Inc()
+		return errors.Wrap(err, "failed to list live pods")
+	}
+
+	missing := make(map[string]time.Time)
+	for _, ipConfig := range gc.service.GetAssignedIPConfigs() {
+		if ipConfig.PodInfo == nil {
+			continue
+		}
+		if _, ok := livePods[ipConfig.PodInfo.Namespace()+"/"+ipConfig.PodInfo.Name()]; ok {
+			continue
+		}
+		since, ok := gc.missingSince[ipConfig.ID]
+		if !ok {
+			since = now
+		}
+		if now.Sub(since) < gc.gracePeriod {
+			missing[ipConfig.ID] = since
+			continue
+		}
+		released, err := gc.service.releaseLeakedIPConfig(ipConfig)
+		if err != nil {
+			gc.log.Error("failed to release leaked IP", zap.String("ip", ipConfig.IPAddress),
+				zap.String("namespace", ipConfig.PodInfo.Namespace()), zap.String("pod", ipConfig.PodInfo.Name()), zap.Error(err))
+			missing[ipConfig.ID] = since
+			continue
+		}
+		if !released {
+			continue
+		}
+		if gc.service.Options[common.OptManageEndpointState] == true {
+			if err := gc.service.removeEndpointState(ipConfig.PodInfo); err != nil {
+				gc.log.Error("failed to remove endpoint state",
+					zap.String("namespace", ipConfig.PodInfo.Namespace()), zap.String("pod", ipConfig.PodInfo.Name()), zap.Error(err))
+			}
+		}
+		gc.log.Info("released leaked IP", zap.String("ip", ipConfig.IPAddress),
+			zap.String("namespace", ipConfig.PodInfo.Namespace()), zap.String("pod", ipConfig.PodInfo.Name()), zap.Time("missingSince", since))
+		leakedIPsReleasedCount.Inc()
+		if gc.recorder != nil && gc.node != nil {
+			gc.recorder.Eventf(gc.node, corev1.EventTypeWarning, IPLeakReclaimedEventReason,
+				"Released IP %s of pod %s/%s which no longer exists", ipConfig.IPAddress, ipConfig.PodInfo.Namespace(), ipConfig.PodInfo.Name())
+		}
+	}
+	// IPs which were released or whose Pod reappeared are forgotten
+	gc.missingSince = missing
+	ipLeakGCRunCount.WithLabelValues("true").Inc()
+	return nil
+}
+
+// releaseLeakedIPConfig releases the IP if it is still Assigned to the same Pod, and reports whether it was released.
+// This guards against releasing an IP which was released and reassigned since it was found to be leaked.
+func (service *HTTPRestService) releaseLeakedIPConfig(leaked cns.IPConfigurationStatus) (bool, error) { //nolint:gocritic // ignore hugeparam
+	service.Lock()
+	defer service.Unlock()
+
+	ipConfig, ok := service.PodIPConfigState[leaked.ID]
+	if !ok || ipConfig.GetState() != types.Assigned || ipConfig.PodInfo == nil || ipConfig.PodInfo.Key() != leaked.PodInfo.Key() {
+		return false, nil
+	}
+	if _, err := service.unassignIPConfig(ipConfig, ipConfig.PodInfo); err != nil {
+		return false, errors.Wrapf(err, "failed to mark IPConfig %s as Available", ipConfig.ID)
+	}
+	return true, nil
+}
@@ -0,0 +1,92 @@
+package restserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-container-networking/cns"
+	"github.com/Azure/azure-container-networking/cns/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestIPLeakGC(t *testing.T) {
+	svc := getTestService()
+	state1, _ := NewPodStateWithOrchestratorContext(testIP1, testPod1GUID, testNCID, types.Assigned, 24, 0, testPod1Info)
+	state2, _ := NewPodStateWithOrchestratorContext(testIP2, testPod2GUID, testNCID, types.Assigned, 24, 0, testPod2Info)
+	state3, _ := NewPodStateWithOrchestratorContext(testIP3, testPod3GUID, testNCID, types.Assigned, 24, 0, testPod3Info)
+	require.NoError(t, UpdatePodIpConfigState(t, svc, map[string]cns.IPConfigurationStatus{
+		state1.ID: state1,
+		state2.ID: state2,
+		state3.ID: state3,
+	}))
+
+	// pod1 is live, pod2 was deleted, and pod3 isn't listed until after the grace period
+	livePods := map[string]struct{}{
+		testPod1Info.Namespace() + "/" + testPod1Info.Name(): {},
+	}
+	var listErr error
+	pods := LivePodsProviderFunc(func(context.Context) (map[string]struct{}, error) {
+		return livePods, listErr
+	})
+	recorder := record.NewFakeRecorder(10)
+	gracePeriod := time.Minute
+	gc := NewIPLeakGC(svc, pods, recorder, &corev1.Node{}, time.Second, gracePeriod)
+	ctx := context.Background()
+	start := time.Now()
+
+	// nothing is released within the grace period
+	require.NoError(t, gc.collect(ctx, start))
+	assert.Len(t, svc.GetAssignedIPConfigs(), 3)
+
+	livePods[testPod3Info.Namespace()+"/"+testPod3Info.Name()] = struct{}{}
+	require.NoError(t, gc.collect(ctx, start.Add(gracePeriod/2)))
+	assert.Len(t, svc.GetAssignedIPConfigs(), 3)
+
+	// a failure to list pods doesn't release anything
+	listErr = errors.New("apiserver unavailable")
+	require.Error(t, gc.collect(ctx, start.Add(gracePeriod)))
+	assert.Len(t, svc.GetAssignedIPConfigs(), 3)
+
+	// pod2 has been missing for the grace period
+	listErr = nil
+	require.NoError(t, gc.collect(ctx, start.Add(gracePeriod)))
+	assigned := svc.GetAssignedIPConfigs()
+	require.Len(t, assigned, 2)
+	for i := range assigned {
+		assert.NotEqual(t, testIP2, assigned[i].IPAddress)
+	}
+	assert.Equal(t, types.Available, ipState(svc, state2.ID))
+	assert.NotContains(t, svc.PodIPIDByPodInterfaceKey, testPod2Info.Key())
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, IPLeakReclaimedEventReason)
+	assert.Empty(t, gc.missingSince)
+}
+
+func TestReleaseLeakedIPConfigReassigned(t *testing.T) {
+	svc := getTestService()
+	state1, _ := NewPodStateWithOrchestratorContext(testIP1, testPod1GUID, testNCID, types.Assigned, 24, 0, testPod1Info)
+	require.NoError(t, UpdatePodIpConfigState(t, svc, map[string]cns.IPConfigurationStatus{state1.ID: state1}))
+
+	// the IP was reassigned to another pod since it was found to be leaked
+	leaked := state1
+	leaked.PodInfo = testPod2Info
+	released, err := svc.releaseLeakedIPConfig(leaked)
+	require.NoError(t, err)
+	assert.False(t, released)
+	assert.Equal(t, types.Assigned, ipState(svc, state1.ID))
+
+	released, err = svc.releaseLeakedIPConfig(state1)
+	require.NoError(t, err)
+	assert.True(t, released)
+	assert.Equal(t, types.Available, ipState(svc, state1.ID))
+}
+
+func ipState(svc *HTTPRestService, ipID string) types.IPState {
+	ipConfig := svc.PodIPConfigState[ipID]
+	return ipConfig.GetState()
+}
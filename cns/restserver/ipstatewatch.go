@@ -0,0 +1,193 @@
+package restserver
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-container-networking/cns"
+	"github.com/Azure/azure-container-networking/cns/types"
+	"go.uber.org/zap"
+)
+
+const (
+	// ipStateEventLogSize is how many IP state events are kept for watches to resume from.
+	ipStateEventLogSize = 1024
+	// ipStateWatchKeepAliveInterval is how often a comment is sent on idle watches, so that they aren't timed out.
+	ipStateWatchKeepAliveInterval = 30 * time.Second
+	// resourceVersionParam is the query parameter of the resource version to watch from.
+	resourceVersionParam = "resourceVersion"
+	// lastEventIDHeader is sent by server-sent events clients when they reconnect.
+	lastEventIDHeader = "Last-Event-ID"
+)
+
+// Resource versions are scoped to the CNS process: the high bits are an epoch chosen at random when CNS starts, and
+// the low bits count the events of the process. A resource version from before CNS restarted has another epoch,
+// so it is never mistaken for one of the current events.
+const (
+	resourceVersionCounterBits = 40
+	resourceVersionCounterMask = 1<<resourceVersionCounterBits - 1
+	resourceVersionEpochMask   = 1<<(64-resourceVersionCounterBits) - 1
+)
+
+// newResourceVersionEpoch returns a random epoch for the resource versions of the process.
+func newResourceVersionEpoch() uint64 {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// the start time of the process also differs across restarts
+		return uint64(time.Now().UnixNano()) & resourceVersionEpochMask
+	}
+	return binary.LittleEndian.Uint64(b[:]) & resourceVersionEpochMask
+}
+
+// ipStateEventLog keeps the latest IP state events, in ResourceVersion order, in a ring buffer.
+type ipStateEventLog struct {
+	sync.Mutex
+	epoch uint64
+	// events holds the event with the counter c of its resource version at c % ipStateEventLogSize
+	events [ipStateEventLogSize]cns.IPStateEvent
+	// kept is the number of events in the ring buffer
+	kept int
+	// counter is the counter of the resource version of the last event
+	counter uint64
+}
+
+func (l *ipStateEventLog) resourceVersion(counter uint64) uint64 {
+	return l.epoch<<resourceVersionCounterBits | counter&resourceVersionCounterMask
+}
+
+func (l *ipStateEventLog) record(ipconfig *cns.IPConfigurationStatus, state types.IPState, deleted bool) {
+	l.Lock()
+	defer l.Unlock()
+	l.counter++
+	event := cns.IPStateEvent{
+		ResourceVersion: l.resourceVersion(l.counter),
+		Time:            time.Now(),
+		ID:              ipconfig.ID,
+		IPAddress:       ipconfig.IPAddress,
+		NCID:            ipconfig.NCID,
+		PreviousState:   ipconfig.GetState(),
+		State:           state,
+		Deleted:         deleted,
+	}
+	if ipconfig.PodInfo != nil {
+		event.PodName = ipconfig.PodInfo.Name()
+		event.PodNamespace = ipconfig.PodInfo.Namespace()
+	}
+	l.events[l.counter%ipStateEventLogSize] = event
+	if l.kept < ipStateEventLogSize {
+		l.kept++
+	}
+}
+
+// since returns the events after the resource version, and false if some of them are no longer kept,
+// or if the resource version is from another epoch, such as from before CNS restarted.
+func (l *ipStateEventLog) since(resourceVersion uint64) ([]cns.IPStateEvent, bool) {
+	l.Lock()
+	defer l.Unlock()
+	if resourceVersion>>resourceVersionCounterBits != l.epoch {
+		return nil, false
+	}
+	counter := resourceVersion & resourceVersionCounterMask
+	if counter > l.counter {
+		return nil, false
+	}
+	oldest := l.counter - uint64(l.kept) + 1
+	if counter+1 < oldest {
+		return nil, false
+	}
+	events := make([]cns.IPStateEvent, 0, l.counter-counter)
+	for c := counter + 1; c <= l.counter; c++ {
+		events = append(events, l.events[c%ipStateEventLogSize])
+	}
+	return events, true
+}
+
+func (l *ipStateEventLog) currentResourceVersion() uint64 {
+	l.Lock()
+	defer l.Unlock()
+	return l.resourceVersion(l.counter)
+}
+
+// watchIPAddressesHandler streams the IP state events after the resourceVersion query parameter (or the Last-Event-ID
+// header of a reconnecting client) as server-sent events, with the ResourceVersion as the event id.
+// Without a resource version the events after the current one are streamed.
+// If the events after the resource version are no longer kept, or it is from before CNS restarted, it responds with 410 Gone
+// and the client should list the IPs again, which returns the ResourceVersion to watch from.
+func (service *HTTPRestService) watchIPAddressesHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	rawResourceVersion := r.URL.Query().Get(resourceVersionParam)
+	if lastEventID := r.Header.Get(lastEventIDHeader); lastEventID != "" {
+		rawResourceVersion = lastEventID
+	}
+	resourceVersion := service.ipStateEvents.currentResourceVersion()
+	if rawResourceVersion != "" {
+		var err error
+		if resourceVersion, err = strconv.ParseUint(rawResourceVersion, 10, 64); err != nil {
+			http.Error(w, fmt.Sprintf("invalid resource version %q", rawResourceVersion), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// subscribe before reading the events, so that none are missed
+	changes, unsubscribe := service.SubscribeIPStateChanges()
+	defer unsubscribe()
+	events, ok := service.ipStateEvents.since(resourceVersion)
+	if !ok {
+		http.Error(w, fmt.Sprintf("resource version %d is no longer available", resourceVersion), http.StatusGone)
+		return
+	}
+	log := service.log.With(zap.String("remoteAddr", r.RemoteAddr))
+	log.Info("watching IP state events", zap.Uint64("resourceVersion", resourceVersion))
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	// an event with only an id sets the resource version which the client resumes from
+	if _, err := fmt.Fprintf(w, "id: %d\n\n", resourceVersion); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(ipStateWatchKeepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		for i := range events {
+			b, err := json.Marshal(events[i])
+			if err != nil {
+				log.Error("failed to marshal IP state event", zap.Error(err))
+				return
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\ndata: %s\n\n", events[i].ResourceVersion, b); err != nil {
+				return
+			}
+			resourceVersion = events[i].ResourceVersion
+		}
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			events = nil
+		case <-changes:
+			if events, ok = service.ipStateEvents.since(resourceVersion); !ok {
+				// the watcher fell too far behind, and must resume from a new list
+				return
+			}
+		}
+	}
+}
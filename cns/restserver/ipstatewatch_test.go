@@ -0,0 +1,79 @@
+package restserver
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-container-networking/cns"
+	"github.com/Azure/azure-container-networking/cns/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIPStateEventLog(t *testing.T) {
+	l := ipStateEventLog{epoch: newResourceVersionEpoch()}
+	start := l.currentResourceVersion()
+	ipconfig := cns.IPConfigurationStatus{ID: "id", IPAddress: testIP1, NCID: testNCID, PodInfo: testPod1Info}
+	ipconfig.SetState(types.Available)
+	for i := 0; i < ipStateEventLogSize+10; i++ {
+		l.record(&ipconfig, types.Assigned, false)
+	}
+	require.Equal(t, start+ipStateEventLogSize+10, l.currentResourceVersion())
+
+	events, ok := l.since(l.currentResourceVersion())
+	assert.True(t, ok)
+	assert.Empty(t, events)
+
+	events, ok = l.since(l.currentResourceVersion() - 2)
+	require.True(t, ok)
+	require.Len(t, events, 2)
+	assert.Equal(t, l.currentResourceVersion()-1, events[0].ResourceVersion)
+	assert.Equal(t, l.currentResourceVersion(), events[1].ResourceVersion)
+	assert.Equal(t, types.Available, events[1].PreviousState)
+	assert.Equal(t, types.Assigned, events[1].State)
+	assert.Equal(t, testPod1Info.Name(), events[1].PodName)
+	assert.Equal(t, testPod1Info.Namespace(), events[1].PodNamespace)
+
+	// the oldest kept event is after the resource version 10
+	events, ok = l.since(start + 10)
+	require.True(t, ok)
+	require.Len(t, events, ipStateEventLogSize)
+	assert.Equal(t, start+11, events[0].ResourceVersion)
+	assert.Equal(t, l.currentResourceVersion(), events[ipStateEventLogSize-1].ResourceVersion)
+	_, ok = l.since(start + 9)
+	assert.False(t, ok)
+
+	// a resource version which was not produced yet
+	_, ok = l.since(l.currentResourceVersion() + 1)
+	assert.False(t, ok)
+
+	// a resource version from before a restart, which is within the kept events of the new process
+	restarted := ipStateEventLog{epoch: l.epoch + 1}
+	for i := 0; i < 20; i++ {
+		restarted.record(&ipconfig, types.Assigned, false)
+	}
+	_, ok = restarted.since(l.resourceVersion(10))
+	assert.False(t, ok)
+	_, ok = restarted.since(restarted.resourceVersion(10))
+	assert.True(t, ok)
+}
+
+func TestIPStateEvents(t *testing.T) {
+	svc := getTestService()
+	state1, _ := NewPodStateWithOrchestratorContext(testIP1, testPod1GUID, testNCID, types.Available, 24, 0, nil)
+	require.NoError(t, UpdatePodIpConfigState(t, svc, map[string]cns.IPConfigurationStatus{state1.ID: state1}))
+	resourceVersion := svc.getIPAddresses(cns.GetIPAddressesRequest{}).ResourceVersion
+
+	_, err := svc.AssignDesiredIPConfig(testPod1Info, testIP1)
+	require.NoError(t, err)
+	require.NoError(t, svc.releaseIPConfig(testPod1Info))
+
+	events, ok := svc.ipStateEvents.since(resourceVersion)
+	require.True(t, ok)
+	require.Len(t, events, 2)
+	assert.Equal(t, types.Available, events[0].PreviousState)
+	assert.Equal(t, types.Assigned, events[0].State)
+	assert.Equal(t, testPod1Info.Name(), events[0].PodName)
+	assert.Equal(t, types.Assigned, events[1].PreviousState)
+	assert.Equal(t, types.Available, events[1].State)
+	assert.Equal(t, testPod1Info.Name(), events[1].PodName)
+}
@@ -57,6 +57,21 @@ var syncHostNCVersionLatency = prometheus.NewHistogramVec(
 	[]string{"ok"},
 )
 
+var ipLeakGCRunCount = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "ip_leak_gc_runs_total",
+		Help: "Count of IP leak garbage collection runs by success or failure",
+	},
+	[]string{"ok"},
+)
+
+var leakedIPsReleasedCount = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "leaked_ips_released_total",
+		Help: "Count of Assigned IPs released because their Pod no longer exists",
+	},
+)
+
 func init() {
 	metrics.Registry.MustRegister(
 		httpRequestLatency,
@@ -64,6 +79,8 @@ func init() {
 		ipConfigStatusStateTransitionTime,
 		syncHostNCVersionCount,
 		syncHostNCVersionLatency,
+		ipLeakGCRunCount,
+		leakedIPsReleasedCount,
 	)
 }
 
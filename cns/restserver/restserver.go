@@ -3,7 +3,9 @@ package restserver
 import (
 	"context"
 	"net"
+	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Azure/azure-container-networking/cns"
@@ -20,10 +22,12 @@ import (
 	nma "github.com/Azure/azure-container-networking/nmagent"
 	"github.com/Azure/azure-container-networking/store"
 	"github.com/pkg/errors"
+	"go.uber.org/zap"
 )
 
 // This file contains the initialization of RestServer.
 // all HTTP APIs - api.go and/or ipam.go
+// gRPC APIs - grpc.go
 // APIs for internal consumption - internalapi.go
 // All helper/utility functions - util.go
 // Constants - const.go
@@ -31,12 +35,22 @@ import (
 var (
 	// Named Lock for accessing different states in httpRestServiceState
 	namedLock = acn.InitNamedLock()
+
+	// restLog and nmaLog log the messages of the REST server and of its calls to NMAgent at the levels of their subsystems
+	restLog = logger.Subsystem(logger.RestServer)
+	nmaLog  = logger.Subsystem(logger.NMAgent)
 )
 
 type interfaceGetter interface {
 	GetInterfaces(ctx context.Context) (*wireserver.GetInterfacesResult, error)
 }
 
+// hostMetadataProvider is the part of a hostmetadata.Provider which the service uses, besides the HomeAzMonitor.
+type hostMetadataProvider interface {
+	interfaceGetter
+	SupportedAPIs(context.Context) ([]string, error)
+}
+
 type nmagentClient interface {
 	PutNetworkContainer(context.Context, *nma.PutNetworkContainerRequest) error
 	DeleteNetworkContainer(context.Context, nma.DeleteContainerRequest) error
@@ -49,8 +63,9 @@ type nmagentClient interface {
 // HTTPRestService represents http listener for CNS - Container Networking Service.
 type HTTPRestService struct {
 	*cns.Service
+	log                      *zap.Logger
 	dockerClient             *dockerclient.Client
-	wscli                    interfaceGetter
+	hostMetadata             hostMetadataProvider
 	ipamClient               *ipamclient.IpamClient
 	nma                      nmagentClient
 	homeAzMonitor            *HomeAzMonitor
@@ -68,6 +83,16 @@ type HTTPRestService struct {
 	EndpointStateStore      store.KeyValueStore
 	cniConflistGenerator    CNIConflistGenerator
 	generateCNIConflistOnce sync.Once
+	cniConflistGenerated    atomic.Bool
+	// saveStateErr is the error of the last write of the state to the store, nil if it succeeded
+	saveStateErr atomic.Pointer[error]
+	// ipStateNotifier is notified of IP state changes, if set
+	ipStateNotifier chan<- struct{}
+	// ipStateSubscribers are the watchers of IP state changes, such as gRPC watches
+	ipStateSubscribersMu sync.Mutex
+	ipStateSubscribers   map[chan struct{}]struct{}
+	// ipStateEvents are the latest IP state transitions, which are streamed to watches
+	ipStateEvents ipStateEventLog
 }
 
 type CNIConflistGenerator interface {
@@ -144,7 +169,7 @@ type networkInfo struct {
 }
 
 // NewHTTPRestService creates a new HTTP Service object.
-func NewHTTPRestService(config *common.ServiceConfig, wscli interfaceGetter, nmagentClient nmagentClient,
+func NewHTTPRestService(config *common.ServiceConfig, hostMetadata hostMetadataProvider, nmagentClient nmagentClient,
 	endpointStateStore store.KeyValueStore, gen CNIConflistGenerator, homeAzMonitor *HomeAzMonitor,
 ) (cns.HTTPService, error) {
 	service, err := cns.NewService(config.Name, config.Version, config.ChannelMode, config.Store)
@@ -154,7 +179,7 @@ func NewHTTPRestService(config *common.ServiceConfig, wscli interfaceGetter, nma
 
 	routingTable := &routes.RoutingTable{}
 	nc := &networkcontainers.NetworkContainers{}
-	dc, err := dockerclient.NewDefaultClient(wscli)
+	dc, err := dockerclient.NewDefaultClient(hostMetadata)
 	if err != nil {
 		return nil, err
 	}
@@ -164,13 +189,13 @@ func NewHTTPRestService(config *common.ServiceConfig, wscli interfaceGetter, nma
 		return nil, err
 	}
 
-	res, err := wscli.GetInterfaces(context.TODO()) // TODO(rbtr): thread context through this client
+	res, err := hostMetadata.GetInterfaces(context.TODO()) // TODO(rbtr): thread context through this client
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get interfaces from IMDS")
+		return nil, errors.Wrap(err, "failed to get interfaces from host metadata")
 	}
 	primaryInterface, err := wireserver.GetPrimaryInterfaceFromResult(res)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get primary interface from IMDS response")
+		return nil, errors.Wrap(err, "failed to get primary interface from host metadata")
 	}
 
 	serviceState := &httpRestServiceState{
@@ -188,9 +213,10 @@ func NewHTTPRestService(config *common.ServiceConfig, wscli interfaceGetter, nma
 
 	return &HTTPRestService{
 		Service:                  service,
+		log:                      logger.Named(logger.RestServer),
 		store:                    service.Service.Store,
 		dockerClient:             dc,
-		wscli:                    wscli,
+		hostMetadata:             hostMetadata,
 		ipamClient:               ic,
 		nma:                      nmagentClient,
 		networkContainer:         nc,
@@ -203,6 +229,7 @@ func NewHTTPRestService(config *common.ServiceConfig, wscli interfaceGetter, nma
 		EndpointState:            make(map[string]*EndpointInfo),
 		homeAzMonitor:            homeAzMonitor,
 		cniConflistGenerator:     gen,
+		ipStateEvents:            ipStateEventLog{epoch: newResourceVersionEpoch()},
 	}, nil
 }
 
@@ -210,19 +237,20 @@ func NewHTTPRestService(config *common.ServiceConfig, wscli interfaceGetter, nma
 func (service *HTTPRestService) Init(config *common.ServiceConfig) error {
 	err := service.Initialize(config)
 	if err != nil {
-		logger.Errorf("[Azure CNS]  Failed to initialize base service, err:%v.", err)
+		restLog.Errorf("[Azure CNS]  Failed to initialize base service, err:%v.", err)
 		return err
 	}
 
 	service.restoreState()
 	err = service.restoreNetworkState()
 	if err != nil {
-		logger.Errorf("[Azure CNS]  Failed to restore network state, err:%v.", err)
+		restLog.Errorf("[Azure CNS]  Failed to restore network state, err:%v.", err)
 		return err
 	}
 
 	// Add handlers.
 	listener := service.Listener
+	listener.Use(service.traceRequests)
 	// default handlers
 	listener.AddHandler(cns.SetEnvironmentPath, service.setEnvironment)
 	listener.AddHandler(cns.CreateNetworkPath, service.createNetwork)
@@ -252,6 +280,10 @@ func (service *HTTPRestService) Init(config *common.ServiceConfig) error {
 	listener.AddHandler(cns.PathDebugIPAddresses, service.handleDebugIPAddresses)
 	listener.AddHandler(cns.PathDebugPodContext, service.handleDebugPodContext)
 	listener.AddHandler(cns.PathDebugRestData, service.handleDebugRestData)
+	listener.AddHandler(cns.WatchIPAddresses, service.watchIPAddressesHandler)
+	logLevels := http.StripPrefix(cns.PathDebugLogLevels, logger.LevelsHandler()).ServeHTTP
+	listener.AddHandler(cns.PathDebugLogLevels, logLevels)
+	listener.AddHandler(cns.PathDebugLogLevels+"/", logLevels)
 	listener.AddHandler(cns.NetworkContainersURLPath, service.getOrRefreshNetworkContainers)
 	listener.AddHandler(cns.GetHomeAz, service.getHomeAz)
 
@@ -285,7 +317,7 @@ func (service *HTTPRestService) Init(config *common.ServiceConfig) error {
 	acn.InitHttpClient(connectionTimeout, responseHeaderTimeout)
 
 	logger.SetContextDetails(service.state.OrchestratorType, service.state.NodeID)
-	logger.Printf("[Azure CNS]  Listening.")
+	restLog.Printf("[Azure CNS]  Listening.")
 
 	return nil
 }
@@ -299,13 +331,19 @@ func (service *HTTPRestService) Start(config *common.ServiceConfig) error {
 		return err
 	}
 
+	if config.GRPCURL != "" {
+		if err := service.StartGRPCListener(config, service.registerGRPCServer); err != nil {
+			return errors.Wrap(err, "failed to start grpc listener")
+		}
+	}
+
 	return nil
 }
 
 // Stop stops the CNS.
 func (service *HTTPRestService) Stop() {
 	service.Uninitialize()
-	logger.Printf("[Azure CNS]  Service stopped.")
+	restLog.Printf("[Azure CNS]  Service stopped.")
 }
 
 // MustGenerateCNIConflistOnce will generate the CNI conflist once if the service was initialized with
@@ -319,5 +357,6 @@ func (service *HTTPRestService) MustGenerateCNIConflistOnce() {
 		if err := service.cniConflistGenerator.Close(); err != nil {
 			panic("unable to close the cni conflist output stream: " + err.Error())
 		}
+		service.cniConflistGenerated.Store(true)
 	})
 }
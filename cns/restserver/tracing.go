@@ -0,0 +1,22 @@
+package restserver
+
+import (
+	"net/http"
+
+	"github.com/Azure/azure-container-networking/otlp"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// traceRequests serves each request to CNS in a span, which continues the trace of the caller, such as the CNI ADD
+// requesting an IP, and is the parent of the requests to NMAgent made while serving it.
+// The trace ID of the requests of traced callers is logged, so that the CNS logs can be correlated with theirs.
+func (service *HTTPRestService) traceRequests(next http.Handler) http.Handler {
+	return otlp.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(otlp.TraceparentHeader) != "" {
+			service.log.Info("serving traced request", zap.String("method", r.Method), zap.String("path", r.URL.Path),
+				zap.Stringer("traceID", trace.SpanFromContext(r.Context()).SpanContext().TraceID()))
+		}
+		next.ServeHTTP(w, r)
+	}))
+}
@@ -49,11 +49,11 @@ func (service *HTTPRestService) removeNetworkInfo(networkName string) {
 
 // saveState writes CNS state to persistent store.
 func (service *HTTPRestService) saveState() error {
-	logger.Printf("[Azure CNS] saveState")
+	restLog.Printf("[Azure CNS] saveState")
 
 	// Skip if a store is not provided.
 	if service.store == nil {
-		logger.Printf("[Azure CNS]  store not initialized.")
+		restLog.Printf("[Azure CNS]  store not initialized.")
 		return nil
 	}
 
@@ -61,9 +61,11 @@ func (service *HTTPRestService) saveState() error {
 	service.state.TimeStamp = time.Now()
 	err := service.store.Write(storeKey, &service.state)
 	if err == nil {
-		logger.Printf("[Azure CNS]  State saved successfully.\n")
+		restLog.Printf("[Azure CNS]  State saved successfully.\n")
+		service.saveStateErr.Store(nil)
 	} else {
-		logger.Errorf("[Azure CNS]  Failed to save state., err:%v\n", err)
+		restLog.Errorf("[Azure CNS]  Failed to save state., err:%v\n", err)
+		service.saveStateErr.Store(&err)
 	}
 
 	return err
@@ -71,11 +73,11 @@ func (service *HTTPRestService) saveState() error {
 
 // restoreState restores CNS state from persistent store.
 func (service *HTTPRestService) restoreState() {
-	logger.Printf("[Azure CNS] restoreState")
+	restLog.Printf("[Azure CNS] restoreState")
 
 	// Skip if a store is not provided.
 	if service.store == nil {
-		logger.Printf("[Azure CNS]  store not initialized.")
+		restLog.Printf("[Azure CNS]  store not initialized.")
 		return
 	}
 
@@ -84,29 +86,29 @@ func (service *HTTPRestService) restoreState() {
 	if err != nil {
 		if err == store.ErrKeyNotFound {
 			// Nothing to restore.
-			logger.Printf("[Azure CNS]  No state to restore.\n")
+			restLog.Printf("[Azure CNS]  No state to restore.\n")
 		} else {
-			logger.Errorf("[Azure CNS]  Failed to restore state, err:%v. Removing azure-cns.json", err)
+			restLog.Errorf("[Azure CNS]  Failed to restore state, err:%v. Removing azure-cns.json", err)
 			service.store.Remove()
 		}
 
 		return
 	}
 
-	logger.Printf("[Azure CNS]  Restored state, %+v\n", service.state)
+	restLog.Printf("[Azure CNS]  Restored state, %+v\n", service.state)
 
 	if service.Options[acn.OptManageEndpointState] == true {
 		err := service.EndpointStateStore.Read(EndpointStoreKey, &service.EndpointState)
 		if err != nil {
 			if errors.Is(err, store.ErrKeyNotFound) {
 				// Nothing to restore.
-				logger.Printf("[Azure CNS]  No endpoint state to restore.\n")
+				restLog.Printf("[Azure CNS]  No endpoint state to restore.\n")
 			} else {
-				logger.Errorf("[Azure CNS]  Failed to restore endpoint state, err:%v. Removing endpoints.json", err)
+				restLog.Errorf("[Azure CNS]  Failed to restore endpoint state, err:%v. Removing endpoints.json", err)
 			}
 			return
 		}
-		logger.Printf("[Azure CNS]  Restored endpoint state, %+v\n", service.EndpointState)
+		restLog.Printf("[Azure CNS]  Restored endpoint state, %+v\n", service.EndpointState)
 
 	}
 }
@@ -184,7 +186,7 @@ func (service *HTTPRestService) saveNetworkContainerGoalState(
 				return types.UnexpectedError, errBuf
 			}
 
-			logger.Printf("Pod info %v", podInfo)
+			restLog.Printf("Pod info %v", podInfo)
 
 			if service.state.ContainerIDByOrchestratorContext == nil {
 				service.state.ContainerIDByOrchestratorContext = make(map[string]string)
@@ -200,13 +202,13 @@ func (service *HTTPRestService) saveNetworkContainerGoalState(
 			}
 		default:
 			errMsg := fmt.Sprintf("Unsupported orchestrator type: %s", service.state.OrchestratorType)
-			logger.Errorf(errMsg)
+			restLog.Errorf(errMsg)
 			return types.UnsupportedOrchestratorType, errMsg
 		}
 
 	default:
 		errMsg := fmt.Sprintf("Unsupported network container type %s", req.NetworkContainerType)
-		logger.Errorf(errMsg)
+		restLog.Errorf(errMsg)
 		return types.UnsupportedNetworkContainerType, errMsg
 	}
 
@@ -282,12 +284,12 @@ func (service *HTTPRestService) addIPConfigStateUntransacted(ncID string, hostVe
 		}
 
 		if ipState, exists := service.PodIPConfigState[ipID]; exists {
-			logger.Printf("[Azure-Cns] Set ipId %s, IP %s version to %d, programmed host nc version is %d, "+
+			restLog.Printf("[Azure-Cns] Set ipId %s, IP %s version to %d, programmed host nc version is %d, "+
 				"ipState: %+v", ipID, ipconfig.IPAddress, ipconfig.NCVersion, hostVersion, ipState)
 			continue
 		}
 
-		logger.Printf("[Azure-Cns] Set ipId %s, IP %s version to %d, programmed host nc version is %d",
+		restLog.Printf("[Azure-Cns] Set ipId %s, IP %s version to %d, programmed host nc version is %d",
 			ipID, ipconfig.IPAddress, ipconfig.NCVersion, hostVersion)
 		// Using the updated NC version attached with IP to compare with latest nmagent version and determine IP statues.
 		// When reconcile, service.PodIPConfigState doens't exist, rebuild it with the help of NC version attached with IP.
@@ -304,9 +306,9 @@ func (service *HTTPRestService) addIPConfigStateUntransacted(ncID string, hostVe
 			IPAddress: ipconfig.IPAddress,
 			PodInfo:   nil,
 		}
-		ipconfigStatus.WithStateMiddleware(stateTransitionMiddleware)
+		ipconfigStatus.WithStateMiddleware(stateTransitionMiddleware, service.ipStateChangeMiddleware)
 		ipconfigStatus.SetState(newIPCNSStatus)
-		logger.Printf("[Azure-Cns] Add IP %s as %s", ipconfig.IPAddress, newIPCNSStatus)
+		restLog.Printf("[Azure-Cns] Add IP %s as %s", ipconfig.IPAddress, newIPCNSStatus)
 
 		service.PodIPConfigState[ipID] = ipconfigStatus
 
@@ -343,10 +345,14 @@ func (service *HTTPRestService) removeToBeDeletedIPStateUntransacted(
 	}
 
 	// Delete this ip from PODIpConfigState Map
-	logger.Printf("[Azure-Cns] Delete the PodIpConfigState, IpId: %s, IPConfigStatus: %v",
+	restLog.Printf("[Azure-Cns] Delete the PodIpConfigState, IpId: %s, IPConfigStatus: %v",
 		ipID,
 		service.PodIPConfigState[ipID])
+	if ipConfigStatus, exists := service.PodIPConfigState[ipID]; exists {
+		service.ipStateEvents.record(&ipConfigStatus, ipConfigStatus.GetState(), true)
+	}
 	delete(service.PodIPConfigState, ipID)
+	service.notifyIPStateChange()
 	return 0, ""
 }
 
@@ -380,7 +386,7 @@ func (service *HTTPRestService) getNetworkContainerResponse(
 			return getNetworkContainerResponse
 		}
 
-		logger.Printf("pod info %+v", podInfo)
+		restLog.Printf("pod info %+v", podInfo)
 
 		containerID, exists = service.state.ContainerIDByOrchestratorContext[podInfo.Name()+podInfo.Namespace()]
 
@@ -390,7 +396,7 @@ func (service *HTTPRestService) getNetworkContainerResponse(
 		ncVersionListResp, err := service.nma.GetNCVersionList(ctx)
 		if err != nil {
 			skipNCVersionCheck = true
-			logger.Errorf("failed to get nc version list from nmagent")
+			nmaLog.Errorf("failed to get nc version list from nmagent")
 		}
 		nmaNCs := map[string]string{}
 		for _, nc := range ncVersionListResp.Containers {
@@ -403,7 +409,7 @@ func (service *HTTPRestService) getNetworkContainerResponse(
 				service.state.ContainerStatus[containerID].CreateNetworkContainerRequest.Version, containerID, nmaNCs)
 			// If the return code is not success, return the error to the caller
 			if getNetworkContainerResponse.Response.ReturnCode == types.NetworkContainerVfpProgramPending {
-				logger.Errorf("[Azure-CNS] isNCWaitingForUpdate failed for NC: %s with error: %s",
+				restLog.Errorf("[Azure-CNS] isNCWaitingForUpdate failed for NC: %s with error: %s",
 					containerID, getNetworkContainerResponse.Response.Message)
 				return getNetworkContainerResponse
 			}
@@ -416,7 +422,7 @@ func (service *HTTPRestService) getNetworkContainerResponse(
 			if (getNetworkContainerResponse.Response.ReturnCode == types.NetworkContainerVfpProgramComplete &&
 				vfpUpdateComplete && ncstatus.VfpUpdateComplete != vfpUpdateComplete) ||
 				(!vfpUpdateComplete && ncstatus.VfpUpdateComplete != vfpUpdateComplete) {
-				logger.Printf("[Azure-CNS] Setting VfpUpdateComplete to %t for NC: %s", vfpUpdateComplete, containerID)
+				restLog.Printf("[Azure-CNS] Setting VfpUpdateComplete to %t for NC: %s", vfpUpdateComplete, containerID)
 				ncstatus.VfpUpdateComplete = vfpUpdateComplete
 				service.state.ContainerStatus[containerID] = ncstatus
 				service.saveState()
@@ -440,7 +446,7 @@ func (service *HTTPRestService) getNetworkContainerResponse(
 			containerID = service.state.ContainerIDByOrchestratorContext[podInfo.Name()+podInfo.Namespace()]
 		}
 
-		logger.Printf("containerid %v", containerID)
+		restLog.Printf("containerid %v", containerID)
 
 	default:
 		getNetworkContainerResponse.Response.ReturnCode = types.UnsupportedOrchestratorType
@@ -474,15 +480,15 @@ func (service *HTTPRestService) getNetworkContainerResponse(
 
 // restoreNetworkState restores Network state that existed before reboot.
 func (service *HTTPRestService) restoreNetworkState() error {
-	logger.Printf("[Azure CNS] Enter Restoring Network State")
+	restLog.Printf("[Azure CNS] Enter Restoring Network State")
 
 	if service.store == nil {
-		logger.Printf("[Azure CNS] Store is not initialized, nothing to restore for network state.")
+		restLog.Printf("[Azure CNS] Store is not initialized, nothing to restore for network state.")
 		return nil
 	}
 
 	if !service.store.Exists() {
-		logger.Printf("[Azure CNS] Store does not exist, nothing to restore for network state.")
+		restLog.Printf("[Azure CNS] Store does not exist, nothing to restore for network state.")
 		return nil
 	}
 
@@ -490,11 +496,11 @@ func (service *HTTPRestService) restoreNetworkState() error {
 	modTime, err := service.store.GetModificationTime()
 
 	if err == nil {
-		logger.Printf("[Azure CNS] Store timestamp is %v.", modTime)
+		restLog.Printf("[Azure CNS] Store timestamp is %v.", modTime)
 
 		rebootTime, err := platform.GetLastRebootTime()
 		if err == nil && rebootTime.After(modTime) {
-			logger.Printf("[Azure CNS] reboot time %v mod time %v", rebootTime, modTime)
+			restLog.Printf("[Azure CNS] reboot time %v mod time %v", rebootTime, modTime)
 			rebooted = true
 		}
 	}
@@ -503,7 +509,7 @@ func (service *HTTPRestService) restoreNetworkState() error {
 		for _, nwInfo := range service.state.Networks {
 			enableSnat := true
 
-			logger.Printf("[Azure CNS] Restore nwinfo %v", nwInfo)
+			restLog.Printf("[Azure CNS] Restore nwinfo %v", nwInfo)
 
 			if nwInfo.Options != nil {
 				if _, ok := nwInfo.Options[dockerclient.OptDisableSnat]; ok {
@@ -514,7 +520,7 @@ func (service *HTTPRestService) restoreNetworkState() error {
 			if enableSnat {
 				err := platform.SetOutboundSNAT(nwInfo.NicInfo.Subnet)
 				if err != nil {
-					logger.Printf("[Azure CNS] Error setting up SNAT outbound rule %v", err)
+					restLog.Printf("[Azure CNS] Error setting up SNAT outbound rule %v", err)
 					return err
 				}
 			}
@@ -552,7 +558,7 @@ func (service *HTTPRestService) attachOrDetachHelper(req cns.ConfigureContainerN
 				defer cancel()
 				ncVersionListResp, err := service.nma.GetNCVersionList(ctx)
 				if err != nil {
-					logger.Errorf("failed to get nc version list from nmagent")
+					nmaLog.Errorf("failed to get nc version list from nmagent")
 					return cns.Response{
 						ReturnCode: types.NmAgentInternalServerError,
 						Message:    err.Error(),
@@ -682,7 +688,7 @@ func (service *HTTPRestService) joinNetwork(ctx context.Context, networkID strin
 
 	// Network joined successfully
 	service.setNetworkStateJoined(networkID)
-	logger.Printf("[Azure-CNS] setNetworkStateJoined for network: %s", networkID)
+	nmaLog.Printf("[Azure-CNS] setNetworkStateJoined for network: %s", networkID)
 
 	return nil
 }
@@ -715,7 +721,7 @@ func (service *HTTPRestService) logNCSnapshots() {
 		logNCSnapshot(ncStatus.CreateNetworkContainerRequest)
 	}
 
-	logger.Printf("[Azure CNS] Logging periodic NC snapshots. NC Count %d", len(service.state.ContainerStatus))
+	restLog.Printf("[Azure CNS] Logging periodic NC snapshots. NC Count %d", len(service.state.ContainerStatus))
 }
 
 // Sets up periodic timer for sending network container snapshots
@@ -757,17 +763,17 @@ func (service *HTTPRestService) validateIPConfigRequest(
 }
 
 // getPrimaryHostInterface returns the cached InterfaceInfo, if available, otherwise
-// queries the IMDS to get the primary interface info and caches it in the server state
+// queries the host metadata provider to get the primary interface info and caches it in the server state
 // before returning the result.
 func (service *HTTPRestService) getPrimaryHostInterface(ctx context.Context) (*wireserver.InterfaceInfo, error) {
 	if service.state.primaryInterface == nil {
-		res, err := service.wscli.GetInterfaces(ctx)
+		res, err := service.hostMetadata.GetInterfaces(ctx)
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to get interfaces from IMDS")
+			return nil, errors.Wrap(err, "failed to get interfaces from host metadata")
 		}
 		primary, err := wireserver.GetPrimaryInterfaceFromResult(res)
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to get primary interface from IMDS response")
+			return nil, errors.Wrap(err, "failed to get primary interface from host metadata")
 		}
 		service.state.primaryInterface = primary
 	}
@@ -813,7 +819,7 @@ func (service *HTTPRestService) isNCWaitingForUpdate(
 	if ok {
 		if ncStatus.VfpUpdateComplete &&
 			(ncStatus.CreateNetworkContainerRequest.Version == ncVersion) {
-			logger.Printf("[Azure CNS] Network container: %s, version: %s has VFP programming already completed", ncid, ncVersion)
+			nmaLog.Printf("[Azure CNS] Network container: %s, version: %s has VFP programming already completed", ncid, ncVersion)
 			return false, types.NetworkContainerVfpProgramCheckSkipped, ""
 		}
 	}
@@ -821,14 +827,14 @@ func (service *HTTPRestService) isNCWaitingForUpdate(
 	ncTargetVersion, err := strconv.Atoi(ncVersion)
 	if err != nil {
 		// NMA doesn't have this NC version in string type, bail out
-		logger.Printf("[Azure CNS] NC %s version %v from NMAgent NC version list is not string "+
+		nmaLog.Printf("[Azure CNS] NC %s version %v from NMAgent NC version list is not string "+
 			"Skipping GetNCVersionStatus check from NMAgent", ncVersion, ncid)
 		return true, types.NetworkContainerVfpProgramPending, ""
 	}
 	nmaProgrammedNCVersionStr, ok := ncVersionList[ncid]
 	if !ok {
 		// NMA doesn't have this NC that we need programmed yet, bail out
-		logger.Printf("[Azure CNS] Failed to get NC %s doesn't exist in NMAgent NC version list "+
+		nmaLog.Printf("[Azure CNS] Failed to get NC %s doesn't exist in NMAgent NC version list "+
 			"Skipping GetNCVersionStatus check from NMAgent", ncid)
 		return true, types.NetworkContainerVfpProgramPending, ""
 	}
@@ -839,7 +845,7 @@ func (service *HTTPRestService) isNCWaitingForUpdate(
 		// values appear to be exclusively integers. Nevertheless, NMAgent is
 		// allowed to make this parameter anything (by contract), so we should
 		// defend against it by erroring appropriately:
-		logger.Printf("[Azure CNS] Failed to get NC version status from NMAgent with error: %+v. "+
+		nmaLog.Printf("[Azure CNS] Failed to get NC version status from NMAgent with error: %+v. "+
 			"Skipping GetNCVersionStatus check from NMAgent", err)
 		return true, types.NetworkContainerVfpProgramCheckSkipped, ""
 	}
@@ -851,13 +857,13 @@ func (service *HTTPRestService) isNCWaitingForUpdate(
 	}
 
 	msg := "Vfp programming complete"
-	logger.Printf("[Azure CNS] Vfp programming complete for NC: %s with version: %d", ncid, ncTargetVersion)
+	nmaLog.Printf("[Azure CNS] Vfp programming complete for NC: %s with version: %d", ncid, ncTargetVersion)
 	return false, types.NetworkContainerVfpProgramComplete, msg
 }
 
 // handleGetNetworkContainers returns all NCs in CNS
 func (service *HTTPRestService) handleGetNetworkContainers(w http.ResponseWriter) {
-	logger.Printf("[Azure CNS] handleGetNetworkContainers")
+	restLog.Printf("[Azure CNS] handleGetNetworkContainers")
 	service.RLock()
 	networkContainers := make([]cns.GetNetworkContainerResponse, len(service.state.ContainerStatus))
 	i := 0
@@ -886,15 +892,15 @@ func (service *HTTPRestService) handleGetNetworkContainers(w http.ResponseWriter
 		},
 	}
 	err := service.Listener.Encode(w, &response)
-	logger.Response(service.Name, response, response.Response.ReturnCode, err)
+	restLog.Response(service.Name, response, response.Response.ReturnCode, err)
 }
 
 // handlePostNetworkContainers stores all the NCs (from the request that client sent) into CNS's state file
 func (service *HTTPRestService) handlePostNetworkContainers(w http.ResponseWriter, r *http.Request) {
-	logger.Printf("[Azure CNS] handlePostNetworkContainers")
+	restLog.Printf("[Azure CNS] handlePostNetworkContainers")
 	var req cns.PostNetworkContainersRequest
 	err := service.Listener.Decode(w, r, &req)
-	logger.Request(service.Name, &req, err)
+	restLog.Request(service.Name, &req, err)
 	if err != nil {
 		response := cns.PostNetworkContainersResponse{
 			Response: cns.Response{
@@ -903,7 +909,7 @@ func (service *HTTPRestService) handlePostNetworkContainers(w http.ResponseWrite
 			},
 		}
 		err = service.Listener.Encode(w, &response)
-		logger.Response(service.Name, response, response.Response.ReturnCode, err)
+		restLog.Response(service.Name, response, response.Response.ReturnCode, err)
 		return
 	}
 
@@ -912,7 +918,7 @@ func (service *HTTPRestService) handlePostNetworkContainers(w http.ResponseWrite
 		Response: createNCsResp,
 	}
 	err = service.Listener.Encode(w, &response)
-	logger.Response(service.Name, response, response.Response.ReturnCode, err)
+	restLog.Response(service.Name, response, response.Response.ReturnCode, err)
 }
 
 func (service *HTTPRestService) createNetworkContainers(createNetworkContainerRequests []cns.CreateNetworkContainerRequest) cns.Response {
@@ -950,5 +956,5 @@ func (service *HTTPRestService) createNetworkContainers(createNetworkContainerRe
 // setResponse encodes the http response
 func (service *HTTPRestService) setResponse(w http.ResponseWriter, returnCode types.ResponseCode, response interface{}) {
 	serviceErr := service.Listener.Encode(w, &response)
-	logger.Response(service.Name, response, returnCode, serviceErr)
+	restLog.Response(service.Name, response, returnCode, serviceErr)
 }
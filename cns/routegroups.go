@@ -0,0 +1,94 @@
+package cns
+
+import "strings"
+
+// RouteGroup is a set of CNS API paths which are authorized together.
+type RouteGroup string
+
+// Route groups of the CNS API.
+const (
+	// RouteGroupNetworkContainers changes the network containers of the node, as DNC does.
+	RouteGroupNetworkContainers RouteGroup = "NetworkContainers"
+	// RouteGroupIPAM requests and releases pod IPs, as CNI does.
+	RouteGroupIPAM RouteGroup = "IPAM"
+	// RouteGroupRead reads the network containers and state of the node.
+	RouteGroupRead RouteGroup = "Read"
+	// RouteGroupNetwork manages the host networks and IP addresses of the node.
+	RouteGroupNetwork RouteGroup = "Network"
+	// RouteGroupDebug reads the IPAM state of the node, and changes the log levels of CNS and profiles it, for debugging.
+	RouteGroupDebug RouteGroup = "Debug"
+)
+
+// RouteGroups lists the route groups of the CNS API.
+var RouteGroups = []RouteGroup{
+	RouteGroupNetworkContainers,
+	RouteGroupIPAM,
+	RouteGroupRead,
+	RouteGroupNetwork,
+	RouteGroupDebug,
+}
+
+var routeGroupsByPath = map[string]RouteGroup{
+	SetOrchestratorType:            RouteGroupNetworkContainers,
+	CreateOrUpdateNetworkContainer: RouteGroupNetworkContainers,
+	DeleteNetworkContainer:         RouteGroupNetworkContainers,
+	PublishNetworkContainer:        RouteGroupNetworkContainers,
+	UnpublishNetworkContainer:      RouteGroupNetworkContainers,
+	AttachContainerToNetwork:       RouteGroupNetworkContainers,
+	DetachContainerFromNetwork:     RouteGroupNetworkContainers,
+	CreateHostNCApipaEndpointPath:  RouteGroupNetworkContainers,
+	DeleteHostNCApipaEndpointPath:  RouteGroupNetworkContainers,
+
+	RequestIPConfig: RouteGroupIPAM,
+	ReleaseIPConfig: RouteGroupIPAM,
+
+	GetHomeAz:                                RouteGroupRead,
+	GetInterfaceForContainer:                 RouteGroupRead,
+	GetNetworkContainerByOrchestratorContext: RouteGroupRead,
+	NetworkContainersURLPath:                 RouteGroupRead,
+	NumberOfCPUCores:                         RouteGroupRead,
+	NMAgentSupportedAPIs:                     RouteGroupRead,
+	GetHostLocalIPPath:                       RouteGroupRead,
+	GetIPAddressUtilizationPath:              RouteGroupRead,
+	GetUnhealthyIPAddressesPath:              RouteGroupRead,
+
+	SetEnvironmentPath:   RouteGroupNetwork,
+	CreateNetworkPath:    RouteGroupNetwork,
+	DeleteNetworkPath:    RouteGroupNetwork,
+	CreateHnsNetworkPath: RouteGroupNetwork,
+	DeleteHnsNetworkPath: RouteGroupNetwork,
+	ReserveIPAddressPath: RouteGroupNetwork,
+	ReleaseIPAddressPath: RouteGroupNetwork,
+
+	PathDebugIPAddresses: RouteGroupDebug,
+	PathDebugPodContext:  RouteGroupDebug,
+	PathDebugRestData:    RouteGroupDebug,
+	PathDebugLogLevels:   RouteGroupDebug,
+	WatchIPAddresses:     RouteGroupDebug,
+}
+
+// routeGroupsByPrefix are the route groups of the paths under a prefix, such as the subsystems of the log levels.
+var routeGroupsByPrefix = map[string]RouteGroup{
+	PathDebugLogLevels + "/": RouteGroupDebug,
+	PathDebugPprof + "/":     RouteGroupDebug,
+}
+
+// RouteGroupOf returns the route group of the path, with or without its API version prefix.
+// Paths which are not part of the CNS API are in no group.
+func RouteGroupOf(path string) (RouteGroup, bool) {
+	for _, prefix := range []string{V1Prefix, V2Prefix} {
+		if strings.HasPrefix(path, prefix+"/") {
+			path = strings.TrimPrefix(path, prefix)
+			break
+		}
+	}
+	if group, ok := routeGroupsByPath[path]; ok {
+		return group, true
+	}
+	for prefix, group := range routeGroupsByPrefix {
+		if strings.HasPrefix(path, prefix) {
+			return group, true
+		}
+	}
+	return "", false
+}
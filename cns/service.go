@@ -21,6 +21,9 @@ import (
 	"github.com/Azure/azure-container-networking/store"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 const (
@@ -34,6 +37,8 @@ type Service struct {
 	*common.Service
 	EndpointType string
 	Listener     *acn.Listener
+	tlsConfig    *tls.Config
+	grpcServer   *grpc.Server
 }
 
 // NewService creates a new Service object.
@@ -79,6 +84,9 @@ func (service *Service) Initialize(config *common.ServiceConfig) error {
 		if err != nil {
 			return err
 		}
+		if config.Authorize != nil {
+			listener.Use(config.Authorize)
+		}
 
 		if config.TlsSettings.TLSPort != "" {
 			// listener.URL.Host will always be hostname:port, passed in to CNS via CNS command
@@ -97,6 +105,7 @@ func (service *Service) Initialize(config *common.ServiceConfig) error {
 			if err := listener.StartTLS(config.ErrChan, tlsConfig, tlsAddress); err != nil {
 				return err
 			}
+			service.tlsConfig = tlsConfig
 		}
 
 		logger.Printf("HTTP listener will be started later after CNS state has been reconciled")
@@ -110,55 +119,59 @@ func (service *Service) Initialize(config *common.ServiceConfig) error {
 }
 
 func getTLSConfig(tlsSettings localtls.TlsSettings, errChan chan<- error) (*tls.Config, error) {
-	if tlsSettings.TLSCertificatePath != "" {
-		return getTLSConfigFromFile(tlsSettings)
-	}
-
-	if tlsSettings.KeyVaultURL != "" {
-		return getTLSConfigFromKeyVault(tlsSettings, errChan)
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		MaxVersion: tls.VersionTLS13,
 	}
 
-	return nil, errors.Errorf("invalid tls settings: %+v", tlsSettings)
-}
-
-func getTLSConfigFromFile(tlsSettings localtls.TlsSettings) (*tls.Config, error) {
-	tlsCertRetriever, err := localtls.GetTlsCertificateRetriever(tlsSettings)
+	source, err := getCertificateSource(tlsSettings)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get certificate retriever")
+		return nil, err
 	}
+	tlsConfig.GetCertificate = localtls.GetCertificateFunc(source)
 
-	leafCertificate, err := tlsCertRetriever.GetCertificate()
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to get certificate")
-	}
+	if tlsSettings.TLSClientCAPath != "" {
+		// mutual TLS: clients must present a certificate issued by one of the CAs, which identifies them to the
+		// authorization policy. The CAs are reloaded like the certificate, so that they can be rotated.
+		caSource, err := localtls.NewClientCASource(tlsSettings.TLSClientCAPath, logger.Log)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create client CA source")
+		}
+		tlsConfig.ClientCAs = caSource.GetClientCAs()
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		tlsConfig.GetConfigForClient = localtls.GetConfigForClientFunc(caSource, tlsConfig.Clone())
 
-	if leafCertificate == nil {
-		return nil, errors.New("certificate retrieval returned empty")
+		go func() {
+			errChan <- caSource.Run(context.TODO())
+		}()
 	}
 
-	privateKey, err := tlsCertRetriever.GetPrivateKey()
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to get certificate private key")
-	}
+	go func() {
+		errChan <- source.Run(context.TODO())
+	}()
 
-	tlsCert := tls.Certificate{
-		Certificate: [][]byte{leafCertificate.Raw},
-		PrivateKey:  privateKey,
-		Leaf:        leafCertificate,
+	return tlsConfig, nil
+}
+
+// getCertificateSource returns the source of the certificate of the HTTPS listener, which refreshes it from
+// disk or from KeyVault so that a renewed certificate is served without restarting CNS.
+func getCertificateSource(tlsSettings localtls.TlsSettings) (localtls.CertificateSource, error) {
+	if tlsSettings.TLSCertificatePath != "" {
+		source, err := localtls.NewFileCertificateSource(tlsSettings, logger.Log)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create file certificate source")
+		}
+		return source, nil
 	}
 
-	tlsConfig := &tls.Config{
-		MaxVersion: tls.VersionTLS13,
-		MinVersion: tls.VersionTLS12,
-		Certificates: []tls.Certificate{
-			tlsCert,
-		},
+	if tlsSettings.KeyVaultURL != "" {
+		return getCertificateSourceFromKeyVault(tlsSettings)
 	}
 
-	return tlsConfig, nil
+	return nil, errors.Errorf("invalid tls settings: %+v", tlsSettings)
 }
 
-func getTLSConfigFromKeyVault(tlsSettings localtls.TlsSettings, errChan chan<- error) (*tls.Config, error) {
+func getCertificateSourceFromKeyVault(tlsSettings localtls.TlsSettings) (localtls.CertificateSource, error) {
 	credOpts := azidentity.ManagedIdentityCredentialOptions{ID: azidentity.ResourceID(tlsSettings.MSIResourceID)}
 	cred, err := azidentity.NewManagedIdentityCredential(&credOpts)
 	if err != nil {
@@ -170,26 +183,12 @@ func getTLSConfigFromKeyVault(tlsSettings localtls.TlsSettings, errChan chan<- e
 		return nil, errors.Wrap(err, "could not create new keyvault shim")
 	}
 
-	ctx := context.TODO()
-
-	cr, err := keyvault.NewCertRefresher(ctx, kvs, logger.Log, tlsSettings.KeyVaultCertificateName)
+	cr, err := keyvault.NewCertRefresher(context.TODO(), kvs, logger.Log, tlsSettings.KeyVaultCertificateName)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not create new cert refresher")
 	}
 
-	go func() {
-		errChan <- cr.Refresh(ctx, tlsSettings.KeyVaultCertificateRefreshInterval)
-	}()
-
-	tlsConfig := tls.Config{
-		MinVersion: tls.VersionTLS12,
-		MaxVersion: tls.VersionTLS13,
-		GetCertificate: func(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
-			return cr.GetCertificate(), nil
-		},
-	}
-
-	return &tlsConfig, nil
+	return keyvault.NewCertSource(cr, tlsSettings.KeyVaultCertificateRefreshInterval), nil
 }
 
 func (service *Service) StartListener(config *common.ServiceConfig) error {
@@ -198,6 +197,15 @@ func (service *Service) StartListener(config *common.ServiceConfig) error {
 	// Initialize the listener.
 	if service.Listener != nil {
 		log.Debugf("[Azure CNS] Starting listener: %+v", config)
+		if config.UnixSocketPath != "" {
+			if err := service.Listener.StartUnix(config.ErrChan, config.UnixSocketPath, config.UnixSocketMode); err != nil {
+				return errors.Wrap(err, "failed to start unix socket listener")
+			}
+		}
+		if config.UnixSocketOnly {
+			logger.Printf("[Azure CNS] Only listening on unix socket %s", config.UnixSocketPath)
+			return nil
+		}
 		// Start the listener.
 		// continue to listen on the normal endpoint for http traffic, this will be supported
 		// for sometime until partners migrate fully to https
@@ -211,8 +219,62 @@ func (service *Service) StartListener(config *common.ServiceConfig) error {
 	return nil
 }
 
+// StartGRPCListener starts a gRPC server with the services registered by register on the config's GRPCURL,
+// which is a unix socket ("unix:///path") with the UnixSocketMode of the config, or tcp ("tcp://host:port") URL.
+// The gRPC server uses the same TLS settings as the HTTPS listener when they are configured, and is authorized by
+// the interceptors of the config.
+func (service *Service) StartGRPCListener(config *common.ServiceConfig, register func(grpc.ServiceRegistrar)) error {
+	u, err := url.Parse(config.GRPCURL)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse grpc url %s", config.GRPCURL)
+	}
+	address := u.Host + u.Path
+	var listener net.Listener
+	if u.Scheme == "unix" {
+		// the gRPC socket is restricted to the same local users as the socket of the HTTP API
+		listener, err = acn.ListenUnix(address, config.UnixSocketMode)
+	} else {
+		listener, err = net.Listen(u.Scheme, address)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to listen on %s", config.GRPCURL)
+	}
+
+	// the API of the gRPC server is authorized by the same policy as the HTTP listener
+	if config.Authorize != nil && (config.AuthorizeUnary == nil || config.AuthorizeStream == nil) {
+		listener.Close()
+		return errors.New("refusing to start the grpc listener without authorization, since the http listener is authorized")
+	}
+
+	creds := insecure.NewCredentials()
+	if service.tlsConfig != nil {
+		creds = credentials.NewTLS(service.tlsConfig)
+	}
+	opts := []grpc.ServerOption{grpc.Creds(peerCredentials{creds})}
+	if config.AuthorizeUnary != nil {
+		opts = append(opts, grpc.ChainUnaryInterceptor(config.AuthorizeUnary))
+	}
+	if config.AuthorizeStream != nil {
+		opts = append(opts, grpc.ChainStreamInterceptor(config.AuthorizeStream))
+	}
+	service.grpcServer = grpc.NewServer(opts...)
+	register(service.grpcServer)
+	logger.Printf("[Azure CNS] Started gRPC listener on %s", config.GRPCURL)
+
+	go func() {
+		if err := service.grpcServer.Serve(listener); err != nil {
+			config.ErrChan <- errors.Wrap(err, "grpc server failed")
+		}
+	}()
+	return nil
+}
+
 // Uninitialize cleans up the plugin.
 func (service *Service) Uninitialize() {
+	if service.grpcServer != nil {
+		// not graceful, since watches don't end on their own
+		service.grpcServer.Stop()
+	}
 	service.Listener.Stop()
 	service.Service.Uninitialize()
 }
@@ -8,10 +8,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/pprof"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
@@ -22,13 +24,16 @@ import (
 	"github.com/Azure/azure-container-networking/cnm/ipam"
 	"github.com/Azure/azure-container-networking/cnm/network"
 	"github.com/Azure/azure-container-networking/cns"
+	"github.com/Azure/azure-container-networking/cns/authz"
 	cnscli "github.com/Azure/azure-container-networking/cns/cmd/cli"
 	"github.com/Azure/azure-container-networking/cns/cniconflist"
 	"github.com/Azure/azure-container-networking/cns/cnireconciler"
 	"github.com/Azure/azure-container-networking/cns/common"
 	"github.com/Azure/azure-container-networking/cns/configuration"
+	grpcv1 "github.com/Azure/azure-container-networking/cns/grpc/v1"
 	"github.com/Azure/azure-container-networking/cns/healthserver"
 	"github.com/Azure/azure-container-networking/cns/hnsclient"
+	"github.com/Azure/azure-container-networking/cns/hostmetadata"
 	"github.com/Azure/azure-container-networking/cns/ipampool"
 	cssctrl "github.com/Azure/azure-container-networking/cns/kubecontroller/clustersubnetstate"
 	nncctrl "github.com/Azure/azure-container-networking/cns/kubecontroller/nodenetworkconfig"
@@ -46,6 +51,7 @@ import (
 	"github.com/Azure/azure-container-networking/fs"
 	"github.com/Azure/azure-container-networking/log"
 	"github.com/Azure/azure-container-networking/nmagent"
+	"github.com/Azure/azure-container-networking/otlp"
 	"github.com/Azure/azure-container-networking/platform"
 	"github.com/Azure/azure-container-networking/processlock"
 	localtls "github.com/Azure/azure-container-networking/server/tls"
@@ -54,26 +60,33 @@ import (
 	"github.com/avast/retry-go/v3"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	kuberuntime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	kubescheme "k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 const (
 	// Service name.
-	name                              = "azure-cns"
-	pluginName                        = "azure-vnet"
-	endpointStoreName                 = "azure-endpoints"
-	endpointStoreLocation             = "/var/run/azure-cns/"
-	defaultCNINetworkConfigFileName   = "10-azure.conflist"
-	dncApiVersion                     = "?api-version=2018-03-01"
-	poolIPAMRefreshRateInMilliseconds = 1000
+	name                            = "azure-cns"
+	pluginName                      = "azure-vnet"
+	endpointStoreName               = "azure-endpoints"
+	endpointStoreLocation           = "/var/run/azure-cns/"
+	defaultCNINetworkConfigFileName = "10-azure.conflist"
+	dncApiVersion                   = "?api-version=2018-03-01"
+	// the pool monitor reconciles when notified of IP state changes, so polling is only a safety net
+	poolIPAMRefreshRateInMilliseconds = 30000
 
 	// 720 * acn.FiveSeconds sec sleeps = 1Hr
 	maxRetryNodeRegister = 720
@@ -86,11 +99,22 @@ const (
 	scenarioV4Overlay cniConflistScenario = "v4overlay"
 )
 
+// azureIPAMBinary is the CNI IPAM plugin of Cilium nodes, which only reaches CNS over TCP.
+const azureIPAMBinary = "azure-ipam"
+
 var (
 	rootCtx   context.Context
 	rootErrCh chan error
 )
 
+// the messages of CNS are logged at the levels of the subsystems which they are about
+var (
+	cnsLog  = logger.Subsystem(logger.CNS)
+	nmaLog  = logger.Subsystem(logger.NMAgent)
+	kubeLog = logger.Subsystem(logger.KubeController)
+	poolLog = logger.Subsystem(logger.IPAMPool)
+)
+
 // Version is populated by make during build.
 var version string
 
@@ -325,9 +349,27 @@ type NodeInterrogator interface {
 	SupportedAPIs(context.Context) ([]string, error)
 }
 
+// newHostMetadataProvider returns the provider of the metadata of the host selected in the settings.
+func newHostMetadataProvider(settings configuration.HostMetadataSettings, wscli *wireserver.Client, nmaClient *nmagent.Client) (hostmetadata.Provider, error) {
+	switch settings.Provider {
+	case configuration.HostMetadataProviderWireserver:
+		return hostmetadata.NewWireserver(wscli, nmaClient), nil
+	case configuration.HostMetadataProviderIMDS:
+		return &hostmetadata.IMDS{HTTPClient: &http.Client{Timeout: 10 * time.Second}}, nil //nolint:gomnd // 10s is a reasonable timeout for IMDS
+	case configuration.HostMetadataProviderStatic:
+		provider, err := hostmetadata.NewStaticFromFile(settings.StaticFilePath)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create static host metadata provider")
+		}
+		return provider, nil
+	default:
+		return nil, errors.Errorf("unknown host metadata provider %q", settings.Provider)
+	}
+}
+
 // RegisterNode - Tries to register node with DNC when CNS is started in managed DNC mode
 func registerNode(httpc *http.Client, httpRestService cns.HTTPService, dncEP, infraVnet, nodeID string, ni NodeInterrogator) error {
-	logger.Printf("[Azure CNS] Registering node %s with Infrastructure Network: %s PrivateEndpoint: %s", nodeID, infraVnet, dncEP)
+	cnsLog.Printf("[Azure CNS] Registering node %s with Infrastructure Network: %s PrivateEndpoint: %s", nodeID, infraVnet, dncEP)
 
 	var (
 		numCPU              = runtime.NumCPU()
@@ -339,7 +381,7 @@ func registerNode(httpc *http.Client, httpRestService cns.HTTPService, dncEP, in
 	supportedApis, retErr := ni.SupportedAPIs(context.TODO())
 
 	if retErr != nil {
-		logger.Errorf("[Azure CNS] Failed to retrieve SupportedApis from NMagent of node %s with Infrastructure Network: %s PrivateEndpoint: %s",
+		nmaLog.Errorf("[Azure CNS] Failed to retrieve SupportedApis from NMagent of node %s with Infrastructure Network: %s PrivateEndpoint: %s",
 			nodeID, infraVnet, dncEP)
 		return retErr
 	}
@@ -371,14 +413,14 @@ func sendRegisterNodeRequest(httpc *http.Client, httpRestService cns.HTTPService
 
 	response, err := httpc.Post(registerURL, "application/json", &body)
 	if err != nil {
-		logger.Errorf("[Azure CNS] Failed to register node with retriable err: %+v", err)
+		cnsLog.Errorf("[Azure CNS] Failed to register node with retriable err: %+v", err)
 		return errors.Wrap(err, "failed to sendRegisterNodeRequest")
 	}
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusCreated {
 		err = fmt.Errorf("[Azure CNS] Failed to register node, DNC replied with http status code %s", strconv.Itoa(response.StatusCode))
-		logger.Errorf(err.Error())
+		cnsLog.Errorf(err.Error())
 		return errors.Wrap(err, "failed to sendRegisterNodeRequest")
 	}
 
@@ -390,7 +432,7 @@ func sendRegisterNodeRequest(httpc *http.Client, httpRestService cns.HTTPService
 	}
 	httpRestService.SetNodeOrchestrator(&req)
 
-	logger.Printf("[Azure CNS] Node Registered")
+	cnsLog.Printf("[Azure CNS] Node Registered")
 	return nil
 }
 
@@ -416,6 +458,32 @@ func startTelemetryService(ctx context.Context) {
 	tb.PushData(rootCtx)
 }
 
+// newOTLPExporter creates the exporter of the CNS telemetry to an OpenTelemetry collector.
+// Export failures are logged with z, as logging them with the CNS logger would export them too.
+func newOTLPExporter(ts configuration.TelemetrySettings, z *zap.Logger) (*otlp.Exporter, error) {
+	headers, err := otlp.ParseHeaders(ts.OTLPHeaders)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse OTLP headers")
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get hostname")
+	}
+	exporter, err := otlp.New(otlp.Config{
+		Endpoint:           ts.OTLPEndpoint,
+		Headers:            headers,
+		ServiceName:        name,
+		ServiceVersion:     version,
+		ResourceAttributes: map[string]string{"host.name": hostname},
+		BatchInterval:      time.Duration(ts.TelemetryBatchIntervalInSecs) * time.Second,
+		Gatherer:           metrics.Registry,
+		ErrorHandler: func(err error) {
+			z.Error("failed to export OTLP telemetry", zap.Error(err))
+		},
+	})
+	return exporter, errors.Wrap(err, "failed to create OTLP exporter")
+}
+
 // Main is the entry point for CNS.
 func main() {
 	// Initialize and parse command line arguments.
@@ -477,53 +545,77 @@ func main() {
 	}
 
 	if !telemetryEnabled {
-		logger.Errorf("[Azure CNS] Cannot disable telemetry via cmdline. Update cns_config.json to disable telemetry.")
+		cnsLog.Errorf("[Azure CNS] Cannot disable telemetry via cmdline. Update cns_config.json to disable telemetry.")
 	}
 
-	logger.Printf("[Azure CNS] cmdLineConfigPath: %s", cmdLineConfigPath)
+	cnsLog.Printf("[Azure CNS] cmdLineConfigPath: %s", cmdLineConfigPath)
 	cnsconfig, err := configuration.ReadConfig(cmdLineConfigPath)
 	if err != nil {
-		logger.Errorf("[Azure CNS] Error reading cns config: %v", err)
+		cnsLog.Errorf("[Azure CNS] Error reading cns config: %v", err)
 	}
 
 	configuration.SetCNSConfigDefaults(cnsconfig)
-	logger.Printf("[Azure CNS] Read config :%+v", cnsconfig)
+	cnsLog.Printf("[Azure CNS] Read config :%+v", cnsconfig)
+
+	for subsystem, level := range cnsconfig.LogLevels {
+		if err := logger.SetLevel(subsystem, level); err != nil {
+			cnsLog.Errorf("[Azure CNS] Failed to set the log level of %s: %v", subsystem, err)
+		}
+	}
 
 	var conflistGenerator restserver.CNIConflistGenerator
 	if cnsconfig.EnableCNIConflistGeneration {
 		writer, newWriterErr := fs.NewAtomicWriter(cnsconfig.CNIConflistFilepath)
 		if newWriterErr != nil {
-			logger.Errorf("unable to create atomic writer to generate cni conflist: %v", newWriterErr)
+			cnsLog.Errorf("unable to create atomic writer to generate cni conflist: %v", newWriterErr)
 			os.Exit(1)
 		}
 
 		switch scenario := cniConflistScenario(cnsconfig.CNIConflistScenario); scenario {
 		case scenarioV4Overlay:
-			conflistGenerator = &cniconflist.V4OverlayGenerator{Writer: writer}
+			conflistGenerator = &cniconflist.V4OverlayGenerator{Writer: writer, CNSURL: unixSocketURL(cnsconfig.UnixSocketSettings)}
 		default:
-			logger.Errorf("unable to generate cni conflist for unknown scenario: %s", scenario)
+			cnsLog.Errorf("unable to generate cni conflist for unknown scenario: %s", scenario)
 			os.Exit(1)
 		}
 	}
 
 	// start the health server
-	z, _ := zap.NewProduction()
-	go healthserver.Start(z, cnsconfig.MetricsBindAddress)
+	healthChecks := healthserver.NewChecks()
+	go healthserver.Start(logger.Named(logger.CNS), cnsconfig.MetricsBindAddress, healthChecks)
 
 	nmaConfig, err := nmagent.NewConfig(cnsconfig.WireserverIP)
 	if err != nil {
-		logger.Errorf("[Azure CNS] Failed to produce NMAgent config from the supplied wireserver ip: %v", err)
+		nmaLog.Errorf("[Azure CNS] Failed to produce NMAgent config from the supplied wireserver ip: %v", err)
 		return
 	}
+	nmaConfig.RateLimit = cnsconfig.NMAgentRateLimit
+	nmaConfig.RateBurst = cnsconfig.NMAgentRateBurst
+	nmaConfig.CircuitBreakerThreshold = cnsconfig.NMAgentCircuitBreakerThreshold
+	nmaConfig.CircuitBreakerOpenDuration = time.Duration(cnsconfig.NMAgentCircuitBreakerOpenSecs) * time.Second
+	nmaConfig.Logger = logger.Named(logger.NMAgent)
 
 	nmaClient, err := nmagent.NewClient(nmaConfig)
 	if err != nil {
-		logger.Errorf("[Azure CNS] Failed to start nmagent client due to error: %v", err)
+		nmaLog.Errorf("[Azure CNS] Failed to start nmagent client due to error: %v", err)
 		return
 	}
 
-	homeAzMonitor := restserver.NewHomeAzMonitor(nmaClient, time.Duration(cnsconfig.PopulateHomeAzCacheRetryIntervalSecs)*time.Second)
-	logger.Printf("start the goroutine for refreshing homeAz")
+	// the wireserver client uses the same host as NMAgent, so that both can be pointed at an emulator
+	wireserverHost := nmaConfig.Host
+	if nmaConfig.Port != 80 { //nolint:gomnd // 80 is commonly understood to be HTTP
+		wireserverHost = net.JoinHostPort(nmaConfig.Host, strconv.Itoa(int(nmaConfig.Port)))
+	}
+	hostMetadata, err := newHostMetadataProvider(cnsconfig.HostMetadataSettings,
+		&wireserver.Client{HTTPClient: &http.Client{}, Host: wireserverHost}, nmaClient)
+	if err != nil {
+		cnsLog.Errorf("[Azure CNS] Failed to create host metadata provider due to error: %v", err)
+		return
+	}
+	cnsLog.Printf("[Azure CNS] Using host metadata provider %s", cnsconfig.HostMetadataSettings.Provider)
+
+	homeAzMonitor := restserver.NewHomeAzMonitor(hostMetadata, time.Duration(cnsconfig.PopulateHomeAzCacheRetryIntervalSecs)*time.Second)
+	cnsLog.Printf("start the goroutine for refreshing homeAz")
 	homeAzMonitor.Start()
 
 	if cnsconfig.ChannelMode == cns.Managed {
@@ -552,9 +644,19 @@ func main() {
 			DebugMode:                    ts.DebugMode,
 		}
 
-		if aiKey := cnsconfig.TelemetrySettings.AppInsightsInstrumentationKey; aiKey != "" {
-			logger.InitAIWithIKey(aiConfig, aiKey, ts.DisableTrace, ts.DisableMetric, ts.DisableEvent)
-		} else {
+		switch {
+		case ts.Exporter == configuration.TelemetryExporterOTLP:
+			exporter, err := newOTLPExporter(ts, logger.Named(logger.Telemetry))
+			if err != nil {
+				cnsLog.Errorf("[Azure CNS] Failed to create the OTLP exporter: %v", err)
+				return
+			}
+			otlp.SetExporter(exporter)
+			go exporter.Run(rootCtx)
+			logger.InitOTLP(exporter, ts.DisableTrace, ts.DisableMetric, ts.DisableEvent)
+		case ts.AppInsightsInstrumentationKey != "":
+			logger.InitAIWithIKey(aiConfig, ts.AppInsightsInstrumentationKey, ts.DisableTrace, ts.DisableMetric, ts.DisableEvent)
+		default:
 			logger.InitAI(aiConfig, ts.DisableTrace, ts.DisableMetric, ts.DisableEvent)
 		}
 	}
@@ -564,11 +666,11 @@ func main() {
 	}
 
 	// Log platform information.
-	logger.Printf("Running on %v", platform.GetOSInfo())
+	cnsLog.Printf("Running on %v", platform.GetOSInfo())
 
 	err = platform.CreateDirectory(storeFileLocation)
 	if err != nil {
-		logger.Errorf("Failed to create File Store directory %s, due to Error:%v", storeFileLocation, err.Error())
+		cnsLog.Errorf("Failed to create File Store directory %s, due to Error:%v", storeFileLocation, err.Error())
 		return
 	}
 
@@ -582,7 +684,7 @@ func main() {
 	storeFileName := storeFileLocation + name + ".json"
 	config.Store, err = store.NewJsonFileStore(storeFileName, lockclient)
 	if err != nil {
-		logger.Errorf("Failed to create store file: %s, due to error %v\n", storeFileName, err)
+		cnsLog.Errorf("Failed to create store file: %s, due to error %v\n", storeFileName, err)
 		return
 	}
 
@@ -598,24 +700,23 @@ func main() {
 
 		err = platform.CreateDirectory(endpointStoreLocation)
 		if err != nil {
-			logger.Errorf("Failed to create File Store directory %s, due to Error:%v", storeFileLocation, err.Error())
+			cnsLog.Errorf("Failed to create File Store directory %s, due to Error:%v", storeFileLocation, err.Error())
 			return
 		}
 		// Create the key value store.
 		storeFileName := endpointStoreLocation + endpointStoreName + ".json"
 		endpointStateStore, err = store.NewJsonFileStore(storeFileName, endpointStoreLock)
 		if err != nil {
-			logger.Errorf("Failed to create endpoint state store file: %s, due to error %v\n", storeFileName, err)
+			cnsLog.Errorf("Failed to create endpoint state store file: %s, due to error %v\n", storeFileName, err)
 			return
 		}
 	}
 
 	// Create CNS object.
-
-	httpRestService, err := restserver.NewHTTPRestService(&config, &wireserver.Client{HTTPClient: &http.Client{}}, nmaClient,
+	httpRestService, err := restserver.NewHTTPRestService(&config, hostMetadata, nmaClient,
 		endpointStateStore, conflistGenerator, homeAzMonitor)
 	if err != nil {
-		logger.Errorf("Failed to create CNS object, err:%v.\n", err)
+		cnsLog.Errorf("Failed to create CNS object, err:%v.\n", err)
 		return
 	}
 
@@ -629,22 +730,40 @@ func main() {
 	httpRestService.SetOption(acn.OptProgramSNATIPTables, cnsconfig.ProgramSNATIPTables)
 	httpRestService.SetOption(acn.OptManageEndpointState, cnsconfig.ManageEndpointState)
 
+	if restService, ok := httpRestService.(*restserver.HTTPRestService); ok {
+		healthChecks.AddReadyzCheck("state-store", restService.StateStoreCheck)
+		// NMAgent is only a dependency of CNS on Azure VMs which get their metadata from it
+		if cnsconfig.HostMetadataSettings.Provider == configuration.HostMetadataProviderWireserver {
+			healthChecks.AddReadyzCheck("nmagent", restService.NMAgentCheck)
+		}
+		if cnsconfig.EnableCNIConflistGeneration {
+			healthChecks.AddReadyzCheck("cni-conflist", restService.CNIConflistCheck)
+		}
+	}
+
 	// Create default ext network if commandline option is set
 	if len(strings.TrimSpace(createDefaultExtNetworkType)) > 0 {
 		if err := hnsclient.CreateDefaultExtNetwork(createDefaultExtNetworkType); err == nil {
-			logger.Printf("[Azure CNS] Successfully created default ext network")
+			cnsLog.Printf("[Azure CNS] Successfully created default ext network")
 		} else {
-			logger.Printf("[Azure CNS] Failed to create default ext network due to error: %v", err)
+			cnsLog.Printf("[Azure CNS] Failed to create default ext network due to error: %v", err)
 			return
 		}
 	}
 
-	logger.Printf("[Azure CNS] Initialize HTTPRestService")
+	cnsLog.Printf("[Azure CNS] Initialize HTTPRestService")
 	if httpRestService != nil {
+		config.GRPCURL = cnsconfig.GRPCURL
+		if err := setUnixSocketConfig(&config, cnsconfig.UnixSocketSettings, cniPath); err != nil {
+			cnsLog.Errorf("Invalid unix socket settings, err:%v.\n", err)
+			return
+		}
 		if cnsconfig.UseHTTPS {
 			config.TlsSettings = localtls.TlsSettings{
 				TLSSubjectName:                     cnsconfig.TLSSubjectName,
 				TLSCertificatePath:                 cnsconfig.TLSCertificatePath,
+				TLSKeyPath:                         cnsconfig.TLSKeyPath,
+				TLSClientCAPath:                    cnsconfig.TLSClientCAPath,
 				TLSPort:                            cnsconfig.TLSPort,
 				KeyVaultURL:                        cnsconfig.KeyVaultSettings.URL,
 				KeyVaultCertificateName:            cnsconfig.KeyVaultSettings.CertificateName,
@@ -652,10 +771,20 @@ func main() {
 				KeyVaultCertificateRefreshInterval: time.Duration(cnsconfig.KeyVaultSettings.RefreshIntervalInHrs) * time.Hour,
 			}
 		}
+		if cnsconfig.AuthorizationSettings.Enabled {
+			policy, err := authz.NewPolicy(cnsconfig.AuthorizationSettings)
+			if err != nil {
+				cnsLog.Errorf("Failed to create the authorization policy, err:%v.\n", err)
+				return
+			}
+			config.Authorize = policy.Handler
+			config.AuthorizeUnary = policy.UnaryServerInterceptor(grpcv1.RouteGroups)
+			config.AuthorizeStream = policy.StreamServerInterceptor(grpcv1.RouteGroups)
+		}
 
 		err = httpRestService.Init(&config)
 		if err != nil {
-			logger.Errorf("Failed to init HTTPService, err:%v.\n", err)
+			cnsLog.Errorf("Failed to init HTTPService, err:%v.\n", err)
 			return
 		}
 	}
@@ -663,7 +792,7 @@ func main() {
 	// Setting the remote ARP MAC address to 12-34-56-78-9a-bc on windows for external traffic
 	err = platform.SetSdnRemoteArpMacAddress()
 	if err != nil {
-		logger.Errorf("Failed to set remote ARP MAC address: %v", err)
+		cnsLog.Errorf("Failed to set remote ARP MAC address: %v", err)
 		return
 	}
 
@@ -673,7 +802,7 @@ func main() {
 		// Check the CNI statefile mount, and if the file is empty
 		// stub an empty JSON object
 		if err := cnireconciler.WriteObjectToCNIStatefile(); err != nil {
-			logger.Errorf("Failed to write empty object to CNI state: %v", err)
+			cnsLog.Errorf("Failed to write empty object to CNI state: %v", err)
 			return
 		}
 
@@ -684,7 +813,7 @@ func main() {
 			var isGoodVer bool
 			isGoodVer, err = cnireconciler.IsDumpStateVer()
 			if err != nil {
-				logger.Errorf("error checking CNI ver: %v", err)
+				cnsLog.Errorf("error checking CNI ver: %v", err)
 			}
 
 			// override the prior config flag with the result of the ver check.
@@ -696,11 +825,11 @@ func main() {
 				cns.GlobalPodInfoScheme = cns.InterfaceIDPodInfoScheme
 			}
 		}
-		logger.Printf("Set GlobalPodInfoScheme %v (InitializeFromCNI=%t)", cns.GlobalPodInfoScheme, cnsconfig.InitializeFromCNI)
+		cnsLog.Printf("Set GlobalPodInfoScheme %v (InitializeFromCNI=%t)", cns.GlobalPodInfoScheme, cnsconfig.InitializeFromCNI)
 
-		err = InitializeCRDState(rootCtx, httpRestService, cnsconfig)
+		err = InitializeCRDState(rootCtx, httpRestService, cnsconfig, healthChecks)
 		if err != nil {
-			logger.Errorf("Failed to start CRD Controller, err:%v.\n", err)
+			cnsLog.Errorf("Failed to start CRD Controller, err:%v.\n", err)
 			return
 		}
 
@@ -711,17 +840,17 @@ func main() {
 	if config.ChannelMode == cns.MultiTenantCRD {
 		err = InitializeMultiTenantController(rootCtx, httpRestService, *cnsconfig)
 		if err != nil {
-			logger.Errorf("Failed to start multiTenantController, err:%v.\n", err)
+			cnsLog.Errorf("Failed to start multiTenantController, err:%v.\n", err)
 			return
 		}
 
 	}
 
-	logger.Printf("[Azure CNS] Start HTTP listener")
+	cnsLog.Printf("[Azure CNS] Start HTTP listener")
 	if httpRestService != nil {
 		err = httpRestService.Start(&config)
 		if err != nil {
-			logger.Errorf("Failed to start CNS, err:%v.\n", err)
+			cnsLog.Errorf("Failed to start CNS, err:%v.\n", err)
 			return
 		}
 	}
@@ -734,7 +863,7 @@ func main() {
 	// If CNS is running on managed DNC mode
 	if config.ChannelMode == cns.Managed {
 		if privateEndpoint == "" || infravnet == "" || nodeID == "" {
-			logger.Errorf("[Azure CNS] Missing required values to run in managed mode: PrivateEndpoint: %s InfrastructureNetworkID: %s NodeID: %s",
+			cnsLog.Errorf("[Azure CNS] Missing required values to run in managed mode: PrivateEndpoint: %s InfrastructureNetworkID: %s NodeID: %s",
 				privateEndpoint,
 				infravnet,
 				nodeID)
@@ -745,9 +874,9 @@ func main() {
 		httpRestService.SetOption(acn.OptInfrastructureNetworkID, infravnet)
 		httpRestService.SetOption(acn.OptNodeID, nodeID)
 
-		registerErr := registerNode(acn.GetHttpClient(), httpRestService, privateEndpoint, infravnet, nodeID, nmaClient)
+		registerErr := registerNode(acn.GetHttpClient(), httpRestService, privateEndpoint, infravnet, nodeID, hostMetadata)
 		if registerErr != nil {
-			logger.Errorf("[Azure CNS] Resgistering Node failed with error: %v PrivateEndpoint: %s InfrastructureNetworkID: %s NodeID: %s",
+			cnsLog.Errorf("[Azure CNS] Resgistering Node failed with error: %v PrivateEndpoint: %s InfrastructureNetworkID: %s NodeID: %s",
 				registerErr,
 				privateEndpoint,
 				infravnet,
@@ -780,14 +909,14 @@ func main() {
 		// Create network plugin.
 		netPlugin, err = network.NewPlugin(&pluginConfig)
 		if err != nil {
-			logger.Errorf("Failed to create network plugin, err:%v.\n", err)
+			cnsLog.Errorf("Failed to create network plugin, err:%v.\n", err)
 			return
 		}
 
 		// Create IPAM plugin.
 		ipamPlugin, err = ipam.NewPlugin(&pluginConfig)
 		if err != nil {
-			logger.Errorf("Failed to create IPAM plugin, err:%v.\n", err)
+			cnsLog.Errorf("Failed to create IPAM plugin, err:%v.\n", err)
 			return
 		}
 
@@ -801,15 +930,15 @@ func main() {
 		pluginStoreFile := storeFileLocation + pluginName + ".json"
 		pluginConfig.Store, err = store.NewJsonFileStore(pluginStoreFile, lockclientCnm)
 		if err != nil {
-			logger.Errorf("Failed to create plugin store file %s, due to error : %v\n", pluginStoreFile, err)
+			cnsLog.Errorf("Failed to create plugin store file %s, due to error : %v\n", pluginStoreFile, err)
 			return
 		}
 
 		// Set plugin options.
 		netPlugin.SetOption(acn.OptAPIServerURL, url)
-		logger.Printf("Start netplugin\n")
+		cnsLog.Printf("Start netplugin\n")
 		if err := netPlugin.Start(&pluginConfig); err != nil {
-			logger.Errorf("Failed to create network plugin, err:%v.\n", err)
+			cnsLog.Errorf("Failed to create network plugin, err:%v.\n", err)
 			return
 		}
 
@@ -818,7 +947,7 @@ func main() {
 		ipamPlugin.SetOption(acn.OptIpamQueryUrl, ipamQueryUrl)
 		ipamPlugin.SetOption(acn.OptIpamQueryInterval, ipamQueryInterval)
 		if err := ipamPlugin.Start(&pluginConfig); err != nil {
-			logger.Errorf("Failed to create IPAM plugin, err:%v.\n", err)
+			cnsLog.Errorf("Failed to create IPAM plugin, err:%v.\n", err)
 			return
 		}
 	}
@@ -828,29 +957,29 @@ func main() {
 
 	if len(strings.TrimSpace(createDefaultExtNetworkType)) > 0 {
 		if err := hnsclient.DeleteDefaultExtNetwork(); err == nil {
-			logger.Printf("[Azure CNS] Successfully deleted default ext network")
+			cnsLog.Printf("[Azure CNS] Successfully deleted default ext network")
 		} else {
-			logger.Printf("[Azure CNS] Failed to delete default ext network due to error: %v", err)
+			cnsLog.Printf("[Azure CNS] Failed to delete default ext network due to error: %v", err)
 		}
 	}
 
-	logger.Printf("end the goroutine for refreshing homeAz")
+	cnsLog.Printf("end the goroutine for refreshing homeAz")
 	homeAzMonitor.Stop()
 
-	logger.Printf("stop cns service")
+	cnsLog.Printf("stop cns service")
 	// Cleanup.
 	if httpRestService != nil {
 		httpRestService.Stop()
 	}
 
 	if startCNM {
-		logger.Printf("stop cnm plugin")
+		cnsLog.Printf("stop cnm plugin")
 		if netPlugin != nil {
 			netPlugin.Stop()
 		}
 
 		if ipamPlugin != nil {
-			logger.Printf("stop ipam plugin")
+			cnsLog.Printf("stop ipam plugin")
 			ipamPlugin.Stop()
 		}
 
@@ -863,7 +992,7 @@ func main() {
 		log.Errorf("lockclient cns unlock error:%v", err)
 	}
 
-	logger.Printf("CNS exited")
+	cnsLog.Printf("CNS exited")
 	logger.Close()
 }
 
@@ -878,7 +1007,7 @@ func InitializeMultiTenantController(ctx context.Context, httpRestService cns.HT
 	// convert interface type to implementation type
 	httpRestServiceImpl, ok := httpRestService.(*restserver.HTTPRestService)
 	if !ok {
-		logger.Errorf("Failed to convert interface httpRestService to implementation: %v", httpRestService)
+		kubeLog.Errorf("Failed to convert interface httpRestService to implementation: %v", httpRestService)
 		return fmt.Errorf("Failed to convert interface httpRestService to implementation: %v",
 			httpRestService)
 	}
@@ -892,7 +1021,7 @@ func InitializeMultiTenantController(ctx context.Context, httpRestService cns.HT
 	// Create multiTenantController.
 	multiTenantController, err = multitenantoperator.New(httpRestServiceImpl, kubeConfig)
 	if err != nil {
-		logger.Errorf("Failed to create multiTenantController:%v", err)
+		kubeLog.Errorf("Failed to create multiTenantController:%v", err)
 		return err
 	}
 
@@ -900,9 +1029,9 @@ func InitializeMultiTenantController(ctx context.Context, httpRestService cns.HT
 	go func() {
 		for {
 			if err := multiTenantController.Start(ctx); err != nil {
-				logger.Errorf("Failed to start multiTenantController: %v", err)
+				kubeLog.Errorf("Failed to start multiTenantController: %v", err)
 			} else {
-				logger.Printf("Exiting multiTenantController")
+				kubeLog.Printf("Exiting multiTenantController")
 				return
 			}
 
@@ -912,16 +1041,16 @@ func InitializeMultiTenantController(ctx context.Context, httpRestService cns.HT
 	}()
 	for {
 		if multiTenantController.IsStarted() {
-			logger.Printf("MultiTenantController is started")
+			kubeLog.Printf("MultiTenantController is started")
 			break
 		}
 
-		logger.Printf("Waiting for multiTenantController to start...")
+		kubeLog.Printf("Waiting for multiTenantController to start...")
 		time.Sleep(time.Millisecond * 500)
 	}
 
 	// TODO: do we need this to be running?
-	logger.Printf("Starting SyncHostNCVersion")
+	nmaLog.Printf("Starting SyncHostNCVersion")
 	go func() {
 		// Periodically poll vfp programmed NC version from NMAgent
 		tickerChannel := time.Tick(time.Duration(cnsconfig.SyncHostNCVersionIntervalMs) * time.Millisecond)
@@ -1007,11 +1136,11 @@ func reconcileInitialCNSState(ctx context.Context, cli nodeNetworkConfigGetter,
 }
 
 // InitializeCRDState builds and starts the CRD controllers.
-func InitializeCRDState(ctx context.Context, httpRestService cns.HTTPService, cnsconfig *configuration.CNSConfig) error {
+func InitializeCRDState(ctx context.Context, httpRestService cns.HTTPService, cnsconfig *configuration.CNSConfig, healthChecks *healthserver.Checks) error {
 	// convert interface type to implementation type
 	httpRestServiceImplementation, ok := httpRestService.(*restserver.HTTPRestService)
 	if !ok {
-		logger.Errorf("[Azure CNS] Failed to convert interface httpRestService to implementation: %v", httpRestService)
+		kubeLog.Errorf("[Azure CNS] Failed to convert interface httpRestService to implementation: %v", httpRestService)
 		return fmt.Errorf("[Azure CNS] Failed to convert interface httpRestService to implementation: %v",
 			httpRestService)
 	}
@@ -1025,7 +1154,7 @@ func InitializeCRDState(ctx context.Context, httpRestService cns.HTTPService, cn
 	// build default clientset.
 	kubeConfig, err := ctrl.GetConfig()
 	if err != nil {
-		logger.Errorf("[Azure CNS] Failed to get kubeconfig for request controller: %v", err)
+		kubeLog.Errorf("[Azure CNS] Failed to get kubeconfig for request controller: %v", err)
 		return errors.Wrap(err, "failed to get kubeconfig")
 	}
 	kubeConfig.UserAgent = fmt.Sprintf("azure-cns-%s", version)
@@ -1044,23 +1173,23 @@ func InitializeCRDState(ctx context.Context, httpRestService cns.HTTPService, cn
 	var podInfoByIPProvider cns.PodInfoByIPProvider
 	switch {
 	case cnsconfig.ManageEndpointState:
-		logger.Printf("Initializing from self managed endpoint store")
+		kubeLog.Printf("Initializing from self managed endpoint store")
 		podInfoByIPProvider, err = cnireconciler.NewCNSPodInfoProvider(httpRestServiceImplementation.EndpointStateStore) // get reference to endpoint state store from rest server
 		if err != nil {
 			if errors.Is(err, store.ErrKeyNotFound) {
-				logger.Printf("[Azure CNS] No endpoint state found, skipping initializing CNS state")
+				kubeLog.Printf("[Azure CNS] No endpoint state found, skipping initializing CNS state")
 			} else {
 				return errors.Wrap(err, "failed to create CNS PodInfoProvider")
 			}
 		}
 	case cnsconfig.InitializeFromCNI:
-		logger.Printf("Initializing from CNI")
+		kubeLog.Printf("Initializing from CNI")
 		podInfoByIPProvider, err = cnireconciler.NewCNIPodInfoProvider()
 		if err != nil {
 			return errors.Wrap(err, "failed to create CNI PodInfoProvider")
 		}
 	default:
-		logger.Printf("Initializing from Kubernetes")
+		kubeLog.Printf("Initializing from Kubernetes")
 		podInfoByIPProvider = cns.PodInfoByIPProviderFunc(func() (map[string]cns.PodInfo, error) {
 			pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{ //nolint:govet // ignore err shadow
 				FieldSelector: "spec.nodeName=" + nodeName,
@@ -1088,11 +1217,16 @@ func InitializeCRDState(ctx context.Context, httpRestService cns.HTTPService, cn
 	scopedcli := nncctrl.NewScopedClient(nnccli, types.NamespacedName{Namespace: "kube-system", Name: nodeName})
 
 	clusterSubnetStateChan := make(chan v1alpha1.ClusterSubnetState)
+	// notifications of IP state changes are coalesced while the pool monitor is busy reconciling
+	ipStateChan := make(chan struct{}, 1)
+	httpRestServiceImplementation.NotifyIPStateChanges(ipStateChan)
 	// initialize the ipam pool monitor
 	poolOpts := ipampool.Options{
-		RefreshDelay: poolIPAMRefreshRateInMilliseconds * time.Millisecond,
+		RefreshDelay:     poolIPAMRefreshRateInMilliseconds * time.Millisecond,
+		PublishIPAMState: cnsconfig.EnableIPAMStatePublishing,
 	}
-	poolMonitor := ipampool.NewMonitor(httpRestServiceImplementation, scopedcli, clusterSubnetStateChan, &poolOpts)
+	poolMonitor := ipampool.NewMonitor(httpRestServiceImplementation, scopedcli, clusterSubnetStateChan, ipStateChan, &poolOpts)
+	healthChecks.AddReadyzCheck("ipam-pool-monitor", poolMonitor.InitializedCheck)
 	httpRestServiceImplementation.IPAMPoolMonitor = poolMonitor
 
 	// reconcile initial CNS state from CNI or apiserver.
@@ -1103,7 +1237,7 @@ func InitializeCRDState(ctx context.Context, httpRestService cns.HTTPService, cn
 		return errors.Wrap(err, "failed to provide PodInfoByIP")
 	}
 	if len(podInfoByIP) > 0 {
-		logger.Printf("Reconciling initial CNS state as PodInfoByIP is not empty: %d", len(podInfoByIP))
+		kubeLog.Printf("Reconciling initial CNS state as PodInfoByIP is not empty: %d", len(podInfoByIP))
 
 		// apiserver nnc might not be registered or api server might be down and crashloop backof puts us outside of 5-10 minutes we have for
 		// aks addons to come up so retry a bit more aggresively here.
@@ -1111,28 +1245,28 @@ func InitializeCRDState(ctx context.Context, httpRestService cns.HTTPService, cn
 		attempt := 0
 		err = retry.Do(func() error {
 			attempt++
-			logger.Printf("reconciling initial CNS state attempt: %d", attempt)
+			kubeLog.Printf("reconciling initial CNS state attempt: %d", attempt)
 			err = reconcileInitialCNSState(ctx, scopedcli, httpRestServiceImplementation, podInfoByIPProvider)
 			if err != nil {
-				logger.Errorf("failed to reconcile initial CNS state, attempt: %d err: %v", attempt, err)
+				kubeLog.Errorf("failed to reconcile initial CNS state, attempt: %d err: %v", attempt, err)
 			}
 			return errors.Wrap(err, "failed to initialize CNS state")
 		}, retry.Context(ctx), retry.Delay(initCNSInitalDelay), retry.MaxDelay(time.Minute))
 		if err != nil {
 			return err
 		}
-		logger.Printf("reconciled initial CNS state after %d attempts", attempt)
+		kubeLog.Printf("reconciled initial CNS state after %d attempts", attempt)
 	}
 
 	// start the pool Monitor before the Reconciler, since it needs to be ready to receive an
 	// NodeNetworkConfig update by the time the Reconciler tries to send it.
 	go func() {
-		logger.Printf("Starting IPAM Pool Monitor")
+		poolLog.Printf("Starting IPAM Pool Monitor")
 		if e := poolMonitor.Start(ctx); e != nil {
-			logger.Errorf("[Azure CNS] Failed to start pool monitor with err: %v", e)
+			poolLog.Errorf("[Azure CNS] Failed to start pool monitor with err: %v", e)
 		}
 	}()
-	logger.Printf("initialized and started IPAM pool monitor")
+	poolLog.Printf("initialized and started IPAM pool monitor")
 
 	// the nodeScopedCache sets Selector options on the Manager cache which are used
 	// to perform *server-side* filtering of the cached objects. This is very important
@@ -1179,6 +1313,7 @@ func InitializeCRDState(ctx context.Context, httpRestService cns.HTTPService, cn
 	if err := nncReconciler.SetupWithManager(manager, node); err != nil { //nolint:govet // intentional shadow
 		return errors.Wrapf(err, "failed to setup nnc reconciler with manager")
 	}
+	healthChecks.AddReadyzCheck("nnc-reconciler", nncReconciler.StartedCheck)
 
 	if cnsconfig.EnableSubnetScarcity {
 		// ClusterSubnetState reconciler
@@ -1190,34 +1325,34 @@ func InitializeCRDState(ctx context.Context, httpRestService cns.HTTPService, cn
 
 	// adding some routes to the root service mux
 	mux := httpRestServiceImplementation.Listener.GetMux()
-	mux.Handle("/readyz", http.StripPrefix("/readyz", &healthz.Handler{}))
+	mux.Handle(cns.PathReadyz, http.StripPrefix(cns.PathReadyz, healthChecks.ReadyzHandler()))
 	if cnsconfig.EnablePprof {
 		// add pprof endpoints
-		mux.Handle("/debug/pprof/allocs", pprof.Handler("allocs"))
-		mux.Handle("/debug/pprof/block", pprof.Handler("block"))
-		mux.Handle("/debug/pprof/goroutine", pprof.Handler("goroutine"))
-		mux.Handle("/debug/pprof/heap", pprof.Handler("heap"))
-		mux.Handle("/debug/pprof/mutex", pprof.Handler("mutex"))
-		mux.Handle("/debug/pprof/threadcreate", pprof.Handler("threadcreate"))
-		mux.HandleFunc("/debug/pprof/", pprof.Index)
-		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
-		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
-		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
-		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+		mux.Handle(cns.PathDebugPprof+"/allocs", pprof.Handler("allocs"))
+		mux.Handle(cns.PathDebugPprof+"/block", pprof.Handler("block"))
+		mux.Handle(cns.PathDebugPprof+"/goroutine", pprof.Handler("goroutine"))
+		mux.Handle(cns.PathDebugPprof+"/heap", pprof.Handler("heap"))
+		mux.Handle(cns.PathDebugPprof+"/mutex", pprof.Handler("mutex"))
+		mux.Handle(cns.PathDebugPprof+"/threadcreate", pprof.Handler("threadcreate"))
+		mux.HandleFunc(cns.PathDebugPprof+"/", pprof.Index)
+		mux.HandleFunc(cns.PathDebugPprof+"/cmdline", pprof.Cmdline)
+		mux.HandleFunc(cns.PathDebugPprof+"/profile", pprof.Profile)
+		mux.HandleFunc(cns.PathDebugPprof+"/symbol", pprof.Symbol)
+		mux.HandleFunc(cns.PathDebugPprof+"/trace", pprof.Trace)
 	}
 
 	// Start the Manager which starts the reconcile loop.
 	// The Reconciler will send an initial NodeNetworkConfig update to the PoolMonitor, starting the
 	// Monitor's internal loop.
 	go func() {
-		logger.Printf("Starting NodeNetworkConfig reconciler.")
+		kubeLog.Printf("Starting NodeNetworkConfig reconciler.")
 		for {
 			if err := manager.Start(ctx); err != nil {
-				logger.Errorf("[Azure CNS] Failed to start request controller: %v", err)
+				kubeLog.Errorf("[Azure CNS] Failed to start request controller: %v", err)
 				// retry to start the request controller
 				// todo: add a CNS metric to count # of failures
 			} else {
-				logger.Printf("exiting NodeNetworkConfig reconciler")
+				kubeLog.Printf("exiting NodeNetworkConfig reconciler")
 				return
 			}
 
@@ -1225,15 +1360,15 @@ func InitializeCRDState(ctx context.Context, httpRestService cns.HTTPService, cn
 			time.Sleep(time.Second)
 		}
 	}()
-	logger.Printf("initialized NodeNetworkConfig reconciler")
+	kubeLog.Printf("initialized NodeNetworkConfig reconciler")
 	// wait for the Reconciler to run once on a NNC that was made for this Node
 	if started := nncReconciler.Started(ctx); !started {
 		return errors.Errorf("context cancelled while waiting for reconciler start")
 	}
-	logger.Printf("started NodeNetworkConfig reconciler")
+	kubeLog.Printf("started NodeNetworkConfig reconciler")
 
 	go func() {
-		logger.Printf("starting SyncHostNCVersion loop")
+		nmaLog.Printf("starting SyncHostNCVersion loop")
 		// Periodically poll vfp programmed NC version from NMAgent
 		tickerChannel := time.Tick(time.Duration(cnsconfig.SyncHostNCVersionIntervalMs) * time.Millisecond)
 		for {
@@ -1243,12 +1378,81 @@ func InitializeCRDState(ctx context.Context, httpRestService cns.HTTPService, cn
 				httpRestServiceImplementation.SyncHostNCVersion(timedCtx, cnsconfig.ChannelMode)
 				cancel()
 			case <-ctx.Done():
-				logger.Printf("exiting SyncHostNCVersion")
+				nmaLog.Printf("exiting SyncHostNCVersion")
 				return
 			}
 		}
 	}()
-	logger.Printf("initialized and started SyncHostNCVersion loop")
+	nmaLog.Printf("initialized and started SyncHostNCVersion loop")
+
+	if cnsconfig.EnableIPLeakGC {
+		// the live Pods are always listed from the apiserver, since the other PodInfoProviders are built
+		// from the CNS and CNI state which the leaked IPs are in. They are watched through a node-scoped
+		// informer so that each GC pass reads the cache instead of listing the Pods again.
+		podInformerFactory := informers.NewSharedInformerFactoryWithOptions(clientset, 0,
+			informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+				opts.FieldSelector = fields.OneTermEqualSelector("spec.nodeName", nodeName).String()
+			}))
+		podInformer := podInformerFactory.Core().V1().Pods()
+		podLister := podInformer.Lister()
+		podInformerFactory.Start(ctx.Done())
+		livePods := restserver.LivePodsProviderFunc(func(context.Context) (map[string]struct{}, error) {
+			// an unsynced cache would report live Pods as gone and their IPs as leaked.
+			if !podInformer.Informer().HasSynced() {
+				return nil, errors.New("Pod cache for LivePodsProvider has not synced")
+			}
+			pods, err := podLister.List(labels.Everything()) //nolint:govet // ignore err shadow
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to list Pods for LivePodsProvider")
+			}
+			podKeys := make(map[string]struct{}, len(pods))
+			for i := range pods {
+				podKeys[pods[i].Namespace+"/"+pods[i].Name] = struct{}{}
+			}
+			return podKeys, nil
+		})
+		broadcaster := record.NewBroadcaster()
+		broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: clientset.CoreV1().Events("")})
+		recorder := broadcaster.NewRecorder(kubescheme.Scheme, corev1.EventSource{Component: name, Host: nodeName})
+		ipLeakGC := restserver.NewIPLeakGC(httpRestServiceImplementation, livePods, recorder, node,
+			time.Duration(cnsconfig.IPLeakGCIntervalSecs)*time.Second, time.Duration(cnsconfig.IPLeakGCGracePeriodSecs)*time.Second)
+		go ipLeakGC.Start(ctx)
+		kubeLog.Printf("initialized and started IP leak GC")
+	}
 
 	return nil
 }
+
+// setUnixSocketConfig configures the unix socket listener of the CNS API from its settings.
+// The mode also applies to the gRPC socket, so it is set even if the API has no unix socket.
+// The listener can't be exclusive if azure-ipam is installed in the cniPath, since it would lose CNS without TCP.
+func setUnixSocketConfig(config *common.ServiceConfig, settings configuration.UnixSocketSettings, cniPath string) error {
+	mode, err := strconv.ParseUint(settings.Mode, 8, 32)
+	if err != nil {
+		return errors.Wrapf(err, "invalid unix socket mode %s", settings.Mode)
+	}
+	config.UnixSocketMode = os.FileMode(mode)
+	if settings.Path == "" {
+		if settings.Exclusive {
+			return errors.New("the unix socket listener can't be exclusive without a path")
+		}
+		return nil
+	}
+	if settings.Exclusive {
+		if _, err := os.Stat(filepath.Join(cniPath, azureIPAMBinary)); err == nil {
+			return errors.Errorf("the unix socket listener can't be exclusive, since %s in %s only reaches CNS over TCP", azureIPAMBinary, cniPath)
+		}
+	}
+	config.UnixSocketPath = settings.Path
+	config.UnixSocketOnly = settings.Exclusive
+	return nil
+}
+
+// unixSocketURL returns the URL of the unix socket listener of the CNS API for its clients, such as CNI, or an empty
+// URL for the default TCP listener if the socket is disabled.
+func unixSocketURL(settings configuration.UnixSocketSettings) string {
+	if settings.Path == "" {
+		return ""
+	}
+	return "unix://" + settings.Path
+}
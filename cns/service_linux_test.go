@@ -0,0 +1,28 @@
+package cns
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/cns/common"
+	"github.com/Azure/azure-container-networking/cns/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestStartGRPCListenerSocketMode(t *testing.T) {
+	logger.InitLogger("cns-test", 0, 0, t.TempDir()+"/")
+	service, err := NewService("cns", "test", "", nil)
+	require.NoError(t, err)
+	socket := filepath.Join(t.TempDir(), "cns-grpc.sock")
+	config := &common.ServiceConfig{GRPCURL: "unix://" + socket, UnixSocketMode: 0o660, ErrChan: make(chan error, 1)}
+
+	require.NoError(t, service.StartGRPCListener(config, func(grpc.ServiceRegistrar) {}))
+	defer service.grpcServer.Stop()
+
+	info, err := os.Stat(socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o660), info.Mode().Perm())
+}
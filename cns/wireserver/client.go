@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/xml"
+	"fmt"
 	"io"
 	"net/http"
 
@@ -11,7 +12,10 @@ import (
 	"github.com/pkg/errors"
 )
 
-const hostQueryURL = "http://168.63.129.16/machine/plugins?comp=nmagent&type=getinterfaceinfov1"
+const (
+	defaultHost     = "168.63.129.16"
+	hostQueryURLFmt = "http://%s/machine/plugins?comp=nmagent&type=getinterfaceinfov1"
+)
 
 type GetNetworkContainerOpts struct {
 	NetworkContainerID string
@@ -26,13 +30,19 @@ type do interface {
 
 type Client struct {
 	HTTPClient do
+	// Host is the host, and optional port, of the wireserver. It defaults to the well-known wireserver IP.
+	Host string
 }
 
 // GetInterfaces queries interfaces from the wireserver.
 func (c *Client) GetInterfaces(ctx context.Context) (*GetInterfacesResult, error) {
 	logger.Printf("[Azure CNS] GetPrimaryInterfaceInfoFromHost")
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, hostQueryURL, nil)
+	host := c.Host
+	if host == "" {
+		host = defaultHost
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(hostQueryURLFmt, host), nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to construct request")
 	}
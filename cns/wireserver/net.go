@@ -27,7 +27,7 @@ func GetPrimaryInterfaceFromResult(res *GetInterfacesResult) (*InterfaceInfo, er
 
 		// get the first subnet
 		s := i.IPSubnet[0]
-		gw, err := calculateGatewayIP(s.Prefix)
+		gw, err := gatewayIP(s)
 		if err != nil {
 			return nil, err
 		}
@@ -49,6 +49,18 @@ func GetPrimaryInterfaceFromResult(res *GetInterfacesResult) (*InterfaceInfo, er
 	return nil, ErrNoPrimaryInterface
 }
 
+// gatewayIP returns the gateway of the subnet, which is calculated from its prefix unless it is set.
+func gatewayIP(s Subnet) (net.IP, error) {
+	if s.Gateway == "" {
+		return calculateGatewayIP(s.Prefix)
+	}
+	gw := net.ParseIP(s.Gateway)
+	if gw == nil {
+		return nil, errors.Errorf("received malformed gateway %q from host", s.Gateway)
+	}
+	return gw, nil
+}
+
 // calculateGatewayIP parses the passed CIDR string and returns the first IP in the range.
 func calculateGatewayIP(cidr string) (net.IP, error) {
 	_, subnet, err := net.ParseCIDR(cidr)
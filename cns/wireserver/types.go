@@ -15,7 +15,10 @@ type Address struct {
 }
 
 type Subnet struct {
-	Prefix    string `xml:"Prefix,attr"`
+	Prefix string `xml:"Prefix,attr"`
+	// Gateway is not returned by wireserver, whose gateway is the first IP of the prefix. It is set by host metadata
+	// sources which know the gateway of the subnet.
+	Gateway   string `xml:"-"`
 	IPAddress []Address
 }
 
@@ -4,12 +4,14 @@
 package common
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 
 	"github.com/Azure/azure-container-networking/log"
 	"github.com/pkg/errors"
@@ -24,7 +26,9 @@ type Listener struct {
 	active       bool
 	listener     net.Listener
 	tlsListener  net.Listener
+	unixListener net.Listener
 	mux          *http.ServeMux
+	middlewares  []func(http.Handler) http.Handler
 }
 
 // NewListener creates a new Listener.
@@ -43,8 +47,9 @@ func NewListener(u *url.URL) (*Listener, error) {
 // StartTLS creates the listener socket and starts the HTTPS server.
 func (l *Listener) StartTLS(errChan chan<- error, tlsConfig *tls.Config, address string) error {
 	server := http.Server{
-		TLSConfig: tlsConfig,
-		Handler:   l.mux,
+		TLSConfig:   tlsConfig,
+		Handler:     l.handler(),
+		ConnContext: connContext,
 	}
 
 	// listen on a separate endpoint for secure tls connections
@@ -77,9 +82,62 @@ func (l *Listener) Start(errChan chan<- error) error {
 	l.listener = list
 	log.Printf("[Listener] Started listening on %s.", l.localAddress)
 
+	server := http.Server{
+		Handler:     l.handler(),
+		ConnContext: connContext,
+	}
+
 	// Launch goroutine for servicing requests.
 	go func() {
-		errChan <- http.Serve(l.listener, l.mux)
+		errChan <- server.Serve(l.listener)
+	}()
+
+	l.active = true
+	return nil
+}
+
+// ListenUnix creates a unix socket with the file mode, replacing the socket left behind by a previous run.
+// The mode restricts which local users may connect to the socket.
+func ListenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "failed to remove stale socket %s", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil { //nolint:gomnd // the socket's mode restricts access
+		return nil, errors.Wrapf(err, "failed to create the directory of socket %s", path)
+	}
+
+	list, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to listen on %s", path)
+	}
+	// the socket is created with the umask, which only its owner can connect with by default, until it is changed
+	if err := os.Chmod(path, mode); err != nil {
+		_ = list.Close()
+		return nil, errors.Wrapf(err, "failed to set the mode of socket %s", path)
+	}
+	return list, nil
+}
+
+// StartUnix creates a unix socket with the file mode and starts the HTTP server on it, serving the same handlers as
+// the other listeners. The mode restricts which local users may connect to the socket.
+func (l *Listener) StartUnix(errChan chan<- error, path string, mode os.FileMode) error {
+	list, err := ListenUnix(path, mode)
+	if err != nil {
+		log.Printf("[Listener] Failed to listen on unix socket: %+v", err)
+		return err
+	}
+
+	l.unixListener = list
+	log.Printf("[Listener] Started listening on unix socket %s.", path)
+
+	server := http.Server{
+		Handler:     l.handler(),
+		ConnContext: connContext,
+	}
+
+	// Launch goroutine for servicing requests on the socket.
+	go func() {
+		errChan <- server.Serve(l.unixListener)
 	}()
 
 	l.active = true
@@ -95,13 +153,20 @@ func (l *Listener) Stop() {
 	l.active = false
 
 	// Stop servicing requests.
-	_ = l.listener.Close()
+	if l.listener != nil {
+		_ = l.listener.Close()
+	}
 
 	if l.tlsListener != nil {
 		// Stop servicing requests on secure listener
 		_ = l.tlsListener.Close()
 	}
 
+	if l.unixListener != nil {
+		// Stop servicing requests on the unix socket, which is deleted when it is closed
+		_ = l.unixListener.Close()
+	}
+
 	// Delete the unix socket.
 	if l.protocol == "unix" {
 		_ = os.Remove(l.localAddress)
@@ -125,6 +190,57 @@ func (l *Listener) AddEndpoint(endpoint string) {
 	l.endpoints = append(l.endpoints, endpoint)
 }
 
+// Use wraps the handlers of the listener with the middleware, such as to trace requests.
+// Middlewares must be added before the listener is started, and the last one added serves requests first.
+func (l *Listener) Use(middleware func(http.Handler) http.Handler) {
+	l.middlewares = append(l.middlewares, middleware)
+}
+
+// handler returns the mux wrapped with the middlewares.
+func (l *Listener) handler() http.Handler {
+	var h http.Handler = l.mux
+	for _, middleware := range l.middlewares {
+		h = middleware(h)
+	}
+	return h
+}
+
+var errPeerCredentialsNotSupported = errors.New("peer credentials are not supported on this platform")
+
+type peerUIDKey struct{}
+
+// connContext adds the UID of the peer of unix socket connections to the context of their requests.
+func connContext(ctx context.Context, c net.Conn) context.Context {
+	uid, ok := PeerUID(c)
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, peerUIDKey{}, uid)
+}
+
+// PeerUID returns the UID of the process connected to a unix socket connection.
+// It returns false for other connections and on platforms without peer credentials.
+func PeerUID(c net.Conn) (uint32, bool) {
+	uc, ok := c.(*net.UnixConn)
+	if !ok {
+		return 0, false
+	}
+	uid, err := peerUID(uc)
+	if err != nil {
+		if !errors.Is(err, errPeerCredentialsNotSupported) {
+			log.Printf("[Listener] Failed to get the peer of %s: %v", uc.LocalAddr(), err)
+		}
+		return 0, false
+	}
+	return uid, true
+}
+
+// PeerUIDFromContext returns the UID of the process which sent a request over a unix socket.
+func PeerUIDFromContext(ctx context.Context) (uint32, bool) {
+	uid, ok := ctx.Value(peerUIDKey{}).(uint32)
+	return uid, ok
+}
+
 // AddHandler registers a protocol handler.
 func (l *Listener) AddHandler(path string, handler http.HandlerFunc) {
 	l.mux.HandleFunc(path, handler)
@@ -0,0 +1,27 @@
+package common
+
+import (
+	"net"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// peerUID returns the UID of the process connected to the unix socket.
+func peerUID(c *net.UnixConn) (uint32, error) {
+	raw, err := c.SyscallConn()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get raw connection")
+	}
+	var cred *unix.Ucred
+	var credErr error
+	if err := raw.Control(func(fd uintptr) {
+		cred, credErr = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED)
+	}); err != nil {
+		return 0, errors.Wrap(err, "failed to control raw connection")
+	}
+	if credErr != nil {
+		return 0, errors.Wrap(credErr, "failed to get peer credentials")
+	}
+	return cred.Uid, nil
+}
@@ -0,0 +1,8 @@
+package common
+
+import "net"
+
+// peerUID is not supported on Windows, which has no UIDs.
+func peerUID(*net.UnixConn) (uint32, error) {
+	return 0, errPeerCredentialsNotSupported
+}
@@ -22,6 +22,8 @@ import (
 // +kubebuilder:printcolumn:name="Subnet CIDR",type=string,JSONPath=`.status.networkContainers[*].subnetAddressSpace`
 // +kubebuilder:printcolumn:name="NC ID",type=string,JSONPath=`.status.networkContainers[*].id`
 // +kubebuilder:printcolumn:name="NC Version",type=string,JSONPath=`.status.networkContainers[*].version`
+// +kubebuilder:printcolumn:name="Available IPs",type=string,priority=1,JSONPath=`.status.ipam.ipCounts.Available`
+// +kubebuilder:printcolumn:name="Pending Release IPs",type=string,priority=1,JSONPath=`.status.ipam.ipCounts.PendingRelease`
 type NodeNetworkConfig struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -61,6 +63,29 @@ type NodeNetworkConfigStatus struct {
 	Scaler            Scaler             `json:"scaler,omitempty"`
 	Status            Status             `json:"status,omitempty"`
 	NetworkContainers []NetworkContainer `json:"networkContainers,omitempty"`
+	// IPAM is published by CNS and is not written by the request controller.
+	IPAM *IPAMState `json:"ipam,omitempty"`
+}
+
+// IPAMState summarizes the IP pool of the node as seen by CNS
+type IPAMState struct {
+	// IPCounts is the number of IPs in each CNS IP state (Available, Assigned, PendingRelease, PendingProgramming)
+	IPCounts map[string]int64 `json:"ipCounts,omitempty"`
+	// AssignedIPs maps each IP assigned to a Pod to the Pod's namespace/name
+	AssignedIPs map[string]string `json:"assignedIPs,omitempty"`
+	// LastScaleEvent is the last change CNS made to the requested IP count
+	LastScaleEvent *ScaleEvent `json:"lastScaleEvent,omitempty"`
+	// LastError is the last error CNS encountered while scaling the IP pool
+	LastError string `json:"lastError,omitempty"`
+	// LastUpdateTime is when CNS published this state
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+// ScaleEvent is a change of the requested IP count
+type ScaleEvent struct {
+	Time                     metav1.Time `json:"time,omitempty"`
+	PreviousRequestedIPCount int64       `json:"previousRequestedIPCount,omitempty"`
+	RequestedIPCount         int64       `json:"requestedIPCount,omitempty"`
 }
 
 // Scaler groups IP request params together
@@ -9,6 +9,41 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPAMState) DeepCopyInto(out *IPAMState) {
+	*out = *in
+	if in.IPCounts != nil {
+		in, out := &in.IPCounts, &out.IPCounts
+		*out = make(map[string]int64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.AssignedIPs != nil {
+		in, out := &in.AssignedIPs, &out.AssignedIPs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LastScaleEvent != nil {
+		in, out := &in.LastScaleEvent, &out.LastScaleEvent
+		*out = new(ScaleEvent)
+		(*in).DeepCopyInto(*out)
+	}
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPAMState.
+func (in *IPAMState) DeepCopy() *IPAMState {
+	if in == nil {
+		return nil
+	}
+	out := new(IPAMState)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IPAssignment) DeepCopyInto(out *IPAssignment) {
 	*out = *in
@@ -134,6 +169,11 @@ func (in *NodeNetworkConfigStatus) DeepCopyInto(out *NodeNetworkConfigStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.IPAM != nil {
+		in, out := &in.IPAM, &out.IPAM
+		*out = new(IPAMState)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkConfigStatus.
@@ -146,6 +186,22 @@ func (in *NodeNetworkConfigStatus) DeepCopy() *NodeNetworkConfigStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScaleEvent) DeepCopyInto(out *ScaleEvent) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaleEvent.
+func (in *ScaleEvent) DeepCopy() *ScaleEvent {
+	if in == nil {
+		return nil
+	}
+	out := new(ScaleEvent)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Scaler) DeepCopyInto(out *Scaler) {
 	*out = *in
@@ -2,6 +2,7 @@ package nodenetworkconfig
 
 import (
 	"context"
+	"encoding/json"
 	"reflect"
 
 	"github.com/Azure/azure-container-networking/crd"
@@ -129,6 +130,38 @@ func (c *Client) UpdateSpec(ctx context.Context, key types.NamespacedName, spec
 	return nnc, nil
 }
 
+// PatchIPAMState replaces the IPAM state in the status of the NodeNetworkConfig specified by the NamespacedName.
+// Only the IPAM state is patched, so the rest of the status (owned by the request controller) is untouched.
+func (c *Client) PatchIPAMState(ctx context.Context, key types.NamespacedName, state *v1alpha.IPAMState) error {
+	// a JSON patch replaces the whole IPAM state, while a merge patch would keep map entries which were removed
+	patch, err := json.Marshal([]map[string]interface{}{
+		{"op": "add", "path": "/status/ipam", "value": state},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal ipam state patch")
+	}
+	err = c.cli.Status().Patch(ctx, genPatchSkel(key), client.RawPatch(types.JSONPatchType, patch))
+	if apierrors.IsInvalid(err) {
+		// the JSON patch can't add the IPAM state to an NNC without a status yet,
+		// in which case a merge patch creates the status and has no removed map entries to keep
+		err = c.mergePatchIPAMState(ctx, key, state)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to patch nnc status")
+	}
+	return nil
+}
+
+func (c *Client) mergePatchIPAMState(ctx context.Context, key types.NamespacedName, state *v1alpha.IPAMState) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{"ipam": state},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal ipam state merge patch")
+	}
+	return errors.Wrap(c.cli.Status().Patch(ctx, genPatchSkel(key), client.RawPatch(types.MergePatchType, patch)), "failed to merge patch")
+}
+
 // SetOwnerRef sets the owner of the NodeNetworkConfig to the given object, using HTTP Patch
 func (c *Client) SetOwnerRef(ctx context.Context, key types.NamespacedName, owner metav1.Object, fieldManager string) (*v1alpha.NodeNetworkConfig, error) {
 	obj := genPatchSkel(key)
@@ -89,6 +89,23 @@ func (c *CertRefresher) Refresh(ctx context.Context, interval time.Duration) err
 	}
 }
 
+// CertSource adapts a CertRefresher to a certificate source which refreshes the certificate at a fixed interval
+// when it runs, so that Key Vault can serve the certificates of TLS servers like any other source.
+type CertSource struct {
+	*CertRefresher
+	interval time.Duration
+}
+
+// NewCertSource returns a CertSource for the CertRefresher, refreshing at the interval provided.
+func NewCertSource(c *CertRefresher, interval time.Duration) *CertSource {
+	return &CertSource{CertRefresher: c, interval: interval}
+}
+
+// Run refreshes the certificate until the context is done or refreshing fails.
+func (s *CertSource) Run(ctx context.Context) error {
+	return s.Refresh(ctx, s.interval)
+}
+
 // refresh will attempt to fetch the latest version of a certificate, up until the current one expires.
 func (c *CertRefresher) refresh(ctx context.Context) error {
 	certExpires := c.cert.Leaf.NotAfter
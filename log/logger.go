@@ -235,6 +235,18 @@ func (logger *Logger) Logf(format string, args ...interface{}) {
 	logger.mutex.Unlock()
 }
 
+// Write writes p to the log target as is, without the prefix of the formatted logs, such as for the entries
+// of a structured logger.
+func (logger *Logger) Write(p []byte) (int, error) {
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+	if logger.callCount%rotationCheckFrq == 0 {
+		logger.rotate()
+	}
+	logger.callCount++
+	return logger.l.Writer().Write(p) //nolint:wrapcheck // the writer of an io.Writer
+}
+
 // Printf logs a formatted string at info level.
 func (logger *Logger) Printf(format string, args ...interface{}) {
 	if logger.level < LevelInfo {
@@ -8,13 +8,19 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strconv"
 	"time"
 
 	"github.com/Azure/azure-container-networking/nmagent/internal"
+	"github.com/Azure/azure-container-networking/otlp"
 	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
 )
 
+const defaultCircuitBreakerOpenDuration = 30 * time.Second
+
 // NewClient returns an initialized Client using the provided configuration.
 func NewClient(c Config) (*Client, error) {
 	if err := c.Validate(); err != nil {
@@ -23,9 +29,7 @@ func NewClient(c Config) (*Client, error) {
 
 	client := &Client{
 		httpClient: &http.Client{
-			Transport: &internal.WireserverTransport{
-				Transport: http.DefaultTransport,
-			},
+			Transport: newTransport(c),
 		},
 		host:      c.Host,
 		port:      c.Port,
@@ -39,6 +43,63 @@ func NewClient(c Config) (*Client, error) {
 	return client, nil
 }
 
+// newTransport wraps the WireserverTransport in the rate limiter and circuit
+// breaker of the config. The circuit breaker is outermost, so that rejected
+// requests don't take tokens from the rate limiter. Requests are traced around
+// all of them, so that their spans include the waits and rejections.
+func newTransport(c Config) http.RoundTripper {
+	var transport http.RoundTripper = &internal.WireserverTransport{
+		Transport: http.DefaultTransport,
+	}
+
+	if c.RateLimit > 0 {
+		burst := c.RateBurst
+		if burst < 1 {
+			burst = 1
+		}
+		transport = &internal.RateLimiter{
+			Transport: transport,
+			Limiter:   rate.NewLimiter(rate.Limit(c.RateLimit), burst),
+			OnWait: func(d time.Duration) {
+				rateLimiterWait.Observe(d.Seconds())
+			},
+		}
+	}
+
+	if c.CircuitBreakerThreshold > 0 {
+		log := c.Logger
+		if log == nil {
+			log = zap.NewNop()
+		}
+		openDuration := c.CircuitBreakerOpenDuration
+		if openDuration <= 0 {
+			openDuration = defaultCircuitBreakerOpenDuration
+		}
+		transport = &internal.CircuitBreaker{
+			Transport:        transport,
+			FailureThreshold: c.CircuitBreakerThreshold,
+			OpenDuration:     openDuration,
+			OnStateChange: func(state internal.CircuitState) {
+				recordCircuitState(state)
+				log.Warn("circuit breaker changed state", zap.Stringer("state", state))
+			},
+		}
+	}
+
+	return &otlp.Transport{
+		Base:     transport,
+		SpanName: spanName,
+	}
+}
+
+// authTokenPath matches the authentication tokens in the paths of NMAgent requests.
+var authTokenPath = regexp.MustCompile(`/authenticationToken/[^/]+`)
+
+// spanName names the span of a request to NMAgent by its path, without the authentication token.
+func spanName(req *http.Request) string {
+	return "NMAgent " + req.Method + " " + authTokenPath.ReplaceAllString(req.URL.Path, "/authenticationToken/{token}")
+}
+
 // Client is an agent for exchanging information with NMAgent.
 type Client struct {
 	httpClient *http.Client
@@ -0,0 +1,44 @@
+package nmagent
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSpanName(t *testing.T) {
+	tests := []struct {
+		name string
+		req  Request
+		exp  string
+	}{
+		{
+			"without token",
+			&SupportedAPIsRequest{},
+			"NMAgent GET /GetSupportedApis",
+		},
+		{
+			"put nc",
+			&PutNetworkContainerRequest{PrimaryAddress: "10.0.0.4", ID: "nc1", AuthenticationToken: "secret"},
+			"NMAgent POST /NetworkManagement/interfaces/10.0.0.4/networkContainers/nc1/authenticationToken/{token}/api-version/1",
+		},
+		{
+			"delete nc",
+			DeleteContainerRequest{PrimaryAddress: "10.0.0.4", NCID: "nc1", AuthenticationToken: "secret"},
+			"NMAgent POST /NetworkManagement/interfaces/10.0.0.4/networkContainers/nc1/authenticationToken/{token}/api-version/1/method/DELETE",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequest(test.req.Method(), "http://localhost"+test.req.Path(), http.NoBody)
+			if err != nil {
+				t.Fatal("unexpected error building request: err:", err)
+			}
+
+			if got := spanName(req); got != test.exp {
+				t.Error("unexpected span name: got:", got, "exp:", test.exp)
+			}
+		})
+	}
+}
@@ -5,9 +5,11 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Azure/azure-container-networking/nmagent/internal"
 	"github.com/pkg/errors"
+	"go.uber.org/zap"
 )
 
 // Config is a configuration for an NMAgent Client.
@@ -22,6 +24,23 @@ type Config struct {
 	// Optional Config //
 	/////////////////////
 	UseTLS bool // forces all connections to use TLS
+
+	// RateLimit is the number of requests per second which the client sends to
+	// Wireserver, in bursts of up to RateBurst requests (1 if unset). Requests
+	// wait for their turn. A RateLimit of 0 disables rate limiting.
+	RateLimit float64
+	RateBurst int
+
+	// CircuitBreakerThreshold is the number of consecutive failed requests
+	// after which the client stops sending requests to Wireserver for
+	// CircuitBreakerOpenDuration (30s if unset), failing them with
+	// ErrCircuitOpen instead. A CircuitBreakerThreshold of 0 disables the
+	// circuit breaker.
+	CircuitBreakerThreshold    int
+	CircuitBreakerOpenDuration time.Duration
+
+	// Logger logs the state changes of the circuit breaker. Nothing is logged if unset.
+	Logger *zap.Logger
 }
 
 // Validate reports whether this configuration is a valid configuration for a
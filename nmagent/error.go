@@ -10,6 +10,10 @@ import (
 	pkgerrors "github.com/pkg/errors"
 )
 
+// ErrCircuitOpen is returned without contacting Wireserver while the circuit
+// breaker of the Client is open.
+const ErrCircuitOpen = internal.ErrCircuitOpen
+
 // ContentError is encountered when an unexpected content type is obtained from
 // NMAgent.
 type ContentError struct {
@@ -0,0 +1,167 @@
+package internal
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	ErrCircuitOpen = Error("circuit breaker is open")
+)
+
+var _ http.RoundTripper = &CircuitBreaker{}
+
+// CircuitState is the state of a CircuitBreaker.
+type CircuitState int
+
+const (
+	// CircuitClosed sends all requests.
+	CircuitClosed CircuitState = iota
+	// CircuitHalfOpen sends a single request to probe whether the circuit can be closed.
+	CircuitHalfOpen
+	// CircuitOpen rejects all requests.
+	CircuitOpen
+)
+
+// String produces the string equivalent for the CircuitState type.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitHalfOpen:
+		return "half-open"
+	case CircuitOpen:
+		return "open"
+	default:
+		return ""
+	}
+}
+
+// CircuitBreaker is an http.RoundTripper that stops sending requests to its
+// Transport once FailureThreshold consecutive requests have failed, so that a
+// struggling Wireserver is not overwhelmed by retries. Requests are rejected
+// with ErrCircuitOpen for the OpenDuration, after which a single probe request
+// is let through: the circuit closes if it succeeds, and opens again otherwise.
+//
+// A request fails if it returns an error, or a 429 or 5xx status code.
+// Requests cancelled by their caller are not counted either way.
+type CircuitBreaker struct {
+	Transport        http.RoundTripper
+	FailureThreshold int
+	OpenDuration     time.Duration
+
+	// OnStateChange, if set, is called with the new state on every transition.
+	// It is called with the CircuitBreaker locked, so it must not call State.
+	OnStateChange func(CircuitState)
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// State returns the current state of the circuit.
+func (c *CircuitBreaker) State() CircuitState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.currentState()
+}
+
+// RoundTrip sends the request to the Transport unless the circuit is open.
+func (c *CircuitBreaker) RoundTrip(req *http.Request) (*http.Response, error) {
+	probe, err := c.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.Transport.RoundTrip(req)
+	if req.Context().Err() != nil {
+		// the caller gave up on the request, which says nothing about Wireserver
+		c.release(probe)
+		return resp, err //nolint:wrapcheck // the error of the wrapped transport is returned as is
+	}
+	c.record(probe, err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError)
+	return resp, err //nolint:wrapcheck // the error of the wrapped transport is returned as is
+}
+
+// allow reports whether a request may be sent, and whether it is the probe of a
+// half-open circuit.
+func (c *CircuitBreaker) allow() (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch c.currentState() {
+	case CircuitOpen:
+		return false, ErrCircuitOpen
+	case CircuitHalfOpen:
+		if c.probing {
+			return false, ErrCircuitOpen
+		}
+		c.probing = true
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// release lets another probe through after a probe whose result is unknown.
+func (c *CircuitBreaker) release(probe bool) {
+	if !probe {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.probing = false
+}
+
+func (c *CircuitBreaker) record(probe, failed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if probe {
+		c.probing = false
+		if failed {
+			c.open()
+		} else {
+			c.failures = 0
+			c.setState(CircuitClosed)
+		}
+		return
+	}
+	// the results of requests sent before the circuit opened don't change it
+	if c.state != CircuitClosed {
+		return
+	}
+	if !failed {
+		c.failures = 0
+		return
+	}
+	c.failures++
+	if c.failures >= c.FailureThreshold {
+		c.open()
+	}
+}
+
+func (c *CircuitBreaker) open() {
+	c.openedAt = time.Now()
+	c.setState(CircuitOpen)
+}
+
+// currentState moves an open circuit to half-open once the OpenDuration has
+// elapsed, and returns the state.
+func (c *CircuitBreaker) currentState() CircuitState {
+	if c.state == CircuitOpen && time.Since(c.openedAt) >= c.OpenDuration {
+		c.setState(CircuitHalfOpen)
+	}
+	return c.state
+}
+
+func (c *CircuitBreaker) setState(state CircuitState) {
+	if c.state == state {
+		return
+	}
+	c.state = state
+	if c.OnStateChange != nil {
+		c.OnStateChange(state)
+	}
+}
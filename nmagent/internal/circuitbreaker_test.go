@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	status := http.StatusInternalServerError
+	calls := 0
+	var transitions []CircuitState
+	cb := &CircuitBreaker{
+		Transport: &TestTripper{
+			RoundTripF: func(*http.Request) (*http.Response, error) {
+				calls++
+				return &http.Response{StatusCode: status}, nil
+			},
+		},
+		FailureThreshold: 3,
+		OpenDuration:     10 * time.Millisecond,
+		OnStateChange: func(state CircuitState) {
+			transitions = append(transitions, state)
+		},
+	}
+	do := func() error {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://wireserver", http.NoBody)
+		if err != nil {
+			t.Fatal("unexpected error creating request: err:", err)
+		}
+		_, err = cb.RoundTrip(req)
+		return err
+	}
+
+	// consecutive failures open the circuit
+	for i := 0; i < 3; i++ {
+		if err := do(); err != nil {
+			t.Fatal("unexpected error: err:", err)
+		}
+	}
+	if err := do(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatal("expected the circuit to be open: err:", err)
+	}
+	if calls != 3 {
+		t.Error("unexpected number of requests sent: got:", calls, "exp:", 3)
+	}
+
+	// a failed probe opens the circuit again
+	time.Sleep(cb.OpenDuration)
+	if got := cb.State(); got != CircuitHalfOpen {
+		t.Fatal("unexpected state: got:", got, "exp:", CircuitHalfOpen)
+	}
+	if err := do(); err != nil {
+		t.Fatal("unexpected error: err:", err)
+	}
+	if err := do(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatal("expected the circuit to be open: err:", err)
+	}
+
+	// a successful probe closes the circuit
+	status = http.StatusOK
+	time.Sleep(cb.OpenDuration)
+	if err := do(); err != nil {
+		t.Fatal("unexpected error: err:", err)
+	}
+	if got := cb.State(); got != CircuitClosed {
+		t.Fatal("unexpected state: got:", got, "exp:", CircuitClosed)
+	}
+
+	exp := []CircuitState{CircuitOpen, CircuitHalfOpen, CircuitOpen, CircuitHalfOpen, CircuitClosed}
+	if len(transitions) != len(exp) {
+		t.Fatal("unexpected transitions: got:", transitions, "exp:", exp)
+	}
+	for i := range exp {
+		if transitions[i] != exp[i] {
+			t.Fatal("unexpected transitions: got:", transitions, "exp:", exp)
+		}
+	}
+}
+
+func TestCircuitBreakerSuccessResetsFailures(t *testing.T) {
+	statuses := []int{http.StatusBadGateway, http.StatusOK, http.StatusBadGateway, http.StatusNotFound}
+	cb := &CircuitBreaker{
+		Transport: &TestTripper{
+			RoundTripF: func(*http.Request) (*http.Response, error) {
+				status := statuses[0]
+				statuses = statuses[1:]
+				return &http.Response{StatusCode: status}, nil
+			},
+		},
+		FailureThreshold: 2,
+		OpenDuration:     time.Minute,
+	}
+	for len(statuses) > 0 {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://wireserver", http.NoBody)
+		if err != nil {
+			t.Fatal("unexpected error creating request: err:", err)
+		}
+		if _, err := cb.RoundTrip(req); err != nil {
+			t.Fatal("unexpected error: err:", err)
+		}
+	}
+	if got := cb.State(); got != CircuitClosed {
+		t.Error("unexpected state: got:", got, "exp:", CircuitClosed)
+	}
+}
@@ -0,0 +1,35 @@
+package internal
+
+import (
+	"net/http"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"golang.org/x/time/rate"
+)
+
+var _ http.RoundTripper = &RateLimiter{}
+
+// RateLimiter is an http.RoundTripper that waits for a token from the token
+// bucket Limiter before sending each request to its Transport, so that all
+// the requests of a client to Wireserver are limited together.
+type RateLimiter struct {
+	Transport http.RoundTripper
+	Limiter   *rate.Limiter
+
+	// OnWait, if set, is called with the time each request waited for a token.
+	OnWait func(time.Duration)
+}
+
+// RoundTrip waits for a token, or fails if the request's context would expire
+// first, and then sends the request.
+func (r *RateLimiter) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	if err := r.Limiter.Wait(req.Context()); err != nil {
+		return nil, pkgerrors.Wrap(err, "waiting for rate limiter")
+	}
+	if r.OnWait != nil {
+		r.OnWait(time.Since(start))
+	}
+	return r.Transport.RoundTrip(req) //nolint:wrapcheck // the error of the wrapped transport is returned as is
+}
@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestRateLimiter(t *testing.T) {
+	calls := 0
+	var waits []time.Duration
+	rl := &RateLimiter{
+		Transport: &TestTripper{
+			RoundTripF: func(*http.Request) (*http.Response, error) {
+				calls++
+				return &http.Response{StatusCode: http.StatusOK}, nil
+			},
+		},
+		Limiter: rate.NewLimiter(rate.Every(time.Hour), 1),
+		OnWait: func(d time.Duration) {
+			waits = append(waits, d)
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://wireserver", http.NoBody)
+	if err != nil {
+		t.Fatal("unexpected error creating request: err:", err)
+	}
+
+	// the burst is sent right away
+	if _, err := rl.RoundTrip(req); err != nil {
+		t.Fatal("unexpected error: err:", err)
+	}
+	// the next token would arrive after the deadline of the request
+	if _, err := rl.RoundTrip(req); err == nil {
+		t.Fatal("expected an error waiting for the rate limiter")
+	}
+	if calls != 1 || len(waits) != 1 {
+		t.Error("unexpected number of requests sent: got:", calls, "waits:", len(waits), "exp:", 1)
+	}
+}
@@ -0,0 +1,44 @@
+package nmagent
+
+import (
+	"github.com/Azure/azure-container-networking/nmagent/internal"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var circuitBreakerState = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "nmagent_circuit_breaker_state",
+		Help: "State of the NMAgent client circuit breaker: 0 closed, 1 half-open, 2 open.",
+	},
+)
+
+var circuitBreakerTransitions = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "nmagent_circuit_breaker_transitions_total",
+		Help: "Count of NMAgent client circuit breaker transitions by the new state.",
+	},
+	[]string{"state"},
+)
+
+var rateLimiterWait = prometheus.NewHistogram(
+	prometheus.HistogramOpts{
+		Name: "nmagent_rate_limiter_wait_seconds",
+		Help: "Time NMAgent client requests waited for the rate limiter in seconds.",
+		//nolint:gomnd // default bucket consts
+		Buckets: prometheus.ExponentialBuckets(0.001, 2, 15), // 1 ms to ~16 seconds
+	},
+)
+
+func recordCircuitState(state internal.CircuitState) {
+	circuitBreakerState.Set(float64(state))
+	circuitBreakerTransitions.WithLabelValues(state.String()).Inc()
+}
+
+func init() {
+	metrics.Registry.MustRegister(
+		circuitBreakerState,
+		circuitBreakerTransitions,
+		rateLimiterWait,
+	)
+}
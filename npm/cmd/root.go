@@ -49,6 +49,7 @@ func NewRootCmd() *cobra.Command {
 	rootCmd.AddCommand(startCmd)
 
 	rootCmd.AddCommand(newDebugCmd())
+	rootCmd.AddCommand(newSimulateCmd())
 
 	return rootCmd
 }
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Azure/azure-container-networking/npm/pkg/simulator"
+	npmerrors "github.com/Azure/azure-container-networking/npm/util/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	expectAllowed = "allowed"
+	expectDenied  = "denied"
+)
+
+var (
+	errManifestsDirNotSpecified = errors.New("manifests directory not specified")
+	errInvalidExpectation       = fmt.Errorf("expect must be either %q or %q", expectAllowed, expectDenied)
+	errUnexpectedVerdict        = errors.New("verdict does not match expectation")
+)
+
+func newSimulateCmd() *cobra.Command {
+	simulateCmd := &cobra.Command{
+		Use:   "simulate",
+		Short: "Simulate NetworkPolicy enforcement for Kubernetes manifests without a cluster",
+		Long: "Runs the NPM v2 controllers and Linux dataplane against an in-memory iptables/ipset " +
+			"programmed from the Namespace, Pod, and NetworkPolicy manifests in a directory, " +
+			"then reports whether traffic from the source to the destination is allowed and which rules decided it.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, _ := cmd.Flags().GetString("manifests")
+			if dir == "" {
+				return errManifestsDirNotSpecified
+			}
+			src, _ := cmd.Flags().GetString("src")
+			if src == "" {
+				return fmt.Errorf("%w", npmerrors.ErrSrcNotSpecified)
+			}
+			dst, _ := cmd.Flags().GetString("dst")
+			if dst == "" {
+				return fmt.Errorf("%w", npmerrors.ErrDstNotSpecified)
+			}
+			port, _ := cmd.Flags().GetInt32("port")
+			protocol, _ := cmd.Flags().GetString("protocol")
+			expect, _ := cmd.Flags().GetString("expect")
+			if expect != "" && expect != expectAllowed && expect != expectDenied {
+				return errInvalidExpectation
+			}
+
+			cfg := simulator.DefaultConfig
+			cfg.NodeName, _ = cmd.Flags().GetString("node-name")
+			cfg.PodCIDR, _ = cmd.Flags().GetString("pod-cidr")
+
+			m, err := simulator.LoadManifests(dir)
+			if err != nil {
+				return fmt.Errorf("%w", err)
+			}
+			s, err := simulator.New(m, cfg)
+			if err != nil {
+				return fmt.Errorf("failed to start simulator: %w", err)
+			}
+			defer s.Stop()
+
+			v, err := s.Query(src, dst, port, protocol)
+			if err != nil {
+				return fmt.Errorf("%w", err)
+			}
+			printVerdict(src, dst, port, protocol, v)
+
+			if expect != "" && (expect == expectAllowed) != v.Allowed {
+				return fmt.Errorf("%w: expected %s", errUnexpectedVerdict, expect)
+			}
+			return nil
+		},
+	}
+
+	simulateCmd.Flags().StringP("manifests", "m", "", "Set the directory of Namespace, Pod, and NetworkPolicy manifests")
+	simulateCmd.Flags().StringP("src", "s", "", "set the source (namespace/name, pod IP, or External)")
+	simulateCmd.Flags().StringP("dst", "d", "", "set the destination (namespace/name, pod IP, or External)")
+	simulateCmd.Flags().Int32P("port", "p", 0, "Set the destination port (0 only matches rules for all ports)")
+	simulateCmd.Flags().String("protocol", "tcp", "Set the protocol")
+	simulateCmd.Flags().String("node-name", simulator.DefaultNodeName, "Set the node name assigned to pods without one")
+	simulateCmd.Flags().String("pod-cidr", simulator.DefaultPodCIDR, "Set the CIDR used to assign IPs to pods without one")
+	simulateCmd.Flags().String("expect", "", fmt.Sprintf("Fail if the verdict is not %q or %q (optional)", expectAllowed, expectDenied))
+
+	return simulateCmd
+}
+
+func printVerdict(src, dst string, port int32, protocol string, v *simulator.Verdict) {
+	fmt.Printf("%s -> %s %s/%d: %s\n", src, dst, protocol, port, verdictString(v.Allowed))
+	printDirectionVerdict("egress", &v.Egress)
+	printDirectionVerdict("ingress", &v.Ingress)
+}
+
+func printDirectionVerdict(direction string, v *simulator.DirectionVerdict) {
+	if v.Rule == nil {
+		fmt.Printf("  %s: %s (no policy selects the pod)\n", direction, verdictString(v.Allowed))
+		return
+	}
+	fmt.Printf("  %s: %s by chain %s", direction, verdictString(v.Allowed), v.Rule.Rule.Chain)
+	if v.Rule.Rule.Comment != "" {
+		fmt.Printf(" (%s)", v.Rule.Rule.Comment)
+	}
+	fmt.Println()
+}
+
+func verdictString(allowed bool) string {
+	if allowed {
+		return expectAllowed
+	}
+	return expectDenied
+}
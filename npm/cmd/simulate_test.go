@@ -0,0 +1,68 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-container-networking/npm/util"
+)
+
+const (
+	simulateCmdString = "simulate"
+	manifestsFlag     = "-m"
+	portFlag          = "-p"
+	expectFlag        = "--expect"
+
+	simulatorManifestsDir = "../pkg/simulator/testdata"
+	frontendPod           = "testnamespace/frontend"
+	backendPod            = "testnamespace/backend"
+)
+
+func TestSimulateCmd(t *testing.T) {
+	baseArgs := []string{simulateCmdString}
+	standardArgs := concatArgs(baseArgs, manifestsFlag, simulatorManifestsDir, srcFlag, backendPod, dstFlag, frontendPod, portFlag, "8000")
+
+	tests := []*testCases{
+		{
+			name:    "no manifests",
+			args:    concatArgs(baseArgs, srcFlag, backendPod, dstFlag, frontendPod),
+			wantErr: true,
+		},
+		{
+			name:    "no src",
+			args:    concatArgs(baseArgs, manifestsFlag, simulatorManifestsDir, dstFlag, frontendPod),
+			wantErr: true,
+		},
+		{
+			name:    "no dst",
+			args:    concatArgs(baseArgs, manifestsFlag, simulatorManifestsDir, srcFlag, backendPod),
+			wantErr: true,
+		},
+		{
+			name:    "bad expectation",
+			args:    concatArgs(standardArgs, expectFlag, "maybe"),
+			wantErr: true,
+		},
+		{
+			name:    "non-existing manifests directory",
+			args:    concatArgs(baseArgs, manifestsFlag, nonExistingFile, srcFlag, backendPod, dstFlag, frontendPod),
+			wantErr: true,
+		},
+	}
+
+	if !util.IsWindowsDP() {
+		tests = append(tests,
+			&testCases{
+				name:    "allowed as expected",
+				args:    concatArgs(standardArgs, expectFlag, "allowed"),
+				wantErr: false,
+			},
+			&testCases{
+				name:    "denied but expected allowed",
+				args:    concatArgs(baseArgs, manifestsFlag, simulatorManifestsDir, srcFlag, backendPod, dstFlag, frontendPod, portFlag, "9000", expectFlag, "allowed"),
+				wantErr: true,
+			},
+		)
+	}
+
+	testCommand(t, tests)
+}
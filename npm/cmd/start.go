@@ -130,6 +130,10 @@ func start(config npmconfig.Config, flags npmconfig.Flags) error {
 		} else {
 			npmV2DataplaneCfg.IPSetMode = ipsets.ApplyAllIPSets
 		}
+		npmV2DataplaneCfg.EnablePolicyHitCounts = config.Toggles.EnablePolicyHitCounts
+		npmV2DataplaneCfg.EnableFQDNEgress = config.Toggles.EnableFQDNEgress
+		npmV2DataplaneCfg.FQDNDNSServerIPs = config.FQDNDNSServerIPs
+		npmV2DataplaneCfg.CacheFile = config.DataplaneCacheFile
 
 		dp, err = dataplane.NewDataPlane(models.GetNodeName(), common.NewIOShim(), npmV2DataplaneCfg, stopChannel)
 		if err != nil {
@@ -138,9 +142,9 @@ func start(config npmconfig.Config, flags npmconfig.Flags) error {
 		dp.RunPeriodicTasks()
 	}
 	npMgr := npm.NewNetworkPolicyManager(config, factory, dp, exec.New(), version, k8sServerVersion)
-	err = metrics.CreateTelemetryHandle(config.NPMVersion(), version, npm.GetAIMetadata())
+	err = createTelemetryHandle(config)
 	if err != nil {
-		klog.Infof("CreateTelemetryHandle failed with error %v. AITelemetry is not initialized.", err)
+		klog.Infof("CreateTelemetryHandle failed with error %v. Telemetry is not initialized.", err)
 	}
 
 	go restserver.NPMRestServerListenAndServe(config, npMgr)
@@ -154,6 +158,14 @@ func start(config npmconfig.Config, flags npmconfig.Flags) error {
 	select {}
 }
 
+// createTelemetryHandle sends the telemetry of NPM to the OTLP endpoint of the config, or to AI by default.
+func createTelemetryHandle(config npmconfig.Config) error {
+	if config.OTLPEndpoint != "" {
+		return metrics.CreateOTLPTelemetryHandle(config.NPMVersion(), version, config.OTLPEndpoint) //nolint:wrapcheck // the error is logged as is
+	}
+	return metrics.CreateTelemetryHandle(config.NPMVersion(), version, npm.GetAIMetadata()) //nolint:wrapcheck // the error is logged as is
+}
+
 func initLogging() error {
 	log.SetName("azure-npm")
 	log.SetLevel(log.LevelInfo)
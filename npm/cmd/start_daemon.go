@@ -7,7 +7,6 @@ import (
 	"strconv"
 
 	"github.com/Azure/azure-container-networking/common"
-	"github.com/Azure/azure-container-networking/npm"
 	npmconfig "github.com/Azure/azure-container-networking/npm/config"
 	"github.com/Azure/azure-container-networking/npm/daemon"
 	restserver "github.com/Azure/azure-container-networking/npm/http/server"
@@ -66,6 +65,10 @@ func startDaemon(config npmconfig.Config) error {
 
 	var dp dataplane.GenericDataplane
 
+	npmV2DataplaneCfg.EnablePolicyHitCounts = config.Toggles.EnablePolicyHitCounts
+	npmV2DataplaneCfg.EnableFQDNEgress = config.Toggles.EnableFQDNEgress
+	npmV2DataplaneCfg.FQDNDNSServerIPs = config.FQDNDNSServerIPs
+	npmV2DataplaneCfg.CacheFile = config.DataplaneCacheFile
 	dp, err = dataplane.NewDataPlane(models.GetNodeName(), common.NewIOShim(), npmV2DataplaneCfg, wait.NeverStop)
 	if err != nil {
 		klog.Errorf("failed to create dataplane: %v", err)
@@ -73,8 +76,6 @@ func startDaemon(config npmconfig.Config) error {
 	}
 
 	dp.RunPeriodicTasks()
-	// TODO Daemon should implement cache encoder
-	go restserver.NPMRestServerListenAndServe(config, nil)
 
 	client, err := transport.NewEventsClient(ctx, pod, node, addr)
 	if err != nil {
@@ -82,11 +83,12 @@ func startDaemon(config npmconfig.Config) error {
 		return fmt.Errorf("failed to create dataplane events client: %w", err)
 	}
 
-	gsp, err := goalstateprocessor.NewGoalStateProcessor(ctx, node, pod, client.EventsChannel(), dp)
+	gsp, err := goalstateprocessor.NewGoalStateProcessor(ctx, node, pod, client.EventsChannel(), client.AckChannel(), dp)
 	if err != nil {
 		klog.Errorf("failed to create goalstate processor with error %v", err)
 		return fmt.Errorf("failed to create goalstate processor: %w", err)
 	}
+	client.SetStateDigester(gsp)
 
 	n, err := daemon.NewNetworkPolicyDaemon(ctx, config, dp, gsp, client, version)
 	if err != nil {
@@ -94,9 +96,11 @@ func startDaemon(config npmconfig.Config) error {
 		return fmt.Errorf("failed to create dataplane: %w", err)
 	}
 
-	err = metrics.CreateTelemetryHandle(config.NPMVersion(), version, npm.GetAIMetadata())
+	go restserver.NPMRestServerListenAndServe(config, n)
+
+	err = createTelemetryHandle(config)
 	if err != nil {
-		klog.Infof("CreateTelemetryHandle failed with error %v. AITelemetry is not initialized.", err)
+		klog.Infof("CreateTelemetryHandle failed with error %v. Telemetry is not initialized.", err)
 	}
 
 	err = n.Start(config, wait.NeverStop)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"os"
 	"time"
 
 	"github.com/Azure/azure-container-networking/npm"
@@ -24,6 +25,9 @@ import (
 	"k8s.io/klog"
 )
 
+// controlplanePodNameEnv is the leader election identity of the controlplane replica
+const controlplanePodNameEnv = "CONTROLPLANE_POD_NAME"
+
 func newStartNPMControlplaneCmd() *cobra.Command {
 	startNPMControlplaneCmd := &cobra.Command{
 		Use:   "controlplane",
@@ -122,5 +126,17 @@ func startControlplane(config npmconfig.Config, flags npmconfig.Flags) error {
 
 	metrics.SendLog(util.FanOutServerID, "starting fan-out server", metrics.PrintLog)
 
+	if config.LeaderElection.Enabled {
+		identity := os.Getenv(controlplanePodNameEnv)
+		if identity == "" {
+			identity, err = os.Hostname()
+			if err != nil {
+				return fmt.Errorf("failed to get leader election identity: %w", err)
+			}
+		}
+		klog.Infof("starting fan-out server with leader election as %s", identity)
+		return npMgr.StartWithLeaderElection(config, clientset, identity, wait.NeverStop) //nolint:wrapcheck // unnecessary to wrap error
+	}
+
 	return npMgr.Start(config, wait.NeverStop) //nolint:wrapcheck // unnecessary to wrap error
 }
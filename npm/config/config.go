@@ -7,6 +7,10 @@ const (
 	defaultListeningPort   = 10091
 	defaultGrpcPort        = 10092
 	defaultGrpcServicePort = 9002
+	// leader election defaults match those of kube-controller-manager
+	defaultLeaseDuration = 15
+	defaultRenewDeadline = 10
+	defaultRetryPeriod   = 2
 	// ConfigEnvPath is what's used by viper to load config path
 	ConfigEnvPath = "NPM_CONFIG"
 
@@ -27,6 +31,15 @@ var DefaultConfig = Config{
 		ServicePort: defaultGrpcServicePort,
 	},
 
+	LeaderElection: LeaderElectionConfig{
+		Enabled:                false,
+		LeaseName:              "azure-npm-controlplane",
+		LeaseNamespace:         "kube-system",
+		LeaseDurationInSeconds: defaultLeaseDuration,
+		RenewDeadlineInSeconds: defaultRenewDeadline,
+		RetryPeriodInSeconds:   defaultRetryPeriod,
+	},
+
 	Toggles: Toggles{
 		EnablePrometheusMetrics: true,
 		EnablePprof:             true,
@@ -34,6 +47,8 @@ var DefaultConfig = Config{
 		EnableV2NPM:             true,
 		PlaceAzureChainFirst:    util.PlaceAzureChainFirst,
 		ApplyIPSetsOnNeed:       false,
+		EnablePolicyHitCounts:   false,
+		EnableFQDNEgress:        false,
 	},
 }
 
@@ -46,6 +61,39 @@ type GrpcServerConfig struct {
 	ServicePort int `json:"ServicePort,omitempty"`
 }
 
+// LeaderElectionConfig is for running multiple replicas of the fan-out controlplane.
+// All replicas keep their caches warm, but only the leader serves daemons.
+type LeaderElectionConfig struct {
+	Enabled bool `json:"Enabled,omitempty"`
+	// LeaseName and LeaseNamespace identify the Lease used as the lock
+	LeaseName              string `json:"LeaseName,omitempty"`
+	LeaseNamespace         string `json:"LeaseNamespace,omitempty"`
+	LeaseDurationInSeconds int    `json:"LeaseDurationInSeconds,omitempty"`
+	RenewDeadlineInSeconds int    `json:"RenewDeadlineInSeconds,omitempty"`
+	RetryPeriodInSeconds   int    `json:"RetryPeriodInSeconds,omitempty"`
+}
+
+// WithDefaults returns the config with unset fields set to the default values
+func (c LeaderElectionConfig) WithDefaults() LeaderElectionConfig {
+	defaults := DefaultConfig.LeaderElection
+	if c.LeaseName == "" {
+		c.LeaseName = defaults.LeaseName
+	}
+	if c.LeaseNamespace == "" {
+		c.LeaseNamespace = defaults.LeaseNamespace
+	}
+	if c.LeaseDurationInSeconds == 0 {
+		c.LeaseDurationInSeconds = defaults.LeaseDurationInSeconds
+	}
+	if c.RenewDeadlineInSeconds == 0 {
+		c.RenewDeadlineInSeconds = defaults.RenewDeadlineInSeconds
+	}
+	if c.RetryPeriodInSeconds == 0 {
+		c.RetryPeriodInSeconds = defaults.RetryPeriodInSeconds
+	}
+	return c
+}
+
 type Config struct {
 	ResyncPeriodInMinutes int `json:"ResyncPeriodInMinutes,omitempty"`
 
@@ -54,6 +102,21 @@ type Config struct {
 
 	Transport GrpcServerConfig `json:"Transport,omitempty"`
 
+	// LeaderElection is only used by the fan-out controlplane
+	LeaderElection LeaderElectionConfig `json:"LeaderElection,omitempty"`
+
+	// DataplaneCacheFile is where the v2 dataplane persists its ipsets and policies to restart without flushing them (only in Linux).
+	// Empty disables the cache.
+	DataplaneCacheFile string `json:"DataplaneCacheFile,omitempty"`
+
+	// FQDNDNSServerIPs are the IPs of DNS servers which pods query besides the CoreDNS pods, such as the IP of the cluster DNS service
+	// or of a node-local DNS cache. DNS snooping for FQDN egress rules ignores responses from any other server.
+	FQDNDNSServerIPs []string `json:"FQDNDNSServerIPs,omitempty"`
+
+	// OTLPEndpoint is the base URL of the OTLP/HTTP receiver of an OpenTelemetry collector, such as http://otel-collector:4318.
+	// If set, the telemetry and Prometheus metrics of NPM are exported to it instead of AI.
+	OTLPEndpoint string `json:"OTLPEndpoint,omitempty"`
+
 	Toggles Toggles `json:"Toggles,omitempty"`
 }
 
@@ -64,6 +127,10 @@ type Toggles struct {
 	EnableV2NPM             bool
 	PlaceAzureChainFirst    bool
 	ApplyIPSetsOnNeed       bool
+	// EnablePolicyHitCounts exports per-policy allow/drop packet and byte counts (only in Linux)
+	EnablePolicyHitCounts bool
+	// EnableFQDNEgress snoops DNS responses for the FQDN egress annotation on network policies (only in Linux)
+	EnableFQDNEgress bool
 }
 
 type Flags struct {
@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	npmconfig "github.com/Azure/azure-container-networking/npm/config"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog"
+)
+
+// ErrLostLeadership is returned once a controlplane replica stops leading, so that it restarts as a standby
+var ErrLostLeadership = errors.New("lost leadership of the NPM controlplane")
+
+// StartWithLeaderElection is like Start, but for one of multiple controlplane replicas.
+// Every replica runs the controllers to keep its DPShim cache warm, but only the replica holding the Lease serves daemons.
+// When the leader changes, daemons reconnect to the new leader and only receive what differs from their applied state.
+func (n *NetworkPolicyServer) StartWithLeaderElection(
+	config npmconfig.Config,
+	client kubernetes.Interface,
+	identity string,
+	stopCh <-chan struct{},
+) error {
+	if err := n.startControllers(stopCh); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	err := runLeaderElection(ctx, client, config.LeaderElection.WithDefaults(), identity, func(leaderCtx context.Context) error {
+		// the transport layer (gRPC) server blocks until the replica stops leading
+		return n.tm.Start(leaderCtx.Done()) //nolint:wrapcheck // ignore: can't use n.tm.Start() directly
+	})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-stopCh:
+		return nil
+	default:
+		return ErrLostLeadership
+	}
+}
+
+// runLeaderElection calls run while holding the Lease.
+// It returns once ctx is done, the Lease is lost, or run fails.
+func runLeaderElection(
+	ctx context.Context,
+	client kubernetes.Interface,
+	config npmconfig.LeaderElectionConfig,
+	identity string,
+	run func(context.Context) error,
+) error {
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      config.LeaseName,
+			Namespace: config.LeaseNamespace,
+		},
+		Client: client.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: identity,
+		},
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   time.Duration(config.LeaseDurationInSeconds) * time.Second,
+		RenewDeadline:   time.Duration(config.RenewDeadlineInSeconds) * time.Second,
+		RetryPeriod:     time.Duration(config.RetryPeriodInSeconds) * time.Second,
+		ReleaseOnCancel: true,
+		Name:            config.LeaseName,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(leaderCtx context.Context) {
+				klog.Infof("%s started leading the NPM controlplane", identity)
+				if err := run(leaderCtx); err != nil {
+					errCh <- err
+					cancel()
+				}
+			},
+			OnStoppedLeading: func() {
+				klog.Infof("%s stopped leading the NPM controlplane", identity)
+			},
+			OnNewLeader: func(leader string) {
+				klog.Infof("NPM controlplane leader is %s", leader)
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create leader elector: %w", err)
+	}
+
+	// blocks until ctx is done or the Lease is lost
+	elector.Run(ctx)
+
+	select {
+	case err := <-errCh:
+		return err
+	default:
+		return nil
+	}
+}
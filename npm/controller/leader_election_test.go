@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	npmconfig "github.com/Azure/azure-container-networking/npm/config"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+var testLeaderElectionConfig = npmconfig.LeaderElectionConfig{
+	Enabled:                true,
+	LeaseName:              "test-lease",
+	LeaseNamespace:         "kube-system",
+	LeaseDurationInSeconds: 3,
+	RenewDeadlineInSeconds: 2,
+	RetryPeriodInSeconds:   1,
+}
+
+func TestRunLeaderElection(t *testing.T) {
+	client := k8sfake.NewSimpleClientset()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- runLeaderElection(ctx, client, testLeaderElectionConfig, "replica-1", func(leaderCtx context.Context) error {
+			close(started)
+			<-leaderCtx.Done()
+			return nil
+		})
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "replica never started leading")
+	}
+	lease, err := client.CoordinationV1().Leases("kube-system").Get(context.Background(), "test-lease", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "replica-1", *lease.Spec.HolderIdentity)
+
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "leader election didn't stop")
+	}
+}
+
+func TestRunLeaderElectionFailure(t *testing.T) {
+	client := k8sfake.NewSimpleClientset()
+	errServe := errors.New("failed to serve")
+
+	err := runLeaderElection(context.Background(), client, testLeaderElectionConfig, "replica-1", func(context.Context) error {
+		return errServe
+	})
+	require.ErrorIs(t, err, errServe)
+}
+
+func TestLeaderElectionConfigWithDefaults(t *testing.T) {
+	config := npmconfig.LeaderElectionConfig{Enabled: true, LeaseName: "custom"}.WithDefaults()
+	require.Equal(t, "custom", config.LeaseName)
+	require.Equal(t, npmconfig.DefaultConfig.LeaderElection.LeaseNamespace, config.LeaseNamespace)
+	require.Equal(t, npmconfig.DefaultConfig.LeaderElection.LeaseDurationInSeconds, config.LeaseDurationInSeconds)
+	require.True(t, config.Enabled)
+}
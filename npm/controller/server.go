@@ -130,11 +130,30 @@ func (n *NetworkPolicyServer) MarshalJSON() ([]byte, error) {
 	return npmCacheRaw, nil
 }
 
+// GoalStateStatus returns the goal state status of each node with connected daemons
+func (n *NetworkPolicyServer) GoalStateStatus() map[string]transport.NodeGoalStateStatus {
+	return n.tm.GoalStateStatus()
+}
+
 func (n *NetworkPolicyServer) GetAppVersion() string {
 	return n.Version
 }
 
 func (n *NetworkPolicyServer) Start(config npmconfig.Config, stopCh <-chan struct{}) error {
+	if err := n.startControllers(stopCh); err != nil {
+		return err
+	}
+
+	// start the transport layer (gRPC) server
+	// We block the main thread here until the server is stopped.
+	// This is unlike the other start methods in this package, which returns nil
+	// and blocks in the main thread during command invocation through the select {}
+	// statement.
+	return n.tm.Start(stopCh) //nolint:wrapcheck // ignore: can't use n.tm.Start() directly
+}
+
+// startControllers starts the informers and runs the controllers once the informers are synced
+func (n *NetworkPolicyServer) startControllers(stopCh <-chan struct{}) error {
 	// Starts all informers manufactured by n's InformerFactory.
 	n.InformerFactory.Start(stopCh)
 
@@ -155,11 +174,5 @@ func (n *NetworkPolicyServer) Start(config npmconfig.Config, stopCh <-chan struc
 	go n.PodControllerV2.Run(stopCh)
 	go n.NamespaceControllerV2.Run(stopCh)
 	go n.NetPolControllerV2.Run(stopCh)
-
-	// start the transport layer (gRPC) server
-	// We block the main thread here until the server is stopped.
-	// This is unlike the other start methods in this package, which returns nil
-	// and blocks in the main thread during command invocation through the select {}
-	// statement.
-	return n.tm.Start(stopCh) //nolint:wrapcheck // ignore: can't use n.tm.Start() directly
+	return nil
 }
@@ -4,12 +4,14 @@ package daemon
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 
 	npmconfig "github.com/Azure/azure-container-networking/npm/config"
 	"github.com/Azure/azure-container-networking/npm/pkg/controlplane/goalstateprocessor"
 	"github.com/Azure/azure-container-networking/npm/pkg/dataplane"
+	"github.com/Azure/azure-container-networking/npm/pkg/models"
 	"github.com/Azure/azure-container-networking/npm/pkg/transport"
 )
 
@@ -23,6 +25,7 @@ type NetworkPolicyDaemon struct {
 	client  *transport.EventsClient
 	version string
 	gsp     *goalstateprocessor.GoalStateProcessor
+	dp      dataplane.GenericDataplane
 }
 
 func NewNetworkPolicyDaemon(
@@ -44,6 +47,7 @@ func NewNetworkPolicyDaemon(
 		gsp:     gsp,
 		client:  client,
 		version: npmVersion,
+		dp:      dp,
 	}, nil
 }
 
@@ -56,6 +60,34 @@ func (n *NetworkPolicyDaemon) Start(config npmconfig.Config, stopCh <-chan struc
 	return nil
 }
 
+// MarshalJSON encodes the dataplane's caches and the last applied goal state for the NPM debug tooling
+func (n *NetworkPolicyDaemon) MarshalJSON() ([]byte, error) {
+	dpEncoder, ok := n.dp.(json.Marshaler)
+	if !ok {
+		return nil, fmt.Errorf("%w: dataplane doesn't support encoding", models.ErrMarshalNPMCache)
+	}
+	dpRaw, err := dpEncoder.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", models.ErrMarshalNPMCache, err)
+	}
+
+	m := map[models.CacheKey]json.RawMessage{}
+	if err := json.Unmarshal(dpRaw, &m); err != nil {
+		return nil, fmt.Errorf("%s: %w", models.ErrMarshalNPMCache, err)
+	}
+
+	m[models.AppliedGoalState], err = json.Marshal(n.gsp.LastApplied())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", models.ErrMarshalNPMCache, err)
+	}
+
+	npmCacheRaw, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", models.ErrMarshalNPMCache, err)
+	}
+	return npmCacheRaw, nil
+}
+
 func (n *NetworkPolicyDaemon) GetAppVersion() string {
 	return n.version
 }
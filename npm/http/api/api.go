@@ -6,6 +6,7 @@ const (
 	NodeMetricsPath    = "/node-metrics"
 	ClusterMetricsPath = "/cluster-metrics"
 	NPMMgrPath         = "/npm/v1/debug/manager"
+	GoalStatesPath     = "/npm/v1/debug/goalstates"
 )
 
 type DescribeIPSetRequest struct{}
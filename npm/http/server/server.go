@@ -11,11 +11,17 @@ import (
 	npmconfig "github.com/Azure/azure-container-networking/npm/config"
 	"github.com/Azure/azure-container-networking/npm/http/api"
 	"github.com/Azure/azure-container-networking/npm/metrics"
+	"github.com/Azure/azure-container-networking/npm/pkg/transport"
 	"k8s.io/klog"
 
 	"github.com/gorilla/mux"
 )
 
+// goalStateStatusProvider is implemented by the fan-out controlplane
+type goalStateStatusProvider interface {
+	GoalStateStatus() map[string]transport.NodeGoalStateStatus
+}
+
 type NPMRestServer struct {
 	listeningAddress string
 	router           *mux.Router
@@ -32,10 +38,14 @@ func NPMRestServerListenAndServe(config npmconfig.Config, npmEncoder json.Marsha
 		rs.router.Handle(api.ClusterMetricsPath, metrics.GetHandler(metrics.ClusterMetrics))
 	}
 
-	// the nil check is for fan-out npm
 	if config.Toggles.EnableHTTPDebugAPI && npmEncoder != nil {
 		// ACN CLI debug handlers
 		rs.router.Handle(api.NPMMgrPath, rs.npmCacheHandler(npmEncoder)).Methods(http.MethodGet)
+
+		// goal state status of each node's daemons
+		if provider, ok := npmEncoder.(goalStateStatusProvider); ok {
+			rs.router.Handle(api.GoalStatesPath, rs.goalStateStatusHandler(provider)).Methods(http.MethodGet)
+		}
 	}
 
 	if config.Toggles.EnablePprof {
@@ -74,3 +84,17 @@ func (n *NPMRestServer) npmCacheHandler(npmCacheEncoder json.Marshaler) http.Han
 		}
 	})
 }
+
+func (n *NPMRestServer) goalStateStatusHandler(provider goalStateStatusProvider) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := json.Marshal(provider.GoalStateStatus())
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		_, err = w.Write(b)
+		if err != nil {
+			log.Errorf("failed to write resp: %v", err)
+		}
+	})
+}
@@ -10,7 +10,9 @@ import (
 	"github.com/Azure/azure-container-networking/npm"
 	"github.com/Azure/azure-container-networking/npm/http/api"
 	"github.com/Azure/azure-container-networking/npm/pkg/controlplane/controllers/common"
+	"github.com/Azure/azure-container-networking/npm/pkg/transport"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetNPMCacheHandler(t *testing.T) {
@@ -55,3 +57,29 @@ func TestGetNPMCacheHandler(t *testing.T) {
 
 	assert.Exactly(expected, actual)
 }
+
+type fakeGoalStateStatusProvider map[string]transport.NodeGoalStateStatus
+
+func (f fakeGoalStateStatusProvider) GoalStateStatus() map[string]transport.NodeGoalStateStatus {
+	return f
+}
+
+func TestGoalStateStatusHandler(t *testing.T) {
+	provider := fakeGoalStateStatusProvider{
+		"node-a": {Clients: map[string]transport.ClientGoalStateStatus{
+			"npm-abc": {SentGeneration: 5, AppliedGeneration: 4, Error: "failed"},
+		}},
+	}
+	n := &NPMRestServer{}
+	handler := n.goalStateStatusHandler(provider)
+
+	req, err := http.NewRequest(http.MethodGet, api.GoalStatesPath, nil)
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	actual := map[string]transport.NodeGoalStateStatus{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &actual))
+	require.Equal(t, map[string]transport.NodeGoalStateStatus(provider), actual)
+}
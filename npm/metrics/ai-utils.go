@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"time"
@@ -8,6 +9,8 @@ import (
 	"github.com/Azure/azure-container-networking/aitelemetry"
 	"github.com/Azure/azure-container-networking/log"
 	"github.com/Azure/azure-container-networking/npm/util"
+	"github.com/Azure/azure-container-networking/otlp"
+	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/klog"
 )
 
@@ -54,6 +57,30 @@ func CreateTelemetryHandle(npmVersionNum int, imageVersion, aiMetadata string) e
 	return nil
 }
 
+// CreateOTLPTelemetryHandle creates a handler which exports the telemetry of NPM, and mirrors its Prometheus metrics,
+// to the OpenTelemetry collector at the endpoint, instead of AI.
+func CreateOTLPTelemetryHandle(npmVersionNum int, imageVersion, endpoint string) error {
+	npmVersion = npmVersionNum
+	exporter, err := otlp.New(otlp.Config{
+		Endpoint:       endpoint,
+		ServiceName:    util.AzureNpmFlag,
+		ServiceVersion: imageVersion,
+		BatchInterval:  time.Duration(util.BatchIntervalInSecs) * time.Second,
+		Gatherer:       prometheus.Gatherers{nodeRegistry, clusterRegistry},
+		ErrorHandler: func(err error) {
+			klog.Errorf("failed to export OTLP telemetry: %v", err)
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	th = otlp.NewTelemetryHandle(exporter)
+	go exporter.Run(context.Background())
+	log.Logf("Initialized OTLP telemetry handle")
+	return nil
+}
+
 // SendErrorLogAndMetric sends a metric through AI telemetry and sends a log to the Kusto Messages table
 func SendErrorLogAndMetric(operationID int, format string, args ...interface{}) {
 	// Send error metrics
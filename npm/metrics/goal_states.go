@@ -0,0 +1,48 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// SetGoalStateGeneration sets the latest goal state generation of the controlplane.
+func SetGoalStateGeneration(generation uint64) {
+	goalStateGeneration.Set(float64(generation))
+}
+
+// SetNodeGoalStateStatus sets the goal state lag and failure status of a node.
+func SetNodeGoalStateStatus(nodeName string, lag uint64, failed bool) {
+	labels := getNodeGoalStateLabels(nodeName)
+	nodeGoalStateLag.With(labels).Set(float64(lag))
+	if failed {
+		nodeGoalStateFailed.With(labels).Set(1)
+	} else {
+		nodeGoalStateFailed.With(labels).Set(0)
+	}
+}
+
+// RemoveNodeGoalStateStatus removes the goal state metrics of a node without connected daemons.
+func RemoveNodeGoalStateStatus(nodeName string) {
+	labels := getNodeGoalStateLabels(nodeName)
+	nodeGoalStateLag.Delete(labels)
+	nodeGoalStateFailed.Delete(labels)
+}
+
+// GetGoalStateGeneration returns the latest goal state generation of the controlplane.
+// This function is slow.
+func GetGoalStateGeneration() (int, error) {
+	return getValue(goalStateGeneration)
+}
+
+// GetNodeGoalStateLag returns the goal state lag of a node.
+// This function is slow.
+func GetNodeGoalStateLag(nodeName string) (int, error) {
+	return getVecValue(nodeGoalStateLag, getNodeGoalStateLabels(nodeName))
+}
+
+// GetNodeGoalStateFailed returns 1 if the daemon on the node failed to apply its last goal state.
+// This function is slow.
+func GetNodeGoalStateFailed(nodeName string) (int, error) {
+	return getVecValue(nodeGoalStateFailed, getNodeGoalStateLabels(nodeName))
+}
+
+func getNodeGoalStateLabels(nodeName string) prometheus.Labels {
+	return prometheus.Labels{nodeLabel: nodeName}
+}
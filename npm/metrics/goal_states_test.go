@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeGoalStateStatus(t *testing.T) {
+	InitializeAll()
+	node := "node-a"
+	defer RemoveNodeGoalStateStatus(node)
+
+	SetGoalStateGeneration(12)
+	generation, err := GetGoalStateGeneration()
+	require.NoError(t, err)
+	require.Equal(t, 12, generation)
+
+	SetNodeGoalStateStatus(node, 3, true)
+	lag, err := GetNodeGoalStateLag(node)
+	require.NoError(t, err)
+	require.Equal(t, 3, lag)
+	failed, err := GetNodeGoalStateFailed(node)
+	require.NoError(t, err)
+	require.Equal(t, 1, failed)
+
+	RemoveNodeGoalStateStatus(node)
+	require.Equal(t, 0, numNodeGoalStateLagSeries())
+}
+
+// numNodeGoalStateLagSeries returns the number of nodes with a lag metric.
+// Call before GetNodeGoalStateLag(), which creates the series if it doesn't exist.
+func numNodeGoalStateLagSeries() int {
+	ch := make(chan prometheus.Metric, 10)
+	go func() {
+		nodeGoalStateLag.Collect(ch)
+		close(ch)
+	}()
+	n := 0
+	for range ch {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// MaxPoliciesWithHitCounts bounds the cardinality of the policy hit count metrics.
+	// Hit counts of the remaining policies are summed under the OtherPolicies label value.
+	MaxPoliciesWithHitCounts = 100
+	// OtherPolicies is the policy label value for policies beyond MaxPoliciesWithHitCounts.
+	OtherPolicies = "other"
+
+	IngressDirection = "ingress"
+	EgressDirection  = "egress"
+	AllowVerdict     = "allow"
+	DropVerdict      = "drop"
+)
+
+// PolicyHitCount is the number of packets and bytes matched by the allow or drop rules of a network policy in one direction.
+type PolicyHitCount struct {
+	PolicyKey string
+	Direction string
+	Verdict   string
+	Packets   uint64
+	Bytes     uint64
+}
+
+type policyHitCountKey struct {
+	policyKey string
+	direction string
+	verdict   string
+}
+
+// SetPolicyHitCounts replaces all policy hit count metrics with the given counts.
+// Only the MaxPoliciesWithHitCounts policies with the most packets get their own label value.
+func SetPolicyHitCounts(counts []*PolicyHitCount) {
+	totalPackets := make(map[string]uint64)
+	for _, count := range counts {
+		totalPackets[count.PolicyKey] += count.Packets
+	}
+	policyKeys := make([]string, 0, len(totalPackets))
+	for policyKey := range totalPackets {
+		policyKeys = append(policyKeys, policyKey)
+	}
+	sort.Slice(policyKeys, func(i, j int) bool {
+		if totalPackets[policyKeys[i]] != totalPackets[policyKeys[j]] {
+			return totalPackets[policyKeys[i]] > totalPackets[policyKeys[j]]
+		}
+		return policyKeys[i] < policyKeys[j]
+	})
+	labeledPolicies := make(map[string]struct{}, MaxPoliciesWithHitCounts)
+	for i := 0; i < len(policyKeys) && i < MaxPoliciesWithHitCounts; i++ {
+		labeledPolicies[policyKeys[i]] = struct{}{}
+	}
+
+	packets := make(map[policyHitCountKey]uint64)
+	bytes := make(map[policyHitCountKey]uint64)
+	for _, count := range counts {
+		key := policyHitCountKey{policyKey: count.PolicyKey, direction: count.Direction, verdict: count.Verdict}
+		if _, ok := labeledPolicies[count.PolicyKey]; !ok {
+			key.policyKey = OtherPolicies
+		}
+		packets[key] += count.Packets
+		bytes[key] += count.Bytes
+	}
+
+	policyHitPackets.Reset()
+	policyHitBytes.Reset()
+	for key, val := range packets {
+		labels := getPolicyHitCountLabels(key.policyKey, key.direction, key.verdict)
+		policyHitPackets.With(labels).Set(float64(val))
+		policyHitBytes.With(labels).Set(float64(bytes[key]))
+	}
+}
+
+// ResetPolicyHitCounts removes all policy hit count metrics.
+func ResetPolicyHitCounts() {
+	policyHitPackets.Reset()
+	policyHitBytes.Reset()
+}
+
+// GetPolicyHitPackets returns the number of packets matched by a policy's rules with the direction and verdict.
+// This function is slow.
+func GetPolicyHitPackets(policyKey, direction, verdict string) (int, error) {
+	return getVecValue(policyHitPackets, getPolicyHitCountLabels(policyKey, direction, verdict))
+}
+
+// GetPolicyHitBytes returns the number of bytes matched by a policy's rules with the direction and verdict.
+// This function is slow.
+func GetPolicyHitBytes(policyKey, direction, verdict string) (int, error) {
+	return getVecValue(policyHitBytes, getPolicyHitCountLabels(policyKey, direction, verdict))
+}
+
+func getPolicyHitCountLabels(policyKey, direction, verdict string) prometheus.Labels {
+	return prometheus.Labels{policyLabel: policyKey, directionLabel: direction, verdictLabel: verdict}
+}
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetPolicyHitCounts(t *testing.T) {
+	InitializeAll()
+	ResetPolicyHitCounts()
+	defer ResetPolicyHitCounts()
+
+	SetPolicyHitCounts([]*PolicyHitCount{
+		{PolicyKey: "x/allow-web", Direction: IngressDirection, Verdict: AllowVerdict, Packets: 10, Bytes: 1000},
+		{PolicyKey: "x/allow-web", Direction: IngressDirection, Verdict: AllowVerdict, Packets: 5, Bytes: 500},
+		{PolicyKey: "x/allow-web", Direction: IngressDirection, Verdict: DropVerdict, Packets: 2, Bytes: 120},
+		{PolicyKey: "y/deny-all", Direction: EgressDirection, Verdict: DropVerdict, Packets: 3, Bytes: 180},
+	})
+	require.Equal(t, 3, numPolicyHitCountSeries())
+	assertPolicyHitCount(t, "x/allow-web", IngressDirection, AllowVerdict, 15, 1500)
+	assertPolicyHitCount(t, "x/allow-web", IngressDirection, DropVerdict, 2, 120)
+	assertPolicyHitCount(t, "y/deny-all", EgressDirection, DropVerdict, 3, 180)
+
+	// stale policies are removed
+	SetPolicyHitCounts([]*PolicyHitCount{
+		{PolicyKey: "y/deny-all", Direction: EgressDirection, Verdict: DropVerdict, Packets: 4, Bytes: 240},
+	})
+	require.Equal(t, 1, numPolicyHitCountSeries())
+	assertPolicyHitCount(t, "y/deny-all", EgressDirection, DropVerdict, 4, 240)
+}
+
+func TestSetPolicyHitCountsBoundsCardinality(t *testing.T) {
+	InitializeAll()
+	ResetPolicyHitCounts()
+	defer ResetPolicyHitCounts()
+
+	numPolicies := MaxPoliciesWithHitCounts + 10
+	counts := make([]*PolicyHitCount, 0, numPolicies)
+	for i := 0; i < numPolicies; i++ {
+		counts = append(counts, &PolicyHitCount{
+			PolicyKey: fmt.Sprintf("x/policy-%d", i),
+			Direction: IngressDirection,
+			Verdict:   AllowVerdict,
+			Packets:   uint64(i + 1),
+			Bytes:     uint64(100 * (i + 1)),
+		})
+	}
+	SetPolicyHitCounts(counts)
+
+	require.Equal(t, MaxPoliciesWithHitCounts+1, numPolicyHitCountSeries())
+	// the policies with the fewest packets are summed up: 1 + 2 + ... + 10
+	assertPolicyHitCount(t, OtherPolicies, IngressDirection, AllowVerdict, 55, 5500)
+	assertPolicyHitCount(t, fmt.Sprintf("x/policy-%d", numPolicies-1), IngressDirection, AllowVerdict, numPolicies, 100*numPolicies)
+}
+
+func assertPolicyHitCount(t *testing.T, policyKey, direction, verdict string, expectedPackets, expectedBytes int) {
+	t.Helper()
+	packets, err := GetPolicyHitPackets(policyKey, direction, verdict)
+	require.NoError(t, err)
+	require.Equal(t, expectedPackets, packets, "wrong packets for %s %s %s", policyKey, direction, verdict)
+	bytes, err := GetPolicyHitBytes(policyKey, direction, verdict)
+	require.NoError(t, err)
+	require.Equal(t, expectedBytes, bytes, "wrong bytes for %s %s %s", policyKey, direction, verdict)
+}
+
+// numPolicyHitCountSeries returns the number of label combinations for the packets metric.
+// Call before GetPolicyHitPackets(), which creates the series if it doesn't exist.
+func numPolicyHitCountSeries() int {
+	ch := make(chan prometheus.Metric, MaxPoliciesWithHitCounts*4)
+	go func() {
+		policyHitPackets.Collect(ch)
+		close(ch)
+	}()
+	n := 0
+	for range ch {
+		n++
+	}
+	return n
+}
@@ -54,6 +54,24 @@ const (
 	namespaceExecTimeName           = "namespace_exec_time"
 	controllerNamespaceExecTimeHelp = "Execution time in milliseconds for adding/updating/deleting a namespace"
 
+	// policy hit counters, read from the iptables rule counters of each policy's chains
+	policyHitPacketsName = "policy_hit_packets"
+	policyHitPacketsHelp = "The number of packets matched by the allow/drop rules of a network policy for this node"
+	policyHitBytesName   = "policy_hit_bytes"
+	policyHitBytesHelp   = "The number of bytes matched by the allow/drop rules of a network policy for this node"
+	policyLabel          = "policy"
+	directionLabel       = "direction"
+	verdictLabel         = "verdict"
+
+	// fan-out goal state metrics, tracked by the controlplane for each node with connected daemons
+	goalStateGenerationName = "goal_state_generation"
+	goalStateGenerationHelp = "The latest goal state generation of the controlplane"
+	nodeGoalStateLagName    = "node_goal_state_lag"
+	nodeGoalStateLagHelp    = "The number of goal state generations sent to a node but not yet acknowledged as applied by its daemon"
+	nodeGoalStateFailedName = "node_goal_state_failed"
+	nodeGoalStateFailedHelp = "1 if the daemon on a node failed to apply its last goal state, 0 otherwise"
+	nodeLabel               = "node"
+
 	// TODO add health metrics
 
 	quantileMedian float64 = 0.5
@@ -95,6 +113,15 @@ var (
 	controllerNamespaceExecTime *prometheus.SummaryVec
 	controllerExecTimeLabels    = []string{operationLabel, hadErrorLabel}
 
+	policyHitPackets     *prometheus.GaugeVec
+	policyHitBytes       *prometheus.GaugeVec
+	policyHitCountLabels = []string{policyLabel, directionLabel, verdictLabel}
+
+	goalStateGeneration prometheus.Gauge
+	nodeGoalStateLag    *prometheus.GaugeVec
+	nodeGoalStateFailed *prometheus.GaugeVec
+	nodeGoalStateLabels = []string{nodeLabel}
+
 	// TODO add health metrics
 )
 
@@ -171,12 +198,17 @@ func initializeDaemonMetrics() {
 	// NODE METRICS
 	addACLRuleExecTime = createNodeSummary(addACLRuleExecTimeName, addACLRuleExecTimeHelp)
 	addIPSetExecTime = createNodeSummary(addIPSetExecTimeName, addIPSetExecTimeHelp)
+	policyHitPackets = createNodeGaugeVec(policyHitPacketsName, policyHitPacketsHelp, policyHitCountLabels)
+	policyHitBytes = createNodeGaugeVec(policyHitBytesName, policyHitBytesHelp, policyHitCountLabels)
 }
 
 // initializeControllerMetrics creates metrics modified by the controller
 func initializeControllerMetrics() {
 	// CLUSTER METRICS
 	numPolicies = createClusterGauge(numPoliciesName, numPoliciesHelp)
+	goalStateGeneration = createClusterGauge(goalStateGenerationName, goalStateGenerationHelp)
+	nodeGoalStateLag = createClusterGaugeVec(nodeGoalStateLagName, nodeGoalStateLagHelp, nodeGoalStateLabels)
+	nodeGoalStateFailed = createClusterGaugeVec(nodeGoalStateFailedName, nodeGoalStateFailedHelp, nodeGoalStateLabels)
 	// TODO include health metrics: num failures for validating policies & ipsets
 
 	// NODE METRICS
@@ -228,6 +260,19 @@ func createClusterGaugeVec(name, helpMessage string, labels []string) *prometheu
 	return gaugeVec
 }
 
+func createNodeGaugeVec(name, helpMessage string, labels []string) *prometheus.GaugeVec {
+	gaugeVec := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      name,
+			Help:      helpMessage,
+		},
+		labels,
+	)
+	register(gaugeVec, name, NodeMetrics)
+	return gaugeVec
+}
+
 func createNodeSummary(name, helpMessage string) prometheus.Summary {
 	// uses default observation TTL of 10 minutes
 	summary := prometheus.NewSummary(
@@ -34,7 +34,10 @@ type NetworkPolicyController struct {
 	netPolLister netpollister.NetworkPolicyLister
 	workqueue    workqueue.RateLimitingInterface
 	rawNpSpecMap map[string]*networkingv1.NetworkPolicySpec // Key is <nsname>/<policyname>
-	dp           dataplane.GenericDataplane
+	// rawFQDNMap holds the FQDN egress annotation of policies which have one since it's translated alongside the spec.
+	// Key is <nsname>/<policyname>
+	rawFQDNMap map[string]string
+	dp         dataplane.GenericDataplane
 }
 
 func (c *NetworkPolicyController) GetCache() map[string]*networkingv1.NetworkPolicySpec {
@@ -48,6 +51,7 @@ func NewNetworkPolicyController(npInformer networkinginformers.NetworkPolicyInfo
 		netPolLister: npInformer.Lister(),
 		workqueue:    workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "NetworkPolicy"),
 		rawNpSpecMap: make(map[string]*networkingv1.NetworkPolicySpec),
+		rawFQDNMap:   make(map[string]string),
 		dp:           dp,
 	}
 
@@ -62,6 +66,8 @@ func NewNetworkPolicyController(npInformer networkinginformers.NetworkPolicyInfo
 }
 
 func (c *NetworkPolicyController) LengthOfRawNpMap() int {
+	c.RLock()
+	defer c.RUnlock()
 	return len(c.rawNpSpecMap)
 }
 
@@ -212,6 +218,9 @@ func (c *NetworkPolicyController) syncNetPol(key string) error {
 		return nil //nolint HandleError  is used instead of returning error to caller
 	}
 
+	c.Lock()
+	defer c.Unlock()
+
 	// record exec time after syncing
 	operationKind := metrics.NoOp
 	defer func() {
@@ -260,7 +269,8 @@ func (c *NetworkPolicyController) syncNetPol(key string) error {
 		// netPolController does not need to reconcile this update.
 		// In this updateNetworkPolicy event,
 		// newNetPol was updated with states which netPolController does not need to reconcile.
-		if reflect.DeepEqual(cachedNetPolSpecObj, &netPolObj.Spec) {
+		if reflect.DeepEqual(cachedNetPolSpecObj, &netPolObj.Spec) &&
+			c.rawFQDNMap[key] == netPolObj.Annotations[translation.FQDNEgressAnnotation] {
 			return nil
 		}
 	}
@@ -324,6 +334,11 @@ func (c *NetworkPolicyController) syncAddAndUpdateNetPol(netPolObj *networkingv1
 	}
 
 	c.rawNpSpecMap[netpolKey] = &netPolObj.Spec
+	if fqdns, ok := netPolObj.Annotations[translation.FQDNEgressAnnotation]; ok {
+		c.rawFQDNMap[netpolKey] = fqdns
+	} else {
+		delete(c.rawFQDNMap, netpolKey)
+	}
 	return operationKind, nil
 }
 
@@ -342,6 +357,7 @@ func (c *NetworkPolicyController) cleanUpNetworkPolicy(netPolKey string) error {
 
 	// Success to clean up ipset and iptables operations in kernel and delete the cached network policy from RawNpMap
 	delete(c.rawNpSpecMap, netPolKey)
+	delete(c.rawFQDNMap, netPolKey)
 	metrics.DecNumPolicies()
 	return nil
 }
@@ -350,5 +366,6 @@ func isUnsupportedWindowsTranslationErr(err error) bool {
 	return errors.Is(err, translation.ErrUnsupportedNamedPort) ||
 		errors.Is(err, translation.ErrUnsupportedNegativeMatch) ||
 		errors.Is(err, translation.ErrUnsupportedSCTP) ||
-		errors.Is(err, translation.ErrUnsupportedExceptCIDR)
+		errors.Is(err, translation.ErrUnsupportedExceptCIDR) ||
+		errors.Is(err, translation.ErrUnsupportedFQDN)
 }
@@ -9,8 +9,10 @@ import (
 
 	"github.com/Azure/azure-container-networking/npm/metrics"
 	"github.com/Azure/azure-container-networking/npm/metrics/promutil"
+	"github.com/Azure/azure-container-networking/npm/pkg/controlplane/translation"
 	"github.com/Azure/azure-container-networking/npm/pkg/dataplane"
 	dpmocks "github.com/Azure/azure-container-networking/npm/pkg/dataplane/mocks"
+	"github.com/Azure/azure-container-networking/npm/util"
 	gomock "github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
@@ -391,6 +393,48 @@ func TestUpdateNetworkPolicy(t *testing.T) {
 	checkNetPolTestResult("TestUpdateNetPol", f, testCases)
 }
 
+func TestFQDNAnnotationUpdateNetworkPolicy(t *testing.T) {
+	oldNetPolObj := createNetPol()
+
+	f := newNetPolFixture(t)
+	f.netPolLister = append(f.netPolLister, oldNetPolObj)
+	f.kubeobjects = append(f.kubeobjects, oldNetPolObj)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dp := dpmocks.NewMockGenericDataplane(ctrl)
+	f.newNetPolController(stopCh, dp)
+
+	newNetPolObj := oldNetPolObj.DeepCopy()
+	// only the annotation changes, but the policy must be translated again
+	newNetPolObj.Annotations = map[string]string{translation.FQDNEgressAnnotation: "*.blob.core.windows.net"}
+	// oldNetPolObj.ResourceVersion value is "0"
+	newRV, _ := strconv.Atoi(oldNetPolObj.ResourceVersion)
+	newNetPolObj.ResourceVersion = fmt.Sprintf("%d", newRV+1)
+
+	if util.IsWindowsDP() {
+		// FQDN egress rules are unsupported in Windows, so the update is a no-op
+		dp.EXPECT().UpdatePolicy(gomock.Any()).Times(1)
+		updateNetPol(t, f, oldNetPolObj, newNetPolObj)
+		testCases := []expectedNetPolValues{
+			{1, 0, netPolPromVals{1, 1, 0, 0}},
+		}
+		checkNetPolTestResult("TestFQDNAnnotationUpdateNetworkPolicy", f, testCases)
+		return
+	}
+
+	dp.EXPECT().UpdatePolicy(gomock.Any()).Times(2)
+	updateNetPol(t, f, oldNetPolObj, newNetPolObj)
+	require.Equal(t, "*.blob.core.windows.net", f.netPolController.rawFQDNMap["test-nwpolicy/allow-ingress"])
+
+	testCases := []expectedNetPolValues{
+		{1, 0, netPolPromVals{1, 1, 1, 0}},
+	}
+	checkNetPolTestResult("TestFQDNAnnotationUpdateNetworkPolicy", f, testCases)
+}
+
 func TestLabelUpdateNetworkPolicy(t *testing.T) {
 	oldNetPolObj := createNetPol()
 
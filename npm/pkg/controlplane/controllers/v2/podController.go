@@ -77,6 +77,8 @@ func (c *PodController) MarshalJSON() ([]byte, error) {
 }
 
 func (c *PodController) LengthOfPodMap() int {
+	c.RLock()
+	defer c.RUnlock()
 	return len(c.podMap)
 }
 
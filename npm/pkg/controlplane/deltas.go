@@ -0,0 +1,177 @@
+package controlplane
+
+import (
+	"fmt"
+	"reflect"
+
+	dp "github.com/Azure/azure-container-networking/npm/pkg/dataplane"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/policies"
+	"github.com/Azure/azure-container-networking/npm/pkg/protos"
+	npmerrors "github.com/Azure/azure-container-networking/npm/util/errors"
+)
+
+// IPSetDeltas are sent to daemons with API version V2 when only members of a hash set changed,
+// instead of the whole gob-encoded ControllerIPSets.
+// Likewise, PolicyDeltas are sent when only the rules (ACLs) of a NetworkPolicy changed,
+// instead of the whole gob-encoded NPMNetworkPolicy.
+
+// NewIPSetMember converts pod metadata to a member of an IPSetDelta
+func NewIPSetMember(podMetadata *dp.PodMetadata) *protos.IPSetMember {
+	return &protos.IPSetMember{
+		Ip:       podMetadata.PodIP,
+		PodKey:   podMetadata.PodKey,
+		NodeName: podMetadata.NodeName,
+	}
+}
+
+// PodMetadataFromIPSetMember converts a member of an IPSetDelta to pod metadata
+func PodMetadataFromIPSetMember(member *protos.IPSetMember) *dp.PodMetadata {
+	return dp.NewPodMetadata(member.GetPodKey(), member.GetIp(), member.GetNodeName())
+}
+
+// IPSetDeltaMetadata returns the metadata of the set in the IPSetDelta
+func IPSetDeltaMetadata(delta *protos.IPSetDelta) *ipsets.IPSetMetadata {
+	return ipsets.NewIPSetMetadata(delta.GetName(), ipsets.SetType(delta.GetType()))
+}
+
+// NewPolicyDelta returns the rules removed from and added to the policy, which keep the order of the rules.
+// It returns nil if other fields of the policy changed, so the policy must be sent whole.
+// The delta has no rules if the rules didn't change.
+func NewPolicyDelta(before, after *policies.NPMNetworkPolicy) *protos.PolicyDelta {
+	beforeWithoutACLs, afterWithoutACLs := *before, *after
+	beforeWithoutACLs.ACLs, afterWithoutACLs.ACLs = nil, nil
+	if !reflect.DeepEqual(beforeWithoutACLs, afterWithoutACLs) {
+		return nil
+	}
+
+	// lengths[i][j] is the length of the longest common subsequence of before.ACLs[i:] and after.ACLs[j:].
+	// Rules outside of it are removed or added.
+	n, m := len(before.ACLs), len(after.ACLs)
+	isEqual := func(i, j int) bool {
+		return reflect.DeepEqual(before.ACLs[i], after.ACLs[j])
+	}
+	lengths := make([][]int, n+1)
+	for i := range lengths {
+		lengths[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if isEqual(i, j) {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	delta := &protos.PolicyDelta{PolicyKey: after.PolicyKey}
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && isEqual(i, j):
+			i++
+			j++
+		case j == m || (i < n && lengths[i+1][j] >= lengths[i][j+1]):
+			delta.Removed = append(delta.Removed, int32(i))
+			i++
+		default:
+			delta.Added = append(delta.Added, newPolicyRule(j, after.ACLs[j]))
+			j++
+		}
+	}
+	return delta
+}
+
+// ApplyPolicyDelta returns a copy of the policy with the rules of the delta removed and added.
+// The copy shares the rules which weren't removed with the policy.
+func ApplyPolicyDelta(netpol *policies.NPMNetworkPolicy, delta *protos.PolicyDelta) (*policies.NPMNetworkPolicy, error) {
+	removed := make(map[int]struct{}, len(delta.GetRemoved()))
+	for _, index := range delta.GetRemoved() {
+		if index < 0 || int(index) >= len(netpol.ACLs) {
+			return nil, npmerrors.SimpleError(fmt.Sprintf("removed rule %d of policy %s is out of range", index, netpol.PolicyKey))
+		}
+		removed[int(index)] = struct{}{}
+	}
+
+	acls := make([]*policies.ACLPolicy, 0, len(netpol.ACLs)-len(removed)+len(delta.GetAdded()))
+	for i, acl := range netpol.ACLs {
+		if _, ok := removed[i]; !ok {
+			acls = append(acls, acl)
+		}
+	}
+	for _, rule := range delta.GetAdded() {
+		index := int(rule.GetIndex())
+		if index < 0 || index > len(acls) {
+			return nil, npmerrors.SimpleError(fmt.Sprintf("added rule %d of policy %s is out of range", index, netpol.PolicyKey))
+		}
+		acls = append(acls, nil)
+		copy(acls[index+1:], acls[index:])
+		acls[index] = aclFromPolicyRule(rule)
+	}
+
+	updated := *netpol
+	updated.ACLs = acls
+	return &updated, nil
+}
+
+func newPolicyRule(index int, acl *policies.ACLPolicy) *protos.PolicyRule {
+	return &protos.PolicyRule{
+		Index:     int32(index),
+		Comment:   acl.Comment,
+		SrcList:   newPolicySetInfos(acl.SrcList),
+		DstList:   newPolicySetInfos(acl.DstList),
+		Target:    string(acl.Target),
+		Direction: string(acl.Direction),
+		Port:      acl.DstPorts.Port,
+		EndPort:   acl.DstPorts.EndPort,
+		Protocol:  string(acl.Protocol),
+	}
+}
+
+func aclFromPolicyRule(rule *protos.PolicyRule) *policies.ACLPolicy {
+	return &policies.ACLPolicy{
+		Comment:   rule.GetComment(),
+		SrcList:   setInfosFromPolicySetInfos(rule.GetSrcList()),
+		DstList:   setInfosFromPolicySetInfos(rule.GetDstList()),
+		Target:    policies.Verdict(rule.GetTarget()),
+		Direction: policies.Direction(rule.GetDirection()),
+		DstPorts: policies.Ports{
+			Port:    rule.GetPort(),
+			EndPort: rule.GetEndPort(),
+		},
+		Protocol: policies.Protocol(rule.GetProtocol()),
+	}
+}
+
+func newPolicySetInfos(setInfos []policies.SetInfo) []*protos.PolicySetInfo {
+	if len(setInfos) == 0 {
+		return nil
+	}
+	policySetInfos := make([]*protos.PolicySetInfo, 0, len(setInfos))
+	for _, setInfo := range setInfos {
+		policySetInfos = append(policySetInfos, &protos.PolicySetInfo{
+			Name:      setInfo.IPSet.Name,
+			Type:      int32(setInfo.IPSet.Type),
+			Included:  setInfo.Included,
+			MatchType: int32(setInfo.MatchType),
+		})
+	}
+	return policySetInfos
+}
+
+// setInfosFromPolicySetInfos returns nil for no sets, like a gob-decoded policy
+func setInfosFromPolicySetInfos(policySetInfos []*protos.PolicySetInfo) []policies.SetInfo {
+	if len(policySetInfos) == 0 {
+		return nil
+	}
+	setInfos := make([]policies.SetInfo, 0, len(policySetInfos))
+	for _, policySetInfo := range policySetInfos {
+		setInfos = append(setInfos, policies.SetInfo{
+			IPSet:     ipsets.NewIPSetMetadata(policySetInfo.GetName(), ipsets.SetType(policySetInfo.GetType())),
+			Included:  policySetInfo.GetIncluded(),
+			MatchType: policies.MatchType(policySetInfo.GetMatchType()),
+		})
+	}
+	return setInfos
+}
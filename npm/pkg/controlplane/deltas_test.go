@@ -0,0 +1,77 @@
+package controlplane
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/policies"
+	"github.com/stretchr/testify/require"
+)
+
+func deltaTestACL(comment string, port int32) *policies.ACLPolicy {
+	return &policies.ACLPolicy{
+		Comment:   comment,
+		Target:    policies.Allowed,
+		Direction: policies.Ingress,
+		SrcList: []policies.SetInfo{
+			{IPSet: ipsets.NewIPSetMetadata("role:client", ipsets.KeyValueLabelOfPod), Included: true, MatchType: policies.SrcMatch},
+		},
+		DstPorts: policies.Ports{Port: port, EndPort: port},
+		Protocol: policies.TCP,
+	}
+}
+
+func deltaTestPolicy(acls ...*policies.ACLPolicy) *policies.NPMNetworkPolicy {
+	selector := ipsets.NewIPSetMetadata("app:web", ipsets.KeyValueLabelOfPod)
+	return &policies.NPMNetworkPolicy{
+		Namespace:         "x",
+		PolicyKey:         "x/web",
+		PodSelectorIPSets: []*ipsets.TranslatedIPSet{{Metadata: selector}},
+		PodSelectorList:   []policies.SetInfo{{IPSet: selector, Included: true, MatchType: policies.EitherMatch}},
+		ACLs:              acls,
+	}
+}
+
+func TestPolicyDelta(t *testing.T) {
+	a, b, c := deltaTestACL("a", 80), deltaTestACL("b", 443), deltaTestACL("c", 8080)
+	d, e := deltaTestACL("d", 53), deltaTestACL("e", 9090)
+	before := deltaTestPolicy(a, b, c)
+	after := deltaTestPolicy(b, d, c, e)
+
+	delta := NewPolicyDelta(before, after)
+	require.NotNil(t, delta)
+	require.Equal(t, "x/web", delta.GetPolicyKey())
+	require.Equal(t, []int32{0}, delta.GetRemoved())
+	require.Len(t, delta.GetAdded(), 2)
+	require.Equal(t, int32(1), delta.GetAdded()[0].GetIndex())
+	require.Equal(t, int32(3), delta.GetAdded()[1].GetIndex())
+
+	// the daemon's policy went through gob, and ends up with the same digest as the controller's
+	payload, err := EncodeNPMNetworkPolicies([]*policies.NPMNetworkPolicy{before})
+	require.NoError(t, err)
+	decoded, err := DecodeNPMNetworkPolicies(payload)
+	require.NoError(t, err)
+	updated, err := ApplyPolicyDelta(decoded[0], delta)
+	require.NoError(t, err)
+	require.Equal(t, after.ACLs, updated.ACLs)
+	expectedDigest, err := PolicyDigest(after)
+	require.NoError(t, err)
+	digest, err := PolicyDigest(updated)
+	require.NoError(t, err)
+	require.Equal(t, expectedDigest, digest)
+
+	// unchanged rules give an empty delta
+	delta = NewPolicyDelta(before, deltaTestPolicy(a, b, c))
+	require.NotNil(t, delta)
+	require.Empty(t, delta.GetRemoved())
+	require.Empty(t, delta.GetAdded())
+
+	// other changes can't be sent as a delta
+	changedSelector := deltaTestPolicy(a, b, c)
+	changedSelector.PodSelectorList[0].Included = false
+	require.Nil(t, NewPolicyDelta(before, changedSelector))
+
+	// deltas which don't fit the policy are rejected
+	_, err = ApplyPolicyDelta(deltaTestPolicy(a), NewPolicyDelta(before, after))
+	require.Error(t, err)
+}
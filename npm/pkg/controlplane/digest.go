@@ -0,0 +1,83 @@
+package controlplane
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	dp "github.com/Azure/azure-container-networking/npm/pkg/dataplane"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/policies"
+	npmerrors "github.com/Azure/azure-container-networking/npm/util/errors"
+)
+
+// Digests identify the content of the IPSets and NetworkPolicies applied by a daemon.
+// A reconnecting daemon sends its digests so the controller only sends what differs.
+// Both sides compute digests from the objects sent by the controller, so they only need to be stable within a version of NPM.
+
+// SetDigest returns a digest of the members of the set.
+// It's the XOR of the digests of each member, so a daemon can update it with member deltas (see UpdateSetDigest).
+func SetDigest(set *ControllerIPSets) string {
+	var sum [sha256.Size]byte
+	for ip, podMetadata := range set.IPPodMetadata {
+		xorDigest(&sum, memberDigest(ip, podMetadata))
+	}
+	for memberName := range set.MemberIPSets {
+		xorDigest(&sum, sha256.Sum256([]byte("set:"+memberName)))
+	}
+	return hex.EncodeToString(sum[:])
+}
+
+// UpdateSetDigest returns the digest of a hash set after removing and then adding the members.
+// Removed members must be in the set, and added members must not be, for the digest to match SetDigest.
+func UpdateSetDigest(digest string, removed, added []*dp.PodMetadata) (string, error) {
+	b, err := hex.DecodeString(digest)
+	if err != nil || len(b) != sha256.Size {
+		return "", npmerrors.SimpleError(fmt.Sprintf("invalid set digest %q", digest))
+	}
+
+	var sum [sha256.Size]byte
+	copy(sum[:], b)
+	for _, podMetadata := range removed {
+		xorDigest(&sum, memberDigest(podMetadata.PodIP, podMetadata))
+	}
+	for _, podMetadata := range added {
+		xorDigest(&sum, memberDigest(podMetadata.PodIP, podMetadata))
+	}
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// PolicyDigest returns a digest of the policy
+func PolicyDigest(netpol *policies.NPMNetworkPolicy) (string, error) {
+	// the daemon's policy went through gob, so normalize this policy the same way
+	payload, err := EncodeNPMNetworkPolicies([]*policies.NPMNetworkPolicy{netpol})
+	if err != nil {
+		return "", err
+	}
+	netpols, err := DecodeNPMNetworkPolicies(payload)
+	if err != nil {
+		return "", err
+	}
+
+	// json sorts map keys, so the encoding is deterministic unlike gob
+	b, err := json.Marshal(netpols[0])
+	if err != nil {
+		return "", npmerrors.SimpleErrorWrapper("failed to encode policy for digest", err)
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func memberDigest(ip string, podMetadata *dp.PodMetadata) [sha256.Size]byte {
+	if podMetadata == nil {
+		return sha256.Sum256([]byte("ip:" + ip))
+	}
+	return sha256.Sum256([]byte("ip:" + strings.Join([]string{ip, podMetadata.PodKey, podMetadata.NodeName}, ",")))
+}
+
+func xorDigest(sum *[sha256.Size]byte, digest [sha256.Size]byte) {
+	for i := range sum {
+		sum[i] ^= digest[i]
+	}
+}
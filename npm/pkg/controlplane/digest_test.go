@@ -0,0 +1,81 @@
+package controlplane
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/policies"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetDigest(t *testing.T) {
+	set := NewControllerIPSets(ipsets.NewIPSetMetadata("app:web", ipsets.KeyValueLabelOfPod))
+	emptyDigest := SetDigest(set)
+
+	set.IPPodMetadata["10.0.0.1"] = dataplane.NewPodMetadata("x/a", "10.0.0.1", "node-a")
+	set.IPPodMetadata["10.0.0.2"] = dataplane.NewPodMetadata("x/b", "10.0.0.2", "node-a")
+	digest := SetDigest(set)
+	require.NotEqual(t, emptyDigest, digest)
+
+	// the pod key is part of the digest
+	set.IPPodMetadata["10.0.0.2"] = dataplane.NewPodMetadata("x/c", "10.0.0.2", "node-a")
+	require.NotEqual(t, digest, SetDigest(set))
+
+	payload, err := EncodeControllerIPSets([]*ControllerIPSets{set})
+	require.NoError(t, err)
+	decoded, err := DecodeControllerIPSets(payload)
+	require.NoError(t, err)
+	require.Equal(t, SetDigest(set), SetDigest(decoded[0]))
+}
+
+func TestUpdateSetDigest(t *testing.T) {
+	podA := dataplane.NewPodMetadata("x/a", "10.0.0.1", "node-a")
+	podB := dataplane.NewPodMetadata("x/b", "10.0.0.2", "node-a")
+	podC := dataplane.NewPodMetadata("x/c", "10.0.0.2", "node-b")
+
+	set := NewControllerIPSets(ipsets.NewIPSetMetadata("app:web", ipsets.KeyValueLabelOfPod))
+	set.IPPodMetadata[podA.PodIP] = podA
+	set.IPPodMetadata[podB.PodIP] = podB
+	digest := SetDigest(set)
+
+	// podC reuses podB's IP on another node
+	updatedDigest, err := UpdateSetDigest(digest, []*dataplane.PodMetadata{podB}, []*dataplane.PodMetadata{podC})
+	require.NoError(t, err)
+	set.IPPodMetadata[podC.PodIP] = podC
+	require.Equal(t, SetDigest(set), updatedDigest)
+
+	updatedDigest, err = UpdateSetDigest(updatedDigest, []*dataplane.PodMetadata{podA, podC}, nil)
+	require.NoError(t, err)
+	require.Equal(t, SetDigest(NewControllerIPSets(set.IPSetMetadata)), updatedDigest)
+
+	_, err = UpdateSetDigest("not-a-digest", nil, nil)
+	require.Error(t, err)
+}
+
+func TestPolicyDigest(t *testing.T) {
+	netpol := &policies.NPMNetworkPolicy{
+		Namespace:    "x",
+		PolicyKey:    "x/web",
+		PodEndpoints: map[string]string{},
+		ACLs: []*policies.ACLPolicy{
+			{Target: policies.Allowed, Direction: policies.Ingress},
+		},
+	}
+	digest, err := PolicyDigest(netpol)
+	require.NoError(t, err)
+
+	payload, err := EncodeNPMNetworkPolicies([]*policies.NPMNetworkPolicy{netpol})
+	require.NoError(t, err)
+	decoded, err := DecodeNPMNetworkPolicies(bytes.NewBuffer(payload.Bytes()))
+	require.NoError(t, err)
+	decodedDigest, err := PolicyDigest(decoded[0])
+	require.NoError(t, err)
+	require.Equal(t, digest, decodedDigest)
+
+	netpol.ACLs[0].Target = policies.Dropped
+	changedDigest, err := PolicyDigest(netpol)
+	require.NoError(t, err)
+	require.NotEqual(t, digest, changedDigest)
+}
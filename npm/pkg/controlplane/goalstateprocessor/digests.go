@@ -0,0 +1,91 @@
+package goalstateprocessor
+
+import (
+	"sync"
+
+	cp "github.com/Azure/azure-container-networking/npm/pkg/controlplane"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane"
+	"k8s.io/klog"
+)
+
+// appliedDigests holds the digests of the IPSets and NetworkPolicies applied from the controller's events.
+// The events client sends them when reconnecting, so a new controller only sends what changed.
+type appliedDigests struct {
+	sync.Mutex
+	ipsets   map[string]string
+	policies map[string]string
+}
+
+func newAppliedDigests() *appliedDigests {
+	return &appliedDigests{
+		ipsets:   make(map[string]string),
+		policies: make(map[string]string),
+	}
+}
+
+func (d *appliedDigests) setIPSet(setName, digest string) {
+	d.Lock()
+	defer d.Unlock()
+	d.ipsets[setName] = digest
+}
+
+// updateIPSet updates the digest of a set after applying a delta. Sets without a digest are left without one.
+func (d *appliedDigests) updateIPSet(setName string, removed, added []*dataplane.PodMetadata) {
+	d.Lock()
+	defer d.Unlock()
+
+	digest, ok := d.ipsets[setName]
+	if !ok {
+		return
+	}
+	digest, err := cp.UpdateSetDigest(digest, removed, added)
+	if err != nil {
+		klog.Errorf("failed to update digest of set %s: %v", setName, err)
+		delete(d.ipsets, setName)
+		return
+	}
+	d.ipsets[setName] = digest
+}
+
+func (d *appliedDigests) deleteIPSet(setName string) {
+	d.Lock()
+	defer d.Unlock()
+	delete(d.ipsets, setName)
+}
+
+func (d *appliedDigests) setPolicy(policyKey, digest string) {
+	d.Lock()
+	defer d.Unlock()
+	d.policies[policyKey] = digest
+}
+
+func (d *appliedDigests) deletePolicy(policyKey string) {
+	d.Lock()
+	defer d.Unlock()
+	delete(d.policies, policyKey)
+}
+
+// reset forgets all digests, e.g. when an event failed to apply and the dataplane state is unknown
+func (d *appliedDigests) reset() {
+	d.Lock()
+	defer d.Unlock()
+	d.ipsets = make(map[string]string)
+	d.policies = make(map[string]string)
+}
+
+// StateDigests returns copies of the digests of the applied IPSets and NetworkPolicies.
+// Both are empty if an event failed to apply since the last hydration.
+func (gsp *GoalStateProcessor) StateDigests() (ipsetDigests, policyDigests map[string]string) {
+	gsp.digests.Lock()
+	defer gsp.digests.Unlock()
+
+	ipsetDigests = make(map[string]string, len(gsp.digests.ipsets))
+	for setName, digest := range gsp.digests.ipsets {
+		ipsetDigests[setName] = digest
+	}
+	policyDigests = make(map[string]string, len(gsp.digests.policies))
+	for policyKey, digest := range gsp.digests.policies {
+		policyDigests[policyKey] = digest
+	}
+	return ipsetDigests, policyDigests
+}
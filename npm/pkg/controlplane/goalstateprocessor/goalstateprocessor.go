@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"sync"
 
 	cp "github.com/Azure/azure-container-networking/npm/pkg/controlplane"
 	"github.com/Azure/azure-container-networking/npm/pkg/dataplane"
 	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/policies"
 	"github.com/Azure/azure-container-networking/npm/pkg/protos"
 	"github.com/Azure/azure-container-networking/npm/util"
 	npmerrors "github.com/Azure/azure-container-networking/npm/util/errors"
@@ -24,6 +26,23 @@ type GoalStateProcessor struct {
 	dp             dataplane.GenericDataplane
 	inputChannel   chan *protos.Events
 	backoffChannel chan *protos.Events
+	// ackChannel receives an ack after each event is processed, if set
+	ackChannel chan<- *protos.GoalStateAck
+	digests    *appliedDigests
+	// netpols holds the policies as received from the controlplane, to apply PolicyDeltas to.
+	// The dataplane gets copies, since it may modify them.
+	netpols map[string]*policies.NPMNetworkPolicy
+
+	lastAppliedMu sync.Mutex
+	lastApplied   AppliedGoalState
+}
+
+// AppliedGoalState describes the last event processed by the GoalStateProcessor
+type AppliedGoalState struct {
+	// Generation is the goal state generation of the event
+	Generation uint64
+	// Error is the error encountered while processing the event, if any
+	Error string `json:",omitempty"`
 }
 
 func NewGoalStateProcessor(
@@ -31,6 +50,7 @@ func NewGoalStateProcessor(
 	nodeID string,
 	podName string,
 	inputChan chan *protos.Events,
+	ackChan chan<- *protos.GoalStateAck,
 	dp dataplane.GenericDataplane) (*GoalStateProcessor, error) {
 
 	if nodeID == "" || podName == "" {
@@ -46,6 +66,9 @@ func NewGoalStateProcessor(
 		dp:             dp,
 		inputChannel:   inputChan,
 		backoffChannel: make(chan *protos.Events),
+		ackChannel:     ackChan,
+		digests:        newAppliedDigests(),
+		netpols:        make(map[string]*policies.NPMNetworkPolicy),
 	}, nil
 }
 
@@ -75,14 +98,14 @@ func (gsp *GoalStateProcessor) processNext(stopCh <-chan struct{}) bool {
 	case inputEvents := <-gsp.inputChannel:
 		// TODO remove this large print later
 		klog.Infof("Received event %s", inputEvents)
-		gsp.process(inputEvents)
+		gsp.ack(inputEvents, gsp.processAndTrack(inputEvents))
 		return true
 	case backoffEvents := <-gsp.backoffChannel:
 		// For now keep it simple. Do not worry about backoff events
 		// but if we need to handle them, we can do it here.
 		// TODO remove this large print later
 		klog.Infof("Received backoff event %s", backoffEvents)
-		gsp.process(backoffEvents)
+		gsp.ack(backoffEvents, gsp.processAndTrack(backoffEvents))
 		return true
 
 	case <-gsp.ctx.Done():
@@ -94,36 +117,87 @@ func (gsp *GoalStateProcessor) processNext(stopCh <-chan struct{}) bool {
 	}
 }
 
-func (gsp *GoalStateProcessor) process(inputEvent *protos.Events) {
+// ack reports the generation of the processed event and its processing error to the controlplane
+func (gsp *GoalStateProcessor) ack(inputEvent *protos.Events, err error) {
+	if gsp.ackChannel == nil {
+		return
+	}
+
+	ack := &protos.GoalStateAck{
+		PodName:    gsp.podName,
+		NodeName:   gsp.nodeID,
+		Generation: inputEvent.GetGeneration(),
+	}
+	if err != nil {
+		ack.Error = err.Error()
+	}
+
+	select {
+	case gsp.ackChannel <- ack:
+	case <-gsp.ctx.Done():
+	}
+}
+
+// processAndTrack processes the event and forgets the applied digests if it fails
+func (gsp *GoalStateProcessor) processAndTrack(inputEvent *protos.Events) error {
+	err := gsp.process(inputEvent)
+	if err != nil {
+		gsp.digests.reset()
+	}
+
+	applied := AppliedGoalState{Generation: inputEvent.GetGeneration()}
+	if err != nil {
+		applied.Error = err.Error()
+	}
+	gsp.lastAppliedMu.Lock()
+	gsp.lastApplied = applied
+	gsp.lastAppliedMu.Unlock()
+	return err
+}
+
+// LastApplied returns the last event processed, for debugging
+func (gsp *GoalStateProcessor) LastApplied() AppliedGoalState {
+	gsp.lastAppliedMu.Lock()
+	defer gsp.lastAppliedMu.Unlock()
+	return gsp.lastApplied
+}
+
+// process applies the event to the dataplane and returns the first error encountered
+func (gsp *GoalStateProcessor) process(inputEvent *protos.Events) (err error) {
 	klog.Infof("Processing event")
 	// apply dataplane after syncing
 	defer func() {
 		dperr := gsp.dp.ApplyDataPlane()
 		if dperr != nil {
 			klog.Errorf("Apply Dataplane failed with %v", dperr)
+			if err == nil {
+				err = npmerrors.SimpleErrorWrapper("failed to apply dataplane", dperr)
+			}
 		}
 	}()
 
 	payload := inputEvent.GetPayload()
 	if !validatePayload(payload) {
 		klog.Warningf("Empty payload in event %s", inputEvent)
-		return
+		return nil
 	}
 
 	switch inputEvent.GetEventType() {
 	case protos.Events_Hydration:
 		// in hydration event, any thing in local cache and not in event should be deleted.
 		klog.Infof("Received hydration event")
-		gsp.processHydrationEvent(payload)
+		return gsp.processHydrationEvent(payload)
 	case protos.Events_GoalState:
 		klog.Infof("Received goal state event")
-		gsp.processGoalStateEvent(payload)
+		return gsp.processGoalStateEvent(payload)
 	default:
 		klog.Errorf("Received unknown event type %s", inputEvent.GetEventType())
+		return npmerrors.SimpleError(fmt.Sprintf("unknown event type %s", inputEvent.GetEventType()))
 	}
 }
 
-func (gsp *GoalStateProcessor) processHydrationEvent(payload map[string]*protos.GoalState) {
+// processHydrationEvent returns the first error encountered, but keeps processing the rest of the payload
+func (gsp *GoalStateProcessor) processHydrationEvent(payload map[string]*protos.GoalState) error {
 	// Hydration events are sent when the daemon first starts up, or a reconnection to controller happens.
 	// In this case, the controller will send a current state of the cache down to daemon.
 	// Daemon will need to calculate what updates and deleted have been missed and send them to the dataplane.
@@ -137,12 +211,18 @@ func (gsp *GoalStateProcessor) processHydrationEvent(payload map[string]*protos.
 
 	var appendedIPSets map[string]struct{}
 	var appendedPolicies map[string]struct{}
-	var err error
+	var err, firstErr error
+	setErr := func(err error) {
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
 
 	if ipsetApplyPayload, ok := payload[cp.IpsetApply]; ok {
 		appendedIPSets, err = gsp.processIPSetsApplyEvent(ipsetApplyPayload)
 		if err != nil {
 			klog.Errorf("Error processing IPSET apply HYDRATION event %s", err)
+			setErr(err)
 		}
 	}
 
@@ -150,6 +230,13 @@ func (gsp *GoalStateProcessor) processHydrationEvent(payload map[string]*protos.
 		appendedPolicies, err = gsp.processPolicyApplyEvent(policyApplyPayload)
 		if err != nil {
 			klog.Errorf("Error processing POLICY apply HYDRATION event %s", err)
+			setErr(err)
+		}
+	}
+
+	for policyKey := range gsp.netpols {
+		if _, ok := appendedPolicies[policyKey]; !ok {
+			delete(gsp.netpols, policyKey)
 		}
 	}
 
@@ -170,6 +257,7 @@ func (gsp *GoalStateProcessor) processHydrationEvent(payload map[string]*protos.
 		err = gsp.processPolicyRemoveEvent(toDeletePolicies)
 		if err != nil {
 			klog.Errorf("Error processing POLICY remove HYDRATION event %s", err)
+			setErr(err)
 		}
 	}
 
@@ -198,18 +286,38 @@ func (gsp *GoalStateProcessor) processHydrationEvent(payload map[string]*protos.
 		klog.Infof("Deleting %d ipsets", len(toDeleteIPSets))
 		gsp.processIPSetsRemoveEvent(toDeleteIPSets, util.ForceDelete)
 	}
+	return firstErr
 }
 
-func (gsp *GoalStateProcessor) processGoalStateEvent(payload map[string]*protos.GoalState) {
+// processGoalStateEvent returns the first error encountered, but keeps processing the rest of the payload
+func (gsp *GoalStateProcessor) processGoalStateEvent(payload map[string]*protos.GoalState) error {
+	var firstErr error
+	setErr := func(err error) {
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
 	// Process these individual buckets in order
 	// 1. Apply IPSET
-	// 2. Apply POLICY
-	// 3. Remove POLICY
-	// 4. Remove IPSET
+	// 2. Apply IPSET deltas
+	// 3. Apply POLICY
+	// 4. Apply POLICY deltas
+	// 5. Remove POLICY
+	// 6. Remove IPSET
 	if ipsetApplyPayload, ok := payload[cp.IpsetApply]; ok {
 		_, err := gsp.processIPSetsApplyEvent(ipsetApplyPayload)
 		if err != nil {
 			klog.Errorf("Error processing IPSET apply event %s", err)
+			setErr(err)
+		}
+	}
+
+	if ipsetDeltaPayload, ok := payload[cp.IpsetDelta]; ok {
+		err := gsp.processIPSetDeltas(ipsetDeltaPayload.GetIpsetDeltas())
+		if err != nil {
+			klog.Errorf("Error processing IPSET delta event %s", err)
+			setErr(err)
 		}
 	}
 
@@ -217,6 +325,15 @@ func (gsp *GoalStateProcessor) processGoalStateEvent(payload map[string]*protos.
 		_, err := gsp.processPolicyApplyEvent(policyApplyPayload)
 		if err != nil {
 			klog.Errorf("Error processing POLICY apply event %s", err)
+			setErr(err)
+		}
+	}
+
+	if policyDeltaPayload, ok := payload[cp.PolicyDelta]; ok {
+		err := gsp.processPolicyDeltas(policyDeltaPayload.GetPolicyDeltas())
+		if err != nil {
+			klog.Errorf("Error processing POLICY delta event %s", err)
+			setErr(err)
 		}
 	}
 
@@ -225,10 +342,12 @@ func (gsp *GoalStateProcessor) processGoalStateEvent(payload map[string]*protos.
 		netpolNames, err := cp.DecodeStrings(payload)
 		if err != nil {
 			klog.Errorf("Error processing POLICY remove event, failed to decode Policy remove event %s", err)
+			setErr(npmerrors.SimpleErrorWrapper("failed to decode Policy remove event", err))
 		}
 		err = gsp.processPolicyRemoveEvent(netpolNames)
 		if err != nil {
 			klog.Errorf("Error processing POLICY remove event %s", err)
+			setErr(err)
 		}
 	}
 
@@ -237,9 +356,11 @@ func (gsp *GoalStateProcessor) processGoalStateEvent(payload map[string]*protos.
 		ipsetNames, err := cp.DecodeStrings(payload)
 		if err != nil {
 			klog.Errorf("Error processing IPSET remove event, failed to decode IPSet remove event: %s", err)
+			setErr(npmerrors.SimpleErrorWrapper("failed to decode IPSet remove event", err))
 		}
 		gsp.processIPSetsRemoveEvent(ipsetNames, util.SoftDelete)
 	}
+	return firstErr
 }
 
 func (gsp *GoalStateProcessor) processIPSetsApplyEvent(goalState *protos.GoalState) (map[string]struct{}, error) {
@@ -284,17 +405,48 @@ func (gsp *GoalStateProcessor) processIPSetsApplyEvent(goalState *protos.GoalSta
 			)
 		}
 		appendedIPSets[ipsetName] = struct{}{}
+		gsp.digests.setIPSet(ipsetName, cp.SetDigest(ipset))
 	}
 	return appendedIPSets, nil
 }
 
+// processIPSetDeltas applies the member changes of hash sets, which are sent instead of the sets since this client has API version V2
+func (gsp *GoalStateProcessor) processIPSetDeltas(deltas []*protos.IPSetDelta) error {
+	for _, delta := range deltas {
+		setMetadata := cp.IPSetDeltaMetadata(delta)
+		klog.Infof("Processing %s IPSET delta event", setMetadata.GetPrefixName())
+
+		removed := make([]*dataplane.PodMetadata, 0, len(delta.GetRemoved()))
+		for _, member := range delta.GetRemoved() {
+			podMetadata := cp.PodMetadataFromIPSetMember(member)
+			err := gsp.dp.RemoveFromSets([]*ipsets.IPSetMetadata{setMetadata}, podMetadata)
+			if err != nil {
+				return npmerrors.SimpleErrorWrapper("IPSet delta event, failed at RemoveFromSets.", err)
+			}
+			removed = append(removed, podMetadata)
+		}
+
+		added := make([]*dataplane.PodMetadata, 0, len(delta.GetAdded()))
+		for _, member := range delta.GetAdded() {
+			podMetadata := cp.PodMetadataFromIPSetMember(member)
+			err := gsp.dp.AddToSets([]*ipsets.IPSetMetadata{setMetadata}, podMetadata)
+			if err != nil {
+				return npmerrors.SimpleErrorWrapper("IPSet delta event, failed at AddToSets.", err)
+			}
+			added = append(added, podMetadata)
+		}
+		gsp.digests.updateIPSet(setMetadata.GetPrefixName(), removed, added)
+	}
+	return nil
+}
+
 func (gsp *GoalStateProcessor) applySets(ipSet *cp.ControllerIPSets, cachedIPSet *ipsets.IPSet) error {
+	setMetadata := ipSet.GetMetadata()
 	if len(ipSet.IPPodMetadata) == 0 {
-		gsp.dp.CreateIPSets([]*ipsets.IPSetMetadata{ipSet.GetMetadata()})
-		return nil
+		// the set may still have members to remove below
+		gsp.dp.CreateIPSets([]*ipsets.IPSetMetadata{setMetadata})
 	}
 
-	setMetadata := ipSet.GetMetadata()
 	for _, podMetadata := range ipSet.IPPodMetadata {
 		err := gsp.dp.AddToSets([]*ipsets.IPSetMetadata{setMetadata}, podMetadata)
 		if err != nil {
@@ -305,7 +457,7 @@ func (gsp *GoalStateProcessor) applySets(ipSet *cp.ControllerIPSets, cachedIPSet
 	if cachedIPSet != nil {
 		for podIP, cachedPodKey := range cachedIPSet.IPPodKey {
 			if _, ok := ipSet.IPPodMetadata[podIP]; !ok {
-				err := gsp.dp.RemoveFromSets([]*ipsets.IPSetMetadata{setMetadata}, dataplane.NewPodMetadata(podIP, cachedPodKey, ""))
+				err := gsp.dp.RemoveFromSets([]*ipsets.IPSetMetadata{setMetadata}, dataplane.NewPodMetadata(cachedPodKey, podIP, ""))
 				if err != nil {
 					return npmerrors.SimpleErrorWrapper("IPSet apply event, failed at RemoveFromSets.", err)
 				}
@@ -358,6 +510,7 @@ func (gsp *GoalStateProcessor) processIPSetsRemoveEvent(ipsetNames []string, for
 			continue
 		}
 		klog.Infof("Processing %s IPSET remove event", ipsetName)
+		gsp.digests.deleteIPSet(ipsetName)
 
 		cachedIPSet := gsp.dp.GetIPSet(ipsetName)
 		if cachedIPSet == nil {
@@ -385,16 +538,65 @@ func (gsp *GoalStateProcessor) processPolicyApplyEvent(goalState *protos.GoalSta
 		klog.Infof("Processing %s Policy ADD event", netpol.PolicyKey)
 		klog.Infof("Netpol: %v", netpol)
 
+		// the dataplane may modify the policy
+		digest, err := cp.PolicyDigest(netpol)
+		if err != nil {
+			return nil, err
+		}
+		received, err := copyPolicy(netpol)
+		if err != nil {
+			return nil, err
+		}
+
 		err = gsp.dp.UpdatePolicy(netpol)
 		if err != nil {
 			klog.Errorf("Error applying policy %s to dataplane with error: %s", netpol.PolicyKey, err.Error())
+			delete(gsp.netpols, netpol.PolicyKey)
 			return nil, npmerrors.SimpleErrorWrapper("failed update policy event", err)
 		}
 		appendedPolicies[netpol.PolicyKey] = struct{}{}
+		gsp.netpols[netpol.PolicyKey] = received
+		gsp.digests.setPolicy(netpol.PolicyKey, digest)
 	}
 	return appendedPolicies, nil
 }
 
+// processPolicyDeltas applies the rule changes of policies, which are sent instead of the policies since this client has API version V2
+func (gsp *GoalStateProcessor) processPolicyDeltas(deltas []*protos.PolicyDelta) error {
+	for _, delta := range deltas {
+		policyKey := delta.GetPolicyKey()
+		klog.Infof("Processing %s Policy delta event", policyKey)
+
+		// the controlplane rehydrates the client if the policy is missing
+		received, ok := gsp.netpols[policyKey]
+		if !ok {
+			return npmerrors.SimpleError(fmt.Sprintf("Policy delta event, policy %s was never received", policyKey))
+		}
+		updated, err := cp.ApplyPolicyDelta(received, delta)
+		if err != nil {
+			return npmerrors.SimpleErrorWrapper("Policy delta event, failed to apply delta.", err)
+		}
+		digest, err := cp.PolicyDigest(updated)
+		if err != nil {
+			return err
+		}
+		netpol, err := copyPolicy(updated)
+		if err != nil {
+			return err
+		}
+
+		err = gsp.dp.UpdatePolicy(netpol)
+		if err != nil {
+			klog.Errorf("Error applying policy delta %s to dataplane with error: %s", policyKey, err.Error())
+			delete(gsp.netpols, policyKey)
+			return npmerrors.SimpleErrorWrapper("failed update policy delta event", err)
+		}
+		gsp.netpols[policyKey] = updated
+		gsp.digests.setPolicy(policyKey, digest)
+	}
+	return nil
+}
+
 func (gsp *GoalStateProcessor) processPolicyRemoveEvent(netpolNames []string) error {
 	for _, netpolName := range netpolNames {
 		klog.Infof("Processing %s Policy remove event", netpolName)
@@ -409,15 +611,30 @@ func (gsp *GoalStateProcessor) processPolicyRemoveEvent(netpolNames []string) er
 			klog.Errorf("Error removing policy %s from dataplane with error: %s", netpolName, err.Error())
 			return npmerrors.SimpleErrorWrapper("failed remove policy event", err)
 		}
+		gsp.digests.deletePolicy(netpolName)
+		delete(gsp.netpols, netpolName)
 	}
 	return nil
 }
 
 func validatePayload(payload map[string]*protos.GoalState) bool {
 	for _, v := range payload {
-		if len(v.GetData()) != 0 {
+		if len(v.GetData()) != 0 || len(v.GetIpsetDeltas()) != 0 || len(v.GetPolicyDeltas()) != 0 {
 			return true
 		}
 	}
 	return false
 }
+
+// copyPolicy returns a deep copy of the policy, which went through gob like the policies sent by the controlplane
+func copyPolicy(netpol *policies.NPMNetworkPolicy) (*policies.NPMNetworkPolicy, error) {
+	payload, err := cp.EncodeNPMNetworkPolicies([]*policies.NPMNetworkPolicy{netpol})
+	if err != nil {
+		return nil, npmerrors.SimpleErrorWrapper("failed to copy policy", err)
+	}
+	netpols, err := cp.DecodeNPMNetworkPolicies(payload)
+	if err != nil {
+		return nil, npmerrors.SimpleErrorWrapper("failed to copy policy", err)
+	}
+	return netpols[0], nil
+}
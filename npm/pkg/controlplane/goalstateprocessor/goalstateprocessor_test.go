@@ -2,6 +2,7 @@ package goalstateprocessor
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -90,7 +91,7 @@ func TestPolicyApplyEvent(t *testing.T) {
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	gsp, _ := NewGoalStateProcessor(ctx, "node1", "pod1", inputChan, dp)
+	gsp, _ := NewGoalStateProcessor(ctx, "node1", "pod1", inputChan, nil, dp)
 
 	go func() {
 		inputChan <- &protos.Events{
@@ -129,7 +130,7 @@ func TestIPSetsApply(t *testing.T) {
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	gsp, _ := NewGoalStateProcessor(ctx, "node1", "pod1", inputChan, dp)
+	gsp, _ := NewGoalStateProcessor(ctx, "node1", "pod1", inputChan, nil, dp)
 	go func() {
 		inputChan <- &protos.Events{
 			Payload: goalState,
@@ -170,7 +171,7 @@ func TestIPSetsApplyUpdateMembers(t *testing.T) {
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	gsp, _ := NewGoalStateProcessor(ctx, "node1", "pod1", inputChan, dp)
+	gsp, _ := NewGoalStateProcessor(ctx, "node1", "pod1", inputChan, nil, dp)
 	go func() {
 		inputChan <- &protos.Events{
 			EventType: protos.Events_GoalState,
@@ -201,6 +202,277 @@ func TestIPSetsApplyUpdateMembers(t *testing.T) {
 	gsp.processNext(wait.NeverStop)
 }
 
+func TestIPSetsApplyRemovesMembersFromEmptySet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cachedSet := ipsets.NewIPSet(testKeyPodSet)
+	cachedSet.IPPodKey["10.0.0.1"] = "x/a"
+
+	dp := dpmocks.NewMockGenericDataplane(ctrl)
+	dp.EXPECT().GetIPSet(testKeyPodSet.GetPrefixName()).Return(cachedSet).Times(1)
+	dp.EXPECT().CreateIPSets([]*ipsets.IPSetMetadata{testKeyPodSet}).Times(1)
+	dp.EXPECT().RemoveFromSets([]*ipsets.IPSetMetadata{testKeyPodSet}, dataplane.NewPodMetadata("x/a", "10.0.0.1", "")).Times(1)
+	dp.EXPECT().ApplyDataPlane().Times(1)
+
+	inputChan := make(chan *protos.Events)
+	goalState := getGoalStateForControllerSets(t,
+		[]*controlplane.ControllerIPSets{
+			controlplane.NewControllerIPSets(testKeyPodSet),
+		},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	gsp, _ := NewGoalStateProcessor(ctx, "node1", "pod1", inputChan, nil, dp)
+	go func() {
+		inputChan <- &protos.Events{
+			EventType: protos.Events_GoalState,
+			Payload:   goalState,
+		}
+	}()
+	time.Sleep(sleepAfterChanSent)
+
+	gsp.processNext(wait.NeverStop)
+}
+
+func TestAckAfterProcessing(t *testing.T) {
+	payload, err := controlplane.EncodeNPMNetworkPolicies([]*policies.NPMNetworkPolicy{testNetPol})
+	assert.NoError(t, err)
+	event := &protos.Events{
+		EventType:  protos.Events_GoalState,
+		Generation: 7,
+		Payload: map[string]*protos.GoalState{
+			controlplane.PolicyApply: {
+				Data: payload.Bytes(),
+			},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		updateErr     error
+		applyErr      error
+		expectedError string
+	}{
+		{
+			name: "success",
+		},
+		{
+			name:          "failed policy update",
+			updateErr:     errors.New("update failed"),
+			expectedError: "failed update policy event: update failed",
+		},
+		{
+			name:          "failed apply",
+			applyErr:      errors.New("apply failed"),
+			expectedError: "failed to apply dataplane: apply failed",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			dp := dpmocks.NewMockGenericDataplane(ctrl)
+			dp.EXPECT().UpdatePolicy(gomock.Any()).Return(tt.updateErr).Times(1)
+			dp.EXPECT().ApplyDataPlane().Return(tt.applyErr).Times(1)
+
+			inputChan := make(chan *protos.Events)
+			ackChan := make(chan *protos.GoalStateAck, 1)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			gsp, _ := NewGoalStateProcessor(ctx, "node1", "pod1", inputChan, ackChan, dp)
+
+			go func() {
+				inputChan <- event
+			}()
+			time.Sleep(sleepAfterChanSent)
+
+			gsp.processNext(wait.NeverStop)
+			ack := <-ackChan
+			assert.Equal(t, "node1", ack.GetNodeName())
+			assert.Equal(t, "pod1", ack.GetPodName())
+			assert.Equal(t, uint64(7), ack.GetGeneration())
+			assert.Equal(t, tt.expectedError, ack.GetError())
+		})
+	}
+}
+
+func TestStateDigests(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dp := dpmocks.NewMockGenericDataplane(ctrl)
+	dp.EXPECT().UpdatePolicy(gomock.Any()).Return(nil).Times(1)
+	dp.EXPECT().ApplyDataPlane().Return(nil).Times(1)
+	dp.EXPECT().UpdatePolicy(gomock.Any()).Return(errors.New("update failed")).Times(1)
+	dp.EXPECT().ApplyDataPlane().Return(nil).Times(1)
+
+	payload, err := controlplane.EncodeNPMNetworkPolicies([]*policies.NPMNetworkPolicy{testNetPol})
+	assert.NoError(t, err)
+	event := &protos.Events{
+		EventType: protos.Events_GoalState,
+		Payload: map[string]*protos.GoalState{
+			controlplane.PolicyApply: {
+				Data: payload.Bytes(),
+			},
+		},
+	}
+	expectedDigest, err := controlplane.PolicyDigest(testNetPol)
+	assert.NoError(t, err)
+
+	inputChan := make(chan *protos.Events)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	gsp, _ := NewGoalStateProcessor(ctx, "node1", "pod1", inputChan, nil, dp)
+
+	go func() {
+		inputChan <- event
+	}()
+	time.Sleep(sleepAfterChanSent)
+	gsp.processNext(wait.NeverStop)
+
+	_, policyDigests := gsp.StateDigests()
+	assert.Equal(t, map[string]string{testNetPol.PolicyKey: expectedDigest}, policyDigests)
+
+	// the digests are forgotten once an event fails to apply
+	go func() {
+		inputChan <- event
+	}()
+	time.Sleep(sleepAfterChanSent)
+	gsp.processNext(wait.NeverStop)
+
+	ipsetDigests, policyDigests := gsp.StateDigests()
+	assert.Empty(t, ipsetDigests)
+	assert.Empty(t, policyDigests)
+}
+
+func TestIPSetDeltas(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	podA := dataplane.NewPodMetadata("x/a", "10.0.0.1", "node1")
+	podB := dataplane.NewPodMetadata("x/b", "10.0.0.2", "node1")
+	set := controlplane.NewControllerIPSets(testKeyPodSet)
+	set.IPPodMetadata[podA.PodIP] = podA
+
+	dp := dpmocks.NewMockGenericDataplane(ctrl)
+	dp.EXPECT().GetIPSet(gomock.Any()).Times(1)
+	dp.EXPECT().AddToSets([]*ipsets.IPSetMetadata{testKeyPodSet}, podA).Times(1)
+	dp.EXPECT().RemoveFromSets([]*ipsets.IPSetMetadata{testKeyPodSet}, podA).Times(1)
+	dp.EXPECT().AddToSets([]*ipsets.IPSetMetadata{testKeyPodSet}, podB).Times(1)
+	dp.EXPECT().ApplyDataPlane().Times(2)
+
+	inputChan := make(chan *protos.Events)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	gsp, _ := NewGoalStateProcessor(ctx, "node1", "pod1", inputChan, nil, dp)
+
+	go func() {
+		inputChan <- &protos.Events{
+			Payload: getGoalStateForControllerSets(t, []*controlplane.ControllerIPSets{set}),
+		}
+		inputChan <- &protos.Events{
+			Payload: map[string]*protos.GoalState{
+				controlplane.IpsetDelta: {
+					IpsetDeltas: []*protos.IPSetDelta{
+						{
+							Name:    testKeyPodSet.Name,
+							Type:    int32(testKeyPodSet.Type),
+							Removed: []*protos.IPSetMember{controlplane.NewIPSetMember(podA)},
+							Added:   []*protos.IPSetMember{controlplane.NewIPSetMember(podB)},
+						},
+					},
+				},
+			},
+		}
+	}()
+	time.Sleep(sleepAfterChanSent)
+	gsp.processNext(wait.NeverStop)
+	time.Sleep(sleepAfterChanSent)
+	gsp.processNext(wait.NeverStop)
+
+	// the digest matches the controller's digest of the set after the delta
+	delete(set.IPPodMetadata, podA.PodIP)
+	set.IPPodMetadata[podB.PodIP] = podB
+	ipsetDigests, _ := gsp.StateDigests()
+	assert.Equal(t, map[string]string{testKeyPodSet.GetPrefixName(): controlplane.SetDigest(set)}, ipsetDigests)
+}
+
+func TestPolicyDeltas(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	updated := *testNetPol
+	updated.ACLs = []*policies.ACLPolicy{
+		testNetPol.ACLs[1],
+		{
+			Target:    policies.Allowed,
+			Direction: policies.Egress,
+			DstPorts:  policies.Ports{Port: 53, EndPort: 53},
+			Protocol:  policies.UDP,
+		},
+	}
+	delta := controlplane.NewPolicyDelta(testNetPol, &updated)
+	assert.NotNil(t, delta)
+
+	dp := dpmocks.NewMockGenericDataplane(ctrl)
+	dp.EXPECT().UpdatePolicy(gomock.Any()).Times(1)
+	dp.EXPECT().UpdatePolicy(gomock.Any()).DoAndReturn(func(netpol *policies.NPMNetworkPolicy) error {
+		assert.Equal(t, updated.ACLs, netpol.ACLs)
+		return nil
+	}).Times(1)
+	dp.EXPECT().ApplyDataPlane().Times(3)
+
+	payload, err := controlplane.EncodeNPMNetworkPolicies([]*policies.NPMNetworkPolicy{testNetPol})
+	assert.NoError(t, err)
+	deltaEvent := &protos.Events{
+		Payload: map[string]*protos.GoalState{
+			controlplane.PolicyDelta: {
+				PolicyDeltas: []*protos.PolicyDelta{delta},
+			},
+		},
+	}
+
+	inputChan := make(chan *protos.Events)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	gsp, _ := NewGoalStateProcessor(ctx, "node1", "pod1", inputChan, nil, dp)
+
+	go func() {
+		inputChan <- &protos.Events{
+			Payload: map[string]*protos.GoalState{
+				controlplane.PolicyApply: {
+					Data: payload.Bytes(),
+				},
+			},
+		}
+		inputChan <- deltaEvent
+	}()
+	time.Sleep(sleepAfterChanSent)
+	gsp.processNext(wait.NeverStop)
+	time.Sleep(sleepAfterChanSent)
+	gsp.processNext(wait.NeverStop)
+
+	// the digest matches the controller's digest of the policy after the delta
+	expectedDigest, err := controlplane.PolicyDigest(&updated)
+	assert.NoError(t, err)
+	_, policyDigests := gsp.StateDigests()
+	assert.Equal(t, map[string]string{testNetPol.PolicyKey: expectedDigest}, policyDigests)
+
+	// a delta can't be applied to a policy which was never received
+	deltaEvent.Payload[controlplane.PolicyDelta].PolicyDeltas[0].PolicyKey = "x/unknown"
+	go func() {
+		inputChan <- deltaEvent
+	}()
+	time.Sleep(sleepAfterChanSent)
+	gsp.processNext(wait.NeverStop)
+	assert.NotEmpty(t, gsp.LastApplied().Error)
+}
+
 func getGoalStateForControllerSets(t *testing.T, sets []*controlplane.ControllerIPSets) map[string]*protos.GoalState {
 	goalState := map[string]*protos.GoalState{
 		controlplane.IpsetApply: {
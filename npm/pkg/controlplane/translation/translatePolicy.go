@@ -3,12 +3,14 @@ package translation
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
 	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/policies"
 	"github.com/Azure/azure-container-networking/npm/util"
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 /*
@@ -28,6 +30,10 @@ var (
 	ErrUnsupportedExceptCIDR = errors.New("unsupported Except CIDR block translation features used on windows")
 	// ErrUnsupportedSCTP is returned when SCTP protocol is used in windows.
 	ErrUnsupportedSCTP = errors.New("unsupported SCTP protocol used on windows")
+	// ErrUnsupportedFQDN is returned when the FQDN egress annotation is used in windows.
+	ErrUnsupportedFQDN = errors.New("unsupported FQDN egress annotation used on windows")
+	// ErrInvalidFQDN is returned when the FQDN egress annotation has a pattern which isn't a DNS name or a wildcard DNS name.
+	ErrInvalidFQDN = errors.New("FQDN patterns must be lowercase DNS names optionally prefixed with '*.'")
 	// ErrInvalidMatchExpressionValues ensures proper matchExpression label values since k8s doesn't perform this check.
 	ErrInvalidMatchExpressionValues = errors.New(
 		"matchExpression label values must be an empty string or consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character",
@@ -47,6 +53,11 @@ const (
 	namedPortType        netpolPortType = "namedport"
 	included             bool           = true
 	ipBlocksetNameFormat                = "%s-in-ns-%s-%d-%d%s"
+
+	// FQDNEgressAnnotation on a NetworkPolicy with the Egress policy type allows the selected pods to send traffic to comma-separated FQDN patterns.
+	// A pattern is either a DNS name like login.microsoftonline.com or a wildcard like *.blob.core.windows.net, which matches any subdomain.
+	// The allowed IPs are learned from DNS responses sent to pods, so NPM must run with the FQDN egress toggle enabled.
+	FQDNEgressAnnotation = "npm.azure.com/egress-fqdns"
 )
 
 // portType returns type of ports (e.g., numeric port or namedPort) given NetworkPolicyPort object.
@@ -521,6 +532,51 @@ func ingressPolicy(npmNetPol *policies.NPMNetworkPolicy, netPolName string, ingr
 	return nil
 }
 
+// fqdnPatterns returns the deduplicated FQDN patterns in the FQDN egress annotation of the network policy.
+func fqdnPatterns(npObj *networkingv1.NetworkPolicy) ([]string, error) {
+	annotation, ok := npObj.Annotations[FQDNEgressAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	if util.IsWindowsDP() {
+		return nil, ErrUnsupportedFQDN
+	}
+
+	patterns := make([]string, 0)
+	seen := make(map[string]struct{})
+	for _, pattern := range strings.Split(annotation, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if len(validation.IsDNS1123Subdomain(pattern)) > 0 && len(validation.IsWildcardDNS1123Subdomain(pattern)) > 0 {
+			return nil, fmt.Errorf("invalid FQDN pattern %q: %w", pattern, ErrInvalidFQDN)
+		}
+		if _, ok := seen[pattern]; ok {
+			continue
+		}
+		seen[pattern] = struct{}{}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// fqdnRule adds an ACL allowing traffic to the FQDN set of each pattern in the policy's namespace.
+// Members of FQDN sets are added by the dataplane when it observes DNS responses to pods in the namespace,
+// so the translated sets have no members.
+func fqdnRule(npmNetPol *policies.NPMNetworkPolicy, patterns []string) {
+	for _, pattern := range patterns {
+		setName := util.GetFQDNSetName(npmNetPol.Namespace, pattern)
+		fqdnIPSet := ipsets.NewTranslatedIPSet(setName, ipsets.FQDNAddresses)
+		npmNetPol.RuleIPSets = append(npmNetPol.RuleIPSets, fqdnIPSet)
+
+		acl := policies.NewACLPolicy(policies.Allowed, policies.Egress)
+		setInfo := policies.NewSetInfo(setName, ipsets.FQDNAddresses, included, policies.DstMatch)
+		acl.AddSetInfo([]policies.SetInfo{setInfo})
+		npmNetPol.ACLs = append(npmNetPol.ACLs, acl)
+	}
+}
+
 // isAllowAllToEgress returns true if this network policy allows all traffic to internal (i.e,. K8s cluster) and external (i.e., internet)
 // Otherwise, it returns false.
 func isAllowAllToEgress(egress []networkingv1.NetworkPolicyEgressRule) bool {
@@ -531,7 +587,7 @@ func isAllowAllToEgress(egress []networkingv1.NetworkPolicyEgressRule) bool {
 
 // egressPolicy traslates NetworkPolicyEgressRule in networkpolicy object
 // to NPMNetworkPolicy object.
-func egressPolicy(npmNetPol *policies.NPMNetworkPolicy, netPolName string, egress []networkingv1.NetworkPolicyEgressRule) error {
+func egressPolicy(npmNetPol *policies.NPMNetworkPolicy, netPolName string, egress []networkingv1.NetworkPolicyEgressRule, fqdns []string) error {
 	// #1. Allow all traffic to both internal and external.
 	// In yaml file, it is specified with '{}'.
 	if isAllowAllToEgress(egress) {
@@ -540,7 +596,9 @@ func egressPolicy(npmNetPol *policies.NPMNetworkPolicy, netPolName string, egres
 	}
 
 	// #2. If egress is nil (in yaml file, it is specified with '[]'), it means "Deny all" - it does not allow sending traffic to others.
+	// The FQDN egress annotation can still allow traffic to FQDNs.
 	if egress == nil {
+		fqdnRule(npmNetPol, fqdns)
 		// Except for allow all traffic case in #1, the rest of them should have default drop rules.
 		dropACL := defaultDropACL(policies.Egress)
 		npmNetPol.ACLs = append(npmNetPol.ACLs, dropACL)
@@ -556,6 +614,8 @@ func egressPolicy(npmNetPol *policies.NPMNetworkPolicy, netPolName string, egres
 		}
 	}
 
+	fqdnRule(npmNetPol, fqdns)
+
 	// #3. Except for allow all traffic case in #1, the rest of them should have default drop rules.
 	// Add drop ACL to drop the rest of traffic which is not specified in Egress Spec.
 	dropACL := defaultDropACL(policies.Egress)
@@ -579,6 +639,11 @@ func TranslatePolicy(npObj *networkingv1.NetworkPolicy) (*policies.NPMNetworkPol
 	npmNetPol.ChildPodSelectorIPSets = psResult.childPSSets
 	npmNetPol.PodSelectorList = psResult.psList
 
+	fqdns, err := fqdnPatterns(npObj)
+	if err != nil {
+		return nil, err
+	}
+
 	// Each NetworkPolicy includes a policyTypes list which may include either Ingress, Egress, or both.
 	// If no policyTypes are specified on a NetworkPolicy then by default Ingress will always be set
 	// and Egress will be set if the NetworkPolicy has any egress rules.
@@ -589,7 +654,7 @@ func TranslatePolicy(npObj *networkingv1.NetworkPolicy) (*policies.NPMNetworkPol
 				return nil, err
 			}
 		} else {
-			err := egressPolicy(npmNetPol, netPolName, npObj.Spec.Egress)
+			err := egressPolicy(npmNetPol, netPolName, npObj.Spec.Egress, fqdns)
 			if err != nil {
 				return nil, err
 			}
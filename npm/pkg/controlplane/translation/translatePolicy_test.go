@@ -2802,7 +2802,7 @@ func TestEgressPolicy(t *testing.T) {
 			npmNetPol.PodSelectorList = psResult.psList
 			splitPolicyKey := strings.Split(npmNetPol.PolicyKey, "/")
 			require.Len(t, splitPolicyKey, 2, "policy key must include name")
-			err = egressPolicy(npmNetPol, splitPolicyKey[1], tt.rules)
+			err = egressPolicy(npmNetPol, splitPolicyKey[1], tt.rules, nil)
 			if tt.wantErr || (tt.skipWindows && util.IsWindowsDP()) {
 				require.Error(t, err)
 			} else {
@@ -2812,3 +2812,143 @@ func TestEgressPolicy(t *testing.T) {
 		})
 	}
 }
+
+func TestFQDNPatterns(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        []string
+		wantErr     bool
+	}{
+		{
+			name: "no annotation",
+		},
+		{
+			name:        "names and wildcards",
+			annotations: map[string]string{FQDNEgressAnnotation: "*.blob.core.windows.net, login.microsoftonline.com"},
+			want:        []string{"*.blob.core.windows.net", "login.microsoftonline.com"},
+		},
+		{
+			name:        "duplicates and empty patterns",
+			annotations: map[string]string{FQDNEgressAnnotation: "example.com,,example.com, "},
+			want:        []string{"example.com"},
+		},
+		{
+			name:        "uppercase",
+			annotations: map[string]string{FQDNEgressAnnotation: "Example.com"},
+			wantErr:     true,
+		},
+		{
+			name:        "wildcard in middle",
+			annotations: map[string]string{FQDNEgressAnnotation: "blob.*.windows.net"},
+			wantErr:     true,
+		},
+		{
+			name:        "url",
+			annotations: map[string]string{FQDNEgressAnnotation: "https://example.com"},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			npObj := &networkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "fqdn",
+					Namespace:   defaultNS,
+					Annotations: tt.annotations,
+				},
+			}
+			patterns, err := fqdnPatterns(npObj)
+			if len(tt.annotations) > 0 && util.IsWindowsDP() {
+				require.ErrorIs(t, err, ErrUnsupportedFQDN)
+				return
+			}
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidFQDN)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, patterns)
+		})
+	}
+}
+
+func TestEgressPolicyWithFQDNs(t *testing.T) {
+	fqdnACL := func(pattern string) *policies.ACLPolicy {
+		acl := policies.NewACLPolicy(policies.Allowed, policies.Egress)
+		acl.AddSetInfo([]policies.SetInfo{policies.NewSetInfo(pattern, ipsets.FQDNAddresses, included, policies.DstMatch)})
+		return acl
+	}
+	// FQDN sets are scoped to the namespace of the policy
+	blobSet := util.GetFQDNSetName(defaultNS, "*.blob.core.windows.net")
+
+	tests := []struct {
+		name         string
+		rules        []networkingv1.NetworkPolicyEgressRule
+		wantRuleSets []*ipsets.TranslatedIPSet
+		wantACLs     []*policies.ACLPolicy
+	}{
+		{
+			name:  "deny all except FQDNs",
+			rules: nil,
+			wantRuleSets: []*ipsets.TranslatedIPSet{
+				ipsets.NewTranslatedIPSet(blobSet, ipsets.FQDNAddresses),
+			},
+			wantACLs: []*policies.ACLPolicy{
+				fqdnACL(blobSet),
+				defaultDropACL(policies.Egress),
+			},
+		},
+		{
+			name: "allow all ignores FQDNs",
+			rules: []networkingv1.NetworkPolicyEgressRule{
+				{},
+			},
+			wantACLs: []*policies.ACLPolicy{
+				policies.NewACLPolicy(policies.Allowed, policies.Egress),
+			},
+		},
+		{
+			name: "FQDNs after egress rules",
+			rules: []networkingv1.NetworkPolicyEgressRule{
+				{
+					To: []networkingv1.NetworkPolicyPeer{
+						{
+							IPBlock: &networkingv1.IPBlock{
+								CIDR: "172.17.0.0/16",
+							},
+						},
+					},
+				},
+			},
+			wantRuleSets: []*ipsets.TranslatedIPSet{
+				ipsets.NewTranslatedIPSet("fqdn-in-ns-default-0-0OUT", ipsets.CIDRBlocks, "172.17.0.0/16"),
+				ipsets.NewTranslatedIPSet(blobSet, ipsets.FQDNAddresses),
+			},
+			wantACLs: []*policies.ACLPolicy{
+				{
+					Target:    policies.Allowed,
+					Direction: policies.Egress,
+					DstList: []policies.SetInfo{
+						policies.NewSetInfo("fqdn-in-ns-default-0-0OUT", ipsets.CIDRBlocks, included, policies.DstMatch),
+					},
+				},
+				fqdnACL(blobSet),
+				defaultDropACL(policies.Egress),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			npmNetPol := policies.NewNPMNetworkPolicy("fqdn", defaultNS)
+			err := egressPolicy(npmNetPol, "fqdn", tt.rules, []string{"*.blob.core.windows.net"})
+			require.NoError(t, err)
+			require.Equal(t, tt.wantRuleSets, npmNetPol.RuleIPSets)
+			require.Equal(t, tt.wantACLs, npmNetPol.ACLs)
+		})
+	}
+}
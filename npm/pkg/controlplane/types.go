@@ -10,6 +10,8 @@ const (
 	IpsetRemove     string = "IPSETREMOVE"
 	PolicyApply     string = "POLICYAPPLY"
 	PolicyRemove    string = "POLICYREMOVE"
+	IpsetDelta      string = "IPSETDELTA"
+	PolicyDelta     string = "POLICYDELTA"
 	ListReference   string = "LISTREFERENCE"
 	PolicyReference string = "POLICYREFERENCE"
 )
@@ -0,0 +1,186 @@
+package dataplane
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Azure/azure-container-networking/npm/metrics"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/policies"
+	"github.com/Azure/azure-container-networking/npm/util"
+	"k8s.io/klog"
+)
+
+const (
+	saveCacheTimeInMinutes int = 1
+	// the controllers are expected to add all current pods, namespaces, and policies within this period after bootup.
+	// Afterwards, restored ipset members and policies which weren't added again are removed.
+	restoredCacheGracePeriodInMinutes int = 10
+
+	// keys of the cache file, which has the same format as the NPM cache (a map of cache keys to raw JSON)
+	nodeNameCacheKey = "NodeName"
+	ipsetsCacheKey   = "IPSets"
+	policiesCacheKey = "Policies"
+)
+
+var (
+	ErrCacheForOtherNode = errors.New("cache file is for another node")
+	errInvalidCache      = errors.New("invalid cache file")
+)
+
+// dataplaneCache is the content of the cache file
+type dataplaneCache struct {
+	sets     []*ipsets.SetSnapshot
+	policies *policies.PolicyManagerSnapshot
+}
+
+// runCacheTasks periodically saves the cache file, and removes anything restored from the cache which
+// wasn't added again by the controllers once the grace period ends. Should only be called once.
+func (dp *DataPlane) runCacheTasks() {
+	var gracePeriod <-chan time.Time
+	if dp.restoredFromCache {
+		gracePeriod = time.After(time.Minute * time.Duration(restoredCacheGracePeriodInMinutes))
+	}
+
+	ticker := time.NewTicker(time.Minute * time.Duration(saveCacheTimeInMinutes))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-dp.stopChannel:
+			return
+		case <-gracePeriod:
+			dp.removeUnconfirmedCache()
+		case <-ticker.C:
+			if err := dp.saveCache(); err != nil {
+				if errors.Is(err, ipsets.ErrDirtyCache) {
+					klog.Infof("[DataPlane] skipped saving the cache file since ipsets are being applied")
+					continue
+				}
+				metrics.SendErrorLogAndMetric(util.DaemonDataplaneID, "error: failed to save cache file: %s", err.Error())
+			}
+		}
+	}
+}
+
+// removeUnconfirmedCache removes restored policies and ipset members which weren't added again since bootup
+func (dp *DataPlane) removeUnconfirmedCache() {
+	policyKeys := dp.policyMgr.TakeUnconfirmedPolicies()
+	klog.Infof("[DataPlane] removing %d restored policies which weren't added again: %+v", len(policyKeys), policyKeys)
+	for _, policyKey := range policyKeys {
+		if err := dp.RemovePolicy(policyKey); err != nil {
+			metrics.SendErrorLogAndMetric(util.DaemonDataplaneID, "error: failed to remove restored policy %s: %s", policyKey, err.Error())
+		}
+	}
+
+	dp.ipsetMgr.RemoveUnconfirmedMembers()
+	if err := dp.ApplyDataPlane(); err != nil {
+		metrics.SendErrorLogAndMetric(util.DaemonDataplaneID, "error: failed to apply dataplane after removing restored ipset members: %s", err.Error())
+	}
+}
+
+// saveCache atomically replaces the cache file with the current ipsets and policies.
+// Policies are snapshotted first so that any ipset references to policies missing from the snapshot can be dropped on restore.
+func (dp *DataPlane) saveCache() error {
+	policySnapshot, err := dp.policyMgr.Snapshot()
+	if err != nil {
+		return fmt.Errorf("failed to snapshot policies: %w", err)
+	}
+	setSnapshots, err := dp.ipsetMgr.Snapshot()
+	if err != nil {
+		return fmt.Errorf("failed to snapshot ipsets: %w", err)
+	}
+
+	m := map[string]json.RawMessage{}
+	if m[nodeNameCacheKey], err = json.Marshal(dp.nodeName); err != nil {
+		return fmt.Errorf("failed to marshal node name: %w", err)
+	}
+	if m[ipsetsCacheKey], err = json.Marshal(setSnapshots); err != nil {
+		return fmt.Errorf("failed to marshal ipsets: %w", err)
+	}
+	if m[policiesCacheKey], err = json.Marshal(policySnapshot); err != nil {
+		return fmt.Errorf("failed to marshal policies: %w", err)
+	}
+	contents, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(dp.CacheFile), filepath.Base(dp.CacheFile)+".tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary cache file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name()) //nolint:errcheck // the file no longer exists after a successful rename
+	if _, err := tmpFile.Write(contents); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write temporary cache file: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary cache file: %w", err)
+	}
+	if err := os.Rename(tmpFile.Name(), dp.CacheFile); err != nil {
+		return fmt.Errorf("failed to replace cache file: %w", err)
+	}
+	return nil
+}
+
+// loadCache reads the cache file. References to policies which aren't in the cache are dropped from the ipsets.
+func (dp *DataPlane) loadCache() (*dataplaneCache, error) {
+	contents, err := os.ReadFile(dp.CacheFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache file: %w", err)
+	}
+	m := map[string]json.RawMessage{}
+	if err := json.Unmarshal(contents, &m); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cache file: %w", err)
+	}
+
+	var nodeName string
+	if err := json.Unmarshal(m[nodeNameCacheKey], &nodeName); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal node name: %w", err)
+	}
+	if nodeName != dp.nodeName {
+		return nil, fmt.Errorf("%w: %s", ErrCacheForOtherNode, nodeName)
+	}
+
+	cache := &dataplaneCache{}
+	if err := json.Unmarshal(m[ipsetsCacheKey], &cache.sets); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal ipsets: %w", err)
+	}
+	if err := json.Unmarshal(m[policiesCacheKey], &cache.policies); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal policies: %w", err)
+	}
+	if cache.policies == nil {
+		return nil, fmt.Errorf("%w: no policies", errInvalidCache)
+	}
+
+	policyKeys := make(map[string]struct{}, len(cache.policies.Policies))
+	for _, policy := range cache.policies.Policies {
+		if policy == nil {
+			return nil, fmt.Errorf("%w: nil policy", errInvalidCache)
+		}
+		policyKeys[policy.PolicyKey] = struct{}{}
+	}
+	for _, set := range cache.sets {
+		if set == nil {
+			return nil, fmt.Errorf("%w: nil ipset", errInvalidCache)
+		}
+		set.SelectorReference = filterReferences(set.SelectorReference, policyKeys)
+		set.NetPolReference = filterReferences(set.NetPolReference, policyKeys)
+	}
+	return cache, nil
+}
+
+func filterReferences(references []string, policyKeys map[string]struct{}) []string {
+	filtered := make([]string, 0, len(references))
+	for _, reference := range references {
+		if _, ok := policyKeys[reference]; ok {
+			filtered = append(filtered, reference)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,85 @@
+package dataplane
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestCacheFile(t *testing.T, contents string) string {
+	cacheFile := filepath.Join(t.TempDir(), "npm-dataplane-cache.json")
+	require.NoError(t, os.WriteFile(cacheFile, []byte(contents), 0o600))
+	return cacheFile
+}
+
+func TestLoadCache(t *testing.T) {
+	cacheFile := writeTestCacheFile(t, `{
+		"NodeName": "testnode",
+		"IPSets": [
+			{
+				"Metadata": {"Name": "x", "Type": 1},
+				"IPPodKey": {"10.0.0.1": "x/a"},
+				"SelectorReference": ["x/base", "x/deleted"],
+				"NetPolReference": ["x/deleted"]
+			}
+		],
+		"Policies": {
+			"Policies": [{"Namespace": "x", "PolicyKey": "x/base"}],
+			"Jumps": {},
+			"ChainLines": {}
+		}
+	}`)
+	dp := &DataPlane{Config: &Config{CacheFile: cacheFile}, nodeName: nodeName}
+
+	cache, err := dp.loadCache()
+	require.NoError(t, err)
+	require.Len(t, cache.policies.Policies, 1)
+	require.Equal(t, []*ipsets.SetSnapshot{
+		{
+			Metadata:          ipsets.NewIPSetMetadata("x", ipsets.Namespace),
+			IPPodKey:          map[string]string{"10.0.0.1": "x/a"},
+			SelectorReference: []string{"x/base"},
+			NetPolReference:   []string{},
+		},
+	}, cache.sets)
+}
+
+func TestLoadCacheForOtherNode(t *testing.T) {
+	cacheFile := writeTestCacheFile(t, `{"NodeName": "othernode", "IPSets": [], "Policies": {}}`)
+	dp := &DataPlane{Config: &Config{CacheFile: cacheFile}, nodeName: nodeName}
+
+	_, err := dp.loadCache()
+	require.ErrorIs(t, err, ErrCacheForOtherNode)
+}
+
+func TestLoadInvalidCache(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+	}{
+		{
+			name:     "null policies",
+			contents: `{"NodeName": "testnode", "IPSets": [], "Policies": null}`,
+		},
+		{
+			name:     "nil policy",
+			contents: `{"NodeName": "testnode", "IPSets": [], "Policies": {"Policies": [null]}}`,
+		},
+		{
+			name:     "nil ipset",
+			contents: `{"NodeName": "testnode", "IPSets": [null], "Policies": {"Policies": []}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			dp := &DataPlane{Config: &Config{CacheFile: writeTestCacheFile(t, tt.contents)}, nodeName: nodeName}
+			_, err := dp.loadCache()
+			require.ErrorIs(t, err, errInvalidCache)
+		})
+	}
+}
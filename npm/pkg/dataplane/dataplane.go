@@ -7,6 +7,7 @@ import (
 
 	"github.com/Azure/azure-container-networking/common"
 	"github.com/Azure/azure-container-networking/npm/metrics"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/fqdn"
 	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
 	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/policies"
 	"github.com/Azure/azure-container-networking/npm/util"
@@ -14,7 +15,10 @@ import (
 	"k8s.io/klog"
 )
 
-const reconcileTimeInMinutes int = 5
+const (
+	reconcileTimeInMinutes       int = 5
+	policyHitCountsTimeInMinutes int = 1
+)
 
 type PolicyMode string
 
@@ -22,6 +26,15 @@ type PolicyMode string
 type Config struct {
 	*ipsets.IPSetManagerCfg
 	*policies.PolicyManagerCfg
+	// EnablePolicyHitCounts periodically exports the packets and bytes matched by each policy as Prometheus metrics
+	EnablePolicyHitCounts bool
+	// EnableFQDNEgress snoops DNS responses to populate the ipsets of FQDN egress rules
+	EnableFQDNEgress bool
+	// FQDNDNSServerIPs are trusted to send DNS responses to pods in addition to the CoreDNS pods
+	FQDNDNSServerIPs []string
+	// CacheFile is where ipsets and policies are periodically saved so that bootup can keep them in the kernel.
+	// Empty disables the cache. Only supported in Linux.
+	CacheFile string
 }
 
 type updatePodCache struct {
@@ -54,6 +67,10 @@ type DataPlane struct {
 	ioShim         *common.IOShim
 	updatePodCache *updatePodCache
 	stopChannel    <-chan struct{}
+	// fqdnSnooper is nil unless EnableFQDNEgress is set
+	fqdnSnooper *fqdn.Snooper
+	// restoredFromCache is true if bootup restored ipsets and policies from the CacheFile
+	restoredFromCache bool
 }
 
 func NewDataPlane(nodeName string, ioShim *common.IOShim, cfg *Config, stopChannel <-chan struct{}) (*DataPlane, error) {
@@ -61,6 +78,10 @@ func NewDataPlane(nodeName string, ioShim *common.IOShim, cfg *Config, stopChann
 	if util.IsWindowsDP() {
 		klog.Infof("[DataPlane] enabling AddEmptySetToLists for Windows")
 		cfg.IPSetManagerCfg.AddEmptySetToLists = true
+		if cfg.CacheFile != "" {
+			klog.Infof("[DataPlane] disabling the cache file for Windows")
+			cfg.CacheFile = ""
+		}
 	}
 	dp := &DataPlane{
 		Config:         cfg,
@@ -72,6 +93,9 @@ func NewDataPlane(nodeName string, ioShim *common.IOShim, cfg *Config, stopChann
 		updatePodCache: newUpdatePodCache(),
 		stopChannel:    stopChannel,
 	}
+	if cfg.EnableFQDNEgress {
+		dp.fqdnSnooper = fqdn.NewSnooper(dp.ipsetMgr, cfg.FQDNDNSServerIPs)
+	}
 
 	err := dp.BootupDataplane()
 	if err != nil {
@@ -110,6 +134,31 @@ func (dp *DataPlane) RunPeriodicTasks() {
 			}
 		}
 	}()
+
+	if dp.EnablePolicyHitCounts {
+		go func() {
+			ticker := time.NewTicker(time.Minute * time.Duration(policyHitCountsTimeInMinutes))
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-dp.stopChannel:
+					return
+				case <-ticker.C:
+					// in Windows, does nothing
+					dp.policyMgr.UpdatePolicyHitCounts()
+				}
+			}
+		}()
+	}
+
+	if dp.fqdnSnooper != nil {
+		go dp.fqdnSnooper.Run(dp.stopChannel)
+	}
+
+	if dp.CacheFile != "" {
+		go dp.runCacheTasks()
+	}
 }
 
 func (dp *DataPlane) GetIPSet(setName string) *ipsets.IPSet {
@@ -323,20 +372,7 @@ func (dp *DataPlane) UpdatePolicy(policy *policies.NPMNetworkPolicy) error {
 		return dp.AddPolicy(policy)
 	}
 
-	// TODO it would be ideal to calculate a diff of policies
-	// and remove/apply only the delta of IPSets and policies
-
-	// Taking the easy route here, delete existing policy
-	err := dp.RemovePolicy(policy.PolicyKey)
-	if err != nil {
-		return fmt.Errorf("[DataPlane] error while updating policy: %w", err)
-	}
-	// and add the new updated policy
-	err = dp.AddPolicy(policy)
-	if err != nil {
-		return fmt.Errorf("[DataPlane] error while updating policy: %w", err)
-	}
-	return nil
+	return dp.updatePolicy(policy)
 }
 
 func (dp *DataPlane) GetAllIPSets() map[string]string {
@@ -388,6 +424,64 @@ func (dp *DataPlane) createIPSetsAndReferences(sets []*ipsets.TranslatedIPSet, n
 	return nil
 }
 
+// deleteStaleIPSetsAndReferences deletes references for sets which are in oldSets but not newSets,
+// and deletes translated members which are only in the oldSets version of a set.
+func (dp *DataPlane) deleteStaleIPSetsAndReferences(oldSets, newSets []*ipsets.TranslatedIPSet, netpolName string, referenceType ipsets.ReferenceType) error {
+	newSetsByName := make(map[string]*ipsets.TranslatedIPSet, len(newSets))
+	for _, set := range newSets {
+		newSetsByName[set.Metadata.GetPrefixName()] = set
+	}
+
+	staleSets := make([]*ipsets.TranslatedIPSet, 0)
+	staleMembers := make([]*ipsets.TranslatedIPSet, 0)
+	for _, oldSet := range oldSets {
+		newSet, ok := newSetsByName[oldSet.Metadata.GetPrefixName()]
+		if !ok {
+			staleSets = append(staleSets, oldSet)
+			continue
+		}
+
+		newMembers := make(map[string]struct{}, len(newSet.Members))
+		for _, member := range newSet.Members {
+			newMembers[member] = struct{}{}
+		}
+		members := make([]string, 0)
+		for _, member := range oldSet.Members {
+			if _, ok := newMembers[member]; !ok {
+				members = append(members, member)
+			}
+		}
+		if len(members) > 0 {
+			staleMembers = append(staleMembers, &ipsets.TranslatedIPSet{Metadata: oldSet.Metadata, Members: members})
+		}
+	}
+
+	if err := dp.deleteIPSetsAndReferences(staleSets, netpolName, referenceType); err != nil {
+		return err
+	}
+
+	npmErrorString := npmerrors.DeleteSelectorReference
+	if referenceType == ipsets.NetPolType {
+		npmErrorString = npmerrors.DeleteNetPolReference
+	}
+	for _, set := range staleMembers {
+		if set.Metadata.Type == ipsets.CIDRBlocks {
+			for _, ipblock := range set.Members {
+				err := dp.ipsetMgr.RemoveFromSets([]*ipsets.IPSetMetadata{set.Metadata}, ipblock, "")
+				if err != nil {
+					return npmerrors.Errorf(npmErrorString, false, fmt.Sprintf("[DataPlane] failed to RemoveFromSet in deleteStaleIPSetsAndReferences with err: %s", err.Error()))
+				}
+			}
+		} else if set.Metadata.GetSetKind() == ipsets.ListSet {
+			err := dp.ipsetMgr.RemoveFromList(set.Metadata, ipsets.GetMembersOfTranslatedSets(set.Members))
+			if err != nil {
+				return npmerrors.Errorf(npmErrorString, false, fmt.Sprintf("[DataPlane] failed to RemoveFromList in deleteStaleIPSetsAndReferences with err: %s", err.Error()))
+			}
+		}
+	}
+	return nil
+}
+
 func (dp *DataPlane) deleteIPSetsAndReferences(sets []*ipsets.TranslatedIPSet, netpolName string, referenceType ipsets.ReferenceType) error {
 	for _, set := range sets {
 		prefixName := set.Metadata.GetPrefixName()
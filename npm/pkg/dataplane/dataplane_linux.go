@@ -1,8 +1,14 @@
 package dataplane
 
 import (
+	"fmt"
+
+	"github.com/Azure/azure-container-networking/npm/metrics"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
 	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/policies"
+	"github.com/Azure/azure-container-networking/npm/util"
 	npmerrors "github.com/Azure/azure-container-networking/npm/util/errors"
+	"k8s.io/klog"
 )
 
 func (dp *DataPlane) getEndpointsToApplyPolicy(policy *policies.NPMNetworkPolicy) (map[string]string, error) {
@@ -10,6 +16,48 @@ func (dp *DataPlane) getEndpointsToApplyPolicy(policy *policies.NPMNetworkPolicy
 	return nil, nil
 }
 
+// updatePolicy applies only the delta of IPSets and iptables rules between the cached policy and the updated policy.
+func (dp *DataPlane) updatePolicy(policy *policies.NPMNetworkPolicy) error {
+	oldPolicy, ok := dp.policyMgr.GetPolicy(policy.PolicyKey)
+	if !ok {
+		return dp.AddPolicy(policy)
+	}
+
+	// Create and add references for new IPSets first, so the updated rules can reference them
+	err := dp.createIPSetsAndReferences(policy.AllPodSelectorIPSets(), policy.PolicyKey, ipsets.SelectorType)
+	if err != nil {
+		return fmt.Errorf("[DataPlane] error while adding Selector IPSet references on update: %w", err)
+	}
+	err = dp.createIPSetsAndReferences(policy.RuleIPSets, policy.PolicyKey, ipsets.NetPolType)
+	if err != nil {
+		return fmt.Errorf("[DataPlane] error while adding Rule IPSet references on update: %w", err)
+	}
+	err = dp.ApplyDataPlane()
+	if err != nil {
+		return fmt.Errorf("[DataPlane] error while applying dataplane: %w", err)
+	}
+
+	err = dp.policyMgr.UpdatePolicy(policy)
+	if err != nil {
+		return fmt.Errorf("[DataPlane] error while updating policy: %w", err)
+	}
+
+	// Remove references for IPSets that are no longer used by the policy
+	err = dp.deleteStaleIPSetsAndReferences(oldPolicy.RuleIPSets, policy.RuleIPSets, policy.PolicyKey, ipsets.NetPolType)
+	if err != nil {
+		return err
+	}
+	err = dp.deleteStaleIPSetsAndReferences(oldPolicy.AllPodSelectorIPSets(), policy.AllPodSelectorIPSets(), policy.PolicyKey, ipsets.SelectorType)
+	if err != nil {
+		return err
+	}
+	err = dp.ApplyDataPlane()
+	if err != nil {
+		return fmt.Errorf("[DataPlane] error while applying dataplane: %w", err)
+	}
+	return nil
+}
+
 func (dp *DataPlane) shouldUpdatePod() bool {
 	return false
 }
@@ -20,6 +68,15 @@ func (dp *DataPlane) updatePod(pod *updateNPMPod) error {
 }
 
 func (dp *DataPlane) bootupDataPlane() error {
+	if dp.CacheFile != "" {
+		err := dp.bootupDataPlaneWithCache()
+		if err == nil {
+			dp.restoredFromCache = true
+			return nil
+		}
+		metrics.SendErrorLogAndMetric(util.DaemonDataplaneID, "error: failed to bootup dataplane with cache file, so resetting the dataplane. err: %s", err.Error())
+	}
+
 	// It is important to keep order to clean-up ACLs before ipsets. Otherwise we won't be able to delete ipsets referenced by ACLs
 	if err := dp.policyMgr.Bootup(nil); err != nil {
 		return npmerrors.ErrorWrapper(npmerrors.BootupDataplane, false, "failed to reset policy dataplane", err)
@@ -30,6 +87,42 @@ func (dp *DataPlane) bootupDataPlane() error {
 	return nil
 }
 
+// bootupDataPlaneWithCache restores ipsets and policies from the cache file and reconciles them with the kernel.
+// Like bootupDataPlane(), ipsets are destroyed only after policies no longer reference them.
+func (dp *DataPlane) bootupDataPlaneWithCache() error {
+	cache, err := dp.loadCache()
+	if err != nil {
+		return err
+	}
+
+	if err := dp.ipsetMgr.RestoreIPSets(cache.sets); err != nil {
+		return fmt.Errorf("failed to restore ipsets: %w", err)
+	}
+	// make sure the ipsets referenced by cached policies are in the kernel, even if the ipset snapshot was taken after a policy was removed
+	for _, policy := range cache.policies.Policies {
+		if err := dp.createIPSetsAndReferences(policy.AllPodSelectorIPSets(), policy.PolicyKey, ipsets.SelectorType); err != nil {
+			return fmt.Errorf("failed to restore Selector IPSet references: %w", err)
+		}
+		if err := dp.createIPSetsAndReferences(policy.RuleIPSets, policy.PolicyKey, ipsets.NetPolType); err != nil {
+			return fmt.Errorf("failed to restore Rule IPSet references: %w", err)
+		}
+	}
+	if err := dp.ipsetMgr.ApplyRestoredIPSets(); err != nil {
+		return fmt.Errorf("failed to apply restored ipsets: %w", err)
+	}
+
+	if err := dp.policyMgr.BootupWithCache(cache.policies); err != nil {
+		return fmt.Errorf("failed to restore policies: %w", err)
+	}
+
+	if err := dp.ipsetMgr.DestroyStaleKernelIPSets(); err != nil {
+		// stale ipsets don't affect traffic
+		metrics.SendErrorLogAndMetric(util.DaemonDataplaneID, "error: failed to destroy stale ipsets after bootup with cache file: %s", err.Error())
+	}
+	klog.Infof("[DataPlane] booted up with cache file %s", dp.CacheFile)
+	return nil
+}
+
 func (dp *DataPlane) refreshPodEndpoints() error {
 	// NOOP in Linux
 	return nil
@@ -232,8 +232,7 @@ func TestUpdatePolicy(t *testing.T) {
 	}
 
 	calls := append(getBootupTestCalls(), getAddPolicyTestCallsForDP(&testPolicyobj)...)
-	calls = append(calls, getRemovePolicyTestCallsForDP(&testPolicyobj)...)
-	calls = append(calls, getAddPolicyTestCallsForDP(&updatedTestPolicyobj)...)
+	calls = append(calls, getUpdatePolicyTestCallsForDP(&testPolicyobj, &updatedTestPolicyobj)...)
 	for _, call := range calls {
 		fmt.Println(call)
 	}
@@ -268,6 +267,15 @@ func getRemovePolicyTestCallsForDP(networkPolicy *policies.NPMNetworkPolicy) []t
 	return calls
 }
 
+func getUpdatePolicyTestCallsForDP(oldPolicy, newPolicy *policies.NPMNetworkPolicy) []testutils.TestCmd {
+	if util.IsWindowsDP() {
+		calls := getRemovePolicyTestCallsForDP(oldPolicy)
+		return append(calls, getAddPolicyTestCallsForDP(newPolicy)...)
+	}
+	// NOTE assumes both policies reference the same ipsets, so there are no ipset changes to apply
+	return policies.GetUpdatePolicyTestCalls(oldPolicy, newPolicy)
+}
+
 func getAffectedIPSets(networkPolicy *policies.NPMNetworkPolicy) []*ipsets.IPSetMetadata {
 	sets := make([]*ipsets.IPSetMetadata, 0)
 	for _, translatedIPSet := range networkPolicy.PodSelectorIPSets {
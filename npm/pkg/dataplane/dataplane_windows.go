@@ -106,6 +106,23 @@ func (dp *DataPlane) bootupDataPlane() error {
 	return nil
 }
 
+func (dp *DataPlane) updatePolicy(policy *policies.NPMNetworkPolicy) error {
+	// TODO it would be ideal to calculate a diff of policies
+	// and remove/apply only the delta of IPSets and policies
+
+	// Taking the easy route here, delete existing policy
+	err := dp.RemovePolicy(policy.PolicyKey)
+	if err != nil {
+		return fmt.Errorf("[DataPlane] error while updating policy: %w", err)
+	}
+	// and add the new updated policy
+	err = dp.AddPolicy(policy)
+	if err != nil {
+		return fmt.Errorf("[DataPlane] error while updating policy: %w", err)
+	}
+	return nil
+}
+
 func (dp *DataPlane) shouldUpdatePod() bool {
 	return true
 }
@@ -44,6 +44,9 @@ type Converter struct {
 	AzureNPMChains       map[string]bool
 	NPMCache             npmcommon.GenericCache
 	EnableV2NPM          bool
+	// CIDRBlockContents is only used in V2 where CIDR block sets aren't listed from the kernel.
+	// key: hashed set name, value: members of the set (i.e. "10.0.0.0/16" or "10.0.1.0/24 nomatch")
+	CIDRBlockContents map[string][]string
 }
 
 // NpmCacheFromFile initialize NPM cache from file.
@@ -61,6 +64,16 @@ func (c *Converter) NpmCacheFromFile(npmCacheJSONFile string) error {
 	return nil
 }
 
+// NpmCacheFromBytes initialize NPM cache from the JSON served by the NPM debug endpoint.
+func (c *Converter) NpmCacheFromBytes(byteArray []byte) error {
+	err := c.getCacheFromBytes(byteArray)
+	if err != nil {
+		return errors.Wrap(err, "failed to get cache from bytes")
+	}
+
+	return nil
+}
+
 // NpmCache initialize NPM cache from node.
 func (c *Converter) NpmCache() error {
 	req, err := http.NewRequestWithContext(
@@ -255,6 +268,24 @@ func (c *Converter) GetProtobufRulesFromIptable(tableName string) (map[*pb.RuleR
 	return ruleResList, nil
 }
 
+// GetProtobufRulesFromParser returns a list of protobuf rules using the converter's Parser.
+// The NPM cache must already be initialized, e.g. with NpmCacheFromBytes.
+func (c *Converter) GetProtobufRulesFromParser(tableName string) (map[*pb.RuleResponse]struct{}, error) {
+	c.initConverterMaps()
+
+	ipTable, err := c.Parser.Iptables(tableName)
+	if err != nil {
+		return nil, fmt.Errorf("error occurred during parsing iptables : %w", err)
+	}
+
+	ruleResList, err := c.pbRuleList(ipTable)
+	if err != nil {
+		return nil, fmt.Errorf("error occurred during getting protobuf rules from iptables : %w", err)
+	}
+
+	return ruleResList, nil
+}
+
 // Create a list of protobuf rules from iptable.
 func (c *Converter) pbRuleList(ipTable *NPMIPtable.Table) (map[*pb.RuleResponse]struct{}, error) {
 	allRulesInNPMChains := make(map[*pb.RuleResponse]struct{}, 0)
@@ -434,6 +465,10 @@ func (c *Converter) getSetTypeV2(name string) (pb.SetType, ipsets.SetKind) {
 	case strings.HasPrefix(name, util.NestedLabelPrefix):
 		settype = pb.SetType_NESTEDLABELOFPOD
 		setmetadata.Type = ipsets.NestedLabelOfPod
+	case strings.HasPrefix(name, util.FQDNPrefix):
+		// FQDN sets hold IPs like CIDR sets
+		settype = pb.SetType_CIDRBLOCKS
+		setmetadata.Type = ipsets.FQDNAddresses
 	default:
 		log.Printf("set [%s] unknown settype", name)
 		settype = pb.SetType_UNKNOWN
@@ -515,6 +550,9 @@ func (c *Converter) populateSetInfo(setInfo *pb.RuleResponse_SetInfo, values []s
 		}
 
 		setInfo.Type = settype
+		if settype == pb.SetType_CIDRBLOCKS {
+			setInfo.Contents = c.CIDRBlockContents[ipsetHashedName]
+		}
 	} else {
 		if v, ok := c.ListMap[ipsetHashedName]; ok {
 			setInfo.Name = v
@@ -99,7 +99,7 @@ func TestGetProtobufRulesFromIptableFileV2(t *testing.T) {
 		},
 	}
 
-	hitrules, _, _, err := getHitRules(srcPod, dstPod, rules, c.NPMCache)
+	hitrules, _, _, err := getHitRules(srcPod, dstPod, rules, c.NPMCache, false)
 	require.NoError(t, err)
 	log.Printf("hitrules %+v", hitrules)
 	if err != nil {
@@ -96,7 +96,7 @@ func (c *Converter) GetNetworkTuple(src, dst *common.Input, config *npmconfig.Co
 
 	// after we have all rules from the AZURE-NPM chains in the filter table, get the network tuples of src and dst
 
-	return getNetworkTupleCommon(src, dst, c.NPMCache, allRules)
+	return getNetworkTupleCommon(src, dst, c.NPMCache, allRules, false)
 }
 
 // GetNetworkTupleFile read from NPM cache and iptables-save files and
@@ -112,7 +112,20 @@ func (c *Converter) GetNetworkTupleFile( //nolint:gocritic
 		return nil, nil, nil, nil, fmt.Errorf("error occurred during get network tuple : %w", err)
 	}
 
-	return getNetworkTupleCommon(src, dst, c.NPMCache, allRules)
+	return getNetworkTupleCommon(src, dst, c.NPMCache, allRules, false)
+}
+
+// GetNetworkTupleFromParser reads the iptables filter table through the converter's Parser and
+// uses the already initialized NPM cache (see NpmCacheFromBytes) to return a list of hit rules
+// between the source and the destination in JSON format and a list of tuples from those rules.
+// With V2 enabled, every match set of a rule must match, as in the kernel.
+func (c *Converter) GetNetworkTupleFromParser(src, dst *common.Input) ([][]byte, []*TupleAndRule, map[string]*pb.RuleResponse_SetInfo, map[string]*pb.RuleResponse_SetInfo, error) { //nolint: gocritic,lll
+	allRules, err := c.GetProtobufRulesFromParser(util.IptablesFilterTable)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("error occurred during get network tuple : %w", err)
+	}
+
+	return getNetworkTupleCommon(src, dst, c.NPMCache, allRules, c.EnableV2NPM)
 }
 
 // Common function.
@@ -120,6 +133,7 @@ func getNetworkTupleCommon(
 	src, dst *common.Input,
 	npmCache common.GenericCache,
 	allRules map[*pb.RuleResponse]struct{},
+	matchAllSets bool,
 ) ([][]byte, []*TupleAndRule, map[string]*pb.RuleResponse_SetInfo, map[string]*pb.RuleResponse_SetInfo, error) {
 
 	srcPod, err := npmCache.GetPod(src)
@@ -133,7 +147,7 @@ func getNetworkTupleCommon(
 	}
 
 	// find all rules where the source pod and dest pod exist
-	hitRules, srcSets, dstSets, err := getHitRules(srcPod, dstPod, allRules, npmCache)
+	hitRules, srcSets, dstSets, err := getHitRules(srcPod, dstPod, allRules, npmCache, matchAllSets)
 	if err != nil {
 		return nil, nil, srcSets, dstSets, fmt.Errorf("%w", err)
 	}
@@ -220,6 +234,7 @@ func getHitRules(
 	src, dst *common.NpmPod,
 	rules map[*pb.RuleResponse]struct{},
 	npmCache common.GenericCache,
+	matchAllSets bool,
 ) ([]*pb.RuleResponse, map[string]*pb.RuleResponse_SetInfo, map[string]*pb.RuleResponse_SetInfo, error) {
 
 	res := make([]*pb.RuleResponse, 0)
@@ -227,43 +242,16 @@ func getHitRules(
 	dstSets := make(map[string]*pb.RuleResponse_SetInfo, 0)
 
 	for rule := range rules {
-		matchedSrc := false
-		matchedDst := false
 		// evalute all match set in src
-		for _, setInfo := range rule.SrcList {
-			if src.Namespace == "" {
-				// internet
-				break
-			}
-
-			matchedSource, err := evaluateSetInfo("src", setInfo, src, rule, npmCache)
-			if err != nil {
-				return nil, nil, nil, fmt.Errorf("error occurred during evaluating source's set info : %w", err)
-			}
-			if matchedSource {
-				matchedSrc = true
-				srcSets[setInfo.HashedSetName] = setInfo
-				break
-			}
+		matchedSrc, err := matchSetList("src", rule.SrcList, src, rule, npmCache, matchAllSets, srcSets)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("error occurred during evaluating source's set info : %w", err)
 		}
 
 		// evaluate all match set in dst
-		for _, setInfo := range rule.DstList {
-			if dst.Namespace == "" {
-				// internet
-				break
-			}
-
-			matchedDestination, err := evaluateSetInfo("dst", setInfo, dst, rule, npmCache)
-			if err != nil {
-				return nil, nil, nil, fmt.Errorf("error occurred during evaluating destination's set info : %w", err)
-			}
-			if matchedDestination {
-
-				dstSets[setInfo.HashedSetName] = setInfo
-				matchedDst = true
-				break
-			}
+		matchedDst, err := matchSetList("dst", rule.DstList, dst, rule, npmCache, matchAllSets, dstSets)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("error occurred during evaluating destination's set info : %w", err)
 		}
 
 		// conditions:
@@ -285,6 +273,55 @@ func getHitRules(
 	return res, srcSets, dstSets, nil
 }
 
+// matchSetList evaluates the match sets of one side of a rule and records the matched sets.
+// By default, the first matching set is enough. With matchAllSets, every set must match like in iptables,
+// and V2 set names are evaluated.
+func matchSetList(
+	origin string,
+	setList []*pb.RuleResponse_SetInfo,
+	pod *common.NpmPod,
+	rule *pb.RuleResponse,
+	npmCache common.GenericCache,
+	matchAllSets bool,
+	matchedSets map[string]*pb.RuleResponse_SetInfo,
+) (bool, error) {
+	if pod.Namespace == "" {
+		// internet
+		return false, nil
+	}
+
+	if !matchAllSets {
+		for _, setInfo := range setList {
+			matched, err := evaluateSetInfo(origin, setInfo, pod, rule, npmCache)
+			if err != nil {
+				return false, err
+			}
+			if matched {
+				matchedSets[setInfo.HashedSetName] = setInfo
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	if len(setList) == 0 {
+		return false, nil
+	}
+	for _, setInfo := range setList {
+		matched, err := evaluateSetInfoV2(origin, setInfo, pod, rule, npmCache)
+		if err != nil {
+			return false, err
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	for _, setInfo := range setList {
+		matchedSets[setInfo.HashedSetName] = setInfo
+	}
+	return true, nil
+}
+
 // evalute an ipset to find out whether the pod's attributes match with the set
 func evaluateSetInfo(
 	origin string,
@@ -316,6 +353,42 @@ func evaluateSetInfo(
 	}
 }
 
+// evaluate a V2 ipset to find out whether the pod's attributes match with the set.
+// V2 set names carry a type prefix (e.g. podlabel-app:frontend) and the V2 cache keys namespaces without the ns- prefix.
+func evaluateSetInfoV2(
+	origin string,
+	setInfo *pb.RuleResponse_SetInfo,
+	pod *common.NpmPod,
+	rule *pb.RuleResponse,
+	npmCache common.GenericCache,
+) (bool, error) {
+	switch {
+	case strings.HasPrefix(setInfo.Name, util.NamespacePrefix):
+		return matchNAMESPACE(pod, setInfo), nil
+	case strings.HasPrefix(setInfo.Name, util.PodLabelPrefix):
+		key, value, hasValue := strings.Cut(strings.TrimPrefix(setInfo.Name, util.PodLabelPrefix), util.IpsetLabelDelimter)
+		actualValue, hasKey := pod.Labels[key]
+		return setInfo.Included == (hasKey && (!hasValue || actualValue == value)), nil
+	case strings.HasPrefix(setInfo.Name, util.NamespaceLabelPrefix):
+		label := strings.TrimPrefix(setInfo.Name, util.NamespaceLabelPrefix)
+		if label == util.KubeAllNamespacesFlag {
+			return setInfo.Included, nil
+		}
+		key, value, hasValue := strings.Cut(label, util.IpsetLabelDelimter)
+		actualValue := npmCache.GetNamespaceLabel(pod.Namespace, key)
+		// NOTE: a namespace label with an empty value is indistinguishable from a missing label in the cache
+		return setInfo.Included == (actualValue != "" && (!hasValue || actualValue == value)), nil
+	case strings.HasPrefix(setInfo.Name, util.NestedLabelPrefix):
+		return matchNESTEDLABELOFPOD(pod, setInfo), nil
+	case strings.HasPrefix(setInfo.Name, util.NamedPortIPSetPrefix):
+		return matchNAMEDPORTS(pod, setInfo, rule, origin), nil
+	case strings.HasPrefix(setInfo.Name, util.CIDRPrefix):
+		return matchCIDRBLOCKS(pod, setInfo), nil
+	default:
+		return false, common.ErrSetType
+	}
+}
+
 func matchKEYVALUELABELOFNAMESPACE(pod *common.NpmPod, npmCache common.GenericCache, setInfo *pb.RuleResponse_SetInfo) bool {
 	srcNamespace := util.NamespacePrefix + pod.Namespace
 	key, expectedValue := processKeyValueLabelOfNameSpace(setInfo.Name)
@@ -0,0 +1,173 @@
+package dataplane
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-container-networking/npm/pkg/controlplane/controllers/common"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
+	"github.com/Azure/azure-container-networking/npm/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// keys of the NPM debug cache which aren't in the cache file.
+// They match the cache keys in npm/pkg/models, which can't be imported since it depends on the controllers.
+const (
+	nsMapCacheKey     = "NsMap"
+	podMapCacheKey    = "PodMap"
+	setMapCacheKey    = "SetMap"
+	endpointsCacheKey = "Endpoints"
+)
+
+// MarshalJSON encodes the dataplane in the format of the NPM debug cache, so that the debug tooling
+// (e.g. gettuples and convertiptable) works against daemons, which don't have the controllers' caches.
+// The NsMap and PodMap are rebuilt from the ipsets, so they only hold the namespaces and pods known to this node.
+func (dp *DataPlane) MarshalJSON() ([]byte, error) {
+	// include unapplied changes instead of failing while goal states are being applied
+	sets := dp.ipsetMgr.CacheSnapshot()
+	nsMap, podMap := namespacesAndPodsFromIPSets(sets)
+
+	dp.endpointCache.Lock()
+	endpointsRaw, err := json.Marshal(dp.endpointCache.cache)
+	dp.endpointCache.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal endpoints: %w", err)
+	}
+
+	m := map[string]json.RawMessage{endpointsCacheKey: endpointsRaw}
+	for key, v := range map[string]interface{}{
+		nodeNameCacheKey: dp.nodeName,
+		nsMapCacheKey:    nsMap,
+		podMapCacheKey:   podMap,
+		setMapCacheKey:   dp.GetAllIPSets(),
+		ipsetsCacheKey:   sets,
+		policiesCacheKey: dp.policyMgr.GetAllPolicies(),
+	} {
+		if m[key], err = json.Marshal(v); err != nil {
+			return nil, fmt.Errorf("failed to marshal %s: %w", key, err)
+		}
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal dataplane: %w", err)
+	}
+	return b, nil
+}
+
+// namespacesAndPodsFromIPSets rebuilds the namespaces and pods (keyed by "namespace/name") which the sets were created for.
+// Labels and named ports are only known for the label and named port sets that exist on this node.
+func namespacesAndPodsFromIPSets(sets []*ipsets.SetSnapshot) (map[string]*common.Namespace, map[string]*common.NpmPod) {
+	nsMap := make(map[string]*common.Namespace)
+	podMap := make(map[string]*common.NpmPod)
+
+	getNamespace := func(name string) *common.Namespace {
+		ns, ok := nsMap[name]
+		if !ok {
+			ns = common.NewNs(name)
+			nsMap[name] = ns
+		}
+		return ns
+	}
+	getPod := func(podKey string) *common.NpmPod {
+		pod, ok := podMap[podKey]
+		if !ok {
+			namespace, name, _ := strings.Cut(podKey, "/")
+			pod = &common.NpmPod{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    make(map[string]string),
+			}
+			podMap[podKey] = pod
+		}
+		return pod
+	}
+
+	setsByPrefixedName := make(map[string]*ipsets.IPSetMetadata, len(sets))
+	for _, set := range sets {
+		setsByPrefixedName[set.Metadata.GetPrefixName()] = set.Metadata
+	}
+
+	for _, set := range sets {
+		switch set.Metadata.Type {
+		case ipsets.Namespace:
+			getNamespace(set.Metadata.Name)
+			for ip, podKey := range set.IPPodKey {
+				if podKey != "" {
+					getPod(podKey).PodIP = ip
+				}
+			}
+		case ipsets.KeyLabelOfPod, ipsets.KeyValueLabelOfPod:
+			key, value, _ := strings.Cut(set.Metadata.Name, util.IpsetLabelDelimter)
+			for ip, podKey := range set.IPPodKey {
+				if podKey == "" {
+					continue
+				}
+				pod := getPod(podKey)
+				pod.PodIP = ip
+				// the key-value set has the value, which may be empty
+				if _, ok := pod.Labels[key]; !ok || set.Metadata.Type == ipsets.KeyValueLabelOfPod {
+					pod.Labels[key] = value
+				}
+			}
+		case ipsets.NamedPorts:
+			for member, podKey := range set.IPPodKey {
+				port, ok := namedPortFromMember(set.Metadata.Name, member)
+				if podKey == "" || !ok {
+					continue
+				}
+				pod := getPod(podKey)
+				pod.ContainerPorts = append(pod.ContainerPorts, port)
+			}
+		case ipsets.KeyLabelOfNamespace, ipsets.KeyValueLabelOfNamespace:
+			if set.Metadata.Name == util.KubeAllNamespacesFlag {
+				continue
+			}
+			key, value, _ := strings.Cut(set.Metadata.Name, util.IpsetLabelDelimter)
+			for _, memberName := range set.MemberSets {
+				member, ok := setsByPrefixedName[memberName]
+				if !ok || member.Type != ipsets.Namespace {
+					continue
+				}
+				labels := getNamespace(member.Name).LabelsMap
+				if _, ok := labels[key]; !ok || set.Metadata.Type == ipsets.KeyValueLabelOfNamespace {
+					labels[key] = value
+				}
+			}
+		}
+	}
+
+	for _, pod := range podMap {
+		sort.Slice(pod.ContainerPorts, func(i, j int) bool {
+			if pod.ContainerPorts[i].Name != pod.ContainerPorts[j].Name {
+				return pod.ContainerPorts[i].Name < pod.ContainerPorts[j].Name
+			}
+			return pod.ContainerPorts[i].ContainerPort < pod.ContainerPorts[j].ContainerPort
+		})
+	}
+	return nsMap, podMap
+}
+
+// namedPortFromMember parses a member of a named port set, e.g. "10.0.0.1,TCP:8080" or "10.0.0.1,8080"
+func namedPortFromMember(portName, member string) (corev1.ContainerPort, bool) {
+	_, protocolAndPort, ok := strings.Cut(member, ",")
+	if !ok {
+		return corev1.ContainerPort{}, false
+	}
+	protocol, port, ok := strings.Cut(protocolAndPort, ":")
+	if !ok {
+		protocol, port = "", protocolAndPort
+	}
+	portNumber, err := strconv.ParseInt(port, 10, 32)
+	if err != nil {
+		return corev1.ContainerPort{}, false
+	}
+	return corev1.ContainerPort{
+		Name:          portName,
+		Protocol:      corev1.Protocol(protocol),
+		ContainerPort: int32(portNumber),
+	}, true
+}
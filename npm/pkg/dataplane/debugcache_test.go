@@ -0,0 +1,108 @@
+package dataplane
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/common"
+	"github.com/Azure/azure-container-networking/npm/metrics"
+	controllerscommon "github.com/Azure/azure-container-networking/npm/pkg/controlplane/controllers/common"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/policies"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	metrics.InitializeAll()
+
+	calls := append(getBootupTestCalls(), getAddPolicyTestCallsForDP(&testPolicyobj)...)
+	ioshim := common.NewMockIOShim(calls)
+	defer ioshim.VerifyCalls(t, calls)
+	dp, err := NewDataPlane(nodeName, ioshim, dpCfg, nil)
+	require.NoError(t, err)
+	require.NoError(t, dp.AddPolicy(&testPolicyobj))
+
+	nsSet := ipsets.NewIPSetMetadata("x", ipsets.Namespace)
+	podSets := []*ipsets.IPSetMetadata{
+		nsSet,
+		ipsets.NewIPSetMetadata("app", ipsets.KeyLabelOfPod),
+		ipsets.NewIPSetMetadata("app:web", ipsets.KeyValueLabelOfPod),
+	}
+	require.NoError(t, dp.AddToSets(podSets, NewPodMetadata("x/a", "10.0.0.1", nodeName)))
+	namedPort := NewPodMetadata("x/a", "10.0.0.1,TCP:8080", nodeName)
+	require.NoError(t, dp.AddToSets([]*ipsets.IPSetMetadata{ipsets.NewIPSetMetadata("http", ipsets.NamedPorts)}, namedPort))
+	require.NoError(t, dp.AddToLists([]*ipsets.IPSetMetadata{
+		ipsets.NewIPSetMetadata("team", ipsets.KeyLabelOfNamespace),
+		ipsets.NewIPSetMetadata("team:blue", ipsets.KeyValueLabelOfNamespace),
+		ipsets.NewIPSetMetadata("all-namespaces", ipsets.KeyLabelOfNamespace),
+	}, []*ipsets.IPSetMetadata{nsSet}))
+
+	b, err := json.Marshal(dp)
+	require.NoError(t, err)
+
+	m := map[string]json.RawMessage{}
+	require.NoError(t, json.Unmarshal(b, &m))
+
+	var gotNodeName string
+	require.NoError(t, json.Unmarshal(m[nodeNameCacheKey], &gotNodeName))
+	require.Equal(t, nodeName, gotNodeName)
+
+	nsMap := map[string]*controllerscommon.Namespace{}
+	require.NoError(t, json.Unmarshal(m[nsMapCacheKey], &nsMap))
+	require.Equal(t, map[string]*controllerscommon.Namespace{
+		"x":      {Name: "x", LabelsMap: map[string]string{"team": "blue"}},
+		"setns1": {Name: "setns1", LabelsMap: map[string]string{}},
+		"setns2": {Name: "setns2", LabelsMap: map[string]string{}},
+	}, nsMap)
+
+	podMap := map[string]*controllerscommon.NpmPod{}
+	require.NoError(t, json.Unmarshal(m[podMapCacheKey], &podMap))
+	require.Equal(t, map[string]*controllerscommon.NpmPod{
+		"x/a": {
+			Name:      "a",
+			Namespace: "x",
+			PodIP:     "10.0.0.1",
+			Labels:    map[string]string{"app": "web"},
+			ContainerPorts: []corev1.ContainerPort{
+				{Name: "http", Protocol: corev1.ProtocolTCP, ContainerPort: 8080},
+			},
+		},
+	}, podMap)
+
+	setMap := map[string]string{}
+	require.NoError(t, json.Unmarshal(m[setMapCacheKey], &setMap))
+	require.Equal(t, dp.GetAllIPSets(), setMap)
+
+	var sets []*ipsets.SetSnapshot
+	require.NoError(t, json.Unmarshal(m[ipsetsCacheKey], &sets))
+	require.Len(t, sets, len(setMap))
+
+	var gotPolicies []*policies.NPMNetworkPolicy
+	require.NoError(t, json.Unmarshal(m[policiesCacheKey], &gotPolicies))
+	require.Len(t, gotPolicies, 1)
+	require.Equal(t, testPolicyobj.PolicyKey, gotPolicies[0].PolicyKey)
+
+	require.Contains(t, m, endpointsCacheKey)
+}
+
+func TestNamedPortFromMember(t *testing.T) {
+	tests := []struct {
+		member string
+		want   corev1.ContainerPort
+		ok     bool
+	}{
+		{"10.0.0.1,TCP:8080", corev1.ContainerPort{Name: "http", Protocol: corev1.ProtocolTCP, ContainerPort: 8080}, true},
+		{"10.0.0.1,8080", corev1.ContainerPort{Name: "http", ContainerPort: 8080}, true},
+		{"10.0.0.1", corev1.ContainerPort{}, false},
+		{"10.0.0.1,TCP:http", corev1.ContainerPort{}, false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.member, func(t *testing.T) {
+			got, ok := namedPortFromMember("http", tt.member)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
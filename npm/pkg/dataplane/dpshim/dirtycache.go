@@ -1,19 +1,35 @@
 package dpshim
 
-import "k8s.io/klog"
+import (
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/policies"
+	"k8s.io/klog"
+)
 
 type dirtyCache struct {
-	toAddorUpdateSets     map[string]struct{}
+	toAddorUpdateSets map[string]struct{}
+	// setMemberNodes holds the nodes whose members changed for sets in toAddorUpdateSets which only had member changes.
+	// Sets in toAddorUpdateSets and not in setMemberNodes changed for all nodes.
+	setMemberNodes map[string]map[string]struct{}
+	// setMembersBefore holds the pod metadata of changed members before their first change, for sets in setMemberNodes.
+	// The pod metadata is nil if the IP wasn't a member.
+	setMembersBefore      map[string]map[string]*dataplane.PodMetadata
 	toDeleteSets          map[string]struct{}
 	toAddorUpdatePolicies map[string]struct{}
-	toDeletePolicies      map[string]struct{}
+	// policiesBefore holds policies in toAddorUpdatePolicies as they were before their first change.
+	// The policy is nil if it was added.
+	policiesBefore   map[string]*policies.NPMNetworkPolicy
+	toDeletePolicies map[string]struct{}
 }
 
 func newDirtyCache() *dirtyCache {
 	return &dirtyCache{
 		toAddorUpdateSets:     make(map[string]struct{}),
+		setMemberNodes:        make(map[string]map[string]struct{}),
+		setMembersBefore:      make(map[string]map[string]*dataplane.PodMetadata),
 		toDeleteSets:          make(map[string]struct{}),
 		toAddorUpdatePolicies: make(map[string]struct{}),
+		policiesBefore:        make(map[string]*policies.NPMNetworkPolicy),
 		toDeletePolicies:      make(map[string]struct{}),
 	}
 }
@@ -21,19 +37,81 @@ func newDirtyCache() *dirtyCache {
 func (dc *dirtyCache) clearCache() {
 	klog.Infof("Clearing dirty cache")
 	dc.toAddorUpdateSets = make(map[string]struct{})
+	dc.setMemberNodes = make(map[string]map[string]struct{})
+	dc.setMembersBefore = make(map[string]map[string]*dataplane.PodMetadata)
 	dc.toDeleteSets = make(map[string]struct{})
 	dc.toAddorUpdatePolicies = make(map[string]struct{})
+	dc.policiesBefore = make(map[string]*policies.NPMNetworkPolicy)
 	dc.toDeletePolicies = make(map[string]struct{})
 	dc.printContents()
 }
 
 func (dc *dirtyCache) modifyAddorUpdateSets(setName string) {
 	delete(dc.toDeleteSets, setName)
+	delete(dc.setMemberNodes, setName)
+	delete(dc.setMembersBefore, setName)
 	dc.toAddorUpdateSets[setName] = struct{}{}
 }
 
+// modifyAddorUpdateSetMembers marks a change to the members of a set which belong to the node.
+// Members without a node belong to all nodes.
+func (dc *dirtyCache) modifyAddorUpdateSetMembers(setName, nodeName string) {
+	_, isDirty := dc.toAddorUpdateSets[setName]
+	nodes, onlyMembersChanged := dc.setMemberNodes[setName]
+	if nodeName == "" || (isDirty && !onlyMembersChanged) {
+		dc.modifyAddorUpdateSets(setName)
+		return
+	}
+
+	if !onlyMembersChanged {
+		nodes = make(map[string]struct{})
+		dc.setMemberNodes[setName] = nodes
+	}
+	nodes[nodeName] = struct{}{}
+	delete(dc.toDeleteSets, setName)
+	dc.toAddorUpdateSets[setName] = struct{}{}
+}
+
+// setChangedForNode returns true if the set was marked dirty for all nodes or had members of the node change
+func (dc *dirtyCache) setChangedForNode(setName, nodeName string) bool {
+	if _, ok := dc.toAddorUpdateSets[setName]; !ok {
+		return false
+	}
+	nodes, onlyMembersChanged := dc.setMemberNodes[setName]
+	if !onlyMembersChanged {
+		return true
+	}
+	_, ok := nodes[nodeName]
+	return ok
+}
+
+// recordSetMember records the pod metadata of a member IP before its first change, so that member deltas can be sent.
+// It must be called after modifyAddorUpdateSetMembers. podMetadata is nil if the IP wasn't a member.
+func (dc *dirtyCache) recordSetMember(setName, ip string, podMetadata *dataplane.PodMetadata) {
+	if !dc.onlyMembersChanged(setName) {
+		return
+	}
+
+	members, ok := dc.setMembersBefore[setName]
+	if !ok {
+		members = make(map[string]*dataplane.PodMetadata)
+		dc.setMembersBefore[setName] = members
+	}
+	if _, ok := members[ip]; !ok {
+		members[ip] = podMetadata
+	}
+}
+
+// onlyMembersChanged returns true if the set is dirty only because members with a node name changed
+func (dc *dirtyCache) onlyMembersChanged(setName string) bool {
+	_, ok := dc.setMemberNodes[setName]
+	return ok
+}
+
 func (dc *dirtyCache) modifyDeleteSets(setName string) {
 	delete(dc.toAddorUpdateSets, setName)
+	delete(dc.setMemberNodes, setName)
+	delete(dc.setMembersBefore, setName)
 	dc.toDeleteSets[setName] = struct{}{}
 }
 
@@ -42,8 +120,17 @@ func (dc *dirtyCache) modifyAddorUpdatePolicies(policyName string) {
 	dc.toAddorUpdatePolicies[policyName] = struct{}{}
 }
 
+// recordPolicy records the policy before its first change, so that policy deltas can be sent.
+// policy is nil if the policy didn't exist.
+func (dc *dirtyCache) recordPolicy(policyName string, policy *policies.NPMNetworkPolicy) {
+	if _, ok := dc.policiesBefore[policyName]; !ok {
+		dc.policiesBefore[policyName] = policy
+	}
+}
+
 func (dc *dirtyCache) modifyDeletePolicies(policyName string) {
 	delete(dc.toAddorUpdatePolicies, policyName)
+	delete(dc.policiesBefore, policyName)
 	dc.toDeletePolicies[policyName] = struct{}{}
 }
 
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"reflect"
 	"sync"
 	"time"
 
+	"github.com/Azure/azure-container-networking/npm/metrics"
 	"github.com/Azure/azure-container-networking/npm/pkg/controlplane"
 	"github.com/Azure/azure-container-networking/npm/pkg/dataplane"
 	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
@@ -25,56 +27,146 @@ var ErrChannelUnset = errors.New("channel must be set")
 // to have a common interface for both.
 
 type DPShim struct {
-	OutChannel  chan *protos.Events
+	// OutChannel receives the events of each apply in the order they were applied. See sendEvents
+	OutChannel  chan NodeEvents
 	stopChannel <-chan struct{}
-	setCache    map[string]*controlplane.ControllerIPSets
-	policyCache map[string]*policies.NPMNetworkPolicy
-	dirtyCache  *dirtyCache
-	mu          *sync.Mutex
+	// pendingEvents are the events of applies which haven't been received from OutChannel yet, oldest first.
+	// Events must arrive in order since daemons apply IPSetDeltas and PolicyDeltas on top of what they have.
+	pendingEvents []NodeEvents
+	// eventsPending is signaled when events are queued
+	eventsPending chan struct{}
+	setCache      map[string]*controlplane.ControllerIPSets
+	policyCache   map[string]*policies.NPMNetworkPolicy
+	dirtyCache    *dirtyCache
+	// nodes has the view of each node with connected daemons. See nodes.go
+	nodes map[string]*nodeView
+	// generation increases with each applied change and is sent with every event
+	generation uint64
+	mu         *sync.Mutex
 }
 
 func NewDPSim(stopChannel <-chan struct{}) (*DPShim, error) {
-	return &DPShim{
-		OutChannel:  make(chan *protos.Events),
-		setCache:    make(map[string]*controlplane.ControllerIPSets),
-		policyCache: make(map[string]*policies.NPMNetworkPolicy),
-		stopChannel: stopChannel,
-		dirtyCache:  newDirtyCache(),
-		mu:          &sync.Mutex{},
-	}, nil
+	dp := &DPShim{
+		OutChannel:    make(chan NodeEvents),
+		eventsPending: make(chan struct{}, 1),
+		setCache:      make(map[string]*controlplane.ControllerIPSets),
+		policyCache:   make(map[string]*policies.NPMNetworkPolicy),
+		stopChannel:   stopChannel,
+		dirtyCache:    newDirtyCache(),
+		nodes:         make(map[string]*nodeView),
+		mu:            &sync.Mutex{},
+	}
+	go dp.sendEvents()
+	return dp, nil
+}
+
+// queueEvents queues the events of an apply for sendEvents. The caller must hold the lock.
+func (dp *DPShim) queueEvents(events NodeEvents) {
+	dp.pendingEvents = append(dp.pendingEvents, events)
+	select {
+	case dp.eventsPending <- struct{}{}:
+	default:
+	}
+}
+
+// sendEvents sends the queued events to OutChannel one at a time and in order until the stop channel is closed.
+// Applies don't block on OutChannel since its receiver may be waiting for the lock, e.g. to hydrate a client.
+func (dp *DPShim) sendEvents() {
+	for {
+		select {
+		case <-dp.stopChannel:
+			return
+		case <-dp.eventsPending:
+		}
+
+		for {
+			dp.lock()
+			if len(dp.pendingEvents) == 0 {
+				dp.unlock()
+				break
+			}
+			events := dp.pendingEvents[0]
+			dp.pendingEvents[0] = nil
+			dp.pendingEvents = dp.pendingEvents[1:]
+			dp.unlock()
+
+			select {
+			case dp.OutChannel <- events:
+			case <-dp.stopChannel:
+				return
+			}
+		}
+	}
 }
 
 func (dp *DPShim) BootupDataplane() error {
 	return nil
 }
 
-// HydrateClients is used in DPShim to hydrate a restarted Daemon Client
-func (dp *DPShim) HydrateClients() (*protos.Events, error) {
+// HydrateClients is used in DPShim to hydrate a restarted Daemon Client on the node.
+// If the client sends digests of what it already applied (e.g. after failing over from another controller),
+// it returns a goal state event with only the differences instead.
+// Afterwards, ApplyDataPlane sends events to the node until RemoveClient is called for each hydrated client.
+func (dp *DPShim) HydrateClients(client *protos.DatapathPodMetadata) (*protos.Events, error) {
 	dp.lock()
 	defer dp.unlock()
 
-	if len(dp.setCache) == 0 && len(dp.policyCache) == 0 {
-		klog.Infof("HydrateClients: No local cache objects to hydrate daemon client")
-		return nil, nil
+	nodeName := client.GetNodeName()
+	ipsetDigests, policyDigests := client.GetIpsetDigests(), client.GetPolicyDigests()
+	view := dp.registerNode(client)
+	if len(ipsetDigests) == 0 && len(policyDigests) == 0 {
+		return dp.hydrationEvent(view, nodeName)
 	}
 
-	goalStates := make(map[string]*protos.GoalState)
-
-	toApplySets, err := dp.hydrateSetCache()
+	goalStates, err := dp.diffForNode(view, nodeName, ipsetDigests, policyDigests)
 	if err != nil {
 		return nil, err
 	}
-	if toApplySets != nil {
-		goalStates[controlplane.IpsetApply] = toApplySets
+
+	if len(goalStates) == 0 {
+		klog.Infof("HydrateClients: client on node %s is up to date", nodeName)
+		return nil, nil
+	}
+
+	return &protos.Events{
+		EventType:  protos.Events_GoalState,
+		Payload:    goalStates,
+		Generation: dp.generation,
+	}, nil
+}
+
+// RehydrateNode returns a hydration event for a node with connected daemons, e.g. when its daemons failed to apply goal states.
+// It returns nil if the node isn't tracked.
+func (dp *DPShim) RehydrateNode(nodeName string) (*protos.Events, error) {
+	dp.lock()
+	defer dp.unlock()
+
+	view, ok := dp.nodes[nodeName]
+	if !ok {
+		klog.Infof("RehydrateNode: node %s has no connected clients", nodeName)
+		return nil, nil
+	}
+	view.hydrated = true
+	return dp.hydrationEvent(view, nodeName)
+}
+
+// Generation returns the current goal state generation
+func (dp *DPShim) Generation() uint64 {
+	dp.lock()
+	defer dp.unlock()
+	return dp.generation
+}
+
+func (dp *DPShim) hydrationEvent(view *nodeView, nodeName string) (*protos.Events, error) {
+	if len(dp.setCache) == 0 && len(dp.policyCache) == 0 {
+		klog.Infof("HydrateClients: No local cache objects to hydrate daemon client")
+		return nil, nil
 	}
 
-	toApplyPolicies, err := dp.hydratePolicyCache()
+	goalStates, err := dp.hydrationForNode(view, nodeName)
 	if err != nil {
 		return nil, err
 	}
-	if toApplyPolicies != nil {
-		goalStates[controlplane.PolicyApply] = toApplyPolicies
-	}
 
 	if len(goalStates) == 0 {
 		klog.Info("HydrateClients: No changes to apply")
@@ -82,8 +174,9 @@ func (dp *DPShim) HydrateClients() (*protos.Events, error) {
 	}
 
 	return &protos.Events{
-		EventType: protos.Events_Hydration,
-		Payload:   goalStates,
+		EventType:  protos.Events_Hydration,
+		Payload:    goalStates,
+		Generation: dp.generation,
 	}, nil
 }
 
@@ -169,11 +262,16 @@ func (dp *DPShim) AddToSets(setMetadatas []*ipsets.IPSetMetadata, podMetadata *d
 		}
 
 		cachedPodMetadata, ok := set.IPPodMetadata[podMetadata.PodIP]
-		if ok && cachedPodMetadata.PodKey == podMetadata.PodKey {
+		if ok && cachedPodMetadata.PodKey == podMetadata.PodKey && cachedPodMetadata.NodeName == podMetadata.NodeName {
 			continue
 		}
+		if ok && cachedPodMetadata.NodeName != podMetadata.NodeName {
+			// the IP moved to another node
+			dp.dirtyCache.modifyAddorUpdateSetMembers(prefixedSetName, cachedPodMetadata.NodeName)
+		}
 		set.IPPodMetadata[podMetadata.PodIP] = podMetadata
-		dp.dirtyCache.modifyAddorUpdateSets(prefixedSetName)
+		dp.dirtyCache.modifyAddorUpdateSetMembers(prefixedSetName, podMetadata.NodeName)
+		dp.dirtyCache.recordSetMember(prefixedSetName, podMetadata.PodIP, cachedPodMetadata)
 	}
 
 	return nil
@@ -212,7 +310,8 @@ func (dp *DPShim) RemoveFromSets(setMetadatas []*ipsets.IPSetMetadata, podMetada
 
 		// update the IP ownership with podkey
 		delete(set.IPPodMetadata, podMetadata.PodIP)
-		dp.dirtyCache.modifyAddorUpdateSets(prefixedSetName)
+		dp.dirtyCache.modifyAddorUpdateSetMembers(prefixedSetName, cachedPod.NodeName)
+		dp.dirtyCache.recordSetMember(prefixedSetName, podMetadata.PodIP, cachedPod)
 	}
 	return nil
 }
@@ -342,6 +441,7 @@ func (dp *DPShim) AddPolicy(networkpolicies *policies.NPMNetworkPolicy) error {
 		return npmerrors.Errorf(npmerrors.AddPolicy, false, fmt.Sprintf("couldn't add malformed policy: %s", vErr.Error()))
 	}
 	dp.policyCache[networkpolicies.PolicyKey] = networkpolicies
+	dp.dirtyCache.recordPolicy(networkpolicies.PolicyKey, nil)
 	dp.dirtyCache.modifyAddorUpdatePolicies(networkpolicies.PolicyKey)
 
 	return err
@@ -384,7 +484,16 @@ func (dp *DPShim) UpdatePolicy(networkpolicies *policies.NPMNetworkPolicy) error
 	// For simplicity, we will not be adding references of netpols to ipsets.
 	// DP in daemon will take care of tracking the references.
 
+	// Only policies whose translation changed are sent. The policy before the change is recorded,
+	// so that nodes whose daemons all have API version V2 receive a PolicyDelta when only its rules changed.
+	cachedPolicy, ok := dp.policyCache[networkpolicies.PolicyKey]
+	if ok && reflect.DeepEqual(cachedPolicy, networkpolicies) {
+		klog.Infof("UpdatePolicy: policy %s is unchanged", networkpolicies.PolicyKey)
+		return err
+	}
+
 	dp.policyCache[networkpolicies.PolicyKey] = networkpolicies
+	dp.dirtyCache.recordPolicy(networkpolicies.PolicyKey, cachedPolicy)
 	dp.dirtyCache.modifyAddorUpdatePolicies(networkpolicies.PolicyKey)
 
 	return err
@@ -402,52 +511,37 @@ func (dp *DPShim) ApplyDataPlane() error {
 
 	dp.dirtyCache.printContents()
 
-	goalStates := make(map[string]*protos.GoalState)
-
-	toApplySets, err := dp.processIPSetsApply()
-	if err != nil {
-		return err
-	}
-	if toApplySets != nil {
-		goalStates[controlplane.IpsetApply] = toApplySets
-	}
-
-	toDeleteSets, err := dp.processIPSetsDelete()
-	if err != nil {
-		return err
-	}
-	if toDeleteSets != nil {
-		goalStates[controlplane.IpsetRemove] = toDeleteSets
-	}
+	// update the node views and generation only if goal states are computed for all nodes
+	generation := dp.generation + 1
+	idx := newNodeIndex(dp)
+	events := make(NodeEvents, len(dp.nodes))
+	views := make(map[string]*nodeView, len(dp.nodes))
+	for nodeName, oldView := range dp.nodes {
+		view, goalStates, err := dp.nodeGoalStates(idx, nodeName, oldView)
+		if err != nil {
+			return err
+		}
+		views[nodeName] = view
+		if len(goalStates) == 0 {
+			continue
+		}
 
-	toApplyPolicies, err := dp.processPoliciesApply()
-	if err != nil {
-		return err
-	}
-	if toApplyPolicies != nil {
-		goalStates[controlplane.PolicyApply] = toApplyPolicies
+		events[nodeName] = &protos.Events{
+			EventType:  protos.Events_GoalState,
+			Payload:    goalStates,
+			Generation: generation,
+		}
 	}
 
-	toDeletePolicies, err := dp.processPoliciesRemove()
-	if err != nil {
-		return err
-	}
-	if toDeletePolicies != nil {
-		goalStates[controlplane.PolicyRemove] = toDeletePolicies
+	dp.nodes = views
+	dp.generation = generation
+	metrics.SetGoalStateGeneration(generation)
+	if len(events) == 0 {
+		klog.Info("ApplyDataPlane: No changes to apply for any node")
+	} else {
+		dp.queueEvents(events)
 	}
 
-	if len(goalStates) == 0 {
-		klog.Info("ApplyDataPlane: No changes to apply")
-		return nil
-	}
-
-	go func() {
-		dp.OutChannel <- &protos.Events{
-			EventType: protos.Events_GoalState,
-			Payload:   goalStates,
-		}
-	}()
-
 	dp.dirtyCache.clearCache()
 	return nil
 }
@@ -473,146 +567,57 @@ func (dp *DPShim) policyExists(policyKey string) bool {
 	return ok
 }
 
-func (dp *DPShim) processIPSetsApply() (*protos.GoalState, error) {
-	if len(dp.dirtyCache.toAddorUpdateSets) == 0 {
-		return nil, nil
-	}
-
-	toApplySets := make([]*controlplane.ControllerIPSets, len(dp.dirtyCache.toAddorUpdateSets))
-	idx := 0
-
-	for setName := range dp.dirtyCache.toAddorUpdateSets {
-		set := dp.getCachedIPSet(setName)
-		if set == nil {
-			klog.Errorf("processIPSetsApply: set %s not found", setName)
-			return nil, npmerrors.Errorf(npmerrors.AppendIPSet, false, fmt.Sprintf("ipset %s not found", setName))
-		}
-
-		toApplySets[idx] = set
-		idx++
-	}
-
-	payload, err := controlplane.EncodeControllerIPSets(toApplySets)
-	if err != nil {
-		klog.Errorf("processIPSetsApply: failed to encode sets %v", err)
-		return nil, npmerrors.ErrorWrapper(npmerrors.AppendIPSet, false, "processIPSetsApply: failed to encode sets", err)
-	}
-
-	return getGoalStateFromBuffer(payload), nil
-}
-
-func (dp *DPShim) processIPSetsDelete() (*protos.GoalState, error) {
-	if len(dp.dirtyCache.toDeleteSets) == 0 {
-		return nil, nil
-	}
-
-	toDeleteSets := make([]string, len(dp.dirtyCache.toDeleteSets))
-	idx := 0
-
-	for setName := range dp.dirtyCache.toDeleteSets {
-		toDeleteSets[idx] = setName
-		idx++
-	}
-
-	payload, err := controlplane.EncodeStrings(toDeleteSets)
-	if err != nil {
-		klog.Errorf("processIPSetsDelete: failed to encode sets %v", err)
-		return nil, npmerrors.ErrorWrapper(npmerrors.DeleteIPSet, false, "processIPSetsDelete: failed to encode sets", err)
-	}
-
-	return getGoalStateFromBuffer(payload), nil
+func errSetNotFound(setName string) error {
+	klog.Errorf("processIPSetsApply: set %s not found", setName)
+	return npmerrors.Errorf(npmerrors.AppendIPSet, false, fmt.Sprintf("ipset %s not found", setName))
 }
 
-func (dp *DPShim) processPoliciesApply() (*protos.GoalState, error) {
-	if len(dp.dirtyCache.toAddorUpdatePolicies) == 0 {
-		return nil, nil
-	}
-
-	toApplyPolicies := make([]*policies.NPMNetworkPolicy, len(dp.dirtyCache.toAddorUpdatePolicies))
-	idx := 0
+// encodeGoalStates returns goal states for the non-empty inputs
+func encodeGoalStates(
+	toApplySets []*controlplane.ControllerIPSets,
+	toDeleteSets []string,
+	toApplyPolicies []*policies.NPMNetworkPolicy,
+	toDeletePolicies []string,
+) (map[string]*protos.GoalState, error) {
+	goalStates := make(map[string]*protos.GoalState)
 
-	for policyKey := range dp.dirtyCache.toAddorUpdatePolicies {
-		if !dp.policyExists(policyKey) {
-			return nil, npmerrors.Errorf(npmerrors.AddPolicy, false, fmt.Sprintf("policy %s not found", policyKey))
+	if len(toApplySets) > 0 {
+		payload, err := controlplane.EncodeControllerIPSets(toApplySets)
+		if err != nil {
+			klog.Errorf("processIPSetsApply: failed to encode sets %v", err)
+			return nil, npmerrors.ErrorWrapper(npmerrors.AppendIPSet, false, "processIPSetsApply: failed to encode sets", err)
 		}
-
-		policy := dp.policyCache[policyKey]
-		toApplyPolicies[idx] = policy
-		idx++
+		goalStates[controlplane.IpsetApply] = getGoalStateFromBuffer(payload)
 	}
 
-	payload, err := controlplane.EncodeNPMNetworkPolicies(toApplyPolicies)
-	if err != nil {
-		klog.Errorf("processPoliciesApply: failed to encode policies %v", err)
-		return nil, npmerrors.ErrorWrapper(npmerrors.AddPolicy, false, "processPoliciesApply: failed to encode sets", err)
-	}
-
-	return getGoalStateFromBuffer(payload), nil
-}
-
-func (dp *DPShim) processPoliciesRemove() (*protos.GoalState, error) {
-	if len(dp.dirtyCache.toDeletePolicies) == 0 {
-		return nil, nil
-	}
-
-	toDeletePolicies := make([]string, len(dp.dirtyCache.toDeletePolicies))
-	idx := 0
-
-	for policyKey := range dp.dirtyCache.toDeletePolicies {
-		toDeletePolicies[idx] = policyKey
-		idx++
-	}
-
-	payload, err := controlplane.EncodeStrings(toDeletePolicies)
-	if err != nil {
-		klog.Errorf("processPoliciesRemove: failed to encode policies %v", err)
-		return nil, npmerrors.ErrorWrapper(npmerrors.RemovePolicy, false, "processPoliciesRemove: failed to encode sets", err)
-	}
-
-	return getGoalStateFromBuffer(payload), nil
-}
-
-func (dp *DPShim) hydrateSetCache() (*protos.GoalState, error) {
-	if len(dp.setCache) == 0 {
-		return nil, nil
-	}
-	toApplySets := make([]*controlplane.ControllerIPSets, len(dp.setCache))
-	idx := 0
-
-	for _, set := range dp.setCache {
-		toApplySets[idx] = set
-		idx++
-	}
-
-	payload, err := controlplane.EncodeControllerIPSets(toApplySets)
-	if err != nil {
-		klog.Errorf("processIPSetsApply: failed to encode sets %v", err)
-		return nil, npmerrors.ErrorWrapper(npmerrors.AppendIPSet, false, "processIPSetsApply: failed to encode sets", err)
-	}
-
-	return getGoalStateFromBuffer(payload), nil
-}
-
-func (dp *DPShim) hydratePolicyCache() (*protos.GoalState, error) {
-	if len(dp.policyCache) == 0 {
-		return nil, nil
+	if len(toDeleteSets) > 0 {
+		payload, err := controlplane.EncodeStrings(toDeleteSets)
+		if err != nil {
+			klog.Errorf("processIPSetsDelete: failed to encode sets %v", err)
+			return nil, npmerrors.ErrorWrapper(npmerrors.DeleteIPSet, false, "processIPSetsDelete: failed to encode sets", err)
+		}
+		goalStates[controlplane.IpsetRemove] = getGoalStateFromBuffer(payload)
 	}
 
-	toApplyPolicies := make([]*policies.NPMNetworkPolicy, len(dp.policyCache))
-	idx := 0
-
-	for _, policy := range dp.policyCache {
-		toApplyPolicies[idx] = policy
-		idx++
+	if len(toApplyPolicies) > 0 {
+		payload, err := controlplane.EncodeNPMNetworkPolicies(toApplyPolicies)
+		if err != nil {
+			klog.Errorf("processPoliciesApply: failed to encode policies %v", err)
+			return nil, npmerrors.ErrorWrapper(npmerrors.AddPolicy, false, "processPoliciesApply: failed to encode sets", err)
+		}
+		goalStates[controlplane.PolicyApply] = getGoalStateFromBuffer(payload)
 	}
 
-	payload, err := controlplane.EncodeNPMNetworkPolicies(toApplyPolicies)
-	if err != nil {
-		klog.Errorf("processPoliciesApply: failed to encode policies %v", err)
-		return nil, npmerrors.ErrorWrapper(npmerrors.AddPolicy, false, "processPoliciesApply: failed to encode sets", err)
+	if len(toDeletePolicies) > 0 {
+		payload, err := controlplane.EncodeStrings(toDeletePolicies)
+		if err != nil {
+			klog.Errorf("processPoliciesRemove: failed to encode policies %v", err)
+			return nil, npmerrors.ErrorWrapper(npmerrors.RemovePolicy, false, "processPoliciesRemove: failed to encode sets", err)
+		}
+		goalStates[controlplane.PolicyRemove] = getGoalStateFromBuffer(payload)
 	}
 
-	return getGoalStateFromBuffer(payload), nil
+	return goalStates, nil
 }
 
 func (dp *DPShim) deleteUnusedSets(stopChannel <-chan struct{}) {
@@ -2,15 +2,16 @@ package dpshim
 
 import (
 	"bytes"
+	"os"
 	"reflect"
 	"testing"
 	"time"
 
+	"github.com/Azure/azure-container-networking/npm/metrics"
 	"github.com/Azure/azure-container-networking/npm/pkg/controlplane"
 	"github.com/Azure/azure-container-networking/npm/pkg/dataplane"
 	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
 	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/policies"
-	"github.com/Azure/azure-container-networking/npm/pkg/protos"
 	"github.com/Azure/azure-container-networking/npm/util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -20,6 +21,7 @@ const (
 	sleepAfterChanSent = time.Millisecond * 10
 	testSetName        = "test-set"
 	testListName       = "test-list"
+	testNodeName       = "test-node"
 )
 
 var (
@@ -80,6 +82,8 @@ var (
 func TestAddToList(t *testing.T) {
 	dp, err := NewDPSim(nil)
 	require.NoError(t, err)
+	_, err = dp.HydrateClients(v1Client(testNodeName))
+	require.NoError(t, err)
 
 	setMetadata := ipsets.NewIPSetMetadata(testSetName, ipsets.Namespace)
 	listMetadata := ipsets.NewIPSetMetadata(testListName, ipsets.KeyLabelOfNamespace)
@@ -98,7 +102,7 @@ func TestAddToList(t *testing.T) {
 	err = dp.ApplyDataPlane()
 	require.NoError(t, err)
 
-	payload := getPayload(t, dp.OutChannel, controlplane.IpsetApply)
+	payload := getPayload(t, dp.OutChannel, testNodeName, controlplane.IpsetApply)
 	sets, err := controlplane.DecodeControllerIPSets(payload)
 	require.NoError(t, err)
 	assert.Equal(t, 2, len(sets))
@@ -107,6 +111,8 @@ func TestAddToList(t *testing.T) {
 func TestRemoveFromList(t *testing.T) {
 	dp, err := NewDPSim(nil)
 	require.NoError(t, err)
+	_, err = dp.HydrateClients(v1Client(testNodeName))
+	require.NoError(t, err)
 
 	dp.CreateIPSets([]*ipsets.IPSetMetadata{testKeyPodSet, testNestedKeyPodSet})
 
@@ -123,7 +129,7 @@ func TestRemoveFromList(t *testing.T) {
 	err = dp.ApplyDataPlane()
 	require.NoError(t, err)
 
-	payload := getPayload(t, dp.OutChannel, controlplane.IpsetApply)
+	payload := getPayload(t, dp.OutChannel, testNodeName, controlplane.IpsetApply)
 	sets, err := controlplane.DecodeControllerIPSets(payload)
 	require.NoError(t, err)
 	assert.Equal(t, 2, len(sets))
@@ -138,7 +144,7 @@ func TestRemoveFromList(t *testing.T) {
 	err = dp.ApplyDataPlane()
 	require.NoError(t, err)
 
-	payload = getPayload(t, dp.OutChannel, controlplane.IpsetApply)
+	payload = getPayload(t, dp.OutChannel, testNodeName, controlplane.IpsetApply)
 	sets, err = controlplane.DecodeControllerIPSets(payload)
 	require.NoError(t, err)
 	assert.Equal(t, 1, len(sets))
@@ -148,6 +154,8 @@ func TestRemoveFromList(t *testing.T) {
 func TestAddToSets(t *testing.T) {
 	dp, err := NewDPSim(nil)
 	require.NoError(t, err)
+	_, err = dp.HydrateClients(v1Client(testNodeName))
+	require.NoError(t, err)
 
 	err = dp.AddToSets([]*ipsets.IPSetMetadata{
 		testKeyPodSet,
@@ -160,7 +168,7 @@ func TestAddToSets(t *testing.T) {
 	err = dp.ApplyDataPlane()
 	require.NoError(t, err)
 
-	payload := getPayload(t, dp.OutChannel, controlplane.IpsetApply)
+	payload := getPayload(t, dp.OutChannel, testNodeName, controlplane.IpsetApply)
 	sets, err := controlplane.DecodeControllerIPSets(payload)
 	require.NoError(t, err)
 	assert.Equal(t, 2, len(sets))
@@ -169,6 +177,8 @@ func TestAddToSets(t *testing.T) {
 func TestRemoveFromSet(t *testing.T) {
 	dp, err := NewDPSim(nil)
 	require.NoError(t, err)
+	_, err = dp.HydrateClients(v1Client(testNodeName))
+	require.NoError(t, err)
 
 	setMetadata := ipsets.NewIPSetMetadata(testSetName, ipsets.Namespace)
 	err = dp.AddToSets([]*ipsets.IPSetMetadata{setMetadata}, podMetadata)
@@ -177,7 +187,7 @@ func TestRemoveFromSet(t *testing.T) {
 	err = dp.ApplyDataPlane()
 	require.NoError(t, err)
 
-	payload := getPayload(t, dp.OutChannel, controlplane.IpsetApply)
+	payload := getPayload(t, dp.OutChannel, testNodeName, controlplane.IpsetApply)
 	sets, err := controlplane.DecodeControllerIPSets(payload)
 	require.NoError(t, err)
 	assert.Equal(t, 1, len(sets))
@@ -188,7 +198,7 @@ func TestRemoveFromSet(t *testing.T) {
 	err = dp.ApplyDataPlane()
 	require.Nil(t, err)
 
-	payload = getPayload(t, dp.OutChannel, controlplane.IpsetApply)
+	payload = getPayload(t, dp.OutChannel, testNodeName, controlplane.IpsetApply)
 	sets, err = controlplane.DecodeControllerIPSets(payload)
 	require.Nil(t, err)
 	require.Equal(t, 1, len(sets))
@@ -197,12 +207,14 @@ func TestRemoveFromSet(t *testing.T) {
 func TestPolicyUpdateEvent(t *testing.T) {
 	dp, err := NewDPSim(nil)
 	require.NoError(t, err)
+	_, err = dp.HydrateClients(v1Client(testNodeName))
+	require.NoError(t, err)
 
 	err = dp.UpdatePolicy(testPolicyobj)
 	require.NoError(t, err)
 	assert.True(t, dp.policyExists(testPolicyobj.PolicyKey))
 
-	payload := getPayload(t, dp.OutChannel, controlplane.PolicyApply)
+	payload := getPayload(t, dp.OutChannel, testNodeName, controlplane.PolicyApply)
 	netpols, err := controlplane.DecodeNPMNetworkPolicies(payload)
 	require.NoError(t, err)
 	assert.Equal(t, 1, len(netpols))
@@ -210,11 +222,13 @@ func TestPolicyUpdateEvent(t *testing.T) {
 	assert.True(t, reflect.DeepEqual(netpols[0], testPolicyobj))
 }
 
-func getPayload(t *testing.T, outChan chan *protos.Events, key string) *bytes.Buffer {
+func getPayload(t *testing.T, outChan chan NodeEvents, nodeName, key string) *bytes.Buffer {
 	time.Sleep(sleepAfterChanSent)
 	for {
 		select {
-		case event := <-outChan:
+		case events := <-outChan:
+			event, ok := events[nodeName]
+			require.True(t, ok, "no event for node %s", nodeName)
 			gs := event.GetPayload()
 
 			goalState, ok := gs[key]
@@ -225,3 +239,10 @@ func getPayload(t *testing.T, outChan chan *protos.Events, key string) *bytes.Bu
 		}
 	}
 }
+
+func TestMain(m *testing.M) {
+	metrics.InitializeAll()
+
+	exitCode := m.Run()
+	os.Exit(exitCode)
+}
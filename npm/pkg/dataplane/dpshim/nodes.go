@@ -0,0 +1,422 @@
+package dpshim
+
+import (
+	"github.com/Azure/azure-container-networking/npm/pkg/controlplane"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/policies"
+	"github.com/Azure/azure-container-networking/npm/pkg/protos"
+	"k8s.io/klog"
+)
+
+// This file contains the node-scoped filtering of goal states.
+// Each node only needs:
+// 1. policies whose pod selector matches one of its local pods.
+// 2. all members of the ipsets used in the rules of those policies (peers may be on any node).
+// 3. local members of all other ipsets, which are only used to select local pods.
+// Members without a node name (e.g. pods whose node is unknown) are sent to all nodes.
+// When only members of a hash set changed, nodes whose daemons all have API version V2 receive IPSetDeltas instead of the set.
+// Likewise, they receive PolicyDeltas instead of policies whose rules were the only change.
+
+// NodeEvents maps the name of each registered node to the events it should receive
+type NodeEvents map[string]*protos.Events
+
+// nodeView is what was last sent to a node
+type nodeView struct {
+	// numClients is the number of connected daemons on the node
+	numClients int
+	// numV1Clients is the number of connected daemons on the node which can't apply IPSetDeltas and PolicyDeltas
+	numV1Clients int
+	// hydrated is true if the node was hydrated since the last ApplyDataPlane.
+	// Hydration events already include the changes in the dirty cache.
+	hydrated bool
+	// policies holds the keys of policies relevant to the node
+	policies map[string]struct{}
+	// fullSets holds the names of sets sent with all members instead of just the node's local members
+	fullSets map[string]struct{}
+}
+
+// nodeIndex memoizes which IPs of a set belong to each node, and the deltas of dirty policies, while computing goal states
+type nodeIndex struct {
+	dp           *DPShim
+	setNodeIP    map[string]map[string]map[string]struct{}
+	policyDeltas map[string]*protos.PolicyDelta
+}
+
+func newNodeIndex(dp *DPShim) *nodeIndex {
+	return &nodeIndex{
+		dp:           dp,
+		setNodeIP:    make(map[string]map[string]map[string]struct{}),
+		policyDeltas: make(map[string]*protos.PolicyDelta),
+	}
+}
+
+// localIPs returns the IPs in the set (or the members of the list) which belong to the node.
+// The result includes members without a node name.
+func (idx *nodeIndex) localIPs(setName, nodeName string) map[string]struct{} {
+	nodeIPs, ok := idx.setNodeIP[setName]
+	if !ok {
+		nodeIPs = make(map[string]map[string]struct{})
+		idx.addIPsByNode(nodeIPs, setName)
+		idx.setNodeIP[setName] = nodeIPs
+	}
+
+	ips := make(map[string]struct{}, len(nodeIPs[nodeName])+len(nodeIPs[""]))
+	for ip := range nodeIPs[nodeName] {
+		ips[ip] = struct{}{}
+	}
+	for ip := range nodeIPs[""] {
+		ips[ip] = struct{}{}
+	}
+	return ips
+}
+
+func (idx *nodeIndex) addIPsByNode(nodeIPs map[string]map[string]struct{}, setName string) {
+	set := idx.dp.getCachedIPSet(setName)
+	if set == nil {
+		return
+	}
+
+	if set.GetSetKind() == ipsets.ListSet {
+		for memberName := range set.MemberIPSets {
+			idx.addIPsByNode(nodeIPs, memberName)
+		}
+		return
+	}
+
+	for ip, podMetadata := range set.IPPodMetadata {
+		ips, ok := nodeIPs[podMetadata.NodeName]
+		if !ok {
+			ips = make(map[string]struct{})
+			nodeIPs[podMetadata.NodeName] = ips
+		}
+		ips[ip] = struct{}{}
+	}
+}
+
+// isRelevant returns true if a local pod of the node may be selected by the policy.
+// Excluded sets are ignored, so a policy may be sent to a node which doesn't need it, but never the opposite.
+func (idx *nodeIndex) isRelevant(policy *policies.NPMNetworkPolicy, nodeName string) bool {
+	var candidateIPs map[string]struct{}
+	for _, setInfo := range policy.PodSelectorList {
+		if !setInfo.Included {
+			continue
+		}
+
+		localIPs := idx.localIPs(setInfo.IPSet.GetPrefixName(), nodeName)
+		if candidateIPs == nil {
+			candidateIPs = localIPs
+		} else {
+			for ip := range candidateIPs {
+				if _, ok := localIPs[ip]; !ok {
+					delete(candidateIPs, ip)
+				}
+			}
+		}
+
+		if len(candidateIPs) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// policyDelta returns the changes to the rules of the dirty policy since the last ApplyDataPlane.
+// It returns false if the policy was added or other fields changed, so it must be sent whole.
+func (idx *nodeIndex) policyDelta(policyKey string) (*protos.PolicyDelta, bool) {
+	delta, ok := idx.policyDeltas[policyKey]
+	if !ok {
+		if before := idx.dp.dirtyCache.policiesBefore[policyKey]; before != nil {
+			delta = controlplane.NewPolicyDelta(before, idx.dp.policyCache[policyKey])
+		}
+		idx.policyDeltas[policyKey] = delta
+	}
+	return delta, delta != nil
+}
+
+// canApplyDeltas returns true if IPSetDeltas and PolicyDeltas can be sent to the node instead of sets and policies.
+// Deltas aren't sent after a hydration, since daemons update set digests with them and can't apply a change twice.
+func (v *nodeView) canApplyDeltas() bool {
+	return v.numV1Clients == 0 && !v.hydrated
+}
+
+// newNodeView computes the policies and full sets for the node
+func (dp *DPShim) newNodeView(idx *nodeIndex, nodeName string, numClients, numV1Clients int) *nodeView {
+	view := &nodeView{
+		numClients:   numClients,
+		numV1Clients: numV1Clients,
+		policies:     make(map[string]struct{}),
+		fullSets:     make(map[string]struct{}),
+	}
+	for policyKey, policy := range dp.policyCache {
+		if !idx.isRelevant(policy, nodeName) {
+			continue
+		}
+
+		view.policies[policyKey] = struct{}{}
+		for _, ruleSet := range policy.RuleIPSets {
+			dp.addFullSet(view.fullSets, ruleSet.Metadata.GetPrefixName())
+		}
+	}
+	return view
+}
+
+// addFullSet adds the set and the members of a list to fullSets
+func (dp *DPShim) addFullSet(fullSets map[string]struct{}, setName string) {
+	set := dp.getCachedIPSet(setName)
+	if set == nil {
+		return
+	}
+
+	fullSets[setName] = struct{}{}
+	for memberName := range set.MemberIPSets {
+		fullSets[memberName] = struct{}{}
+	}
+}
+
+// registerNode starts tracking a node for a newly connected daemon and returns the node's view
+func (dp *DPShim) registerNode(client *protos.DatapathPodMetadata) *nodeView {
+	nodeName := client.GetNodeName()
+	numClients, numV1Clients := 0, 0
+	if oldView, ok := dp.nodes[nodeName]; ok {
+		numClients, numV1Clients = oldView.numClients, oldView.numV1Clients
+	}
+	numClients++
+	if !supportsDeltas(client) {
+		numV1Clients++
+	}
+
+	view := dp.newNodeView(newNodeIndex(dp), nodeName, numClients, numV1Clients)
+	view.hydrated = true
+	dp.nodes[nodeName] = view
+	return view
+}
+
+// RemoveClient stops tracking the node of a disconnected daemon once no daemons on the node are connected
+func (dp *DPShim) RemoveClient(client *protos.DatapathPodMetadata) {
+	dp.lock()
+	defer dp.unlock()
+
+	nodeName := client.GetNodeName()
+	view, ok := dp.nodes[nodeName]
+	if !ok {
+		return
+	}
+
+	view.numClients--
+	if !supportsDeltas(client) {
+		view.numV1Clients--
+	}
+	if view.numClients <= 0 {
+		klog.Infof("RemoveClient: no more clients on node %s", nodeName)
+		delete(dp.nodes, nodeName)
+	}
+}
+
+func supportsDeltas(client *protos.DatapathPodMetadata) bool {
+	return client.GetApiVersion() >= protos.DatapathPodMetadata_V2
+}
+
+// filteredSetForNode returns the set with only the members needed on the node
+func (dp *DPShim) filteredSetForNode(set *controlplane.ControllerIPSets, view *nodeView, nodeName string) *controlplane.ControllerIPSets {
+	if _, ok := view.fullSets[set.GetPrefixName()]; ok || set.GetSetKind() != ipsets.HashSet {
+		return set
+	}
+
+	filteredSet := controlplane.NewControllerIPSets(set.IPSetMetadata)
+	filteredSet.MemberIPSets = set.MemberIPSets
+	filteredSet.NetPolReference = set.NetPolReference
+	for ip, podMetadata := range set.IPPodMetadata {
+		if podMetadata.NodeName == nodeName || podMetadata.NodeName == "" {
+			filteredSet.IPPodMetadata[ip] = podMetadata
+		}
+	}
+	return filteredSet
+}
+
+// nodeGoalStates computes the goal states for a node from the dirty cache and the node's new view
+func (dp *DPShim) nodeGoalStates(idx *nodeIndex, nodeName string, oldView *nodeView) (*nodeView, map[string]*protos.GoalState, error) {
+	view := dp.newNodeView(idx, nodeName, oldView.numClients, oldView.numV1Clients)
+
+	toApplySets := make([]*controlplane.ControllerIPSets, 0)
+	toApplyDeltas := make([]*protos.IPSetDelta, 0)
+	for setName := range dp.dirtyCache.toAddorUpdateSets {
+		_, isFull := view.fullSets[setName]
+		if !isFull && !dp.dirtyCache.setChangedForNode(setName, nodeName) {
+			// may still need to be sent below if it used to be a full set
+			continue
+		}
+
+		set := dp.getCachedIPSet(setName)
+		if set == nil {
+			return nil, nil, errSetNotFound(setName)
+		}
+
+		_, wasFull := oldView.fullSets[setName]
+		if isFull == wasFull && oldView.canApplyDeltas() && dp.dirtyCache.onlyMembersChanged(setName) {
+			if delta := dp.setDeltaForNode(set, view, nodeName); delta != nil {
+				toApplyDeltas = append(toApplyDeltas, delta)
+			}
+			continue
+		}
+		toApplySets = append(toApplySets, dp.filteredSetForNode(set, view, nodeName))
+	}
+
+	// sets which started or stopped being full sets
+	for setName := range view.fullSets {
+		_, wasFull := oldView.fullSets[setName]
+		_, isDirty := dp.dirtyCache.toAddorUpdateSets[setName]
+		if !wasFull && !isDirty {
+			toApplySets = append(toApplySets, dp.getCachedIPSet(setName))
+		}
+	}
+	for setName := range oldView.fullSets {
+		_, isFull := view.fullSets[setName]
+		_, isDeleted := dp.dirtyCache.toDeleteSets[setName]
+		set := dp.getCachedIPSet(setName)
+		if isFull || isDeleted || set == nil {
+			continue
+		}
+		if dp.dirtyCache.setChangedForNode(setName, nodeName) {
+			// already added above
+			continue
+		}
+		toApplySets = append(toApplySets, dp.filteredSetForNode(set, view, nodeName))
+	}
+
+	toApplyPolicies := make([]*policies.NPMNetworkPolicy, 0)
+	toApplyPolicyDeltas := make([]*protos.PolicyDelta, 0)
+	for policyKey := range view.policies {
+		_, isDirty := dp.dirtyCache.toAddorUpdatePolicies[policyKey]
+		_, wasSent := oldView.policies[policyKey]
+		if !isDirty && wasSent {
+			continue
+		}
+
+		// the node has the policy as it was before the changes in the dirty cache
+		if isDirty && wasSent && oldView.canApplyDeltas() {
+			if delta, ok := idx.policyDelta(policyKey); ok {
+				if len(delta.GetRemoved()) > 0 || len(delta.GetAdded()) > 0 {
+					toApplyPolicyDeltas = append(toApplyPolicyDeltas, delta)
+				}
+				continue
+			}
+		}
+		toApplyPolicies = append(toApplyPolicies, dp.policyCache[policyKey])
+	}
+
+	toDeletePolicies := make([]string, 0)
+	for policyKey := range oldView.policies {
+		if _, ok := view.policies[policyKey]; !ok {
+			toDeletePolicies = append(toDeletePolicies, policyKey)
+		}
+	}
+
+	toDeleteSets := make([]string, 0, len(dp.dirtyCache.toDeleteSets))
+	for setName := range dp.dirtyCache.toDeleteSets {
+		toDeleteSets = append(toDeleteSets, setName)
+	}
+
+	goalStates, err := encodeGoalStates(toApplySets, toDeleteSets, toApplyPolicies, toDeletePolicies)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(toApplyDeltas) > 0 {
+		goalStates[controlplane.IpsetDelta] = &protos.GoalState{
+			IpsetDeltas: toApplyDeltas,
+		}
+	}
+	if len(toApplyPolicyDeltas) > 0 {
+		goalStates[controlplane.PolicyDelta] = &protos.GoalState{
+			PolicyDeltas: toApplyPolicyDeltas,
+		}
+	}
+	return view, goalStates, nil
+}
+
+// setDeltaForNode returns the changes to the members of the hash set which are needed on the node.
+// It returns nil if there are none.
+func (dp *DPShim) setDeltaForNode(set *controlplane.ControllerIPSets, view *nodeView, nodeName string) *protos.IPSetDelta {
+	setName := set.GetPrefixName()
+	_, isFull := view.fullSets[setName]
+	isNeeded := func(podMetadata *dataplane.PodMetadata) bool {
+		return podMetadata != nil && (isFull || podMetadata.NodeName == nodeName || podMetadata.NodeName == "")
+	}
+
+	delta := &protos.IPSetDelta{
+		Name: set.Name,
+		Type: int32(set.Type),
+	}
+	for ip, oldPodMetadata := range dp.dirtyCache.setMembersBefore[setName] {
+		newPodMetadata := set.IPPodMetadata[ip]
+		if oldPodMetadata != nil && newPodMetadata != nil && *oldPodMetadata == *newPodMetadata {
+			continue
+		}
+		// a member whose pod or node changed is removed and added again, so the daemon can update the set's digest
+		if isNeeded(oldPodMetadata) {
+			delta.Removed = append(delta.Removed, controlplane.NewIPSetMember(oldPodMetadata))
+		}
+		if isNeeded(newPodMetadata) {
+			delta.Added = append(delta.Added, controlplane.NewIPSetMember(newPodMetadata))
+		}
+	}
+
+	if len(delta.Added) == 0 && len(delta.Removed) == 0 {
+		return nil
+	}
+	return delta
+}
+
+// hydrationForNode returns every set filtered for the node and the node's relevant policies
+func (dp *DPShim) hydrationForNode(view *nodeView, nodeName string) (map[string]*protos.GoalState, error) {
+	toApplySets := make([]*controlplane.ControllerIPSets, 0, len(dp.setCache))
+	for _, set := range dp.setCache {
+		toApplySets = append(toApplySets, dp.filteredSetForNode(set, view, nodeName))
+	}
+
+	toApplyPolicies := make([]*policies.NPMNetworkPolicy, 0, len(view.policies))
+	for policyKey := range view.policies {
+		toApplyPolicies = append(toApplyPolicies, dp.policyCache[policyKey])
+	}
+
+	return encodeGoalStates(toApplySets, nil, toApplyPolicies, nil)
+}
+
+// diffForNode returns the sets and policies which differ from the digests of what a reconnecting daemon applied
+func (dp *DPShim) diffForNode(view *nodeView, nodeName string, ipsetDigests, policyDigests map[string]string) (map[string]*protos.GoalState, error) {
+	toApplySets := make([]*controlplane.ControllerIPSets, 0)
+	for setName, set := range dp.setCache {
+		filteredSet := dp.filteredSetForNode(set, view, nodeName)
+		if digest, ok := ipsetDigests[setName]; !ok || digest != controlplane.SetDigest(filteredSet) {
+			toApplySets = append(toApplySets, filteredSet)
+		}
+	}
+
+	toDeleteSets := make([]string, 0)
+	for setName := range ipsetDigests {
+		if !dp.setExists(setName) {
+			toDeleteSets = append(toDeleteSets, setName)
+		}
+	}
+
+	toApplyPolicies := make([]*policies.NPMNetworkPolicy, 0)
+	for policyKey := range view.policies {
+		policy := dp.policyCache[policyKey]
+		policyDigest, err := controlplane.PolicyDigest(policy)
+		if err != nil {
+			return nil, err
+		}
+		if digest, ok := policyDigests[policyKey]; !ok || digest != policyDigest {
+			toApplyPolicies = append(toApplyPolicies, policy)
+		}
+	}
+
+	toDeletePolicies := make([]string, 0)
+	for policyKey := range policyDigests {
+		if _, ok := view.policies[policyKey]; !ok {
+			toDeletePolicies = append(toDeletePolicies, policyKey)
+		}
+	}
+
+	return encodeGoalStates(toApplySets, toDeleteSets, toApplyPolicies, toDeletePolicies)
+}
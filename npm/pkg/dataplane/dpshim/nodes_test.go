@@ -0,0 +1,528 @@
+package dpshim
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-container-networking/npm/pkg/controlplane"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/policies"
+	"github.com/Azure/azure-container-networking/npm/pkg/protos"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	nodeA = "node-a"
+	nodeB = "node-b"
+)
+
+var (
+	selectorSet      = ipsets.NewIPSetMetadata("app:web", ipsets.KeyValueLabelOfPod)
+	otherSelectorSet = ipsets.NewIPSetMetadata("app:db", ipsets.KeyValueLabelOfPod)
+	peerSet          = ipsets.NewIPSetMetadata("role:client", ipsets.KeyValueLabelOfPod)
+
+	podA1 = dataplane.NewPodMetadata("x/a1", "10.0.0.1", nodeA)
+	podA2 = dataplane.NewPodMetadata("x/a2", "10.0.0.2", nodeA)
+	podB1 = dataplane.NewPodMetadata("x/b1", "10.0.1.1", nodeB)
+	podB2 = dataplane.NewPodMetadata("x/b2", "10.0.1.2", nodeB)
+)
+
+// policy selecting pods in the selector set and allowing ingress from pods in the peer set
+func nodeTestPolicy(policyKey string, selector *ipsets.IPSetMetadata) *policies.NPMNetworkPolicy {
+	return &policies.NPMNetworkPolicy{
+		Namespace:         "x",
+		PolicyKey:         policyKey,
+		PodSelectorIPSets: []*ipsets.TranslatedIPSet{{Metadata: selector}},
+		PodSelectorList: []policies.SetInfo{
+			{IPSet: selector, Included: true, MatchType: policies.EitherMatch},
+		},
+		RuleIPSets: []*ipsets.TranslatedIPSet{{Metadata: peerSet}},
+		ACLs: []*policies.ACLPolicy{
+			{
+				Target:    policies.Allowed,
+				Direction: policies.Ingress,
+				SrcList:   []policies.SetInfo{{IPSet: peerSet, Included: true, MatchType: policies.SrcMatch}},
+			},
+		},
+	}
+}
+
+// v1Client is a daemon on the node which can't apply IPSetDeltas
+func v1Client(nodeName string) *protos.DatapathPodMetadata {
+	return &protos.DatapathPodMetadata{NodeName: nodeName}
+}
+
+// v2Client is a daemon on the node which can apply IPSetDeltas
+func v2Client(nodeName string) *protos.DatapathPodMetadata {
+	return &protos.DatapathPodMetadata{NodeName: nodeName, ApiVersion: protos.DatapathPodMetadata_V2}
+}
+
+// decodedEvent is the decoded payload of an event
+type decodedEvent struct {
+	// sets maps set names to their sorted members
+	sets             map[string][]string
+	deletedSets      []string
+	policies         []string
+	deletedPolicies  []string
+	isHydrationEvent bool
+	// deltas maps set names to their sorted removed and added members
+	deltas map[string]decodedDelta
+}
+
+type decodedDelta struct {
+	removed []string
+	added   []string
+}
+
+func decodeEvent(t *testing.T, event *protos.Events) *decodedEvent {
+	require.NotNil(t, event)
+	decoded := &decodedEvent{
+		sets:             make(map[string][]string),
+		isHydrationEvent: event.GetEventType() == protos.Events_Hydration,
+		deltas:           make(map[string]decodedDelta),
+	}
+	payload := event.GetPayload()
+	if goalState, ok := payload[controlplane.IpsetApply]; ok {
+		sets, err := controlplane.DecodeControllerIPSets(bytes.NewBuffer(goalState.GetData()))
+		require.NoError(t, err)
+		for _, set := range sets {
+			members := make([]string, 0)
+			for ip := range set.IPPodMetadata {
+				members = append(members, ip)
+			}
+			for memberName := range set.MemberIPSets {
+				members = append(members, memberName)
+			}
+			sort.Strings(members)
+			decoded.sets[set.GetPrefixName()] = members
+		}
+	}
+	if goalState, ok := payload[controlplane.IpsetDelta]; ok {
+		for _, delta := range goalState.GetIpsetDeltas() {
+			decoded.deltas[controlplane.IPSetDeltaMetadata(delta).GetPrefixName()] = decodedDelta{
+				removed: memberIPs(delta.GetRemoved()),
+				added:   memberIPs(delta.GetAdded()),
+			}
+		}
+	}
+	if goalState, ok := payload[controlplane.IpsetRemove]; ok {
+		names, err := controlplane.DecodeStrings(bytes.NewBuffer(goalState.GetData()))
+		require.NoError(t, err)
+		decoded.deletedSets = names
+	}
+	if goalState, ok := payload[controlplane.PolicyApply]; ok {
+		netpols, err := controlplane.DecodeNPMNetworkPolicies(bytes.NewBuffer(goalState.GetData()))
+		require.NoError(t, err)
+		for _, netpol := range netpols {
+			decoded.policies = append(decoded.policies, netpol.PolicyKey)
+		}
+		sort.Strings(decoded.policies)
+	}
+	if goalState, ok := payload[controlplane.PolicyRemove]; ok {
+		keys, err := controlplane.DecodeStrings(bytes.NewBuffer(goalState.GetData()))
+		require.NoError(t, err)
+		decoded.deletedPolicies = keys
+	}
+	return decoded
+}
+
+func memberIPs(members []*protos.IPSetMember) []string {
+	ips := make([]string, 0, len(members))
+	for _, member := range members {
+		ips = append(ips, member.GetIp())
+	}
+	sort.Strings(ips)
+	return ips
+}
+
+func nextEvents(t *testing.T, dp *DPShim) NodeEvents {
+	select {
+	case events := <-dp.OutChannel:
+		return events
+	case <-time.After(time.Second):
+		require.FailNow(t, "no events sent")
+		return nil
+	}
+}
+
+func requireNoEvents(t *testing.T, dp *DPShim) {
+	select {
+	case events := <-dp.OutChannel:
+		require.FailNow(t, "unexpected events", "%+v", events)
+	case <-time.After(sleepAfterChanSent):
+	}
+}
+
+// newNodeTestDPShim creates a DPShim with pods on two nodes and a policy that only selects pods on node A
+func newNodeTestDPShim(t *testing.T) *DPShim {
+	dp, err := NewDPSim(nil)
+	require.NoError(t, err)
+	require.NoError(t, dp.AddToSets([]*ipsets.IPSetMetadata{selectorSet}, podA1))
+	require.NoError(t, dp.AddToSets([]*ipsets.IPSetMetadata{selectorSet, peerSet}, podB1))
+	require.NoError(t, dp.AddToSets([]*ipsets.IPSetMetadata{otherSelectorSet}, podB2))
+	// no registered nodes yet
+	require.NoError(t, dp.UpdatePolicy(nodeTestPolicy("x/web", selectorSet)))
+	require.NoError(t, dp.UpdatePolicy(nodeTestPolicy("x/db", otherSelectorSet)))
+	requireNoEvents(t, dp)
+	return dp
+}
+
+func TestHydrateClientsForNode(t *testing.T) {
+	dp := newNodeTestDPShim(t)
+
+	event, err := dp.HydrateClients(v1Client(nodeA))
+	require.NoError(t, err)
+	decoded := decodeEvent(t, event)
+	require.True(t, decoded.isHydrationEvent)
+	require.Equal(t, []string{"x/web"}, decoded.policies)
+	require.Equal(t, map[string][]string{
+		selectorSet.GetPrefixName(): {podA1.PodIP},
+		// the peer set is used in a rule of x/web, so it has members on other nodes
+		peerSet.GetPrefixName():          {podB1.PodIP},
+		otherSelectorSet.GetPrefixName(): {},
+	}, decoded.sets)
+
+	event, err = dp.HydrateClients(v1Client(nodeB))
+	require.NoError(t, err)
+	decoded = decodeEvent(t, event)
+	require.Equal(t, []string{"x/db", "x/web"}, decoded.policies)
+	require.Equal(t, map[string][]string{
+		selectorSet.GetPrefixName():      {podB1.PodIP},
+		peerSet.GetPrefixName():          {podB1.PodIP},
+		otherSelectorSet.GetPrefixName(): {podB2.PodIP},
+	}, decoded.sets)
+}
+
+func TestApplyDataPlaneForNodes(t *testing.T) {
+	dp := newNodeTestDPShim(t)
+	_, err := dp.HydrateClients(v1Client(nodeA))
+	require.NoError(t, err)
+	_, err = dp.HydrateClients(v1Client(nodeB))
+	require.NoError(t, err)
+
+	// a new pod on node B is only sent to node B
+	podB3 := dataplane.NewPodMetadata("x/b3", "10.0.1.3", nodeB)
+	require.NoError(t, dp.AddToSets([]*ipsets.IPSetMetadata{selectorSet}, podB3))
+	require.NoError(t, dp.ApplyDataPlane())
+	events := nextEvents(t, dp)
+	require.Len(t, events, 1)
+	decoded := decodeEvent(t, events[nodeB])
+	require.Equal(t, map[string][]string{selectorSet.GetPrefixName(): {podB1.PodIP, podB3.PodIP}}, decoded.sets)
+
+	// a new peer is sent to both nodes since both have x/web
+	require.NoError(t, dp.AddToSets([]*ipsets.IPSetMetadata{peerSet}, podA2))
+	require.NoError(t, dp.ApplyDataPlane())
+	events = nextEvents(t, dp)
+	require.Len(t, events, 2)
+	for _, nodeName := range []string{nodeA, nodeB} {
+		decoded = decodeEvent(t, events[nodeName])
+		require.Equal(t, map[string][]string{peerSet.GetPrefixName(): {podA2.PodIP, podB1.PodIP}}, decoded.sets, "node %s", nodeName)
+		require.Empty(t, decoded.policies)
+	}
+
+	// x/db becomes relevant to node A
+	require.NoError(t, dp.AddToSets([]*ipsets.IPSetMetadata{otherSelectorSet}, podA2))
+	require.NoError(t, dp.ApplyDataPlane())
+	events = nextEvents(t, dp)
+	require.Len(t, events, 1)
+	decoded = decodeEvent(t, events[nodeA])
+	require.Equal(t, []string{"x/db"}, decoded.policies)
+	require.Equal(t, map[string][]string{otherSelectorSet.GetPrefixName(): {podA2.PodIP}}, decoded.sets)
+
+	// x/db stops being relevant to node A
+	require.NoError(t, dp.RemoveFromSets([]*ipsets.IPSetMetadata{otherSelectorSet}, podA2))
+	require.NoError(t, dp.ApplyDataPlane())
+	events = nextEvents(t, dp)
+	require.Len(t, events, 1)
+	decoded = decodeEvent(t, events[nodeA])
+	require.Equal(t, []string{"x/db"}, decoded.deletedPolicies)
+	require.Equal(t, map[string][]string{otherSelectorSet.GetPrefixName(): {}}, decoded.sets)
+
+	// removing x/web from node A makes the peer set local to node A
+	require.NoError(t, dp.RemoveFromSets([]*ipsets.IPSetMetadata{selectorSet}, podA1))
+	require.NoError(t, dp.ApplyDataPlane())
+	events = nextEvents(t, dp)
+	require.Len(t, events, 1)
+	decoded = decodeEvent(t, events[nodeA])
+	require.Equal(t, []string{"x/web"}, decoded.deletedPolicies)
+	require.Equal(t, map[string][]string{
+		selectorSet.GetPrefixName(): {},
+		peerSet.GetPrefixName():     {podA2.PodIP},
+	}, decoded.sets)
+}
+
+func TestApplyDataPlaneSendsEventsInOrder(t *testing.T) {
+	dp := newNodeTestDPShim(t)
+	_, err := dp.HydrateClients(v1Client(nodeB))
+	require.NoError(t, err)
+
+	// applies don't wait for the events to be received
+	numApplies := 20
+	for i := 0; i < numApplies; i++ {
+		pod := dataplane.NewPodMetadata(fmt.Sprintf("x/b-%d", i), fmt.Sprintf("10.0.2.%d", i), nodeB)
+		require.NoError(t, dp.AddToSets([]*ipsets.IPSetMetadata{selectorSet}, pod))
+		require.NoError(t, dp.ApplyDataPlane())
+	}
+
+	generation := dp.Generation() - uint64(numApplies)
+	for i := 0; i < numApplies; i++ {
+		events := nextEvents(t, dp)
+		generation++
+		require.Equal(t, generation, events[nodeB].GetGeneration())
+	}
+	requireNoEvents(t, dp)
+}
+
+func TestRemoveClient(t *testing.T) {
+	dp := newNodeTestDPShim(t)
+	_, err := dp.HydrateClients(v1Client(nodeA))
+	require.NoError(t, err)
+	_, err = dp.HydrateClients(v1Client(nodeA))
+	require.NoError(t, err)
+
+	// one daemon on the node is still connected
+	dp.RemoveClient(v1Client(nodeA))
+	require.NoError(t, dp.AddToSets([]*ipsets.IPSetMetadata{selectorSet}, podA2))
+	require.NoError(t, dp.ApplyDataPlane())
+	events := nextEvents(t, dp)
+	require.Contains(t, events, nodeA)
+
+	dp.RemoveClient(v1Client(nodeA))
+	require.NoError(t, dp.RemoveFromSets([]*ipsets.IPSetMetadata{selectorSet}, podA2))
+	require.NoError(t, dp.ApplyDataPlane())
+	requireNoEvents(t, dp)
+}
+
+func TestGenerations(t *testing.T) {
+	dp := newNodeTestDPShim(t)
+	// policy updates are applied before any node registers
+	base := dp.Generation()
+	require.NotZero(t, base)
+
+	event, err := dp.HydrateClients(v1Client(nodeA))
+	require.NoError(t, err)
+	require.Equal(t, base, event.GetGeneration())
+
+	// a change for node B still increases the generation
+	podB3 := dataplane.NewPodMetadata("x/b3", "10.0.1.3", nodeB)
+	require.NoError(t, dp.AddToSets([]*ipsets.IPSetMetadata{otherSelectorSet}, podB3))
+	require.NoError(t, dp.ApplyDataPlane())
+	requireNoEvents(t, dp)
+	require.Equal(t, base+1, dp.Generation())
+
+	require.NoError(t, dp.AddToSets([]*ipsets.IPSetMetadata{selectorSet}, podA2))
+	require.NoError(t, dp.ApplyDataPlane())
+	events := nextEvents(t, dp)
+	require.Equal(t, base+2, events[nodeA].GetGeneration())
+
+	// no changes
+	require.NoError(t, dp.ApplyDataPlane())
+	require.Equal(t, base+2, dp.Generation())
+}
+
+func TestRehydrateNode(t *testing.T) {
+	dp := newNodeTestDPShim(t)
+
+	event, err := dp.RehydrateNode(nodeA)
+	require.NoError(t, err)
+	require.Nil(t, event, "node A has no clients")
+
+	_, err = dp.HydrateClients(v1Client(nodeA))
+	require.NoError(t, err)
+	require.NoError(t, dp.AddToSets([]*ipsets.IPSetMetadata{selectorSet}, podA2))
+	require.NoError(t, dp.ApplyDataPlane())
+	nextEvents(t, dp)
+
+	event, err = dp.RehydrateNode(nodeA)
+	require.NoError(t, err)
+	require.Equal(t, dp.Generation(), event.GetGeneration())
+	decoded := decodeEvent(t, event)
+	require.True(t, decoded.isHydrationEvent)
+	require.Equal(t, []string{"x/web"}, decoded.policies)
+	require.Equal(t, []string{podA1.PodIP, podA2.PodIP}, decoded.sets[selectorSet.GetPrefixName()])
+
+	// rehydrating doesn't add a client
+	dp.RemoveClient(v1Client(nodeA))
+	require.NoError(t, dp.RemoveFromSets([]*ipsets.IPSetMetadata{selectorSet}, podA2))
+	require.NoError(t, dp.ApplyDataPlane())
+	requireNoEvents(t, dp)
+}
+
+func TestHydrateClientsWithDigests(t *testing.T) {
+	// digests of what a daemon on node A applied from a previous leader
+	oldLeader := newNodeTestDPShim(t)
+	event, err := oldLeader.HydrateClients(v1Client(nodeA))
+	require.NoError(t, err)
+	ipsetDigests, policyDigests := eventDigests(t, event)
+
+	dp := newNodeTestDPShim(t)
+	event, err = dp.HydrateClients(&protos.DatapathPodMetadata{NodeName: nodeA, IpsetDigests: ipsetDigests, PolicyDigests: policyDigests})
+	require.NoError(t, err)
+	require.Nil(t, event, "the daemon is up to date")
+	dp.RemoveClient(v1Client(nodeA))
+
+	// the new leader has a new member, a new set, and a deleted policy
+	newSet := ipsets.NewIPSetMetadata("app:new", ipsets.KeyValueLabelOfPod)
+	require.NoError(t, dp.AddToSets([]*ipsets.IPSetMetadata{selectorSet}, podA2))
+	require.NoError(t, dp.AddToSets([]*ipsets.IPSetMetadata{newSet}, podB2))
+	require.NoError(t, dp.RemovePolicy("x/web"))
+	// a policy only the daemon knows about
+	policyDigests["x/stale"] = "digest"
+
+	event, err = dp.HydrateClients(&protos.DatapathPodMetadata{NodeName: nodeA, IpsetDigests: ipsetDigests, PolicyDigests: policyDigests})
+	require.NoError(t, err)
+	decoded := decodeEvent(t, event)
+	require.False(t, decoded.isHydrationEvent)
+	require.Equal(t, event.GetGeneration(), dp.Generation())
+	require.Equal(t, map[string][]string{
+		selectorSet.GetPrefixName(): {podA1.PodIP, podA2.PodIP},
+		// the peer set is no longer used in a rule of a policy on node A
+		peerSet.GetPrefixName(): {},
+		newSet.GetPrefixName():  {},
+	}, decoded.sets)
+	require.Empty(t, decoded.policies)
+	sort.Strings(decoded.deletedPolicies)
+	require.Equal(t, []string{"x/stale", "x/web"}, decoded.deletedPolicies)
+}
+
+func eventDigests(t *testing.T, event *protos.Events) (ipsetDigests, policyDigests map[string]string) {
+	ipsetDigests = make(map[string]string)
+	policyDigests = make(map[string]string)
+	payload := event.GetPayload()
+	sets, err := controlplane.DecodeControllerIPSets(bytes.NewBuffer(payload[controlplane.IpsetApply].GetData()))
+	require.NoError(t, err)
+	for _, set := range sets {
+		ipsetDigests[set.GetPrefixName()] = controlplane.SetDigest(set)
+	}
+	netpols, err := controlplane.DecodeNPMNetworkPolicies(bytes.NewBuffer(payload[controlplane.PolicyApply].GetData()))
+	require.NoError(t, err)
+	for _, netpol := range netpols {
+		policyDigests[netpol.PolicyKey], err = controlplane.PolicyDigest(netpol)
+		require.NoError(t, err)
+	}
+	return ipsetDigests, policyDigests
+}
+
+func TestIPSetDeltas(t *testing.T) {
+	dp := newNodeTestDPShim(t)
+	_, err := dp.HydrateClients(v2Client(nodeA))
+	require.NoError(t, err)
+	_, err = dp.HydrateClients(v1Client(nodeB))
+	require.NoError(t, err)
+
+	// the first changes after a hydration are sent as sets
+	require.NoError(t, dp.AddToSets([]*ipsets.IPSetMetadata{selectorSet}, podA2))
+	require.NoError(t, dp.ApplyDataPlane())
+	events := nextEvents(t, dp)
+	decoded := decodeEvent(t, events[nodeA])
+	require.Equal(t, map[string][]string{selectorSet.GetPrefixName(): {podA1.PodIP, podA2.PodIP}}, decoded.sets)
+	require.Empty(t, decoded.deltas)
+
+	// node A receives deltas for its local members and all members of the peer set
+	require.NoError(t, dp.RemoveFromSets([]*ipsets.IPSetMetadata{selectorSet}, podA2))
+	require.NoError(t, dp.AddToSets([]*ipsets.IPSetMetadata{peerSet}, podA2))
+	require.NoError(t, dp.ApplyDataPlane())
+	events = nextEvents(t, dp)
+	decoded = decodeEvent(t, events[nodeA])
+	require.Empty(t, decoded.sets)
+	require.Equal(t, map[string]decodedDelta{
+		selectorSet.GetPrefixName(): {removed: []string{podA2.PodIP}, added: []string{}},
+		peerSet.GetPrefixName():     {removed: []string{}, added: []string{podA2.PodIP}},
+	}, decoded.deltas)
+
+	// node B can't apply deltas, and also has the policy since pod B1 is selected
+	decoded = decodeEvent(t, events[nodeB])
+	require.Empty(t, decoded.deltas)
+	require.Equal(t, map[string][]string{peerSet.GetPrefixName(): {podA2.PodIP, podB1.PodIP}}, decoded.sets)
+
+	// a pod on node B reuses the IP
+	podB3 := dataplane.NewPodMetadata("x/b3", podA2.PodIP, nodeB)
+	require.NoError(t, dp.AddToSets([]*ipsets.IPSetMetadata{peerSet}, podB3))
+	require.NoError(t, dp.ApplyDataPlane())
+	events = nextEvents(t, dp)
+	decoded = decodeEvent(t, events[nodeA])
+	require.Equal(t, map[string]decodedDelta{
+		peerSet.GetPrefixName(): {removed: []string{podA2.PodIP}, added: []string{podB3.PodIP}},
+	}, decoded.deltas)
+	added := events[nodeA].GetPayload()[controlplane.IpsetDelta].GetIpsetDeltas()[0].GetAdded()[0]
+	require.Equal(t, podB3, controlplane.PodMetadataFromIPSetMember(added))
+
+	decoded = decodeEvent(t, events[nodeB])
+	require.Equal(t, map[string][]string{peerSet.GetPrefixName(): {podB3.PodIP, podB1.PodIP}}, decoded.sets)
+
+	// changes which cancel out aren't sent
+	require.NoError(t, dp.RemoveFromSets([]*ipsets.IPSetMetadata{selectorSet}, podA1))
+	require.NoError(t, dp.AddToSets([]*ipsets.IPSetMetadata{selectorSet}, podA1))
+	require.NoError(t, dp.ApplyDataPlane())
+	requireNoEvents(t, dp)
+}
+
+func TestUpdateUnchangedPolicy(t *testing.T) {
+	dp := newNodeTestDPShim(t)
+	_, err := dp.HydrateClients(v2Client(nodeA))
+	require.NoError(t, err)
+	generation := dp.Generation()
+
+	require.NoError(t, dp.UpdatePolicy(nodeTestPolicy("x/web", selectorSet)))
+	requireNoEvents(t, dp)
+	require.Equal(t, generation, dp.Generation())
+
+	policy := nodeTestPolicy("x/web", selectorSet)
+	policy.ACLs[0].Target = policies.Dropped
+	require.NoError(t, dp.UpdatePolicy(policy))
+	decoded := decodeEvent(t, nextEvents(t, dp)[nodeA])
+	require.Equal(t, []string{"x/web"}, decoded.policies)
+}
+
+func TestPolicyDeltas(t *testing.T) {
+	dp := newNodeTestDPShim(t)
+	_, err := dp.HydrateClients(v2Client(nodeA))
+	require.NoError(t, err)
+	_, err = dp.HydrateClients(v1Client(nodeB))
+	require.NoError(t, err)
+
+	// the first changes after a hydration are sent as policies
+	policy := nodeTestPolicy("x/web", selectorSet)
+	policy.ACLs = append(policy.ACLs, &policies.ACLPolicy{Target: policies.Dropped, Direction: policies.Ingress})
+	require.NoError(t, dp.UpdatePolicy(policy))
+	events := nextEvents(t, dp)
+	decoded := decodeEvent(t, events[nodeA])
+	require.Equal(t, []string{"x/web"}, decoded.policies)
+	require.Empty(t, events[nodeA].GetPayload()[controlplane.PolicyDelta].GetPolicyDeltas())
+
+	// node A receives the rule changes
+	policy = nodeTestPolicy("x/web", selectorSet)
+	policy.ACLs = append(policy.ACLs,
+		&policies.ACLPolicy{Target: policies.Allowed, Direction: policies.Egress},
+		&policies.ACLPolicy{Target: policies.Dropped, Direction: policies.Ingress},
+	)
+	require.NoError(t, dp.UpdatePolicy(policy))
+	events = nextEvents(t, dp)
+	decoded = decodeEvent(t, events[nodeA])
+	require.Empty(t, decoded.policies)
+	deltas := events[nodeA].GetPayload()[controlplane.PolicyDelta].GetPolicyDeltas()
+	require.Len(t, deltas, 1)
+	require.Equal(t, "x/web", deltas[0].GetPolicyKey())
+	require.Empty(t, deltas[0].GetRemoved())
+	require.Len(t, deltas[0].GetAdded(), 1)
+	require.Equal(t, int32(1), deltas[0].GetAdded()[0].GetIndex())
+	require.Equal(t, string(policies.Egress), deltas[0].GetAdded()[0].GetDirection())
+
+	// node B can't apply deltas, and also has the policy since pod B1 is selected
+	decoded = decodeEvent(t, events[nodeB])
+	require.Equal(t, []string{"x/web"}, decoded.policies)
+	require.Empty(t, events[nodeB].GetPayload()[controlplane.PolicyDelta].GetPolicyDeltas())
+
+	// other changes are sent as policies
+	policy = nodeTestPolicy("x/web", selectorSet)
+	policy.ACLPolicyID = "azure-acl-x-web"
+	require.NoError(t, dp.UpdatePolicy(policy))
+	events = nextEvents(t, dp)
+	decoded = decodeEvent(t, events[nodeA])
+	require.Equal(t, []string{"x/web"}, decoded.policies)
+	require.Empty(t, events[nodeA].GetPayload()[controlplane.PolicyDelta].GetPolicyDeltas())
+}
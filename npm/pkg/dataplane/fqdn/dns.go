@@ -0,0 +1,199 @@
+package fqdn
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+const (
+	dnsPort = 53
+
+	ipv4MinHeaderLength = 20
+	udpHeaderLength     = 8
+	protocolUDP         = 17
+
+	dnsHeaderLength = 12
+	dnsTypeA        = 1
+	dnsClassIN      = 1
+
+	// a name can't contain more compression pointers than this without looping
+	maxCompressionPointers = 64
+)
+
+var (
+	ErrNotDNSMessage   = errors.New("packet is not a DNS query or response")
+	ErrMalformedPacket = errors.New("malformed packet")
+)
+
+// dnsAnswer is an IPv4 address from an A record and the record's TTL in seconds.
+type dnsAnswer struct {
+	ip  string
+	ttl uint32
+}
+
+// dnsPacket is a DNS query sent to port 53 over UDP, or a successful DNS response sent from port 53 with its A records.
+// Records are attributed to the question name, so answers reached through CNAMEs are included.
+type dnsPacket struct {
+	srcIP    string
+	dstIP    string
+	srcPort  uint16
+	dstPort  uint16
+	id       uint16
+	response bool
+	name     string
+	answers  []dnsAnswer
+}
+
+// parseIPv4Packet parses an IPv4 packet (without a link-layer header)
+// containing a DNS query sent to port 53 or a DNS response sent from port 53 over UDP.
+func parseIPv4Packet(packet []byte) (*dnsPacket, error) {
+	if len(packet) < ipv4MinHeaderLength || packet[0]>>4 != 4 {
+		return nil, fmt.Errorf("%w: not an IPv4 packet", ErrNotDNSMessage)
+	}
+	headerLength := int(packet[0]&0x0f) * 4
+	totalLength := int(binary.BigEndian.Uint16(packet[2:4]))
+	if headerLength < ipv4MinHeaderLength || totalLength < headerLength || totalLength > len(packet) {
+		return nil, fmt.Errorf("%w: bad IPv4 header", ErrMalformedPacket)
+	}
+	if packet[9] != protocolUDP {
+		return nil, fmt.Errorf("%w: not a UDP packet", ErrNotDNSMessage)
+	}
+	// DNS responses which are fragmented are ignored
+	if binary.BigEndian.Uint16(packet[6:8])&0x3fff != 0 {
+		return nil, fmt.Errorf("%w: fragmented packet", ErrNotDNSMessage)
+	}
+
+	udp := packet[headerLength:totalLength]
+	if len(udp) < udpHeaderLength {
+		return nil, fmt.Errorf("%w: bad UDP header", ErrMalformedPacket)
+	}
+	srcPort := binary.BigEndian.Uint16(udp[0:2])
+	dstPort := binary.BigEndian.Uint16(udp[2:4])
+	if srcPort != dnsPort && dstPort != dnsPort {
+		return nil, fmt.Errorf("%w: neither port is %d", ErrNotDNSMessage, dnsPort)
+	}
+	udpLength := int(binary.BigEndian.Uint16(udp[4:6]))
+	if udpLength < udpHeaderLength || udpLength > len(udp) {
+		return nil, fmt.Errorf("%w: bad UDP length", ErrMalformedPacket)
+	}
+	dns, err := parseDNSMessage(udp[udpHeaderLength:udpLength])
+	if err != nil {
+		return nil, err
+	}
+	if (dns.response && srcPort != dnsPort) || (!dns.response && dstPort != dnsPort) {
+		return nil, fmt.Errorf("%w: DNS message on the wrong port", ErrNotDNSMessage)
+	}
+	dns.srcIP = net.IP(packet[12:16]).String()
+	dns.dstIP = net.IP(packet[16:20]).String()
+	dns.srcPort = srcPort
+	dns.dstPort = dstPort
+	return dns, nil
+}
+
+// parseDNSMessage parses a standard query or a successful response with a single question,
+// and returns the A records of the answer section of a response.
+func parseDNSMessage(msg []byte) (*dnsPacket, error) {
+	if len(msg) < dnsHeaderLength {
+		return nil, fmt.Errorf("%w: short DNS header", ErrMalformedPacket)
+	}
+	flags := binary.BigEndian.Uint16(msg[2:4])
+	isResponse := flags&0x8000 != 0
+	opcode := (flags >> 11) & 0x0f
+	rcode := flags & 0x0f
+	if opcode != 0 || rcode != 0 {
+		return nil, fmt.Errorf("%w: not a standard query or a successful response", ErrNotDNSMessage)
+	}
+	questionCount := binary.BigEndian.Uint16(msg[4:6])
+	answerCount := int(binary.BigEndian.Uint16(msg[6:8]))
+	if questionCount != 1 {
+		return nil, fmt.Errorf("%w: expected 1 question but got %d", ErrNotDNSMessage, questionCount)
+	}
+
+	name, offset, err := readName(msg, dnsHeaderLength)
+	if err != nil {
+		return nil, err
+	}
+	// skip the question's type and class
+	offset += 4
+	if offset > len(msg) {
+		return nil, fmt.Errorf("%w: short DNS question", ErrMalformedPacket)
+	}
+
+	dns := &dnsPacket{
+		id:       binary.BigEndian.Uint16(msg[0:2]),
+		response: isResponse,
+		name:     name,
+	}
+	if !isResponse {
+		return dns, nil
+	}
+	for i := 0; i < answerCount; i++ {
+		_, offset, err = readName(msg, offset)
+		if err != nil {
+			return nil, err
+		}
+		if offset+10 > len(msg) {
+			return nil, fmt.Errorf("%w: short DNS record", ErrMalformedPacket)
+		}
+		recordType := binary.BigEndian.Uint16(msg[offset : offset+2])
+		recordClass := binary.BigEndian.Uint16(msg[offset+2 : offset+4])
+		ttl := binary.BigEndian.Uint32(msg[offset+4 : offset+8])
+		dataLength := int(binary.BigEndian.Uint16(msg[offset+8 : offset+10]))
+		offset += 10
+		if offset+dataLength > len(msg) {
+			return nil, fmt.Errorf("%w: short DNS record data", ErrMalformedPacket)
+		}
+		if recordType == dnsTypeA && recordClass == dnsClassIN && dataLength == net.IPv4len {
+			ip := net.IP(msg[offset : offset+dataLength]).String()
+			dns.answers = append(dns.answers, dnsAnswer{ip: ip, ttl: ttl})
+		}
+		offset += dataLength
+	}
+	return dns, nil
+}
+
+// readName reads a possibly compressed domain name starting at offset.
+// It returns the lowercase name without a trailing dot and the offset after the name.
+func readName(msg []byte, offset int) (string, int, error) {
+	labels := make([]string, 0)
+	// the offset after the name is the offset after the first compression pointer, if any
+	end := -1
+	pointers := 0
+	for {
+		if offset >= len(msg) {
+			return "", 0, fmt.Errorf("%w: short DNS name", ErrMalformedPacket)
+		}
+		length := int(msg[offset])
+		switch {
+		case length == 0:
+			if end == -1 {
+				end = offset + 1
+			}
+			return strings.ToLower(strings.Join(labels, ".")), end, nil
+		case length&0xc0 == 0xc0:
+			if offset+1 >= len(msg) {
+				return "", 0, fmt.Errorf("%w: short DNS name pointer", ErrMalformedPacket)
+			}
+			pointers++
+			if pointers > maxCompressionPointers {
+				return "", 0, fmt.Errorf("%w: too many DNS name pointers", ErrMalformedPacket)
+			}
+			if end == -1 {
+				end = offset + 2
+			}
+			offset = int(binary.BigEndian.Uint16(msg[offset:offset+2]) & 0x3fff)
+		case length&0xc0 != 0:
+			return "", 0, fmt.Errorf("%w: unsupported DNS label type", ErrMalformedPacket)
+		default:
+			offset++
+			if offset+length > len(msg) {
+				return "", 0, fmt.Errorf("%w: short DNS label", ErrMalformedPacket)
+			}
+			labels = append(labels, string(msg[offset:offset+length]))
+			offset += length
+		}
+	}
+}
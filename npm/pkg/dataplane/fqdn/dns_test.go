@@ -0,0 +1,193 @@
+package fqdn
+
+import (
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testRecord struct {
+	recordType uint16
+	ttl        uint32
+	data       []byte
+}
+
+func aRecord(ip string, ttl uint32) testRecord {
+	return testRecord{recordType: dnsTypeA, ttl: ttl, data: net.ParseIP(ip).To4()}
+}
+
+func encodeName(name string) []byte {
+	encoded := make([]byte, 0, len(name)+2)
+	for _, label := range strings.Split(name, ".") {
+		encoded = append(encoded, byte(len(label)))
+		encoded = append(encoded, label...)
+	}
+	return append(encoded, 0)
+}
+
+// dnsMessage creates a response whose records point to the question name with a compression pointer
+func dnsMessage(flags uint16, name string, records ...testRecord) []byte {
+	msg := make([]byte, dnsHeaderLength)
+	binary.BigEndian.PutUint16(msg[0:2], 0x1234)
+	binary.BigEndian.PutUint16(msg[2:4], flags)
+	binary.BigEndian.PutUint16(msg[4:6], 1)
+	binary.BigEndian.PutUint16(msg[6:8], uint16(len(records)))
+	msg = append(msg, encodeName(name)...)
+	msg = binary.BigEndian.AppendUint16(msg, dnsTypeA)
+	msg = binary.BigEndian.AppendUint16(msg, dnsClassIN)
+	for _, record := range records {
+		msg = append(msg, 0xc0, dnsHeaderLength)
+		msg = binary.BigEndian.AppendUint16(msg, record.recordType)
+		msg = binary.BigEndian.AppendUint16(msg, dnsClassIN)
+		msg = binary.BigEndian.AppendUint32(msg, record.ttl)
+		msg = binary.BigEndian.AppendUint16(msg, uint16(len(record.data)))
+		msg = append(msg, record.data...)
+	}
+	return msg
+}
+
+func dnsResponseMessage(name string, records ...testRecord) []byte {
+	return dnsMessage(0x8180, name, records...)
+}
+
+func dnsQueryMessage(name string) []byte {
+	return dnsMessage(0x0100, name)
+}
+
+const (
+	testServerIP   = "10.0.0.10"
+	testClientIP   = "10.224.0.5"
+	testClientPort = 40000
+)
+
+func ipv4UDPPacket(srcIP, dstIP string, srcPort, dstPort uint16, payload []byte) []byte {
+	packet := make([]byte, ipv4MinHeaderLength+udpHeaderLength)
+	packet[0] = 0x45
+	binary.BigEndian.PutUint16(packet[2:4], uint16(len(packet)+len(payload)))
+	packet[8] = 64
+	packet[9] = protocolUDP
+	copy(packet[12:16], net.ParseIP(srcIP).To4())
+	copy(packet[16:20], net.ParseIP(dstIP).To4())
+	binary.BigEndian.PutUint16(packet[20:22], srcPort)
+	binary.BigEndian.PutUint16(packet[22:24], dstPort)
+	binary.BigEndian.PutUint16(packet[24:26], uint16(udpHeaderLength+len(payload)))
+	return append(packet, payload...)
+}
+
+// udpPacket is sent from srcPort of the test server to the test client
+func udpPacket(srcPort uint16, payload []byte) []byte {
+	return ipv4UDPPacket(testServerIP, testClientIP, srcPort, testClientPort, payload)
+}
+
+// queryPacket is sent from the test client to port 53 of the test server
+func queryPacket(name string) []byte {
+	return ipv4UDPPacket(testClientIP, testServerIP, testClientPort, dnsPort, dnsQueryMessage(name))
+}
+
+func responsePacket(name string, answers ...dnsAnswer) *dnsPacket {
+	return &dnsPacket{
+		srcIP:    testServerIP,
+		dstIP:    testClientIP,
+		srcPort:  dnsPort,
+		dstPort:  testClientPort,
+		id:       0x1234,
+		response: true,
+		name:     name,
+		answers:  answers,
+	}
+}
+
+func TestParseIPv4Packet(t *testing.T) {
+	cname := testRecord{recordType: 5, ttl: 300, data: encodeName("blob.cdn.net")}
+	fragmented := udpPacket(dnsPort, dnsResponseMessage("example.com", aRecord("1.2.3.4", 60)))
+	binary.BigEndian.PutUint16(fragmented[6:8], 0x2000)
+
+	tests := []struct {
+		name      string
+		packet    []byte
+		want      *dnsPacket
+		wantErrIs error
+	}{
+		{
+			name:   "A records",
+			packet: udpPacket(dnsPort, dnsResponseMessage("Example.com", aRecord("1.2.3.4", 60), aRecord("5.6.7.8", 10))),
+			want:   responsePacket("example.com", dnsAnswer{ip: "1.2.3.4", ttl: 60}, dnsAnswer{ip: "5.6.7.8", ttl: 10}),
+		},
+		{
+			name:   "A record after CNAME",
+			packet: udpPacket(dnsPort, dnsResponseMessage("account.blob.core.windows.net", cname, aRecord("20.60.0.1", 30))),
+			want:   responsePacket("account.blob.core.windows.net", dnsAnswer{ip: "20.60.0.1", ttl: 30}),
+		},
+		{
+			name:   "no answers",
+			packet: udpPacket(dnsPort, dnsResponseMessage("example.com")),
+			want:   responsePacket("example.com"),
+		},
+		{
+			name:   "query",
+			packet: queryPacket("Example.com"),
+			want: &dnsPacket{
+				srcIP:   testClientIP,
+				dstIP:   testServerIP,
+				srcPort: testClientPort,
+				dstPort: dnsPort,
+				id:      0x1234,
+				name:    "example.com",
+			},
+		},
+		{
+			name:      "query from port 53",
+			packet:    udpPacket(dnsPort, dnsQueryMessage("example.com")),
+			wantErrIs: ErrNotDNSMessage,
+		},
+		{
+			name:      "NXDOMAIN",
+			packet:    udpPacket(dnsPort, dnsMessage(0x8183, "example.com")),
+			wantErrIs: ErrNotDNSMessage,
+		},
+		{
+			name:      "not from port 53",
+			packet:    udpPacket(5353, dnsResponseMessage("example.com", aRecord("1.2.3.4", 60))),
+			wantErrIs: ErrNotDNSMessage,
+		},
+		{
+			name:      "fragmented",
+			packet:    fragmented,
+			wantErrIs: ErrNotDNSMessage,
+		},
+		{
+			name:      "truncated record",
+			packet:    udpPacket(dnsPort, dnsResponseMessage("example.com", aRecord("1.2.3.4", 60))[:40]),
+			wantErrIs: ErrMalformedPacket,
+		},
+		{
+			name:      "short packet",
+			packet:    []byte{0x45, 0, 0},
+			wantErrIs: ErrNotDNSMessage,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseIPv4Packet(tt.packet)
+			if tt.wantErrIs != nil {
+				require.ErrorIs(t, err, tt.wantErrIs)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReadNameWithPointerLoop(t *testing.T) {
+	msg := make([]byte, dnsHeaderLength)
+	// a pointer to itself
+	msg = append(msg, 0xc0, dnsHeaderLength)
+	_, _, err := readName(msg, dnsHeaderLength)
+	require.ErrorIs(t, err, ErrMalformedPacket)
+}
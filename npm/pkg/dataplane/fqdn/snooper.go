@@ -0,0 +1,329 @@
+// Package fqdn populates FQDNAddresses ipsets with the IPs of DNS responses observed on the node.
+//
+// Any pod can send a packet which looks like a DNS response, so a response is only trusted if it comes from a
+// CoreDNS pod or a configured DNS server and answers a query seen earlier with the same flow, DNS ID and question.
+// FQDN sets are scoped to a namespace, and a response only adds IPs to the sets of the namespace of the pod which queried.
+package fqdn
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-container-networking/npm/metrics"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
+	"github.com/Azure/azure-container-networking/npm/util"
+	"k8s.io/klog"
+)
+
+const (
+	// podKey owns the members added by the snooper in the IPSetManager
+	podKey = "fqdn-snooper"
+	// minTTL keeps IPs from DNS responses with very low TTLs long enough for the pod to connect
+	minTTL = 30 * time.Second
+	// sweepInterval is how often expired IPs are removed and the FQDN patterns referenced by policies are refreshed.
+	// A pattern from a new policy will start being snooped within this interval.
+	sweepInterval = 5 * time.Second
+	// queryTimeout is how long a query waits for its response
+	queryTimeout = 10 * time.Second
+	// maxQueries bounds the queries waiting for a response. New queries are ignored while there are this many.
+	maxQueries = 8192
+
+	maxPacketSize = 65535
+)
+
+// coreDNSSets hold the IPs of the CoreDNS pods, which are trusted to send DNS responses
+var coreDNSSets = []*ipsets.IPSetMetadata{
+	ipsets.NewIPSetMetadata("kube-system", ipsets.Namespace),
+	ipsets.NewIPSetMetadata(util.GetIpSetFromLabelKV("k8s-app", "kube-dns"), ipsets.KeyValueLabelOfPod),
+}
+
+var ErrSnoopingNotSupported = errors.New("DNS snooping is not supported on this OS")
+
+// packetSource provides IPv4 packets containing DNS queries and responses.
+type packetSource interface {
+	// ReadPacket reads a packet into buf. It returns 0 bytes and no error when no packet arrives before a timeout.
+	ReadPacket(buf []byte) (int, error)
+	Close() error
+}
+
+// ipsetWriter is the subset of the IPSetManager used by the snooper.
+type ipsetWriter interface {
+	GetNetPolReferencedIPSets(setType ipsets.SetType) []*ipsets.IPSetMetadata
+	AddToSets(addToSets []*ipsets.IPSetMetadata, ip, podKey string) error
+	RemoveFromSets(removeFromSets []*ipsets.IPSetMetadata, ip, podKey string) error
+	DeleteIPSet(name string, deleteOption util.DeleteOption)
+	ApplyIPSets() error
+	IsMember(setMetadata *ipsets.IPSetMetadata, ip string) bool
+}
+
+// dnsQuery identifies a query by its flow, DNS ID and question. A response must match all of them.
+type dnsQuery struct {
+	clientIP   string
+	clientPort uint16
+	serverIP   string
+	id         uint16
+	name       string
+}
+
+// Snooper observes DNS responses sent to pods and adds the resolved IPs to the FQDNAddresses ipsets
+// of the pods' namespace whose patterns match the queried name. IPs are removed once the TTL of their DNS records expires.
+type Snooper struct {
+	sync.Mutex
+	ipsetMgr  ipsetWriter
+	newSource func() (packetSource, error)
+	now       func() time.Time
+	// dnsServerIPs are trusted to send DNS responses in addition to the CoreDNS pods
+	dnsServerIPs map[string]struct{}
+	// sets are the FQDN sets referenced by network policies, keyed by set name
+	sets map[string]*ipsets.IPSetMetadata
+	// members maps a set name to the expiry time of each of its IPs
+	members map[string]map[string]time.Time
+	// queries maps the queries waiting for a response to their expiry time
+	queries map[dnsQuery]time.Time
+}
+
+// NewSnooper returns a Snooper which trusts DNS responses from the CoreDNS pods and from dnsServerIPs,
+// such as the IP of the cluster DNS service or of a node-local DNS cache.
+func NewSnooper(ipsetMgr *ipsets.IPSetManager, dnsServerIPs []string) *Snooper {
+	return newSnooper(ipsetMgr, dnsServerIPs, newPacketSource, time.Now)
+}
+
+func newSnooper(ipsetMgr ipsetWriter, dnsServerIPs []string, newSource func() (packetSource, error), now func() time.Time) *Snooper {
+	s := &Snooper{
+		ipsetMgr:     ipsetMgr,
+		newSource:    newSource,
+		now:          now,
+		dnsServerIPs: make(map[string]struct{}, len(dnsServerIPs)),
+		sets:         make(map[string]*ipsets.IPSetMetadata),
+		members:      make(map[string]map[string]time.Time),
+		queries:      make(map[dnsQuery]time.Time),
+	}
+	for _, ip := range dnsServerIPs {
+		s.dnsServerIPs[ip] = struct{}{}
+	}
+	return s
+}
+
+// Run snoops DNS responses and sweeps expired IPs until the stop channel is closed. Should only be called once.
+func (s *Snooper) Run(stopChannel <-chan struct{}) {
+	source, err := s.newSource()
+	if err != nil {
+		metrics.SendErrorLogAndMetric(util.FQDNID, "error: failed to start DNS snooping: %s", err.Error())
+		return
+	}
+	klog.Infof("[FQDNSnooper] started DNS snooping with DNS servers %v and the CoreDNS pods", s.dnsServerIPList())
+
+	s.sweep()
+
+	go func() {
+		defer source.Close()
+		buf := make([]byte, maxPacketSize)
+		for {
+			select {
+			case <-stopChannel:
+				return
+			default:
+			}
+
+			n, err := source.ReadPacket(buf)
+			if err != nil {
+				metrics.SendErrorLogAndMetric(util.FQDNID, "error: failed to read packet: %s", err.Error())
+				time.Sleep(time.Second)
+				continue
+			}
+			if n > 0 {
+				s.handlePacket(buf[:n])
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopChannel:
+			return
+		case <-ticker.C:
+			s.sweep()
+		}
+	}
+}
+
+// handlePacket records a DNS query to a trusted DNS server, or adds the IPs of a DNS response from a trusted DNS server
+// which answers a recorded query to the sets of all matching patterns in the namespace of the client.
+// IPs already in a set have their expiry extended, and ipsets are only applied when a set gains an IP.
+func (s *Snooper) handlePacket(packet []byte) {
+	dns, err := parseIPv4Packet(packet)
+	if err != nil {
+		if !errors.Is(err, ErrNotDNSMessage) {
+			klog.Infof("[FQDNSnooper] ignoring packet: %s", err.Error())
+		}
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if !dns.response {
+		s.recordQuery(dns)
+		return
+	}
+
+	query := dnsQuery{clientIP: dns.dstIP, clientPort: dns.dstPort, serverIP: dns.srcIP, id: dns.id, name: dns.name}
+	expiry, ok := s.queries[query]
+	if !ok || !s.now().Before(expiry) {
+		// also covers responses which are seen again on another interface after the first one was handled
+		return
+	}
+	delete(s.queries, query)
+	if !s.isDNSServer(dns.srcIP) {
+		klog.Infof("[FQDNSnooper] ignoring DNS response for %s from untrusted server %s", dns.name, dns.srcIP)
+		return
+	}
+	if len(dns.answers) == 0 {
+		return
+	}
+
+	now := s.now()
+	changed := false
+	for setName, metadata := range s.sets {
+		namespace, pattern := util.GetNamespaceAndPatternFromFQDNSet(setName)
+		if !matchesPattern(pattern, dns.name) {
+			continue
+		}
+		if !s.ipsetMgr.IsMember(ipsets.NewIPSetMetadata(namespace, ipsets.Namespace), dns.dstIP) {
+			continue
+		}
+		members, ok := s.members[setName]
+		if !ok {
+			members = make(map[string]time.Time)
+			s.members[setName] = members
+		}
+		for _, answer := range dns.answers {
+			ttl := time.Duration(answer.ttl) * time.Second
+			if ttl < minTTL {
+				ttl = minTTL
+			}
+			expiry := now.Add(ttl)
+			if current, ok := members[answer.ip]; ok {
+				if expiry.After(current) {
+					members[answer.ip] = expiry
+				}
+				continue
+			}
+			if err := s.ipsetMgr.AddToSets([]*ipsets.IPSetMetadata{metadata}, answer.ip, podKey); err != nil {
+				metrics.SendErrorLogAndMetric(util.FQDNID, "error: failed to add ip %s for %s: %s", answer.ip, setName, err.Error())
+				continue
+			}
+			klog.Infof("[FQDNSnooper] added ip %s for name %s queried by %s to set %s", answer.ip, dns.name, dns.dstIP, metadata.GetPrefixName())
+			members[answer.ip] = expiry
+			changed = true
+		}
+	}
+
+	if changed {
+		s.applyIPSets()
+	}
+}
+
+// recordQuery records a query to a trusted DNS server so that its response is accepted.
+// Queries are only recorded while there are no more than maxQueries waiting, so a pod can't exhaust memory.
+func (s *Snooper) recordQuery(dns *dnsPacket) {
+	if !s.isDNSServer(dns.dstIP) {
+		return
+	}
+	query := dnsQuery{clientIP: dns.srcIP, clientPort: dns.srcPort, serverIP: dns.dstIP, id: dns.id, name: dns.name}
+	if _, ok := s.queries[query]; !ok && len(s.queries) >= maxQueries {
+		klog.Infof("[FQDNSnooper] ignoring DNS query for %s from %s since %d queries are waiting for a response", dns.name, dns.srcIP, maxQueries)
+		return
+	}
+	s.queries[query] = s.now().Add(queryTimeout)
+}
+
+// isDNSServer returns true if the IP is a configured DNS server or a CoreDNS pod
+func (s *Snooper) isDNSServer(ip string) bool {
+	if _, ok := s.dnsServerIPs[ip]; ok {
+		return true
+	}
+	for _, metadata := range coreDNSSets {
+		if !s.ipsetMgr.IsMember(metadata, ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Snooper) dnsServerIPList() []string {
+	ips := make([]string, 0, len(s.dnsServerIPs))
+	for ip := range s.dnsServerIPs {
+		ips = append(ips, ip)
+	}
+	sort.Strings(ips)
+	return ips
+}
+
+// sweep refreshes the referenced sets, removes expired IPs and queries,
+// and removes all IPs and the sets which are no longer referenced.
+func (s *Snooper) sweep() {
+	referencedSets := s.ipsetMgr.GetNetPolReferencedIPSets(ipsets.FQDNAddresses)
+
+	s.Lock()
+	defer s.Unlock()
+
+	s.sets = make(map[string]*ipsets.IPSetMetadata, len(referencedSets))
+	for _, metadata := range referencedSets {
+		s.sets[metadata.Name] = metadata
+	}
+
+	now := s.now()
+	for query, expiry := range s.queries {
+		if !now.Before(expiry) {
+			delete(s.queries, query)
+		}
+	}
+
+	changed := false
+	for setName, members := range s.members {
+		_, referenced := s.sets[setName]
+		metadata := ipsets.NewIPSetMetadata(setName, ipsets.FQDNAddresses)
+		for ip, expiry := range members {
+			if referenced && now.Before(expiry) {
+				continue
+			}
+			if err := s.ipsetMgr.RemoveFromSets([]*ipsets.IPSetMetadata{metadata}, ip, podKey); err != nil {
+				metrics.SendErrorLogAndMetric(util.FQDNID, "error: failed to remove ip %s for %s: %s", ip, setName, err.Error())
+				continue
+			}
+			delete(members, ip)
+			changed = true
+		}
+		if !referenced && len(members) == 0 {
+			// won't delete the set if it's referenced again in the meantime
+			s.ipsetMgr.DeleteIPSet(metadata.GetPrefixName(), util.SoftDelete)
+			delete(s.members, setName)
+			changed = true
+		}
+	}
+
+	if changed {
+		s.applyIPSets()
+	}
+}
+
+func (s *Snooper) applyIPSets() {
+	if err := s.ipsetMgr.ApplyIPSets(); err != nil {
+		metrics.SendErrorLogAndMetric(util.FQDNID, "error: failed to apply ipsets: %s", err.Error())
+	}
+}
+
+// matchesPattern returns true if the name equals the pattern,
+// or if the pattern starts with "*." and the name is a subdomain of the rest of the pattern.
+func matchesPattern(pattern, name string) bool {
+	if suffix := strings.TrimPrefix(pattern, "*"); suffix != pattern {
+		return strings.HasSuffix(name, suffix) && len(name) > len(suffix)
+	}
+	return pattern == name
+}
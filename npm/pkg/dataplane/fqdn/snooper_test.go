@@ -0,0 +1,284 @@
+package fqdn
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
+	"github.com/Azure/azure-container-networking/npm/util"
+	"github.com/stretchr/testify/require"
+)
+
+// testNamespace is the namespace of the test client
+const testNamespace = "default"
+
+// fakeIPSetWriter records the members of sets like the IPSetManager would
+type fakeIPSetWriter struct {
+	referenced  []string
+	members     map[string]map[string]string
+	deletedSets []string
+	numApplies  int
+}
+
+// newFakeIPSetWriter references the FQDN sets of the patterns in the test namespace, which has the test client
+func newFakeIPSetWriter(patterns ...string) *fakeIPSetWriter {
+	f := &fakeIPSetWriter{
+		members: make(map[string]map[string]string),
+	}
+	for _, pattern := range patterns {
+		f.referenced = append(f.referenced, util.GetFQDNSetName(testNamespace, pattern))
+	}
+	_ = f.AddToSets([]*ipsets.IPSetMetadata{ipsets.NewIPSetMetadata(testNamespace, ipsets.Namespace)}, testClientIP, "default/client")
+	return f
+}
+
+func (f *fakeIPSetWriter) GetNetPolReferencedIPSets(setType ipsets.SetType) []*ipsets.IPSetMetadata {
+	sets := make([]*ipsets.IPSetMetadata, 0, len(f.referenced))
+	for _, name := range f.referenced {
+		sets = append(sets, ipsets.NewIPSetMetadata(name, setType))
+	}
+	return sets
+}
+
+func (f *fakeIPSetWriter) IsMember(setMetadata *ipsets.IPSetMetadata, ip string) bool {
+	_, ok := f.members[setMetadata.GetPrefixName()][ip]
+	return ok
+}
+
+func (f *fakeIPSetWriter) AddToSets(addToSets []*ipsets.IPSetMetadata, ip, key string) error {
+	for _, metadata := range addToSets {
+		if _, ok := f.members[metadata.GetPrefixName()]; !ok {
+			f.members[metadata.GetPrefixName()] = make(map[string]string)
+		}
+		f.members[metadata.GetPrefixName()][ip] = key
+	}
+	return nil
+}
+
+func (f *fakeIPSetWriter) RemoveFromSets(removeFromSets []*ipsets.IPSetMetadata, ip, key string) error {
+	for _, metadata := range removeFromSets {
+		if f.members[metadata.GetPrefixName()][ip] == key {
+			delete(f.members[metadata.GetPrefixName()], ip)
+		}
+	}
+	return nil
+}
+
+func (f *fakeIPSetWriter) DeleteIPSet(name string, _ util.DeleteOption) {
+	if len(f.members[name]) == 0 {
+		delete(f.members, name)
+		f.deletedSets = append(f.deletedSets, name)
+	}
+}
+
+func (f *fakeIPSetWriter) ApplyIPSets() error {
+	f.numApplies++
+	return nil
+}
+
+// setMembers returns the members of the FQDN set of the pattern in the test namespace
+func (f *fakeIPSetWriter) setMembers(pattern string) []string {
+	return f.setMembersIn(testNamespace, pattern)
+}
+
+func (f *fakeIPSetWriter) setMembersIn(namespace, pattern string) []string {
+	ips := make([]string, 0)
+	for ip := range f.members[util.FQDNPrefix+util.GetFQDNSetName(namespace, pattern)] {
+		ips = append(ips, ip)
+	}
+	sort.Strings(ips)
+	return ips
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+// newTestSnooper trusts the test server
+func newTestSnooper(writer *fakeIPSetWriter) (*Snooper, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	s := newSnooper(writer, []string{testServerIP}, func() (packetSource, error) { return nil, ErrSnoopingNotSupported }, clock.Now)
+	s.sweep()
+	return s, clock
+}
+
+// resolve has the snooper observe a query from the test client and its response from the test server
+func resolve(s *Snooper, name string, records ...testRecord) {
+	s.handlePacket(queryPacket(name))
+	s.handlePacket(udpPacket(dnsPort, dnsResponseMessage(name, records...)))
+}
+
+func TestMatchesPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"example.com", "example.com", true},
+		{"example.com", "www.example.com", false},
+		{"*.example.com", "www.example.com", true},
+		{"*.example.com", "a.b.example.com", true},
+		{"*.example.com", "example.com", false},
+		{"*.example.com", "badexample.com", false},
+		{"*.example.com", ".example.com", false},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, matchesPattern(tt.pattern, tt.name), "pattern %s and name %s", tt.pattern, tt.name)
+	}
+}
+
+func TestHandlePacketAddsMatchingIPs(t *testing.T) {
+	writer := newFakeIPSetWriter("*.blob.core.windows.net", "login.microsoftonline.com")
+	s, _ := newTestSnooper(writer)
+
+	resolve(s, "account.blob.core.windows.net", aRecord("20.60.0.1", 60), aRecord("20.60.0.2", 60))
+	resolve(s, "example.com", aRecord("1.2.3.4", 60))
+
+	require.Equal(t, []string{"20.60.0.1", "20.60.0.2"}, writer.setMembers("*.blob.core.windows.net"))
+	require.Empty(t, writer.setMembers("login.microsoftonline.com"))
+	require.Equal(t, 1, writer.numApplies)
+
+	// a repeated response doesn't change membership, so ipsets aren't applied
+	resolve(s, "account.blob.core.windows.net", aRecord("20.60.0.1", 60))
+	require.Equal(t, 1, writer.numApplies)
+}
+
+func TestHandlePacketIgnoresUnsolicitedResponses(t *testing.T) {
+	writer := newFakeIPSetWriter("example.com")
+	s, clock := newTestSnooper(writer)
+
+	// no query
+	s.handlePacket(udpPacket(dnsPort, dnsResponseMessage("example.com", aRecord("1.2.3.4", 60))))
+	require.Empty(t, writer.setMembers("example.com"))
+
+	// a different question
+	s.handlePacket(queryPacket("example.org"))
+	s.handlePacket(udpPacket(dnsPort, dnsResponseMessage("example.com", aRecord("1.2.3.4", 60))))
+	require.Empty(t, writer.setMembers("example.com"))
+
+	// a different DNS ID
+	s.handlePacket(queryPacket("example.com"))
+	response := dnsResponseMessage("example.com", aRecord("1.2.3.4", 60))
+	response[1]++
+	s.handlePacket(udpPacket(dnsPort, response))
+	require.Empty(t, writer.setMembers("example.com"))
+
+	// a different client port
+	s.handlePacket(ipv4UDPPacket(testServerIP, testClientIP, dnsPort, testClientPort+1, dnsResponseMessage("example.com", aRecord("1.2.3.4", 60))))
+	require.Empty(t, writer.setMembers("example.com"))
+
+	// an expired query
+	clock.now = clock.now.Add(queryTimeout)
+	s.handlePacket(udpPacket(dnsPort, dnsResponseMessage("example.com", aRecord("1.2.3.4", 60))))
+	require.Empty(t, writer.setMembers("example.com"))
+
+	// a query is only answered once
+	resolve(s, "example.com", aRecord("1.2.3.4", 60))
+	s.handlePacket(udpPacket(dnsPort, dnsResponseMessage("example.com", aRecord("5.6.7.8", 60))))
+	require.Equal(t, []string{"1.2.3.4"}, writer.setMembers("example.com"))
+}
+
+func TestHandlePacketTrustsOnlyDNSServers(t *testing.T) {
+	writer := newFakeIPSetWriter("example.com")
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	s := newSnooper(writer, nil, func() (packetSource, error) { return nil, ErrSnoopingNotSupported }, clock.Now)
+	s.sweep()
+
+	// the test server is neither configured nor a CoreDNS pod
+	resolve(s, "example.com", aRecord("1.2.3.4", 60))
+	require.Empty(t, writer.setMembers("example.com"))
+	require.Empty(t, s.queries)
+
+	// a pod labeled like CoreDNS outside of kube-system isn't trusted
+	kubeDNSLabel := ipsets.NewIPSetMetadata(util.GetIpSetFromLabelKV("k8s-app", "kube-dns"), ipsets.KeyValueLabelOfPod)
+	require.NoError(t, writer.AddToSets([]*ipsets.IPSetMetadata{kubeDNSLabel}, testServerIP, "default/coredns"))
+	resolve(s, "example.com", aRecord("1.2.3.4", 60))
+	require.Empty(t, writer.setMembers("example.com"))
+
+	require.NoError(t, writer.AddToSets(coreDNSSets, testServerIP, "kube-system/coredns"))
+	resolve(s, "example.com", aRecord("1.2.3.4", 60))
+	require.Equal(t, []string{"1.2.3.4"}, writer.setMembers("example.com"))
+}
+
+func TestHandlePacketScopesSetsToNamespace(t *testing.T) {
+	writer := newFakeIPSetWriter("example.com")
+	writer.referenced = append(writer.referenced, util.GetFQDNSetName("other", "example.com"))
+	s, _ := newTestSnooper(writer)
+
+	resolve(s, "example.com", aRecord("1.2.3.4", 60))
+	require.Equal(t, []string{"1.2.3.4"}, writer.setMembers("example.com"))
+	require.Empty(t, writer.setMembersIn("other", "example.com"))
+}
+
+func TestSweepRemovesExpiredIPs(t *testing.T) {
+	writer := newFakeIPSetWriter("example.com")
+	s, clock := newTestSnooper(writer)
+
+	resolve(s, "example.com", aRecord("1.2.3.4", 300), aRecord("5.6.7.8", 1))
+	require.Equal(t, []string{"1.2.3.4", "5.6.7.8"}, writer.setMembers("example.com"))
+
+	// the low TTL is raised to minTTL
+	clock.now = clock.now.Add(minTTL - time.Second)
+	s.sweep()
+	require.Equal(t, []string{"1.2.3.4", "5.6.7.8"}, writer.setMembers("example.com"))
+
+	clock.now = clock.now.Add(time.Second)
+	s.sweep()
+	require.Equal(t, []string{"1.2.3.4"}, writer.setMembers("example.com"))
+
+	// a new response extends the expiry
+	resolve(s, "example.com", aRecord("1.2.3.4", 300))
+	clock.now = clock.now.Add(290 * time.Second)
+	s.sweep()
+	require.Equal(t, []string{"1.2.3.4"}, writer.setMembers("example.com"))
+
+	clock.now = clock.now.Add(10 * time.Second)
+	s.sweep()
+	require.Empty(t, writer.setMembers("example.com"))
+}
+
+func TestSweepRemovesExpiredQueries(t *testing.T) {
+	writer := newFakeIPSetWriter("example.com")
+	s, clock := newTestSnooper(writer)
+
+	s.handlePacket(queryPacket("example.com"))
+	require.Len(t, s.queries, 1)
+
+	clock.now = clock.now.Add(queryTimeout)
+	s.sweep()
+	require.Empty(t, s.queries)
+}
+
+func TestSweepCleansUpUnreferencedPatterns(t *testing.T) {
+	writer := newFakeIPSetWriter("example.com", "*.example.org")
+	s, _ := newTestSnooper(writer)
+
+	resolve(s, "example.com", aRecord("1.2.3.4", 300))
+	resolve(s, "www.example.org", aRecord("5.6.7.8", 300))
+
+	writer.referenced = []string{util.GetFQDNSetName(testNamespace, "*.example.org")}
+	s.sweep()
+	require.Empty(t, writer.setMembers("example.com"))
+	require.Equal(t, []string{util.FQDNPrefix + util.GetFQDNSetName(testNamespace, "example.com")}, writer.deletedSets)
+	require.Equal(t, []string{"5.6.7.8"}, writer.setMembers("*.example.org"))
+
+	// responses for unreferenced patterns are ignored
+	resolve(s, "example.com", aRecord("1.2.3.4", 300))
+	require.Empty(t, writer.setMembers("example.com"))
+}
+
+func TestRunWithoutPacketSource(t *testing.T) {
+	writer := newFakeIPSetWriter("example.com")
+	s, _ := newTestSnooper(writer)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	// returns immediately
+	s.Run(stopCh)
+}
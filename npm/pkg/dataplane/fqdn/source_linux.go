@@ -0,0 +1,85 @@
+package fqdn
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// readTimeout bounds how long a read blocks so the snooper can notice the stop channel
+const readTimeout = time.Second
+
+// dnsFilter is a classic BPF program for packets without a link-layer header.
+// It accepts unfragmented IPv4 UDP packets with source or destination port 53.
+var dnsFilter = []unix.SockFilter{
+	// A = IP protocol
+	{Code: unix.BPF_LD | unix.BPF_B | unix.BPF_ABS, K: 9},
+	// if A != UDP then drop
+	{Code: unix.BPF_JMP | unix.BPF_JEQ | unix.BPF_K, Jt: 0, Jf: 8, K: protocolUDP},
+	// A = flags and fragment offset
+	{Code: unix.BPF_LD | unix.BPF_H | unix.BPF_ABS, K: 6},
+	// if more fragments or fragment offset then drop
+	{Code: unix.BPF_JMP | unix.BPF_JSET | unix.BPF_K, Jt: 6, Jf: 0, K: 0x3fff},
+	// X = IP header length
+	{Code: unix.BPF_LDX | unix.BPF_B | unix.BPF_MSH, K: 0},
+	// A = UDP source port
+	{Code: unix.BPF_LD | unix.BPF_H | unix.BPF_IND, K: 0},
+	// if A == 53 then accept
+	{Code: unix.BPF_JMP | unix.BPF_JEQ | unix.BPF_K, Jt: 2, Jf: 0, K: dnsPort},
+	// A = UDP destination port
+	{Code: unix.BPF_LD | unix.BPF_H | unix.BPF_IND, K: 2},
+	// if A == 53 then accept else drop
+	{Code: unix.BPF_JMP | unix.BPF_JEQ | unix.BPF_K, Jt: 0, Jf: 1, K: dnsPort},
+	{Code: unix.BPF_RET | unix.BPF_K, K: maxPacketSize},
+	{Code: unix.BPF_RET | unix.BPF_K, K: 0},
+}
+
+// rawSocketSource reads DNS queries and responses crossing any interface on the node, including pod veths.
+type rawSocketSource struct {
+	fd int
+}
+
+func newPacketSource() (packetSource, error) {
+	fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, int(htons(unix.ETH_P_IP)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open packet socket: %w", err)
+	}
+
+	prog := unix.SockFprog{
+		Len:    uint16(len(dnsFilter)),
+		Filter: &dnsFilter[0],
+	}
+	if err := unix.SetsockoptSockFprog(fd, unix.SOL_SOCKET, unix.SO_ATTACH_FILTER, &prog); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("failed to attach DNS filter: %w", err)
+	}
+
+	timeout := unix.NsecToTimeval(readTimeout.Nanoseconds())
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &timeout); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("failed to set read timeout: %w", err)
+	}
+	return &rawSocketSource{fd: fd}, nil
+}
+
+func (s *rawSocketSource) ReadPacket(buf []byte) (int, error) {
+	n, err := unix.Read(s.fd, buf)
+	if errors.Is(err, unix.EAGAIN) || errors.Is(err, unix.EINTR) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read from packet socket: %w", err)
+	}
+	return n, nil
+}
+
+func (s *rawSocketSource) Close() error {
+	return unix.Close(s.fd) //nolint:wrapcheck // no need to wrap
+}
+
+// htons converts to network byte order on little-endian hosts
+func htons(i uint16) uint16 {
+	return (i<<8)&0xff00 | i>>8
+}
@@ -0,0 +1,5 @@
+package fqdn
+
+func newPacketSource() (packetSource, error) {
+	return nil, ErrSnoopingNotSupported
+}
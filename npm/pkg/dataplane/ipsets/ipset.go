@@ -63,6 +63,8 @@ func (setMetadata *IPSetMetadata) GetPrefixName() string {
 		return fmt.Sprintf("%s%s", util.NestedLabelPrefix, setMetadata.Name)
 	case EmptyHashSet:
 		return fmt.Sprintf("%s%s", util.EmptySetPrefix, setMetadata.Name)
+	case FQDNAddresses:
+		return fmt.Sprintf("%s%s", util.FQDNPrefix, setMetadata.Name)
 	case UnknownType: // adding this to appease golint
 		metrics.SendErrorLogAndMetric(util.UtilID, "experienced unknown type in set metadata: %+v", setMetadata)
 		return Unknown
@@ -90,6 +92,8 @@ func (setType SetType) getSetKind() SetKind {
 		return HashSet
 	case EmptyHashSet:
 		return HashSet
+	case FQDNAddresses:
+		return HashSet
 	case KeyLabelOfNamespace:
 		return ListSet
 	case KeyValueLabelOfNamespace:
@@ -164,6 +168,8 @@ const (
 	CIDRBlocks SetType = 8
 	// EmptyHashSet is a set meant to have no members
 	EmptyHashSet SetType = 9
+	// FQDNAddresses holds IPs resolved from DNS responses for an FQDN pattern
+	FQDNAddresses SetType = 10
 
 	// Unknown const for unknown string
 	Unknown string = "unknown"
@@ -181,6 +187,7 @@ var (
 		NestedLabelOfPod:         "NestedLabelOfPod",
 		CIDRBlocks:               "CIDRBlocks",
 		EmptyHashSet:             "EmptySet",
+		FQDNAddresses:            "FQDNAddresses",
 	}
 	// ErrIPSetInvalidKind is returned when IPSet kind is invalid
 	ErrIPSetInvalidKind = errors.New("invalid IPSet Kind")
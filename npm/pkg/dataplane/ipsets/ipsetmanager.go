@@ -51,6 +51,9 @@ type IPSetManager struct {
 	setMap     map[string]*IPSet
 	dirtyCache dirtyCacheInterface
 	ioShim     *common.IOShim
+	// unconfirmedMembers maps a set's prefixed name to members restored from a snapshot which haven't been added again since.
+	// It's nil unless the cache was restored via RestoreIPSets().
+	unconfirmedMembers map[string]map[string]struct{}
 	sync.RWMutex
 }
 
@@ -100,6 +103,7 @@ func (iMgr *IPSetManager) ResetIPSets() error {
 	err := iMgr.resetIPSets()
 	iMgr.setMap = make(map[string]*IPSet)
 	iMgr.emptySet = nil
+	iMgr.unconfirmedMembers = nil
 	iMgr.clearDirtyCache()
 	if err != nil {
 		metrics.SendErrorLogAndMetric(util.IpsmID, "error: failed to reset ipsetmanager: %s", err.Error())
@@ -259,6 +263,7 @@ func (iMgr *IPSetManager) AddToSets(addToSets []*IPSetMetadata, ip, podKey strin
 		}
 
 		// 2. add ip to the set, and update the pod key
+		iMgr.confirmMember(prefixedName, ip)
 		_, ok := set.IPPodKey[ip]
 		if !ok {
 			iMgr.modifyCacheForKernelMemberAdd(set, ip)
@@ -357,6 +362,7 @@ func (iMgr *IPSetManager) AddToLists(listMetadatas, setMetadatas []*IPSetMetadat
 				metrics.SendErrorLogAndMetric(util.IpsmID, "[AddToLists] warning: adding empty member name to list %s", list.Name)
 				continue
 			}
+			iMgr.confirmMember(list.Name, memberName)
 			// the member shouldn't be the list itself, but this is satisfied since we already asserted that the member is a HashSet
 			if list.hasMember(memberName) {
 				continue
@@ -482,6 +488,31 @@ func (iMgr *IPSetManager) GetAllIPSets() map[string]string {
 	return setMap
 }
 
+// GetNetPolReferencedIPSets returns the metadata of sets with the given type which are referenced by a network policy
+func (iMgr *IPSetManager) GetNetPolReferencedIPSets(setType SetType) []*IPSetMetadata {
+	iMgr.RLock()
+	defer iMgr.RUnlock()
+	sets := make([]*IPSetMetadata, 0)
+	for _, set := range iMgr.setMap {
+		if set.Type == setType && set.usedByNetPol() {
+			sets = append(sets, set.GetSetMetadata())
+		}
+	}
+	return sets
+}
+
+// IsMember returns true if the ip is a member of the hash set
+func (iMgr *IPSetManager) IsMember(setMetadata *IPSetMetadata, ip string) bool {
+	iMgr.RLock()
+	defer iMgr.RUnlock()
+	set, ok := iMgr.setMap[setMetadata.GetPrefixName()]
+	if !ok {
+		return false
+	}
+	_, ok = set.IPPodKey[ip]
+	return ok
+}
+
 func (iMgr *IPSetManager) exists(name string) bool {
 	_, ok := iMgr.setMap[name]
 	return ok
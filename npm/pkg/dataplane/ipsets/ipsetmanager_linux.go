@@ -354,7 +354,6 @@ example where every set in add/update cache should have ip 1.2.3.4 and 2.3.4.5:
 		-X set-to-delete3
 		-X set-to-delete1
 */
-// unused currently, but may be used later for reconciling with the kernel
 func (iMgr *IPSetManager) applyIPSetsWithSaveFile() error {
 	var saveFile []byte
 	var saveError error
@@ -372,6 +371,73 @@ func (iMgr *IPSetManager) applyIPSetsWithSaveFile() error {
 	return nil
 }
 
+// ApplyRestoredIPSets reconciles the kernel with a cache restored via RestoreIPSets().
+// Sets already in the kernel only have their extra members deleted and missing members added,
+// so traffic relying on unchanged members isn't interrupted.
+// NPM sets in the kernel which aren't in the cache are left alone. See DestroyStaleKernelIPSets().
+func (iMgr *IPSetManager) ApplyRestoredIPSets() error {
+	iMgr.Lock()
+	defer iMgr.Unlock()
+
+	prometheusTimer := metrics.StartNewTimer()
+	defer metrics.RecordIPSetExecTime(prometheusTimer) // record execution time regardless of failure
+	if err := iMgr.applyIPSetsWithSaveFile(); err != nil {
+		metrics.SendErrorLogAndMetric(util.IpsmID, "error: failed to apply restored ipsets: %s", err.Error())
+		return err
+	}
+	iMgr.clearDirtyCache()
+	return nil
+}
+
+// DestroyStaleKernelIPSets flushes and destroys NPM sets in the kernel which shouldn't be in the kernel according to the cache.
+// Should be called after ApplyRestoredIPSets() and after iptables no longer references stale sets.
+func (iMgr *IPSetManager) DestroyStaleKernelIPSets() error {
+	iMgr.Lock()
+	defer iMgr.Unlock()
+
+	listNamesCommand := iMgr.ioShim.Exec.Command(ipsetCommand, ipsetListFlag, ipsetNameFlag)
+	grepCommand := iMgr.ioShim.Exec.Command(ioutil.Grep, azureNPMPrefix)
+	klog.Infof("running this command while destroying stale ipsets: [%s %s %s | %s %s]", ipsetCommand, ipsetListFlag, ipsetNameFlag, ioutil.Grep, azureNPMRegex)
+	azureIPSets, haveAzureNPMIPSets, commandError := ioutil.PipeCommandToGrep(listNamesCommand, grepCommand)
+	if commandError != nil {
+		return npmerrors.SimpleErrorWrapper("failed to run ipset list for destroying stale IPSets", commandError)
+	}
+	if !haveAzureNPMIPSets {
+		return nil
+	}
+
+	desiredSets := iMgr.desiredKernelSets()
+	staleIPSets := make([]byte, 0, len(azureIPSets))
+	for _, hashedName := range strings.Split(string(azureIPSets), "\n") {
+		if hashedName == "" {
+			continue
+		}
+		if _, ok := desiredSets[hashedName]; ok {
+			continue
+		}
+		if len(staleIPSets) > 0 {
+			staleIPSets = append(staleIPSets, '\n')
+		}
+		staleIPSets = append(staleIPSets, hashedName...)
+	}
+	if len(staleIPSets) == 0 {
+		return nil
+	}
+
+	creator, names, failedNames := iMgr.fileCreatorForFlushAll(staleIPSets)
+	klog.Infof("[IPSetManager] destroying %d stale ipsets", len(names))
+	if err := creator.RunCommandWithFile(ipsetCommand, ipsetRestoreFlag); err != nil {
+		return npmerrors.SimpleErrorWrapper("failed to run ipset restore while flushing stale IPSets", err)
+	}
+
+	creator, destroyFailureCount := iMgr.fileCreatorForDestroyAll(names, failedNames, iMgr.setsWithReferences())
+	if err := creator.RunCommandWithFile(ipsetCommand, ipsetRestoreFlag); err != nil {
+		klog.Errorf("failed to destroy stale ipsets. destroyFailureCount %d. err: %v", *destroyFailureCount, err)
+		return npmerrors.SimpleErrorWrapper("failed to run ipset restore while destroying stale IPSets", err)
+	}
+	return nil
+}
+
 /*
 See error handling in applyIPSetsWithSaveFile().
 
@@ -737,7 +803,7 @@ func (iMgr *IPSetManager) createSetForApply(creator *ioutil.FileCreator, set *IP
 	}
 
 	specs := []string{ipsetCreateFlag, set.HashedName, ipsetExistFlag, methodFlag}
-	if set.Type == CIDRBlocks {
+	if set.Type == CIDRBlocks || set.Type == FQDNAddresses {
 		specs = append(specs, ipsetMaxelemName, ipsetMaxelemNum)
 	}
 
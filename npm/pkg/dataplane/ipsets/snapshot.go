@@ -0,0 +1,258 @@
+package ipsets
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/Azure/azure-container-networking/npm/metrics"
+	"k8s.io/klog"
+)
+
+var (
+	ErrDirtyCache      = errors.New("ipset cache has changes which aren't applied to the kernel")
+	ErrInvalidSnapshot = errors.New("invalid ipset snapshot")
+)
+
+// SetSnapshot is the serializable state of an IPSet in the cache.
+type SetSnapshot struct {
+	Metadata *IPSetMetadata
+	// IPPodKey holds the members of a HashSet and the keys of the pods which own them
+	IPPodKey map[string]string `json:",omitempty"`
+	// MemberSets holds the prefixed names of the members of a ListSet
+	MemberSets        []string `json:",omitempty"`
+	SelectorReference []string `json:",omitempty"`
+	NetPolReference   []string `json:",omitempty"`
+}
+
+// Snapshot returns the state of every set in the cache sorted by prefixed name.
+// It fails if the cache has changes which aren't applied to the kernel yet, so that a snapshot always describes the kernel.
+func (iMgr *IPSetManager) Snapshot() ([]*SetSnapshot, error) {
+	iMgr.RLock()
+	defer iMgr.RUnlock()
+
+	if iMgr.dirtyCache.numSetsToAddOrUpdate() > 0 || iMgr.dirtyCache.numSetsToDelete() > 0 {
+		return nil, ErrDirtyCache
+	}
+	return iMgr.snapshot(), nil
+}
+
+// CacheSnapshot is like Snapshot, but it includes changes which aren't applied to the kernel yet.
+// It's meant for debugging, where failing while policies are being applied isn't helpful.
+func (iMgr *IPSetManager) CacheSnapshot() []*SetSnapshot {
+	iMgr.RLock()
+	defer iMgr.RUnlock()
+	return iMgr.snapshot()
+}
+
+func (iMgr *IPSetManager) snapshot() []*SetSnapshot {
+	snapshots := make([]*SetSnapshot, 0, len(iMgr.setMap))
+	for _, set := range iMgr.setMap {
+		snapshot := &SetSnapshot{
+			Metadata:          set.GetSetMetadata(),
+			SelectorReference: sortedKeys(set.SelectorReference),
+			NetPolReference:   sortedKeys(set.NetPolReference),
+		}
+		if set.Kind == HashSet {
+			snapshot.IPPodKey = make(map[string]string, len(set.IPPodKey))
+			for ip, podKey := range set.IPPodKey {
+				snapshot.IPPodKey[ip] = podKey
+			}
+		} else {
+			snapshot.MemberSets = make([]string, 0, len(set.MemberIPSets))
+			for memberName := range set.MemberIPSets {
+				snapshot.MemberSets = append(snapshot.MemberSets, memberName)
+			}
+			sort.Strings(snapshot.MemberSets)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Metadata.GetPrefixName() < snapshots[j].Metadata.GetPrefixName()
+	})
+	return snapshots
+}
+
+// RestoreIPSets replaces the cache with the snapshots and marks every set which should be in the kernel as dirty (to be created).
+// Applying the dirty cache with the kernel's current sets in mind is OS-specific. In Linux, see ApplyRestoredIPSets().
+//
+// Members from the snapshots are unconfirmed until they're added again via AddToSets() or AddToLists().
+// Once the controllers have caught up, RemoveUnconfirmedMembers() removes members of pods and namespaces which were deleted in the meantime.
+// The cache is empty if an error is returned.
+func (iMgr *IPSetManager) RestoreIPSets(snapshots []*SetSnapshot) error {
+	iMgr.Lock()
+	defer iMgr.Unlock()
+
+	metrics.ResetNumIPSets()
+	metrics.ResetIPSetEntries()
+	iMgr.setMap = make(map[string]*IPSet, len(snapshots))
+	iMgr.emptySet = nil
+	iMgr.clearDirtyCache()
+	iMgr.unconfirmedMembers = make(map[string]map[string]struct{})
+
+	if err := iMgr.restoreSnapshots(snapshots); err != nil {
+		metrics.ResetNumIPSets()
+		metrics.ResetIPSetEntries()
+		iMgr.setMap = make(map[string]*IPSet)
+		iMgr.emptySet = nil
+		iMgr.unconfirmedMembers = nil
+		return err
+	}
+
+	// mark sets as dirty after all kernel reference counts are known
+	for _, set := range iMgr.setMap {
+		if iMgr.shouldBeInKernel(set) {
+			iMgr.modifyCacheForKernelCreation(set)
+		}
+	}
+	klog.Infof("[IPSetManager] restored %d ipsets from snapshot", len(iMgr.setMap))
+	return nil
+}
+
+func (iMgr *IPSetManager) restoreSnapshots(snapshots []*SetSnapshot) error {
+	// 1. create sets with their references and HashSet members
+	for _, snapshot := range snapshots {
+		if snapshot == nil || snapshot.Metadata == nil {
+			return fmt.Errorf("%w: missing set metadata", ErrInvalidSnapshot)
+		}
+		set := NewIPSet(snapshot.Metadata)
+		if _, ok := iMgr.setMap[set.Name]; ok {
+			return fmt.Errorf("%w: duplicate set %s", ErrInvalidSnapshot, set.Name)
+		}
+		iMgr.setMap[set.Name] = set
+		metrics.IncNumIPSets()
+		if set.Name == emptySetPrefixName {
+			iMgr.emptySet = set
+		}
+
+		for _, referenceName := range snapshot.SelectorReference {
+			set.addReference(referenceName, SelectorType)
+		}
+		for _, referenceName := range snapshot.NetPolReference {
+			set.addReference(referenceName, NetPolType)
+		}
+
+		if set.Kind != HashSet {
+			continue
+		}
+		for ip, podKey := range snapshot.IPPodKey {
+			set.IPPodKey[ip] = podKey
+			metrics.AddEntryToIPSet(set.Name)
+			iMgr.markUnconfirmed(set.Name, ip)
+		}
+	}
+
+	// 2. add members to lists now that all members exist
+	for _, snapshot := range snapshots {
+		list := iMgr.setMap[snapshot.Metadata.GetPrefixName()]
+		if list.Kind != ListSet {
+			if len(snapshot.MemberSets) > 0 {
+				return fmt.Errorf("%w: hash set %s has member sets", ErrInvalidSnapshot, list.Name)
+			}
+			continue
+		}
+		for _, memberName := range snapshot.MemberSets {
+			member, ok := iMgr.setMap[memberName]
+			if !ok || member.Kind != HashSet {
+				return fmt.Errorf("%w: list %s has missing or invalid member %s", ErrInvalidSnapshot, list.Name, memberName)
+			}
+			list.MemberIPSets[memberName] = member
+			member.incIPSetReferCount()
+			metrics.AddEntryToIPSet(list.Name)
+			if member != iMgr.emptySet {
+				iMgr.markUnconfirmed(list.Name, memberName)
+			}
+		}
+	}
+
+	// 3. count references from lists which should be in the kernel
+	for _, list := range iMgr.setMap {
+		if list.Kind != ListSet || !iMgr.shouldBeInKernel(list) {
+			continue
+		}
+		for _, member := range list.MemberIPSets {
+			member.incKernelReferCount()
+		}
+	}
+	return nil
+}
+
+// RemoveUnconfirmedMembers removes the members restored by RestoreIPSets() which haven't been added again since.
+// Sets are left in the cache even if they become empty. See Reconcile().
+func (iMgr *IPSetManager) RemoveUnconfirmedMembers() {
+	iMgr.Lock()
+	defer iMgr.Unlock()
+
+	numRemoved := 0
+	for setName, members := range iMgr.unconfirmedMembers {
+		set, ok := iMgr.setMap[setName]
+		if !ok {
+			continue
+		}
+		for member := range members {
+			if set.Kind == HashSet {
+				if _, ok := set.IPPodKey[member]; !ok {
+					continue
+				}
+				iMgr.modifyCacheForKernelMemberDelete(set, member)
+				delete(set.IPPodKey, member)
+			} else {
+				memberSet, ok := set.MemberIPSets[member]
+				if !ok {
+					continue
+				}
+				iMgr.modifyCacheForKernelMemberDelete(set, memberSet.HashedName)
+				delete(set.MemberIPSets, member)
+				memberSet.decIPSetReferCount()
+				if iMgr.shouldBeInKernel(set) {
+					iMgr.decKernelReferCountAndModifyCache(memberSet)
+				}
+			}
+			metrics.RemoveEntryFromIPSet(set.Name)
+			numRemoved++
+		}
+	}
+	iMgr.unconfirmedMembers = nil
+	if numRemoved > 0 {
+		klog.Infof("[IPSetManager] removed %d restored members which weren't added again", numRemoved)
+	}
+}
+
+func (iMgr *IPSetManager) markUnconfirmed(setName, member string) {
+	members, ok := iMgr.unconfirmedMembers[setName]
+	if !ok {
+		members = make(map[string]struct{})
+		iMgr.unconfirmedMembers[setName] = members
+	}
+	members[member] = struct{}{}
+}
+
+// confirmMember is a no-op unless the cache was restored and RemoveUnconfirmedMembers() hasn't been called yet
+func (iMgr *IPSetManager) confirmMember(setName, member string) {
+	if members, ok := iMgr.unconfirmedMembers[setName]; ok {
+		delete(members, member)
+	}
+}
+
+// desiredKernelSets returns the hashed names of sets which should be in the kernel
+func (iMgr *IPSetManager) desiredKernelSets() map[string]struct{} {
+	hashedNames := make(map[string]struct{}, len(iMgr.setMap))
+	for _, set := range iMgr.setMap {
+		if iMgr.shouldBeInKernel(set) {
+			hashedNames[set.HashedName] = struct{}{}
+		}
+	}
+	return hashedNames
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
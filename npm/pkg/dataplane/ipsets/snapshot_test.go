@@ -0,0 +1,135 @@
+package ipsets
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-container-networking/common"
+	"github.com/Azure/azure-container-networking/npm/metrics"
+	testutils "github.com/Azure/azure-container-networking/test/utils"
+	"github.com/stretchr/testify/require"
+)
+
+const testPodIP2 = "10.0.0.1"
+
+// snapshotOfTestCache returns a snapshot of a cache with a referenced list containing a HashSet with two members
+func snapshotOfTestCache(t *testing.T) []*SetSnapshot {
+	iMgr := NewIPSetManager(applyOnNeedCfg, common.NewMockIOShim([]testutils.TestCmd{}))
+	require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{namespaceSet}, testPodIP, testPodKey))
+	require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{namespaceSet}, testPodIP2, testPodKey))
+	require.NoError(t, iMgr.AddToLists([]*IPSetMetadata{list}, []*IPSetMetadata{namespaceSet}))
+	require.NoError(t, iMgr.AddReference(list, testNetPolKey, NetPolType))
+	// pretend the dirty cache was applied
+	iMgr.clearDirtyCache()
+
+	snapshots, err := iMgr.Snapshot()
+	require.NoError(t, err)
+	return snapshots
+}
+
+func TestSnapshotWithDirtyCache(t *testing.T) {
+	iMgr := NewIPSetManager(applyAlwaysCfg, common.NewMockIOShim([]testutils.TestCmd{}))
+	iMgr.CreateIPSets([]*IPSetMetadata{namespaceSet})
+
+	_, err := iMgr.Snapshot()
+	require.ErrorIs(t, err, ErrDirtyCache)
+}
+
+func TestSnapshot(t *testing.T) {
+	metrics.ReinitializeAll()
+	snapshots := snapshotOfTestCache(t)
+
+	expected := []*SetSnapshot{
+		{
+			Metadata: namespaceSet,
+			IPPodKey: map[string]string{testPodIP: testPodKey, testPodIP2: testPodKey},
+		},
+		{
+			Metadata:        list,
+			MemberSets:      []string{namespaceSet.GetPrefixName()},
+			NetPolReference: []string{testNetPolKey},
+		},
+	}
+	require.Equal(t, expected, snapshots)
+}
+
+func TestRestoreIPSets(t *testing.T) {
+	metrics.ReinitializeAll()
+	snapshots := snapshotOfTestCache(t)
+
+	iMgr := NewIPSetManager(applyOnNeedCfg, common.NewMockIOShim([]testutils.TestCmd{}))
+	require.NoError(t, iMgr.RestoreIPSets(snapshots))
+
+	assertExpectedInfo(t, iMgr, &expectedInfo{
+		mainCache: []setMembers{
+			{metadata: namespaceSet, members: []member{{testPodIP, isHashMember}, {testPodIP2, isHashMember}}},
+			{metadata: list, members: []member{{namespaceSet.GetPrefixName(), isSetMember}}, netPolReferences: []string{testNetPolKey}},
+		},
+		toAddUpdateCache: []*IPSetMetadata{namespaceSet, list},
+		setsForKernel:    []*IPSetMetadata{namespaceSet, list},
+	})
+	require.Equal(t, 1, iMgr.setMap[namespaceSet.GetPrefixName()].kernelReferCount)
+	require.Equal(t, 1, iMgr.setMap[namespaceSet.GetPrefixName()].ipsetReferCount)
+}
+
+func TestRestoreInvalidIPSets(t *testing.T) {
+	metrics.ReinitializeAll()
+	snapshots := []*SetSnapshot{
+		{
+			Metadata:   list,
+			MemberSets: []string{namespaceSet.GetPrefixName()},
+		},
+	}
+
+	iMgr := NewIPSetManager(applyOnNeedCfg, common.NewMockIOShim([]testutils.TestCmd{}))
+	require.ErrorIs(t, iMgr.RestoreIPSets(snapshots), ErrInvalidSnapshot)
+	assertExpectedInfo(t, iMgr, &expectedInfo{})
+}
+
+func TestRemoveUnconfirmedMembers(t *testing.T) {
+	metrics.ReinitializeAll()
+	snapshots := snapshotOfTestCache(t)
+
+	iMgr := NewIPSetManager(applyOnNeedCfg, common.NewMockIOShim([]testutils.TestCmd{}))
+	require.NoError(t, iMgr.RestoreIPSets(snapshots))
+	// pretend the restored sets were applied
+	iMgr.clearDirtyCache()
+
+	// the controllers add back one pod IP and the namespace
+	require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{namespaceSet}, testPodIP, testPodKey))
+	require.NoError(t, iMgr.AddToLists([]*IPSetMetadata{list}, []*IPSetMetadata{namespaceSet}))
+
+	iMgr.RemoveUnconfirmedMembers()
+	assertExpectedInfo(t, iMgr, &expectedInfo{
+		mainCache: []setMembers{
+			{metadata: namespaceSet, members: []member{{testPodIP, isHashMember}}},
+			{metadata: list, members: []member{{namespaceSet.GetPrefixName(), isSetMember}}, netPolReferences: []string{testNetPolKey}},
+		},
+		toAddUpdateCache: []*IPSetMetadata{namespaceSet},
+		setsForKernel:    []*IPSetMetadata{namespaceSet},
+	})
+
+	// later changes aren't tracked
+	require.Nil(t, iMgr.unconfirmedMembers)
+}
+
+func TestRemoveUnconfirmedListMembers(t *testing.T) {
+	metrics.ReinitializeAll()
+	snapshots := snapshotOfTestCache(t)
+
+	iMgr := NewIPSetManager(applyOnNeedCfg, common.NewMockIOShim([]testutils.TestCmd{}))
+	require.NoError(t, iMgr.RestoreIPSets(snapshots))
+	iMgr.clearDirtyCache()
+
+	// the namespace was deleted while NPM was down, so the list member isn't added back
+	iMgr.RemoveUnconfirmedMembers()
+	assertExpectedInfo(t, iMgr, &expectedInfo{
+		mainCache: []setMembers{
+			{metadata: namespaceSet},
+			{metadata: list, netPolReferences: []string{testNetPolKey}},
+		},
+		// the namespace set is no longer referenced by a set in the kernel, so it's deleted from the kernel
+		toAddUpdateCache: []*IPSetMetadata{list},
+		toDeleteCache:    []string{namespaceSet.GetPrefixName()},
+		setsForKernel:    []*IPSetMetadata{list},
+	})
+}
@@ -23,6 +23,9 @@ type Rule struct {
 	Protocol string
 	Target   *Target
 	Modules  []*Module
+	// Packets and Bytes are only set when parsing the output of iptables-save with counters (-c)
+	Packets uint64
+	Bytes   uint64
 }
 
 // Module struct
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 
 	"github.com/Azure/azure-container-networking/common"
@@ -24,6 +25,8 @@ var (
 	SpaceBytes = []byte(" ")
 	// MinOptionLength indicates the minimum length of an option
 	MinOptionLength = 2
+
+	errUnexpectedCounters = errors.New("unexpected counters in iptables-save output")
 )
 
 type IPTablesParser struct {
@@ -63,6 +66,20 @@ func (i *IPTablesParser) Iptables(tableName string) (*NPMIPtable.Table, error) {
 	return &NPMIPtable.Table{Name: tableName, Chains: chains}, nil
 }
 
+// IptablesWithCounters creates a Go object from specified iptable by calling iptables-save within node.
+// Each rule also has its packet and byte counters.
+func (i *IPTablesParser) IptablesWithCounters(tableName string) (*NPMIPtable.Table, error) {
+	cmdArgs := []string{util.IptablesCountersFlag, util.IptablesTableFlag, string(tableName)}
+
+	output, err := i.runCommand(util.IptablesSave, cmdArgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	chains := parseIptablesChainObject(tableName, output)
+	return &NPMIPtable.Table{Name: tableName, Chains: chains}, nil
+}
+
 // Iptables creates a Go object from specified iptable by calling iptables-save within node.
 func Iptables(tableName string) (*NPMIPtable.Table, error) {
 	iptableBuffer := bytes.NewBuffer(nil)
@@ -135,19 +152,53 @@ func parseIptablesChainObject(tableName string, iptableBuffer []byte) map[string
 			} else {
 				chainMap[chainName] = &NPMIPtable.Chain{Name: chainName, Data: line, Rules: make([]*NPMIPtable.Rule, 0)}
 			}
-		} else if line[0] == '-' && len(line) > 1 {
-			// rules
+		} else if (line[0] == '-' || line[0] == '[') && len(line) > 1 {
+			// rules, which are prefixed with [packets:bytes] when saved with counters
+			var packets, byteCount uint64
+			if line[0] == '[' {
+				var err error
+				packets, byteCount, line, err = parseCounters(line)
+				if err != nil {
+					klog.Errorf("skipping rule: %s", err.Error())
+					continue
+				}
+			}
 			chainName, ruleStartIndex := parseChainNameFromRuleLine(line)
 			iptableChain, ok := chainMap[chainName]
 			if !ok {
 				iptableChain = &NPMIPtable.Chain{Name: chainName, Data: []byte{}, Rules: make([]*NPMIPtable.Rule, 0)}
 			}
-			iptableChain.Rules = append(iptableChain.Rules, parseRuleFromLine(line[ruleStartIndex:]))
+			rule := parseRuleFromLine(line[ruleStartIndex:])
+			rule.Packets = packets
+			rule.Bytes = byteCount
+			iptableChain.Rules = append(iptableChain.Rules, rule)
 		}
 	}
 	return chainMap
 }
 
+// parseCounters parses the [packets:bytes] prefix of a rule line saved with counters.
+// Returns the counters and the rest of the line.
+func parseCounters(ruleLine []byte) (packets, byteCount uint64, rest []byte, err error) {
+	end := bytes.IndexByte(ruleLine, ']')
+	if end == -1 || end+2 > len(ruleLine) {
+		return 0, 0, nil, fmt.Errorf("%w: %s", errUnexpectedCounters, string(ruleLine))
+	}
+	packetsString, bytesString, found := strings.Cut(string(ruleLine[1:end]), ":")
+	if !found {
+		return 0, 0, nil, fmt.Errorf("%w: %s", errUnexpectedCounters, string(ruleLine))
+	}
+	packets, err = strconv.ParseUint(packetsString, 10, 64)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("%w: %s", errUnexpectedCounters, string(ruleLine))
+	}
+	byteCount, err = strconv.ParseUint(bytesString, 10, 64)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("%w: %s", errUnexpectedCounters, string(ruleLine))
+	}
+	return packets, byteCount, ruleLine[end+2:], nil
+}
+
 // Line parses the line starting from the given readIndex of the iptableBuffer.
 // Returns a slice of line starting from given read index and the next index to read from.
 func Line(readIndex int, iptableBuffer []byte) ([]byte, int) {
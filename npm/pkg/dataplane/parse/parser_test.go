@@ -43,6 +43,77 @@ func TestParseIptablesObject(t *testing.T) {
 	}
 }
 
+func TestParseIptablesObjectWithCounters(t *testing.T) {
+	calls := []testutils.TestCmd{
+		{
+			Cmd: []string{"iptables-save", "-c", "-t", "filter"},
+			Stdout: "*filter\n" +
+				":AZURE-NPM - [0:0]\n" +
+				":AZURE-NPM-ACCEPT - [0:0]\n" +
+				"[12:3456] -A AZURE-NPM -j AZURE-NPM-ACCEPT\n" +
+				"[bad] -A AZURE-NPM -j AZURE-NPM-ACCEPT\n" +
+				"-A AZURE-NPM-ACCEPT -j ACCEPT\n" +
+				"COMMIT\n",
+		},
+	}
+
+	parser := IPTablesParser{
+		IOShim: common.NewMockIOShim(calls),
+	}
+
+	table, err := parser.IptablesWithCounters(util.IptablesFilterTable)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rules := table.Chains["AZURE-NPM"].Rules
+	if len(rules) != 1 {
+		t.Fatalf("got %d rules in AZURE-NPM, expected 1", len(rules))
+	}
+	if rules[0].Packets != 12 || rules[0].Bytes != 3456 || rules[0].Target.Name != "AZURE-NPM-ACCEPT" {
+		t.Errorf("got '%+v', expected 12 packets and 3456 bytes for a jump to AZURE-NPM-ACCEPT", rules[0])
+	}
+
+	rules = table.Chains["AZURE-NPM-ACCEPT"].Rules
+	if len(rules) != 1 || rules[0].Packets != 0 || rules[0].Bytes != 0 {
+		t.Errorf("got '%+v', expected one rule without counters in AZURE-NPM-ACCEPT", rules)
+	}
+}
+
+func TestParseCounters(t *testing.T) {
+	tests := []struct {
+		input   string
+		packets uint64
+		bytes   uint64
+		rest    string
+		wantErr bool
+	}{
+		{input: "[0:0] -A AZURE-NPM -j AZURE-NPM-ACCEPT", rest: "-A AZURE-NPM -j AZURE-NPM-ACCEPT"},
+		{input: "[5:420] -A AZURE-NPM -j AZURE-NPM-ACCEPT", packets: 5, bytes: 420, rest: "-A AZURE-NPM -j AZURE-NPM-ACCEPT"},
+		{input: "[5] -A AZURE-NPM", wantErr: true},
+		{input: "[5:x] -A AZURE-NPM", wantErr: true},
+		{input: "[5:420", wantErr: true},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.input, func(t *testing.T) {
+			packets, bytes, rest, err := parseCounters([]byte(tc.input))
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected error for '%s'", tc.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if packets != tc.packets || bytes != tc.bytes || string(rest) != tc.rest {
+				t.Errorf("got (%d, %d, '%s'), expected (%d, %d, '%s')", packets, bytes, rest, tc.packets, tc.bytes, tc.rest)
+			}
+		})
+	}
+}
+
 func TestParseLine(t *testing.T) {
 	type test struct {
 		input    string
@@ -174,14 +174,7 @@ func (pMgr *PolicyManager) bootup(_ []string) error {
 	defer pMgr.reconcileManager.forceUnlock()
 
 	// 1. delete the deprecated jump to AZURE-NPM
-	deprecatedErrCode, deprecatedErr := pMgr.ignoreErrorsAndRunIPTablesCommand(removeDeprecatedJumpIgnoredErrors, util.IptablesDeletionFlag, deprecatedJumpFromForwardToAzureChainArgs...)
-	if deprecatedErrCode == 0 {
-		klog.Infof("deleted deprecated jump rule from FORWARD chain to AZURE-NPM chain")
-	} else if deprecatedErr != nil {
-		metrics.SendErrorLogAndMetric(util.IptmID,
-			"failed to delete deprecated jump rule from FORWARD chain to AZURE-NPM chain for unexpected reason with exit code %d and error: %s",
-			deprecatedErrCode, deprecatedErr.Error())
-	}
+	pMgr.deleteDeprecatedJump()
 
 	currentChains, err := ioutil.AllCurrentAzureChains(pMgr.ioShim.Exec, util.IptablesDefaultWaitTime)
 	if err != nil {
@@ -193,6 +186,7 @@ func (pMgr *PolicyManager) bootup(_ []string) error {
 	if err := restore(creator); err != nil {
 		return npmerrors.SimpleErrorWrapper("failed to run iptables-restore for bootup", err)
 	}
+	pMgr.kernelModel.reset()
 
 	// 3. add/reposition the jump to AZURE-NPM
 	if err := pMgr.positionAzureChainJumpRule(); err != nil {
@@ -203,6 +197,17 @@ func (pMgr *PolicyManager) bootup(_ []string) error {
 	return nil
 }
 
+func (pMgr *PolicyManager) deleteDeprecatedJump() {
+	deprecatedErrCode, deprecatedErr := pMgr.ignoreErrorsAndRunIPTablesCommand(removeDeprecatedJumpIgnoredErrors, util.IptablesDeletionFlag, deprecatedJumpFromForwardToAzureChainArgs...)
+	if deprecatedErrCode == 0 {
+		klog.Infof("deleted deprecated jump rule from FORWARD chain to AZURE-NPM chain")
+	} else if deprecatedErr != nil {
+		metrics.SendErrorLogAndMetric(util.IptmID,
+			"failed to delete deprecated jump rule from FORWARD chain to AZURE-NPM chain for unexpected reason with exit code %d and error: %s",
+			deprecatedErrCode, deprecatedErr.Error())
+	}
+}
+
 // reconcile does the following:
 // - creates the jump rule from FORWARD chain to AZURE-NPM chain (if it does not exist) and makes sure it's after the jumps to KUBE-FORWARD & KUBE-SERVICES chains (if they exist).
 // - cleans up stale policy chains. It can be forced to stop this process if reconcileManager.forceLock() is called.
@@ -318,19 +323,42 @@ func (pMgr *PolicyManager) creatorForBootup(currentChains map[string]struct{}) *
 	}
 
 	// add AZURE-NPM-INGRESS chain rules
-	ingressDropSpecs := []string{util.IptablesAppendFlag, util.IptablesAzureIngressChain, util.IptablesJumpFlag, util.IptablesDrop}
-	ingressDropSpecs = append(ingressDropSpecs, onMarkSpecs(util.IptablesAzureIngressDropMarkHex)...)
-	ingressDropSpecs = append(ingressDropSpecs, commentSpecs(fmt.Sprintf("DROP-ON-INGRESS-DROP-MARK-%s", util.IptablesAzureIngressDropMarkHex))...)
-	creator.AddLine("", nil, ingressDropSpecs...)
+	writeIngressChainBaseRules(creator)
 
 	// add AZURE-NPM-INGRESS-ALLOW-MARK chain
+	writeIngressAllowMarkChainRules(creator)
+
+	// add AZURE-NPM-EGRESS chain rules
+	writeEgressChainBaseRules(creator)
+
+	// add AZURE-NPM-ACCEPT chain rules
+	writeAcceptChainRules(creator)
+	creator.AddLine("", nil, util.IptablesRestoreCommit)
+	return creator
+}
+
+func writeIngressAllowMarkChainRules(creator *ioutil.FileCreator) {
 	markIngressAllowSpecs := []string{util.IptablesAppendFlag, util.IptablesAzureIngressAllowMarkChain}
 	markIngressAllowSpecs = append(markIngressAllowSpecs, setMarkSpecs(util.IptablesAzureIngressAllowMarkHex)...)
 	markIngressAllowSpecs = append(markIngressAllowSpecs, commentSpecs(fmt.Sprintf("SET-INGRESS-ALLOW-MARK-%s", util.IptablesAzureIngressAllowMarkHex))...)
 	creator.AddLine("", nil, markIngressAllowSpecs...)
 	creator.AddLine("", nil, util.IptablesAppendFlag, util.IptablesAzureIngressAllowMarkChain, util.IptablesJumpFlag, util.IptablesAzureEgressChain)
+}
 
-	// add AZURE-NPM-EGRESS chain rules
+func writeAcceptChainRules(creator *ioutil.FileCreator) {
+	creator.AddLine("", nil, util.IptablesAppendFlag, util.IptablesAzureAcceptChain, util.IptablesJumpFlag, util.IptablesAccept)
+}
+
+// appends the rules which come after the jumps to policy chains in the AZURE-NPM-INGRESS chain
+func writeIngressChainBaseRules(creator *ioutil.FileCreator) {
+	ingressDropSpecs := []string{util.IptablesAppendFlag, util.IptablesAzureIngressChain, util.IptablesJumpFlag, util.IptablesDrop}
+	ingressDropSpecs = append(ingressDropSpecs, onMarkSpecs(util.IptablesAzureIngressDropMarkHex)...)
+	ingressDropSpecs = append(ingressDropSpecs, commentSpecs(fmt.Sprintf("DROP-ON-INGRESS-DROP-MARK-%s", util.IptablesAzureIngressDropMarkHex))...)
+	creator.AddLine("", nil, ingressDropSpecs...)
+}
+
+// appends the rules which come after the jumps to policy chains in the AZURE-NPM-EGRESS chain
+func writeEgressChainBaseRules(creator *ioutil.FileCreator) {
 	egressDropSpecs := []string{util.IptablesAppendFlag, util.IptablesAzureEgressChain, util.IptablesJumpFlag, util.IptablesDrop}
 	egressDropSpecs = append(egressDropSpecs, onMarkSpecs(util.IptablesAzureEgressDropMarkHex)...)
 	egressDropSpecs = append(egressDropSpecs, commentSpecs(fmt.Sprintf("DROP-ON-EGRESS-DROP-MARK-%s", util.IptablesAzureEgressDropMarkHex))...)
@@ -340,11 +368,6 @@ func (pMgr *PolicyManager) creatorForBootup(currentChains map[string]struct{}) *
 	jumpOnIngressMatchSpecs = append(jumpOnIngressMatchSpecs, onMarkSpecs(util.IptablesAzureIngressAllowMarkHex)...)
 	jumpOnIngressMatchSpecs = append(jumpOnIngressMatchSpecs, commentSpecs(fmt.Sprintf("ACCEPT-ON-INGRESS-ALLOW-MARK-%s", util.IptablesAzureIngressAllowMarkHex))...)
 	creator.AddLine("", nil, jumpOnIngressMatchSpecs...)
-
-	// add AZURE-NPM-ACCEPT chain rules
-	creator.AddLine("", nil, util.IptablesAppendFlag, util.IptablesAzureAcceptChain, util.IptablesJumpFlag, util.IptablesAccept)
-	creator.AddLine("", nil, util.IptablesRestoreCommit)
-	return creator
 }
 
 // add/reposition the jump from FORWARD chain to AZURE-NPM chain to be in the correct position based on config:
@@ -0,0 +1,113 @@
+package policies
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-container-networking/npm/metrics"
+	NPMIPtable "github.com/Azure/azure-container-networking/npm/pkg/dataplane/iptables"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/parse"
+	"github.com/Azure/azure-container-networking/npm/util"
+	"k8s.io/klog"
+)
+
+// updatePolicyHitCounts reads the counters of the rules in each policy chain and
+// attributes them to policy keys using the comments of the jump rules to the policy chains.
+func (pMgr *PolicyManager) updatePolicyHitCounts() error {
+	parser := &parse.IPTablesParser{IOShim: pMgr.ioShim}
+	table, err := parser.IptablesWithCounters(util.IptablesFilterTable)
+	if err != nil {
+		return fmt.Errorf("failed to read iptables counters: %w", err)
+	}
+
+	metrics.SetPolicyHitCounts(policyHitCounts(table))
+	return nil
+}
+
+func policyHitCounts(table *NPMIPtable.Table) []*metrics.PolicyHitCount {
+	counts := make([]*metrics.PolicyHitCount, 0)
+	counts = append(counts, policyHitCountsForDirection(table, util.IptablesAzureIngressChain, forIngress)...)
+	counts = append(counts, policyHitCountsForDirection(table, util.IptablesAzureEgressChain, forEgress)...)
+	return counts
+}
+
+func policyHitCountsForDirection(table *NPMIPtable.Table, baseChainName string, direction UniqueDirection) []*metrics.PolicyHitCount {
+	baseChain, ok := table.Chains[baseChainName]
+	if !ok {
+		return nil
+	}
+
+	metricDirection := metrics.EgressDirection
+	chainPrefix := util.IptablesAzureEgressPolicyChainPrefix
+	if direction == forIngress {
+		metricDirection = metrics.IngressDirection
+		chainPrefix = util.IptablesAzureIngressPolicyChainPrefix
+	}
+
+	counts := make([]*metrics.PolicyHitCount, 0)
+	for _, jumpRule := range baseChain.Rules {
+		if jumpRule.Target == nil || !strings.HasPrefix(jumpRule.Target.Name, chainPrefix+"-") {
+			continue
+		}
+		policyKey, ok := policyKeyFromJumpComment(ruleComment(jumpRule), direction)
+		if !ok || joinWithDash(chainPrefix, util.Hash(policyKey)) != jumpRule.Target.Name {
+			klog.Infof("[PolicyManager] skipping hit counts for chain %s without a policy comment", jumpRule.Target.Name)
+			continue
+		}
+		policyChain, ok := table.Chains[jumpRule.Target.Name]
+		if !ok {
+			continue
+		}
+
+		allowed := &metrics.PolicyHitCount{PolicyKey: policyKey, Direction: metricDirection, Verdict: metrics.AllowVerdict}
+		dropped := &metrics.PolicyHitCount{PolicyKey: policyKey, Direction: metricDirection, Verdict: metrics.DropVerdict}
+		for _, rule := range policyChain.Rules {
+			if rule.Target == nil {
+				continue
+			}
+			switch rule.Target.Name {
+			case util.IptablesAzureIngressAllowMarkChain, util.IptablesAzureAcceptChain:
+				allowed.Packets += rule.Packets
+				allowed.Bytes += rule.Bytes
+			case util.IptablesMark:
+				dropped.Packets += rule.Packets
+				dropped.Bytes += rule.Bytes
+			}
+		}
+		counts = append(counts, allowed, dropped)
+	}
+	return counts
+}
+
+// policyKeyFromJumpComment parses the policy key from a comment created by commentForJump().
+// Kubernetes object names are lowercase, so the uppercase separators can't appear within the policy key.
+func policyKeyFromJumpComment(comment string, direction UniqueDirection) (string, bool) {
+	prefix := "EGRESS-POLICY-"
+	separator := "-FROM-"
+	if direction == forIngress {
+		prefix = "INGRESS-POLICY-"
+		separator = "-TO-"
+	}
+
+	comment = strings.Trim(comment, "\"")
+	if !strings.HasPrefix(comment, prefix) {
+		return "", false
+	}
+	policyKey, _, found := strings.Cut(strings.TrimPrefix(comment, prefix), separator)
+	if !found || policyKey == "" {
+		return "", false
+	}
+	return policyKey, true
+}
+
+func ruleComment(rule *NPMIPtable.Rule) string {
+	for _, module := range rule.Modules {
+		if module.Verb != util.IptablesCommentModuleFlag {
+			continue
+		}
+		if values := module.OptionValueMap[util.IptablesCommentModuleFlag]; len(values) > 0 {
+			return strings.Join(values, " ")
+		}
+	}
+	return ""
+}
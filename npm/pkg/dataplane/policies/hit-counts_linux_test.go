@@ -0,0 +1,99 @@
+package policies
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/common"
+	"github.com/Azure/azure-container-networking/npm/metrics"
+	testutils "github.com/Azure/azure-container-networking/test/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdatePolicyHitCounts(t *testing.T) {
+	metrics.ReinitializeAll()
+
+	iptablesSaveWithCounters := strings.Join([]string{
+		"*filter",
+		":AZURE-NPM-INGRESS - [0:0]",
+		":AZURE-NPM-EGRESS - [0:0]",
+		fmt.Sprintf(":%s - [0:0]", bothDirectionsNetPolIngressChain),
+		fmt.Sprintf(":%s - [0:0]", bothDirectionsNetPolEgressChain),
+		fmt.Sprintf(":%s - [0:0]", egressNetPolChain),
+		":AZURE-NPM-INGRESS-123456 - [0:0]",
+		"[20:2000] -A AZURE-NPM-INGRESS " + ingressEgressNetPolIngressJump,
+		// a jump without a policy comment isn't attributed
+		"[99:9900] -A AZURE-NPM-INGRESS -j AZURE-NPM-INGRESS-123456",
+		"[30:3000] -A AZURE-NPM-EGRESS " + ingressEgressNetPolEgressJump,
+		"[7:700] -A AZURE-NPM-EGRESS " + egressNetPolJump,
+		fmt.Sprintf("[3:300] -A %s %s", bothDirectionsNetPolIngressChain, ingressDropRule),
+		fmt.Sprintf("[15:1500] -A %s %s", bothDirectionsNetPolIngressChain, ingressAllowRule),
+		fmt.Sprintf("[4:400] -A %s %s", bothDirectionsNetPolEgressChain, egressDropRule),
+		fmt.Sprintf("[25:2500] -A %s %s", bothDirectionsNetPolEgressChain, egressAllowRule),
+		fmt.Sprintf("[7:700] -A %s %s", egressNetPolChain, egressAllowRule),
+		"[99:9900] -A AZURE-NPM-INGRESS-123456 -j AZURE-NPM-INGRESS-ALLOW-MARK",
+		"COMMIT",
+		"",
+	}, "\n")
+	calls := []testutils.TestCmd{{Cmd: []string{"iptables-save", "-c", "-t", "filter"}, Stdout: iptablesSaveWithCounters}}
+	ioshim := common.NewMockIOShim(calls)
+	defer ioshim.VerifyCalls(t, calls)
+	pMgr := NewPolicyManager(ioshim, ipsetConfig)
+
+	require.NoError(t, pMgr.updatePolicyHitCounts())
+
+	tests := []struct {
+		policyKey string
+		direction string
+		verdict   string
+		packets   int
+		bytes     int
+	}{
+		{bothDirectionsNetPol.PolicyKey, metrics.IngressDirection, metrics.AllowVerdict, 15, 1500},
+		{bothDirectionsNetPol.PolicyKey, metrics.IngressDirection, metrics.DropVerdict, 3, 300},
+		{bothDirectionsNetPol.PolicyKey, metrics.EgressDirection, metrics.AllowVerdict, 25, 2500},
+		{bothDirectionsNetPol.PolicyKey, metrics.EgressDirection, metrics.DropVerdict, 4, 400},
+		{egressNetPol.PolicyKey, metrics.EgressDirection, metrics.AllowVerdict, 7, 700},
+		{egressNetPol.PolicyKey, metrics.EgressDirection, metrics.DropVerdict, 0, 0},
+	}
+	for _, tt := range tests {
+		packets, err := metrics.GetPolicyHitPackets(tt.policyKey, tt.direction, tt.verdict)
+		require.NoError(t, err)
+		require.Equal(t, tt.packets, packets, "wrong packets for %s %s %s", tt.policyKey, tt.direction, tt.verdict)
+		bytes, err := metrics.GetPolicyHitBytes(tt.policyKey, tt.direction, tt.verdict)
+		require.NoError(t, err)
+		require.Equal(t, tt.bytes, bytes, "wrong bytes for %s %s %s", tt.policyKey, tt.direction, tt.verdict)
+	}
+}
+
+func TestUpdatePolicyHitCountsFailure(t *testing.T) {
+	metrics.ReinitializeAll()
+	calls := []testutils.TestCmd{{Cmd: []string{"iptables-save", "-c", "-t", "filter"}, ExitCode: 1}}
+	ioshim := common.NewMockIOShim(calls)
+	defer ioshim.VerifyCalls(t, calls)
+	pMgr := NewPolicyManager(ioshim, ipsetConfig)
+
+	require.Error(t, pMgr.updatePolicyHitCounts())
+}
+
+func TestPolicyKeyFromJumpComment(t *testing.T) {
+	tests := []struct {
+		comment   string
+		direction UniqueDirection
+		policyKey string
+		ok        bool
+	}{
+		{bothDirectionsNetPolIngressJumpComment, forIngress, "x/test1", true},
+		{bothDirectionsNetPolEgressJumpComment, forEgress, "x/test1", true},
+		{`"` + egressNetPolJumpComment + `"`, forEgress, "z/test3", true},
+		{egressNetPolJumpComment, forIngress, "", false},
+		{"INGRESS-POLICY-", forIngress, "", false},
+		{"", forEgress, "", false},
+	}
+	for _, tt := range tests {
+		policyKey, ok := policyKeyFromJumpComment(tt.comment, tt.direction)
+		require.Equal(t, tt.ok, ok, tt.comment)
+		require.Equal(t, tt.policyKey, policyKey, tt.comment)
+	}
+}
@@ -2,6 +2,7 @@ package policies
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/Azure/azure-container-networking/common"
@@ -38,6 +39,8 @@ type PolicyManagerCfg struct {
 type PolicyMap struct {
 	sync.RWMutex
 	cache map[string]*NPMNetworkPolicy
+	// unconfirmed holds the keys of policies restored by BootupWithCache() which haven't been added or updated since
+	unconfirmed map[string]struct{}
 }
 
 type reconcileManager struct {
@@ -55,6 +58,7 @@ type PolicyManager struct {
 	policyMap        *PolicyMap
 	ioShim           *common.IOShim
 	staleChains      *staleChains
+	kernelModel      *kernelModel
 	reconcileManager *reconcileManager
 	*PolicyManagerCfg
 }
@@ -66,6 +70,7 @@ func NewPolicyManager(ioShim *common.IOShim, cfg *PolicyManagerCfg) *PolicyManag
 		},
 		ioShim:      ioShim,
 		staleChains: newStaleChains(),
+		kernelModel: newKernelModel(),
 		reconcileManager: &reconcileManager{
 			releaseLockSignal: make(chan struct{}, 1),
 		},
@@ -92,6 +97,14 @@ func (pMgr *PolicyManager) Reconcile() {
 	pMgr.reconcile()
 }
 
+// UpdatePolicyHitCounts exports the number of packets and bytes matched by each policy's allow and drop rules as Prometheus metrics.
+// In Windows, does nothing.
+func (pMgr *PolicyManager) UpdatePolicyHitCounts() {
+	if err := pMgr.updatePolicyHitCounts(); err != nil {
+		metrics.SendErrorLogAndMetric(util.IptmID, "error: failed to update policy hit counts: %s", err.Error())
+	}
+}
+
 func (pMgr *PolicyManager) PolicyExists(policyKey string) bool {
 	pMgr.policyMap.RLock()
 	defer pMgr.policyMap.RUnlock()
@@ -108,6 +121,21 @@ func (pMgr *PolicyManager) GetPolicy(policyKey string) (*NPMNetworkPolicy, bool)
 	return policy, ok
 }
 
+// GetAllPolicies returns the cached policies sorted by policy key
+func (pMgr *PolicyManager) GetAllPolicies() []*NPMNetworkPolicy {
+	pMgr.policyMap.RLock()
+	defer pMgr.policyMap.RUnlock()
+
+	policies := make([]*NPMNetworkPolicy, 0, len(pMgr.policyMap.cache))
+	for _, policy := range pMgr.policyMap.cache {
+		policies = append(policies, policy)
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].PolicyKey < policies[j].PolicyKey
+	})
+	return policies
+}
+
 func (pMgr *PolicyManager) AddPolicy(policy *NPMNetworkPolicy, endpointList map[string]string) error {
 	if len(policy.ACLs) == 0 {
 		klog.Infof("[DataPlane] No ACLs in policy %s to apply", policy.PolicyKey)
@@ -144,6 +172,50 @@ func (pMgr *PolicyManager) AddPolicy(policy *NPMNetworkPolicy, endpointList map[
 	metrics.IncNumACLRulesBy(policy.numACLRulesProducedInKernel() * numEndpoints)
 
 	pMgr.policyMap.cache[policy.PolicyKey] = policy
+	delete(pMgr.policyMap.unconfirmed, policy.PolicyKey)
+	return nil
+}
+
+// UpdatePolicy replaces the cached policy with the same key and applies the difference in rules.
+// The policy is added if it doesn't exist yet.
+// This function is intended for Linux only.
+func (pMgr *PolicyManager) UpdatePolicy(policy *NPMNetworkPolicy) error {
+	oldPolicy, ok := pMgr.GetPolicy(policy.PolicyKey)
+	if !ok {
+		return pMgr.AddPolicy(policy, nil)
+	}
+
+	if len(policy.ACLs) == 0 {
+		klog.Infof("[DataPlane] No ACLs in updated policy %s, so removing it", policy.PolicyKey)
+		return pMgr.RemovePolicy(policy.PolicyKey)
+	}
+
+	NormalizePolicy(policy)
+	if err := ValidatePolicy(policy); err != nil {
+		msg := fmt.Sprintf("failed to validate policy: %s", err.Error())
+		metrics.SendErrorLogAndMetric(util.IptmID, "error: %s", msg)
+		return npmerrors.Errorf(npmerrors.UpdatePolicy, false, msg)
+	}
+
+	pMgr.policyMap.Lock()
+	defer pMgr.policyMap.Unlock()
+
+	// Call actual dataplane function to apply changes
+	timer := metrics.StartNewTimer()
+	err := pMgr.updatePolicy(oldPolicy, policy)
+	metrics.RecordACLRuleExecTime(timer) // record execution time regardless of failure
+	if err != nil {
+		msg := fmt.Sprintf("failed to update policy: %s", err.Error())
+		metrics.SendErrorLogAndMetric(util.IptmID, "error: %s", msg)
+		return npmerrors.Errorf(npmerrors.UpdatePolicy, false, msg)
+	}
+
+	// update Prometheus metrics on success
+	metrics.DecNumACLRulesBy(oldPolicy.numACLRulesProducedInKernel())
+	metrics.IncNumACLRulesBy(policy.numACLRulesProducedInKernel())
+
+	pMgr.policyMap.cache[policy.PolicyKey] = policy
+	delete(pMgr.policyMap.unconfirmed, policy.PolicyKey)
 	return nil
 }
 
@@ -189,6 +261,7 @@ func (pMgr *PolicyManager) RemovePolicy(policyKey string) error {
 
 	// remove policy from cache
 	delete(pMgr.policyMap.cache, policyKey)
+	delete(pMgr.policyMap.unconfirmed, policyKey)
 	return nil
 }
 
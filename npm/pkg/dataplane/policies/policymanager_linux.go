@@ -59,6 +59,9 @@ func (pMgr *PolicyManager) addPolicy(networkPolicy *NPMNetworkPolicy, _ map[stri
 	for _, chain := range chainsToCreate {
 		pMgr.staleChains.remove(chain)
 	}
+
+	// 3. Remember the new rules so that updates can be applied as a diff
+	pMgr.kernelModel.addPolicies([]*NPMNetworkPolicy{networkPolicy})
 	return nil
 }
 
@@ -87,6 +90,8 @@ func (pMgr *PolicyManager) removePolicy(networkPolicy *NPMNetworkPolicy, _ map[s
 	for _, chain := range chainsToDelete {
 		pMgr.staleChains.add(chain)
 	}
+
+	pMgr.kernelModel.removePolicy(networkPolicy)
 	return nil
 }
 
@@ -206,9 +211,7 @@ func (pMgr *PolicyManager) creatorForNewNetworkPolicies(policyChains []string, n
 	// 1. Activate NPM if necessary
 	if pMgr.isFirstPolicy() {
 		creator.AddLine("", nil, util.IptablesFlushFlag, util.IptablesAzureChain) // flush just in case there are old rules
-		creator.AddLine("", nil, util.IptablesAppendFlag, util.IptablesAzureChain, util.IptablesJumpFlag, util.IptablesAzureIngressChain)
-		creator.AddLine("", nil, util.IptablesAppendFlag, util.IptablesAzureChain, util.IptablesJumpFlag, util.IptablesAzureEgressChain)
-		creator.AddLine("", nil, util.IptablesAppendFlag, util.IptablesAzureChain, util.IptablesJumpFlag, util.IptablesAzureAcceptChain)
+		writeAzureChainRules(creator)
 	}
 
 	// 2. Add all rules for the network policies
@@ -235,33 +238,45 @@ func (pMgr *PolicyManager) creatorForNewNetworkPolicies(policyChains []string, n
 	return creator
 }
 
+// appends the rules which activate NPM in the AZURE-NPM chain
+func writeAzureChainRules(creator *ioutil.FileCreator) {
+	creator.AddLine("", nil, util.IptablesAppendFlag, util.IptablesAzureChain, util.IptablesJumpFlag, util.IptablesAzureIngressChain)
+	creator.AddLine("", nil, util.IptablesAppendFlag, util.IptablesAzureChain, util.IptablesJumpFlag, util.IptablesAzureEgressChain)
+	creator.AddLine("", nil, util.IptablesAppendFlag, util.IptablesAzureChain, util.IptablesJumpFlag, util.IptablesAzureAcceptChain)
+}
+
 // write rules for the policy chain(s)
 func writeNetworkPolicyRules(creator *ioutil.FileCreator, networkPolicy *NPMNetworkPolicy) {
 	for _, aclPolicy := range networkPolicy.ACLs {
-		var chainName string
-		var actionSpecs []string
-		if aclPolicy.hasIngress() {
-			chainName = networkPolicy.ingressChainName()
-			if aclPolicy.Target == Allowed {
-				actionSpecs = []string{util.IptablesJumpFlag, util.IptablesAzureIngressAllowMarkChain}
-			} else {
-				actionSpecs = setMarkSpecs(util.IptablesAzureIngressDropMarkHex)
-			}
-		} else {
-			chainName = networkPolicy.egressChainName()
-			if aclPolicy.Target == Allowed {
-				actionSpecs = []string{util.IptablesJumpFlag, util.IptablesAzureAcceptChain}
-			} else {
-				actionSpecs = setMarkSpecs(util.IptablesAzureEgressDropMarkHex)
-			}
-		}
+		chainName, specs := aclRuleSpecs(networkPolicy, aclPolicy)
 		line := []string{"-A", chainName}
-		line = append(line, actionSpecs...)
-		line = append(line, iptablesRuleSpecs(aclPolicy)...)
+		line = append(line, specs...)
 		creator.AddLine("", nil, line...) // TODO add error handler
 	}
 }
 
+// returns the policy chain for the ACL and the specs of its rule in that chain
+func aclRuleSpecs(networkPolicy *NPMNetworkPolicy, aclPolicy *ACLPolicy) (chainName string, specs []string) {
+	var actionSpecs []string
+	if aclPolicy.hasIngress() {
+		chainName = networkPolicy.ingressChainName()
+		if aclPolicy.Target == Allowed {
+			actionSpecs = []string{util.IptablesJumpFlag, util.IptablesAzureIngressAllowMarkChain}
+		} else {
+			actionSpecs = setMarkSpecs(util.IptablesAzureIngressDropMarkHex)
+		}
+	} else {
+		chainName = networkPolicy.egressChainName()
+		if aclPolicy.Target == Allowed {
+			actionSpecs = []string{util.IptablesJumpFlag, util.IptablesAzureAcceptChain}
+		} else {
+			actionSpecs = setMarkSpecs(util.IptablesAzureEgressDropMarkHex)
+		}
+	}
+	specs = append(actionSpecs, iptablesRuleSpecs(aclPolicy)...)
+	return chainName, specs
+}
+
 func iptablesRuleSpecs(aclPolicy *ACLPolicy) []string {
 	specs := make([]string, 0)
 	if aclPolicy.Protocol != UnspecifiedProtocol {
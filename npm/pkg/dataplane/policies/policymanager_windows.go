@@ -13,14 +13,18 @@ import (
 )
 
 var (
-	ErrFailedMarshalACLSettings                      = errors.New("failed to marshal ACL settings")
-	ErrFailedUnMarshalACLSettings                    = errors.New("failed to unmarshal ACL settings")
-	resetAllACLs                  shouldResetAllACLs = true
-	removeOnlyGivenPolicy         shouldResetAllACLs = false
+	ErrFailedMarshalACLSettings                         = errors.New("failed to marshal ACL settings")
+	ErrFailedUnMarshalACLSettings                       = errors.New("failed to unmarshal ACL settings")
+	ErrIncrementalUpdateNotSupported                    = errors.New("incremental policy updates are not supported in windows dataplane")
+	ErrSnapshotNotSupported                             = errors.New("policy manager snapshots are not supported in windows dataplane")
+	resetAllACLs                     shouldResetAllACLs = true
+	removeOnlyGivenPolicy            shouldResetAllACLs = false
 )
 
 type staleChains struct{} // unused in Windows
 
+type kernelModel struct{} // unused in Windows
+
 type shouldResetAllACLs bool
 
 type endpointPolicyBuilder struct {
@@ -32,6 +36,10 @@ func newStaleChains() *staleChains {
 	return &staleChains{}
 }
 
+func newKernelModel() *kernelModel {
+	return &kernelModel{}
+}
+
 func (pMgr *PolicyManager) bootup(epIDs []string) error {
 	var aggregateErr error
 	for _, epID := range epIDs {
@@ -56,6 +64,24 @@ func (pMgr *PolicyManager) reconcile() {
 	// not implemented
 }
 
+func (pMgr *PolicyManager) updatePolicyHitCounts() error {
+	// not implemented
+	return nil
+}
+
+func (pMgr *PolicyManager) snapshot() (*PolicyManagerSnapshot, error) {
+	return nil, ErrSnapshotNotSupported
+}
+
+func (pMgr *PolicyManager) bootupWithCache(_ *PolicyManagerSnapshot) error {
+	return ErrSnapshotNotSupported
+}
+
+func (pMgr *PolicyManager) updatePolicy(_, _ *NPMNetworkPolicy) error {
+	// the DataPlane removes and re-adds policies on update in Windows
+	return ErrIncrementalUpdateNotSupported
+}
+
 // addPolicy will add the policy for each specified endpoint if the policy doesn't exist on the endpoint yet,
 // and will add the endpoint to the PodEndpoints of the policy if successful.
 // addPolicy may modify the endpointList input.
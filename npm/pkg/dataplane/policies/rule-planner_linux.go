@@ -0,0 +1,382 @@
+package policies
+
+// This file contains code for updating a policy's iptables rules incrementally.
+
+import (
+	"strings"
+
+	"github.com/Azure/azure-container-networking/npm/metrics"
+	"github.com/Azure/azure-container-networking/npm/util"
+	npmerrors "github.com/Azure/azure-container-networking/npm/util/errors"
+	"github.com/Azure/azure-container-networking/npm/util/ioutil"
+	"k8s.io/klog"
+)
+
+// kernelModel caches the rules NPM has programmed into the policy chains and
+// the jumps to the policy chains at the top of AZURE-NPM-INGRESS and AZURE-NPM-EGRESS.
+// It's only accurate as long as nothing else modifies these chains.
+// Updates are planned against this model and detect drift when a rule to delete is missing in the kernel.
+type kernelModel struct {
+	// policy chain name -> rule specs in kernel order
+	chainRules map[string][][]string
+	// base chain name -> jumps to policy chains in kernel order
+	jumps map[string][]*modeledJump
+}
+
+type modeledJump struct {
+	policyKey string
+	specs     []string
+}
+
+// rulePlan holds the positions of rules to delete from an old list of rules
+// and the positions of rules to insert from a new list of rules.
+type rulePlan struct {
+	deletes []int
+	inserts []int
+}
+
+func newKernelModel() *kernelModel {
+	m := &kernelModel{}
+	m.reset()
+	return m
+}
+
+func (m *kernelModel) reset() {
+	m.chainRules = make(map[string][][]string)
+	m.jumps = map[string][]*modeledJump{
+		util.IptablesAzureIngressChain: {},
+		util.IptablesAzureEgressChain:  {},
+	}
+}
+
+// addPolicies models the jumps inserted at the top of the base chains by creatorForNewNetworkPolicies().
+func (m *kernelModel) addPolicies(networkPolicies []*NPMNetworkPolicy) {
+	newJumps := map[string][]*modeledJump{
+		util.IptablesAzureIngressChain: {},
+		util.IptablesAzureEgressChain:  {},
+	}
+	for _, networkPolicy := range networkPolicies {
+		for chain, rules := range policyChainRules(networkPolicy) {
+			m.chainRules[chain] = rules
+		}
+		for baseChain, specs := range policyJumps(networkPolicy) {
+			newJumps[baseChain] = append(newJumps[baseChain], &modeledJump{policyKey: networkPolicy.PolicyKey, specs: specs})
+		}
+	}
+	for baseChain, jumps := range newJumps {
+		m.jumps[baseChain] = append(jumps, m.jumps[baseChain]...)
+	}
+}
+
+func (m *kernelModel) removePolicy(networkPolicy *NPMNetworkPolicy) {
+	for _, chain := range chainNames([]*NPMNetworkPolicy{networkPolicy}) {
+		delete(m.chainRules, chain)
+	}
+	for baseChain := range m.jumps {
+		if i := m.jumpIndex(baseChain, networkPolicy.PolicyKey); i >= 0 {
+			m.jumps[baseChain] = append(m.jumps[baseChain][:i], m.jumps[baseChain][i+1:]...)
+		}
+	}
+}
+
+// replacePolicy models the kernel after updating a policy in place.
+// A jump keeps its position if the policy already had one in the base chain, and is placed first otherwise.
+// newRules must be the result of policyChainRules(newPolicy).
+func (m *kernelModel) replacePolicy(oldPolicy, newPolicy *NPMNetworkPolicy, newRules map[string][][]string) {
+	for _, chain := range chainNames([]*NPMNetworkPolicy{oldPolicy}) {
+		delete(m.chainRules, chain)
+	}
+	for chain, rules := range newRules {
+		m.chainRules[chain] = rules
+	}
+
+	newJumps := policyJumps(newPolicy)
+	for baseChain := range m.jumps {
+		i := m.jumpIndex(baseChain, oldPolicy.PolicyKey)
+		specs, hasJump := newJumps[baseChain]
+		switch {
+		case i >= 0 && hasJump:
+			m.jumps[baseChain][i] = &modeledJump{policyKey: newPolicy.PolicyKey, specs: specs}
+		case i >= 0:
+			m.jumps[baseChain] = append(m.jumps[baseChain][:i], m.jumps[baseChain][i+1:]...)
+		case hasJump:
+			m.jumps[baseChain] = append([]*modeledJump{{policyKey: newPolicy.PolicyKey, specs: specs}}, m.jumps[baseChain]...)
+		}
+	}
+}
+
+// hasPolicy returns true if the model has every chain and jump for the policy.
+func (m *kernelModel) hasPolicy(networkPolicy *NPMNetworkPolicy) bool {
+	for _, chain := range chainNames([]*NPMNetworkPolicy{networkPolicy}) {
+		if _, ok := m.chainRules[chain]; !ok {
+			return false
+		}
+	}
+	for baseChain := range policyJumps(networkPolicy) {
+		if m.jumpIndex(baseChain, networkPolicy.PolicyKey) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// returns -1 if there is no jump for the policy
+func (m *kernelModel) jumpIndex(baseChain, policyKey string) int {
+	for i, jump := range m.jumps[baseChain] {
+		if jump.policyKey == policyKey {
+			return i
+		}
+	}
+	return -1
+}
+
+func (pMgr *PolicyManager) updatePolicy(oldPolicy, newPolicy *NPMNetworkPolicy) error {
+	// Stop reconciling so we don't contend for iptables, and so we don't update the staleChains at the same time as reconcile()
+	pMgr.reconcileManager.forceLock()
+	defer pMgr.reconcileManager.forceUnlock()
+
+	newRules := policyChainRules(newPolicy)
+	if pMgr.kernelModel.hasPolicy(oldPolicy) {
+		creator := pMgr.creatorForUpdatingPolicy(oldPolicy, newPolicy, newRules)
+		err := restore(creator)
+		if err == nil {
+			pMgr.finishPolicyUpdate(oldPolicy, newPolicy, newRules)
+			return nil
+		}
+		// iptables-restore is atomic, so nothing from the failed file was applied
+		metrics.SendErrorLogAndMetric(util.IptmID, "error: failed to update policy %s incrementally, so rewriting its chains. err: %s", newPolicy.PolicyKey, err.Error())
+	} else {
+		klog.Infof("[PolicyManager] no cached rules for policy %s, so rewriting its chains", oldPolicy.PolicyKey)
+	}
+
+	creator := pMgr.creatorForRewritingPolicy(oldPolicy, newPolicy, newRules)
+	if err := restore(creator); err != nil {
+		return npmerrors.SimpleErrorWrapper("failed to rewrite chains for updated policy", err)
+	}
+	pMgr.finishPolicyUpdate(oldPolicy, newPolicy, newRules)
+	return nil
+}
+
+func (pMgr *PolicyManager) finishPolicyUpdate(oldPolicy, newPolicy *NPMNetworkPolicy, newRules map[string][][]string) {
+	newChains := make(map[string]struct{})
+	for _, chain := range chainNames([]*NPMNetworkPolicy{newPolicy}) {
+		newChains[chain] = struct{}{}
+		pMgr.staleChains.remove(chain)
+	}
+	for _, chain := range chainNames([]*NPMNetworkPolicy{oldPolicy}) {
+		if _, ok := newChains[chain]; !ok {
+			pMgr.staleChains.add(chain)
+		}
+	}
+	pMgr.kernelModel.replacePolicy(oldPolicy, newPolicy, newRules)
+}
+
+// creatorForUpdatingPolicy deletes and inserts the minimal set of rules to turn the modeled rules of the old policy into the new rules.
+// Rules are deleted by spec instead of by position so that the restore fails if the kernel has drifted from the model.
+// The model must have the old policy.
+func (pMgr *PolicyManager) creatorForUpdatingPolicy(oldPolicy, newPolicy *NPMNetworkPolicy, newRules map[string][][]string) *ioutil.FileCreator {
+	oldChains := chainNames([]*NPMNetworkPolicy{oldPolicy})
+	oldRules := make(map[string][][]string, len(oldChains))
+	for _, chain := range oldChains {
+		oldRules[chain] = pMgr.kernelModel.chainRules[chain]
+	}
+	allChains := oldChains
+	chainsToCreate := make([]string, 0)
+	for _, chain := range chainNames([]*NPMNetworkPolicy{newPolicy}) {
+		if _, ok := oldRules[chain]; !ok {
+			chainsToCreate = append(chainsToCreate, chain)
+			allChains = append(allChains, chain)
+		}
+	}
+	creator := pMgr.newCreatorWithChains(chainsToCreate)
+
+	// 1. Update jumps to the policy chains in place.
+	newJumps := policyJumps(newPolicy)
+	for _, baseChain := range []string{util.IptablesAzureIngressChain, util.IptablesAzureEgressChain} {
+		i := pMgr.kernelModel.jumpIndex(baseChain, oldPolicy.PolicyKey)
+		newSpecs, hasNewJump := newJumps[baseChain]
+		if i >= 0 {
+			oldSpecs := pMgr.kernelModel.jumps[baseChain][i].specs
+			if hasNewJump && specsEqual(oldSpecs, newSpecs) {
+				continue
+			}
+			creator.AddLine("", nil, append([]string{util.IptablesDeletionFlag, baseChain}, oldSpecs...)...)
+		} else {
+			i = 0
+		}
+		if hasNewJump {
+			creator.AddLine("", nil, insertSpecs(baseChain, i+1, newSpecs)...)
+		}
+	}
+
+	// 2. Update rules in the policy chains.
+	for _, chain := range allChains {
+		oldChainRules, hadChain := oldRules[chain]
+		newChainRules, hasChain := newRules[chain]
+		switch {
+		case !hasChain:
+			creator.AddLine("", nil, util.IptablesFlushFlag, chain)
+		case !hadChain:
+			for _, specs := range newChainRules {
+				creator.AddLine("", nil, append([]string{util.IptablesAppendFlag, chain}, specs...)...)
+			}
+		default:
+			plan := planRuleChanges(oldChainRules, newChainRules)
+			for _, i := range plan.deletes {
+				creator.AddLine("", nil, append([]string{util.IptablesDeletionFlag, chain}, oldChainRules[i]...)...)
+			}
+			for _, i := range plan.inserts {
+				creator.AddLine("", nil, insertSpecs(chain, i+1, newChainRules[i])...)
+			}
+		}
+	}
+	creator.AddLine("", nil, util.IptablesRestoreCommit)
+	return creator
+}
+
+// creatorForRewritingPolicy rewrites the base chains from the model and the updated policy's chains from scratch.
+// Chain headers flush existing rules, so this doesn't depend on the current state of these chains.
+func (pMgr *PolicyManager) creatorForRewritingPolicy(oldPolicy, newPolicy *NPMNetworkPolicy, newRules map[string][][]string) *ioutil.FileCreator {
+	newChains := chainNames([]*NPMNetworkPolicy{newPolicy})
+	creator := pMgr.newCreatorWithChains(append([]string{util.IptablesAzureIngressChain, util.IptablesAzureEgressChain}, newChains...))
+
+	isNewChain := make(map[string]struct{}, len(newChains))
+	for _, chain := range newChains {
+		isNewChain[chain] = struct{}{}
+	}
+	for _, chain := range chainNames([]*NPMNetworkPolicy{oldPolicy}) {
+		if _, ok := isNewChain[chain]; !ok {
+			creator.AddLine("", nil, util.IptablesFlushFlag, chain)
+		}
+	}
+
+	// build the jumps for the model as if the update succeeded
+	model := &kernelModel{chainRules: make(map[string][][]string), jumps: make(map[string][]*modeledJump)}
+	for baseChain, jumps := range pMgr.kernelModel.jumps {
+		model.jumps[baseChain] = append([]*modeledJump{}, jumps...)
+	}
+	model.replacePolicy(oldPolicy, newPolicy, newRules)
+	for _, baseChain := range []string{util.IptablesAzureIngressChain, util.IptablesAzureEgressChain} {
+		for _, jump := range model.jumps[baseChain] {
+			creator.AddLine("", nil, append([]string{util.IptablesAppendFlag, baseChain}, jump.specs...)...)
+		}
+		if baseChain == util.IptablesAzureIngressChain {
+			writeIngressChainBaseRules(creator)
+		} else {
+			writeEgressChainBaseRules(creator)
+		}
+	}
+
+	for _, chain := range newChains {
+		for _, specs := range newRules[chain] {
+			creator.AddLine("", nil, append([]string{util.IptablesAppendFlag, chain}, specs...)...)
+		}
+	}
+	creator.AddLine("", nil, util.IptablesRestoreCommit)
+	return creator
+}
+
+// returns the rule specs for each of the policy's chains in the order they're written by writeNetworkPolicyRules()
+func policyChainRules(networkPolicy *NPMNetworkPolicy) map[string][][]string {
+	rules := make(map[string][][]string)
+	for _, chain := range chainNames([]*NPMNetworkPolicy{networkPolicy}) {
+		rules[chain] = make([][]string, 0)
+	}
+	for _, aclPolicy := range networkPolicy.ACLs {
+		chain, specs := aclRuleSpecs(networkPolicy, aclPolicy)
+		rules[chain] = append(rules[chain], specs)
+	}
+	return rules
+}
+
+// returns the specs of the policy's jumps keyed by base chain
+func policyJumps(networkPolicy *NPMNetworkPolicy) map[string][]string {
+	jumps := make(map[string][]string)
+	hasIngress, hasEgress := networkPolicy.hasIngressAndEgress()
+	if hasIngress {
+		jumps[util.IptablesAzureIngressChain] = ingressJumpSpecs(networkPolicy)
+	}
+	if hasEgress {
+		jumps[util.IptablesAzureEgressChain] = egressJumpSpecs(networkPolicy)
+	}
+	return jumps
+}
+
+// planRuleChanges keeps a longest common subsequence of the old and new rules.
+// Deletes are indexes of old rules, and inserts are ascending indexes of new rules.
+// After the deletes, inserting each new rule at its index+1 in ascending order yields the new rules.
+func planRuleChanges(oldRules, newRules [][]string) rulePlan {
+	oldKeys := make([]string, len(oldRules))
+	for i, specs := range oldRules {
+		oldKeys[i] = strings.Join(specs, " ")
+	}
+	newKeys := make([]string, len(newRules))
+	for i, specs := range newRules {
+		newKeys[i] = strings.Join(specs, " ")
+	}
+
+	// skip the common prefix and suffix since most updates only touch a few rules
+	prefix := 0
+	for prefix < len(oldKeys) && prefix < len(newKeys) && oldKeys[prefix] == newKeys[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldKeys)-prefix && suffix < len(newKeys)-prefix &&
+		oldKeys[len(oldKeys)-1-suffix] == newKeys[len(newKeys)-1-suffix] {
+		suffix++
+	}
+	oldMiddle := oldKeys[prefix : len(oldKeys)-suffix]
+	newMiddle := newKeys[prefix : len(newKeys)-suffix]
+
+	// lcs[i][j] is the length of the longest common subsequence of oldMiddle[i:] and newMiddle[j:]
+	lcs := make([][]int, len(oldMiddle)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newMiddle)+1)
+	}
+	for i := len(oldMiddle) - 1; i >= 0; i-- {
+		for j := len(newMiddle) - 1; j >= 0; j-- {
+			if oldMiddle[i] == newMiddle[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	plan := rulePlan{deletes: make([]int, 0), inserts: make([]int, 0)}
+	i, j := 0, 0
+	for i < len(oldMiddle) && j < len(newMiddle) {
+		switch {
+		case oldMiddle[i] == newMiddle[j]:
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			plan.deletes = append(plan.deletes, prefix+i)
+			i++
+		default:
+			plan.inserts = append(plan.inserts, prefix+j)
+			j++
+		}
+	}
+	for ; i < len(oldMiddle); i++ {
+		plan.deletes = append(plan.deletes, prefix+i)
+	}
+	for ; j < len(newMiddle); j++ {
+		plan.inserts = append(plan.inserts, prefix+j)
+	}
+	return plan
+}
+
+func specsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+package policies
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Azure/azure-container-networking/npm/metrics"
+	"github.com/Azure/azure-container-networking/npm/util"
+	npmerrors "github.com/Azure/azure-container-networking/npm/util/errors"
+	"k8s.io/klog"
+)
+
+// PolicyManagerSnapshot is the serializable state of the PolicyManager and the kernel rules it programmed.
+type PolicyManagerSnapshot struct {
+	Policies []*NPMNetworkPolicy
+	// Jumps maps AZURE-NPM-INGRESS and AZURE-NPM-EGRESS to the keys of the policies they jump to in kernel order
+	Jumps map[string][]string
+	// ChainLines maps each policy chain to its rules as printed by iptables-save when the snapshot was taken
+	ChainLines map[string][]string
+}
+
+// Snapshot returns the cached policies and the state of their kernel rules, sorted by policy key.
+func (pMgr *PolicyManager) Snapshot() (*PolicyManagerSnapshot, error) {
+	pMgr.policyMap.RLock()
+	defer pMgr.policyMap.RUnlock()
+
+	snapshot, err := pMgr.snapshot()
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot policy manager: %w", err)
+	}
+	sort.Slice(snapshot.Policies, func(i, j int) bool {
+		return snapshot.Policies[i].PolicyKey < snapshot.Policies[j].PolicyKey
+	})
+	return snapshot, nil
+}
+
+// BootupWithCache is an alternative to Bootup() when restarting with a snapshot of a previous PolicyManager.
+// The snapshot's policies are cached and their rules are reconciled with the kernel instead of being flushed,
+// so traffic isn't interrupted while the controllers catch up.
+// The ipsets referenced by the snapshot's policies must be in the kernel.
+//
+// The restored policies are unconfirmed until they're added or updated again. See TakeUnconfirmedPolicies().
+// If an error is returned, the cache is empty and the kernel may be partially reconciled, so Bootup() should be called.
+func (pMgr *PolicyManager) BootupWithCache(snapshot *PolicyManagerSnapshot) error {
+	metrics.ResetNumACLRules()
+	if err := pMgr.bootupWithCache(snapshot); err != nil {
+		metrics.SendErrorLogAndMetric(util.IptmID, "error: failed to bootup policy manager with cache: %s", err.Error())
+		return npmerrors.ErrorWrapper(npmerrors.BootupPolicyMgr, false, "failed to bootup policy manager with cache", err)
+	}
+
+	pMgr.policyMap.Lock()
+	defer pMgr.policyMap.Unlock()
+	pMgr.policyMap.cache = make(map[string]*NPMNetworkPolicy, len(snapshot.Policies))
+	pMgr.policyMap.unconfirmed = make(map[string]struct{}, len(snapshot.Policies))
+	numACLRules := numLinuxBaseACLRules
+	for _, policy := range snapshot.Policies {
+		pMgr.policyMap.cache[policy.PolicyKey] = policy
+		pMgr.policyMap.unconfirmed[policy.PolicyKey] = struct{}{}
+		numACLRules += policy.numACLRulesProducedInKernel()
+	}
+	metrics.IncNumACLRulesBy(numACLRules)
+	klog.Infof("[PolicyManager] restored %d policies from snapshot", len(snapshot.Policies))
+	return nil
+}
+
+// TakeUnconfirmedPolicies returns the keys of policies restored by BootupWithCache() which haven't been added or updated since,
+// and stops tracking unconfirmed policies.
+func (pMgr *PolicyManager) TakeUnconfirmedPolicies() []string {
+	pMgr.policyMap.Lock()
+	defer pMgr.policyMap.Unlock()
+
+	policyKeys := make([]string, 0, len(pMgr.policyMap.unconfirmed))
+	for policyKey := range pMgr.policyMap.unconfirmed {
+		policyKeys = append(policyKeys, policyKey)
+	}
+	sort.Strings(policyKeys)
+	pMgr.policyMap.unconfirmed = nil
+	return policyKeys
+}
@@ -0,0 +1,222 @@
+package policies
+
+// This file contains code for snapshotting the PolicyManager and booting up from a snapshot.
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-container-networking/npm/metrics"
+	"github.com/Azure/azure-container-networking/npm/util"
+	npmerrors "github.com/Azure/azure-container-networking/npm/util/errors"
+	"github.com/Azure/azure-container-networking/npm/util/ioutil"
+	"k8s.io/klog"
+)
+
+var (
+	errPolicyNotModeled = errors.New("policy isn't in the kernel model")
+	errInvalidSnapshot  = errors.New("invalid policy manager snapshot")
+)
+
+func (pMgr *PolicyManager) snapshot() (*PolicyManagerSnapshot, error) {
+	chainLines, err := pMgr.azureChainLines()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &PolicyManagerSnapshot{
+		Policies:   make([]*NPMNetworkPolicy, 0, len(pMgr.policyMap.cache)),
+		Jumps:      make(map[string][]string, len(pMgr.kernelModel.jumps)),
+		ChainLines: make(map[string][]string),
+	}
+	for _, policy := range pMgr.policyMap.cache {
+		if !pMgr.kernelModel.hasPolicy(policy) {
+			return nil, fmt.Errorf("%w: %s", errPolicyNotModeled, policy.PolicyKey)
+		}
+		snapshot.Policies = append(snapshot.Policies, policy)
+		for _, chain := range chainNames([]*NPMNetworkPolicy{policy}) {
+			snapshot.ChainLines[chain] = chainLines[chain]
+		}
+	}
+	for baseChain, jumps := range pMgr.kernelModel.jumps {
+		policyKeys := make([]string, 0, len(jumps))
+		for _, jump := range jumps {
+			policyKeys = append(policyKeys, jump.policyKey)
+		}
+		snapshot.Jumps[baseChain] = policyKeys
+	}
+	return snapshot, nil
+}
+
+// Like bootup(), but keeps the snapshot's policies in the kernel. All changes are made in one iptables-restore call.
+//
+// 1. Delete the deprecated jump from FORWARD to AZURE-NPM chain (if it exists).
+// 2. Reconcile NPM chains via iptables-restore --noflush:
+//   - rewrite the base chains, including the jumps to policy chains in the snapshot's order, and activate NPM if there are policies
+//   - keep policy chains whose rules haven't changed since the snapshot, and rewrite the rest
+//   - flush all other NPM chains and delete them in the background
+//
+// 3. Add/reposition the jump from FORWARD chain to AZURE-NPM chain.
+func (pMgr *PolicyManager) bootupWithCache(snapshot *PolicyManagerSnapshot) error {
+	klog.Infof("booting up iptables Azure chains with %d cached policies", len(snapshot.Policies))
+
+	model, err := kernelModelFromSnapshot(snapshot)
+	if err != nil {
+		return err
+	}
+
+	// Stop reconciling so we don't contend for iptables, and so we don't update the staleChains at the same time as reconcile()
+	pMgr.reconcileManager.forceLock()
+	defer pMgr.reconcileManager.forceUnlock()
+
+	// 1. delete the deprecated jump to AZURE-NPM
+	pMgr.deleteDeprecatedJump()
+
+	currentChainLines, err := pMgr.azureChainLines()
+	if err != nil {
+		return npmerrors.SimpleErrorWrapper("failed to get current chains for bootup with cache", err)
+	}
+
+	// 2. reconcile NPM chains
+	creator := pMgr.creatorForBootupWithCache(snapshot, model, currentChainLines)
+	if err := restore(creator); err != nil {
+		return npmerrors.SimpleErrorWrapper("failed to run iptables-restore for bootup with cache", err)
+	}
+	pMgr.kernelModel = model
+
+	// 3. add/reposition the jump to AZURE-NPM
+	if err := pMgr.positionAzureChainJumpRule(); err != nil {
+		baseErrString := "failed to add/reposition jump from FORWARD chain to AZURE-NPM chain"
+		metrics.SendErrorLogAndMetric(util.IptmID, "error: %s with error: %s", baseErrString, err.Error())
+		return npmerrors.SimpleErrorWrapper(baseErrString, err)
+	}
+	return nil
+}
+
+// kernelModelFromSnapshot models the rules of the snapshot's policies and its jumps,
+// making sure that each policy has exactly the jumps it needs.
+func kernelModelFromSnapshot(snapshot *PolicyManagerSnapshot) (*kernelModel, error) {
+	model := newKernelModel()
+	policies := make(map[string]*NPMNetworkPolicy, len(snapshot.Policies))
+	numJumps := 0
+	for _, policy := range snapshot.Policies {
+		if policy == nil || len(policy.ACLs) == 0 {
+			return nil, fmt.Errorf("%w: policy without ACLs", errInvalidSnapshot)
+		}
+		if _, ok := policies[policy.PolicyKey]; ok {
+			return nil, fmt.Errorf("%w: duplicate policy %s", errInvalidSnapshot, policy.PolicyKey)
+		}
+		policies[policy.PolicyKey] = policy
+		for chain, rules := range policyChainRules(policy) {
+			model.chainRules[chain] = rules
+		}
+		numJumps += len(policyJumps(policy))
+	}
+
+	for _, baseChain := range []string{util.IptablesAzureIngressChain, util.IptablesAzureEgressChain} {
+		for _, policyKey := range snapshot.Jumps[baseChain] {
+			policy, ok := policies[policyKey]
+			if !ok {
+				return nil, fmt.Errorf("%w: jump from %s to missing policy %s", errInvalidSnapshot, baseChain, policyKey)
+			}
+			specs, ok := policyJumps(policy)[baseChain]
+			if !ok || model.jumpIndex(baseChain, policyKey) >= 0 {
+				return nil, fmt.Errorf("%w: unexpected jump from %s for policy %s", errInvalidSnapshot, baseChain, policyKey)
+			}
+			model.jumps[baseChain] = append(model.jumps[baseChain], &modeledJump{policyKey: policyKey, specs: specs})
+			numJumps--
+		}
+	}
+	if numJumps != 0 {
+		return nil, fmt.Errorf("%w: missing jumps to policy chains", errInvalidSnapshot)
+	}
+	return model, nil
+}
+
+// Writes the restore file for bootup with a cache, and marks NPM chains which aren't needed anymore as stale.
+// This is a separate function to help with UTs.
+func (pMgr *PolicyManager) creatorForBootupWithCache(snapshot *PolicyManagerSnapshot, model *kernelModel, currentChainLines map[string][]string) *ioutil.FileCreator {
+	desiredChains := make(map[string]struct{})
+	chainsToWrite := append([]string{}, iptablesAzureChains...)
+	for _, policy := range snapshot.Policies {
+		for _, chain := range chainNames([]*NPMNetworkPolicy{policy}) {
+			desiredChains[chain] = struct{}{}
+			currentLines, exists := currentChainLines[chain]
+			if exists && specsEqual(currentLines, snapshot.ChainLines[chain]) {
+				klog.Infof("keeping rules of unchanged policy chain %s", chain)
+				continue
+			}
+			chainsToWrite = append(chainsToWrite, chain)
+		}
+	}
+
+	// chain headers flush the base chains and policy chains to rewrite
+	creator := pMgr.newCreatorWithChains(chainsToWrite)
+
+	// flush other NPM chains and delete them in the background
+	pMgr.staleChains.empty()
+	staleChains := make([]string, 0)
+	for chain := range currentChainLines {
+		if _, ok := desiredChains[chain]; !ok && !isBaseChain(chain) {
+			staleChains = append(staleChains, chain)
+		}
+	}
+	sort.Strings(staleChains)
+	for _, chain := range staleChains {
+		creator.AddLine("", nil, util.IptablesFlushFlag, chain)
+		pMgr.staleChains.add(chain)
+	}
+
+	// activate NPM if there are policies
+	if len(snapshot.Policies) > 0 {
+		writeAzureChainRules(creator)
+	}
+
+	for _, jump := range model.jumps[util.IptablesAzureIngressChain] {
+		creator.AddLine("", nil, append([]string{util.IptablesAppendFlag, util.IptablesAzureIngressChain}, jump.specs...)...)
+	}
+	writeIngressChainBaseRules(creator)
+	writeIngressAllowMarkChainRules(creator)
+	for _, jump := range model.jumps[util.IptablesAzureEgressChain] {
+		creator.AddLine("", nil, append([]string{util.IptablesAppendFlag, util.IptablesAzureEgressChain}, jump.specs...)...)
+	}
+	writeEgressChainBaseRules(creator)
+	writeAcceptChainRules(creator)
+
+	for _, chain := range chainsToWrite[len(iptablesAzureChains):] {
+		for _, specs := range model.chainRules[chain] {
+			creator.AddLine("", nil, append([]string{util.IptablesAppendFlag, chain}, specs...)...)
+		}
+	}
+	creator.AddLine("", nil, util.IptablesRestoreCommit)
+	return creator
+}
+
+// azureChainLines returns the rules of each NPM chain in the filter table as printed by iptables-save.
+// Chains without rules have an empty slice.
+func (pMgr *PolicyManager) azureChainLines() (map[string][]string, error) {
+	command := pMgr.ioShim.Exec.Command(util.IptablesSave, util.IptablesTableFlag, util.IptablesFilterTable)
+	output, err := command.CombinedOutput()
+	if err != nil {
+		return nil, npmerrors.SimpleErrorWrapper("failed to run iptables-save", err)
+	}
+
+	chainLines := make(map[string][]string)
+	chainHeaderPrefix := ":" + util.IptablesAzureChain
+	rulePrefix := util.IptablesAppendFlag + " " + util.IptablesAzureChain
+	for _, line := range strings.Split(string(output), "\n") {
+		switch {
+		case strings.HasPrefix(line, chainHeaderPrefix):
+			chain := strings.Fields(line[1:])[0]
+			if _, ok := chainLines[chain]; !ok {
+				chainLines[chain] = make([]string, 0)
+			}
+		case strings.HasPrefix(line, rulePrefix):
+			chain := strings.Fields(line)[1]
+			chainLines[chain] = append(chainLines[chain], line)
+		}
+	}
+	return chainLines, nil
+}
@@ -0,0 +1,183 @@
+package policies
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/common"
+	"github.com/Azure/azure-container-networking/npm/metrics"
+	dptestutils "github.com/Azure/azure-container-networking/npm/pkg/dataplane/testutils"
+	"github.com/Azure/azure-container-networking/npm/util"
+	testutils "github.com/Azure/azure-container-networking/test/utils"
+	"github.com/stretchr/testify/require"
+)
+
+const staleTestChain = "AZURE-NPM-INGRESS-123456"
+
+// snapshot of bothDirectionsNetPol and egressNetPol as if they were added in that order
+func testSnapshot() *PolicyManagerSnapshot {
+	return &PolicyManagerSnapshot{
+		Policies: []*NPMNetworkPolicy{bothDirectionsNetPol, egressNetPol},
+		Jumps: map[string][]string{
+			util.IptablesAzureIngressChain: {bothDirectionsNetPol.PolicyKey},
+			util.IptablesAzureEgressChain:  {bothDirectionsNetPol.PolicyKey, egressNetPol.PolicyKey},
+		},
+		ChainLines: map[string][]string{
+			bothDirectionsNetPolIngressChain: {
+				fmt.Sprintf("-A %s %s", bothDirectionsNetPolIngressChain, ingressDropRule),
+				fmt.Sprintf("-A %s %s", bothDirectionsNetPolIngressChain, ingressAllowRule),
+			},
+			bothDirectionsNetPolEgressChain: {
+				fmt.Sprintf("-A %s %s", bothDirectionsNetPolEgressChain, egressDropRule),
+				fmt.Sprintf("-A %s %s", bothDirectionsNetPolEgressChain, egressAllowRule),
+			},
+			egressNetPolChain: {
+				fmt.Sprintf("-A %s %s", egressNetPolChain, egressAllowRule),
+			},
+		},
+	}
+}
+
+func TestCreatorForBootupWithCache(t *testing.T) {
+	snapshot := testSnapshot()
+	currentChainLines := map[string][]string{
+		util.IptablesAzureChain:        {},
+		util.IptablesAzureIngressChain: {},
+		util.IptablesAzureEgressChain:  {},
+		// unchanged since the snapshot
+		bothDirectionsNetPolIngressChain: snapshot.ChainLines[bothDirectionsNetPolIngressChain],
+		// a rule was deleted since the snapshot
+		bothDirectionsNetPolEgressChain: snapshot.ChainLines[bothDirectionsNetPolEgressChain][:1],
+		// egressNetPolChain was deleted since the snapshot
+		staleTestChain: {},
+	}
+
+	pMgr := NewPolicyManager(common.NewMockIOShim(nil), ipsetConfig)
+	model, err := kernelModelFromSnapshot(snapshot)
+	require.NoError(t, err)
+	creator := pMgr.creatorForBootupWithCache(snapshot, model, currentChainLines)
+	actualLines := strings.Split(creator.ToString(), "\n")
+	expectedLines := []string{
+		"*filter",
+		":AZURE-NPM - -",
+		":AZURE-NPM-INGRESS - -",
+		":AZURE-NPM-INGRESS-ALLOW-MARK - -",
+		":AZURE-NPM-EGRESS - -",
+		":AZURE-NPM-ACCEPT - -",
+		fmt.Sprintf(":%s - -", bothDirectionsNetPolEgressChain),
+		fmt.Sprintf(":%s - -", egressNetPolChain),
+		fmt.Sprintf("-F %s", staleTestChain),
+		"-A AZURE-NPM -j AZURE-NPM-INGRESS",
+		"-A AZURE-NPM -j AZURE-NPM-EGRESS",
+		"-A AZURE-NPM -j AZURE-NPM-ACCEPT",
+		fmt.Sprintf("-A AZURE-NPM-INGRESS %s", ingressEgressNetPolIngressJump),
+		"-A AZURE-NPM-INGRESS -j DROP -m mark --mark 0x400/0x400 -m comment --comment DROP-ON-INGRESS-DROP-MARK-0x400/0x400",
+		"-A AZURE-NPM-INGRESS-ALLOW-MARK -j MARK --set-mark 0x200/0x200 -m comment --comment SET-INGRESS-ALLOW-MARK-0x200/0x200",
+		"-A AZURE-NPM-INGRESS-ALLOW-MARK -j AZURE-NPM-EGRESS",
+		fmt.Sprintf("-A AZURE-NPM-EGRESS %s", ingressEgressNetPolEgressJump),
+		fmt.Sprintf("-A AZURE-NPM-EGRESS %s", egressNetPolJump),
+		"-A AZURE-NPM-EGRESS -j DROP -m mark --mark 0x800/0x800 -m comment --comment DROP-ON-EGRESS-DROP-MARK-0x800/0x800",
+		"-A AZURE-NPM-EGRESS -j AZURE-NPM-ACCEPT -m mark --mark 0x200/0x200 -m comment --comment ACCEPT-ON-INGRESS-ALLOW-MARK-0x200/0x200",
+		"-A AZURE-NPM-ACCEPT -j ACCEPT",
+		fmt.Sprintf("-A %s %s", bothDirectionsNetPolEgressChain, egressDropRule),
+		fmt.Sprintf("-A %s %s", bothDirectionsNetPolEgressChain, egressAllowRule),
+		fmt.Sprintf("-A %s %s", egressNetPolChain, egressAllowRule),
+		"COMMIT",
+		"",
+	}
+	dptestutils.AssertEqualLines(t, expectedLines, actualLines)
+	assertStaleChainsContain(t, pMgr.staleChains, staleTestChain)
+}
+
+func TestBootupWithCache(t *testing.T) {
+	metrics.ReinitializeAll()
+	snapshot := testSnapshot()
+	iptablesSaveOutput := strings.Join(append([]string{
+		"*filter",
+		":AZURE-NPM - [0:0]",
+		fmt.Sprintf(":%s - [0:0]", bothDirectionsNetPolIngressChain),
+	}, snapshot.ChainLines[bothDirectionsNetPolIngressChain]...), "\n")
+	calls := []testutils.TestCmd{
+		{Cmd: []string{"iptables", "-w", "60", "-D", "FORWARD", "-j", "AZURE-NPM"}, ExitCode: 2}, //nolint // AZURE-NPM chain didn't exist
+		{Cmd: []string{"iptables-save", "-t", "filter"}, Stdout: iptablesSaveOutput},
+		fakeIPTablesRestoreCommand,
+		{Cmd: listLineNumbersCommandStrings, PipedToCommand: true},
+		{Cmd: []string{"grep", "AZURE-NPM"}, ExitCode: 1},
+		{Cmd: []string{"iptables", "-w", "60", "-I", "FORWARD", "-j", "AZURE-NPM", "-m", "conntrack", "--ctstate", "NEW"}},
+	}
+	calls = append(calls, GetUpdatePolicyTestCalls(bothDirectionsNetPol, bothDirectionsNetPol)...)
+	ioshim := common.NewMockIOShim(calls)
+	defer ioshim.VerifyCalls(t, calls)
+	pMgr := NewPolicyManager(ioshim, ipsetConfig)
+
+	require.NoError(t, pMgr.BootupWithCache(snapshot))
+	for _, policy := range snapshot.Policies {
+		cachedPolicy, ok := pMgr.GetPolicy(policy.PolicyKey)
+		require.True(t, ok)
+		require.Equal(t, policy, cachedPolicy)
+		require.True(t, pMgr.kernelModel.hasPolicy(policy))
+	}
+	promVals{numLinuxBaseACLRules + bothDirectionsNetPol.numACLRulesProducedInKernel() + egressNetPol.numACLRulesProducedInKernel(), 0}.testPrometheusMetrics(t)
+
+	// confirm one of the restored policies
+	require.NoError(t, pMgr.UpdatePolicy(bothDirectionsNetPol))
+	require.Equal(t, []string{egressNetPol.PolicyKey}, pMgr.TakeUnconfirmedPolicies())
+	require.Empty(t, pMgr.TakeUnconfirmedPolicies())
+}
+
+func TestKernelModelFromInvalidSnapshot(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(snapshot *PolicyManagerSnapshot)
+	}{
+		{
+			name: "duplicate policy",
+			modify: func(snapshot *PolicyManagerSnapshot) {
+				snapshot.Policies = append(snapshot.Policies, egressNetPol)
+			},
+		},
+		{
+			name: "policy without ACLs",
+			modify: func(snapshot *PolicyManagerSnapshot) {
+				policy := copyNetworkPolicy(egressNetPol)
+				policy.ACLs = nil
+				snapshot.Policies[1] = policy
+			},
+		},
+		{
+			name: "jump to missing policy",
+			modify: func(snapshot *PolicyManagerSnapshot) {
+				snapshot.Policies = snapshot.Policies[:1]
+			},
+		},
+		{
+			name: "missing jump",
+			modify: func(snapshot *PolicyManagerSnapshot) {
+				snapshot.Jumps[util.IptablesAzureIngressChain] = nil
+			},
+		},
+		{
+			name: "unexpected jump",
+			modify: func(snapshot *PolicyManagerSnapshot) {
+				snapshot.Jumps[util.IptablesAzureIngressChain] = append(snapshot.Jumps[util.IptablesAzureIngressChain], egressNetPol.PolicyKey)
+			},
+		},
+		{
+			name: "duplicate jump",
+			modify: func(snapshot *PolicyManagerSnapshot) {
+				snapshot.Jumps[util.IptablesAzureIngressChain] = append(snapshot.Jumps[util.IptablesAzureIngressChain], bothDirectionsNetPol.PolicyKey)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot := testSnapshot()
+			tt.modify(snapshot)
+			_, err := kernelModelFromSnapshot(snapshot)
+			require.ErrorIs(t, err, errInvalidSnapshot)
+		})
+	}
+}
@@ -41,6 +41,11 @@ func GetRemovePolicyTestCalls(policy *NPMNetworkPolicy) []testutils.TestCmd {
 	return calls
 }
 
+// GetUpdatePolicyTestCalls assumes the policy manager's model of the kernel is accurate, so the incremental restore succeeds
+func GetUpdatePolicyTestCalls(_, _ *NPMNetworkPolicy) []testutils.TestCmd {
+	return []testutils.TestCmd{fakeIPTablesRestoreCommand}
+}
+
 // GetRemovePolicyFailureTestCalls fails on the restore
 func GetRemovePolicyFailureTestCalls(policy *NPMNetworkPolicy) []testutils.TestCmd {
 	calls := GetRemovePolicyTestCalls(policy)
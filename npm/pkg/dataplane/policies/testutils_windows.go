@@ -10,6 +10,10 @@ func GetRemovePolicyTestCalls(_ *NPMNetworkPolicy) []testutils.TestCmd {
 	return []testutils.TestCmd{}
 }
 
+func GetUpdatePolicyTestCalls(_, _ *NPMNetworkPolicy) []testutils.TestCmd {
+	return []testutils.TestCmd{}
+}
+
 func GetBootupTestCalls() []testutils.TestCmd {
 	return []testutils.TestCmd{}
 }
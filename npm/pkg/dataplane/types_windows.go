@@ -1,6 +1,12 @@
 package dataplane
 
-import "github.com/Microsoft/hcsshim/hcn"
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/Microsoft/hcsshim/hcn"
+)
 
 const (
 	unspecifiedPodKey        = ""
@@ -41,3 +47,30 @@ func newNPMEndpoint(endpoint *hcn.HostComputeEndpoint) *npmEndpoint {
 func (ep *npmEndpoint) isStalePodKey(podKey string) bool {
 	return ep.stalePodKey != nil && ep.stalePodKey.key == podKey
 }
+
+// MarshalJSON encodes the endpoint for the NPM debug cache
+func (ep *npmEndpoint) MarshalJSON() ([]byte, error) {
+	netPolReference := make([]string, 0, len(ep.netPolReference))
+	for policyKey := range ep.netPolReference {
+		netPolReference = append(netPolReference, policyKey)
+	}
+	sort.Strings(netPolReference)
+
+	b, err := json.Marshal(struct {
+		Name            string
+		ID              string
+		IP              string
+		PodKey          string
+		NetPolReference []string
+	}{
+		Name:            ep.name,
+		ID:              ep.id,
+		IP:              ep.ip,
+		PodKey:          ep.podKey,
+		NetPolReference: netPolReference,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal endpoint %s: %w", ep.id, err)
+	}
+	return b, nil
+}
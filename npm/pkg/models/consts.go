@@ -14,6 +14,12 @@ const (
 	ListMap CacheKey = "ListMap"
 	SetMap  CacheKey = "SetMap"
 
+	// only in the cache of V2 daemons
+	IPSets           CacheKey = "IPSets"
+	Policies         CacheKey = "Policies"
+	Endpoints        CacheKey = "Endpoints"
+	AppliedGoalState CacheKey = "AppliedGoalState"
+
 	EnvNodeName = "HOSTNAME"
 )
 
@@ -19,6 +19,7 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type DataplaneEventsClient interface {
 	Connect(ctx context.Context, in *DatapathPodMetadata, opts ...grpc.CallOption) (DataplaneEvents_ConnectClient, error)
+	Ack(ctx context.Context, in *GoalStateAck, opts ...grpc.CallOption) (*AckResponse, error)
 }
 
 type dataplaneEventsClient struct {
@@ -61,11 +62,21 @@ func (x *dataplaneEventsConnectClient) Recv() (*Events, error) {
 	return m, nil
 }
 
+func (c *dataplaneEventsClient) Ack(ctx context.Context, in *GoalStateAck, opts ...grpc.CallOption) (*AckResponse, error) {
+	out := new(AckResponse)
+	err := c.cc.Invoke(ctx, "/protos.DataplaneEvents/Ack", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DataplaneEventsServer is the server API for DataplaneEvents service.
 // All implementations must embed UnimplementedDataplaneEventsServer
 // for forward compatibility
 type DataplaneEventsServer interface {
 	Connect(*DatapathPodMetadata, DataplaneEvents_ConnectServer) error
+	Ack(context.Context, *GoalStateAck) (*AckResponse, error)
 	mustEmbedUnimplementedDataplaneEventsServer()
 }
 
@@ -76,6 +87,9 @@ type UnimplementedDataplaneEventsServer struct {
 func (UnimplementedDataplaneEventsServer) Connect(*DatapathPodMetadata, DataplaneEvents_ConnectServer) error {
 	return status.Errorf(codes.Unimplemented, "method Connect not implemented")
 }
+func (UnimplementedDataplaneEventsServer) Ack(context.Context, *GoalStateAck) (*AckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Ack not implemented")
+}
 func (UnimplementedDataplaneEventsServer) mustEmbedUnimplementedDataplaneEventsServer() {}
 
 // UnsafeDataplaneEventsServer may be embedded to opt out of forward compatibility for this service.
@@ -110,13 +124,36 @@ func (x *dataplaneEventsConnectServer) Send(m *Events) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _DataplaneEvents_Ack_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GoalStateAck)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataplaneEventsServer).Ack(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.DataplaneEvents/Ack",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataplaneEventsServer).Ack(ctx, req.(*GoalStateAck))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // DataplaneEvents_ServiceDesc is the grpc.ServiceDesc for DataplaneEvents service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
 var DataplaneEvents_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "protos.DataplaneEvents",
 	HandlerType: (*DataplaneEventsServer)(nil),
-	Methods:     []grpc.MethodDesc{},
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Ack",
+			Handler:    _DataplaneEvents_Ack_Handler,
+		},
+	},
 	Streams: []grpc.StreamDesc{
 		{
 			StreamName:    "Connect",
@@ -0,0 +1,433 @@
+package simulator
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-container-networking/npm/util"
+	"github.com/Azure/azure-container-networking/npm/util/ioutil"
+	utilexec "k8s.io/utils/exec"
+	testingexec "k8s.io/utils/exec/testing"
+)
+
+const (
+	// exit codes returned by iptables
+	iptablesDoesNotExistExitCode = 1
+	// exit code returned by grep when no lines are selected
+	grepNoMatchExitCode = 1
+)
+
+// filterTable is an in-memory model of the iptables filter table.
+// It understands the subset of iptables, iptables-restore, and iptables-save that NPM uses.
+type filterTable struct {
+	sync.Mutex
+	// chainOrder keeps iptables-save output deterministic
+	chainOrder []string
+	chains     map[string][]string
+}
+
+func newFilterTable() *filterTable {
+	t := &filterTable{chains: make(map[string][]string)}
+	for _, chain := range []string{util.IptablesForwardChain, "INPUT", "OUTPUT"} {
+		t.createChain(chain)
+	}
+	return t
+}
+
+func (t *filterTable) createChain(chain string) {
+	if _, ok := t.chains[chain]; ok {
+		return
+	}
+	t.chainOrder = append(t.chainOrder, chain)
+	t.chains[chain] = nil
+}
+
+func (t *filterTable) deleteChain(chain string) bool {
+	if _, ok := t.chains[chain]; !ok {
+		return false
+	}
+	delete(t.chains, chain)
+	for i, c := range t.chainOrder {
+		if c == chain {
+			t.chainOrder = append(t.chainOrder[:i], t.chainOrder[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// apply runs one iptables rule command (e.g. -A CHAIN specs...).
+// Returns false if the command refers to a chain or rule that doesn't exist.
+func (t *filterTable) apply(args []string) bool {
+	if len(args) == 0 {
+		return true
+	}
+	flag := args[0]
+	if len(args) < 2 {
+		return false
+	}
+	chain := args[1]
+	specs := strings.Join(args[2:], " ")
+	switch flag {
+	case util.IptablesChainCreationFlag:
+		t.createChain(chain)
+		return true
+	case util.IptablesDestroyFlag:
+		return t.deleteChain(chain)
+	case util.IptablesFlushFlag:
+		if _, ok := t.chains[chain]; !ok {
+			return false
+		}
+		t.chains[chain] = nil
+		return true
+	}
+
+	rules, ok := t.chains[chain]
+	if !ok {
+		return false
+	}
+	switch flag {
+	case util.IptablesAppendFlag:
+		t.chains[chain] = append(rules, specs)
+	case util.IptablesInsertionFlag:
+		index := 1
+		if len(args) > 2 {
+			if i, err := strconv.Atoi(args[2]); err == nil {
+				index = i
+				specs = strings.Join(args[3:], " ")
+			}
+		}
+		if index < 1 || index > len(rules)+1 {
+			return false
+		}
+		rules = append(rules, "")
+		copy(rules[index:], rules[index-1:])
+		rules[index-1] = specs
+		t.chains[chain] = rules
+	case util.IptablesDeletionFlag:
+		for i, rule := range rules {
+			if rule == specs {
+				t.chains[chain] = append(rules[:i], rules[i+1:]...)
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+	return true
+}
+
+// restore applies an iptables-restore file with --noflush semantics.
+func (t *filterTable) restore(file []byte) {
+	scanner := bufio.NewScanner(bytes.NewReader(file))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || line == util.IptablesRestoreCommit || strings.HasPrefix(line, "*"):
+			continue
+		case strings.HasPrefix(line, ":"):
+			chain := strings.Fields(line[1:])[0]
+			if _, ok := t.chains[chain]; ok {
+				t.chains[chain] = nil
+			} else {
+				t.createChain(chain)
+			}
+		default:
+			t.apply(splitRuleLine(line))
+		}
+	}
+}
+
+// save returns the table in iptables-save format.
+func (t *filterTable) save() []byte {
+	var b bytes.Buffer
+	b.WriteString("*" + util.IptablesFilterTable + "\n")
+	for _, chain := range t.chainOrder {
+		fmt.Fprintf(&b, ":%s - [0:0]\n", chain)
+	}
+	for _, chain := range t.chainOrder {
+		for _, rule := range t.chains[chain] {
+			fmt.Fprintf(&b, "-A %s %s\n", chain, canonicalRuleSpecs(rule))
+		}
+	}
+	b.WriteString(util.IptablesRestoreCommit + "\n")
+	return b.Bytes()
+}
+
+// list returns the output of iptables -n -L [chain] [--line-numbers] with just enough detail for NPM's grep calls.
+func (t *filterTable) list(chain string) []byte {
+	var b bytes.Buffer
+	chains := t.chainOrder
+	if chain != "" {
+		chains = []string{chain}
+	}
+	for _, c := range chains {
+		fmt.Fprintf(&b, "Chain %s (0 references)\n", c)
+		for i, rule := range t.chains[c] {
+			target := ""
+			fields := strings.Fields(rule)
+			for j := 0; j < len(fields)-1; j++ {
+				if fields[j] == util.IptablesJumpFlag {
+					target = fields[j+1]
+				}
+			}
+			fmt.Fprintf(&b, "%d    %s  all  --  0.0.0.0/0  0.0.0.0/0\n", i+1, target)
+		}
+		b.WriteString("\n")
+	}
+	return b.Bytes()
+}
+
+// splitRuleLine splits a rule on spaces while keeping quoted comments together.
+func splitRuleLine(line string) []string {
+	fields := make([]string, 0)
+	var current strings.Builder
+	inQuotes := false
+	for _, r := range line {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			current.WriteRune(r)
+		case r == ' ' && !inQuotes:
+			if current.Len() > 0 {
+				fields = append(fields, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		fields = append(fields, current.String())
+	}
+	return fields
+}
+
+// canonicalRuleSpecs mimics iptables-save, which loads the protocol module
+// explicitly before port matches (e.g. -p tcp -m tcp --dport 80).
+func canonicalRuleSpecs(rule string) string {
+	fields := splitRuleLine(rule)
+	result := make([]string, 0, len(fields)+2)
+	protocol := ""
+	protocolModuleLoaded := false
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		switch field {
+		case util.IptablesProtFlag:
+			if i+1 < len(fields) {
+				protocol = strings.ToLower(fields[i+1])
+				result = append(result, field, protocol)
+				i++
+				continue
+			}
+		case util.IptablesModuleFlag:
+			if i+1 < len(fields) && strings.EqualFold(fields[i+1], protocol) {
+				protocolModuleLoaded = true
+			}
+		case util.IptablesDstPortFlag, "--sport":
+			if protocol != "" && !protocolModuleLoaded {
+				result = append(result, util.IptablesModuleFlag, protocol)
+				protocolModuleLoaded = true
+			}
+		}
+		result = append(result, field)
+	}
+	return strings.Join(result, " ")
+}
+
+// fakeExec implements utilexec.Interface on top of a filterTable.
+// Commands other than iptables and grep (e.g. ipset) always succeed without output.
+type fakeExec struct {
+	table *filterTable
+}
+
+var _ utilexec.Interface = &fakeExec{}
+
+func newFakeExec(table *filterTable) *fakeExec {
+	return &fakeExec{table: table}
+}
+
+func (e *fakeExec) Command(cmd string, args ...string) utilexec.Cmd {
+	return &fakeCmd{exec: e, name: cmd, args: args}
+}
+
+func (e *fakeExec) CommandContext(_ context.Context, cmd string, args ...string) utilexec.Cmd {
+	return e.Command(cmd, args...)
+}
+
+func (e *fakeExec) LookPath(file string) (string, error) {
+	return file, nil
+}
+
+type fakeCmd struct {
+	exec   *fakeExec
+	name   string
+	args   []string
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	pipeWriter *io.PipeWriter
+	done       chan error
+}
+
+var _ utilexec.Cmd = &fakeCmd{}
+
+func (c *fakeCmd) SetDir(string)                      {}
+func (c *fakeCmd) SetEnv([]string)                    {}
+func (c *fakeCmd) SetStdin(in io.Reader)              { c.stdin = in }
+func (c *fakeCmd) SetStdout(out io.Writer)            { c.stdout = out }
+func (c *fakeCmd) SetStderr(out io.Writer)            { c.stderr = out }
+func (c *fakeCmd) StderrPipe() (io.ReadCloser, error) { return io.NopCloser(&bytes.Buffer{}), nil }
+func (c *fakeCmd) Stop()                              {}
+
+func (c *fakeCmd) StdoutPipe() (io.ReadCloser, error) {
+	reader, writer := io.Pipe()
+	c.pipeWriter = writer
+	return reader, nil
+}
+
+func (c *fakeCmd) Start() error {
+	c.done = make(chan error, 1)
+	go func() {
+		output, err := c.run()
+		if c.pipeWriter != nil {
+			_, _ = c.pipeWriter.Write(output)
+			_ = c.pipeWriter.Close()
+		}
+		c.done <- err
+	}()
+	return nil
+}
+
+func (c *fakeCmd) Wait() error {
+	if c.done == nil {
+		return nil
+	}
+	return <-c.done
+}
+
+func (c *fakeCmd) Run() error {
+	output, err := c.run()
+	if c.stdout != nil {
+		_, _ = c.stdout.Write(output)
+	}
+	return err
+}
+
+func (c *fakeCmd) CombinedOutput() ([]byte, error) {
+	return c.run()
+}
+
+func (c *fakeCmd) Output() ([]byte, error) {
+	return c.run()
+}
+
+func (c *fakeCmd) readStdin() []byte {
+	if c.stdin == nil {
+		return nil
+	}
+	input, _ := io.ReadAll(c.stdin)
+	return input
+}
+
+func (c *fakeCmd) run() ([]byte, error) {
+	switch c.name {
+	case util.Iptables:
+		return c.runIPTables()
+	case util.IptablesRestore:
+		c.exec.table.Lock()
+		defer c.exec.table.Unlock()
+		c.exec.table.restore(c.readStdin())
+		return nil, nil
+	case util.IptablesSave:
+		c.exec.table.Lock()
+		defer c.exec.table.Unlock()
+		return c.exec.table.save(), nil
+	case ioutil.Grep:
+		return c.runGrep()
+	default:
+		// drain stdin so that piped writers never block
+		c.readStdin()
+		return nil, nil
+	}
+}
+
+func (c *fakeCmd) runIPTables() ([]byte, error) {
+	args := make([]string, 0, len(c.args))
+	listing := false
+	chain := ""
+	for i := 0; i < len(c.args); i++ {
+		switch c.args[i] {
+		case util.IptablesWaitFlag, util.IptablesTableFlag:
+			i++
+		case util.IptablesNumericFlag, util.IptablesLineNumbersFlag:
+		case util.IptablesListFlag:
+			listing = true
+			if i+1 < len(c.args) && !strings.HasPrefix(c.args[i+1], "-") {
+				chain = c.args[i+1]
+				i++
+			}
+		default:
+			args = append(args, c.args[i])
+		}
+	}
+
+	c.exec.table.Lock()
+	defer c.exec.table.Unlock()
+	if listing {
+		return c.exec.table.list(chain), nil
+	}
+	if !c.exec.table.apply(args) {
+		return []byte("iptables: Bad rule (does a matching rule exist in that chain?)."), &testingexec.FakeExitError{Status: iptablesDoesNotExistExitCode}
+	}
+	return nil, nil
+}
+
+func (c *fakeCmd) runGrep() ([]byte, error) {
+	quiet := false
+	invert := false
+	pattern := ""
+	for i := 0; i < len(c.args); i++ {
+		switch c.args[i] {
+		case ioutil.GrepQuietFlag:
+			quiet = true
+		case ioutil.GrepAntiMatchFlag:
+			invert = true
+		case ioutil.GrepRegexFlag, ioutil.GrepOnlyMatchingFlag:
+		case ioutil.GrepBeforeFlag:
+			i++
+		default:
+			pattern = c.args[i]
+		}
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		re = regexp.MustCompile(regexp.QuoteMeta(pattern))
+	}
+
+	var output bytes.Buffer
+	matched := false
+	scanner := bufio.NewScanner(bytes.NewReader(c.readStdin()))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if re.MatchString(line) != invert {
+			matched = true
+			if !quiet {
+				output.WriteString(line + "\n")
+			}
+		}
+	}
+	if !matched {
+		return nil, &testingexec.FakeExitError{Status: grepNoMatchExitCode}
+	}
+	return output.Bytes(), nil
+}
@@ -0,0 +1,113 @@
+package simulator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/klog"
+)
+
+const yamlDecoderBufferSize = 4096
+
+var manifestExtensions = map[string]struct{}{
+	".yaml": {},
+	".yml":  {},
+	".json": {},
+}
+
+// Manifests are the Kubernetes objects that NPM watches.
+type Manifests struct {
+	Namespaces      []*corev1.Namespace
+	Pods            []*corev1.Pod
+	NetworkPolicies []*networkingv1.NetworkPolicy
+}
+
+// LoadManifests reads all YAML and JSON files in the directory (recursively).
+// Objects of kinds other than Namespace, Pod, and NetworkPolicy are ignored.
+func LoadManifests(dir string) (*Manifests, error) {
+	m := &Manifests{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if _, ok := manifestExtensions[strings.ToLower(filepath.Ext(path))]; !ok {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read manifest %s: %w", path, err)
+		}
+		if err := m.decode(data); err != nil {
+			return fmt.Errorf("failed to decode manifest %s: %w", path, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load manifests from %s: %w", dir, err)
+	}
+	return m, nil
+}
+
+// decode adds every object in a (possibly multi-document) YAML or JSON file.
+func (m *Manifests) decode(data []byte) error {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), yamlDecoderBufferSize)
+	for {
+		var raw runtime.RawExtension
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to split documents: %w", err)
+		}
+		if len(bytes.TrimSpace(raw.Raw)) == 0 {
+			continue
+		}
+
+		obj, gvk, err := scheme.Codecs.UniversalDeserializer().Decode(raw.Raw, nil, nil)
+		if err != nil {
+			if runtime.IsNotRegisteredError(err) {
+				klog.Infof("[simulator] ignoring object of unknown kind: %s", err.Error())
+				continue
+			}
+			return fmt.Errorf("failed to decode object: %w", err)
+		}
+		if err := m.add(obj); err != nil {
+			return fmt.Errorf("failed to add object of kind %s: %w", gvk.Kind, err)
+		}
+	}
+}
+
+func (m *Manifests) add(obj runtime.Object) error {
+	switch o := obj.(type) {
+	case *corev1.Namespace:
+		m.Namespaces = append(m.Namespaces, o)
+	case *corev1.Pod:
+		m.Pods = append(m.Pods, o)
+	case *networkingv1.NetworkPolicy:
+		m.NetworkPolicies = append(m.NetworkPolicies, o)
+	case *corev1.List:
+		for i := range o.Items {
+			if err := m.decode(o.Items[i].Raw); err != nil {
+				return err
+			}
+		}
+	default:
+		klog.Infof("[simulator] ignoring object of type %T", obj)
+	}
+	return nil
+}
@@ -0,0 +1,362 @@
+// Package simulator evaluates NetworkPolicies offline.
+// Manifests are fed through the NPM v2 controllers, the translation package, and a DataPlane
+// backed by an in-memory iptables filter table, and traffic is analyzed with the same logic as npm debug gettuples.
+package simulator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-container-networking/common"
+	"github.com/Azure/azure-container-networking/npm"
+	npmconfig "github.com/Azure/azure-container-networking/npm/config"
+	npmcommon "github.com/Azure/azure-container-networking/npm/pkg/controlplane/controllers/common"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/debug"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/parse"
+	"github.com/Azure/azure-container-networking/npm/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	k8sversion "k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/informers"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/klog"
+)
+
+const (
+	// DefaultNodeName is the node that pods without a nodeName are scheduled on.
+	DefaultNodeName = "simulated-node"
+	// DefaultPodCIDR is the range that pods without a status.podIP get an IP from.
+	DefaultPodCIDR = "10.224.0.0/16"
+	// DefaultSyncTimeout bounds how long the controllers may take to program the dataplane.
+	DefaultSyncTimeout = time.Minute
+
+	npmVersion = "npm-simulator"
+
+	egressDirection  = "EGRESS"
+	ingressDirection = "INGRESS"
+	allowedRuleType  = "ALLOWED"
+
+	syncPollInterval = 100 * time.Millisecond
+)
+
+var (
+	ErrPodCIDRExhausted = errors.New("no more IPs available in pod CIDR")
+	ErrPodCIDRNotIPv4   = errors.New("pod CIDR must be IPv4")
+	ErrSyncTimeout      = errors.New("timed out waiting for controllers to program the dataplane")
+	ErrUnsupportedOS    = errors.New("the NetworkPolicy simulator only supports the Linux dataplane")
+)
+
+// Config for the Simulator.
+type Config struct {
+	// NodeName is the node that pods without a nodeName are scheduled on.
+	NodeName string
+	// PodCIDR is the range that pods without a status.podIP get an IP from.
+	PodCIDR string
+	// SyncTimeout bounds how long the controllers may take to program the dataplane.
+	SyncTimeout time.Duration
+}
+
+// DefaultConfig is the configuration the npm simulate command uses.
+var DefaultConfig = Config{
+	NodeName:    DefaultNodeName,
+	PodCIDR:     DefaultPodCIDR,
+	SyncTimeout: DefaultSyncTimeout,
+}
+
+// Simulator holds an NPM v2 dataplane programmed from manifests.
+type Simulator struct {
+	npMgr     *npm.NetworkPolicyManager
+	dp        *dataplane.DataPlane
+	ioShim    *common.IOShim
+	stopCh    chan struct{}
+	manifests *Manifests
+}
+
+// DirectionVerdict is the result of evaluating one direction (egress of the source or ingress of the destination).
+type DirectionVerdict struct {
+	Allowed bool
+	// Rule is the rule that decided the verdict.
+	// It is nil when no NetworkPolicy selects the pod in this direction.
+	Rule *debug.TupleAndRule
+}
+
+// Verdict is the result of a Query.
+type Verdict struct {
+	Allowed bool
+	Egress  DirectionVerdict
+	Ingress DirectionVerdict
+}
+
+// New programs a simulated dataplane from the manifests.
+// Pods without an IP are assigned one from the PodCIDR, and missing namespaces are created.
+// Call Stop to release the controllers.
+func New(m *Manifests, cfg Config) (*Simulator, error) {
+	if err := prepareManifests(m, cfg); err != nil {
+		return nil, err
+	}
+
+	s := &Simulator{
+		stopCh:    make(chan struct{}),
+		manifests: m,
+	}
+
+	var err error
+	s.ioShim, s.dp, err = newDataplane(cfg.NodeName, s.stopCh)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create simulated dataplane: %w", err)
+	}
+
+	objects := make([]runtime.Object, 0, len(m.Namespaces)+len(m.Pods)+len(m.NetworkPolicies))
+	for _, ns := range m.Namespaces {
+		objects = append(objects, ns)
+	}
+	for _, pod := range m.Pods {
+		objects = append(objects, pod)
+	}
+	for _, netpol := range m.NetworkPolicies {
+		objects = append(objects, netpol)
+	}
+	kubeclient := k8sfake.NewSimpleClientset(objects...)
+	factory := informers.NewSharedInformerFactory(kubeclient, 0)
+
+	npmCfg := npmconfig.DefaultConfig
+	npmCfg.Toggles.EnableV2NPM = true
+	npmCfg.Toggles.EnableHTTPDebugAPI = false
+	s.npMgr = npm.NewNetworkPolicyManager(npmCfg, factory, s.dp, s.ioShim.Exec, npmVersion, &k8sversion.Info{})
+	s.npMgr.NodeName = cfg.NodeName
+
+	if err := s.npMgr.Start(npmCfg, s.stopCh); err != nil {
+		s.Stop()
+		return nil, fmt.Errorf("failed to start NPM controllers: %w", err)
+	}
+
+	if err := s.waitForSync(cfg.SyncTimeout); err != nil {
+		s.Stop()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Stop stops the controllers.
+func (s *Simulator) Stop() {
+	select {
+	case <-s.stopCh:
+	default:
+		close(s.stopCh)
+	}
+}
+
+// waitForSync polls until every eligible object has been processed by the controllers.
+func (s *Simulator) waitForSync(timeout time.Duration) error {
+	expectedPods := 0
+	for _, pod := range s.manifests.Pods {
+		if !pod.Spec.HostNetwork {
+			expectedPods++
+		}
+	}
+	expectedNamespaces := len(s.manifests.Namespaces)
+	expectedPolicies := len(s.manifests.NetworkPolicies)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err := wait.PollImmediateUntil(syncPollInterval, func() (bool, error) {
+		return s.npMgr.PodControllerV2.LengthOfPodMap() == expectedPods &&
+			len(s.npMgr.NpmNamespaceCacheV2.GetCache()) == expectedNamespaces &&
+			s.npMgr.NetPolControllerV2.LengthOfRawNpMap() == expectedPolicies, nil
+	}, ctx.Done())
+	if err != nil {
+		return fmt.Errorf("%w: processed %d/%d pods, %d/%d namespaces, %d/%d network policies (a policy may have failed translation)",
+			ErrSyncTimeout,
+			s.npMgr.PodControllerV2.LengthOfPodMap(), expectedPods,
+			len(s.npMgr.NpmNamespaceCacheV2.GetCache()), expectedNamespaces,
+			s.npMgr.NetPolControllerV2.LengthOfRawNpMap(), expectedPolicies)
+	}
+	return nil
+}
+
+// converter returns a debug.Converter that reads from the simulated dataplane.
+func (s *Simulator) converter() (*debug.Converter, error) {
+	npmCache, err := json.Marshal(s.npMgr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal NPM cache: %w", err)
+	}
+
+	c := &debug.Converter{
+		Parser:            parse.IPTablesParser{IOShim: s.ioShim},
+		EnableV2NPM:       true,
+		CIDRBlockContents: make(map[string][]string),
+	}
+	if err := c.NpmCacheFromBytes(npmCache); err != nil {
+		return nil, fmt.Errorf("failed to load NPM cache: %w", err)
+	}
+
+	for hashedName, name := range s.dp.GetAllIPSets() {
+		if !strings.HasPrefix(name, util.CIDRPrefix) {
+			continue
+		}
+		set := s.dp.GetIPSet(name)
+		if set == nil {
+			continue
+		}
+		for member := range set.IPPodKey {
+			c.CIDRBlockContents[hashedName] = append(c.CIDRBlockContents[hashedName], member)
+		}
+	}
+	return c, nil
+}
+
+// Query reports whether traffic from src to dst on the port and protocol is allowed and which rules decided it.
+// src and dst can be a pod ("namespace/name"), a pod IP, or "External".
+// A port of 0 matches only rules that allow all ports.
+func (s *Simulator) Query(src, dst string, port int32, protocol string) (*Verdict, error) {
+	c, err := s.converter()
+	if err != nil {
+		return nil, err
+	}
+
+	srcInput := &npmcommon.Input{Content: src, Type: npmcommon.GetInputType(src)}
+	dstInput := &npmcommon.Input{Content: dst, Type: npmcommon.GetInputType(dst)}
+	_, tuples, _, _, err := c.GetNetworkTupleFromParser(srcInput, dstInput)
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze traffic from %s to %s: %w", src, dst, err)
+	}
+
+	v := &Verdict{
+		Egress:  decide(tuples, egressDirection, port, protocol),
+		Ingress: decide(tuples, ingressDirection, port, protocol),
+	}
+	v.Allowed = v.Egress.Allowed && v.Ingress.Allowed
+	return v, nil
+}
+
+// decide mirrors the NPM v2 chains: an allow rule for the port wins, otherwise a drop rule for
+// the selected pod denies, and traffic is allowed when no policy selects the pod.
+func decide(tuples []*debug.TupleAndRule, direction string, port int32, protocol string) DirectionVerdict {
+	var drop *debug.TupleAndRule
+	for _, tuple := range tuples {
+		if tuple.Tuple.Direction != direction || !matchesPortAndProtocol(tuple, port, protocol) {
+			continue
+		}
+		if tuple.Tuple.RuleType == allowedRuleType {
+			return DirectionVerdict{Allowed: true, Rule: tuple}
+		}
+		if drop == nil {
+			drop = tuple
+		}
+	}
+	if drop != nil {
+		return DirectionVerdict{Allowed: false, Rule: drop}
+	}
+	return DirectionVerdict{Allowed: true}
+}
+
+func matchesPortAndProtocol(tuple *debug.TupleAndRule, port int32, protocol string) bool {
+	rule := tuple.Rule
+	if rule.DPort != 0 && rule.DPort != port {
+		return false
+	}
+	return rule.Protocol == "" || protocol == "" || strings.EqualFold(rule.Protocol, protocol)
+}
+
+// prepareManifests fills in what the API server and kubelet would have set.
+func prepareManifests(m *Manifests, cfg Config) error {
+	namespaces := make(map[string]*corev1.Namespace, len(m.Namespaces))
+	for _, ns := range m.Namespaces {
+		namespaces[ns.Name] = ns
+	}
+	ensureNamespace := func(name string) {
+		if _, ok := namespaces[name]; ok {
+			return
+		}
+		ns := &corev1.Namespace{}
+		ns.Name = name
+		namespaces[name] = ns
+		m.Namespaces = append(m.Namespaces, ns)
+	}
+
+	_, podCIDR, err := net.ParseCIDR(cfg.PodCIDR)
+	if err != nil {
+		return fmt.Errorf("failed to parse pod CIDR %s: %w", cfg.PodCIDR, err)
+	}
+	if podCIDR.IP.To4() == nil {
+		return fmt.Errorf("%w: %s", ErrPodCIDRNotIPv4, cfg.PodCIDR)
+	}
+	usedIPs := make(map[string]struct{}, len(m.Pods))
+	for _, pod := range m.Pods {
+		if pod.Status.PodIP != "" {
+			usedIPs[pod.Status.PodIP] = struct{}{}
+		}
+	}
+	nextIP := nextIPFunc(podCIDR, usedIPs)
+
+	for _, pod := range m.Pods {
+		if pod.Namespace == "" {
+			pod.Namespace = corev1.NamespaceDefault
+		}
+		ensureNamespace(pod.Namespace)
+		if pod.Spec.NodeName == "" {
+			pod.Spec.NodeName = cfg.NodeName
+		}
+		if pod.Status.Phase == "" {
+			pod.Status.Phase = corev1.PodRunning
+		}
+		if pod.Status.PodIP == "" && !pod.Spec.HostNetwork {
+			ip, err := nextIP()
+			if err != nil {
+				return fmt.Errorf("failed to assign IP to pod %s/%s: %w", pod.Namespace, pod.Name, err)
+			}
+			pod.Status.PodIP = ip
+			klog.Infof("[simulator] assigned IP %s to pod %s/%s", ip, pod.Namespace, pod.Name)
+		}
+	}
+
+	for _, netpol := range m.NetworkPolicies {
+		if netpol.Namespace == "" {
+			netpol.Namespace = corev1.NamespaceDefault
+		}
+		ensureNamespace(netpol.Namespace)
+	}
+
+	// the API server labels every namespace with its name
+	for _, ns := range m.Namespaces {
+		if ns.Labels == nil {
+			ns.Labels = make(map[string]string)
+		}
+		if _, ok := ns.Labels[corev1.LabelMetadataName]; !ok {
+			ns.Labels[corev1.LabelMetadataName] = ns.Name
+		}
+	}
+	return nil
+}
+
+// nextIPFunc returns a generator of unused IPv4 addresses in the CIDR, skipping the network address.
+func nextIPFunc(cidr *net.IPNet, usedIPs map[string]struct{}) func() (string, error) {
+	ip := cidr.IP.To4()
+	current := make(net.IP, len(ip))
+	copy(current, ip)
+	return func() (string, error) {
+		for {
+			for i := len(current) - 1; i >= 0; i-- {
+				current[i]++
+				if current[i] != 0 {
+					break
+				}
+			}
+			if !cidr.Contains(current) {
+				return "", ErrPodCIDRExhausted
+			}
+			candidate := current.String()
+			if _, ok := usedIPs[candidate]; !ok {
+				usedIPs[candidate] = struct{}{}
+				return candidate, nil
+			}
+		}
+	}
+}
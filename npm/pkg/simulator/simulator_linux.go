@@ -0,0 +1,31 @@
+package simulator
+
+import (
+	"fmt"
+
+	"github.com/Azure/azure-container-networking/common"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/policies"
+	"github.com/Azure/azure-container-networking/npm/util"
+)
+
+func newDataplane(nodeName string, stopCh <-chan struct{}) (*common.IOShim, *dataplane.DataPlane, error) {
+	ioShim := &common.IOShim{Exec: newFakeExec(newFilterTable())}
+	cfg := &dataplane.Config{
+		IPSetManagerCfg: &ipsets.IPSetManagerCfg{
+			IPSetMode:   ipsets.ApplyAllIPSets,
+			NetworkName: "azure",
+		},
+		PolicyManagerCfg: &policies.PolicyManagerCfg{
+			PolicyMode:           policies.IPSetPolicyMode,
+			PlaceAzureChainFirst: util.PlaceAzureChainFirst,
+		},
+	}
+
+	dp, err := dataplane.NewDataPlane(nodeName, ioShim, cfg, stopCh)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to boot up dataplane: %w", err)
+	}
+	return ioShim, dp, nil
+}
@@ -0,0 +1,105 @@
+package simulator
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-container-networking/npm/util"
+	"github.com/stretchr/testify/require"
+)
+
+const testManifestsDir = "testdata"
+
+func TestLoadManifests(t *testing.T) {
+	m, err := LoadManifests(testManifestsDir)
+	require.NoError(t, err)
+	require.Len(t, m.Namespaces, 1)
+	require.Len(t, m.Pods, 3)
+	require.Len(t, m.NetworkPolicies, 1)
+}
+
+func TestQuery(t *testing.T) {
+	if util.IsWindowsDP() {
+		return
+	}
+
+	m, err := LoadManifests(testManifestsDir)
+	require.NoError(t, err)
+	s, err := New(m, DefaultConfig)
+	require.NoError(t, err)
+	defer s.Stop()
+
+	tests := []struct {
+		name            string
+		src             string
+		dst             string
+		port            int32
+		protocol        string
+		wantAllowed     bool
+		wantIngressRule bool
+	}{
+		{
+			name:            "backend to frontend on allowed port",
+			src:             "testnamespace/backend",
+			dst:             "testnamespace/frontend",
+			port:            8000,
+			protocol:        "tcp",
+			wantAllowed:     true,
+			wantIngressRule: true,
+		},
+		{
+			name:            "backend to frontend on other port",
+			src:             "testnamespace/backend",
+			dst:             "testnamespace/frontend",
+			port:            9000,
+			protocol:        "tcp",
+			wantAllowed:     false,
+			wantIngressRule: true,
+		},
+		{
+			name:            "other pod to frontend",
+			src:             "testnamespace/other",
+			dst:             "testnamespace/frontend",
+			port:            8000,
+			protocol:        "tcp",
+			wantAllowed:     false,
+			wantIngressRule: true,
+		},
+		{
+			name:            "frontend to backend is not selected by any policy",
+			src:             "testnamespace/frontend",
+			dst:             "testnamespace/backend",
+			port:            8000,
+			protocol:        "tcp",
+			wantAllowed:     true,
+			wantIngressRule: false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := s.Query(tt.src, tt.dst, tt.port, tt.protocol)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantAllowed, v.Allowed)
+			require.True(t, v.Egress.Allowed)
+			require.Equal(t, tt.wantIngressRule, v.Ingress.Rule != nil)
+		})
+	}
+}
+
+func TestPrepareManifestsAssignsIPs(t *testing.T) {
+	m, err := LoadManifests(testManifestsDir)
+	require.NoError(t, err)
+	m.Pods[0].Status.PodIP = "10.224.0.1"
+
+	require.NoError(t, prepareManifests(m, DefaultConfig))
+	seen := make(map[string]struct{})
+	for _, pod := range m.Pods {
+		require.NotEmpty(t, pod.Status.PodIP)
+		require.Equal(t, DefaultNodeName, pod.Spec.NodeName)
+		_, ok := seen[pod.Status.PodIP]
+		require.False(t, ok, "duplicate IP %s", pod.Status.PodIP)
+		seen[pod.Status.PodIP] = struct{}{}
+	}
+	require.Equal(t, "testnamespace", m.Namespaces[0].Labels["kubernetes.io/metadata.name"])
+}
@@ -0,0 +1,10 @@
+package simulator
+
+import (
+	"github.com/Azure/azure-container-networking/common"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane"
+)
+
+func newDataplane(_ string, _ <-chan struct{}) (*common.IOShim, *dataplane.DataPlane, error) {
+	return nil, nil, ErrUnsupportedOS
+}
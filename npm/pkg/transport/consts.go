@@ -1,6 +1,15 @@
 package transport
 
+import "time"
+
 const (
 	// concurrentInputRegistrations = 10
 	grpcMaxConcurrentStreams = 100
+
+	// ackChannelSize is the number of acks a client buffers before blocking event processing
+	ackChannelSize = 10
+	// ackTimeout is how long the server waits for a daemon to ack a goal state before rehydrating its node
+	ackTimeout = 2 * time.Minute
+	// rehydrateInterval is how often the server checks for nodes to rehydrate
+	rehydrateInterval = 30 * time.Second
 )
@@ -0,0 +1,82 @@
+package transport
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Azure/azure-container-networking/npm/pkg/protos"
+	"k8s.io/klog/v2"
+)
+
+// eventSender sends the events of a client in order from a single goroutine, since a gRPC stream doesn't support
+// concurrent SendMsg calls. Events are queued without bound, so that a slow client doesn't block the EventsServer loop.
+type eventSender struct {
+	client string
+	stream protos.DataplaneEvents_ConnectServer
+
+	sync.Mutex
+	queue []*protos.Events
+	// queued is signaled when events are queued
+	queued   chan struct{}
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+func newEventSender(client string, stream protos.DataplaneEvents_ConnectServer) *eventSender {
+	return &eventSender{
+		client: client,
+		stream: stream,
+		queued: make(chan struct{}, 1),
+		stopCh: make(chan struct{}),
+	}
+}
+
+// send queues the event to be sent to the client
+func (s *eventSender) send(event *protos.Events) {
+	s.Lock()
+	s.queue = append(s.queue, event)
+	s.Unlock()
+
+	select {
+	case s.queued <- struct{}{}:
+	default:
+	}
+}
+
+// run sends the queued events until the context is done or the sender is stopped
+func (s *eventSender) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopCh:
+			return
+		case <-s.queued:
+			// the sender may have been stopped while events were queued
+			select {
+			case <-s.stopCh:
+				return
+			default:
+			}
+		}
+
+		s.Lock()
+		events := s.queue
+		s.queue = nil
+		s.Unlock()
+
+		for _, event := range events {
+			klog.Infof("######## Servicing the event to %s ######", s.client)
+			if err := s.stream.SendMsg(event); err != nil {
+				klog.Errorf("Failed to send message to client %s: %v", s.client, err)
+			}
+		}
+	}
+}
+
+// stop stops sending events to a client which deregistered
+func (s *eventSender) stop() {
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+}
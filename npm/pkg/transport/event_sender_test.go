@@ -0,0 +1,64 @@
+package transport
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-container-networking/npm/pkg/protos"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConnectServer records the events sent on the stream, and fails the test on concurrent sends.
+type fakeConnectServer struct {
+	protos.DataplaneEvents_ConnectServer
+	t       *testing.T
+	sending int32
+	mu      sync.Mutex
+	sent    []uint64
+}
+
+func (f *fakeConnectServer) SendMsg(m interface{}) error {
+	if !atomic.CompareAndSwapInt32(&f.sending, 0, 1) {
+		f.t.Error("concurrent SendMsg on the stream")
+	}
+	defer atomic.StoreInt32(&f.sending, 0)
+	time.Sleep(time.Millisecond)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, m.(*protos.Events).GetGeneration())
+	return nil
+}
+
+func (f *fakeConnectServer) sentGenerations() []uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]uint64(nil), f.sent...)
+}
+
+func TestEventSenderSendsInOrder(t *testing.T) {
+	stream := &fakeConnectServer{t: t}
+	sender := newEventSender("10.0.0.4:5000", stream)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go sender.run(ctx)
+
+	expected := make([]uint64, 0, 20)
+	for generation := uint64(1); generation <= 20; generation++ {
+		sender.send(&protos.Events{Generation: generation})
+		expected = append(expected, generation)
+	}
+	require.Eventually(t, func() bool {
+		return len(stream.sentGenerations()) == len(expected)
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, expected, stream.sentGenerations())
+
+	// events aren't sent after the sender is stopped
+	sender.stop()
+	sender.stop()
+	sender.send(&protos.Events{Generation: 21})
+	time.Sleep(50 * time.Millisecond)
+	require.Len(t, stream.sentGenerations(), len(expected))
+}
@@ -20,6 +20,15 @@ type EventsClient struct {
 	serverAddr string
 
 	outCh chan *protos.Events
+	ackCh chan *protos.GoalStateAck
+
+	// digester provides the digests sent when reconnecting, if set
+	digester StateDigester
+}
+
+// StateDigester returns digests of the IPSets and NetworkPolicies applied by the client
+type StateDigester interface {
+	StateDigests() (ipsetDigests, policyDigests map[string]string)
 }
 
 var (
@@ -60,6 +69,7 @@ func NewEventsClient(ctx context.Context, pod, node, addr string) (*EventsClient
 		node:                  node,
 		serverAddr:            addr,
 		outCh:                 make(chan *protos.Events),
+		ackCh:                 make(chan *protos.GoalStateAck, ackChannelSize),
 	}, nil
 }
 
@@ -67,17 +77,49 @@ func (c *EventsClient) EventsChannel() chan *protos.Events {
 	return c.outCh
 }
 
+// AckChannel returns the channel for acks of processed events, which are sent to the controller
+func (c *EventsClient) AckChannel() chan<- *protos.GoalStateAck {
+	return c.ackCh
+}
+
+// SetStateDigester makes the client send digests of its applied state when reconnecting,
+// so the controller (e.g. a new leader) only sends what changed instead of hydrating the client.
+// It must be called before Start.
+func (c *EventsClient) SetStateDigester(digester StateDigester) {
+	c.digester = digester
+}
+
 func (c *EventsClient) Start(stopCh <-chan struct{}) error {
 	go c.run(c.ctx, stopCh) //nolint:errcheck // ignore error since this is a go routine
+	go c.sendAcks(c.ctx, stopCh)
 	return nil
 }
 
+// sendAcks reports the applied goal state generations to the controller.
+// A failed ack is not retried since the controller rehydrates nodes which don't ack in time.
+func (c *EventsClient) sendAcks(ctx context.Context, stopCh <-chan struct{}) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stopCh:
+			return
+		case ack := <-c.ackCh:
+			if _, err := c.Ack(ctx, ack); err != nil {
+				klog.Errorf("failed to ack goal state generation %d: %v", ack.GetGeneration(), err)
+			}
+		}
+	}
+}
+
 func (c *EventsClient) run(ctx context.Context, stopCh <-chan struct{}) error {
 	var connectClient protos.DataplaneEvents_ConnectClient
 	var err error
 	clientMetadata := &protos.DatapathPodMetadata{
 		PodName:  c.pod,
 		NodeName: c.node,
+		// the GoalStateProcessor applies IPSetDeltas and PolicyDeltas
+		ApiVersion: protos.DatapathPodMetadata_V2,
 	}
 	for {
 		select {
@@ -90,6 +132,9 @@ func (c *EventsClient) run(ctx context.Context, stopCh <-chan struct{}) error {
 		default:
 			if connectClient == nil {
 				klog.Info("Reconnecting to gRPC server controller")
+				if c.digester != nil {
+					clientMetadata.IpsetDigests, clientMetadata.PolicyDigests = c.digester.StateDigests()
+				}
 				opts := []grpc.CallOption{grpc.WaitForReady(false)}
 				connectClient, err = c.Connect(ctx, clientMetadata, opts...)
 				if err != nil {
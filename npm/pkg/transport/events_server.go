@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"time"
 
 	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/dpshim"
 	"github.com/Azure/azure-container-networking/npm/pkg/protos"
@@ -30,8 +31,8 @@ type EventsServer struct {
 	// port is the port the manager is listening on
 	port int
 
-	// inCh is the input channel for the manager
-	inCh chan *protos.Events
+	// inCh is the input channel for the manager, which has events for each node
+	inCh chan dpshim.NodeEvents
 
 	// regCh is the registration channel
 	regCh chan clientStreamConnection
@@ -39,6 +40,16 @@ type EventsServer struct {
 	// deregCh is the deregistration channel
 	deregCh chan deregistrationEvent
 
+	// ackCh receives the acks of daemons after processing events
+	ackCh chan *protos.GoalStateAck
+
+	// goalStates tracks the goal state status of each node
+	goalStates *goalStateTracker
+
+	// generations has the goal state generation each client was last hydrated to.
+	// Goal state events up to that generation are already included in the hydration, so they aren't sent to the client.
+	generations map[string]uint64
+
 	// errCh is the error channel
 	errCh chan error
 
@@ -54,9 +65,12 @@ func NewEventsServer(ctx context.Context, port int, dp *dpshim.DPShim) *EventsSe
 	// Create a deregistration channel
 	deregCh := make(chan deregistrationEvent, grpcMaxConcurrentStreams)
 
+	// Create an ack channel
+	ackCh := make(chan *protos.GoalStateAck, grpcMaxConcurrentStreams)
+
 	return &EventsServer{
 		ctx:           ctx,
-		Server:        NewServer(ctx, regCh),
+		Server:        NewServer(ctx, regCh, ackCh),
 		Watchdog:      NewWatchdog(deregCh),
 		Registrations: make(map[string]clientStreamConnection),
 		port:          port,
@@ -64,12 +78,20 @@ func NewEventsServer(ctx context.Context, port int, dp *dpshim.DPShim) *EventsSe
 		errCh:         make(chan error),
 		deregCh:       deregCh,
 		regCh:         regCh,
+		ackCh:         ackCh,
+		goalStates:    newGoalStateTracker(),
+		generations:   make(map[string]uint64),
 		dp:            dp,
 	}
 }
 
+// GoalStateStatus returns the goal state status of each node with connected daemons
+func (m *EventsServer) GoalStateStatus() map[string]NodeGoalStateStatus {
+	return m.goalStates.snapshot()
+}
+
 // InputChannel returns the input channel for the manager
-func (m *EventsServer) InputChannel() chan *protos.Events {
+func (m *EventsServer) InputChannel() chan dpshim.NodeEvents {
 	return m.inCh
 }
 
@@ -88,6 +110,9 @@ func (m *EventsServer) start(stopCh <-chan struct{}) error {
 		return fmt.Errorf("failed to start transport manager handlers: %w", err)
 	}
 
+	rehydrateTicker := time.NewTicker(rehydrateInterval)
+	defer rehydrateTicker.Stop()
+
 	for {
 		select {
 		case client := <-m.regCh:
@@ -98,22 +123,33 @@ func (m *EventsServer) start(stopCh <-chan struct{}) error {
 			// 2. Nested IPSets
 			// 3. Network Policies
 			// within the same castegory we will have to paginate.
-			klog.Infof("Registering remote client %s", client)
+			klog.Infof("Registering remote client %s on node %s", client, client.GetNodeName())
+			if oldClient, ok := m.Registrations[client.String()]; ok {
+				// the client reconnected before its deregistration event
+				m.dp.RemoveClient(oldClient.DatapathPodMetadata)
+				oldClient.sender.stop()
+			}
+			client.sender = newEventSender(client.String(), client.stream)
+			go client.sender.run(m.ctx)
 			m.Registrations[client.String()] = client
-			event, err := m.dp.HydrateClients()
+			m.goalStates.register(client.GetNodeName(), client.GetPodName())
+			// events computed before the client registered are included in the hydration
+			m.generations[client.String()] = m.dp.Generation()
+			event, err := m.dp.HydrateClients(client.DatapathPodMetadata)
 			if err != nil {
 				klog.Errorf("Failed to hydrate client %s: %v", client, err)
 			}
+			if event == nil {
+				continue
+			}
+			m.generations[client.String()] = event.GetGeneration()
 			// (TODO) Hydration event takes a lock of whole DPShim instance, essentially blocking the
 			// controllers from receiving any more new events or servicing existing daemons.
 			// So we will need to add a buffering mechanism to wait until either we have a N number of daemons
 			// or hit S milliseconds of wait time and send huydration event to all the buffered daemons.
-			go func() {
-				klog.Infof("Hydrating remote client %s", client)
-				if err := client.stream.SendMsg(event); err != nil {
-					klog.Errorf("Failed to hydrate client %s: %v", client, err)
-				}
-			}()
+			klog.Infof("Hydrating remote client %s", client)
+			client.sender.send(event)
+			m.goalStates.sent(client.GetNodeName(), client.GetPodName(), event, time.Now())
 		case ev := <-m.deregCh:
 			// (TODO) A heart beat for each daemon should also be added alongside watchdog to monitor
 			// daemon restarts and then if that fails, we will need to delete the client.
@@ -122,21 +158,33 @@ func (m *EventsServer) start(stopCh <-chan struct{}) error {
 				if v.timestamp <= ev.timestamp {
 					klog.Infof("Deregistering remote client %s", ev.remoteAddr)
 					delete(m.Registrations, ev.remoteAddr)
+					delete(m.generations, ev.remoteAddr)
+					v.sender.stop()
+					m.dp.RemoveClient(v.DatapathPodMetadata)
+					if !m.hasPodRegistration(v.GetNodeName(), v.GetPodName()) {
+						// the pod may have reconnected from another address
+						m.goalStates.remove(v.GetNodeName(), v.GetPodName())
+					}
 				} else {
 					klog.Info("Ignoring stale deregistration event")
 				}
 			}
-		case msg := <-m.inCh:
-			klog.Infof("######## Received event to broadcast ######")
-			for clientName, client := range m.Registrations {
-				// (TODO) Should we call this SendMsg per client in a separate go routine?
-				klog.Infof("######## Servicing the event to %s ######", clientName)
-				if err := client.stream.SendMsg(msg); err != nil {
-					// (TODO) What happens if a portion of the clients fails?
-					// there should be a mechanism to retry the failed clients.
-					klog.Errorf("Failed to send message to client %s: %v", client, err)
-				}
+		case events := <-m.inCh:
+			klog.Infof("######## Received events for %d nodes ######", len(events))
+			for nodeName, msg := range events {
+				m.sendToNode(nodeName, msg)
+			}
+		case ack := <-m.ackCh:
+			if ack.GetError() != "" {
+				klog.Errorf("Client %s on node %s failed to apply goal state generation %d: %s",
+					ack.GetPodName(), ack.GetNodeName(), ack.GetGeneration(), ack.GetError())
+			}
+			if !m.goalStates.acked(ack, time.Now()) {
+				klog.Infof("Ignoring ack of goal state generation %d from client %s on node %s, which is not registered or acked a later generation",
+					ack.GetGeneration(), ack.GetPodName(), ack.GetNodeName())
 			}
+		case <-rehydrateTicker.C:
+			m.rehydrateLaggingNodes()
 		case <-m.ctx.Done():
 			klog.Info("Context Done. Stopping transport manager")
 			return nil
@@ -150,6 +198,63 @@ func (m *EventsServer) start(stopCh <-chan struct{}) error {
 	}
 }
 
+// sendToNode queues the event for all clients on the node, except goal state events which a client's hydration already included.
+// A client which fails to receive or apply the event is rehydrated once the ack timeout passes.
+func (m *EventsServer) sendToNode(nodeName string, msg *protos.Events) {
+	if !m.hasRegistrations(nodeName) {
+		// the node's clients deregistered after the event was computed
+		return
+	}
+
+	for clientName, client := range m.Registrations {
+		if client.GetNodeName() != nodeName {
+			continue
+		}
+		if msg.GetEventType() == protos.Events_Hydration {
+			m.generations[clientName] = msg.GetGeneration()
+		} else if msg.GetGeneration() <= m.generations[clientName] {
+			klog.Infof("Skipping goal state generation %d for client %s hydrated to generation %d", msg.GetGeneration(), clientName, m.generations[clientName])
+			continue
+		}
+		client.sender.send(msg)
+		m.goalStates.sent(nodeName, client.GetPodName(), msg, time.Now())
+	}
+}
+
+// rehydrateLaggingNodes sends a hydration event to nodes which failed to apply or didn't ack their last event in time
+func (m *EventsServer) rehydrateLaggingNodes() {
+	for _, nodeName := range m.goalStates.nodesToRehydrate(time.Now()) {
+		event, err := m.dp.RehydrateNode(nodeName)
+		if err != nil {
+			klog.Errorf("Failed to rehydrate node %s: %v", nodeName, err)
+			continue
+		}
+		if event == nil {
+			continue
+		}
+		klog.Infof("Rehydrating lagging node %s", nodeName)
+		m.sendToNode(nodeName, event)
+	}
+}
+
+func (m *EventsServer) hasRegistrations(nodeName string) bool {
+	for _, client := range m.Registrations {
+		if client.GetNodeName() == nodeName {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *EventsServer) hasPodRegistration(nodeName, podName string) bool {
+	for _, client := range m.Registrations {
+		if client.GetNodeName() == nodeName && client.GetPodName() == podName {
+			return true
+		}
+	}
+	return false
+}
+
 func (m *EventsServer) handle() error {
 	klog.Infof("Starting transport manager listener on port %v", m.port)
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", m.port))
@@ -158,7 +263,7 @@ func (m *EventsServer) handle() error {
 	}
 
 	// load the server certificates
-	creds, err := serverTLSCreds()
+	creds, err := serverTLSCreds(m.ctx)
 	if err != nil {
 		return fmt.Errorf("failed to load TLS certificates: %w", err)
 	}
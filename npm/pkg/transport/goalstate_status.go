@@ -0,0 +1,190 @@
+package transport
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-container-networking/npm/metrics"
+	"github.com/Azure/azure-container-networking/npm/pkg/protos"
+)
+
+// NodeGoalStateStatus is the goal state status of the daemons connected from a node, as seen by the controlplane
+type NodeGoalStateStatus struct {
+	// Clients has the status of each daemon on the node, keyed by pod name
+	Clients map[string]ClientGoalStateStatus
+}
+
+// Lag returns the largest number of generations sent to a daemon on the node but not yet applied
+func (s NodeGoalStateStatus) Lag() uint64 {
+	var lag uint64
+	for _, client := range s.Clients {
+		if clientLag := client.Lag(); clientLag > lag {
+			lag = clientLag
+		}
+	}
+	return lag
+}
+
+// Failed returns true if a daemon on the node failed to apply the generation it last acked
+func (s NodeGoalStateStatus) Failed() bool {
+	for _, client := range s.Clients {
+		if client.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientGoalStateStatus is the goal state status of a connected daemon, as seen by the controlplane
+type ClientGoalStateStatus struct {
+	// SentGeneration is the generation of the last event sent to the daemon
+	SentGeneration uint64
+	// AppliedGeneration is the generation last acked by the daemon
+	AppliedGeneration uint64
+	// Error is the error the daemon hit while applying AppliedGeneration, if any
+	Error    string
+	LastSent time.Time
+	LastAck  time.Time
+	// Hydrations is the number of hydration events sent to the daemon, including the one after it connects
+	Hydrations int
+}
+
+// Lag returns the number of generations sent to the daemon but not yet applied
+func (s ClientGoalStateStatus) Lag() uint64 {
+	if s.AppliedGeneration >= s.SentGeneration {
+		return 0
+	}
+	return s.SentGeneration - s.AppliedGeneration
+}
+
+// needsRehydration returns true if the daemon failed to apply or didn't ack its last event within the ack timeout
+func (s ClientGoalStateStatus) needsRehydration(now time.Time) bool {
+	if now.Sub(s.LastSent) < ackTimeout {
+		return false
+	}
+	return s.Error != "" || s.Lag() > 0
+}
+
+// goalStateTracker tracks the goal state status of each daemon, by node.
+// It's updated by the EventsServer loop and read by the HTTP debug API.
+type goalStateTracker struct {
+	sync.RWMutex
+	nodes map[string]map[string]*ClientGoalStateStatus
+}
+
+func newGoalStateTracker() *goalStateTracker {
+	return &goalStateTracker{
+		nodes: make(map[string]map[string]*ClientGoalStateStatus),
+	}
+}
+
+// register starts tracking a newly connected daemon
+func (t *goalStateTracker) register(nodeName, podName string) {
+	t.Lock()
+	defer t.Unlock()
+
+	clients, ok := t.nodes[nodeName]
+	if !ok {
+		clients = make(map[string]*ClientGoalStateStatus)
+		t.nodes[nodeName] = clients
+	}
+	if _, ok := clients[podName]; !ok {
+		clients[podName] = &ClientGoalStateStatus{}
+	}
+	t.updateMetrics(nodeName)
+}
+
+// sent records an event sent to a daemon
+func (t *goalStateTracker) sent(nodeName, podName string, event *protos.Events, now time.Time) {
+	t.Lock()
+	defer t.Unlock()
+
+	status, ok := t.nodes[nodeName][podName]
+	if !ok {
+		return
+	}
+	status.SentGeneration = event.GetGeneration()
+	status.LastSent = now
+	if event.GetEventType() == protos.Events_Hydration {
+		status.Hydrations++
+	}
+	t.updateMetrics(nodeName)
+}
+
+// acked records an ack from a daemon. It returns false if the ack was ignored, because the daemon isn't registered or
+// it already acked a later generation.
+func (t *goalStateTracker) acked(ack *protos.GoalStateAck, now time.Time) bool {
+	t.Lock()
+	defer t.Unlock()
+
+	status, ok := t.nodes[ack.GetNodeName()][ack.GetPodName()]
+	if !ok || ack.GetGeneration() < status.AppliedGeneration {
+		return false
+	}
+	status.AppliedGeneration = ack.GetGeneration()
+	status.Error = ack.GetError()
+	status.LastAck = now
+	t.updateMetrics(ack.GetNodeName())
+	return true
+}
+
+// remove stops tracking a disconnected daemon, and its node once it has no connected daemons
+func (t *goalStateTracker) remove(nodeName, podName string) {
+	t.Lock()
+	defer t.Unlock()
+
+	clients, ok := t.nodes[nodeName]
+	if !ok {
+		return
+	}
+	delete(clients, podName)
+	if len(clients) > 0 {
+		t.updateMetrics(nodeName)
+		return
+	}
+	delete(t.nodes, nodeName)
+	metrics.RemoveNodeGoalStateStatus(nodeName)
+}
+
+// nodesToRehydrate returns the nodes with daemons which failed to apply or didn't ack their last event in time
+func (t *goalStateTracker) nodesToRehydrate(now time.Time) []string {
+	t.RLock()
+	defer t.RUnlock()
+
+	nodeNames := make([]string, 0)
+	for nodeName, clients := range t.nodes {
+		for _, status := range clients {
+			if status.needsRehydration(now) {
+				nodeNames = append(nodeNames, nodeName)
+				break
+			}
+		}
+	}
+	return nodeNames
+}
+
+// snapshot returns a copy of each node's status
+func (t *goalStateTracker) snapshot() map[string]NodeGoalStateStatus {
+	t.RLock()
+	defer t.RUnlock()
+
+	statuses := make(map[string]NodeGoalStateStatus, len(t.nodes))
+	for nodeName := range t.nodes {
+		statuses[nodeName] = t.nodeStatus(nodeName)
+	}
+	return statuses
+}
+
+func (t *goalStateTracker) nodeStatus(nodeName string) NodeGoalStateStatus {
+	clients := t.nodes[nodeName]
+	status := NodeGoalStateStatus{Clients: make(map[string]ClientGoalStateStatus, len(clients))}
+	for podName, client := range clients {
+		status.Clients[podName] = *client
+	}
+	return status
+}
+
+func (t *goalStateTracker) updateMetrics(nodeName string) {
+	status := t.nodeStatus(nodeName)
+	metrics.SetNodeGoalStateStatus(nodeName, status.Lag(), status.Failed())
+}
@@ -0,0 +1,102 @@
+package transport
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-container-networking/npm/metrics"
+	"github.com/Azure/azure-container-networking/npm/pkg/protos"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testNode = "node-a"
+	testPod  = "npm-abc"
+	testPod2 = "npm-def"
+)
+
+func TestGoalStateTracker(t *testing.T) {
+	metrics.InitializeAll()
+	tracker := newGoalStateTracker()
+	start := time.Now()
+
+	tracker.register(testNode, testPod)
+	require.Empty(t, tracker.nodesToRehydrate(start.Add(2*ackTimeout)), "nothing was sent to the node")
+
+	tracker.sent(testNode, testPod, &protos.Events{EventType: protos.Events_Hydration, Generation: 3}, start)
+	tracker.sent(testNode, testPod, &protos.Events{EventType: protos.Events_GoalState, Generation: 5}, start)
+	status := tracker.snapshot()[testNode]
+	require.Equal(t, uint64(5), status.Clients[testPod].SentGeneration)
+	require.Equal(t, uint64(5), status.Lag())
+	require.Equal(t, 1, status.Clients[testPod].Hydrations)
+	assertNodeGoalStateMetrics(t, 5, 0)
+
+	// the node is lagging only after the ack timeout
+	require.Empty(t, tracker.nodesToRehydrate(start.Add(ackTimeout/2)))
+	require.Equal(t, []string{testNode}, tracker.nodesToRehydrate(start.Add(ackTimeout)))
+
+	require.True(t, tracker.acked(&protos.GoalStateAck{PodName: testPod, NodeName: testNode, Generation: 5}, start))
+	status = tracker.snapshot()[testNode]
+	require.Equal(t, uint64(5), status.Clients[testPod].AppliedGeneration)
+	require.Equal(t, uint64(0), status.Lag())
+	require.Empty(t, tracker.nodesToRehydrate(start.Add(ackTimeout)))
+	assertNodeGoalStateMetrics(t, 0, 0)
+
+	// a failed apply is rehydrated after the ack timeout
+	tracker.sent(testNode, testPod, &protos.Events{EventType: protos.Events_GoalState, Generation: 6}, start)
+	require.True(t, tracker.acked(&protos.GoalStateAck{PodName: testPod, NodeName: testNode, Generation: 6, Error: "failed"}, start))
+	require.Equal(t, uint64(0), tracker.snapshot()[testNode].Lag())
+	require.Equal(t, []string{testNode}, tracker.nodesToRehydrate(start.Add(ackTimeout)))
+	assertNodeGoalStateMetrics(t, 0, 1)
+
+	// acks older than the applied generation are ignored
+	require.False(t, tracker.acked(&protos.GoalStateAck{PodName: testPod, NodeName: testNode, Generation: 5}, start))
+	require.Equal(t, "failed", tracker.snapshot()[testNode].Clients[testPod].Error)
+
+	// acks from daemons which aren't registered are ignored
+	tracker.remove(testNode, testPod)
+	require.False(t, tracker.acked(&protos.GoalStateAck{PodName: testPod, NodeName: testNode, Generation: 6}, start))
+	require.Empty(t, tracker.snapshot())
+}
+
+func TestGoalStateTrackerClientsOnNode(t *testing.T) {
+	metrics.InitializeAll()
+	tracker := newGoalStateTracker()
+	start := time.Now()
+
+	tracker.register(testNode, testPod)
+	tracker.register(testNode, testPod2)
+	event := &protos.Events{EventType: protos.Events_GoalState, Generation: 5}
+	tracker.sent(testNode, testPod, event, start)
+	tracker.sent(testNode, testPod2, event, start)
+
+	// an ack of one daemon doesn't change the status of the other
+	require.True(t, tracker.acked(&protos.GoalStateAck{PodName: testPod, NodeName: testNode, Generation: 5}, start))
+	status := tracker.snapshot()[testNode]
+	require.Equal(t, uint64(0), status.Clients[testPod].Lag())
+	require.Equal(t, uint64(5), status.Clients[testPod2].Lag())
+	require.Equal(t, uint64(5), status.Lag())
+	require.Equal(t, []string{testNode}, tracker.nodesToRehydrate(start.Add(ackTimeout)))
+	assertNodeGoalStateMetrics(t, 5, 0)
+
+	require.True(t, tracker.acked(&protos.GoalStateAck{PodName: testPod2, NodeName: testNode, Generation: 5, Error: "failed"}, start))
+	require.True(t, tracker.snapshot()[testNode].Failed())
+	assertNodeGoalStateMetrics(t, 0, 1)
+
+	// the node is tracked until its last daemon is removed
+	tracker.remove(testNode, testPod2)
+	require.False(t, tracker.snapshot()[testNode].Failed())
+	assertNodeGoalStateMetrics(t, 0, 0)
+	tracker.remove(testNode, testPod)
+	require.Empty(t, tracker.snapshot())
+}
+
+func assertNodeGoalStateMetrics(t *testing.T, expectedLag, expectedFailed int) {
+	t.Helper()
+	lag, err := metrics.GetNodeGoalStateLag(testNode)
+	require.NoError(t, err)
+	require.Equal(t, expectedLag, lag)
+	failed, err := metrics.GetNodeGoalStateFailed(testNode)
+	require.NoError(t, err)
+	require.Equal(t, expectedFailed, failed)
+}
@@ -2,6 +2,7 @@ package transport
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/Azure/azure-container-networking/npm/pkg/protos"
@@ -14,6 +15,8 @@ type clientStreamConnection struct {
 	*protos.DatapathPodMetadata
 	addr      string
 	timestamp int64
+	// sender sends the events of the client, once it's registered
+	sender *eventSender
 }
 
 // String returns the address of the client
@@ -26,13 +29,15 @@ type DataplaneEventsServer struct {
 	protos.UnimplementedDataplaneEventsServer
 	ctx   context.Context
 	regCh chan<- clientStreamConnection
+	ackCh chan<- *protos.GoalStateAck
 }
 
 // NewServer creates a new DataplaneEventsServer instance
-func NewServer(ctx context.Context, ch chan clientStreamConnection) *DataplaneEventsServer {
+func NewServer(ctx context.Context, ch chan clientStreamConnection, ackCh chan *protos.GoalStateAck) *DataplaneEventsServer {
 	return &DataplaneEventsServer{
 		ctx:   ctx,
 		regCh: ch,
+		ackCh: ackCh,
 	}
 }
 
@@ -58,3 +63,15 @@ func (d *DataplaneEventsServer) Connect(m *protos.DatapathPodMetadata, stream pr
 
 	return nil
 }
+
+// Ack is called when a client has processed the events of a goal state generation
+func (d *DataplaneEventsServer) Ack(ctx context.Context, ack *protos.GoalStateAck) (*protos.AckResponse, error) {
+	select {
+	case d.ackCh <- ack:
+		return &protos.AckResponse{}, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to record ack: %w", ctx.Err())
+	case <-d.ctx.Done():
+		return nil, fmt.Errorf("failed to record ack: %w", d.ctx.Err())
+	}
+}
@@ -1,12 +1,15 @@
 package transport
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
 	"os"
 
+	localtls "github.com/Azure/azure-container-networking/server/tls"
 	"google.golang.org/grpc/credentials"
+	"k8s.io/klog/v2"
 )
 
 const (
@@ -16,15 +19,37 @@ const (
 	path                  = "/usr/local/npm"
 )
 
-func serverTLSCreds() (credentials.TransportCredentials, error) {
-	certFilepath := path + "/" + serverCertPEMFilename
-	keyFilepath := path + "/" + serverKeyPEMFilename
-
-	creds, err := credentials.NewServerTLSFromFile(certFilepath, keyFilepath)
+// serverTLSCreds returns the credentials of the gRPC server, which serve the certificate and key files reloaded
+// whenever the mounted secret holding them is updated, until the context is done.
+func serverTLSCreds(ctx context.Context) (credentials.TransportCredentials, error) {
+	source, err := localtls.NewFileCertificateSource(localtls.TlsSettings{
+		TLSCertificatePath: path + "/" + serverCertPEMFilename,
+		TLSKeyPath:         path + "/" + serverKeyPEMFilename,
+	}, klogLogger{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create creds from cert/key files : %w", err)
 	}
-	return creds, nil
+
+	go func() {
+		if err := source.Run(ctx); err != nil && ctx.Err() == nil {
+			klog.Errorf("stopped reloading the server certificate: %v", err)
+		}
+	}()
+
+	return credentials.NewTLS(&tls.Config{ //nolint:gosec // the min version defaults to TLS 1.2 for servers
+		GetCertificate: localtls.GetCertificateFunc(source),
+	}), nil
+}
+
+// klogLogger logs the reloads of the server certificate with klog.
+type klogLogger struct{}
+
+func (klogLogger) Printf(format string, args ...any) {
+	klog.Infof(format, args...)
+}
+
+func (klogLogger) Errorf(format string, args ...any) {
+	klog.Errorf(format, args...)
 }
 
 func clientTLSConfig() (*tls.Config, error) {
@@ -81,6 +81,7 @@ const (
 	IptablesListFlag        string = "-L"
 	IptablesNumericFlag     string = "-n"
 	IptablesLineNumbersFlag string = "--line-numbers"
+	IptablesCountersFlag    string = "-c"
 
 	IptablesKubeServicesChain          string = "KUBE-SERVICES"
 	IptablesForwardChain               string = "FORWARD"
@@ -185,9 +186,13 @@ const (
 	CIDRPrefix           string = "cidr-"
 	NestedLabelPrefix    string = "nestedlabel-"
 	EmptySetPrefix       string = "empty-"
+	FQDNPrefix           string = "fqdn:"
 
 	NegationPrefix string = "not-"
 
+	// FQDNSetDelimiter separates the namespace and the pattern in the name of an FQDN set
+	FQDNSetDelimiter string = "/"
+
 	SetPolicyDelimiter string = ","
 )
 
@@ -248,4 +253,5 @@ const (
 	ControllerID
 	DaemonDataplaneID // for v2
 	FanOutServerID    // for v2
+	FQDNID            // dns snooper in v2
 )
@@ -54,6 +54,7 @@ const (
 	IPSetIntersection       = "IPSetIntersection"
 	AddPolicy               = "AddNetworkPolicy"
 	RemovePolicy            = "RemovePolicy"
+	UpdatePolicy            = "UpdatePolicy"
 	GetSelectorReference    = "GetSelectorReference"
 	AddSelectorReference    = "AddSelectorReference"
 	DeleteSelectorReference = "DeleteSelectorReference"
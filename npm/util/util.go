@@ -296,6 +296,18 @@ func GetLabelKVFromSet(ipsetName string) (string, string) {
 	return strSplit[0], ""
 }
 
+// GetFQDNSetName returns the name of the FQDN set for a pattern in a namespace.
+// FQDN sets are scoped to a namespace so that DNS responses only grant egress to pods in the namespace which queried.
+func GetFQDNSetName(namespace, pattern string) string {
+	return namespace + FQDNSetDelimiter + pattern
+}
+
+// GetNamespaceAndPatternFromFQDNSet splits the name of an FQDN set into its namespace and pattern.
+func GetNamespaceAndPatternFromFQDNSet(setName string) (namespace, pattern string) {
+	namespace, pattern, _ = strings.Cut(setName, FQDNSetDelimiter)
+	return namespace, pattern
+}
+
 // StrExistsInSlice check if a string already exists in a given slice
 func StrExistsInSlice(items []string, val string) bool {
 	for _, item := range items {
@@ -0,0 +1,229 @@
+// Package otlp exports traces, metrics and logs to an OpenTelemetry collector with the OTLP/HTTP protocol,
+// as an alternative to Application Insights for clusters running their own observability stack.
+// The telemetry is batched and exported by the OpenTelemetry Go SDK; this package configures its providers for
+// the ACN components, and adapts the Prometheus metrics and the aitelemetry handle of the components to them.
+package otlp
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const (
+	tracesPath  = "/v1/traces"
+	metricsPath = "/v1/metrics"
+	logsPath    = "/v1/logs"
+
+	defaultBatchInterval = 5 * time.Second
+	defaultTimeout       = 10 * time.Second
+
+	scopeName = "github.com/Azure/azure-container-networking/otlp"
+)
+
+// ErrNoEndpoint is returned when an Exporter is created without a collector endpoint.
+var ErrNoEndpoint = errors.New("no OTLP endpoint")
+
+// Config configures an Exporter.
+type Config struct {
+	// Endpoint is the base URL of the OTLP/HTTP receiver of the collector, such as http://localhost:4318.
+	// The signal paths /v1/traces, /v1/metrics and /v1/logs are appended to it.
+	Endpoint string
+	// Headers are added to every export request, such as for authentication.
+	Headers map[string]string
+	// ServiceName and ServiceVersion identify the exporting component, as the service.name and service.version
+	// resource attributes.
+	ServiceName    string
+	ServiceVersion string
+	// ResourceAttributes are added to the resource of all the exported telemetry, such as the node name.
+	ResourceAttributes map[string]string
+	// BatchInterval is how often the queued spans and log records, and the metrics, are exported.
+	// Defaults to 5 seconds.
+	BatchInterval time.Duration
+	// Gatherer, if set, has its Prometheus metrics exported along with the metrics of the Exporter, so that the
+	// OTLP metrics mirror the ones scraped from the metrics endpoint.
+	Gatherer prometheus.Gatherer
+	// ErrorHandler, if set, is called with the errors of the exports run in the background. It is installed as
+	// the global OpenTelemetry error handler, which the SDK reports them to.
+	ErrorHandler func(error)
+}
+
+// Exporter owns the OpenTelemetry providers which batch the telemetry of a component and export it to an
+// OTLP/HTTP collector. Telemetry is dropped once the queues of the providers are full, rather than blocking
+// the component while the collector can't be reached.
+type Exporter struct {
+	tracerProvider *sdktrace.TracerProvider
+	meterProvider  *sdkmetric.MeterProvider
+	loggerProvider *sdklog.LoggerProvider
+}
+
+// New creates an Exporter from the Config. The providers export in the background until the Exporter is shut down.
+func New(c Config) (*Exporter, error) {
+	tp, err := NewTracerProvider(c)
+	if err != nil {
+		return nil, err
+	}
+	endpoint, res := strings.TrimSuffix(c.Endpoint, "/"), newResource(c)
+	interval := batchInterval(c)
+
+	metricExporter, err := otlpmetrichttp.New(context.Background(),
+		otlpmetrichttp.WithEndpointURL(endpoint+metricsPath),
+		otlpmetrichttp.WithHeaders(c.Headers),
+		otlpmetrichttp.WithTimeout(defaultTimeout))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create OTLP metric exporter")
+	}
+	readerOpts := []sdkmetric.PeriodicReaderOption{sdkmetric.WithInterval(interval)}
+	if c.Gatherer != nil {
+		readerOpts = append(readerOpts, sdkmetric.WithProducer(newGathererProducer(c.Gatherer)))
+	}
+
+	logExporter, err := otlploghttp.New(context.Background(),
+		otlploghttp.WithEndpointURL(endpoint+logsPath),
+		otlploghttp.WithHeaders(c.Headers),
+		otlploghttp.WithTimeout(defaultTimeout))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create OTLP log exporter")
+	}
+
+	return &Exporter{
+		tracerProvider: tp,
+		meterProvider: sdkmetric.NewMeterProvider(
+			sdkmetric.WithResource(res),
+			sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter, readerOpts...))),
+		loggerProvider: sdklog.NewLoggerProvider(
+			sdklog.WithResource(res),
+			sdklog.WithProcessor(sdklog.NewBatchProcessor(logExporter, sdklog.WithExportInterval(interval)))),
+	}, nil
+}
+
+// NewTracerProvider creates a TracerProvider which batches the spans of the component and exports them to the
+// collector of the Config. It is used instead of New by the components which only export traces, such as CNI.
+func NewTracerProvider(c Config) (*sdktrace.TracerProvider, error) {
+	if c.Endpoint == "" {
+		return nil, ErrNoEndpoint
+	}
+	u, err := url.Parse(c.Endpoint)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse OTLP endpoint %s", c.Endpoint)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.Errorf("OTLP endpoint %s must be an http or https URL", c.Endpoint)
+	}
+	if c.ErrorHandler != nil {
+		otel.SetErrorHandler(otel.ErrorHandlerFunc(c.ErrorHandler))
+	}
+
+	traceExporter, err := otlptracehttp.New(context.Background(),
+		otlptracehttp.WithEndpointURL(strings.TrimSuffix(c.Endpoint, "/")+tracesPath),
+		otlptracehttp.WithHeaders(c.Headers),
+		otlptracehttp.WithTimeout(defaultTimeout))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create OTLP trace exporter")
+	}
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithResource(newResource(c)),
+		sdktrace.WithBatcher(traceExporter, sdktrace.WithBatchTimeout(batchInterval(c)))), nil
+}
+
+func newResource(c Config) *resource.Resource {
+	attrs := make([]attribute.KeyValue, 0, len(c.ResourceAttributes)+2) //nolint:gomnd // the service attributes
+	for k, v := range c.ResourceAttributes {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+	if c.ServiceName != "" {
+		attrs = append(attrs, semconv.ServiceName(c.ServiceName))
+	}
+	if c.ServiceVersion != "" {
+		attrs = append(attrs, semconv.ServiceVersion(c.ServiceVersion))
+	}
+	return resource.NewSchemaless(attrs...)
+}
+
+func batchInterval(c Config) time.Duration {
+	if c.BatchInterval <= 0 {
+		return defaultBatchInterval
+	}
+	return c.BatchInterval
+}
+
+// ParseHeaders parses headers in the key1=value1,key2=value2 format of the OTEL_EXPORTER_OTLP_HEADERS variable.
+func ParseHeaders(s string) (map[string]string, error) {
+	headers := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, errors.Errorf("invalid OTLP header %q", pair)
+		}
+		value, err := url.QueryUnescape(strings.TrimSpace(v))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid OTLP header %q", pair)
+		}
+		headers[strings.TrimSpace(k)] = value
+	}
+	return headers, nil
+}
+
+// Run waits until the context is cancelled, and then shuts the Exporter down, exporting the telemetry left in
+// its queues.
+func (e *Exporter) Run(ctx context.Context) {
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+	if err := e.Shutdown(shutdownCtx); err != nil {
+		otel.Handle(err)
+	}
+}
+
+// Flush exports all the queued telemetry, and the current value of the metrics.
+func (e *Exporter) Flush(ctx context.Context) error {
+	var errs []string
+	if err := e.tracerProvider.ForceFlush(ctx); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if err := e.meterProvider.ForceFlush(ctx); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if err := e.loggerProvider.ForceFlush(ctx); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if len(errs) > 0 {
+		return errors.Errorf("failed to flush OTLP telemetry: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Shutdown exports the queued telemetry and stops the providers. Telemetry recorded afterwards is dropped.
+func (e *Exporter) Shutdown(ctx context.Context) error {
+	var errs []string
+	if err := e.tracerProvider.Shutdown(ctx); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if err := e.meterProvider.Shutdown(ctx); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if err := e.loggerProvider.Shutdown(ctx); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if len(errs) > 0 {
+		return errors.Errorf("failed to shut down OTLP exporter: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
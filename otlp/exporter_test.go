@@ -0,0 +1,344 @@
+package otlp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/aitelemetry"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricpb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// collector records the requests to an OTLP/HTTP receiver.
+type collector struct {
+	sync.Mutex
+	traces  []*coltracepb.ExportTraceServiceRequest
+	metrics []*colmetricpb.ExportMetricsServiceRequest
+	logs    []*collogspb.ExportLogsServiceRequest
+	headers []http.Header
+	status  int
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.Lock()
+	defer c.Unlock()
+	c.headers = append(c.headers, r.Header.Clone())
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	switch r.URL.Path {
+	case tracesPath:
+		req := &coltracepb.ExportTraceServiceRequest{}
+		err = proto.Unmarshal(body, req)
+		c.traces = append(c.traces, req)
+	case metricsPath:
+		req := &colmetricpb.ExportMetricsServiceRequest{}
+		err = proto.Unmarshal(body, req)
+		c.metrics = append(c.metrics, req)
+	case logsPath:
+		req := &collogspb.ExportLogsServiceRequest{}
+		err = proto.Unmarshal(body, req)
+		c.logs = append(c.logs, req)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if c.status != 0 {
+		w.WriteHeader(c.status)
+	}
+}
+
+func (c *collector) spans() []*tracepb.Span {
+	c.Lock()
+	defer c.Unlock()
+	var spans []*tracepb.Span
+	for _, req := range c.traces {
+		for _, rs := range req.GetResourceSpans() {
+			for _, ss := range rs.GetScopeSpans() {
+				spans = append(spans, ss.GetSpans()...)
+			}
+		}
+	}
+	return spans
+}
+
+func (c *collector) metricsByName() map[string]*metricpb.Metric {
+	c.Lock()
+	defer c.Unlock()
+	metrics := map[string]*metricpb.Metric{}
+	for _, req := range c.metrics {
+		for _, rm := range req.GetResourceMetrics() {
+			for _, sm := range rm.GetScopeMetrics() {
+				for _, m := range sm.GetMetrics() {
+					metrics[m.GetName()] = m
+				}
+			}
+		}
+	}
+	return metrics
+}
+
+func (c *collector) logRecords() []*logspb.LogRecord {
+	c.Lock()
+	defer c.Unlock()
+	var records []*logspb.LogRecord
+	for _, req := range c.logs {
+		for _, rl := range req.GetResourceLogs() {
+			for _, sl := range rl.GetScopeLogs() {
+				records = append(records, sl.GetLogRecords()...)
+			}
+		}
+	}
+	return records
+}
+
+// attributes returns the string values of the attributes.
+func attributes(kvs []*commonpb.KeyValue) map[string]string {
+	m := make(map[string]string, len(kvs))
+	for _, kv := range kvs {
+		m[kv.GetKey()] = kv.GetValue().GetStringValue()
+	}
+	return m
+}
+
+func newTestExporter(t *testing.T, gatherer prometheus.Gatherer) (*Exporter, *collector) {
+	t.Helper()
+	c := &collector{}
+	srv := httptest.NewServer(c)
+	t.Cleanup(srv.Close)
+	e, err := New(Config{
+		Endpoint:           srv.URL + "/",
+		Headers:            map[string]string{"Authorization": "Bearer token"},
+		ServiceName:        "azure-cns",
+		ServiceVersion:     "v1.0.0",
+		ResourceAttributes: map[string]string{"host.name": "node1"},
+		Gatherer:           gatherer,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = e.Shutdown(context.Background()) })
+	return e, c
+}
+
+// setTracerProvider sets the global TracerProvider for the test.
+func setTracerProvider(t *testing.T, tp trace.TracerProvider) {
+	t.Helper()
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{})
+	require.ErrorIs(t, err, ErrNoEndpoint)
+	_, err = New(Config{Endpoint: "localhost:4318"})
+	require.Error(t, err)
+	_, err = New(Config{Endpoint: "http://localhost:4318"})
+	require.NoError(t, err)
+}
+
+func TestParseHeaders(t *testing.T) {
+	headers, err := ParseHeaders("Authorization=Bearer%20token, x-tenant = a=b,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Authorization": "Bearer token", "x-tenant": "a=b"}, headers)
+
+	_, err = ParseHeaders("no-value")
+	require.Error(t, err)
+}
+
+func TestExportSpans(t *testing.T) {
+	e, c := newTestExporter(t, nil)
+	SetExporter(e)
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	ctx, parent := Tracer().Start(context.Background(), "CNI ADD", trace.WithAttributes(attribute.String("pod", "pod1")))
+	_, child := Tracer().Start(ctx, "RequestIPAddress", trace.WithSpanKind(trace.SpanKindClient))
+	SetError(child, errors.New("no IPs available"))
+	child.End()
+	parent.SetStatus(codes.Ok, "")
+	parent.End()
+
+	require.NoError(t, e.Flush(context.Background()))
+	require.Len(t, c.traces, 1)
+	assert.Equal(t, "Bearer token", c.headers[0].Get("Authorization"))
+	assert.Equal(t, map[string]string{
+		"host.name":       "node1",
+		"service.name":    "azure-cns",
+		"service.version": "v1.0.0",
+	}, attributes(c.traces[0].GetResourceSpans()[0].GetResource().GetAttributes()))
+
+	spans := c.spans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "RequestIPAddress", spans[0].GetName())
+	assert.Equal(t, tracepb.Span_SPAN_KIND_CLIENT, spans[0].GetKind())
+	assert.Equal(t, tracepb.Status_STATUS_CODE_ERROR, spans[0].GetStatus().GetCode())
+	assert.Equal(t, "no IPs available", spans[0].GetStatus().GetMessage())
+	assert.Equal(t, "CNI ADD", spans[1].GetName())
+	assert.Equal(t, tracepb.Status_STATUS_CODE_OK, spans[1].GetStatus().GetCode())
+	assert.Empty(t, spans[1].GetParentSpanId())
+	assert.Equal(t, spans[1].GetTraceId(), spans[0].GetTraceId())
+	assert.Equal(t, spans[1].GetSpanId(), spans[0].GetParentSpanId())
+	assert.Equal(t, map[string]string{"pod": "pod1"}, attributes(spans[1].GetAttributes()))
+
+	// nothing is left to export
+	require.NoError(t, e.Flush(context.Background()))
+	assert.Len(t, c.spans(), 2)
+}
+
+func TestPropagation(t *testing.T) {
+	setTracerProvider(t, sdktrace.NewTracerProvider())
+	var serverCtx context.Context
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		serverCtx = Extract(r.Context(), r.Header)
+	}))
+	defer srv.Close()
+
+	ctx, span := Tracer().Start(context.Background(), "CNI ADD")
+	defer span.End()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL, http.NoBody)
+	require.NoError(t, err)
+	client := &http.Client{Transport: &Transport{}}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Empty(t, req.Header.Get(TraceparentHeader), "the request of the caller must not be modified")
+
+	// the server continues the trace from the client span
+	remote := trace.SpanContextFromContext(serverCtx)
+	require.True(t, remote.IsValid())
+	assert.True(t, remote.IsRemote())
+	assert.Equal(t, span.SpanContext().TraceID(), remote.TraceID())
+	assert.NotEqual(t, span.SpanContext().SpanID(), remote.SpanID())
+}
+
+func TestExportGatheredMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "requests_total", Help: "requests"}, []string{"code"})
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "allocated_ips", Help: "allocated"})
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "latency_seconds", Help: "latency", Buckets: []float64{1, 2}})
+	registry.MustRegister(counter, gauge, histogram)
+	counter.WithLabelValues("200").Add(3)
+	gauge.Set(7)
+	histogram.Observe(0.5)
+	histogram.Observe(1.5)
+	histogram.Observe(5)
+
+	e, c := newTestExporter(t, registry)
+	require.NoError(t, e.Flush(context.Background()))
+	metrics := c.metricsByName()
+
+	sum := metrics["requests_total"].GetSum()
+	require.NotNil(t, sum)
+	assert.True(t, sum.GetIsMonotonic())
+	assert.Equal(t, metricpb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE, sum.GetAggregationTemporality())
+	assert.Equal(t, 3.0, sum.GetDataPoints()[0].GetAsDouble())
+	assert.Equal(t, map[string]string{"code": "200"}, attributes(sum.GetDataPoints()[0].GetAttributes()))
+
+	require.NotNil(t, metrics["allocated_ips"].GetGauge())
+	assert.Equal(t, 7.0, metrics["allocated_ips"].GetGauge().GetDataPoints()[0].GetAsDouble())
+
+	require.NotNil(t, metrics["latency_seconds"].GetHistogram())
+	point := metrics["latency_seconds"].GetHistogram().GetDataPoints()[0]
+	assert.Equal(t, uint64(3), point.GetCount())
+	assert.Equal(t, 7.0, point.GetSum())
+	assert.Equal(t, []float64{1, 2}, point.GetExplicitBounds())
+	assert.Equal(t, []uint64{1, 1, 1}, point.GetBucketCounts())
+}
+
+func TestTelemetryHandle(t *testing.T) {
+	e, c := newTestExporter(t, nil)
+	th := NewTelemetryHandle(e)
+
+	th.TrackLog(aitelemetry.Report{Message: "started", CustomDimensions: map[string]string{"NodeID": "node1"}})
+	th.TrackEvent(aitelemetry.Event{EventName: "NCSnapshot", Properties: map[string]string{"NCID": "nc1"}})
+	th.TrackMetric(aitelemetry.Metric{Name: "HeartBeat", Value: 1, CustomDimensions: map[string]string{"NodeID": "node1"}})
+	th.Flush()
+
+	records := c.logRecords()
+	require.Len(t, records, 2)
+	assert.Equal(t, "started", records[0].GetBody().GetStringValue())
+	assert.Equal(t, "node1", attributes(records[0].GetAttributes())["NodeID"])
+	assert.Equal(t, logspb.SeverityNumber_SEVERITY_NUMBER_INFO, records[0].GetSeverityNumber())
+	assert.Equal(t, "NCSnapshot", records[1].GetBody().GetStringValue())
+	assert.Equal(t, "NCSnapshot", attributes(records[1].GetAttributes())["event.name"])
+
+	gauge := c.metricsByName()["HeartBeat"].GetGauge()
+	require.NotNil(t, gauge)
+	assert.Equal(t, 1.0, gauge.GetDataPoints()[0].GetAsDouble())
+	assert.Equal(t, map[string]string{"NodeID": "node1"}, attributes(gauge.GetDataPoints()[0].GetAttributes()))
+}
+
+func TestFlushError(t *testing.T) {
+	e, c := newTestExporter(t, nil)
+	c.status = http.StatusBadRequest
+	_, span := e.tracerProvider.Tracer("test").Start(context.Background(), "span")
+	span.End()
+	require.Error(t, e.Flush(context.Background()))
+}
+
+func TestHandler(t *testing.T) {
+	e, c := newTestExporter(t, nil)
+	SetExporter(e)
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	var served trace.SpanContext
+	srv := httptest.NewServer(Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served = trace.SpanFromContext(r.Context()).SpanContext()
+		w.WriteHeader(http.StatusInternalServerError)
+	})))
+	defer srv.Close()
+
+	ctx, span := Tracer().Start(context.Background(), "CNI ADD")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL+"/network/requestipconfigs", http.NoBody)
+	require.NoError(t, err)
+	resp, err := (&http.Client{Transport: &Transport{}}).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	span.End()
+
+	// the handler continues the trace of the client, and returns it in the traceresponse
+	assert.Equal(t, span.SpanContext().TraceID(), served.TraceID())
+	assert.Equal(t, "00-"+served.TraceID().String()+"-"+served.SpanID().String()+"-01", resp.Header.Get(TraceresponseHeader))
+
+	require.NoError(t, e.Flush(context.Background()))
+	var server *tracepb.Span
+	for _, s := range c.spans() {
+		if s.GetKind() == tracepb.Span_SPAN_KIND_SERVER {
+			server = s
+		}
+	}
+	require.NotNil(t, server)
+	assert.Equal(t, "HTTP POST /network/requestipconfigs", server.GetName())
+	assert.Equal(t, tracepb.Status_STATUS_CODE_ERROR, server.GetStatus().GetCode())
+	var statusCode int64
+	for _, kv := range server.GetAttributes() {
+		if kv.GetKey() == "http.status_code" {
+			statusCode = kv.GetValue().GetIntValue()
+		}
+	}
+	assert.Equal(t, int64(http.StatusInternalServerError), statusCode)
+}
@@ -0,0 +1,71 @@
+package otlp
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceresponseHeader is the W3C Trace Context response header which returns the trace of a request to the client.
+const TraceresponseHeader = "traceresponse"
+
+// Handler serves each request in a server span, which continues the trace of the traceparent header of the request,
+// and returns the trace to the client in the traceresponse header.
+// The handler gets the span from the context of the request with trace.SpanFromContext, to describe the request in it.
+func Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := Tracer().Start(Extract(r.Context(), r.Header), "HTTP "+r.Method+" "+r.URL.Path,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attribute.String("http.method", r.Method)))
+		defer span.End()
+
+		if sc := span.SpanContext(); sc.IsValid() {
+			w.Header().Set(TraceresponseHeader, fmt.Sprintf("00-%s-%s-%s", sc.TraceID(), sc.SpanID(), sc.TraceFlags()))
+		}
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		span.SetAttributes(attribute.Int("http.status_code", rec.status))
+		if rec.status >= http.StatusInternalServerError {
+			SetError(span, errors.New(http.StatusText(rec.status)))
+		}
+	})
+}
+
+// statusRecorder records the status code of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return s.ResponseWriter.Write(b) //nolint:wrapcheck // the error of the wrapped writer is returned as is
+}
+
+// Flush flushes streamed responses, such as server-sent events.
+func (s *statusRecorder) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the wrapped ResponseWriter, for http.ResponseController.
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
@@ -0,0 +1,129 @@
+package otlp
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+var _ sdkmetric.Producer = (*gathererProducer)(nil)
+
+// gathererProducer produces the metrics of a Prometheus Gatherer, which have been collected since it was created,
+// each time the metrics of the MeterProvider are exported.
+// Counters are produced as monotonic cumulative sums, gauges and untyped metrics as gauges, and histograms
+// as cumulative histograms. Summaries are not produced.
+type gathererProducer struct {
+	gatherer prometheus.Gatherer
+	start    time.Time
+}
+
+func newGathererProducer(gatherer prometheus.Gatherer) *gathererProducer {
+	return &gathererProducer{gatherer: gatherer, start: time.Now()}
+}
+
+// Produce returns the current value of the metrics of the Gatherer.
+func (p *gathererProducer) Produce(context.Context) ([]metricdata.ScopeMetrics, error) {
+	families, err := p.gatherer.Gather()
+	if err != nil && len(families) == 0 {
+		return nil, errors.Wrap(err, "failed to gather prometheus metrics")
+	}
+	// the families gathered along with an error are still consistent, and exported
+	metrics := convertMetricFamilies(families, p.start, time.Now())
+	if len(metrics) == 0 {
+		return nil, errors.Wrap(err, "failed to gather some prometheus metrics")
+	}
+	return []metricdata.ScopeMetrics{{
+		Scope:   instrumentation.Scope{Name: scopeName},
+		Metrics: metrics,
+	}}, errors.Wrap(err, "failed to gather some prometheus metrics")
+}
+
+func convertMetricFamilies(families []*dto.MetricFamily, start, now time.Time) []metricdata.Metrics {
+	metrics := make([]metricdata.Metrics, 0, len(families))
+	for _, family := range families {
+		m := metricdata.Metrics{
+			Name:        family.GetName(),
+			Description: family.GetHelp(),
+		}
+		switch family.GetType() {
+		case dto.MetricType_COUNTER:
+			sum := metricdata.Sum[float64]{Temporality: metricdata.CumulativeTemporality, IsMonotonic: true}
+			for _, metric := range family.GetMetric() {
+				sum.DataPoints = append(sum.DataPoints, metricdata.DataPoint[float64]{
+					Attributes: labelSet(metric.GetLabel()),
+					StartTime:  start,
+					Time:       now,
+					Value:      metric.GetCounter().GetValue(),
+				})
+			}
+			m.Data = sum
+		case dto.MetricType_GAUGE, dto.MetricType_UNTYPED:
+			gauge := metricdata.Gauge[float64]{}
+			for _, metric := range family.GetMetric() {
+				value := metric.GetGauge().GetValue()
+				if family.GetType() == dto.MetricType_UNTYPED {
+					value = metric.GetUntyped().GetValue()
+				}
+				gauge.DataPoints = append(gauge.DataPoints, metricdata.DataPoint[float64]{
+					Attributes: labelSet(metric.GetLabel()),
+					Time:       now,
+					Value:      value,
+				})
+			}
+			m.Data = gauge
+		case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+			histogram := metricdata.Histogram[float64]{Temporality: metricdata.CumulativeTemporality}
+			for _, metric := range family.GetMetric() {
+				histogram.DataPoints = append(histogram.DataPoints, convertHistogram(metric, start, now))
+			}
+			m.Data = histogram
+		default:
+			continue
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
+
+// convertHistogram converts the cumulative buckets of a Prometheus histogram to the per-bucket counts of an OTLP
+// histogram, which has an extra bucket for the values above the largest bound.
+func convertHistogram(metric *dto.Metric, start, now time.Time) metricdata.HistogramDataPoint[float64] {
+	h := metric.GetHistogram()
+	point := metricdata.HistogramDataPoint[float64]{
+		Attributes:   labelSet(metric.GetLabel()),
+		StartTime:    start,
+		Time:         now,
+		Count:        h.GetSampleCount(),
+		Sum:          h.GetSampleSum(),
+		Bounds:       []float64{},
+		BucketCounts: []uint64{},
+	}
+	var previous uint64
+	for _, bucket := range h.GetBucket() {
+		if math.IsInf(bucket.GetUpperBound(), 1) {
+			// the last OTLP bucket already counts the values above the largest bound
+			break
+		}
+		point.Bounds = append(point.Bounds, bucket.GetUpperBound())
+		point.BucketCounts = append(point.BucketCounts, bucket.GetCumulativeCount()-previous)
+		previous = bucket.GetCumulativeCount()
+	}
+	point.BucketCounts = append(point.BucketCounts, h.GetSampleCount()-previous)
+	return point
+}
+
+func labelSet(labels []*dto.LabelPair) attribute.Set {
+	attrs := make([]attribute.KeyValue, len(labels))
+	for i, label := range labels {
+		attrs[i] = attribute.String(label.GetName(), label.GetValue())
+	}
+	return attribute.NewSet(attrs...)
+}
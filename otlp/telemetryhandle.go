@@ -0,0 +1,108 @@
+package otlp
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-container-networking/aitelemetry"
+	"go.opentelemetry.io/contrib/bridges/otelzap"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+)
+
+var _ aitelemetry.TelemetryHandle = (*TelemetryHandle)(nil)
+
+// TelemetryHandle implements aitelemetry.TelemetryHandle for OTLP, so that the components which report their
+// telemetry through a handle can export it to a collector instead of Application Insights.
+// Reports and events are exported as log records through the otelzap bridge, and metrics as gauges.
+type TelemetryHandle struct {
+	exporter *Exporter
+	logger   *zap.Logger
+	meter    metric.Meter
+
+	mu     sync.Mutex
+	gauges map[string]metric.Float64Gauge
+}
+
+// NewTelemetryHandle creates a TelemetryHandle which exports to the Exporter.
+func NewTelemetryHandle(e *Exporter) *TelemetryHandle {
+	return &TelemetryHandle{
+		exporter: e,
+		logger:   zap.New(otelzap.NewCore(scopeName, otelzap.WithLoggerProvider(e.loggerProvider))),
+		meter:    e.meterProvider.Meter(scopeName),
+		gauges:   map[string]metric.Float64Gauge{},
+	}
+}
+
+// TrackLog exports the report as an info log record, with its custom dimensions as attributes.
+func (t *TelemetryHandle) TrackLog(report aitelemetry.Report) {
+	fields := make([]zap.Field, 0, len(report.CustomDimensions)+2) //nolint:gomnd // the context and version fields
+	for k, v := range report.CustomDimensions {
+		fields = append(fields, zap.String(k, v))
+	}
+	if report.Context != "" {
+		fields = append(fields, zap.String("context", report.Context))
+	}
+	if report.AppVersion != "" {
+		fields = append(fields, zap.String("app.version", report.AppVersion))
+	}
+	t.logger.Info(report.Message, fields...)
+}
+
+// TrackEvent exports the event as an info log record named by its event.name attribute.
+func (t *TelemetryHandle) TrackEvent(event aitelemetry.Event) {
+	fields := make([]zap.Field, 0, len(event.Properties)+2) //nolint:gomnd // the name and resource id fields
+	for k, v := range event.Properties {
+		fields = append(fields, zap.String(k, v))
+	}
+	fields = append(fields, zap.String("event.name", event.EventName))
+	if event.ResourceID != "" {
+		fields = append(fields, zap.String("resource.id", event.ResourceID))
+	}
+	t.logger.Info(event.EventName, fields...)
+}
+
+// TrackMetric records the metric in a gauge of its name, with its custom dimensions as attributes.
+func (t *TelemetryHandle) TrackMetric(m aitelemetry.Metric) {
+	gauge, err := t.gauge(m.Name)
+	if err != nil {
+		otel.Handle(err)
+		return
+	}
+	attrs := make([]attribute.KeyValue, 0, len(m.CustomDimensions))
+	for k, v := range m.CustomDimensions {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+	gauge.Record(context.Background(), m.Value, metric.WithAttributes(attrs...))
+}
+
+func (t *TelemetryHandle) gauge(name string) (metric.Float64Gauge, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if gauge, ok := t.gauges[name]; ok {
+		return gauge, nil
+	}
+	gauge, err := t.meter.Float64Gauge(name)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // the SDK describes the invalid instrument
+	}
+	t.gauges[name] = gauge
+	return gauge, nil
+}
+
+// Close exports the queued telemetry, waiting up to the timeout in seconds.
+func (t *TelemetryHandle) Close(timeout int) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	defer cancel()
+	if err := t.exporter.Flush(ctx); err != nil {
+		otel.Handle(err)
+	}
+}
+
+// Flush exports the queued telemetry.
+func (t *TelemetryHandle) Flush() {
+	t.Close(int(defaultTimeout / time.Second))
+}
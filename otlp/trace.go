@@ -0,0 +1,93 @@
+package otlp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceparentHeader is the W3C Trace Context header which propagates spans across HTTP calls.
+const TraceparentHeader = "traceparent"
+
+// propagator propagates spans with the W3C Trace Context headers, whichever the global propagator is.
+var propagator = propagation.TraceContext{}
+
+// SetExporter sets the global TracerProvider and MeterProvider to the ones of the Exporter, so that the spans
+// started with Tracer and the metrics of the global meters are exported.
+// Without an Exporter, the spans of traced callers are still propagated, but no span is exported.
+func SetExporter(e *Exporter) {
+	otel.SetTracerProvider(e.tracerProvider)
+	otel.SetMeterProvider(e.meterProvider)
+}
+
+// Tracer returns the tracer of the ACN components, from the global TracerProvider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(scopeName)
+}
+
+// SetError marks the span as failed with the error, if it is not nil.
+func SetError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// Inject sets the traceparent header of the span of the context, if any.
+func Inject(ctx context.Context, h http.Header) {
+	propagator.Inject(ctx, propagation.HeaderCarrier(h))
+}
+
+// Extract returns a context carrying the remote span of the traceparent header, if it is valid.
+func Extract(ctx context.Context, h http.Header) context.Context {
+	return propagator.Extract(ctx, propagation.HeaderCarrier(h))
+}
+
+// Transport is an http.RoundTripper which sends each request in a client span, and propagates the span to the
+// server with the traceparent header.
+type Transport struct {
+	// Base sends the requests. Defaults to http.DefaultTransport.
+	Base http.RoundTripper
+	// SpanName, if set, names the span of the request. The default name includes the URL path of the request,
+	// so clients of APIs with secrets in their paths must set a SpanName which leaves them out.
+	SpanName func(*http.Request) string
+}
+
+// RoundTrip sends the request in a client span which is a child of the span of the request's context.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	name := "HTTP " + req.Method + " " + req.URL.Path
+	if t.SpanName != nil {
+		name = t.SpanName(req)
+	}
+	ctx, span := Tracer().Start(req.Context(), name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.method", req.Method),
+			attribute.String("net.peer.name", req.URL.Host)))
+	defer span.End()
+
+	// the request must not be modified by RoundTrippers
+	req = req.Clone(ctx)
+	Inject(ctx, req.Header)
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		SetError(span, err)
+		return resp, err //nolint:wrapcheck // the error of the wrapped transport is returned as is
+	}
+	span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		SetError(span, errors.New(resp.Status))
+	}
+	return resp, nil
+}
@@ -0,0 +1,220 @@
+// Copyright 2023 Microsoft. All rights reserved.
+
+package tls
+
+import (
+	"context"
+	cryptotls "crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/pkcs12"
+)
+
+// CertificateSource provides the latest certificate of a TLS server, which gets it for every handshake,
+// so that a renewed certificate is served without restarting the server.
+type CertificateSource interface {
+	// GetCertificate returns the latest certificate.
+	GetCertificate() *cryptotls.Certificate
+	// Run keeps the certificate up to date. It blocks until the context is done or the certificate can't be
+	// kept up to date anymore.
+	Run(ctx context.Context) error
+}
+
+// GetCertificateFunc adapts a CertificateSource to the GetCertificate callback of a tls.Config.
+func GetCertificateFunc(s CertificateSource) func(*cryptotls.ClientHelloInfo) (*cryptotls.Certificate, error) {
+	return func(*cryptotls.ClientHelloInfo) (*cryptotls.Certificate, error) {
+		if cert := s.GetCertificate(); cert != nil {
+			return cert, nil
+		}
+		return nil, errors.New("no certificate available")
+	}
+}
+
+type logger interface {
+	Printf(format string, args ...any)
+	Errorf(format string, args ...any)
+}
+
+// FileCertificateSource is a CertificateSource which reloads the certificate from disk whenever its files change.
+// The certificate is read from TLSCertificatePath, as:
+//   - a PEM certificate with the private key in TLSKeyPath, such as a kubernetes.io/tls secret mount,
+//   - a PFX bundle without a password, for paths ending in .pfx or .p12,
+//   - a PEM bundle of the certificate and private key otherwise, read by the TlsCertificateRetriever of the platform.
+//
+// The directories of the files are watched rather than the files themselves, because Kubernetes updates secret
+// mounts by swapping a symlink to the directory holding them.
+type FileCertificateSource struct {
+	settings TlsSettings
+	logger   logger
+
+	m    sync.RWMutex
+	cert *cryptotls.Certificate
+}
+
+// NewFileCertificateSource returns a FileCertificateSource with the certificate loaded from the files of the settings.
+func NewFileCertificateSource(settings TlsSettings, l logger) (*FileCertificateSource, error) {
+	cert, err := loadCertificate(settings)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load initial certificate")
+	}
+	s := &FileCertificateSource{
+		settings: settings,
+		logger:   l,
+		cert:     cert,
+	}
+	s.logger.Printf("[tls] loaded certificate %s expiring on %s", s.settings.TLSCertificatePath, cert.Leaf.NotAfter)
+	return s, nil
+}
+
+// GetCertificate returns the certificate last loaded from disk.
+func (s *FileCertificateSource) GetCertificate() *cryptotls.Certificate {
+	s.m.RLock()
+	defer s.m.RUnlock()
+	return s.cert
+}
+
+// Run reloads the certificate whenever the directories of its files change.
+// A certificate which fails to load, such as one which is only partially written, is skipped, and the previous
+// certificate is served until a valid one replaces it.
+func (s *FileCertificateSource) Run(ctx context.Context) error {
+	paths := []string{s.settings.TLSCertificatePath}
+	if s.settings.TLSKeyPath != "" {
+		paths = append(paths, s.settings.TLSKeyPath)
+	}
+	return watchFiles(ctx, paths, s.reload, func(err error) {
+		s.logger.Errorf("[tls] error watching certificate %s: %v", s.settings.TLSCertificatePath, err)
+	})
+}
+
+// watchFiles calls reload whenever the directories of the files change, until the context is done or the watch fails.
+func watchFiles(ctx context.Context, paths []string, reload func(), onError func(error)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrap(err, "could not create file watcher")
+	}
+	defer watcher.Close()
+
+	dirs := map[string]struct{}{}
+	for _, path := range paths {
+		dirs[filepath.Dir(path)] = struct{}{}
+	}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			return errors.Wrapf(err, "could not watch %s", dir)
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "watch canceled")
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return errors.New("file watcher closed")
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			reload()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return errors.New("file watcher closed")
+			}
+			onError(err)
+		}
+	}
+}
+
+func (s *FileCertificateSource) reload() {
+	cert, err := loadCertificate(s.settings)
+	if err != nil {
+		s.logger.Errorf("[tls] could not reload certificate %s, keeping the current one: %v", s.settings.TLSCertificatePath, err)
+		return
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+	if cert.Leaf.Equal(s.cert.Leaf) {
+		return
+	}
+	s.cert = cert
+	s.logger.Printf("[tls] reloaded certificate %s expiring on %s", s.settings.TLSCertificatePath, cert.Leaf.NotAfter)
+}
+
+func loadCertificate(settings TlsSettings) (*cryptotls.Certificate, error) {
+	var (
+		cert cryptotls.Certificate
+		err  error
+	)
+	switch ext := strings.ToLower(filepath.Ext(settings.TLSCertificatePath)); {
+	case settings.TLSKeyPath != "":
+		cert, err = cryptotls.LoadX509KeyPair(settings.TLSCertificatePath, settings.TLSKeyPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not load key pair")
+		}
+	case ext == ".pfx" || ext == ".p12":
+		if cert, err = loadPFX(settings.TLSCertificatePath); err != nil {
+			return nil, err
+		}
+	default:
+		if cert, err = loadFromRetriever(settings); err != nil {
+			return nil, err
+		}
+	}
+
+	if cert.Leaf == nil {
+		if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return nil, errors.Wrap(err, "could not parse leaf certificate")
+		}
+	}
+	return &cert, nil
+}
+
+func loadPFX(path string) (cryptotls.Certificate, error) {
+	pfx, err := os.ReadFile(path)
+	if err != nil {
+		return cryptotls.Certificate{}, errors.Wrapf(err, "could not read %s", path)
+	}
+	blocks, err := pkcs12.ToPEM(pfx, "")
+	if err != nil {
+		return cryptotls.Certificate{}, errors.Wrap(err, "could not convert pfx to pem")
+	}
+
+	var certPEM, keyPEM []byte
+	for _, b := range blocks {
+		if strings.Contains(b.Type, PrivateKeyLabel) {
+			keyPEM = append(keyPEM, pem.EncodeToMemory(b)...)
+			continue
+		}
+		certPEM = append(certPEM, pem.EncodeToMemory(b)...)
+	}
+	cert, err := cryptotls.X509KeyPair(certPEM, keyPEM)
+	return cert, errors.Wrap(err, "could not load key pair from pfx")
+}
+
+func loadFromRetriever(settings TlsSettings) (cryptotls.Certificate, error) {
+	retriever, err := GetTlsCertificateRetriever(settings)
+	if err != nil {
+		return cryptotls.Certificate{}, errors.Wrap(err, "failed to get certificate retriever")
+	}
+	leaf, err := retriever.GetCertificate()
+	if err != nil {
+		return cryptotls.Certificate{}, errors.Wrap(err, "failed to get certificate")
+	}
+	key, err := retriever.GetPrivateKey()
+	if err != nil {
+		return cryptotls.Certificate{}, errors.Wrap(err, "failed to get certificate private key")
+	}
+	return cryptotls.Certificate{
+		Certificate: [][]byte{leaf.Raw},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}, nil
+}
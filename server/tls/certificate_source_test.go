@@ -0,0 +1,139 @@
+// Copyright 2023 Microsoft. All rights reserved.
+
+package tls
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testLogger struct {
+	t *testing.T
+}
+
+func (l testLogger) Printf(format string, args ...any) {
+	l.t.Logf(format, args...)
+}
+
+func (l testLogger) Errorf(format string, args ...any) {
+	l.t.Logf(format, args...)
+}
+
+// writeKeyPair writes a self-signed certificate with the serial number and its key to the paths, like a
+// kubernetes.io/tls secret mount.
+func writeKeyPair(t *testing.T, certPath, keyPath string, serial int64) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate private key: %v", err)
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal private key: %v", err)
+	}
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: CertLabel, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: PrivateKeyLabel, Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("Failed to write private key: %v", err)
+	}
+}
+
+func TestFileCertificateSourceReload(t *testing.T) {
+	dir := t.TempDir()
+	settings := TlsSettings{
+		TLSCertificatePath: filepath.Join(dir, "tls.crt"),
+		TLSKeyPath:         filepath.Join(dir, "tls.key"),
+	}
+	writeKeyPair(t, settings.TLSCertificatePath, settings.TLSKeyPath, 1)
+
+	source, err := NewFileCertificateSource(settings, testLogger{t})
+	if err != nil {
+		t.Fatalf("Failed to create file certificate source: %v", err)
+	}
+	if serial := source.GetCertificate().Leaf.SerialNumber.Int64(); serial != 1 {
+		t.Fatalf("Unexpected serial number of the initial certificate %d", serial)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- source.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// the watch starts asynchronously, so the certificate is renewed until the source reloads it
+	deadline := time.Now().Add(10 * time.Second)
+	for serial := int64(2); source.GetCertificate().Leaf.SerialNumber.Int64() == 1; serial++ {
+		if time.Now().After(deadline) {
+			t.Fatal("Certificate was not reloaded")
+		}
+		writeKeyPair(t, settings.TLSCertificatePath, settings.TLSKeyPath, serial)
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	cert, err := GetCertificateFunc(source)(nil)
+	if err != nil {
+		t.Fatalf("Failed to get certificate: %v", err)
+	}
+	if cert.Leaf.SerialNumber.Int64() == 1 {
+		t.Fatal("GetCertificateFunc returned the initial certificate")
+	}
+}
+
+func TestFileCertificateSourceKeepsCertificateOnInvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	settings := TlsSettings{
+		TLSCertificatePath: filepath.Join(dir, "tls.crt"),
+		TLSKeyPath:         filepath.Join(dir, "tls.key"),
+	}
+	writeKeyPair(t, settings.TLSCertificatePath, settings.TLSKeyPath, 1)
+
+	source, err := NewFileCertificateSource(settings, testLogger{t})
+	if err != nil {
+		t.Fatalf("Failed to create file certificate source: %v", err)
+	}
+
+	// a partially written certificate is skipped
+	if err := os.WriteFile(settings.TLSCertificatePath, []byte("-----BEGIN CERT"), 0o600); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+	source.reload()
+	if serial := source.GetCertificate().Leaf.SerialNumber.Int64(); serial != 1 {
+		t.Fatalf("Unexpected serial number %d after an invalid reload", serial)
+	}
+}
+
+func TestNewFileCertificateSourceMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	_, err := NewFileCertificateSource(TlsSettings{
+		TLSCertificatePath: filepath.Join(dir, "tls.crt"),
+		TLSKeyPath:         filepath.Join(dir, "tls.key"),
+	}, testLogger{t})
+	if err == nil {
+		t.Fatal("Expected an error for missing certificate files")
+	}
+}
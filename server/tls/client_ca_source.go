@@ -0,0 +1,94 @@
+// Copyright 2023 Microsoft. All rights reserved.
+
+package tls
+
+import (
+	"bytes"
+	"context"
+	cryptotls "crypto/tls"
+	"crypto/x509"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ClientCASource provides the latest CAs which a TLS server verifies client certificates with, reloading them from
+// a PEM bundle whenever its file changes, so that the CAs can be rotated without restarting the server.
+type ClientCASource struct {
+	path   string
+	logger logger
+
+	m    sync.RWMutex
+	pem  []byte
+	pool *x509.CertPool
+}
+
+// NewClientCASource returns a ClientCASource with the CAs loaded from the PEM bundle at the path.
+func NewClientCASource(path string, l logger) (*ClientCASource, error) {
+	caPEM, pool, err := loadClientCAs(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load initial client CA certificates")
+	}
+	l.Printf("[tls] loaded client CA certificates %s", path)
+	return &ClientCASource{
+		path:   path,
+		logger: l,
+		pem:    caPEM,
+		pool:   pool,
+	}, nil
+}
+
+// GetClientCAs returns the CAs last loaded from disk.
+func (s *ClientCASource) GetClientCAs() *x509.CertPool {
+	s.m.RLock()
+	defer s.m.RUnlock()
+	return s.pool
+}
+
+// Run reloads the CAs whenever the directory of their file changes.
+// A bundle which fails to load is skipped, and the previous CAs are used until a valid bundle replaces it.
+func (s *ClientCASource) Run(ctx context.Context) error {
+	return watchFiles(ctx, []string{s.path}, s.reload, func(err error) {
+		s.logger.Errorf("[tls] error watching client CA certificates %s: %v", s.path, err)
+	})
+}
+
+func (s *ClientCASource) reload() {
+	caPEM, pool, err := loadClientCAs(s.path)
+	if err != nil {
+		s.logger.Errorf("[tls] could not reload client CA certificates %s, keeping the current ones: %v", s.path, err)
+		return
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+	if bytes.Equal(caPEM, s.pem) {
+		return
+	}
+	s.pem, s.pool = caPEM, pool
+	s.logger.Printf("[tls] reloaded client CA certificates %s", s.path)
+}
+
+// GetConfigForClientFunc adapts a ClientCASource to the GetConfigForClient callback of the tls.Config, which
+// verifies the certificate of every client with the latest CAs.
+func GetConfigForClientFunc(s *ClientCASource, config *cryptotls.Config) func(*cryptotls.ClientHelloInfo) (*cryptotls.Config, error) {
+	return func(*cryptotls.ClientHelloInfo) (*cryptotls.Config, error) {
+		c := config.Clone()
+		c.GetConfigForClient = nil
+		c.ClientCAs = s.GetClientCAs()
+		return c, nil
+	}
+}
+
+func loadClientCAs(path string) ([]byte, *x509.CertPool, error) {
+	caPEM, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "could not read %s", path)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, nil, errors.Errorf("no CA certificates in %s", path)
+	}
+	return caPEM, pool, nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2023 Microsoft. All rights reserved.
+
+package tls
+
+import (
+	cryptotls "crypto/tls"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClientCASourceReload(t *testing.T) {
+	dir := t.TempDir()
+	caPath := filepath.Join(dir, "ca.crt")
+	writeKeyPair(t, caPath, filepath.Join(dir, "ca.key"), 1)
+
+	source, err := NewClientCASource(caPath, testLogger{t})
+	if err != nil {
+		t.Fatalf("Failed to create client CA source: %v", err)
+	}
+	initial := source.GetClientCAs()
+
+	getConfig := GetConfigForClientFunc(source, &cryptotls.Config{ClientAuth: cryptotls.RequireAndVerifyClientCert})
+	config, err := getConfig(nil)
+	if err != nil {
+		t.Fatalf("Failed to get config for client: %v", err)
+	}
+	if !config.ClientCAs.Equal(initial) || config.ClientAuth != cryptotls.RequireAndVerifyClientCert {
+		t.Fatal("Config for client does not verify clients with the initial CAs")
+	}
+
+	// an invalid bundle is skipped
+	if err := os.WriteFile(caPath, []byte("-----BEGIN CERT"), 0o600); err != nil {
+		t.Fatalf("Failed to write CA certificates: %v", err)
+	}
+	source.reload()
+	if source.GetClientCAs() != initial {
+		t.Fatal("Client CAs changed after an invalid reload")
+	}
+
+	writeKeyPair(t, caPath, filepath.Join(dir, "ca.key"), 2)
+	source.reload()
+	if source.GetClientCAs().Equal(initial) {
+		t.Fatal("Client CAs were not reloaded")
+	}
+	if config, _ = getConfig(nil); !config.ClientCAs.Equal(source.GetClientCAs()) {
+		t.Fatal("Config for client does not verify clients with the reloaded CAs")
+	}
+}
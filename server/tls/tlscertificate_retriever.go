@@ -8,6 +8,8 @@ import "time"
 type TlsSettings struct {
 	TLSSubjectName                     string
 	TLSCertificatePath                 string
+	TLSKeyPath                         string
+	TLSClientCAPath                    string
 	TLSPort                            string
 	KeyVaultURL                        string
 	KeyVaultCertificateName            string
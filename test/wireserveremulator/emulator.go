@@ -0,0 +1,495 @@
+// Package wireserveremulator emulates the wireserver plugin API of an Azure host and the NMAgent behind it,
+// so that the NMAgent and wireserver clients of CNS can be tested without an Azure VM.
+package wireserveremulator
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-container-networking/cns/wireserver"
+	"github.com/Azure/azure-container-networking/nmagent"
+	"github.com/pkg/errors"
+)
+
+const (
+	// pluginPath is the wireserver path which requests to NMAgent are sent to.
+	pluginPath = "/machine/plugins"
+	// faultsPath is the path of the emulator API to inject faults.
+	faultsPath = "/emulator/faults"
+)
+
+// Operation identifies the NMAgent and wireserver requests.
+type Operation string
+
+const (
+	JoinNetwork            Operation = "JoinNetwork"
+	GetNetworkConfig       Operation = "GetNetworkConfig"
+	PutNetworkContainer    Operation = "PutNetworkContainer"
+	DeleteNetworkContainer Operation = "DeleteNetworkContainer"
+	GetNCVersion           Operation = "GetNCVersion"
+	GetNCVersionList       Operation = "GetNCVersionList"
+	SupportedAPIs          Operation = "SupportedAPIs"
+	GetHomeAz              Operation = "GetHomeAz"
+	GetInterfaces          Operation = "GetInterfaces"
+	// AnyOperation applies a Fault to the operations without a Fault of their own.
+	AnyOperation Operation = "*"
+)
+
+// Fault is injected into the responses of an Operation.
+type Fault struct {
+	// Latency delays the response.
+	Latency time.Duration
+	// WireserverStatusCode fails the request at wireserver with the HTTP status code.
+	WireserverStatusCode int
+	// NMAgentStatusCode fails the request at NMAgent with the status code, which wireserver returns in the
+	// httpStatusCode of a 200 OK response.
+	NMAgentStatusCode int
+	// StaleVersions reports the version of each NC from before its last PUT.
+	StaleVersions bool
+	// Count is the number of requests which the Fault is injected into, or 0 for all requests.
+	Count int
+}
+
+// Config is the initial state of the Emulator.
+type Config struct {
+	HomeAz        uint
+	SupportedAPIs []string
+	// Interfaces are returned by the getinterfaceinfov1 query.
+	Interfaces []wireserver.Interface
+	// VirtualNetworks are returned for the networks once they are joined. Other networks can be joined too,
+	// and have an empty configuration.
+	VirtualNetworks map[string]nmagent.VirtualNetwork
+}
+
+type networkContainer struct {
+	nmagent.PutNetworkContainerRequest
+	previousVersion uint64
+}
+
+// Emulator is an http.Handler which serves the wireserver plugin queries of the NMAgent client, as rewritten by its
+// WireserverTransport, and the getinterfaceinfov1 query of the wireserver client. The NC and network state is kept in
+// memory. Faults are injected with SetFault, or over HTTP at /emulator/faults.
+type Emulator struct {
+	sync.Mutex
+	config   Config
+	ncs      map[string]*networkContainer
+	joined   map[string]struct{}
+	faults   map[Operation]*Fault
+	requests map[Operation]int
+}
+
+// New creates an Emulator with the initial state.
+func New(config Config) *Emulator {
+	return &Emulator{
+		config:   config,
+		ncs:      map[string]*networkContainer{},
+		joined:   map[string]struct{}{},
+		faults:   map[Operation]*Fault{},
+		requests: map[Operation]int{},
+	}
+}
+
+// SetFault injects the Fault into the responses of the Operation, replacing its previous Fault.
+func (e *Emulator) SetFault(op Operation, fault Fault) {
+	e.Lock()
+	defer e.Unlock()
+	e.faults[op] = &fault
+}
+
+// ClearFaults removes all Faults.
+func (e *Emulator) ClearFaults() {
+	e.Lock()
+	defer e.Unlock()
+	e.faults = map[Operation]*Fault{}
+}
+
+// Requests returns the number of requests of the Operation, including those which failed.
+func (e *Emulator) Requests(op Operation) int {
+	e.Lock()
+	defer e.Unlock()
+	return e.requests[op]
+}
+
+// NetworkContainers returns the NCs which were PUT and not deleted, by ID.
+func (e *Emulator) NetworkContainers() map[string]nmagent.PutNetworkContainerRequest {
+	e.Lock()
+	defer e.Unlock()
+	ncs := make(map[string]nmagent.PutNetworkContainerRequest, len(e.ncs))
+	for id, nc := range e.ncs {
+		ncs[id] = nc.PutNetworkContainerRequest
+	}
+	return ncs
+}
+
+// JoinedNetworks returns the IDs of the joined networks.
+func (e *Emulator) JoinedNetworks() []string {
+	e.Lock()
+	defer e.Unlock()
+	networks := make([]string, 0, len(e.joined))
+	for id := range e.joined {
+		networks = append(networks, id)
+	}
+	sort.Strings(networks)
+	return networks
+}
+
+func (e *Emulator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch strings.TrimSuffix(r.URL.Path, "/") {
+	case pluginPath:
+		e.servePlugin(w, r)
+	case faultsPath:
+		e.serveFaults(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// response is returned by NMAgent. Its body is returned by wireserver with the httpStatusCode added,
+// or as is if it is not a JSON object.
+type response struct {
+	statusCode int
+	body       interface{}
+}
+
+func (e *Emulator) servePlugin(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("comp") != "nmagent" {
+		http.Error(w, "unknown plugin "+query.Get("comp"), http.StatusBadRequest)
+		return
+	}
+	op, handle := e.route(r.Method, strings.Split(strings.Trim(query.Get("type"), "/"), "/"))
+	if handle == nil {
+		http.Error(w, "unknown request "+query.Get("type"), http.StatusBadRequest)
+		return
+	}
+
+	fault := e.takeFault(op)
+	if fault.Latency > 0 {
+		select {
+		case <-time.After(fault.Latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if fault.WireserverStatusCode != 0 {
+		http.Error(w, http.StatusText(fault.WireserverStatusCode), fault.WireserverStatusCode)
+		return
+	}
+
+	var resp response
+	if fault.NMAgentStatusCode != 0 {
+		resp = response{statusCode: fault.NMAgentStatusCode}
+	} else {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		e.Lock()
+		resp = handle(body, fault.StaleVersions)
+		e.Unlock()
+	}
+	writeResponse(w, resp)
+}
+
+// takeFault counts the request, and returns the Fault to inject into it.
+func (e *Emulator) takeFault(op Operation) Fault {
+	e.Lock()
+	defer e.Unlock()
+	e.requests[op]++
+	key := op
+	fault, ok := e.faults[key]
+	if !ok {
+		key = AnyOperation
+		if fault, ok = e.faults[key]; !ok {
+			return Fault{}
+		}
+	}
+	if fault.Count > 0 {
+		if fault.Count--; fault.Count == 0 {
+			delete(e.faults, key)
+		}
+	}
+	return *fault
+}
+
+// route returns the Operation and handler of the request, from the segments of the NMAgent path.
+// The handler is called with the Emulator locked.
+func (e *Emulator) route(method string, path []string) (Operation, func(body []byte, stale bool) response) {
+	if len(path) == 1 && method == http.MethodGet {
+		switch path[0] {
+		case "GetSupportedApis":
+			return SupportedAPIs, e.supportedAPIs
+		case "GetHomeAz":
+			return GetHomeAz, e.homeAz
+		case "getinterfaceinfov1":
+			return GetInterfaces, e.interfaces
+		}
+		return "", nil
+	}
+	if len(path) < 4 || path[0] != "NetworkManagement" {
+		return "", nil
+	}
+
+	// NetworkManagement/joinedVirtualNetworks/{vnet}/api-version/1
+	if path[1] == "joinedVirtualNetworks" && len(path) == 5 {
+		vnet := path[2]
+		switch method {
+		case http.MethodPost:
+			return JoinNetwork, func([]byte, bool) response { return e.joinNetwork(vnet) }
+		case http.MethodGet:
+			return GetNetworkConfig, func([]byte, bool) response { return e.getNetworkConfig(vnet) }
+		}
+		return "", nil
+	}
+	if path[1] != "interfaces" {
+		return "", nil
+	}
+
+	// NetworkManagement/interfaces/api-version/{version}
+	if path[2] == "api-version" && len(path) == 4 && method == http.MethodGet {
+		return GetNCVersionList, e.getNCVersionList
+	}
+
+	// NetworkManagement/interfaces/{primary}/networkContainers/{nc}/[version/]authenticationToken/{token}/api-version/1[/method/DELETE]
+	deleteNC := len(path) > 2 && path[len(path)-2] == "method" && path[len(path)-1] == "DELETE"
+	if deleteNC {
+		path = path[:len(path)-2]
+	}
+	if len(path) < 9 || path[3] != "networkContainers" || path[len(path)-2] != "api-version" {
+		return "", nil
+	}
+	primaryAddress, ncID := path[2], path[4]
+	getVersion := path[5] == "version"
+	tokenStart := 6
+	if getVersion {
+		tokenStart = 7
+	}
+	if path[tokenStart-1] != "authenticationToken" || tokenStart >= len(path)-2 {
+		return "", nil
+	}
+	// base64 tokens may contain slashes
+	token := strings.Join(path[tokenStart:len(path)-2], "/")
+
+	switch {
+	case getVersion && method == http.MethodGet:
+		return GetNCVersion, func(_ []byte, stale bool) response { return e.getNCVersion(primaryAddress, ncID, token, stale) }
+	case deleteNC && method == http.MethodPost:
+		return DeleteNetworkContainer, func([]byte, bool) response { return e.deleteNetworkContainer(primaryAddress, ncID, token) }
+	case !getVersion && !deleteNC && method == http.MethodPost:
+		return PutNetworkContainer, func(body []byte, _ bool) response {
+			return e.putNetworkContainer(primaryAddress, ncID, token, body)
+		}
+	}
+	return "", nil
+}
+
+func (e *Emulator) supportedAPIs([]byte, bool) response {
+	return response{
+		statusCode: http.StatusOK,
+		body: struct {
+			XMLName xml.Name `xml:"SupportedAPIsResponseXML"`
+			nmagent.SupportedAPIsResponseXML
+		}{SupportedAPIsResponseXML: nmagent.SupportedAPIsResponseXML{SupportedApis: e.config.SupportedAPIs}},
+	}
+}
+
+func (e *Emulator) homeAz([]byte, bool) response {
+	return response{statusCode: http.StatusOK, body: nmagent.AzResponse{HomeAz: e.config.HomeAz}}
+}
+
+func (e *Emulator) interfaces([]byte, bool) response {
+	return response{
+		statusCode: http.StatusOK,
+		body: struct {
+			XMLName   xml.Name `xml:"Interfaces"`
+			Interface []wireserver.Interface
+		}{Interface: e.config.Interfaces},
+	}
+}
+
+func (e *Emulator) joinNetwork(vnet string) response {
+	e.joined[vnet] = struct{}{}
+	return response{statusCode: http.StatusOK}
+}
+
+func (e *Emulator) getNetworkConfig(vnet string) response {
+	if _, ok := e.joined[vnet]; !ok {
+		return response{statusCode: http.StatusNotFound}
+	}
+	return response{statusCode: http.StatusOK, body: e.config.VirtualNetworks[vnet]}
+}
+
+func (e *Emulator) putNetworkContainer(primaryAddress, ncID, token string, body []byte) response {
+	var req nmagent.PutNetworkContainerRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return response{statusCode: http.StatusBadRequest}
+	}
+	req.ID, req.PrimaryAddress, req.AuthenticationToken = ncID, primaryAddress, token
+	if err := req.Validate(); err != nil {
+		return response{statusCode: http.StatusBadRequest}
+	}
+	nc := &networkContainer{PutNetworkContainerRequest: req}
+	if existing, ok := e.ncs[ncID]; ok {
+		if existing.AuthenticationToken != token {
+			return response{statusCode: http.StatusUnauthorized}
+		}
+		nc.previousVersion = existing.Version
+	}
+	e.ncs[ncID] = nc
+	return response{statusCode: http.StatusOK}
+}
+
+func (e *Emulator) deleteNetworkContainer(primaryAddress, ncID, token string) response {
+	nc, ok := e.ncs[ncID]
+	if !ok || nc.PrimaryAddress != primaryAddress {
+		return response{statusCode: http.StatusNotFound}
+	}
+	if nc.AuthenticationToken != token {
+		return response{statusCode: http.StatusUnauthorized}
+	}
+	delete(e.ncs, ncID)
+	return response{statusCode: http.StatusOK}
+}
+
+func (e *Emulator) getNCVersion(primaryAddress, ncID, token string, stale bool) response {
+	nc, ok := e.ncs[ncID]
+	if !ok || nc.PrimaryAddress != primaryAddress {
+		return response{statusCode: http.StatusNotFound}
+	}
+	if nc.AuthenticationToken != token {
+		return response{statusCode: http.StatusUnauthorized}
+	}
+	return response{statusCode: http.StatusOK, body: nc.ncVersion(stale)}
+}
+
+func (e *Emulator) getNCVersionList(_ []byte, stale bool) response {
+	list := nmagent.NCVersionList{Containers: []nmagent.NCVersion{}}
+	for _, nc := range e.ncs {
+		list.Containers = append(list.Containers, nc.ncVersion(stale))
+	}
+	sort.Slice(list.Containers, func(i, j int) bool {
+		return list.Containers[i].NetworkContainerID < list.Containers[j].NetworkContainerID
+	})
+	return response{statusCode: http.StatusOK, body: list}
+}
+
+func (nc *networkContainer) ncVersion(stale bool) nmagent.NCVersion {
+	version := nc.Version
+	if stale {
+		version = nc.previousVersion
+	}
+	return nmagent.NCVersion{
+		NetworkContainerID: nc.ID,
+		Version:            strconv.FormatUint(version, 10),
+	}
+}
+
+// writeResponse writes the NMAgent response as wireserver does: JSON objects with the status code in their
+// httpStatusCode, and XML as is, with the status code of the response.
+func writeResponse(w http.ResponseWriter, resp response) {
+	var (
+		b   []byte
+		err error
+	)
+	switch resp.body.(type) {
+	case nil, nmagent.AzResponse, nmagent.VirtualNetwork, nmagent.NCVersion, nmagent.NCVersionList:
+		b, err = marshalWireserverJSON(resp)
+		w.Header().Set("Content-Type", "application/json")
+	default:
+		if b, err = xml.Marshal(resp.body); err == nil {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(resp.statusCode)
+		}
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, _ = w.Write(b)
+}
+
+func marshalWireserverJSON(resp response) ([]byte, error) {
+	body := map[string]json.RawMessage{}
+	if resp.body != nil && resp.statusCode == http.StatusOK {
+		b, err := json.Marshal(resp.body)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal response")
+		}
+		if err := json.Unmarshal(b, &body); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal response")
+		}
+	}
+	statusCode, err := json.Marshal(strconv.Itoa(resp.statusCode))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal status code")
+	}
+	body["httpStatusCode"] = statusCode
+	b, err := json.Marshal(body)
+	return b, errors.Wrap(err, "failed to marshal response")
+}
+
+// serveFaults sets the Fault of an Operation from the query parameters of a POST, and clears all Faults on a DELETE:
+//
+//	POST /emulator/faults?operation=PutNetworkContainer&latency=2s&wireserverStatusCode=500&nmagentStatusCode=401&staleVersions=true&count=1
+func (e *Emulator) serveFaults(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodDelete:
+		e.ClearFaults()
+	case http.MethodPost:
+		op, fault, err := parseFault(r.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		e.SetFault(op, fault)
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+func parseFault(query url.Values) (Operation, Fault, error) {
+	get := query.Get
+	var (
+		fault Fault
+		err   error
+	)
+	op := Operation(get("operation"))
+	if op == "" {
+		op = AnyOperation
+	}
+	if v := get("latency"); v != "" {
+		if fault.Latency, err = time.ParseDuration(v); err != nil {
+			return op, fault, errors.Wrap(err, "invalid latency")
+		}
+	}
+	if v := get("wireserverStatusCode"); v != "" {
+		if fault.WireserverStatusCode, err = strconv.Atoi(v); err != nil {
+			return op, fault, errors.Wrap(err, "invalid wireserverStatusCode")
+		}
+	}
+	if v := get("nmagentStatusCode"); v != "" {
+		if fault.NMAgentStatusCode, err = strconv.Atoi(v); err != nil {
+			return op, fault, errors.Wrap(err, "invalid nmagentStatusCode")
+		}
+	}
+	if v := get("staleVersions"); v != "" {
+		if fault.StaleVersions, err = strconv.ParseBool(v); err != nil {
+			return op, fault, errors.Wrap(err, "invalid staleVersions")
+		}
+	}
+	if v := get("count"); v != "" {
+		if fault.Count, err = strconv.Atoi(v); err != nil {
+			return op, fault, errors.Wrap(err, "invalid count")
+		}
+	}
+	return op, fault, nil
+}
@@ -0,0 +1,49 @@
+// The emulator serves a wireserver and NMAgent emulator, which CNS can be pointed at with its WireserverIP config.
+// Faults are injected at runtime with the /emulator/faults API, for example:
+//
+//	curl -X POST 'http://localhost:8080/emulator/faults?operation=PutNetworkContainer&nmagentStatusCode=500&count=2'
+package main
+
+import (
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/Azure/azure-container-networking/cns/wireserver"
+	"github.com/Azure/azure-container-networking/test/wireserveremulator"
+)
+
+func main() {
+	address := flag.String("address", "localhost:8080", "the address to serve on")
+	homeAz := flag.Uint("home-az", 1, "the home AZ of the node")
+	supportedAPIs := flag.String("supported-apis", "", "comma separated list of the NMAgent supported APIs")
+	interfaces := flag.String("interfaces", "", "path of a getinterfaceinfov1 XML response of the node interfaces")
+	flag.Parse()
+
+	config := wireserveremulator.Config{HomeAz: *homeAz}
+	if *supportedAPIs != "" {
+		config.SupportedAPIs = strings.Split(*supportedAPIs, ",")
+	}
+	if *interfaces != "" {
+		b, err := os.ReadFile(*interfaces)
+		if err != nil {
+			fmt.Printf("failed to read interfaces: %v\n", err)
+			os.Exit(1)
+		}
+		var res wireserver.GetInterfacesResult
+		if err := xml.Unmarshal(b, &res); err != nil {
+			fmt.Printf("failed to parse interfaces: %v\n", err)
+			os.Exit(1)
+		}
+		config.Interfaces = res.Interface
+	}
+
+	fmt.Printf("starting wireserver emulator on %s\n", *address)
+	if err := http.ListenAndServe(*address, wireserveremulator.New(config)); err != nil { //nolint:gosec // test server without timeouts
+		fmt.Printf("wireserver emulator failed: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,164 @@
+package wireserveremulator
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-container-networking/cns/logger"
+	"github.com/Azure/azure-container-networking/cns/wireserver"
+	"github.com/Azure/azure-container-networking/nmagent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	logger.InitLogger("testlogs", 0, 0, "./")
+}
+
+func newTestServer(t *testing.T) (*Server, *nmagent.Client) {
+	s := NewServer(Config{
+		HomeAz:        2,
+		SupportedAPIs: []string{"EnableGreKey"},
+		Interfaces: []wireserver.Interface{
+			{
+				MacAddress: "002248263DBD",
+				IsPrimary:  true,
+				IPSubnet: []wireserver.Subnet{
+					{Prefix: "10.240.0.0/16", IPAddress: []wireserver.Address{{Address: "10.240.0.4", IsPrimary: true}}},
+				},
+			},
+		},
+		VirtualNetworks: map[string]nmagent.VirtualNetwork{
+			"vnet": {VNetSpace: "10.0.0.0/8"},
+		},
+	})
+	t.Cleanup(s.Close)
+	client, err := nmagent.NewClient(s.NMAgentConfig())
+	require.NoError(t, err)
+	return s, client
+}
+
+func putNCRequest(version uint64) *nmagent.PutNetworkContainerRequest {
+	return &nmagent.PutNetworkContainerRequest{
+		ID:                  "nc",
+		VNetID:              "vnet",
+		Version:             version,
+		SubnetName:          "subnet",
+		IPv4Addrs:           []string{"10.0.0.5"},
+		AuthenticationToken: "token/with/slashes",
+		PrimaryAddress:      "10.240.0.4",
+	}
+}
+
+func TestNetworkContainers(t *testing.T) {
+	s, client := newTestServer(t)
+	ctx := context.Background()
+
+	require.NoError(t, client.PutNetworkContainer(ctx, putNCRequest(1)))
+	require.NoError(t, client.PutNetworkContainer(ctx, putNCRequest(2)))
+	assert.Equal(t, []string{"10.0.0.5"}, s.NetworkContainers()["nc"].IPv4Addrs)
+
+	version, err := client.GetNCVersion(ctx, nmagent.NCVersionRequest{
+		AuthToken:          "token/with/slashes",
+		NetworkContainerID: "nc",
+		PrimaryAddress:     "10.240.0.4",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, nmagent.NCVersion{NetworkContainerID: "nc", Version: "2"}, version)
+
+	_, err = client.GetNCVersion(ctx, nmagent.NCVersionRequest{
+		AuthToken:          "wrong",
+		NetworkContainerID: "nc",
+		PrimaryAddress:     "10.240.0.4",
+	})
+	var nmaErr nmagent.Error
+	require.ErrorAs(t, err, &nmaErr)
+	assert.True(t, nmaErr.Unauthorized())
+
+	s.SetFault(GetNCVersionList, Fault{StaleVersions: true, Count: 1})
+	list, err := client.GetNCVersionList(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []nmagent.NCVersion{{NetworkContainerID: "nc", Version: "1"}}, list.Containers)
+	list, err = client.GetNCVersionList(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []nmagent.NCVersion{{NetworkContainerID: "nc", Version: "2"}}, list.Containers)
+
+	require.NoError(t, client.DeleteNetworkContainer(ctx, nmagent.DeleteContainerRequest{
+		NCID:                "nc",
+		PrimaryAddress:      "10.240.0.4",
+		AuthenticationToken: "token/with/slashes",
+	}))
+	assert.Empty(t, s.NetworkContainers())
+	assert.Equal(t, 1, s.Requests(DeleteNetworkContainer))
+}
+
+func TestNetworks(t *testing.T) {
+	s, client := newTestServer(t)
+	ctx := context.Background()
+
+	_, err := client.GetNetworkConfiguration(ctx, nmagent.GetNetworkConfigRequest{VNetID: "vnet"})
+	require.Error(t, err)
+
+	require.NoError(t, client.JoinNetwork(ctx, nmagent.JoinNetworkRequest{NetworkID: "vnet"}))
+	assert.Equal(t, []string{"vnet"}, s.JoinedNetworks())
+	vnet, err := client.GetNetworkConfiguration(ctx, nmagent.GetNetworkConfigRequest{VNetID: "vnet"})
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.0/8", vnet.VNetSpace)
+}
+
+func TestHostInfo(t *testing.T) {
+	s, client := newTestServer(t)
+	ctx := context.Background()
+
+	apis, err := client.SupportedAPIs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"EnableGreKey"}, apis)
+
+	az, err := client.GetHomeAz(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), az.HomeAz)
+
+	interfaces, err := s.WireserverClient().GetInterfaces(ctx)
+	require.NoError(t, err)
+	require.Len(t, interfaces.Interface, 1)
+	assert.Equal(t, "10.240.0.4", interfaces.Interface[0].IPSubnet[0].IPAddress[0].Address)
+}
+
+func TestFaults(t *testing.T) {
+	s, client := newTestServer(t)
+	ctx := context.Background()
+	var nmaErr nmagent.Error
+
+	s.SetFault(PutNetworkContainer, Fault{WireserverStatusCode: http.StatusServiceUnavailable, Count: 1})
+	err := client.PutNetworkContainer(ctx, putNCRequest(1))
+	require.ErrorAs(t, err, &nmaErr)
+	assert.Equal(t, http.StatusServiceUnavailable, nmaErr.StatusCode())
+	assert.Equal(t, "wireserver", nmaErr.Source)
+	require.NoError(t, client.PutNetworkContainer(ctx, putNCRequest(1)))
+
+	s.SetFault(AnyOperation, Fault{NMAgentStatusCode: http.StatusInternalServerError})
+	_, err = client.GetHomeAz(ctx)
+	require.ErrorAs(t, err, &nmaErr)
+	assert.Equal(t, http.StatusInternalServerError, nmaErr.StatusCode())
+	assert.Equal(t, "nmagent", nmaErr.Source)
+
+	// faults are injected over HTTP too
+	resp, err := http.Post(s.URL+faultsPath+"?operation=GetHomeAz&latency=50ms", "", http.NoBody) //nolint:noctx // test request
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	start := time.Now()
+	_, err = client.GetHomeAz(ctx)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.URL+faultsPath, http.NoBody)
+	require.NoError(t, err)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	_, err = client.GetNCVersionList(ctx)
+	require.NoError(t, err)
+}
@@ -0,0 +1,50 @@
+package wireserveremulator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+
+	"github.com/Azure/azure-container-networking/cns/wireserver"
+	"github.com/Azure/azure-container-networking/nmagent"
+)
+
+// Server is an Emulator served by an httptest.Server, for in-process tests.
+type Server struct {
+	*Emulator
+	*httptest.Server
+}
+
+// NewServer starts a Server with the initial state. It must be closed.
+func NewServer(config Config) *Server {
+	e := New(config)
+	return &Server{
+		Emulator: e,
+		Server:   httptest.NewServer(e),
+	}
+}
+
+// WireserverIP returns the host:port of the Server, to configure as the CNSConfig WireserverIP.
+func (s *Server) WireserverIP() string {
+	u, _ := url.Parse(s.URL)
+	return u.Host
+}
+
+// NMAgentConfig returns the config of an NMAgent client of the Server.
+func (s *Server) NMAgentConfig() nmagent.Config {
+	u, _ := url.Parse(s.URL)
+	port, _ := strconv.ParseUint(u.Port(), 10, 16)
+	return nmagent.Config{
+		Host: u.Hostname(),
+		Port: uint16(port),
+	}
+}
+
+// WireserverClient returns a wireserver client of the Server.
+func (s *Server) WireserverClient() *wireserver.Client {
+	return &wireserver.Client{
+		HTTPClient: &http.Client{},
+		Host:       s.WireserverIP(),
+	}
+}